@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	"github.com/rs/zerolog"
+
+	"github.com/persistenceOne/oracle-feeder/config"
+	"github.com/persistenceOne/oracle-feeder/oracle"
+	"github.com/persistenceOne/oracle-feeder/pkg/archive"
+)
+
+// newArchiveStores returns the stores attestations are archived to, with the
+// S3 keys resolved from their secret references.
+func newArchiveStores(cfg config.Archive, timeout time.Duration) (map[string]archive.Store, error) {
+	stores := make(map[string]archive.Store, 2) //nolint:gomnd //IPFS and S3
+	if len(cfg.IPFSURL) > 0 {
+		stores["ipfs"] = archive.NewIPFSStore(cfg.IPFSURL, timeout)
+	}
+	if s3 := cfg.S3; len(s3.Bucket) > 0 {
+		accessKey, err := config.ResolveSecret(s3.AccessKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve archive s3 access key: %w", err)
+		}
+		secretKey, err := config.ResolveSecret(s3.SecretKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve archive s3 secret key: %w", err)
+		}
+		stores["s3"] = archive.NewS3Store(s3.Endpoint, s3.Region, s3.Bucket, s3.Prefix, accessKey, secretKey, timeout)
+	}
+
+	return stores, nil
+}
+
+// startArchiver archives an attestation of the current prices, signed by the
+// feeder key, to every store each interval, once the first oracle tick
+// completed. Failures are logged and counted but never stop the feeder.
+func startArchiver(
+	ctx context.Context,
+	logger zerolog.Logger,
+	cfg config.Config,
+	oracle *oracle.Oracle,
+	stores map[string]archive.Store,
+	interval time.Duration,
+) error {
+	logger = logger.With().Str("module", "archive").Logger()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		if oracle.GetLastPriceSyncTimestamp().IsZero() {
+			continue
+		}
+
+		attestation, err := oracle.Attest(cfg)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to attest prices")
+			continue
+		}
+		bz, err := json.Marshal(attestation)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to encode attestation")
+			continue
+		}
+
+		name := fmt.Sprintf("attestation-%d.json", time.Now().UnixMilli())
+		for storeName, store := range stores {
+			location, err := store.Put(ctx, name, bz)
+			if err != nil {
+				logger.Error().Err(err).Str("store", storeName).Msg("failed to archive attestation")
+				telemetry.IncrCounterWithLabels(
+					[]string{"archive", "failure"}, 1,
+					[]metrics.Label{telemetry.NewLabel("store", storeName)},
+				)
+				continue
+			}
+			logger.Info().Str("store", storeName).Str("location", location).Msg("archived attestation")
+		}
+	}
+}
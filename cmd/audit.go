@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/persistenceOne/oracle-feeder/config"
+	"github.com/persistenceOne/oracle-feeder/pkg/audit"
+)
+
+// newAuditTrail returns the trail recording every use of the feeder key to the
+// logs and the configured audit file and service.
+func newAuditTrail(logger zerolog.Logger, cfg config.Audit) (*audit.Trail, error) {
+	var sinks []audit.Sink
+	if len(cfg.File) > 0 {
+		fileSink, err := audit.NewFileSink(cfg.File)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, fileSink)
+	}
+
+	if len(cfg.URL) > 0 {
+		timeout, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse audit timeout: %w", err)
+		}
+		sinks = append(sinks, audit.NewHTTPSink(cfg.URL, timeout))
+	}
+
+	return audit.New(logger, sinks...), nil
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/persistenceOne/oracle-feeder/config"
+	"github.com/persistenceOne/oracle-feeder/oracle"
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+)
+
+const (
+	flagCoverageWarmup = "warmup"
+	flagCoverageFormat = "format"
+
+	coverageFormatText = "text"
+	coverageFormatJSON = "json"
+)
+
+func init() {
+	rootCmd.AddCommand(coverageCmd)
+
+	coverageCmd.Flags().Duration(
+		flagCoverageWarmup,
+		30*time.Second,
+		"time given to the streaming providers to receive their first prices",
+	)
+	coverageCmd.Flags().String(flagCoverageFormat, coverageFormatText, "report format; must be either json or text")
+}
+
+var coverageCmd = &cobra.Command{
+	Use:   "coverage [config-file]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Report how the configured pairs are covered by the supported providers",
+	Long: `Check every configured pair against every supported provider, i.e. whether
+the provider lists the pair and returns a price with a volume for it, and
+report the providers covering a pair which aren't configured for it, the
+configured providers which don't cover it and the assets covered by a single
+source. Run it periodically, e.g. nightly from cron with --format json, to
+strengthen the pair configuration before a provider outage does. No chain
+connection is needed.`,
+	Annotations: map[string]string{annotationConfigArg: ""},
+	RunE:        coverageCmdHandler,
+}
+
+func coverageCmdHandler(cmd *cobra.Command, args []string) error {
+	warmup, err := cmd.Flags().GetDuration(flagCoverageWarmup)
+	if err != nil {
+		return err
+	}
+	format, err := cmd.Flags().GetString(flagCoverageFormat)
+	if err != nil {
+		return err
+	}
+	if format != coverageFormatText && format != coverageFormatJSON {
+		return fmt.Errorf("format must be either json or text")
+	}
+
+	logLvlStr, err := cmd.Flags().GetString(flagLogLevel)
+	if err != nil {
+		return err
+	}
+	logFormatStr, err := cmd.Flags().GetString(flagLogFormat)
+	if err != nil {
+		return err
+	}
+	logMaxValue, err := cmd.Flags().GetInt(flagLogMaxValue)
+	if err != nil {
+		return err
+	}
+	logger, err := setUpLogger(logLvlStr, strings.ToLower(logFormatStr), logMaxValue)
+	if err != nil {
+		return fmt.Errorf("failed to set up logger: %w", err)
+	}
+
+	cfg, err := config.ParseConfig(args[0])
+	if err != nil {
+		return err
+	}
+
+	report, err := oracle.CheckCoverage(cmd.Context(), logger, cfg, warmup)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if format == coverageFormatJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	printCoverageReport(out, report)
+	return nil
+}
+
+// printCoverageReport prints the report as text, one block per pair.
+func printCoverageReport(out io.Writer, report oracle.CoverageReport) {
+	for _, pair := range report.Pairs {
+		fmt.Fprintln(out, pair.Pair)
+		fmt.Fprintf(out, "  %-12s %s\n", "configured:", joinProviderNames(pair.Configured))
+		fmt.Fprintf(out, "  %-12s %s\n", "unavailable:", joinProviderNames(pair.Unavailable))
+		fmt.Fprintf(out, "  %-12s %s\n", "unused:", joinProviderNames(pair.Unused))
+	}
+
+	fmt.Fprintf(out, "\nsingle-source assets: %s\n", strings.Join(report.SingleSourceAssets, ", "))
+
+	if len(report.ProviderErrors) > 0 {
+		names := make([]string, 0, len(report.ProviderErrors))
+		for providerName := range report.ProviderErrors {
+			names = append(names, string(providerName))
+		}
+		sort.Strings(names)
+
+		fmt.Fprintln(out, "\nproviders not checked:")
+		for _, name := range names {
+			fmt.Fprintf(out, "  %s: %s\n", name, report.ProviderErrors[provider.Name(name)])
+		}
+	}
+}
+
+// joinProviderNames returns the comma separated provider names, or "-".
+func joinProviderNames(names []provider.Name) string {
+	if len(names) == 0 {
+		return "-"
+	}
+
+	strs := make([]string, len(names))
+	for i, name := range names {
+		strs[i] = string(name)
+	}
+	return strings.Join(strs, ", ")
+}
@@ -0,0 +1,417 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/persistenceOne/oracle-feeder/config"
+	"github.com/persistenceOne/oracle-feeder/oracle"
+	"github.com/persistenceOne/oracle-feeder/oracle/client"
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+	v1 "github.com/persistenceOne/oracle-feeder/router/v1"
+)
+
+const (
+	flagLoadtestAssets          = "assets"
+	flagLoadtestProviders       = "providers"
+	flagLoadtestTicks           = "ticks"
+	flagLoadtestTickInterval    = "tick-interval"
+	flagLoadtestProviderLatency = "provider-latency"
+	flagLoadtestAPIConcurrency  = "api-concurrency"
+)
+
+func init() {
+	rootCmd.AddCommand(loadtestCmd)
+
+	loadtestCmd.Flags().Int(flagLoadtestAssets, 20, "number of synthetic assets")
+	loadtestCmd.Flags().Int(flagLoadtestProviders, 5, "number of synthetic providers quoting every asset")
+	loadtestCmd.Flags().Int(flagLoadtestTicks, 100, "number of oracle ticks to run")
+	loadtestCmd.Flags().Duration(flagLoadtestTickInterval, 100*time.Millisecond, "interval between ticks")
+	loadtestCmd.Flags().Duration(
+		flagLoadtestProviderLatency,
+		50*time.Millisecond,
+		"mean latency injected into every synthetic provider request, jittered by up to 50%",
+	)
+	loadtestCmd.Flags().Int(
+		flagLoadtestAPIConcurrency,
+		10,
+		"number of concurrent API clients requesting prices while the ticks run; 0 disables the API load",
+	)
+}
+
+var loadtestCmd = &cobra.Command{
+	Use:   "loadtest",
+	Args:  cobra.NoArgs,
+	Short: "Load test the price aggregation pipeline and API server with synthetic providers",
+	Long: `Drive the price aggregation pipeline with synthetic providers at the given
+asset and provider cardinality and tick rate, while concurrently requesting
+prices from an in-process API server, and print latency percentiles. This
+helps sizing machines before deploying the price-feeder; no network access
+or chain connection is needed.`,
+	RunE: loadtestCmdHandler,
+}
+
+// loadtestOracle serves the prices computed by the load test through the v1
+// API, which otherwise only serves the prices of a running oracle.
+type loadtestOracle struct {
+	*oracle.Oracle
+
+	mtx    sync.RWMutex
+	prices map[string]sdk.Dec
+}
+
+func (lo *loadtestOracle) GetLastPriceSyncTimestamp() time.Time {
+	return time.Now()
+}
+
+func (lo *loadtestOracle) GetPrices() map[string]sdk.Dec {
+	lo.mtx.RLock()
+	defer lo.mtx.RUnlock()
+
+	return lo.prices
+}
+
+func (lo *loadtestOracle) GetPriceTimestamps() map[string]time.Time {
+	lo.mtx.RLock()
+	defer lo.mtx.RUnlock()
+
+	now := time.Now()
+	timestamps := make(map[string]time.Time, len(lo.prices))
+	for base := range lo.prices {
+		timestamps[base] = now
+	}
+	return timestamps
+}
+
+func (lo *loadtestOracle) setPrices(prices map[string]sdk.Dec) {
+	lo.mtx.Lock()
+	defer lo.mtx.Unlock()
+
+	lo.prices = prices
+}
+
+//nolint:funlen //No need to split this function
+func loadtestCmdHandler(cmd *cobra.Command, _ []string) error {
+	flags := cmd.Flags()
+
+	numAssets, err := flags.GetInt(flagLoadtestAssets)
+	if err != nil {
+		return err
+	}
+	numProviders, err := flags.GetInt(flagLoadtestProviders)
+	if err != nil {
+		return err
+	}
+	numTicks, err := flags.GetInt(flagLoadtestTicks)
+	if err != nil {
+		return err
+	}
+	tickInterval, err := flags.GetDuration(flagLoadtestTickInterval)
+	if err != nil {
+		return err
+	}
+	providerLatency, err := flags.GetDuration(flagLoadtestProviderLatency)
+	if err != nil {
+		return err
+	}
+	apiConcurrency, err := flags.GetInt(flagLoadtestAPIConcurrency)
+	if err != nil {
+		return err
+	}
+	if numAssets < 1 || numProviders < 1 || numTicks < 1 {
+		return fmt.Errorf("assets, providers and ticks must be positive")
+	}
+
+	market := newSyntheticMarket(numAssets, numProviders)
+	lo := &loadtestOracle{
+		Oracle: oracle.New(
+			zerolog.Nop(),
+			client.ChainClient{},
+			market.currencyPairs,
+			providerLatency,
+			make(map[string]sdk.Dec),
+			make(map[provider.Name]provider.Endpoint),
+		),
+	}
+
+	rtr := mux.NewRouter()
+	v1.New(zerolog.Nop(), config.Config{}, lo, nil).RegisterRoutes(rtr, v1.APIPathPrefix)
+	srv := httptest.NewServer(rtr)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+
+	results := newLoadtestResults(numTicks)
+
+	// warm up with a first tick, so that the API has prices to serve
+	candles, tickers := market.fetchPrices(0)
+	prices, err := lo.GetComputedPrices(candles, tickers, market.providerPairs, make(map[string]sdk.Dec))
+	if err != nil {
+		return fmt.Errorf("failed to compute prices: %w", err)
+	}
+	lo.setPrices(prices)
+
+	apiGroup := errgroup.Group{}
+	for i := 0; i < apiConcurrency; i++ {
+		apiGroup.Go(func() error {
+			for ctx.Err() == nil {
+				start := time.Now()
+				ok := requestPrices(ctx, srv.Client(), srv.URL+v1.APIPathPrefix+"/prices")
+				// requests failing because the load test is over are not errors
+				if ok || ctx.Err() == nil {
+					results.recordAPIRequest(time.Since(start), ok)
+				}
+			}
+			return nil
+		})
+	}
+
+	start := time.Now()
+	for tick := 0; tick < numTicks; tick++ {
+		tickStart := time.Now()
+
+		candles, tickers = market.fetchPrices(providerLatency)
+
+		aggregateStart := time.Now()
+		prices, err = lo.GetComputedPrices(candles, tickers, market.providerPairs, make(map[string]sdk.Dec))
+		if err != nil {
+			return fmt.Errorf("failed to compute prices: %w", err)
+		}
+		results.recordTick(time.Since(tickStart), time.Since(aggregateStart))
+
+		lo.setPrices(prices)
+		time.Sleep(tickInterval)
+	}
+	elapsed := time.Since(start)
+
+	cancel()
+	_ = apiGroup.Wait()
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "assets: %d, providers: %d, ticks: %d, duration: %s\n\n",
+		numAssets, numProviders, numTicks, elapsed.Round(time.Millisecond))
+	results.print(out, elapsed, apiConcurrency > 0)
+
+	return nil
+}
+
+// syntheticMarket defines the synthetic providers of a load test, each of
+// them quoting every synthetic asset against USD.
+type syntheticMarket struct {
+	providers     []provider.Name
+	pairs         []types.CurrencyPair
+	currencyPairs []config.CurrencyPair
+	providerPairs map[provider.Name][]types.CurrencyPair
+}
+
+// newSyntheticMarket returns a market of numAssets assets, "ASSET0" to
+// "ASSET<numAssets-1>", quoted by numProviders providers, "synthetic-0" to
+// "synthetic-<numProviders-1>".
+func newSyntheticMarket(numAssets, numProviders int) syntheticMarket {
+	market := syntheticMarket{
+		providers:     make([]provider.Name, numProviders),
+		pairs:         make([]types.CurrencyPair, numAssets),
+		currencyPairs: make([]config.CurrencyPair, numAssets),
+		providerPairs: make(map[provider.Name][]types.CurrencyPair, numProviders),
+	}
+	for i := range market.providers {
+		market.providers[i] = provider.Name(fmt.Sprintf("synthetic-%d", i))
+	}
+	for i := range market.pairs {
+		market.pairs[i] = types.CurrencyPair{Base: fmt.Sprintf("ASSET%d", i), Quote: config.DenomUSD}
+		market.currencyPairs[i] = config.CurrencyPair{
+			Base:      market.pairs[i].Base,
+			Quote:     market.pairs[i].Quote,
+			Providers: market.providers,
+		}
+	}
+	for _, providerName := range market.providers {
+		market.providerPairs[providerName] = market.pairs
+	}
+
+	return market
+}
+
+// fetchPrices simulates fetching the tickers and candles of every pair from
+// every provider concurrently, each request taking the given mean latency.
+// The price of the i-th asset is within 1% around i+1.
+func (m syntheticMarket) fetchPrices(
+	latency time.Duration,
+) (provider.AggregatedProviderCandles, provider.AggregatedProviderPrices) {
+	var (
+		wg      sync.WaitGroup
+		mtx     sync.Mutex
+		candles = make(provider.AggregatedProviderCandles, len(m.providers))
+		tickers = make(provider.AggregatedProviderPrices, len(m.providers))
+		now     = provider.PastUnixTime(0)
+	)
+
+	for _, providerName := range m.providers {
+		wg.Add(1)
+		go func(providerName provider.Name) {
+			defer wg.Done()
+
+			if latency > 0 {
+				// jitter the latency by up to 50% in either direction
+				jitter := time.Duration(rand.Int63n(int64(latency))) - latency/2 //#nosec
+				time.Sleep(latency + jitter)
+			}
+
+			providerCandles := make(map[string][]types.CandlePrice, len(m.pairs))
+			providerTickers := make(map[string]types.TickerPrice, len(m.pairs))
+			for i, pair := range m.pairs {
+				price := sdk.NewDec(int64(i + 1)).Mul(sdk.NewDecWithPrec(int64(995+rand.Intn(10)), 3)) //#nosec
+				volume := sdk.NewDec(int64(1000 + rand.Intn(1000)))                                    //#nosec
+
+				providerTickers[pair.Base] = types.TickerPrice{Price: price, Volume: volume}
+				providerCandles[pair.Base] = []types.CandlePrice{
+					{Price: price, Volume: volume, TimeStamp: now - int64(time.Minute/time.Millisecond)},
+					{Price: price, Volume: volume, TimeStamp: now},
+				}
+			}
+
+			mtx.Lock()
+			candles[providerName] = providerCandles
+			tickers[providerName] = providerTickers
+			mtx.Unlock()
+		}(providerName)
+	}
+	wg.Wait()
+
+	return candles, tickers
+}
+
+// requestPrices requests the prices endpoint and returns whether it succeeded.
+func requestPrices(ctx context.Context, httpClient *http.Client, url string) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode == http.StatusOK
+}
+
+type (
+	// loadtestResults aggregates the latencies measured by a load test. API
+	// requests are recorded concurrently by the API clients.
+	loadtestResults struct {
+		tick        []time.Duration
+		aggregation []time.Duration
+
+		mtx       sync.Mutex
+		api       []time.Duration
+		apiErrors int
+	}
+
+	// latencySummary defines the percentiles of a set of latencies.
+	latencySummary struct {
+		Count int
+		P50   time.Duration
+		P90   time.Duration
+		P99   time.Duration
+		Max   time.Duration
+	}
+)
+
+func newLoadtestResults(numTicks int) *loadtestResults {
+	return &loadtestResults{
+		tick:        make([]time.Duration, 0, numTicks),
+		aggregation: make([]time.Duration, 0, numTicks),
+	}
+}
+
+// recordTick records the latency of a tick and of the price aggregation
+// within it.
+func (lr *loadtestResults) recordTick(tick, aggregation time.Duration) {
+	lr.tick = append(lr.tick, tick)
+	lr.aggregation = append(lr.aggregation, aggregation)
+}
+
+// recordAPIRequest records the latency of an API request, or an error if it
+// didn't succeed.
+func (lr *loadtestResults) recordAPIRequest(latency time.Duration, ok bool) {
+	lr.mtx.Lock()
+	defer lr.mtx.Unlock()
+
+	if !ok {
+		lr.apiErrors++
+		return
+	}
+	lr.api = append(lr.api, latency)
+}
+
+// print prints the latency percentiles as a table, and the API latencies and
+// throughput over elapsed if the API was load tested.
+func (lr *loadtestResults) print(out io.Writer, elapsed time.Duration, api bool) {
+	lr.mtx.Lock()
+	defer lr.mtx.Unlock()
+
+	fmt.Fprintf(out, "%-12s %8s %12s %12s %12s %12s\n", "", "count", "p50", "p90", "p99", "max")
+	printLatencies(out, "tick", summarizeLatencies(lr.tick))
+	printLatencies(out, "aggregation", summarizeLatencies(lr.aggregation))
+	if api {
+		printLatencies(out, "api", summarizeLatencies(lr.api))
+		fmt.Fprintf(out, "\napi requests: %d (%.0f/s), errors: %d\n",
+			len(lr.api), float64(len(lr.api))/elapsed.Seconds(), lr.apiErrors)
+	}
+}
+
+// summarizeLatencies returns the percentiles of the given latencies, sorting
+// them in place.
+func summarizeLatencies(latencies []time.Duration) latencySummary {
+	if len(latencies) == 0 {
+		return latencySummary{}
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	percentile := func(p float64) time.Duration {
+		return latencies[int(p*float64(len(latencies)-1))]
+	}
+
+	return latencySummary{
+		Count: len(latencies),
+		P50:   percentile(0.5),
+		P90:   percentile(0.9),
+		P99:   percentile(0.99),
+		Max:   latencies[len(latencies)-1],
+	}
+}
+
+// printLatencies prints a latency summary as a table row.
+func printLatencies(out io.Writer, name string, summary latencySummary) {
+	if summary.Count == 0 {
+		fmt.Fprintf(out, "%-12s %8d\n", name, 0)
+		return
+	}
+
+	fmt.Fprintf(out, "%-12s %8d %12s %12s %12s %12s\n",
+		name,
+		summary.Count,
+		summary.P50.Round(time.Microsecond),
+		summary.P90.Round(time.Microsecond),
+		summary.P99.Round(time.Microsecond),
+		summary.Max.Round(time.Microsecond),
+	)
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+)
+
+func TestNewSyntheticMarket(t *testing.T) {
+	market := newSyntheticMarket(3, 2)
+
+	require.Equal(t, []provider.Name{"synthetic-0", "synthetic-1"}, market.providers)
+	require.Len(t, market.pairs, 3)
+	require.Len(t, market.currencyPairs, 3)
+	for i, pair := range market.currencyPairs {
+		require.Equal(t, market.pairs[i].Base, pair.Base)
+		require.Equal(t, "USD", pair.Quote)
+		require.Equal(t, market.providers, pair.Providers)
+	}
+	for _, providerName := range market.providers {
+		require.Equal(t, market.pairs, market.providerPairs[providerName])
+	}
+}
+
+func TestSyntheticMarketFetchPrices(t *testing.T) {
+	market := newSyntheticMarket(4, 3)
+	candles, tickers := market.fetchPrices(0)
+
+	require.Len(t, candles, 3)
+	require.Len(t, tickers, 3)
+	for _, providerName := range market.providers {
+		for i, pair := range market.pairs {
+			base := sdk.NewDec(int64(i + 1))
+			low, high := base.Mul(sdk.MustNewDecFromStr("0.99")), base.Mul(sdk.MustNewDecFromStr("1.01"))
+
+			ticker := tickers[providerName][pair.Base]
+			require.True(t, ticker.Price.GTE(low) && ticker.Price.LTE(high), ticker.Price)
+			require.True(t, ticker.Volume.IsPositive())
+
+			require.Len(t, candles[providerName][pair.Base], 2)
+			for _, candle := range candles[providerName][pair.Base] {
+				require.Equal(t, ticker.Price, candle.Price)
+			}
+		}
+	}
+}
+
+func TestSummarizeLatencies(t *testing.T) {
+	require.Equal(t, latencySummary{}, summarizeLatencies(nil))
+
+	// 100ms down to 1ms, the percentiles being taken once sorted
+	latencies := make([]time.Duration, 100)
+	for i := range latencies {
+		latencies[i] = time.Duration(100-i) * time.Millisecond
+	}
+
+	require.Equal(t, latencySummary{
+		Count: 100,
+		P50:   50 * time.Millisecond,
+		P90:   90 * time.Millisecond,
+		P99:   99 * time.Millisecond,
+		Max:   100 * time.Millisecond,
+	}, summarizeLatencies(latencies))
+}
+
+func TestLoadtestResults(t *testing.T) {
+	results := newLoadtestResults(2)
+	results.recordTick(20*time.Millisecond, 5*time.Millisecond)
+	results.recordTick(10*time.Millisecond, 3*time.Millisecond)
+	results.recordAPIRequest(2*time.Millisecond, true)
+	results.recordAPIRequest(time.Second, false)
+	results.recordAPIRequest(4*time.Millisecond, true)
+
+	require.Equal(t, []time.Duration{20 * time.Millisecond, 10 * time.Millisecond}, results.tick)
+	require.Equal(t, []time.Duration{5 * time.Millisecond, 3 * time.Millisecond}, results.aggregation)
+	require.Equal(t, []time.Duration{2 * time.Millisecond, 4 * time.Millisecond}, results.api)
+	require.Equal(t, 1, results.apiErrors)
+
+	var out bytes.Buffer
+	results.print(&out, time.Second, true)
+	require.Contains(t, out.String(), "api requests: 2 (2/s), errors: 1")
+
+	out.Reset()
+	results.print(&out, time.Second, false)
+	require.NotContains(t, out.String(), "api")
+}
+
+func TestRequestPrices(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	require.True(t, requestPrices(context.Background(), srv.Client(), srv.URL))
+
+	status = http.StatusServiceUnavailable
+	require.False(t, requestPrices(context.Background(), srv.Client(), srv.URL))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.False(t, requestPrices(ctx, srv.Client(), srv.URL))
+}
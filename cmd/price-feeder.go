@@ -3,28 +3,46 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/cosmos/cosmos-sdk/telemetry"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
 	"golang.org/x/sync/errgroup"
 
-	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+	"github.com/persistenceOne/oracle-feeder/pkg/archive"
+	"github.com/persistenceOne/oracle-feeder/pkg/features"
+	"github.com/persistenceOne/oracle-feeder/pkg/httputil"
+	"github.com/persistenceOne/oracle-feeder/pkg/logsafe"
+	"github.com/persistenceOne/oracle-feeder/pkg/watchdog"
+	"github.com/persistenceOne/oracle-feeder/router/admin"
 	v1 "github.com/persistenceOne/oracle-feeder/router/v1"
+	v2 "github.com/persistenceOne/oracle-feeder/router/v2"
 
 	"github.com/persistenceOne/oracle-feeder/config"
 	"github.com/persistenceOne/oracle-feeder/oracle"
 	"github.com/persistenceOne/oracle-feeder/oracle/client"
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
 )
 
 const (
-	flagLogLevel  = "log-level"
-	flagLogFormat = "log-format"
+	flagLogLevel    = "log-level"
+	flagLogFormat   = "log-format"
+	flagLogMaxValue = "log-max-value-size"
+
+	flagDebugCaptureDir = "debug-capture-dir"
+
+	// unixSocketMode restricts the API sockets to the user and group of the
+	// feeder, e.g. a monitoring agent sharing its group.
+	unixSocketMode = 0o660
 )
 
 var rootCmd = &cobra.Command{
@@ -37,13 +55,24 @@ two primary functions. First, it is responsible for obtaining price information
 from various reliable data sources, e.g. exchanges, and exposing this data via
 an API. Secondly, the price-feeder consumes this data and periodically submits
 vote and prevote messages following the oracle voting procedure.`,
-	RunE: priceFeederCmdHandler,
+	Annotations:       map[string]string{annotationConfigArg: ""},
+	PersistentPreRunE: setAccountConfig,
+	RunE:              priceFeederCmdHandler,
 }
 
 func init() {
-	setConfig()
 	rootCmd.PersistentFlags().String(flagLogLevel, zerolog.InfoLevel.String(), "logging level")
 	rootCmd.PersistentFlags().String(flagLogFormat, logLevelText, "logging format; must be either json or text")
+	rootCmd.PersistentFlags().Int(
+		flagLogMaxValue,
+		logsafe.DefaultMaxValueBytes,
+		"size in bytes above which logged values are truncated; 0 disables truncation",
+	)
+	rootCmd.Flags().String(
+		flagDebugCaptureDir,
+		"",
+		"enable capturing raw provider payloads to this directory, triggered through the admin API",
+	)
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -67,7 +96,12 @@ func priceFeederCmdHandler(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	logger, err := setUpLogger(logLvlStr, strings.ToLower(logFormatStr))
+	logMaxValue, err := cmd.Flags().GetInt(flagLogMaxValue)
+	if err != nil {
+		return err
+	}
+
+	logger, err := setUpLogger(logLvlStr, strings.ToLower(logFormatStr), logMaxValue)
 	if err != nil {
 		return fmt.Errorf("failed to set up logger: %w", err)
 	}
@@ -77,27 +111,42 @@ func priceFeederCmdHandler(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	cfg = cfg.ApplyPolicy(cmd.Context(), logger)
+	logStartupBanner(logger, cfg)
+
 	err = config.CheckProviderMinimum(cmd.Context(), logger, cfg)
 	if err != nil {
 		return err
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 	g, ctx := errgroup.WithContext(ctx)
 
-	// listen for and trap any OS signal to gracefully shutdown and exit
-	trapSignal(cancel, logger)
-
 	timeout, err := time.ParseDuration(cfg.RPC.RPCTimeout)
 	if err != nil {
 		return fmt.Errorf("failed to parse RPC timeout: %w", err)
 	}
 
-	// env variable precedes the config value
-	keyringPass := os.Getenv(envPriceFeederPass)
+	maxHeightAge, err := time.ParseDuration(cfg.RPC.MaxHeightAge)
+	if err != nil {
+		return fmt.Errorf("failed to parse max block height age: %w", err)
+	}
+
+	featureFlags, err := features.New(cfg.Features)
+	if err != nil {
+		return err
+	}
+
+	// env variable, or the file it points at, precedes the config value
+	keyringPass, _, err := config.LookupEnv(envPriceFeederPass)
+	if err != nil {
+		return err
+	}
 	if len(keyringPass) == 0 {
 		keyringPass = cfg.Keyring.Passphrase
 	}
+	logsafe.AddSecrets(keyringPass)
 
 	oracleClient, err := client.NewOracleClient(
 		ctx,
@@ -109,6 +158,7 @@ func priceFeederCmdHandler(cmd *cobra.Command, args []string) error {
 		cfg.Keyring.PrivKeyHex,
 		cfg.Keyring.Mnemonic,
 		cfg.RPC.TMRPCEndpoint,
+		cfg.RPC.TMRPCFallbackEndpoints,
 		timeout,
 		cfg.Account.Address,
 		cfg.Account.Validator,
@@ -120,23 +170,118 @@ func priceFeederCmdHandler(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	auditTrail, err := newAuditTrail(logger, cfg.Audit)
+	if err != nil {
+		return err
+	}
+	oracleClient.Audit = auditTrail
+	oracleClient.BroadcastMode = cfg.RPC.BroadcastMode
+	oracleClient.Features = featureFlags
+
 	providerTimeout, err := time.ParseDuration(cfg.ProviderTimeout)
 	if err != nil {
 		return fmt.Errorf("failed to parse provider timeout: %w", err)
 	}
 
-	deviations := make(map[string]sdk.Dec, len(cfg.Deviations))
-	for _, deviation := range cfg.Deviations {
-		threshold, err := sdk.NewDecFromStr(deviation.Threshold)
+	httpClientConfig, err := cfg.HTTPClient.Config()
+	if err != nil {
+		return err
+	}
+	provider.SetHTTPClientConfig(httpClientConfig)
+
+	candleWindow, err := newCandleWindow(cfg)
+	if err != nil {
+		return err
+	}
+
+	deviations, err := cfg.DeviationThresholds()
+	if err != nil {
+		return err
+	}
+
+	endpoints, err := cfg.ResolveProviderEndpoints()
+	if err != nil {
+		return err
+	}
+
+	// telemetry must be set up before the counters are loaded so that the
+	// restored values are emitted to the configured sinks
+	metrics, err := telemetry.New(cfg.Telemetry)
+	if err != nil {
+		return fmt.Errorf("failed to set up telemetry: %w", err)
+	}
+
+	dailyBudget, weeklyBudget, err := cfg.Spend.Budgets()
+	if err != nil {
+		return err
+	}
+	spendBudgets := map[string]sdk.Coins{"24h": dailyBudget, "7d": weeklyBudget}
+
+	counters := oracle.NewCounters(logger)
+	spend := oracle.NewSpendTracker(logger, spendBudgets)
+	oracleOpts := []oracle.Option{
+		oracle.WithSLOTracker(oracle.NewSLOTracker(logger, cfg.VoteSLOTarget)),
+		oracle.WithSourceGroups(cfg.SourceGroupByProvider()),
+		oracle.WithCandleWindow(candleWindow),
+		oracle.WithTimeoutMargin(cfg.TimeoutMargin),
+		oracle.WithMaxHeightAge(maxHeightAge),
+		oracle.WithMaxSyncAge(cfg.Server.PriceSyncAge()),
+		oracle.WithCriticalAssetPolicy(cfg.CriticalAssetPolicy),
+		oracle.WithFeatures(featureFlags),
+	}
+	if len(cfg.DataDir) != 0 {
+		counters, err = oracle.LoadCounters(logger, filepath.Join(cfg.DataDir, oracle.CountersFileName))
+		if err != nil {
+			return fmt.Errorf("failed to load counters: %w", err)
+		}
+
+		spend, err = oracle.LoadSpendTracker(logger, filepath.Join(cfg.DataDir, oracle.SpendFileName), spendBudgets)
 		if err != nil {
+			return fmt.Errorf("failed to load spend: %w", err)
+		}
+
+		statePath := filepath.Join(cfg.DataDir, oracle.StateFileName)
+		state, err := oracle.LoadState(statePath)
+		if err != nil {
+			return fmt.Errorf("failed to load oracle state: %w", err)
+		}
+		oracleOpts = append(oracleOpts, oracle.WithState(statePath, state))
+	}
+	oracleOpts = append(oracleOpts, oracle.WithCounters(counters), oracle.WithSpendTracker(spend))
+	oracleClient.Spend = spend
+
+	// the vote latency histogram is gathered along the telemetry metrics
+	latency, err := oracle.NewVoteLatency(logger, prometheus.DefaultRegisterer)
+	if err != nil {
+		return fmt.Errorf("failed to set up vote latency: %w", err)
+	}
+	oracleOpts = append(oracleOpts, oracle.WithVoteLatency(latency))
+	oracleClient.Latency = latency
+
+	var driftMonitor *oracle.DriftMonitor
+	if cfg.Manifest.Enabled() {
+		if driftMonitor, err = oracle.NewDriftMonitor(logger, cfg); err != nil {
 			return err
 		}
-		deviations[deviation.Base] = threshold
+		oracleOpts = append(oracleOpts, oracle.WithDriftMonitor(driftMonitor))
 	}
 
-	endpoints := make(map[provider.Name]provider.Endpoint, len(cfg.ProviderEndpoints))
-	for _, endpoint := range cfg.ProviderEndpoints {
-		endpoints[endpoint.Name] = endpoint
+	if len(cfg.Hub.URL) > 0 {
+		oracleOpts = append(oracleOpts, oracle.WithHub(provider.NewHubClient(ctx, logger, cfg.Hub.URL)))
+	}
+	if len(cfg.VoteOnlyProviders) > 0 {
+		oracleOpts = append(oracleOpts, oracle.WithVoteOnlyProviders(cfg.VoteOnlyProviders))
+	}
+	if epsilon, ok := cfg.UnchangedEpsilon(); ok {
+		oracleOpts = append(oracleOpts, oracle.WithUnchangedEpsilon(epsilon))
+	}
+	if cfg.AnomalyDetection.Enabled() {
+		oracleOpts = append(oracleOpts, oracle.WithAnomalyDetector(oracle.NewAnomalyDetector(
+			logger,
+			cfg.AnomalyDetection.MaxChangeThreshold(),
+			cfg.AnomalyDetection.MaxZScore,
+			cfg.AnomalyDetection.Quorum,
+		)))
 	}
 
 	oracle := oracle.New(
@@ -146,87 +291,309 @@ func priceFeederCmdHandler(cmd *cobra.Command, args []string) error {
 		providerTimeout,
 		deviations,
 		endpoints,
+		oracleOpts...,
 	)
 
+	captureDir, err := cmd.Flags().GetString(flagDebugCaptureDir)
+	if err != nil {
+		return err
+	}
+
+	var capture *provider.PayloadCapture
+	if len(captureDir) > 0 {
+		capture = provider.NewPayloadCapture(captureDir)
+		provider.SetPayloadCapture(capture)
+	}
+
+	var adminSecret []byte
+	if len(cfg.Server.AdminSecret) > 0 {
+		secret, err := config.ResolveSecret(cfg.Server.AdminSecret)
+		if err != nil {
+			return fmt.Errorf("failed to resolve admin secret: %w", err)
+		}
+		adminSecret = []byte(secret)
+	} else {
+		logger.Warn().
+			Str("admin_listen_addr", cfg.Server.AdminListenAddr).
+			Msg("no admin secret configured; local admin requests are not authenticated")
+	}
+
+	adminRouter := admin.New(logger, cfg, args[0], oracle, capture, featureFlags, adminSecret)
+
+	var archiveStores map[string]archive.Store
+	archiveInterval, archiveTimeout, err := cfg.Archive.Durations()
+	if err != nil {
+		return err
+	}
+	if cfg.Archive.Enabled() {
+		if archiveStores, err = newArchiveStores(cfg.Archive, archiveTimeout); err != nil {
+			return err
+		}
+	}
+
+	watchdogLimits, watchdogInterval, err := cfg.Watchdog.Limits()
+	if err != nil {
+		return err
+	}
+
+	// listen for and trap any OS signal to gracefully shutdown and exit: the
+	// oracle is stopped first, which fails /readyz right away, completes the
+	// vote in progress and disconnects the providers, and only then are the
+	// servers drained and shut down
+	trapSignal(cancel, logger, oracle.Stop)
+
 	g.Go(func() error {
 		// start the process that observes and publishes exchange prices
-		return startPriceFeeder(ctx, logger, cfg, oracle)
+		return startPriceFeeder(ctx, logger, cfg, oracle, metrics)
+	})
+	g.Go(func() error {
+		// start the process that serves the admin and debug endpoints
+		return startAdminServer(ctx, logger, cfg, adminRouter)
 	})
 	g.Go(func() error {
 		// start the process that calculates oracle prices and votes
 		return startOracle(ctx, logger, oracle)
 	})
+	g.Go(func() error {
+		// notify systemd of readiness and ping its watchdog, if run as a service
+		return startSystemdNotifier(ctx, logger, oracle)
+	})
+	if len(archiveStores) > 0 {
+		g.Go(func() error {
+			// archive signed price attestations, for third parties to verify
+			return startArchiver(ctx, logger, cfg, oracle, archiveStores, archiveInterval)
+		})
+	}
+
+	if driftMonitor != nil {
+		g.Go(func() error {
+			// report the drift of the config from the recommended manifest
+			return driftMonitor.Start(ctx)
+		})
+	}
+
+	if cfg.Watchdog.Enabled() {
+		var onBreach func()
+		if cfg.Watchdog.RestartProviders {
+			onBreach = oracle.RestartProviders
+		}
+		g.Go(func() error {
+			// dump diagnostics of, and recover from, goroutine and heap leaks
+			return watchdog.New(logger, watchdogLimits, watchdogInterval, cfg.Watchdog.DumpDir, onBreach).Start(ctx)
+		})
+	}
 
 	// Block main process until all spawned goroutines have gracefully exited and
 	// signal has been captured in the main process or if an error occurs.
 	return g.Wait()
 }
 
+// newCandleWindow returns the candle window of the config, with the candle
+// floors overridden per base by the currency pairs.
+func newCandleWindow(cfg config.Config) (oracle.CandleWindow, error) {
+	tvwapPeriod, maxCandleAge, err := cfg.Candles.Durations()
+	if err != nil {
+		return oracle.CandleWindow{}, err
+	}
+	window := oracle.CandleWindow{Period: tvwapPeriod, MaxAge: maxCandleAge, AssetFloors: map[string]oracle.CandleFloors{}}
+
+	if window.Floors.TimeWeight, window.Floors.Volume, err = cfg.Candles.Floors(); err != nil {
+		return oracle.CandleWindow{}, err
+	}
+	for _, cp := range cfg.CurrencyPairs {
+		minTimeWeight, minCandleVolume, err := cp.CandleFloors()
+		if err != nil {
+			return oracle.CandleWindow{}, err
+		}
+		if !minTimeWeight.IsNil() || !minCandleVolume.IsNil() {
+			window.AssetFloors[cp.Base] = oracle.CandleFloors{TimeWeight: minTimeWeight, Volume: minCandleVolume}
+		}
+	}
+
+	return window, nil
+}
+
 // This function is a Go language function that starts a HTTP server that serves as a price feeder.
-// It takes in several parameters including a context, a logger, a config, an oracle, and metrics.
-// It creates a new router for the server using the mux package, creates a new version 1
-// router using the v1 package, and registers the routes for this router on the mux router.
-// Then it parses the write and read timeouts from the config and sets them on the http server.
-// It also creates a channel called srvErrCh that will be used to communicate any errors that occur
-// while starting the server.
-// It starts the server in a goroutine and listens for done events from the context and shutdown the
-// server gracefully when done is received. It also listen for errors from srvErrCh and if any errors
-// occurs it will log them and return it.
+// It takes in several parameters including a context, a logger, a config, an oracle and the telemetry
+// metrics.
+// It creates a new router for the server using the mux package, creates the version 1 and
+// version 2 routers using the v1 and v2 packages, and registers both of them on the mux
+// router, so that the API versions are served concurrently.
+// Then it parses the timeouts from the config and sets them on the http server,
+// which is served until the context is done, see serveHTTP.
 func startPriceFeeder(
 	ctx context.Context,
 	logger zerolog.Logger,
 	cfg config.Config,
 	oracle *oracle.Oracle,
+	metrics *telemetry.Metrics,
 ) error {
 	rtr := mux.NewRouter()
-	v1Router := v1.New(logger, cfg, oracle)
+	rtr.NotFoundHandler = httputil.NotFoundHandler()
+
+	v1Router := v1.New(logger, cfg, oracle, metrics)
 	v1Router.RegisterRoutes(rtr, v1.APIPathPrefix)
 
-	writeTimeout, err := time.ParseDuration(cfg.Server.WriteTimeout)
+	v2Router := v2.New(logger, cfg, oracle)
+	v2Router.RegisterRoutes(rtr, v2.APIPathPrefix)
+
+	srv, err := newHTTPServer(cfg.Server, cfg.Server.ListenAddr, rtr)
 	if err != nil {
 		return err
 	}
 
-	readTimeout, err := time.ParseDuration(cfg.Server.ReadTimeout)
+	return serveHTTP(ctx, logger, cfg.Server, "price-feeder server", srv)
+}
+
+// startAdminServer starts a HTTP server on the configured admin listen address
+// serving only the admin and debug routes, so that they are never reachable
+// through the public listener.
+func startAdminServer(
+	ctx context.Context,
+	logger zerolog.Logger,
+	cfg config.Config,
+	adminRouter *admin.Router,
+) error {
+	rtr := mux.NewRouter()
+	rtr.NotFoundHandler = httputil.NotFoundHandler()
+	adminRouter.RegisterRoutes(rtr, admin.APIPathPrefix)
+
+	srv, err := newHTTPServer(cfg.Server, cfg.Server.AdminListenAddr, rtr)
+	if err != nil {
+		return err
+	}
+
+	return serveHTTP(ctx, logger, cfg.Server, "price-feeder admin server", srv)
+}
+
+// newHTTPServer returns a http.Server listening on addr with the timeouts set
+// in the server config. The connection of each request is stored in its
+// context, so that event streams can extend their write deadline.
+func newHTTPServer(cfg config.Server, addr string, handler http.Handler) (*http.Server, error) {
+	writeTimeout, err := time.ParseDuration(cfg.WriteTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	readTimeout, err := time.ParseDuration(cfg.ReadTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	idleTimeout, err := time.ParseDuration(cfg.IdleTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	readHeaderTimeout, err := time.ParseDuration(cfg.ReadHeaderTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Server{
+		Handler:           handler,
+		Addr:              addr,
+		WriteTimeout:      writeTimeout,
+		ReadTimeout:       readTimeout,
+		IdleTimeout:       idleTimeout,
+		ReadHeaderTimeout: readHeaderTimeout,
+		ConnContext:       httputil.ConnContext,
+	}, nil
+}
+
+// serveHTTP starts the server in a goroutine and listens for done events from the
+// context to shutdown the server gracefully, after the drain period of the server
+// config. It also listens for errors while starting the server and if any occurs
+// it will log and return it.
+func serveHTTP(
+	ctx context.Context,
+	logger zerolog.Logger,
+	cfg config.Server,
+	name string,
+	srv *http.Server,
+) error {
+	drainPeriod, err := time.ParseDuration(cfg.DrainPeriod)
+	if err != nil {
+		return err
+	}
+
+	shutdownTimeout, err := time.ParseDuration(cfg.ShutdownTimeout)
 	if err != nil {
 		return err
 	}
 
 	srvErrCh := make(chan error, 1)
-	srv := &http.Server{
-		Handler:      rtr,
-		Addr:         cfg.Server.ListenAddr,
-		WriteTimeout: writeTimeout,
-		ReadTimeout:  readTimeout,
+
+	listener, err := listen(srv.Addr)
+	if err != nil {
+		logger.Error().Err(err).Msgf("failed to start %s", name)
+		return err
 	}
 
 	go func() {
-		logger.Info().Str("listen_addr", cfg.Server.ListenAddr).Msg("starting price-feeder server...")
-		srvErrCh <- srv.ListenAndServe()
+		logger.Info().Str("listen_addr", srv.Addr).Msgf("starting %s...", name)
+		srvErrCh <- srv.Serve(listener)
 	}()
 
 	for {
 		select {
 		case <-ctx.Done():
-			shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second) //nolint:gomnd //const
-			// no need to make const
+			if drainPeriod > 0 {
+				// the oracle is stopped by now, so /readyz fails while draining
+				logger.Info().Dur("drain_period", drainPeriod).Msgf("draining %s...", name)
+				select {
+				case <-time.After(drainPeriod):
+				case err := <-srvErrCh:
+					logger.Error().Err(err).Msgf("%s failed while draining", name)
+					return err
+				}
+			}
+
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 			defer cancel()
 
-			logger.Info().Str("listen_addr", cfg.Server.ListenAddr).Msg("shutting down price-feeder server...")
+			logger.Info().Str("listen_addr", srv.Addr).Msgf("shutting down %s...", name)
 			if err := srv.Shutdown(shutdownCtx); err != nil {
-				logger.Error().Err(err).Msg("failed to gracefully shutdown price-feeder server")
+				logger.Error().Err(err).Msgf("failed to gracefully shutdown %s", name)
 				return err
 			}
 
 			return nil
 
 		case err := <-srvErrCh:
-			logger.Error().Err(err).Msg("failed to start price-feeder server")
+			logger.Error().Err(err).Msgf("failed to start %s", name)
 			return err
 		}
 	}
 }
 
+// listen returns a listener on addr, either a TCP address or a unix socket,
+// see config.Server. A socket left behind by an unclean exit is replaced and
+// the socket is only accessible to the user and group of the feeder.
+func listen(addr string) (net.Listener, error) {
+	path, ok := config.UnixSocketPath(addr)
+	if !ok {
+		return net.Listen("tcp", addr)
+	}
+
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, unixSocketMode); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+
+	return listener, nil
+}
+
 // This function is a Go language function that starts an oracle for a price feeder.
 // It takes in three parameters including a context, a logger, and an oracle.
 // It creates a channel called srvErrCh that will be used to communicate
@@ -251,9 +618,14 @@ func startOracle(ctx context.Context, logger zerolog.Logger, oracle *oracle.Orac
 		select {
 		case <-ctx.Done():
 			logger.Info().Msg("shutting down price-feeder oracle...")
+			oracle.Stop()
 			return nil
 
 		case err := <-srvErrCh:
+			if err == nil {
+				logger.Info().Msg("price-feeder oracle stopped")
+				return nil
+			}
 			logger.Err(err).Msg("error starting the price-feeder oracle")
 			oracle.Stop()
 			return err
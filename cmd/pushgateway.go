@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagPushgateway    = "pushgateway"
+	flagPushgatewayJob = "pushgateway-job"
+
+	// metricsNamespace prefixes the metrics pushed by ephemeral runs.
+	metricsNamespace = "price_feeder"
+)
+
+// addPushgatewayFlags adds the flags pushing the metrics of an ephemeral run,
+// e.g. in CI, to a Prometheus pushgateway.
+func addPushgatewayFlags(cmd *cobra.Command, defaultJob string) {
+	cmd.Flags().String(flagPushgateway, "", "URL of a Prometheus pushgateway to push the resulting metrics to")
+	cmd.Flags().String(flagPushgatewayJob, defaultJob, "job the metrics are pushed as to the pushgateway")
+}
+
+// pushMetrics replaces the metrics of the job and grouping labels on the
+// pushgateway set by the flags of cmd with the collectors, if any is set.
+func pushMetrics(cmd *cobra.Command, grouping map[string]string, collectors ...prometheus.Collector) error {
+	url, err := cmd.Flags().GetString(flagPushgateway)
+	if err != nil || len(url) == 0 {
+		return err
+	}
+	job, err := cmd.Flags().GetString(flagPushgatewayJob)
+	if err != nil {
+		return err
+	}
+
+	pusher := push.New(url, job)
+	for name, value := range grouping {
+		pusher = pusher.Grouping(name, value)
+	}
+	for _, collector := range collectors {
+		pusher = pusher.Collector(collector)
+	}
+	if err := pusher.PushContext(cmd.Context()); err != nil {
+		return fmt.Errorf("failed to push metrics to %s: %w", url, err)
+	}
+
+	return nil
+}
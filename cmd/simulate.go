@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/cobra"
+
+	"github.com/persistenceOne/oracle-feeder/oracle"
+)
+
+const (
+	flagSimulateVotePeriod        = "vote-period"
+	flagSimulateRewardBand        = "reward-band"
+	flagSimulateSlashWindow       = "slash-window"
+	flagSimulateMinValidPerWindow = "min-valid-per-window"
+	flagSimulateFormat            = "format"
+
+	// maxSimulationRecordBytes bounds the size of a line of a history file.
+	maxSimulationRecordBytes = 1 << 20
+)
+
+func init() {
+	rootCmd.AddCommand(simulateCmd)
+
+	simulateCmd.Flags().Int64(flagSimulateVotePeriod, 0, "vote period in blocks")
+	simulateCmd.Flags().String(flagSimulateRewardBand, "", "reward band, e.g. 0.02")
+	simulateCmd.Flags().Int64(flagSimulateSlashWindow, 0, "slash window in blocks; no windows are reported if 0")
+	simulateCmd.Flags().String(flagSimulateMinValidPerWindow, "", "minimum ratio of valid votes per slash window")
+	simulateCmd.Flags().String(flagSimulateFormat, coverageFormatText, "report format; must be either json or text")
+	addPushgatewayFlags(simulateCmd, "price_feeder_simulate")
+	_ = simulateCmd.MarkFlagRequired(flagSimulateVotePeriod)
+	_ = simulateCmd.MarkFlagRequired(flagSimulateRewardBand)
+}
+
+var simulateCmd = &cobra.Command{
+	Use:   "simulate [history-file]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Simulate the rewards and misses of the feeder under hypothetical x/oracle params",
+	Long: `Replay a recorded history of the prices of the feeder and the exchange rates
+of the chain under a hypothetical vote period and reward band, and report how
+many vote periods the feeder would have been rewarded for or missed, per asset
+and overall. The history is a file of JSON lines, one per block height:
+
+  {"height":100,"feed":{"ATOM":"10.01"},"median":{"ATOM":"10.00"}}
+
+Votes are tallied at the end of every vote period against the recorded median,
+with the feed prices recorded a vote period earlier, when they were pre-voted.
+Use it to back governance discussions about param changes. No chain
+connection is needed.
+
+With --pushgateway, the outcome is also pushed to a Prometheus pushgateway,
+grouped by vote period and reward band, so that runs in CI track the feed
+quality over time.`,
+	RunE: simulateCmdHandler,
+}
+
+func simulateCmdHandler(cmd *cobra.Command, args []string) error {
+	params, err := simulationParams(cmd)
+	if err != nil {
+		return err
+	}
+	format, err := cmd.Flags().GetString(flagSimulateFormat)
+	if err != nil {
+		return err
+	}
+	if format != coverageFormatText && format != coverageFormatJSON {
+		return fmt.Errorf("format must be either json or text")
+	}
+
+	records, err := readSimulationRecords(args[0])
+	if err != nil {
+		return err
+	}
+
+	report, err := oracle.Simulate(records, params)
+	if err != nil {
+		return err
+	}
+
+	grouping := map[string]string{
+		"vote_period": fmt.Sprint(params.VotePeriod),
+		"reward_band": params.RewardBand.String(),
+	}
+	if err := pushMetrics(cmd, grouping, simulationCollectors(report)...); err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if format == coverageFormatJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	printSimulationReport(out, report)
+	return nil
+}
+
+// simulationParams returns the simulated params set by the flags.
+func simulationParams(cmd *cobra.Command) (oracle.SimulationParams, error) {
+	var (
+		params oracle.SimulationParams
+		err    error
+	)
+	if params.VotePeriod, err = cmd.Flags().GetInt64(flagSimulateVotePeriod); err != nil {
+		return params, err
+	}
+	if params.SlashWindow, err = cmd.Flags().GetInt64(flagSimulateSlashWindow); err != nil {
+		return params, err
+	}
+
+	rewardBand, err := cmd.Flags().GetString(flagSimulateRewardBand)
+	if err != nil {
+		return params, err
+	}
+	if params.RewardBand, err = sdk.NewDecFromStr(rewardBand); err != nil {
+		return params, fmt.Errorf("failed to parse reward band: %w", err)
+	}
+
+	minValidPerWindow, err := cmd.Flags().GetString(flagSimulateMinValidPerWindow)
+	if err != nil {
+		return params, err
+	}
+	if len(minValidPerWindow) > 0 {
+		if params.MinValidPerWindow, err = sdk.NewDecFromStr(minValidPerWindow); err != nil {
+			return params, fmt.Errorf("failed to parse min valid per window: %w", err)
+		}
+	}
+
+	return params, nil
+}
+
+// readSimulationRecords reads the JSON lines history file at path.
+func readSimulationRecords(path string) ([]oracle.SimulationRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	var records []oracle.SimulationRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxSimulationRecordBytes)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var record oracle.SimulationRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("failed to decode history line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	return records, nil
+}
+
+// printSimulationReport prints the report as text, one line per asset.
+func printSimulationReport(out io.Writer, report oracle.SimulationReport) {
+	fmt.Fprintf(out, "vote period: %d blocks, reward band: %s\n", report.Params.VotePeriod, report.Params.RewardBand)
+	fmt.Fprintf(out, "periods: %d, rewarded: %d, missed: %d, miss rate: %s\n",
+		report.Periods, report.Rewarded, report.Missed, report.MissRate)
+	if report.Params.SlashWindow > 0 {
+		fmt.Fprintf(out, "slashable windows: %d of %d\n", report.SlashableWindows, report.Windows)
+	}
+
+	fmt.Fprintf(out, "\n%-10s %8s %8s %12s %8s %14s\n",
+		"asset", "votes", "in band", "out of band", "missing", "max deviation")
+	for _, asset := range report.Assets {
+		fmt.Fprintf(out, "%-10s %8d %8d %12d %8d %14s\n",
+			asset.Asset, asset.Votes, asset.InBand, asset.OutOfBand, asset.Missing, asset.MaxDeviation)
+	}
+}
+
+// simulationCollectors returns the gauges of the outcome of the report.
+func simulationCollectors(report oracle.SimulationReport) []prometheus.Collector {
+	gauge := func(name, help string, value float64) prometheus.Gauge {
+		g := prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "simulation",
+			Name:      name,
+			Help:      help,
+		})
+		g.Set(value)
+		return g
+	}
+	assetGauge := func(name, help string) *prometheus.GaugeVec {
+		return prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "simulation_asset",
+			Name:      name,
+			Help:      help,
+		}, []string{"asset"})
+	}
+
+	var (
+		inBand       = assetGauge("in_band", "Simulated votes of the asset within the reward band.")
+		outOfBand    = assetGauge("out_of_band", "Simulated votes of the asset outside the reward band.")
+		missing      = assetGauge("missing", "Simulated vote periods without a price of the asset.")
+		maxDeviation = assetGauge("max_deviation", "Largest relative deviation of a simulated vote from the median.")
+	)
+	for _, asset := range report.Assets {
+		inBand.WithLabelValues(asset.Asset).Set(float64(asset.InBand))
+		outOfBand.WithLabelValues(asset.Asset).Set(float64(asset.OutOfBand))
+		missing.WithLabelValues(asset.Asset).Set(float64(asset.Missing))
+		maxDeviation.WithLabelValues(asset.Asset).Set(asset.MaxDeviation.MustFloat64())
+	}
+
+	return []prometheus.Collector{
+		gauge("periods", "Simulated vote periods.", float64(report.Periods)),
+		gauge("rewarded", "Simulated vote periods rewarded.", float64(report.Rewarded)),
+		gauge("missed", "Simulated vote periods missed.", float64(report.Missed)),
+		gauge("miss_rate", "Ratio of the simulated vote periods missed.", report.MissRate.MustFloat64()),
+		gauge("slashable_windows", "Simulated slash windows below the minimum valid votes.",
+			float64(report.SlashableWindows)),
+		inBand, outOfBand, missing, maxDeviation,
+	}
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/persistenceOne/oracle-feeder/config"
+	"github.com/persistenceOne/oracle-feeder/oracle"
+)
+
+const (
+	flagStateForce = "force"
+
+	// maxStateFileBytes bounds the size of a file extracted from a state
+	// archive.
+	maxStateFileBytes = 64 << 20
+)
+
+// stateFileNames are the files of the data directory making up the feeder
+// runtime state.
+var stateFileNames = []string{oracle.StateFileName, oracle.CountersFileName, oracle.SpendFileName}
+
+func init() {
+	rootCmd.AddCommand(stateCmd)
+	stateCmd.AddCommand(stateExportCmd, stateImportCmd)
+
+	stateImportCmd.Flags().Bool(flagStateForce, false, "overwrite the state already in the data directory")
+}
+
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Export or import the feeder runtime state",
+	Long: `Export or import the feeder runtime state persisted to the data directory:
+the pending pre-vote, the price history, the learned provider weights, the
+cumulative counters and the spend of the feeder account. Stop the feeder before exporting so the exported state is
+final, and import it on the new host before starting the feeder there.`,
+}
+
+var stateExportCmd = &cobra.Command{
+	Use:         "export [config-file] [archive]",
+	Args:        cobra.ExactArgs(2),
+	Short:       "Export the runtime state of the data directory to a tar.gz archive",
+	Annotations: map[string]string{annotationConfigArg: ""},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dataDir, err := stateDataDir(args[0])
+		if err != nil {
+			return err
+		}
+
+		return exportState(dataDir, args[1])
+	},
+}
+
+var stateImportCmd = &cobra.Command{
+	Use:         "import [config-file] [archive]",
+	Args:        cobra.ExactArgs(2),
+	Short:       "Import the runtime state of a tar.gz archive to the data directory",
+	Annotations: map[string]string{annotationConfigArg: ""},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		force, err := cmd.Flags().GetBool(flagStateForce)
+		if err != nil {
+			return err
+		}
+
+		dataDir, err := stateDataDir(args[0])
+		if err != nil {
+			return err
+		}
+
+		return importState(dataDir, args[1], force)
+	},
+}
+
+// stateDataDir returns the data directory of the config at configPath.
+func stateDataDir(configPath string) (string, error) {
+	cfg, err := config.ParseConfig(configPath)
+	if err != nil {
+		return "", err
+	}
+	if len(cfg.DataDir) == 0 {
+		return "", fmt.Errorf("no data_dir configured, the feeder state is not persisted")
+	}
+
+	return cfg.DataDir, nil
+}
+
+// exportState writes the state files of dataDir to a new archive at path.
+func exportState(dataDir, path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+
+	exported := 0
+	for _, name := range stateFileNames {
+		bz, err := os.ReadFile(filepath.Join(dataDir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		hdr := &tar.Header{Name: name, Mode: 0o600, Size: int64(len(bz)), ModTime: time.Now()}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(bz); err != nil {
+			return err
+		}
+		exported++
+	}
+
+	if exported == 0 {
+		return fmt.Errorf("no state found in %s", dataDir)
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("exported %d state files to %s\n", exported, path)
+	return f.Sync()
+}
+
+// importState extracts the state files of the archive at path to dataDir,
+// refusing to overwrite an existing state unless force is set.
+func importState(dataDir, path string, force bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+	defer gr.Close()
+
+	known := make(map[string]struct{}, len(stateFileNames))
+	for _, name := range stateFileNames {
+		known[name] = struct{}{}
+	}
+
+	// read and validate the whole archive before writing anything
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		if _, ok := known[hdr.Name]; !ok || hdr.Typeflag != tar.TypeReg {
+			return fmt.Errorf("unexpected file in archive: %s", hdr.Name)
+		}
+
+		bz, err := io.ReadAll(io.LimitReader(tr, maxStateFileBytes))
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+		if !json.Valid(bz) {
+			return fmt.Errorf("invalid state file in archive: %s", hdr.Name)
+		}
+		files[hdr.Name] = bz
+	}
+
+	if !force {
+		for name := range files {
+			if _, err := os.Stat(filepath.Join(dataDir, name)); err == nil {
+				return fmt.Errorf("%s already exists in %s, use --%s to overwrite it", name, dataDir, flagStateForce)
+			}
+		}
+	}
+
+	if err := os.MkdirAll(dataDir, 0o700); err != nil {
+		return err
+	}
+	for name, bz := range files {
+		if err := os.WriteFile(filepath.Join(dataDir, name), bz, 0o600); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("imported %d state files to %s\n", len(files), dataDir)
+	return nil
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/persistenceOne/oracle-feeder/oracle"
+	"github.com/persistenceOne/oracle-feeder/pkg/sdnotify"
+)
+
+// readyPollInterval is how often the first oracle tick is checked for before
+// notifying systemd that the feeder is ready.
+const readyPollInterval = time.Second
+
+// startSystemdNotifier notifies systemd once the first oracle tick completed
+// and, if the unit sets WatchdogSec, pings the watchdog at half its interval
+// as long as the tick loop keeps making progress. A stalled tick loop stops
+// the pings so that systemd restarts the feeder. It is a no-op when not run
+// by systemd with Type=notify.
+func startSystemdNotifier(ctx context.Context, logger zerolog.Logger, oracle *oracle.Oracle) error {
+	logger = logger.With().Str("module", "systemd").Logger()
+
+	ticker := time.NewTicker(readyPollInterval)
+	defer ticker.Stop()
+
+	for oracle.GetLastPriceSyncTimestamp().IsZero() {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+
+	sent, err := sdnotify.Notify(sdnotify.Ready)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to notify systemd")
+		return nil
+	}
+	if !sent {
+		return nil
+	}
+	logger.Info().Msg("notified systemd that the feeder is ready")
+
+	interval := sdnotify.WatchdogInterval()
+	if interval == 0 {
+		<-ctx.Done()
+		_, _ = sdnotify.Notify(sdnotify.Stopping)
+		return nil
+	}
+
+	ticker.Reset(interval / 2) //nolint:gomnd //ping twice per watchdog interval as sd_watchdog_enabled(3) advises
+	for {
+		select {
+		case <-ctx.Done():
+			_, _ = sdnotify.Notify(sdnotify.Stopping)
+			return nil
+
+		case <-ticker.C:
+			lastSync := oracle.GetLastPriceSyncTimestamp()
+			if time.Since(lastSync) > interval {
+				logger.Warn().Time("last_sync", lastSync).Msg("oracle tick loop stalled; skipping watchdog ping")
+				continue
+			}
+			if _, err := sdnotify.Notify(sdnotify.Watchdog); err != nil {
+				logger.Error().Err(err).Msg("failed to ping systemd watchdog")
+			}
+		}
+	}
+}
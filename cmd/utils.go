@@ -10,8 +10,10 @@ import (
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
 
-	"github.com/persistenceOne/persistenceCore/v8/app"
+	"github.com/persistenceOne/oracle-feeder/config"
+	"github.com/persistenceOne/oracle-feeder/pkg/logsafe"
 )
 
 const (
@@ -21,20 +23,57 @@ const (
 	envPriceFeederPass = "ORACLE_FEEDER_KEY_PASSPHRASE" // #nosec G101
 )
 
-// setConfig params at the package state.
-func setConfig() {
-	cfg := sdk.GetConfig()
+// bip44Purpose is the BIP44 purpose of the derivation path of the feeder key.
+const bip44Purpose = 44
 
-	cfg.SetBech32PrefixForAccount(app.Bech32PrefixAccAddr, app.Bech32PrefixAccPub)
-	cfg.SetBech32PrefixForValidator(app.Bech32PrefixValAddr, app.Bech32PrefixValPub)
-	cfg.SetBech32PrefixForConsensusNode(app.Bech32PrefixConsAddr, app.Bech32PrefixConsPub)
-	cfg.SetCoinType(app.CoinType)
-	cfg.SetPurpose(app.Purpose)
+// annotationConfigArg annotates the commands whose first argument is the
+// config file, see setAccountConfig.
+const annotationConfigArg = "config_arg"
+
+// setAccountConfig is run before every command to set the bech32 prefixes
+// and coin type of the chain: those of the account of the config file for
+// the commands annotated with annotationConfigArg, Persistence's otherwise.
+func setAccountConfig(cmd *cobra.Command, args []string) error {
+	account := config.DefaultAccount()
+	if _, ok := cmd.Annotations[annotationConfigArg]; ok && len(args) > 0 {
+		cfg, err := config.ParseConfig(args[0])
+		if err != nil {
+			return err
+		}
+		account = cfg.Account
+	}
+
+	setConfig(account)
+	return nil
+}
+
+// setConfig sets the bech32 prefixes and coin type of the account at the
+// package state.
+func setConfig(account config.Account) {
+	var (
+		cfg    = sdk.GetConfig()
+		prefix = account.Bech32Prefix
+	)
+
+	cfg.SetBech32PrefixForAccount(prefix, prefix+sdk.PrefixPublic)
+	cfg.SetBech32PrefixForValidator(
+		prefix+sdk.PrefixValidator+sdk.PrefixOperator,
+		prefix+sdk.PrefixValidator+sdk.PrefixOperator+sdk.PrefixPublic,
+	)
+	cfg.SetBech32PrefixForConsensusNode(
+		prefix+sdk.PrefixValidator+sdk.PrefixConsensus,
+		prefix+sdk.PrefixValidator+sdk.PrefixConsensus+sdk.PrefixPublic,
+	)
+	cfg.SetCoinType(account.CoinType)
+	cfg.SetPurpose(bip44Purpose)
 
 	cfg.Seal()
 }
 
-func setUpLogger(logLevel string, logFormat string) (zerolog.Logger, error) {
+// setUpLogger returns a logger to stderr whose events are redacted of the
+// secrets and have their values above logMaxValue bytes truncated, see
+// logsafe.Writer.
+func setUpLogger(logLevel string, logFormat string, logMaxValue int) (zerolog.Logger, error) {
 	logLvl, err := zerolog.ParseLevel(logLevel)
 	if err != nil {
 		return zerolog.Logger{}, err
@@ -51,12 +90,14 @@ func setUpLogger(logLevel string, logFormat string) (zerolog.Logger, error) {
 		return zerolog.Logger{}, fmt.Errorf("invalid logging format: %s", logFormat)
 	}
 
+	logWriter = logsafe.NewWriter(logWriter, logMaxValue)
+
 	return zerolog.New(logWriter).Level(logLvl).With().Timestamp().Logger(), nil
 }
 
-// trapSignal will listen for any OS signal and invoke Done on the main
-// WaitGroup allowing the main process to gracefully exit.
-func trapSignal(cancel context.CancelFunc, logger zerolog.Logger) {
+// trapSignal will listen for any OS signal, call stop and then invoke cancel
+// allowing the main process to gracefully exit.
+func trapSignal(cancel context.CancelFunc, logger zerolog.Logger, stop func()) {
 	sigCh := make(chan os.Signal, 1)
 
 	signal.Notify(sigCh, syscall.SIGTERM)
@@ -65,6 +106,7 @@ func trapSignal(cancel context.CancelFunc, logger zerolog.Logger) {
 	go func() {
 		sig := <-sigCh
 		logger.Info().Str("signal", sig.String()).Msg("received signal; shutting down...")
+		stop()
 		cancel()
 	}()
 }
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/client"
+	"github.com/persistenceOne/oracle-feeder/pkg/keyring"
+)
+
+// testMnemonic derives persistence1hxtvkgpfy65lehgrs50u7deqflylnu498g8f8v at
+// m/44'/750'/0'/0/0, and persistence1t6dq82wyggtmu2cvegyat9et7uans46n9vfmj2 at
+// the m/44'/118'/0'/0/0 path of the Cosmos Hub.
+const testMnemonic = "toddler gossip soap crop property true off record horn route enable raise produce " +
+	"wheat mango social output ritual pond powder test biology address romance"
+
+func TestSetAccountConfig(t *testing.T) {
+	// commands without a config file, e.g. loadtest, get Persistence's
+	// prefixes and coin type
+	require.NoError(t, setAccountConfig(loadtestCmd, nil))
+
+	address, _, err := keyring.NewCosmosKeyring(
+		client.MakeEncodingConfig().Marshaler,
+		keyring.WithMnemonic(testMnemonic),
+	)
+	require.NoError(t, err)
+	require.Equal(t, "persistence1hxtvkgpfy65lehgrs50u7deqflylnu498g8f8v", address.String())
+}
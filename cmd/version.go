@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+
+	"github.com/persistenceOne/oracle-feeder/config"
+)
+
+// Build information, set via ldflags, see the Makefile.
+var (
+	Version    = ""
+	Commit     = ""
+	SDKVersion = ""
+)
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Args:  cobra.NoArgs,
+	Short: "Print the price-feeder version information",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Printf("version: %s\ncommit: %s\nsdk: %s\ngo: %s\n", Version, Commit, SDKVersion, runtime.Version())
+	},
+}
+
+// logStartupBanner logs the build information and a summary of the loaded
+// configuration, so that operators can verify from the logs what a running
+// instance is using. Secrets are never logged, the full sanitized config is
+// served on /api/v1/config.
+func logStartupBanner(logger zerolog.Logger, cfg config.Config) {
+	pairs := make([]string, 0, len(cfg.CurrencyPairs))
+	for _, pair := range cfg.CurrencyPairs {
+		pairs = append(pairs, fmt.Sprintf("%s/%s%v", pair.Base, pair.Quote, pair.Providers))
+	}
+
+	logger.Info().
+		Str("version", Version).
+		Str("commit", Commit).
+		Str("sdk_version", SDKVersion).
+		Str("go_version", runtime.Version()).
+		Str("chain_id", cfg.Account.ChainID).
+		Str("feeder", cfg.Account.Address).
+		Str("validator", cfg.Account.Validator).
+		Str("listen_addr", cfg.Server.ListenAddr).
+		Str("provider_timeout", cfg.ProviderTimeout).
+		Strs("currency_pairs", pairs).
+		Msg("starting price-feeder")
+}
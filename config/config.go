@@ -4,25 +4,71 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"strings"
 	"time"
 
+	"github.com/cosmos/cosmos-sdk/telemetry"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/go-playground/validator/v10"
 	"github.com/rs/zerolog"
 	"github.com/spf13/viper"
 
 	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+	"github.com/persistenceOne/oracle-feeder/pkg/features"
+	"github.com/persistenceOne/oracle-feeder/pkg/logsafe"
+	"github.com/persistenceOne/oracle-feeder/pkg/watchdog"
 )
 
 const (
 	DenomUSD = "USD"
+	DenomKRW = "KRW"
+	DenomEUR = "EUR"
+	DenomGBP = "GBP"
+	DenomJPY = "JPY"
+	DenomCHF = "CHF"
+	DenomBTC = "BTC"
+	DenomETH = "ETH"
+
+	// RoundingRound and RoundingTruncate are the ways a price is reduced to
+	// the precision of its asset, see CurrencyPair.
+	RoundingRound    = "round"
+	RoundingTruncate = "truncate"
+
+	// PriorityCritical and PriorityBestEffort are the priorities of the
+	// assets, see CurrencyPair.
+	PriorityCritical   = "critical"
+	PriorityBestEffort = "best_effort"
+
+	// CriticalAssetSkip and CriticalAssetAbstain are the policies applied
+	// when the price of a critical asset is missing, see CriticalAssetPolicy.
+	CriticalAssetSkip    = "skip"
+	CriticalAssetAbstain = "abstain"
 
 	defaultListenAddr      = "0.0.0.0:7171"
+	defaultAdminListenAddr = "127.0.0.1:7172"
+	unixSocketPrefix       = "unix://"
 	defaultSrvWriteTimeout = 15 * time.Second
 	defaultSrvReadTimeout  = 15 * time.Second
+	defaultSrvIdleTimeout  = 60 * time.Second
+	defaultSrvReadHeader   = 5 * time.Second
+	defaultSrvMaxBodyBytes = 1 << 20 // 1 MiB
+	defaultSrvShutdown     = 15 * time.Second
+	defaultSrvMaxSyncAge   = 2 * time.Minute
 	defaultProviderTimeout = 100 * time.Millisecond
 	defaultUXPRTFees       = "50uxprt"
+	defaultBroadcastMode   = "sync"
+	defaultMaxHeightAge    = 15 * time.Second
+	defaultAnomalyQuorum   = 2
+	defaultCandlePeriod    = 5 * time.Minute
+	defaultAuditTimeout    = 2 * time.Second
+	defaultArchiveInterval = time.Hour
+	defaultArchiveTimeout  = 30 * time.Second
+	defaultWatchdogPeriod  = time.Minute
+	defaultManifestPeriod  = time.Hour
+	defaultBech32Prefix    = "persistence"
+	defaultCoinType        = 750
 )
 
 var (
@@ -32,52 +78,239 @@ var (
 	ErrEmptyConfigPath = errors.New("empty configuration file path")
 
 	// SupportedProviders defines a lookup table of all the supported currency API
-	// providers.
-	SupportedProviders = map[provider.Name]struct{}{
-		provider.Kraken:    {},
-		provider.Binance:   {},
-		provider.BinanceUS: {},
-		provider.Osmosis:   {},
-		provider.Crypto:    {},
-		provider.Coinbase:  {},
-		provider.Huobi:     {},
-		provider.Mock:      {},
-	}
+	// providers, those registered in the binary, see provider.Register.
+	SupportedProviders = supportedProviders()
 
 	// maxDeviationThreshold is the maxmimum allowed amount of standard
 	// deviations which validators are able to set for a given asset.
 	maxDeviationThreshold = sdk.MustNewDecFromStr("3.0")
 
 	// SupportedQuotes defines a lookup table for which assets we support
-	// using as quotes.
+	// using as quotes. Prices in a quote other than USD are converted with
+	// the rate of a <quote>/USD pair, e.g. KRW/USD, which the ecb and
+	// exchangeratehost forex providers serve for fiat quotes. BTC and ETH
+	// quote the long-tail assets only listed against them, and are converted
+	// with BTC/USD and ETH/USD feeds priced by the exchanges.
 	SupportedQuotes = map[string]struct{}{
 		DenomUSD: {},
+		DenomKRW: {},
+		DenomEUR: {},
+		DenomGBP: {},
+		DenomJPY: {},
+		DenomCHF: {},
+		DenomBTC: {},
+		DenomETH: {},
 	}
 )
 
 type (
 	// Config defines all necessary price-feeder configuration parameters.
 	Config struct {
-		Server              Server              `mapstructure:"server"`
-		CurrencyPairs       []CurrencyPair      `mapstructure:"currency_pairs" validate:"required,gt=0,dive,required"`
-		Deviations          []Deviation         `mapstructure:"deviation_thresholds"`
-		Account             Account             `mapstructure:"account" validate:"required,gt=0,dive,required"`
-		Keyring             Keyring             `mapstructure:"keyring" validate:"required,gt=0,dive,required"`
-		RPC                 RPC                 `mapstructure:"rpc" validate:"required,gt=0,dive,required"`
-		GasAdjustment       float64             `mapstructure:"gas_adjustment" validate:"required"`
-		ProviderTimeout     string              `mapstructure:"provider_timeout"`
-		ProviderMinOverride bool                `mapstructure:"provider_min_override"`
-		ProviderEndpoints   []provider.Endpoint `mapstructure:"provider_endpoints" validate:"dive"`
-		Fees                string              `mapstructure:"fees"`
-	}
-
-	// Server defines the API server configuration.
+		Server              Server               `mapstructure:"server"`
+		CurrencyPairs       []CurrencyPair       `mapstructure:"currency_pairs" validate:"required,gt=0,dive,required"`
+		Deviations          []Deviation          `mapstructure:"deviation_thresholds"`
+		Account             Account              `mapstructure:"account" validate:"required,gt=0,dive,required"`
+		Keyring             Keyring              `mapstructure:"keyring" validate:"required,gt=0,dive,required"`
+		RPC                 RPC                  `mapstructure:"rpc" validate:"required,gt=0,dive,required"`
+		GasAdjustment       float64              `mapstructure:"gas_adjustment" validate:"required"`
+		ProviderTimeout     string               `mapstructure:"provider_timeout"`
+		ProviderMinOverride bool                 `mapstructure:"provider_min_override"`
+		ProviderEndpoints   []provider.Endpoint  `mapstructure:"provider_endpoints" validate:"dive"`
+		ProviderCredentials []ProviderCredential `mapstructure:"provider_credentials" validate:"dive"`
+		SourceGroups        []SourceGroup        `mapstructure:"source_groups" validate:"dive"`
+		VoteOnlyProviders   []provider.Name      `mapstructure:"vote_only_providers"`
+		Fees                string               `mapstructure:"fees"`
+		TimeoutMargin       int64                `mapstructure:"timeout_margin" validate:"gte=0"`
+		Telemetry           telemetry.Config     `mapstructure:"telemetry"`
+		DataDir             string               `mapstructure:"data_dir"`
+		VoteSLOTarget       float64              `mapstructure:"vote_slo_target" validate:"gte=0,lte=1"`
+		Policy              PolicyConfig         `mapstructure:"policy"`
+		AnomalyDetection    AnomalyDetection     `mapstructure:"anomaly_detection"`
+		Candles             Candles              `mapstructure:"candles"`
+		Audit               Audit                `mapstructure:"audit"`
+		HTTPClient          HTTPClient           `mapstructure:"http_client"`
+		Hub                 Hub                  `mapstructure:"hub"`
+		Spend               Spend                `mapstructure:"spend"`
+		Archive             Archive              `mapstructure:"archive"`
+		Watchdog            Watchdog             `mapstructure:"watchdog"`
+		Manifest            Manifest             `mapstructure:"manifest"`
+
+		// VoteUnchangedEpsilon is the relative price change, e.g. "0.0001",
+		// below which the prices are kept instead of being aggregated again
+		// and the exchange rates of the last pre-vote are pre-voted again
+		// instead of being recomputed, see UnchangedEpsilon.
+		VoteUnchangedEpsilon string `mapstructure:"vote_unchanged_epsilon"`
+
+		// CriticalAssetPolicy is applied when the price of a critical asset is
+		// missing: "skip", the default, doesn't pre-vote until it is priced
+		// again, and "abstain" pre-votes the other assets without it.
+		CriticalAssetPolicy string `mapstructure:"critical_asset_policy" validate:"omitempty,oneof=skip abstain"`
+
+		// Features overrides the default state of the feature flags gating
+		// experimental subsystems, see package features. They can be toggled
+		// at runtime through the admin API, until the next restart.
+		Features map[string]bool `mapstructure:"features"`
+
+		// PolicyMinProviders holds the provider minimums of the remote policy,
+		// see ApplyPolicy.
+		PolicyMinProviders map[string]int `mapstructure:"-"`
+	}
+
+	// Server defines the API server configuration. The admin and debug
+	// endpoints are never served on the public listener but on
+	// AdminListenAddr, the loopback interface by default. When AdminSecret
+	// references a secret, see ResolveSecret, admin requests must be signed
+	// with it, which is required unless AdminListenAddr is a loopback address
+	// or a unix socket.
+	//
+	// Both addresses are either a TCP "host:port" address or the path of a unix
+	// socket prefixed by "unix://", e.g. to expose the API to local monitoring
+	// agents only without opening a TCP port.
+	//
+	// IdleTimeout, ReadHeaderTimeout and MaxBodyBytes bound how long and how
+	// much a client can hold on to the server, which protects instances
+	// exposed on public validator IPs against slowloris-style abuse.
+	//
+	// On shutdown, the servers keep serving for DrainPeriod while /readyz
+	// fails, so that load balancers stop routing to the instance before it
+	// refuses connections, and are then given ShutdownTimeout to complete the
+	// requests in flight.
+	//
+	// Once the last price sync is older than MaxSyncAge, /healthz and /readyz
+	// report the oracle as stale and fail with a 503, so that orchestrators
+	// and load balancers can act on it, and the price routes stop serving the
+	// stale prices. "0s" disables the check.
 	Server struct {
-		ListenAddr     string   `mapstructure:"listen_addr"`
-		WriteTimeout   string   `mapstructure:"write_timeout"`
-		ReadTimeout    string   `mapstructure:"read_timeout"`
-		VerboseCORS    bool     `mapstructure:"verbose_cors"`
-		AllowedOrigins []string `mapstructure:"allowed_origins"`
+		ListenAddr        string   `mapstructure:"listen_addr"`
+		AdminListenAddr   string   `mapstructure:"admin_listen_addr"`
+		AdminSecret       string   `mapstructure:"admin_secret"`
+		WriteTimeout      string   `mapstructure:"write_timeout"`
+		ReadTimeout       string   `mapstructure:"read_timeout"`
+		IdleTimeout       string   `mapstructure:"idle_timeout"`
+		ReadHeaderTimeout string   `mapstructure:"read_header_timeout"`
+		ShutdownTimeout   string   `mapstructure:"shutdown_timeout"`
+		DrainPeriod       string   `mapstructure:"drain_period"`
+		MaxSyncAge        string   `mapstructure:"max_sync_age"`
+		MaxBodyBytes      int64    `mapstructure:"max_body_bytes"`
+		VerboseCORS       bool     `mapstructure:"verbose_cors"`
+		AllowedOrigins    []string `mapstructure:"allowed_origins"`
+	}
+
+	// AnomalyDetection defines when the vote of an asset is held for a vote
+	// period because its price jumped without being corroborated by at least
+	// Quorum providers. A jump is a relative change from the last voted price
+	// above MaxChange, e.g. "0.2" for 20%, or a z-score over the recently
+	// voted prices above MaxZScore. Detection is disabled if neither is set.
+	AnomalyDetection struct {
+		MaxChange string  `mapstructure:"max_change"`
+		MaxZScore float64 `mapstructure:"max_z_score" validate:"gte=0"`
+		Quorum    int     `mapstructure:"quorum" validate:"gte=0"`
+	}
+
+	// Audit defines where every use of the feeder key is recorded besides the
+	// logs: appended as JSON lines to File and posted as JSON to URL within
+	// Timeout, e.g. to an audit database.
+	Audit struct {
+		File    string `mapstructure:"file"`
+		URL     string `mapstructure:"url" validate:"omitempty,url"`
+		Timeout string `mapstructure:"timeout"`
+	}
+
+	// HTTPClient defines the transport shared by the REST providers, see
+	// provider.HTTPClientConfig. Unset values default to the ones of
+	// provider.DefaultHTTPClientConfig.
+	HTTPClient struct {
+		DialTimeout         string `mapstructure:"dial_timeout"`
+		KeepAlive           string `mapstructure:"keep_alive"`
+		TLSHandshakeTimeout string `mapstructure:"tls_handshake_timeout"`
+		IdleConnTimeout     string `mapstructure:"idle_conn_timeout"`
+		MaxIdleConns        int    `mapstructure:"max_idle_conns" validate:"gte=0"`
+		MaxIdleConnsPerHost int    `mapstructure:"max_idle_conns_per_host" validate:"gte=0"`
+		MaxConnsPerHost     int    `mapstructure:"max_conns_per_host" validate:"gte=0"`
+		DNSCacheTTL         string `mapstructure:"dns_cache_ttl"`
+	}
+
+	// Candles defines which candles the TVWAP of an asset is computed from.
+	// TVWAPPeriod is the aggregation window and MaxAge the age of the latest
+	// candle of a provider after which its candles are considered stale, so
+	// that thin markets with slow candles can be priced without widening the
+	// aggregation window. Both default to five minutes.
+	//
+	// MinTimeWeight, "0.2" by default and less than 1, is the minimum time
+	// weight of the TVWAP formula, see oracle.CandleFloors, and
+	// MinCandleVolume, "0.0001" by default, the volume given to candles
+	// without volume. They can be overridden per asset by its currency pairs,
+	// as the floor volume dominates the TVWAP of low-liquidity assets.
+	Candles struct {
+		TVWAPPeriod     string `mapstructure:"tvwap_period"`
+		MaxAge          string `mapstructure:"max_age"`
+		MinTimeWeight   string `mapstructure:"min_time_weight"`
+		MinCandleVolume string `mapstructure:"min_candle_volume"`
+	}
+
+	// Hub defines how exchange connections are shared between instances on
+	// the same network. With Serve, the instance acts as a hub and streams
+	// the prices it receives from its providers to other instances. With a
+	// URL, the instance receives the prices of its providers from the hub
+	// served there instead of connecting to the exchanges, in which case the
+	// hub must be configured with its pairs.
+	Hub struct {
+		Serve bool   `mapstructure:"serve"`
+		URL   string `mapstructure:"url" validate:"omitempty,url"`
+	}
+
+	// Spend defines the budgets of the fees paid by the feeder account over
+	// the last day and week, as coins, e.g. "5000000uxprt", above which a
+	// warning is logged. Empty budgets don't alert.
+	Spend struct {
+		DailyBudget  string `mapstructure:"daily_budget"`
+		WeeklyBudget string `mapstructure:"weekly_budget"`
+	}
+
+	// Archive defines where signed attestations of the computed prices and
+	// of the provider prices they were computed from are archived every
+	// Interval: added to the IPFS node whose RPC API is served at IPFSURL
+	// and/or put into an S3 bucket. Archiving is disabled when neither is set.
+	Archive struct {
+		Interval string    `mapstructure:"interval"`
+		Timeout  string    `mapstructure:"timeout"`
+		IPFSURL  string    `mapstructure:"ipfs_url" validate:"omitempty,url"`
+		S3       ArchiveS3 `mapstructure:"s3"`
+	}
+
+	// Watchdog defines the number of goroutines and the heap size, in MiB,
+	// above which the process is considered leaking, sampled every Interval.
+	// On a breach, the goroutine and heap profiles are dumped to DumpDir, if
+	// set, and the providers are restarted if RestartProviders is set. The
+	// watchdog is disabled when neither limit is set.
+	Watchdog struct {
+		Interval         string `mapstructure:"interval"`
+		MaxGoroutines    int    `mapstructure:"max_goroutines" validate:"gte=0"`
+		MaxHeapMB        uint64 `mapstructure:"max_heap_mb"`
+		DumpDir          string `mapstructure:"dump_dir"`
+		RestartProviders bool   `mapstructure:"restart_providers"`
+	}
+
+	// Manifest defines the recommended config manifest, published for the
+	// whole fleet, the config is checked against every Interval so that its
+	// drift is reported, e.g. once the accept list changes. The settings of
+	// Network, by default the chain ID of the account, are checked.
+	Manifest struct {
+		URL      string `mapstructure:"url" validate:"omitempty,url"`
+		Network  string `mapstructure:"network"`
+		Interval string `mapstructure:"interval"`
+	}
+
+	// ArchiveS3 defines the S3 bucket, or the bucket of a service compatible
+	// with its API at Endpoint, attestations are put into under Prefix. The
+	// access keys must be referenced as "env:<NAME>" or "file:<PATH>".
+	ArchiveS3 struct {
+		Endpoint  string `mapstructure:"endpoint" validate:"omitempty,url"`
+		Region    string `mapstructure:"region"`
+		Bucket    string `mapstructure:"bucket"`
+		Prefix    string `mapstructure:"prefix"`
+		AccessKey string `mapstructure:"access_key"`
+		SecretKey string `mapstructure:"secret_key"`
 	}
 
 	// CurrencyPair defines a price quote of the exchange rate for two different
@@ -86,6 +319,35 @@ type (
 		Base      string          `mapstructure:"base" validate:"required"`
 		Quote     string          `mapstructure:"quote" validate:"required"`
 		Providers []provider.Name `mapstructure:"providers" validate:"required,gt=0,dive,required"`
+
+		// Exponent is the exponent of the unit the provider prices of the base
+		// are quoted in, if it differs from the exponent of the base in the
+		// x/oracle accept list. The prices are then scaled before voting.
+		Exponent *uint32 `mapstructure:"exponent"`
+
+		// Precision is the number of decimals, at most 18, the price of the
+		// base is voted with, which keeps the votes of large accept lists
+		// small. Rounding is either "round", the default, which rounds half
+		// away from zero, or "truncate". By default prices are voted with 18
+		// decimals.
+		Precision *uint32 `mapstructure:"precision" validate:"omitempty,lte=18"`
+		Rounding  string  `mapstructure:"rounding" validate:"omitempty,oneof=round truncate"`
+
+		// Priority is either "best_effort", the default, whose missing price
+		// is simply omitted from the vote, or "critical", whose missing price
+		// is handled according to the CriticalAssetPolicy.
+		Priority string `mapstructure:"priority" validate:"omitempty,oneof=critical best_effort"`
+
+		// BackupProviders are only connected to and queried while fewer than
+		// BackupQuorum of the Providers price the pair, by default two, or all
+		// of them if there are fewer.
+		BackupProviders []provider.Name `mapstructure:"backup_providers" validate:"dive,required"`
+		BackupQuorum    int             `mapstructure:"backup_quorum" validate:"gte=0"`
+
+		// MinTimeWeight and MinCandleVolume override the candle floors of
+		// the base, see Candles.
+		MinTimeWeight   string `mapstructure:"min_time_weight"`
+		MinCandleVolume string `mapstructure:"min_candle_volume"`
 	}
 
 	// Deviation defines a maximum amount of standard deviations that a given asset can
@@ -96,11 +358,15 @@ type (
 	}
 
 	// Account defines account related configuration that is related to the persistenceOne
-	// network and transaction signing functionality.
+	// network and transaction signing functionality. Bech32Prefix and CoinType
+	// default to the ones of the Persistence chain, "persistence" and 750, and
+	// allow to serve other persistence-sdk based chains.
 	Account struct {
-		ChainID   string `mapstructure:"chain_id" validate:"required"`
-		Address   string `mapstructure:"address" validate:"required"`
-		Validator string `mapstructure:"validator" validate:"required"`
+		ChainID      string `mapstructure:"chain_id" validate:"required"`
+		Address      string `mapstructure:"address" validate:"required"`
+		Validator    string `mapstructure:"validator" validate:"required"`
+		Bech32Prefix string `mapstructure:"bech32_prefix"`
+		CoinType     uint32 `mapstructure:"coin_type"`
 	}
 
 	// Keyring defines the required persistenceOne keyring configuration.
@@ -113,14 +379,366 @@ type (
 		Mnemonic   string `mapstructure:"mnemonic"`
 	}
 
+	// ProviderCredential defines the API key and secret a provider
+	// authenticates with, e.g. for higher rate limits. Both must be secret
+	// references, see ResolveSecret, and are never stored in plaintext.
+	ProviderCredential struct {
+		Name      provider.Name `mapstructure:"name" validate:"required"`
+		APIKey    string        `mapstructure:"api_key" validate:"required"`
+		APISecret string        `mapstructure:"api_secret"`
+	}
+
+	// SourceGroup defines providers which ultimately proxy the same upstream
+	// venue, e.g. an exchange and an aggregator backed by it. Deviation
+	// filtering and provider minimums count them as a single source.
+	SourceGroup struct {
+		Name      string          `mapstructure:"name" validate:"required"`
+		Providers []provider.Name `mapstructure:"providers" validate:"required,gt=1,dive,required"`
+	}
+
 	// RPC defines RPC configuration of both the persistenceOne gRPC and Tendermint nodes.
+	// BroadcastMode is the mode votes are broadcasted with: "sync" awaits their
+	// inclusion after CheckTx, "block" lets the node await their commit and
+	// "async" returns right away, confirming their inclusion in the background.
+	// Once the block height didn't increase for MaxHeightAge, voting is skipped
+	// and the next of the TMRPCFallbackEndpoints is switched to.
 	RPC struct {
-		TMRPCEndpoint string `mapstructure:"tmrpc_endpoint" validate:"required"`
-		GRPCEndpoint  string `mapstructure:"grpc_endpoint" validate:"required"`
-		RPCTimeout    string `mapstructure:"rpc_timeout" validate:"required"`
+		TMRPCEndpoint          string   `mapstructure:"tmrpc_endpoint" validate:"required"`
+		TMRPCFallbackEndpoints []string `mapstructure:"tmrpc_fallback_endpoints" validate:"dive,required"`
+		GRPCEndpoint           string   `mapstructure:"grpc_endpoint" validate:"required"`
+		RPCTimeout             string   `mapstructure:"rpc_timeout" validate:"required"`
+		BroadcastMode          string   `mapstructure:"broadcast_mode" validate:"omitempty,oneof=sync block async"`
+		MaxHeightAge           string   `mapstructure:"max_height_age"`
 	}
 )
 
+// DeviationThresholds returns the configured deviation thresholds keyed by
+// base denom.
+func (c Config) DeviationThresholds() (map[string]sdk.Dec, error) {
+	deviations := make(map[string]sdk.Dec, len(c.Deviations))
+	for _, deviation := range c.Deviations {
+		threshold, err := sdk.NewDecFromStr(deviation.Threshold)
+		if err != nil {
+			return nil, err
+		}
+		deviations[deviation.Base] = threshold
+	}
+
+	return deviations, nil
+}
+
+// Durations returns the parsed TVWAP period and maximum candle age.
+func (c Candles) Durations() (tvwapPeriod, maxAge time.Duration, err error) {
+	tvwapPeriod, err = time.ParseDuration(c.TVWAPPeriod)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to parse candle tvwap period: %w", err)
+	}
+	maxAge, err = time.ParseDuration(c.MaxAge)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to parse candle max age: %w", err)
+	}
+
+	return tvwapPeriod, maxAge, nil
+}
+
+// Floors returns the parsed minimum candle time weight and volume, which are
+// nil when unset.
+func (c Candles) Floors() (minTimeWeight, minCandleVolume sdk.Dec, err error) {
+	return parseCandleFloors(c.MinTimeWeight, c.MinCandleVolume)
+}
+
+// IsRedemptionRate returns whether the pair is only priced by the pstake
+// provider, at the redemption rate of a liquid staking token in its
+// underlying asset, so that it may be quoted in any asset converted to USD.
+func (cp CurrencyPair) IsRedemptionRate() bool {
+	if len(cp.Providers) == 0 {
+		return false
+	}
+	for _, providers := range [][]provider.Name{cp.Providers, cp.BackupProviders} {
+		for _, p := range providers {
+			if p != provider.PStake {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// CandleFloors returns the parsed minimum candle time weight and volume of
+// the base of the pair, which are nil when unset.
+func (cp CurrencyPair) CandleFloors() (minTimeWeight, minCandleVolume sdk.Dec, err error) {
+	return parseCandleFloors(cp.MinTimeWeight, cp.MinCandleVolume)
+}
+
+// parseCandleFloors parses the minimum time weight, at least 0 and less than
+// 1, and the positive minimum candle volume, if set.
+func parseCandleFloors(minTimeWeight, minCandleVolume string) (timeWeight, volume sdk.Dec, err error) {
+	if len(minTimeWeight) > 0 {
+		timeWeight, err = sdk.NewDecFromStr(minTimeWeight)
+		if err != nil || timeWeight.IsNegative() || timeWeight.GTE(sdk.OneDec()) {
+			return sdk.Dec{}, sdk.Dec{}, fmt.Errorf("candle min time weight must be at least 0 and less than 1")
+		}
+	}
+	if len(minCandleVolume) > 0 {
+		volume, err = sdk.NewDecFromStr(minCandleVolume)
+		if err != nil || !volume.IsPositive() {
+			return sdk.Dec{}, sdk.Dec{}, fmt.Errorf("candle min volume must be a positive number")
+		}
+	}
+
+	return timeWeight, volume, nil
+}
+
+// Budgets returns the daily and weekly fee budgets, which are empty if unset.
+func (s Spend) Budgets() (daily, weekly sdk.Coins, err error) {
+	daily, err = sdk.ParseCoinsNormalized(s.DailyBudget)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse daily spend budget: %w", err)
+	}
+	weekly, err = sdk.ParseCoinsNormalized(s.WeeklyBudget)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse weekly spend budget: %w", err)
+	}
+
+	return daily, weekly, nil
+}
+
+// Enabled returns whether attestations are archived anywhere.
+func (a Archive) Enabled() bool {
+	return len(a.IPFSURL) > 0 || len(a.S3.Bucket) > 0
+}
+
+// Durations returns the archive interval and the timeout of every store
+// request.
+func (a Archive) Durations() (interval, timeout time.Duration, err error) {
+	if interval, err = time.ParseDuration(a.Interval); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse archive interval: %w", err)
+	}
+	if timeout, err = time.ParseDuration(a.Timeout); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse archive timeout: %w", err)
+	}
+
+	return interval, timeout, nil
+}
+
+// Enabled returns whether any watchdog limit is set.
+func (w Watchdog) Enabled() bool {
+	return w.MaxGoroutines > 0 || w.MaxHeapMB > 0
+}
+
+// Limits returns the watchdog limits and sampling interval.
+func (w Watchdog) Limits() (limits watchdog.Limits, interval time.Duration, err error) {
+	if interval, err = time.ParseDuration(w.Interval); err != nil {
+		return limits, 0, fmt.Errorf("failed to parse watchdog interval: %w", err)
+	}
+
+	return watchdog.Limits{MaxGoroutines: w.MaxGoroutines, MaxHeapBytes: w.MaxHeapMB << 20}, interval, nil
+}
+
+// Enabled returns whether a manifest is configured.
+func (m Manifest) Enabled() bool {
+	return len(m.URL) > 0
+}
+
+// Duration returns the interval the manifest is checked at.
+func (m Manifest) Duration() (time.Duration, error) {
+	interval, err := time.ParseDuration(m.Interval)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse manifest interval: %w", err)
+	}
+
+	return interval, nil
+}
+
+// Config returns the provider HTTP client config, with the unset values
+// defaulted.
+func (hc HTTPClient) Config() (provider.HTTPClientConfig, error) {
+	cfg := provider.DefaultHTTPClientConfig
+
+	durations := []struct {
+		name  string
+		value string
+		dst   *time.Duration
+	}{
+		{"dial timeout", hc.DialTimeout, &cfg.DialTimeout},
+		{"keep alive", hc.KeepAlive, &cfg.KeepAlive},
+		{"TLS handshake timeout", hc.TLSHandshakeTimeout, &cfg.TLSHandshakeTimeout},
+		{"idle connection timeout", hc.IdleConnTimeout, &cfg.IdleConnTimeout},
+		{"DNS cache TTL", hc.DNSCacheTTL, &cfg.DNSCacheTTL},
+	}
+	for _, d := range durations {
+		if len(d.value) == 0 {
+			continue
+		}
+		duration, err := time.ParseDuration(d.value)
+		if err != nil {
+			return provider.HTTPClientConfig{}, fmt.Errorf("failed to parse HTTP client %s: %w", d.name, err)
+		}
+		*d.dst = duration
+	}
+
+	if hc.MaxIdleConns > 0 {
+		cfg.MaxIdleConns = hc.MaxIdleConns
+	}
+	if hc.MaxIdleConnsPerHost > 0 {
+		cfg.MaxIdleConnsPerHost = hc.MaxIdleConnsPerHost
+	}
+	if hc.MaxConnsPerHost > 0 {
+		cfg.MaxConnsPerHost = hc.MaxConnsPerHost
+	}
+
+	return cfg, nil
+}
+
+// UnixSocketPath returns the socket path of a "unix://" listen address, or
+// false if addr is a TCP address.
+func UnixSocketPath(addr string) (string, bool) {
+	if !strings.HasPrefix(addr, unixSocketPrefix) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(addr, unixSocketPrefix), true
+}
+
+// DefaultAccount returns the account settings of the Persistence chain, used
+// by the commands which don't read a config file.
+func DefaultAccount() Account {
+	return Account{Bech32Prefix: defaultBech32Prefix, CoinType: defaultCoinType}
+}
+
+// IsLocalAddr returns whether the listen address is only reachable from the
+// host: a unix socket or a TCP address on a loopback interface. An address
+// without host listens on every interface.
+func IsLocalAddr(addr string) bool {
+	if _, ok := UnixSocketPath(addr); ok {
+		return true
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// PriceSyncAge returns the age of the last price sync past which prices are
+// stale, or zero if the check is disabled. The age is validated by
+// ParseConfig.
+func (s Server) PriceSyncAge() time.Duration {
+	maxSyncAge, err := time.ParseDuration(s.MaxSyncAge)
+	if err != nil {
+		return 0
+	}
+
+	return maxSyncAge
+}
+
+// Enabled returns true if any anomaly threshold is set.
+func (ad AnomalyDetection) Enabled() bool {
+	return len(ad.MaxChange) > 0 || ad.MaxZScore > 0
+}
+
+// MaxChangeThreshold returns the relative price change above which a price
+// is a jump, or zero if unset. The threshold is validated by ParseConfig.
+func (ad AnomalyDetection) MaxChangeThreshold() sdk.Dec {
+	maxChange, err := sdk.NewDecFromStr(ad.MaxChange)
+	if err != nil {
+		return sdk.ZeroDec()
+	}
+
+	return maxChange
+}
+
+// UnchangedEpsilon returns the relative price change below which prices are
+// unchanged, and false if unset. The epsilon is validated by ParseConfig.
+func (c Config) UnchangedEpsilon() (sdk.Dec, bool) {
+	if len(c.VoteUnchangedEpsilon) == 0 {
+		return sdk.Dec{}, false
+	}
+
+	epsilon, err := sdk.NewDecFromStr(c.VoteUnchangedEpsilon)
+	if err != nil {
+		return sdk.Dec{}, false
+	}
+
+	return epsilon, true
+}
+
+// SourceGroupByProvider returns the source group name of every grouped
+// provider.
+func (c Config) SourceGroupByProvider() map[provider.Name]string {
+	groups := make(map[provider.Name]string)
+	for _, group := range c.SourceGroups {
+		for _, providerName := range group.Providers {
+			groups[providerName] = group.Name
+		}
+	}
+
+	return groups
+}
+
+// IsVoteOnly returns whether the prices of the provider are only used to vote
+// and must not be re-exposed by the API, its metrics or the archived
+// attestations, as its terms forbid redistributing them.
+func (c Config) IsVoteOnly(providerName provider.Name) bool {
+	for _, voteOnly := range c.VoteOnlyProviders {
+		if voteOnly == providerName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsVoteOnlyAsset returns whether all the providers an asset was priced from
+// are vote-only, in which case its price must not be re-exposed either.
+func (c Config) IsVoteOnlyAsset(providers []provider.Name) bool {
+	if len(providers) == 0 {
+		return false
+	}
+
+	for _, providerName := range providers {
+		if !c.IsVoteOnly(providerName) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ResolveProviderEndpoints returns the provider endpoint overrides keyed by provider
+// name, along with the resolved credentials of the providers. A provider with
+// credentials but no endpoint override gets an endpoint without a name, so
+// that it keeps its default endpoints.
+func (c Config) ResolveProviderEndpoints() (map[provider.Name]provider.Endpoint, error) {
+	endpoints := make(map[provider.Name]provider.Endpoint, len(c.ProviderEndpoints))
+	for _, endpoint := range c.ProviderEndpoints {
+		endpoints[endpoint.Name] = endpoint
+	}
+
+	for _, credential := range c.ProviderCredentials {
+		endpoint := endpoints[credential.Name]
+
+		var err error
+		if endpoint.APIKey, err = ResolveSecret(credential.APIKey); err != nil {
+			return nil, fmt.Errorf("failed to resolve %s API key: %w", credential.Name, err)
+		}
+		if len(credential.APISecret) > 0 {
+			if endpoint.APISecret, err = ResolveSecret(credential.APISecret); err != nil {
+				return nil, fmt.Errorf("failed to resolve %s API secret: %w", credential.Name, err)
+			}
+		}
+
+		endpoints[credential.Name] = endpoint
+	}
+
+	return endpoints, nil
+}
+
 // endpointValidation is custom validation for the ProviderEndpoint struct.
 func endpointValidation(sl validator.StructLevel) {
 	endpoint, ok := sl.Current().Interface().(provider.Endpoint)
@@ -128,14 +746,70 @@ func endpointValidation(sl validator.StructLevel) {
 		sl.ReportError(endpoint, "endpoint", "Endpoint", "unsupportedEndpointType", "")
 		return
 	}
-	if len(endpoint.Name) < 1 || len(endpoint.Rest) < 1 || len(endpoint.Websocket) < 1 {
+	// endpoints of on-chain providers querying a node only need its gRPC
+	// address, and those querying the pools of an indexer its REST URL
+	hasAPI := len(endpoint.Rest) > 0 && (len(endpoint.Websocket) > 0 || len(endpoint.Pools) > 0)
+	// CoinGecko endpoints default to its public or pro API and only map the
+	// pairs to CoinGecko coins, and Kaiko, CryptoCompare and forex endpoints
+	// only set their REST URL
+	restOnly := endpoint.Name == provider.Kaiko || endpoint.Name == provider.CryptoCompare ||
+		endpoint.Name == provider.ECB || endpoint.Name == provider.ExchangeRateHost ||
+		endpoint.Name == provider.MetalsAPI
+	hasAPI = hasAPI || endpoint.Name == provider.CoinGecko || (restOnly && len(endpoint.Rest) > 0)
+	// exec endpoints only set the command of their executable
+	hasAPI = hasAPI || (endpoint.Name == provider.Exec && len(endpoint.Command) > 0)
+	// generic endpoints only set their queries
+	hasAPI = hasAPI || (endpoint.Name == provider.Generic && len(endpoint.Queries) > 0)
+	if len(endpoint.Name) < 1 || (len(endpoint.GRPC) < 1 && !hasAPI) {
 		sl.ReportError(endpoint, "endpoint", "Endpoint", "unsupportedEndpointType", "")
 	}
+	for _, pool := range endpoint.Pools {
+		if !validPool(endpoint.Name, pool) {
+			sl.ReportError(pool, "pools", "Pools", "invalidPool", "")
+		}
+	}
+	for _, query := range endpoint.Queries {
+		if err := query.Validate(); err != nil {
+			sl.ReportError(query, "queries", "Queries", "invalidQuery", err.Error())
+		}
+	}
 	if _, ok := SupportedProviders[endpoint.Name]; !ok {
 		sl.ReportError(endpoint.Name, "name", "Name", "unsupportedEndpointProvider", "")
 	}
 }
 
+// validPool returns whether the pool of the endpoint of the provider is
+// valid. Chainlink aggregators only need their contract address, their
+// answers being quoted in the pair, and CoinGecko pools the coin id of their
+// base.
+func validPool(providerName provider.Name, pool provider.Pool) bool {
+	if len(pool.Base) < 1 || len(pool.Quote) < 1 {
+		return false
+	}
+	switch providerName {
+	case provider.Chainlink:
+		return len(pool.Contract) > 0
+	case provider.CoinGecko:
+		return len(pool.BaseDenom) > 0
+	case provider.PStake:
+		return len(pool.QuoteDenom) > 0
+	case provider.Umee, provider.Ojo, provider.Kujira:
+		return len(pool.BaseDenom) > 0
+	}
+
+	return (pool.ID != 0 || len(pool.Contract) > 0) && len(pool.BaseDenom) > 0 && len(pool.QuoteDenom) > 0 &&
+		pool.BaseExponent >= 0 && pool.QuoteExponent >= 0
+}
+
+// supportedProviders returns the lookup table of the registered providers.
+func supportedProviders() map[provider.Name]struct{} {
+	providers := make(map[provider.Name]struct{})
+	for _, name := range provider.Registered() {
+		providers[name] = struct{}{}
+	}
+	return providers
+}
+
 // Validate returns an error if the Config object is invalid.
 func (c Config) Validate() error {
 	validate.RegisterStructValidation(endpointValidation, provider.Endpoint{})
@@ -159,20 +833,68 @@ func ParseConfig(configPath string) (Config, error) {
 	if err := viper.ReadInConfig(); err != nil {
 		return cfg, fmt.Errorf("failed to read config: %w", err)
 	}
+	if err := applyFileEnv(viper.GetViper()); err != nil {
+		return cfg, err
+	}
 
 	if err := viper.Unmarshal(&cfg); err != nil {
 		return cfg, fmt.Errorf("failed to decode config: %w", err)
 	}
+	logsafe.AddSecrets(cfg.Keyring.Passphrase, cfg.Keyring.PrivKeyHex, cfg.Keyring.Mnemonic)
 
 	if cfg.Server.ListenAddr == "" {
 		cfg.Server.ListenAddr = defaultListenAddr
 	}
+	if cfg.Server.AdminListenAddr == "" {
+		cfg.Server.AdminListenAddr = defaultAdminListenAddr
+	}
+	if cfg.Server.AdminListenAddr == cfg.Server.ListenAddr {
+		return cfg, fmt.Errorf("admin listen address must differ from the public listen address")
+	}
+	if len(cfg.Server.AdminSecret) == 0 && !IsLocalAddr(cfg.Server.AdminListenAddr) {
+		return cfg, fmt.Errorf(
+			"admin listen address %s is not a loopback address or unix socket and requires an admin secret",
+			cfg.Server.AdminListenAddr,
+		)
+	}
+	for _, addr := range []string{cfg.Server.ListenAddr, cfg.Server.AdminListenAddr} {
+		if path, ok := UnixSocketPath(addr); ok && len(path) == 0 {
+			return cfg, fmt.Errorf("unix socket listen address %q has no path", addr)
+		}
+	}
 	if len(cfg.Server.WriteTimeout) == 0 {
 		cfg.Server.WriteTimeout = defaultSrvWriteTimeout.String()
 	}
 	if len(cfg.Server.ReadTimeout) == 0 {
 		cfg.Server.ReadTimeout = defaultSrvReadTimeout.String()
 	}
+	if len(cfg.Server.IdleTimeout) == 0 {
+		cfg.Server.IdleTimeout = defaultSrvIdleTimeout.String()
+	}
+	if len(cfg.Server.ReadHeaderTimeout) == 0 {
+		cfg.Server.ReadHeaderTimeout = defaultSrvReadHeader.String()
+	}
+	if len(cfg.Server.ShutdownTimeout) == 0 {
+		cfg.Server.ShutdownTimeout = defaultSrvShutdown.String()
+	}
+	if len(cfg.Server.DrainPeriod) == 0 {
+		cfg.Server.DrainPeriod = "0s"
+	}
+	if len(cfg.Server.MaxSyncAge) == 0 {
+		cfg.Server.MaxSyncAge = defaultSrvMaxSyncAge.String()
+	}
+	if _, err := time.ParseDuration(cfg.Server.MaxSyncAge); err != nil {
+		return cfg, fmt.Errorf("failed to parse max price sync age: %w", err)
+	}
+	if cfg.Server.MaxBodyBytes <= 0 {
+		cfg.Server.MaxBodyBytes = defaultSrvMaxBodyBytes
+	}
+	if len(cfg.RPC.BroadcastMode) == 0 {
+		cfg.RPC.BroadcastMode = defaultBroadcastMode
+	}
+	if len(cfg.RPC.MaxHeightAge) == 0 {
+		cfg.RPC.MaxHeightAge = defaultMaxHeightAge.String()
+	}
 	if len(cfg.ProviderTimeout) == 0 {
 		cfg.ProviderTimeout = defaultProviderTimeout.String()
 	}
@@ -180,17 +902,58 @@ func ParseConfig(configPath string) (Config, error) {
 	if len(cfg.Fees) == 0 {
 		cfg.Fees = defaultUXPRTFees
 	}
+	if len(cfg.Account.Bech32Prefix) == 0 {
+		cfg.Account.Bech32Prefix = defaultBech32Prefix
+	}
+	if cfg.Account.CoinType == 0 {
+		cfg.Account.CoinType = defaultCoinType
+	}
+
+	// normalize the assets so that e.g. "stkATOM" and "STKATOM" refer to the
+	// same asset across providers, deviations and the accept list
+	for i := range cfg.CurrencyPairs {
+		cfg.CurrencyPairs[i].Base = types.NewSymbol(cfg.CurrencyPairs[i].Base).String()
+		cfg.CurrencyPairs[i].Quote = types.NewSymbol(cfg.CurrencyPairs[i].Quote).String()
+	}
+	for i := range cfg.Deviations {
+		cfg.Deviations[i].Base = types.NewSymbol(cfg.Deviations[i].Base).String()
+	}
 
 	pairProviderMap := make(map[string]map[provider.Name]struct{})
 	coinQuotes := make(map[string]struct{})
+	exponents := make(map[string]uint32)
+	precisions := make(map[string]CurrencyPair)
+	candleFloors := make(map[string]CurrencyPair)
 	for _, cp := range cfg.CurrencyPairs {
+		if cp.Exponent != nil {
+			if exponent, ok := exponents[cp.Base]; ok && exponent != *cp.Exponent {
+				return cfg, fmt.Errorf("conflicting exponents for %s: %d and %d", cp.Base, exponent, *cp.Exponent)
+			}
+			exponents[cp.Base] = *cp.Exponent
+		}
+		if cp.Precision != nil {
+			if other, ok := precisions[cp.Base]; ok && (*other.Precision != *cp.Precision || other.Rounding != cp.Rounding) {
+				return cfg, fmt.Errorf("conflicting precisions for %s", cp.Base)
+			}
+			precisions[cp.Base] = cp
+		}
+		if len(cp.MinTimeWeight) > 0 || len(cp.MinCandleVolume) > 0 {
+			if _, _, err := cp.CandleFloors(); err != nil {
+				return cfg, fmt.Errorf("invalid candle floors of %s: %w", cp.Base, err)
+			}
+			other, ok := candleFloors[cp.Base]
+			if ok && (other.MinTimeWeight != cp.MinTimeWeight || other.MinCandleVolume != cp.MinCandleVolume) {
+				return cfg, fmt.Errorf("conflicting candle floors for %s", cp.Base)
+			}
+			candleFloors[cp.Base] = cp
+		}
 		if _, ok := pairProviderMap[cp.Base]; !ok {
 			pairProviderMap[cp.Base] = make(map[provider.Name]struct{})
 		}
 		if strings.ToUpper(cp.Quote) != DenomUSD {
 			coinQuotes[cp.Quote] = struct{}{}
 		}
-		if _, ok := SupportedQuotes[strings.ToUpper(cp.Quote)]; !ok {
+		if _, ok := SupportedQuotes[strings.ToUpper(cp.Quote)]; !ok && !cp.IsRedemptionRate() {
 			return cfg, fmt.Errorf("unsupported quote: %s", cp.Quote)
 		}
 
@@ -200,6 +963,19 @@ func ParseConfig(configPath string) (Config, error) {
 			}
 			pairProviderMap[cp.Base][p] = struct{}{}
 		}
+		for _, p := range cp.BackupProviders {
+			if _, ok := SupportedProviders[p]; !ok {
+				return cfg, fmt.Errorf("unsupported backup provider: %s", p)
+			}
+			for _, primary := range cp.Providers {
+				if p == primary {
+					return cfg, fmt.Errorf("%s is both a provider and a backup provider of %s", p, cp.Base)
+				}
+			}
+		}
+		if cp.BackupQuorum > len(cp.Providers) {
+			return cfg, fmt.Errorf("backup quorum of %s exceeds its number of providers", cp.Base)
+		}
 	}
 
 	// Use coinQuotes to ensure that any quotes can be converted to USD.
@@ -209,11 +985,157 @@ func ParseConfig(configPath string) (Config, error) {
 				break
 			}
 			if index == len(cfg.CurrencyPairs)-1 {
-				return cfg, fmt.Errorf("all non-usd quotes require a conversion rate feed")
+				return cfg, fmt.Errorf("all non-usd quotes require a conversion rate feed: %s/%s is missing", quote, DenomUSD)
 			}
 		}
 	}
 
+	groupedProviders := make(map[provider.Name]struct{})
+	for _, group := range cfg.SourceGroups {
+		for _, p := range group.Providers {
+			if _, ok := SupportedProviders[p]; !ok {
+				return cfg, fmt.Errorf("unsupported provider in source group %s: %s", group.Name, p)
+			}
+			if _, ok := groupedProviders[p]; ok {
+				return cfg, fmt.Errorf("provider %s belongs to more than one source group", p)
+			}
+			groupedProviders[p] = struct{}{}
+		}
+	}
+
+	for _, p := range cfg.VoteOnlyProviders {
+		if _, ok := SupportedProviders[p]; !ok {
+			return cfg, fmt.Errorf("unsupported vote-only provider: %s", p)
+		}
+	}
+
+	for _, credential := range cfg.ProviderCredentials {
+		if _, ok := SupportedProviders[credential.Name]; !ok {
+			return cfg, fmt.Errorf("unsupported provider credentials: %s", credential.Name)
+		}
+		if !IsSecretRef(credential.APIKey) || (len(credential.APISecret) > 0 && !IsSecretRef(credential.APISecret)) {
+			return cfg, fmt.Errorf(
+				"%s credentials must be referenced as %s<NAME> or %s<PATH>, not stored in plaintext",
+				credential.Name, SecretPrefixEnv, SecretPrefixFile,
+			)
+		}
+	}
+
+	if len(cfg.AnomalyDetection.MaxChange) > 0 {
+		maxChange, err := sdk.NewDecFromStr(cfg.AnomalyDetection.MaxChange)
+		if err != nil || maxChange.IsNegative() {
+			return cfg, fmt.Errorf("anomaly detection max change must be a non-negative number")
+		}
+	}
+	if len(cfg.VoteUnchangedEpsilon) > 0 {
+		epsilon, err := sdk.NewDecFromStr(cfg.VoteUnchangedEpsilon)
+		if err != nil || epsilon.IsNegative() {
+			return cfg, fmt.Errorf("vote unchanged epsilon must be a non-negative number")
+		}
+	}
+	if len(cfg.CriticalAssetPolicy) == 0 {
+		cfg.CriticalAssetPolicy = CriticalAssetSkip
+	}
+	if err := features.Validate(cfg.Features); err != nil {
+		return cfg, err
+	}
+	if cfg.AnomalyDetection.Quorum == 0 {
+		cfg.AnomalyDetection.Quorum = defaultAnomalyQuorum
+	}
+
+	if len(cfg.Audit.Timeout) == 0 {
+		cfg.Audit.Timeout = defaultAuditTimeout.String()
+	}
+	if _, err := time.ParseDuration(cfg.Audit.Timeout); err != nil {
+		return cfg, fmt.Errorf("failed to parse audit timeout: %w", err)
+	}
+
+	if _, err := cfg.HTTPClient.Config(); err != nil {
+		return cfg, err
+	}
+
+	if _, _, err := cfg.Spend.Budgets(); err != nil {
+		return cfg, err
+	}
+
+	if len(cfg.Archive.Interval) == 0 {
+		cfg.Archive.Interval = defaultArchiveInterval.String()
+	}
+	if len(cfg.Archive.Timeout) == 0 {
+		cfg.Archive.Timeout = defaultArchiveTimeout.String()
+	}
+	if interval, _, err := cfg.Archive.Durations(); err != nil {
+		return cfg, err
+	} else if interval <= 0 {
+		return cfg, fmt.Errorf("archive interval must be positive")
+	}
+	if s3 := cfg.Archive.S3; len(s3.Bucket) > 0 {
+		if len(s3.Endpoint) == 0 || len(s3.Region) == 0 {
+			return cfg, fmt.Errorf("archive s3 endpoint and region are required")
+		}
+		if !IsSecretRef(s3.AccessKey) || !IsSecretRef(s3.SecretKey) {
+			return cfg, fmt.Errorf(
+				"archive s3 keys must be referenced as %s<NAME> or %s<PATH>, not stored in plaintext",
+				SecretPrefixEnv, SecretPrefixFile,
+			)
+		}
+	}
+
+	if len(cfg.Watchdog.Interval) == 0 {
+		cfg.Watchdog.Interval = defaultWatchdogPeriod.String()
+	}
+	if _, interval, err := cfg.Watchdog.Limits(); err != nil {
+		return cfg, err
+	} else if interval <= 0 {
+		return cfg, fmt.Errorf("watchdog interval must be positive")
+	}
+
+	if len(cfg.Manifest.Network) == 0 {
+		cfg.Manifest.Network = cfg.Account.ChainID
+	}
+	if len(cfg.Manifest.Interval) == 0 {
+		cfg.Manifest.Interval = defaultManifestPeriod.String()
+	}
+	if interval, err := cfg.Manifest.Duration(); err != nil {
+		return cfg, err
+	} else if interval <= 0 {
+		return cfg, fmt.Errorf("manifest interval must be positive")
+	}
+
+	if len(cfg.Candles.TVWAPPeriod) == 0 {
+		cfg.Candles.TVWAPPeriod = defaultCandlePeriod.String()
+	}
+	if len(cfg.Candles.MaxAge) == 0 {
+		cfg.Candles.MaxAge = cfg.Candles.TVWAPPeriod
+	}
+	tvwapPeriod, maxAge, err := cfg.Candles.Durations()
+	if err != nil {
+		return cfg, err
+	}
+	if tvwapPeriod <= 0 || maxAge < tvwapPeriod {
+		return cfg, fmt.Errorf("candle tvwap period must be positive and max age must not be less than it")
+	}
+	if _, _, err := cfg.Candles.Floors(); err != nil {
+		return cfg, err
+	}
+
+	if len(cfg.Server.AdminSecret) > 0 && !IsSecretRef(cfg.Server.AdminSecret) {
+		return cfg, fmt.Errorf(
+			"admin secret must be referenced as %s<NAME> or %s<PATH>, not stored in plaintext",
+			SecretPrefixEnv, SecretPrefixFile,
+		)
+	}
+
+	if cfg.Hub.Serve && len(cfg.Hub.URL) > 0 {
+		return cfg, fmt.Errorf("an instance cannot both serve as a hub and consume one")
+	}
+
+	if len(cfg.Policy.URL) > 0 {
+		if _, err := cfg.Policy.publicKey(); err != nil {
+			return cfg, err
+		}
+	}
+
 	for _, deviation := range cfg.Deviations {
 		threshold, err := sdk.NewDecFromStr(deviation.Threshold)
 		if err != nil {
@@ -242,13 +1164,20 @@ func CheckProviderMinimum(ctx context.Context, logger zerolog.Logger, cfg Config
 		}
 	}
 
+	// providers of the same source group count as a single provider
+	sourceGroups := cfg.SourceGroupByProvider()
+
 	pairs := make(map[string]map[provider.Name]struct{})
 	for _, cp := range cfg.CurrencyPairs {
 		if _, ok := pairs[cp.Base]; !ok {
 			pairs[cp.Base] = make(map[provider.Name]struct{})
 		}
-		for _, provider := range cp.Providers {
-			pairs[cp.Base][provider] = struct{}{}
+		for _, p := range cp.Providers {
+			source := p
+			if group, ok := sourceGroups[p]; ok {
+				source = provider.Name(group)
+			}
+			pairs[cp.Base][source] = struct{}{}
 		}
 	}
 
@@ -256,7 +1185,9 @@ func CheckProviderMinimum(ctx context.Context, logger zerolog.Logger, cfg Config
 		// If currency provider tracker errored, default to two providers as
 		// the minimum.
 		var minProviders int
-		if currencyProviderTracker != nil {
+		if policyMin, ok := cfg.PolicyMinProviders[base]; ok {
+			minProviders = policyMin
+		} else if currencyProviderTracker != nil {
 			minProviders = currencyProviderTracker.GetMinCurrencyProvider()[base]
 		} else {
 			minProviders = minimumProvider
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const exampleConfigPath = "../price-feeder.example.toml"
+
+// parseExampleConfig parses the example config with every replacement of its
+// text applied, in order.
+func parseExampleConfig(t *testing.T, replacements ...string) (Config, error) {
+	t.Helper()
+
+	bz, err := os.ReadFile(exampleConfigPath)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "price-feeder.toml")
+	content := strings.NewReplacer(replacements...).Replace(string(bz))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return ParseConfig(path)
+}
+
+func TestParseConfigExample(t *testing.T) {
+	cfg, err := parseExampleConfig(t)
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1:7172", cfg.Server.AdminListenAddr)
+}
+
+func TestParseConfigAdminListenAddr(t *testing.T) {
+	const exampleAddr = `admin_listen_addr = "127.0.0.1:7172"`
+
+	testCases := map[string]struct {
+		replacements []string
+		expectedAddr string
+		err          string
+	}{
+		"default": {
+			replacements: []string{exampleAddr, ""},
+			expectedAddr: defaultAdminListenAddr,
+		},
+		"unix socket": {
+			replacements: []string{exampleAddr, `admin_listen_addr = "unix:///run/price-feeder/admin.sock"`},
+			expectedAddr: "unix:///run/price-feeder/admin.sock",
+		},
+		"public without secret": {
+			replacements: []string{exampleAddr, `admin_listen_addr = "0.0.0.0:7172"`},
+			err:          "requires an admin secret",
+		},
+		"public with secret": {
+			replacements: []string{
+				exampleAddr, `admin_listen_addr = "10.0.0.5:7172"`,
+				`# admin_secret = "env:ORACLE_FEEDER_ADMIN_SECRET"`, `admin_secret = "env:ORACLE_FEEDER_ADMIN_SECRET"`,
+			},
+			expectedAddr: "10.0.0.5:7172",
+		},
+		"public listener": {
+			replacements: []string{exampleAddr, `admin_listen_addr = "0.0.0.0:7171"`},
+			err:          "must differ from the public listen address",
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			cfg, err := parseExampleConfig(t, tc.replacements...)
+			if len(tc.err) > 0 {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedAddr, cfg.Server.AdminListenAddr)
+		})
+	}
+}
+
+func TestIsLocalAddr(t *testing.T) {
+	for _, addr := range []string{"127.0.0.1:7172", "[::1]:7172", "localhost:7172", "unix:///run/admin.sock"} {
+		require.True(t, IsLocalAddr(addr), addr)
+	}
+	for _, addr := range []string{"0.0.0.0:7172", ":7172", "[::]:7172", "10.0.0.5:7172", "example.com:7172", "bad"} {
+		require.False(t, IsLocalAddr(addr), addr)
+	}
+}
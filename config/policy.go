@@ -0,0 +1,263 @@
+package config
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+	"github.com/persistenceOne/oracle-feeder/pkg/jsonfile"
+)
+
+const (
+	// PolicyVersionFileName is the file of the data directory recording the
+	// version of the last applied policy, so that older policies are
+	// rejected across restarts too.
+	PolicyVersionFileName = "policy_version.json"
+
+	policyTimeout      = 10 * time.Second
+	maxPolicyBodyBytes = 1 << 20 // 1 MiB
+)
+
+// appliedPolicyVersion is the version of the last policy applied by this
+// process.
+var (
+	appliedPolicyMtx     sync.Mutex
+	appliedPolicyVersion uint64
+)
+
+type (
+	// PolicyConfig defines the remote policy document the per-asset defaults
+	// are read from, and the base64 encoded ed25519 public key it must be
+	// signed with.
+	PolicyConfig struct {
+		URL       string `mapstructure:"url"`
+		PublicKey string `mapstructure:"public_key"`
+	}
+
+	// Policy defines the per-asset settings published by the team so that all
+	// validators run with consistent deviation thresholds and provider
+	// minimums. Assets are keyed by symbol.
+	//
+	// Version must increase with every published policy and the policy is
+	// rejected past Expires, so that a policy can't be replaced by an older
+	// one, e.g. by a compromised mirror serving a previously signed document.
+	Policy struct {
+		Version uint64                 `json:"version"`
+		Expires time.Time              `json:"expires"`
+		Assets  map[string]AssetPolicy `json:"assets"`
+	}
+
+	// AssetPolicy defines the policy settings of an asset. Unset fields are
+	// left to the local defaults.
+	AssetPolicy struct {
+		DeviationThreshold string `json:"deviation_threshold,omitempty"`
+		MinProviders       int    `json:"min_providers,omitempty"`
+	}
+
+	// signedPolicy defines the policy document, where the signature is over
+	// the exact bytes of the policy field.
+	signedPolicy struct {
+		Policy    json.RawMessage `json:"policy"`
+		Signature []byte          `json:"signature"`
+	}
+
+	// policyVersionFile defines the content of the PolicyVersionFileName file.
+	policyVersionFile struct {
+		Version uint64 `json:"version"`
+	}
+)
+
+// publicKey decodes the policy public key.
+func (pc PolicyConfig) publicKey() (ed25519.PublicKey, error) {
+	bz, err := base64.StdEncoding.DecodeString(pc.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy public key: %w", err)
+	}
+	if len(bz) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid policy public key size: %d", len(bz))
+	}
+
+	return bz, nil
+}
+
+// FetchPolicy fetches the policy document at url and verifies its signature
+// against publicKey, and that it is neither expired nor older than
+// minVersion. It is fetched with the HTTP transport shared by the providers.
+func FetchPolicy(ctx context.Context, url string, publicKey ed25519.PublicKey, minVersion uint64) (Policy, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Policy{}, err
+	}
+
+	resp, err := provider.NewHTTPClient(policyTimeout).Do(req)
+	if err != nil {
+		return Policy{}, fmt.Errorf("failed to fetch policy: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Policy{}, fmt.Errorf("failed to fetch policy: unexpected status %s", resp.Status)
+	}
+
+	bz, err := io.ReadAll(io.LimitReader(resp.Body, maxPolicyBodyBytes))
+	if err != nil {
+		return Policy{}, fmt.Errorf("failed to read policy: %w", err)
+	}
+
+	return verifyPolicy(bz, publicKey, minVersion, time.Now())
+}
+
+// verifyPolicy decodes the signed policy document bz, returning an error if
+// it isn't signed by publicKey, is expired at now or is older than
+// minVersion.
+func verifyPolicy(bz []byte, publicKey ed25519.PublicKey, minVersion uint64, now time.Time) (Policy, error) {
+	var doc signedPolicy
+	if err := json.Unmarshal(bz, &doc); err != nil {
+		return Policy{}, fmt.Errorf("failed to decode policy: %w", err)
+	}
+
+	if !ed25519.Verify(publicKey, doc.Policy, doc.Signature) {
+		return Policy{}, fmt.Errorf("invalid policy signature")
+	}
+
+	var policy Policy
+	if err := json.Unmarshal(doc.Policy, &policy); err != nil {
+		return Policy{}, fmt.Errorf("failed to decode policy: %w", err)
+	}
+
+	switch {
+	case policy.Version == 0:
+		return Policy{}, fmt.Errorf("policy has no version")
+	case policy.Version < minVersion:
+		return Policy{}, fmt.Errorf("policy version %d is older than the applied version %d", policy.Version, minVersion)
+	case policy.Expires.IsZero():
+		return Policy{}, fmt.Errorf("policy has no expiry")
+	case !now.Before(policy.Expires):
+		return Policy{}, fmt.Errorf("policy expired at %s", policy.Expires.Format(time.RFC3339))
+	}
+
+	return policy, nil
+}
+
+// loadPolicyVersion returns the version of the last policy applied by this
+// process or, if the data directory is set, by any previous one.
+func (c Config) loadPolicyVersion() (uint64, error) {
+	appliedPolicyMtx.Lock()
+	version := appliedPolicyVersion
+	appliedPolicyMtx.Unlock()
+
+	if len(c.DataDir) == 0 {
+		return version, nil
+	}
+
+	var stored policyVersionFile
+	err := jsonfile.Read(filepath.Join(c.DataDir, PolicyVersionFileName), &stored)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, err
+	}
+	if stored.Version > version {
+		version = stored.Version
+	}
+
+	return version, nil
+}
+
+// storePolicyVersion records version as the version of the last applied
+// policy, unless a newer one was applied meanwhile.
+func (c Config) storePolicyVersion(version uint64) error {
+	appliedPolicyMtx.Lock()
+	defer appliedPolicyMtx.Unlock()
+
+	if version < appliedPolicyVersion {
+		return nil
+	}
+	appliedPolicyVersion = version
+
+	if len(c.DataDir) == 0 {
+		return nil
+	}
+
+	return jsonfile.Write(filepath.Join(c.DataDir, PolicyVersionFileName), policyVersionFile{Version: version})
+}
+
+// ApplyPolicy returns the config with the defaults of the remote policy, if
+// any is configured. The local config takes precedence: the policy only sets
+// the deviation thresholds of the assets which don't have one, and its
+// provider minimums replace the ones inferred from CoinGecko. A policy which
+// can't be fetched or verified, is expired or older than the last applied
+// one is logged and ignored.
+func (c Config) ApplyPolicy(ctx context.Context, logger zerolog.Logger) Config {
+	if len(c.Policy.URL) == 0 {
+		return c
+	}
+
+	publicKey, err := c.Policy.publicKey()
+	if err != nil {
+		logger.Error().Err(err).Msg("ignoring remote policy")
+		return c
+	}
+
+	minVersion, err := c.loadPolicyVersion()
+	if err != nil {
+		logger.Error().Err(err).Msg("ignoring remote policy")
+		return c
+	}
+
+	policy, err := FetchPolicy(ctx, c.Policy.URL, publicKey, minVersion)
+	if err != nil {
+		logger.Error().Err(err).Str("url", c.Policy.URL).Msg("ignoring remote policy")
+		return c
+	}
+	if err := c.storePolicyVersion(policy.Version); err != nil {
+		logger.Error().Err(err).Msg("ignoring remote policy")
+		return c
+	}
+
+	local := make(map[string]struct{}, len(c.Deviations))
+	for _, deviation := range c.Deviations {
+		local[deviation.Base] = struct{}{}
+	}
+
+	deviations := append([]Deviation{}, c.Deviations...)
+	minProviders := make(map[string]int)
+	for symbol, asset := range policy.Assets {
+		base := types.NewSymbol(symbol).String()
+
+		if _, ok := local[base]; !ok && len(asset.DeviationThreshold) > 0 {
+			threshold, err := sdk.NewDecFromStr(asset.DeviationThreshold)
+			if err != nil || threshold.IsNegative() || threshold.GT(maxDeviationThreshold) {
+				logger.Warn().Str("base", base).Msg("ignoring invalid policy deviation threshold")
+			} else {
+				deviations = append(deviations, Deviation{Base: base, Threshold: asset.DeviationThreshold})
+			}
+		}
+
+		if asset.MinProviders > 0 {
+			minProviders[base] = asset.MinProviders
+		}
+	}
+
+	c.Deviations = deviations
+	c.PolicyMinProviders = minProviders
+	logger.Info().
+		Str("url", c.Policy.URL).
+		Uint64("version", policy.Version).
+		Int("assets", len(policy.Assets)).
+		Msg("applied remote policy")
+
+	return c
+}
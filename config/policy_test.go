@@ -0,0 +1,176 @@
+package config
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/pkg/jsonfile"
+)
+
+// signPolicy returns the policy document of policy signed by privKey.
+func signPolicy(t *testing.T, privKey ed25519.PrivateKey, policy Policy) []byte {
+	t.Helper()
+
+	bz, err := json.Marshal(policy)
+	require.NoError(t, err)
+
+	doc, err := json.Marshal(signedPolicy{Policy: bz, Signature: ed25519.Sign(privKey, bz)})
+	require.NoError(t, err)
+
+	return doc
+}
+
+func newPolicyKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	return pubKey, privKey
+}
+
+func TestVerifyPolicy(t *testing.T) {
+	pubKey, privKey := newPolicyKey(t)
+	otherPubKey, _ := newPolicyKey(t)
+
+	now := time.Now()
+	policy := Policy{
+		Version: 7,
+		Expires: now.Add(time.Hour).UTC(),
+		Assets:  map[string]AssetPolicy{"ATOM": {DeviationThreshold: "1.5", MinProviders: 3}},
+	}
+	doc := signPolicy(t, privKey, policy)
+
+	var tampered signedPolicy
+	require.NoError(t, json.Unmarshal(doc, &tampered))
+	tampered.Policy = json.RawMessage(
+		`{"version":7,"expires":"` + policy.Expires.Format(time.RFC3339Nano) +
+			`","assets":{"ATOM":{"deviation_threshold":"100"}}}`,
+	)
+	tamperedDoc, err := json.Marshal(tampered)
+	require.NoError(t, err)
+
+	unversioned, expired := policy, policy
+	unversioned.Version = 0
+	expired.Expires = now.Add(-time.Minute)
+
+	testCases := map[string]struct {
+		doc        []byte
+		pubKey     ed25519.PublicKey
+		minVersion uint64
+		err        string
+	}{
+		"valid": {
+			doc:    doc,
+			pubKey: pubKey,
+		},
+		"same version": {
+			doc:        doc,
+			pubKey:     pubKey,
+			minVersion: 7,
+		},
+		"tampered body": {
+			doc:    tamperedDoc,
+			pubKey: pubKey,
+			err:    "invalid policy signature",
+		},
+		"wrong key": {
+			doc:    doc,
+			pubKey: otherPubKey,
+			err:    "invalid policy signature",
+		},
+		"older version": {
+			doc:        doc,
+			pubKey:     pubKey,
+			minVersion: 8,
+			err:        "older than the applied version 8",
+		},
+		"no version": {
+			doc:    signPolicy(t, privKey, unversioned),
+			pubKey: pubKey,
+			err:    "policy has no version",
+		},
+		"expired": {
+			doc:    signPolicy(t, privKey, expired),
+			pubKey: pubKey,
+			err:    "policy expired",
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			verified, err := verifyPolicy(tc.doc, tc.pubKey, tc.minVersion, now)
+			if len(tc.err) > 0 {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, policy, verified)
+		})
+	}
+}
+
+func TestApplyPolicy(t *testing.T) {
+	pubKey, privKey := newPolicyKey(t)
+
+	var doc []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(doc)
+	}))
+	defer srv.Close()
+
+	cfg := Config{
+		DataDir:    t.TempDir(),
+		Deviations: []Deviation{{Base: "ATOM", Threshold: "2"}},
+		Policy: PolicyConfig{
+			URL:       srv.URL,
+			PublicKey: base64.StdEncoding.EncodeToString(pubKey),
+		},
+	}
+	publish := func(version uint64, threshold string) {
+		doc = signPolicy(t, privKey, Policy{
+			Version: version,
+			Expires: time.Now().Add(time.Hour),
+			Assets: map[string]AssetPolicy{
+				"ATOM": {DeviationThreshold: "1"},
+				"OSMO": {DeviationThreshold: threshold, MinProviders: 3},
+			},
+		})
+	}
+
+	// the local deviation threshold takes precedence
+	publish(5, "1.5")
+	applied := cfg.ApplyPolicy(context.Background(), zerolog.Nop())
+	require.Equal(t, []Deviation{{Base: "ATOM", Threshold: "2"}, {Base: "OSMO", Threshold: "1.5"}}, applied.Deviations)
+	require.Equal(t, map[string]int{"OSMO": 3}, applied.PolicyMinProviders)
+
+	var stored policyVersionFile
+	require.NoError(t, jsonfile.Read(filepath.Join(cfg.DataDir, PolicyVersionFileName), &stored))
+	require.Equal(t, uint64(5), stored.Version)
+
+	// a replayed older policy is ignored, even by a new process reading the
+	// version from the data dir
+	appliedPolicyMtx.Lock()
+	appliedPolicyVersion = 0
+	appliedPolicyMtx.Unlock()
+
+	publish(4, "3")
+	require.Equal(t, cfg, cfg.ApplyPolicy(context.Background(), zerolog.Nop()))
+
+	publish(6, "3")
+	applied = cfg.ApplyPolicy(context.Background(), zerolog.Nop())
+	require.Equal(t, []Deviation{{Base: "ATOM", Threshold: "2"}, {Base: "OSMO", Threshold: "3"}}, applied.Deviations)
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/persistenceOne/oracle-feeder/pkg/logsafe"
+)
+
+// Secret reference prefixes, see ResolveSecret.
+const (
+	SecretPrefixEnv  = "env:"
+	SecretPrefixFile = "file:"
+)
+
+const (
+	// EnvPrefix prefixes the environment variables the config values can be
+	// read from, see applyFileEnv.
+	EnvPrefix = "ORACLE_FEEDER_"
+
+	// EnvFileSuffix suffixes the environment variables which point at the
+	// file holding a value rather than holding it, as the Docker secrets
+	// convention goes, e.g. ORACLE_FEEDER_KEY_PASSPHRASE_FILE.
+	EnvFileSuffix = "_FILE"
+)
+
+// secretRefKeys are the config keys which only accept secret references, so
+// that they are set to a reference to the file of their *_FILE environment
+// variable instead of its contents.
+var secretRefKeys = map[string]struct{}{
+	"server.admin_secret":   {},
+	"archive.s3.access_key": {},
+	"archive.s3.secret_key": {},
+}
+
+// IsSecretRef returns true if value references a secret instead of holding it.
+func IsSecretRef(value string) bool {
+	return strings.HasPrefix(value, SecretPrefixEnv) || strings.HasPrefix(value, SecretPrefixFile)
+}
+
+// ResolveSecret returns the secret referenced by ref, which is either
+// "env:<NAME>" to read the environment variable NAME or "file:<PATH>" to read
+// the file at PATH, e.g. a mounted Kubernetes or Docker secret. Surrounding
+// whitespace of file contents is trimmed. Secrets are referenced rather than
+// stored in plaintext so that the config file can be shared and versioned.
+// Resolved secrets are redacted from the logs.
+func ResolveSecret(ref string) (string, error) {
+	secret, err := resolveSecret(ref)
+	if err == nil {
+		logsafe.AddSecrets(secret)
+	}
+	return secret, err
+}
+
+func resolveSecret(ref string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, SecretPrefixEnv):
+		name := strings.TrimPrefix(ref, SecretPrefixEnv)
+		value, ok, err := LookupEnv(name)
+		if err != nil {
+			return "", err
+		}
+		if !ok || len(value) == 0 {
+			return "", fmt.Errorf("secret environment variable %s is not set", name)
+		}
+		return value, nil
+
+	case strings.HasPrefix(ref, SecretPrefixFile):
+		return readSecretFile(strings.TrimPrefix(ref, SecretPrefixFile))
+
+	default:
+		return "", fmt.Errorf("secrets must be referenced as %s<NAME> or %s<PATH>", SecretPrefixEnv, SecretPrefixFile)
+	}
+}
+
+// LookupEnv returns the value of the environment variable name or, if unset,
+// the contents of the file its name+EnvFileSuffix variable points at, e.g. a
+// mounted Docker secret. Setting both is ambiguous and fails.
+func LookupEnv(name string) (string, bool, error) {
+	value, ok := os.LookupEnv(name)
+	path, fileOK := os.LookupEnv(name + EnvFileSuffix)
+	switch {
+	case ok && fileOK:
+		return "", false, fmt.Errorf("both %s and %s%s are set", name, name, EnvFileSuffix)
+
+	case fileOK:
+		value, err := readSecretFile(path)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read %s%s: %w", name, EnvFileSuffix, err)
+		}
+		return value, true, nil
+
+	default:
+		return value, ok, nil
+	}
+}
+
+// applyFileEnv sets every config value whose EnvPrefix<KEY>EnvFileSuffix
+// environment variable is set, KEY being the upper-cased config key with its
+// dots replaced by underscores, e.g. ORACLE_FEEDER_KEYRING_MNEMONIC_FILE, to
+// the contents of the file it points at, or to a file reference for the keys
+// which only accept secret references. Values of arrays of tables, e.g. the
+// provider credentials, can't be addressed and are referenced instead.
+func applyFileEnv(v *viper.Viper) error {
+	keys := make(map[string]struct{})
+	for _, key := range v.AllKeys() {
+		keys[key] = struct{}{}
+	}
+	collectConfigKeys(reflect.TypeOf(Config{}), "", keys)
+
+	for key := range keys {
+		name := EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_")) + EnvFileSuffix
+		path, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if _, ok := secretRefKeys[key]; ok {
+			v.Set(key, SecretPrefixFile+path)
+			continue
+		}
+
+		value, err := readSecretFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		v.Set(key, value)
+	}
+
+	return nil
+}
+
+// collectConfigKeys adds the keys of the fields of the config struct t,
+// nested under prefix, to keys.
+func collectConfigKeys(t reflect.Type, prefix string, keys map[string]struct{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+		if len(tag) == 0 || tag == "-" {
+			continue
+		}
+
+		key := prefix + tag
+		if field.Type.Kind() == reflect.Struct {
+			collectConfigKeys(field.Type, key+".", keys)
+			continue
+		}
+		keys[key] = struct{}{}
+	}
+}
+
+// readSecretFile returns the contents of the file at path, trimmed of their
+// surrounding whitespace.
+func readSecretFile(path string) (string, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+
+	value := strings.TrimSpace(string(bz))
+	if len(value) == 0 {
+		return "", fmt.Errorf("secret file %s is empty", path)
+	}
+	return value, nil
+}
@@ -0,0 +1,185 @@
+package oracle
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/armon/go-metrics"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// anomalyWindowSize is the number of voted prices per asset the jumps are
+	// compared to.
+	anomalyWindowSize = 30
+
+	// anomalyMinSamples is the number of voted prices per asset required
+	// before the z-score of a price is checked.
+	anomalyMinSamples = 5
+)
+
+var counterKeyAnomalyHolds = []string{"anomaly", "holds"}
+
+type (
+	// AnomalyDetector holds the vote of an asset for one vote period when its
+	// price jumps from the recently voted prices by more than a relative
+	// change or z-score, unless a quorum of providers corroborates the jump.
+	// A jump which persists is voted in the following period.
+	AnomalyDetector struct {
+		logger    zerolog.Logger
+		maxChange sdk.Dec
+		maxZScore float64
+		quorum    int
+
+		mtx     sync.Mutex
+		history map[string][]sdk.Dec
+		held    map[string]uint64
+	}
+
+	// AnomalyHold defines an asset whose vote is held.
+	AnomalyHold struct {
+		Base         string
+		Price        sdk.Dec
+		LastPrice    sdk.Dec
+		Change       sdk.Dec
+		ZScore       float64
+		Corroborated int
+	}
+)
+
+// NewAnomalyDetector returns a detector considering a price anomalous if its
+// relative change from the last voted price exceeds maxChange, e.g. 0.2 for
+// 20%, or if its z-score over the recently voted prices exceeds maxZScore.
+// A zero maxChange or maxZScore disables the respective check. The jump is
+// corroborated if the prices of at least quorum providers moved towards it.
+func NewAnomalyDetector(logger zerolog.Logger, maxChange sdk.Dec, maxZScore float64, quorum int) *AnomalyDetector {
+	return &AnomalyDetector{
+		logger:    logger.With().Str("module", "anomaly").Logger(),
+		maxChange: maxChange,
+		maxZScore: maxZScore,
+		quorum:    quorum,
+		history:   make(map[string][]sdk.Dec),
+		held:      make(map[string]uint64),
+	}
+}
+
+// Filter returns the prices to vote for in the given vote period, without the
+// prices of the assets held due to an uncorroborated jump. The returned prices
+// are recorded as voted.
+func (ad *AnomalyDetector) Filter(
+	votePeriod uint64,
+	prices map[string]sdk.Dec,
+	providerPrices PricesByProvider,
+) (map[string]sdk.Dec, []AnomalyHold) {
+	ad.mtx.Lock()
+	defer ad.mtx.Unlock()
+
+	filtered := make(map[string]sdk.Dec, len(prices))
+	var holds []AnomalyHold
+
+	for base, price := range prices {
+		hold, anomalous := ad.check(base, price, providerPrices)
+
+		// an asset is held for a single vote period, a persisting jump is
+		// taken as the new price level
+		heldPeriod, wasHeld := ad.held[base]
+		if anomalous && !(wasHeld && heldPeriod+1 == votePeriod) {
+			ad.held[base] = votePeriod
+			holds = append(holds, hold)
+			continue
+		}
+		delete(ad.held, base)
+
+		filtered[base] = price
+		ad.record(base, price)
+	}
+
+	sort.Slice(holds, func(i, j int) bool { return holds[i].Base < holds[j].Base })
+	for _, hold := range holds {
+		ad.logger.Warn().
+			Str("asset", hold.Base).
+			Str("price", hold.Price.String()).
+			Str("last_price", hold.LastPrice.String()).
+			Str("change", hold.Change.String()).
+			Float64("z_score", hold.ZScore).
+			Int("corroborating_providers", hold.Corroborated).
+			Uint64("vote_period", votePeriod).
+			Msg("holding vote for asset with an uncorroborated price jump")
+		telemetry.IncrCounterWithLabels(counterKeyAnomalyHolds, 1, []metrics.Label{telemetry.NewLabel("asset", hold.Base)})
+	}
+
+	return filtered, holds
+}
+
+// check returns whether price is an uncorroborated jump from the voted
+// prices of base.
+func (ad *AnomalyDetector) check(base string, price sdk.Dec, providerPrices PricesByProvider) (AnomalyHold, bool) {
+	history := ad.history[base]
+	if len(history) == 0 {
+		return AnomalyHold{}, false
+	}
+
+	last := history[len(history)-1]
+	hold := AnomalyHold{Base: base, Price: price, LastPrice: last, Change: sdk.ZeroDec()}
+	if last.IsPositive() {
+		hold.Change = price.Sub(last).Quo(last).Abs()
+	}
+	hold.ZScore = zScore(history, price)
+
+	jump := (ad.maxChange.IsPositive() && hold.Change.GT(ad.maxChange)) ||
+		(ad.maxZScore > 0 && len(history) >= anomalyMinSamples && hold.ZScore > ad.maxZScore)
+	if !jump {
+		return hold, false
+	}
+
+	hold.Corroborated = corroboratingProviders(providerPrices, base, price, last)
+	return hold, hold.Corroborated < ad.quorum
+}
+
+func (ad *AnomalyDetector) record(base string, price sdk.Dec) {
+	history := append(ad.history[base], price)
+	if len(history) > anomalyWindowSize {
+		history = history[len(history)-anomalyWindowSize:]
+	}
+	ad.history[base] = history
+}
+
+// corroboratingProviders returns the number of providers whose price of base
+// is closer to price than to the last voted price.
+func corroboratingProviders(providerPrices PricesByProvider, base string, price, last sdk.Dec) int {
+	n := 0
+	for _, prices := range providerPrices {
+		p, ok := prices[base]
+		if ok && p.Sub(price).Abs().LT(p.Sub(last).Abs()) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// zScore returns the number of standard deviations price is away from the
+// mean of history, or zero if the history has no variance.
+func zScore(history []sdk.Dec, price sdk.Dec) float64 {
+	values := make([]float64, len(history))
+	mean := 0.0
+	for i, p := range history {
+		values[i] = p.MustFloat64()
+		mean += values[i]
+	}
+	mean /= float64(len(values))
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	stdDev := math.Sqrt(variance / float64(len(values)))
+	if stdDev == 0 {
+		return 0
+	}
+
+	return math.Abs(price.MustFloat64()-mean) / stdDev
+}
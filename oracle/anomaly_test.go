@@ -0,0 +1,67 @@
+package oracle
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+)
+
+func TestAnomalyDetector(t *testing.T) {
+	ad := NewAnomalyDetector(zerolog.Nop(), sdk.MustNewDecFromStr("0.2"), 0, 2)
+
+	voted, holds := ad.Filter(1, map[string]sdk.Dec{"ATOM": sdk.NewDec(10)}, nil)
+	require.Empty(t, holds)
+	require.Equal(t, sdk.NewDec(10), voted["ATOM"])
+
+	t.Run("uncorroborated jump is held for one period", func(t *testing.T) {
+		providerPrices := PricesByProvider{
+			provider.Binance: {"ATOM": sdk.NewDec(15)},
+			provider.Kraken:  {"ATOM": sdk.NewDec(10)},
+		}
+
+		voted, holds := ad.Filter(2, map[string]sdk.Dec{"ATOM": sdk.NewDec(15)}, providerPrices)
+		require.Empty(t, voted)
+		require.Len(t, holds, 1)
+		require.Equal(t, sdk.MustNewDecFromStr("0.5"), holds[0].Change)
+		require.Equal(t, 1, holds[0].Corroborated)
+
+		// the jump persists, so it is voted in the next period
+		voted, holds = ad.Filter(3, map[string]sdk.Dec{"ATOM": sdk.NewDec(15)}, providerPrices)
+		require.Empty(t, holds)
+		require.Equal(t, sdk.NewDec(15), voted["ATOM"])
+	})
+
+	t.Run("corroborated jump is voted", func(t *testing.T) {
+		providerPrices := PricesByProvider{
+			provider.Binance: {"ATOM": sdk.NewDec(10)},
+			provider.Kraken:  {"ATOM": sdk.MustNewDecFromStr("10.1")},
+		}
+
+		voted, holds := ad.Filter(4, map[string]sdk.Dec{"ATOM": sdk.NewDec(10)}, providerPrices)
+		require.Empty(t, holds)
+		require.Equal(t, sdk.NewDec(10), voted["ATOM"])
+	})
+
+	t.Run("small change is voted", func(t *testing.T) {
+		voted, holds := ad.Filter(5, map[string]sdk.Dec{"ATOM": sdk.MustNewDecFromStr("10.5")}, nil)
+		require.Empty(t, holds)
+		require.Equal(t, sdk.MustNewDecFromStr("10.5"), voted["ATOM"])
+	})
+}
+
+func TestAnomalyDetectorZScore(t *testing.T) {
+	ad := NewAnomalyDetector(zerolog.Nop(), sdk.ZeroDec(), 4, 1)
+
+	for i, price := range []string{"10", "10.1", "9.9", "10", "10.1", "9.9"} {
+		_, holds := ad.Filter(uint64(i), map[string]sdk.Dec{"ATOM": sdk.MustNewDecFromStr(price)}, nil)
+		require.Empty(t, holds)
+	}
+
+	_, holds := ad.Filter(10, map[string]sdk.Dec{"ATOM": sdk.MustNewDecFromStr("11")}, nil)
+	require.Len(t, holds, 1)
+	require.Greater(t, holds[0].ZScore, 4.0)
+}
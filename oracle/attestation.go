@@ -0,0 +1,79 @@
+package oracle
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/persistenceOne/oracle-feeder/config"
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+)
+
+type (
+	// AttestationPayload defines what the validator observed at a point in
+	// time: the prices it computed and the provider prices they were computed
+	// from.
+	AttestationPayload struct {
+		Timestamp time.Time                           `json:"timestamp"`
+		Validator string                              `json:"validator"`
+		Prices    map[string]sdk.Dec                  `json:"prices"`
+		Providers map[provider.Name]provider.Snapshot `json:"providers"`
+	}
+
+	// Attestation defines a payload signed by the feeder key, so that it can
+	// be verified by third parties, e.g. when disputing an oracle slashing.
+	// The signature and public key are base64 encoded, and the signature is
+	// over the payload bytes exactly as archived.
+	Attestation struct {
+		Payload   json.RawMessage `json:"payload"`
+		Signature string          `json:"signature"`
+		PubKey    string          `json:"pub_key"`
+		Signer    string          `json:"signer"`
+	}
+)
+
+// Attest returns the current prices and provider snapshots signed by the
+// feeder key. As attestations are published, they leave out the snapshots of
+// the vote-only providers and the prices of the assets priced by them alone,
+// see config.Config.IsVoteOnly.
+func (o *Oracle) Attest(cfg config.Config) (Attestation, error) {
+	prices := o.GetPrices()
+	aggregations := o.GetAggregations()
+	for base := range prices {
+		if cfg.IsVoteOnlyAsset(aggregations[base].Providers) {
+			delete(prices, base)
+		}
+	}
+
+	snapshots := o.GetProviderSnapshots()
+	for providerName := range snapshots {
+		if cfg.IsVoteOnly(providerName) {
+			delete(snapshots, providerName)
+		}
+	}
+
+	payload, err := json.Marshal(AttestationPayload{
+		Timestamp: time.Now().UTC(),
+		Validator: o.client.ValidatorAddr(),
+		Prices:    prices,
+		Providers: snapshots,
+	})
+	if err != nil {
+		return Attestation{}, fmt.Errorf("failed to encode attestation payload: %w", err)
+	}
+
+	signature, pubKey, err := o.Sign(payload)
+	if err != nil {
+		return Attestation{}, fmt.Errorf("failed to sign attestation: %w", err)
+	}
+
+	return Attestation{
+		Payload:   payload,
+		Signature: base64.StdEncoding.EncodeToString(signature),
+		PubKey:    base64.StdEncoding.EncodeToString(pubKey.Bytes()),
+		Signer:    sdk.AccAddress(pubKey.Address()).String(),
+	}, nil
+}
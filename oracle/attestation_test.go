@@ -0,0 +1,67 @@
+package oracle
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/config"
+	"github.com/persistenceOne/oracle-feeder/oracle/client"
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+// signingClient defines a mocked client signing with privKey.
+type signingClient struct {
+	*client.MockOracleClient
+	privKey *secp256k1.PrivKey
+}
+
+func (sc signingClient) Sign(msg []byte) ([]byte, cryptotypes.PubKey, error) {
+	sig, err := sc.privKey.Sign(msg)
+	return sig, sc.privKey.PubKey(), err
+}
+
+func TestAttestVoteOnlyProviders(t *testing.T) {
+	o, mc := newVotingOracle(t, 20)
+	privKey := secp256k1.GenPrivKey()
+	o.client = signingClient{MockOracleClient: mc, privKey: privKey}
+
+	ticker := types.TickerPrice{Price: sdk.MustNewDecFromStr("29.93"), Volume: sdk.MustNewDecFromStr("894123")}
+	o.prices = map[string]sdk.Dec{"ATOM": ticker.Price, "OSMO": sdk.MustNewDecFromStr("0.75")}
+	o.providerSnapshots = map[provider.Name]provider.Snapshot{
+		provider.Binance: {Tickers: map[string]types.TickerPrice{"ATOMUSDT": ticker}},
+		provider.Kraken:  {Tickers: map[string]types.TickerPrice{"ATOMUSD": ticker}},
+	}
+	o.aggregations = map[string]AssetAggregation{
+		"ATOM": {Providers: []provider.Name{provider.Binance, provider.Kraken}},
+		"OSMO": {Providers: []provider.Name{provider.Binance}},
+	}
+
+	// OSMO is priced by binance alone
+	attestation, err := o.Attest(config.Config{VoteOnlyProviders: []provider.Name{provider.Binance}})
+	require.NoError(t, err)
+
+	sig, err := base64.StdEncoding.DecodeString(attestation.Signature)
+	require.NoError(t, err)
+	require.True(t, privKey.PubKey().VerifySignature(attestation.Payload, sig))
+
+	var payload AttestationPayload
+	require.NoError(t, json.Unmarshal(attestation.Payload, &payload))
+	require.Equal(t, map[string]sdk.Dec{"ATOM": ticker.Price}, payload.Prices)
+	require.Len(t, payload.Providers, 1)
+	require.Contains(t, payload.Providers, provider.Kraken)
+
+	// without vote-only providers, everything is attested
+	attestation, err = o.Attest(config.Config{})
+	require.NoError(t, err)
+	var unfiltered AttestationPayload
+	require.NoError(t, json.Unmarshal(attestation.Payload, &unfiltered))
+	require.Len(t, unfiltered.Prices, 2)
+	require.Len(t, unfiltered.Providers, 2)
+}
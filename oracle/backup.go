@@ -0,0 +1,167 @@
+package oracle
+
+import (
+	"context"
+
+	"github.com/armon/go-metrics"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+
+	"github.com/persistenceOne/oracle-feeder/config"
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+// defaultBackupQuorum is the number of primary providers of a pair which must
+// price it for its backup providers to stay on standby, unless configured.
+// It is capped at the number of primary providers.
+const defaultBackupQuorum = 2
+
+var counterKeyBackupActivations = []string{"backup", "activations"}
+
+// BackupPool defines the backup providers of a currency pair, which are only
+// connected to and queried while fewer than Quorum of its primary providers
+// price it. Once the primaries reach the quorum again, the backups are no
+// longer queried and those without other pairs to price are stopped.
+type BackupPool struct {
+	Pair      types.CurrencyPair
+	Primaries []provider.Name
+	Backups   []provider.Name
+	Quorum    int
+	Active    bool
+}
+
+// newBackupPools returns the backup pools of the currency pairs with backup
+// providers.
+func newBackupPools(currencyPairs []config.CurrencyPair) []*BackupPool {
+	var pools []*BackupPool
+	for _, pair := range currencyPairs {
+		if len(pair.BackupProviders) == 0 {
+			continue
+		}
+
+		quorum := pair.BackupQuorum
+		if quorum == 0 {
+			quorum = defaultBackupQuorum
+		}
+		if quorum > len(pair.Providers) {
+			quorum = len(pair.Providers)
+		}
+
+		pools = append(pools, &BackupPool{
+			Pair:      types.CurrencyPair{Base: pair.Base, Quote: pair.Quote},
+			Primaries: pair.Providers,
+			Backups:   pair.BackupProviders,
+			Quorum:    quorum,
+		})
+	}
+
+	return pools
+}
+
+// tickProviderPairs returns the pairs to query from every provider during a
+// tick: the pairs of the primary providers and those of the active backups.
+func (o *Oracle) tickProviderPairs() map[provider.Name][]types.CurrencyPair {
+	providerPairs := make(map[provider.Name][]types.CurrencyPair, len(o.providerPairs))
+	for providerName, pairs := range o.providerPairs {
+		providerPairs[providerName] = append([]types.CurrencyPair{}, pairs...)
+	}
+	for _, pool := range o.backupPools {
+		if !pool.Active {
+			continue
+		}
+		for _, providerName := range pool.Backups {
+			providerPairs[providerName] = append(providerPairs[providerName], pool.Pair)
+		}
+	}
+
+	return providerPairs
+}
+
+// updateBackups activates the backups of the pairs priced by fewer than the
+// quorum of their primary providers during the tick, connecting to them so
+// that they are queried from the next tick on, and puts back on standby those
+// whose primaries reached the quorum again, disconnecting from them.
+func (o *Oracle) updateBackups(
+	ctx context.Context,
+	providerPrices provider.AggregatedProviderPrices,
+	providerCandles provider.AggregatedProviderCandles,
+) {
+	for _, pool := range o.backupPools {
+		var priced int
+		for _, providerName := range pool.Primaries {
+			_, hasPrice := providerPrices[providerName][pool.Pair.Base]
+			if hasPrice || len(providerCandles[providerName][pool.Pair.Base]) > 0 {
+				priced++
+			}
+		}
+
+		switch {
+		case priced < pool.Quorum && !pool.Active:
+			pool.Active = true
+			o.activateBackups(ctx, pool)
+			o.logger.Warn().
+				Str("pair", pool.Pair.String()).
+				Int("priced", priced).
+				Int("quorum", pool.Quorum).
+				Interface("backups", pool.Backups).
+				Msg("primary providers below quorum; activating backup providers")
+			telemetry.IncrCounterWithLabels(counterKeyBackupActivations, 1, []metrics.Label{
+				telemetry.NewLabel("pair", pool.Pair.String()),
+			})
+
+		case priced >= pool.Quorum && pool.Active:
+			pool.Active = false
+			for _, providerName := range pool.Backups {
+				if len(o.pairsOf(providerName)) == 0 {
+					o.removeProvider(providerName)
+				}
+			}
+			o.logger.Info().
+				Str("pair", pool.Pair.String()).
+				Int("priced", priced).
+				Msg("primary providers reached quorum; backup providers back on standby")
+		}
+	}
+}
+
+// activateBackups connects to the backups of the pool not connected yet and
+// subscribes the others to its pair. Failures are only logged, the backups
+// being retried from the next tick on.
+func (o *Oracle) activateBackups(ctx context.Context, pool *BackupPool) {
+	for _, providerName := range pool.Backups {
+		if priceProvider, ok := o.priceProviders[providerName]; ok {
+			if err := priceProvider.SubscribeCurrencyPairs(ctx, pool.Pair); err != nil {
+				o.logger.Err(err).
+					Str("provider", providerName.String()).
+					Str("pair", pool.Pair.String()).
+					Msg("failed to subscribe backup provider")
+			}
+			continue
+		}
+
+		if _, err := o.getOrSetProvider(ctx, providerName); err != nil {
+			o.logger.Err(err).
+				Str("provider", providerName.String()).
+				Str("pair", pool.Pair.String()).
+				Msg("failed to connect backup provider")
+		}
+	}
+}
+
+// pairsOf returns the pairs of the provider during a tick, see
+// tickProviderPairs.
+func (o *Oracle) pairsOf(providerName provider.Name) []types.CurrencyPair {
+	pairs := append([]types.CurrencyPair{}, o.providerPairs[providerName]...)
+	for _, pool := range o.backupPools {
+		if !pool.Active {
+			continue
+		}
+		for _, backup := range pool.Backups {
+			if backup == providerName {
+				pairs = append(pairs, pool.Pair)
+			}
+		}
+	}
+
+	return pairs
+}
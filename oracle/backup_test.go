@@ -0,0 +1,56 @@
+package oracle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/config"
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+func TestBackupProviders(t *testing.T) {
+	pair := config.CurrencyPair{
+		Base:            "ATOM",
+		Quote:           "USD",
+		Providers:       []provider.Name{provider.Binance},
+		BackupProviders: []provider.Name{provider.Kraken},
+	}
+	o := New(zerolog.Nop(), nil, []config.CurrencyPair{pair}, time.Second, nil, nil)
+	require.Equal(t, 1, o.backupPools[0].Quorum)
+
+	primary := staticProvider{
+		"ATOMUSD": {Price: sdk.MustNewDecFromStr("29.93"), Volume: sdk.MustNewDecFromStr("894123")},
+	}
+	o.priceProviders[provider.Binance] = primary
+	// the backup is already connected, it must not be queried while on standby
+	o.priceProviders[provider.Kraken] = staticProvider{
+		"ATOMUSD": {Price: sdk.MustNewDecFromStr("30.10"), Volume: sdk.MustNewDecFromStr("1000")},
+	}
+
+	require.NoError(t, o.setPrices(context.Background()))
+	require.False(t, o.backupPools[0].Active)
+	require.Equal(t, sdk.MustNewDecFromStr("29.93"), o.GetPrices()["ATOM"])
+	require.NotContains(t, o.tickProviderPairs(), provider.Kraken)
+
+	// the primary stops pricing the pair, the backup is queried from the next tick
+	o.priceProviders[provider.Binance] = staticProvider{}
+	_ = o.setPrices(context.Background())
+	require.True(t, o.backupPools[0].Active)
+	require.Equal(t, []types.CurrencyPair{{Base: "ATOM", Quote: "USD"}}, o.tickProviderPairs()[provider.Kraken])
+
+	_ = o.setPrices(context.Background())
+	require.Equal(t, sdk.MustNewDecFromStr("30.10"), o.GetPrices()["ATOM"])
+
+	// the primary recovers, the backup goes back on standby
+	o.priceProviders[provider.Binance] = primary
+	require.NoError(t, o.setPrices(context.Background()))
+	require.False(t, o.backupPools[0].Active)
+	require.NotContains(t, o.tickProviderPairs(), provider.Kraken)
+	require.NotContains(t, o.priceProviders, provider.Kraken)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/rs/zerolog"
 	tmrpcclient "github.com/tendermint/tendermint/rpc/client"
@@ -27,6 +28,13 @@ type ChainHeight struct {
 	mtx               sync.RWMutex
 	errGetChainHeight error
 	lastChainHeight   int64
+	updatedAt         time.Time
+	newBlock          chan struct{}
+
+	// subscription identifies the current new block header subscription,
+	// which is cancelled when subscribing to another node.
+	subscription       uint64
+	cancelSubscription context.CancelFunc
 }
 
 // newChainHeight returns a new ChainHeight struct that
@@ -41,27 +49,60 @@ func newChainHeight(
 		return nil, fmt.Errorf("expected positive initial block height")
 	}
 
+	chainHeight := &ChainHeight{
+		Logger:            logger.With().Str("oracle_client", "chain_height").Logger(),
+		errGetChainHeight: nil,
+		lastChainHeight:   initialHeight,
+		updatedAt:         time.Now(),
+	}
+
+	if err := chainHeight.resubscribe(ctx, rpcClient, initialHeight); err != nil {
+		return nil, err
+	}
+
+	return chainHeight, nil
+}
+
+// resubscribe subscribes to the new block headers of the node behind
+// rpcClient, replacing the current subscription, e.g. when failing over to
+// another node. The height of that node is taken as the chain height unless
+// lower, and its age is reset so that the node is given time to catch up.
+func (ch *ChainHeight) resubscribe(ctx context.Context, rpcClient tmrpcclient.Client, blockHeight int64) error {
 	if !rpcClient.IsRunning() {
 		if err := rpcClient.Start(); err != nil {
-			return nil, err
+			return err
 		}
 	}
 
 	newBlockHeaderSubscription, err := rpcClient.Subscribe(
 		ctx, tmtypes.EventNewBlockHeader, queryEventNewBlockHeader.String())
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	chainHeight := &ChainHeight{
-		Logger:            logger.With().Str("oracle_client", "chain_height").Logger(),
-		errGetChainHeight: nil,
-		lastChainHeight:   initialHeight,
+	subscriptionCtx, cancel := context.WithCancel(ctx)
+
+	ch.mtx.Lock()
+	if ch.cancelSubscription != nil {
+		ch.cancelSubscription()
+	}
+	ch.subscription++
+	ch.cancelSubscription = cancel
+	subscription := ch.subscription
+	if blockHeight < ch.lastChainHeight {
+		blockHeight = ch.lastChainHeight
 	}
+	ch.mtx.Unlock()
 
-	go chainHeight.subscribe(ctx, rpcClient, newBlockHeaderSubscription)
+	ch.updateChainHeight(blockHeight, nil)
 
-	return chainHeight, nil
+	ch.mtx.Lock()
+	ch.updatedAt = time.Now()
+	ch.mtx.Unlock()
+
+	go ch.subscribe(ctx, subscriptionCtx, subscription, rpcClient, newBlockHeaderSubscription)
+
+	return nil
 }
 
 // updateChainHeight receives the data to be updated thread safe.
@@ -69,32 +110,54 @@ func (ch *ChainHeight) updateChainHeight(blockHeight int64, err error) {
 	ch.mtx.Lock()
 	defer ch.mtx.Unlock()
 
+	if blockHeight > ch.lastChainHeight {
+		ch.updatedAt = time.Now()
+		if ch.newBlock != nil {
+			close(ch.newBlock)
+			ch.newBlock = nil
+		}
+	}
 	ch.lastChainHeight = blockHeight
 	ch.errGetChainHeight = err
 }
 
 // subscribe listens to new blocks being made
-// and updates the chain height.
+// and updates the chain height until the subscription is cancelled.
+// The client of a replaced subscription is stopped, and its errors are
+// no longer reported.
 func (ch *ChainHeight) subscribe(
 	ctx context.Context,
-	eventsClient tmrpcclient.EventsClient,
+	subscriptionCtx context.Context,
+	subscription uint64,
+	rpcClient tmrpcclient.Client,
 	newBlockHeaderSubscription <-chan tmctypes.ResultEvent,
 ) {
 	// Unsubscribe from new block header events and log an error if it fails
 	defer func() {
-		err := eventsClient.Unsubscribe(ctx, tmtypes.EventNewBlockHeader, queryEventNewBlockHeader.String())
+		replaced := !ch.isSubscription(subscription)
+		err := rpcClient.Unsubscribe(ctx, tmtypes.EventNewBlockHeader, queryEventNewBlockHeader.String())
 		if err != nil {
-			ch.Logger.Err(err)
-			ch.updateChainHeight(ch.lastChainHeight, err)
+			ch.Logger.Err(err).Msg("failed to unsubscribe from new block headers")
+			if !replaced {
+				ch.updateChainHeight(ch.lastChainHeight, err)
+			}
+		}
+		if replaced {
+			if err := rpcClient.Stop(); err != nil {
+				ch.Logger.Err(err).Msg("failed to stop the replaced RPC client")
+			}
 		}
 		ch.Logger.Info().Msg("closing the ChainHeight new block header subscription")
 	}()
 
 	for {
 		select {
-		case <-ctx.Done():
+		case <-subscriptionCtx.Done():
 			return
 		case resultEvent := <-newBlockHeaderSubscription:
+			if !ch.isSubscription(subscription) {
+				return
+			}
 			eventDataNewBlockHeader, ok := resultEvent.Data.(tmtypes.EventDataNewBlockHeader)
 			if !ok {
 				ch.Logger.Err(errParseEventDataNewBlockHeader)
@@ -106,6 +169,36 @@ func (ch *ChainHeight) subscribe(
 	}
 }
 
+// NewBlock returns a channel which is closed once a block higher than the
+// last chain height available is received.
+func (ch *ChainHeight) NewBlock() <-chan struct{} {
+	ch.mtx.Lock()
+	defer ch.mtx.Unlock()
+
+	if ch.newBlock == nil {
+		ch.newBlock = make(chan struct{})
+	}
+
+	return ch.newBlock
+}
+
+// isSubscription returns true if subscription is the current one.
+func (ch *ChainHeight) isSubscription(subscription uint64) bool {
+	ch.mtx.RLock()
+	defer ch.mtx.RUnlock()
+
+	return ch.subscription == subscription
+}
+
+// UpdatedAt returns when the chain height last increased, or was last
+// subscribed to.
+func (ch *ChainHeight) UpdatedAt() time.Time {
+	ch.mtx.RLock()
+	defer ch.mtx.RUnlock()
+
+	return ch.updatedAt
+}
+
 // GetChainHeight returns the last chain height available.
 func (ch *ChainHeight) GetChainHeight() (int64, error) {
 	ch.mtx.RLock()
@@ -2,7 +2,11 @@ package client
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/cosmos/cosmos-sdk/client"
@@ -10,6 +14,8 @@ import (
 	rpcclient "github.com/cosmos/cosmos-sdk/client/rpc"
 	"github.com/cosmos/cosmos-sdk/client/tx"
 	cosmkeyring "github.com/cosmos/cosmos-sdk/crypto/keyring"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/cosmos/cosmos-sdk/telemetry"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/tx/signing"
 	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
@@ -18,21 +24,91 @@ import (
 	rpchttp "github.com/tendermint/tendermint/rpc/client/http"
 	tmjsonclient "github.com/tendermint/tendermint/rpc/jsonrpc/client"
 
-	"github.com/persistenceOne/persistenceCore/v8/app"
-	"github.com/persistenceOne/persistenceCore/v8/app/params"
+	oracletypes "github.com/persistenceOne/persistence-sdk/v2/x/oracle/types"
 
+	"github.com/persistenceOne/oracle-feeder/pkg/audit"
+	"github.com/persistenceOne/oracle-feeder/pkg/features"
 	"github.com/persistenceOne/oracle-feeder/pkg/keyring"
 )
 
+var _ OracleClient = ChainClient{}
+
 const (
 	wsEndPoint    = "/websocket"
 	jsonFormat    = "json"
 	oracleAppName = "oracle"
+
+	// broadcastBlockTimeout is the minimum RPC timeout in block mode, above the
+	// default timeout_broadcast_tx_commit of Tendermint nodes.
+	broadcastBlockTimeout = 15 * time.Second
+)
+
+var (
+	counterKeyBroadcastRetries   = []string{"broadcast", "retries"}
+	gaugeKeyPendingConfirmations = []string{"broadcast", "pending_confirmations"}
 )
 
 type (
-	// OracleClient defines a structure that interfaces with the persistence node.
-	OracleClient struct {
+	// OracleClient defines the chain operations the oracle depends on to
+	// submit its votes.
+	OracleClient interface {
+		// GetHeight returns the latest known block height of the chain.
+		GetHeight() (int64, error)
+
+		// NewBlock returns a channel which is closed once a block higher than
+		// the one reported by GetHeight is received.
+		NewBlock() <-chan struct{}
+
+		// HeightUpdatedAt returns when the block height last increased.
+		HeightUpdatedAt() time.Time
+
+		// Failover switches to the next configured node, e.g. when the node
+		// in use stalls.
+		Failover(ctx context.Context) error
+
+		// GetParams returns the current on-chain parameters of the x/oracle module.
+		GetParams(context.Context) (oracletypes.Params, error)
+
+		// GetAggregatePrevote returns the aggregate pre-vote currently stored
+		// on-chain for the validator.
+		GetAggregatePrevote(context.Context) (oracletypes.AggregateExchangeRatePrevote, error)
+
+		// BroadcastPrevote broadcasts an aggregate pre-vote, re-attempting until
+		// it can no longer be included by timeoutHeight.
+		BroadcastPrevote(ctx context.Context, nextBlockHeight, timeoutHeight int64,
+			msg *oracletypes.MsgAggregateExchangeRatePrevote) error
+
+		// BroadcastVote broadcasts an aggregate vote, re-attempting until it can
+		// no longer be included by timeoutHeight.
+		BroadcastVote(ctx context.Context, nextBlockHeight, timeoutHeight int64,
+			msg *oracletypes.MsgAggregateExchangeRateVote) error
+
+		// Sign signs an arbitrary message with the feeder key.
+		Sign(msg []byte) ([]byte, cryptotypes.PubKey, error)
+
+		// FeederAddr returns the bech32 address of the feeder account.
+		FeederAddr() string
+
+		// ValidatorAddr returns the bech32 address of the validator voted for.
+		ValidatorAddr() string
+	}
+
+	// SpendRecorder records the gas used and fees paid by the transactions of
+	// the feeder account included in a block.
+	SpendRecorder interface {
+		RecordSpend(gasUsed int64, fees sdk.Coins)
+	}
+
+	// LatencyRecorder records when the transactions of the feeder account,
+	// identified by the type URLs of their messages, are accepted by the node
+	// and included in a block.
+	LatencyRecorder interface {
+		RecordBroadcast(msgTypes []string)
+		RecordInclusion(msgTypes []string)
+	}
+
+	// ChainClient implements the OracleClient interfacing with the persistence node.
+	ChainClient struct {
 		Logger              zerolog.Logger
 		ChainID             string
 		TMRPC               string
@@ -41,12 +117,35 @@ type (
 		OracleAddrString    string
 		Keyring             cosmkeyring.Keyring
 		ValidatorAddrString string
-		Encoding            params.EncodingConfig
+		Encoding            EncodingConfig
 		GasPrices           string
 		GasAdjustment       float64
 		GRPCEndpoint        string
 		ChainHeight         *ChainHeight
 		Fees                string
+		Audit               *audit.Trail
+
+		// Spend records the spend of the included transactions, if not nil.
+		Spend SpendRecorder
+
+		// Latency records the broadcast and inclusion of the transactions, if
+		// not nil.
+		Latency LatencyRecorder
+
+		// BroadcastMode is the mode transactions are broadcasted with, see
+		// BroadcastTx. It defaults to flags.BroadcastSync.
+		BroadcastMode string
+
+		// Features overrides the broadcast mode with the async one while the
+		// features.AsyncBroadcast flag is enabled.
+		Features *features.Flags
+
+		// rpcEndpoints holds TMRPC and its fallbacks, see Failover.
+		rpcEndpoints *rpcEndpoints
+
+		// pendingConfirmations counts the transactions confirmed in the
+		// background, see BroadcastTx.
+		pendingConfirmations *atomic.Int64
 	}
 )
 
@@ -61,14 +160,15 @@ func NewOracleClient(
 	keyPrivHex string,
 	keyMnemonic string,
 	tmRPC string,
+	tmRPCFallbacks []string,
 	rpcTimeout time.Duration,
 	oracleAddrString string,
 	validatorAddrString string,
 	grpcEndpoint string,
 	gasAdjustment float64,
 	fees string,
-) (OracleClient, error) {
-	encodingConfig := app.MakeEncodingConfig()
+) (ChainClient, error) {
+	encodingConfig := MakeEncodingConfig()
 
 	oracleAddr, kb, err := keyring.NewCosmosKeyring(
 		encodingConfig.Marshaler,
@@ -81,10 +181,10 @@ func NewOracleClient(
 	)
 	if err != nil {
 		err = errors.Wrap(err, "failed to initialize client keyring")
-		return OracleClient{}, err
+		return ChainClient{}, err
 	}
 
-	oracleClient := OracleClient{
+	oracleClient := ChainClient{
 		Logger:              logger.With().Str("module", "oracle_client").Logger(),
 		ChainID:             chainID,
 		TMRPC:               tmRPC,
@@ -97,16 +197,19 @@ func NewOracleClient(
 		GasAdjustment:       gasAdjustment,
 		GRPCEndpoint:        grpcEndpoint,
 		Fees:                fees,
+		rpcEndpoints:        newRPCEndpoints(tmRPC, tmRPCFallbacks...),
+
+		pendingConfirmations: new(atomic.Int64),
 	}
 
 	clientCtx, err := oracleClient.createClientContext()
 	if err != nil {
-		return OracleClient{}, err
+		return ChainClient{}, err
 	}
 
 	blockHeight, err := rpcclient.GetChainHeight(clientCtx)
 	if err != nil {
-		return OracleClient{}, err
+		return ChainClient{}, err
 	}
 
 	chainHeight, err := newChainHeight(
@@ -116,17 +219,68 @@ func NewOracleClient(
 		blockHeight,
 	)
 	if err != nil {
-		return OracleClient{}, err
+		return ChainClient{}, err
 	}
 	oracleClient.ChainHeight = chainHeight
 
 	return oracleClient, nil
 }
 
+// GetHeight returns the latest block height cached by the ChainHeight.
+func (oc ChainClient) GetHeight() (int64, error) {
+	return oc.ChainHeight.GetChainHeight()
+}
+
+// NewBlock returns a channel which is closed once a new block is received
+// through the new block header subscription.
+func (oc ChainClient) NewBlock() <-chan struct{} {
+	return oc.ChainHeight.NewBlock()
+}
+
+// FeederAddr returns the bech32 address of the feeder account.
+func (oc ChainClient) FeederAddr() string {
+	return oc.OracleAddrString
+}
+
+// ValidatorAddr returns the bech32 address of the validator voted for.
+func (oc ChainClient) ValidatorAddr() string {
+	return oc.ValidatorAddrString
+}
+
+// BroadcastPrevote broadcasts an aggregate pre-vote, see BroadcastTx.
+func (oc ChainClient) BroadcastPrevote(
+	ctx context.Context,
+	nextBlockHeight, timeoutHeight int64,
+	msg *oracletypes.MsgAggregateExchangeRatePrevote,
+) error {
+	return oc.BroadcastTx(ctx, nextBlockHeight, timeoutHeight, msg)
+}
+
+// BroadcastVote broadcasts an aggregate vote, see BroadcastTx.
+func (oc ChainClient) BroadcastVote(
+	ctx context.Context,
+	nextBlockHeight, timeoutHeight int64,
+	msg *oracletypes.MsgAggregateExchangeRateVote,
+) error {
+	return oc.BroadcastTx(ctx, nextBlockHeight, timeoutHeight, msg)
+}
+
 // BroadcastTx attempts to broadcast a signed transaction. If it fails, a few re-attempts
 // will be made until the transaction succeeds or ultimately times out or fails.
-func (oc OracleClient) BroadcastTx(ctx context.Context, nextBlockHeight, timeoutHeight int64, msgs ...sdk.Msg) error {
-	maxBlockHeight := nextBlockHeight + timeoutHeight
+//
+// In sync mode, the transaction must pass CheckTx and is then awaited until included in
+// a block. In block mode, the node only responds once the transaction is committed, so
+// that failures in DeliverTx are re-attempted as well. In async mode, BroadcastTx returns
+// as soon as the node received the transaction, whose inclusion is only confirmed and
+// logged in the background: failures past that point are not reported to the caller.
+//
+// The transaction is only valid up to and including the block at timeoutHeight, which
+// is set as the timeout height of its body, so that it can never be included past it,
+// e.g. in the next vote period. No attempt is made once the latest block reached it.
+func (oc ChainClient) BroadcastTx(ctx context.Context, nextBlockHeight, timeoutHeight int64, msgs ...sdk.Msg) error {
+	if timeoutHeight < nextBlockHeight {
+		return errors.Errorf("timeout height %d is before the next block %d", timeoutHeight, nextBlockHeight)
+	}
 	lastCheckHeight := nextBlockHeight - 1
 
 	clientCtx, err := oc.createClientContext()
@@ -138,9 +292,10 @@ func (oc OracleClient) BroadcastTx(ctx context.Context, nextBlockHeight, timeout
 	if err != nil {
 		return err
 	}
+	factory = factory.WithTimeoutHeight(uint64(timeoutHeight))
 
-	// re-try voting until timeout
-	for lastCheckHeight < maxBlockHeight {
+	// re-try voting until the next block is past the timeout height
+	for lastCheckHeight < timeoutHeight {
 		latestBlockHeight, err := oc.ChainHeight.GetChainHeight()
 		if err != nil {
 			return err
@@ -152,8 +307,28 @@ func (oc OracleClient) BroadcastTx(ctx context.Context, nextBlockHeight, timeout
 
 		// set last check height to latest block height
 		lastCheckHeight = latestBlockHeight
+		if latestBlockHeight >= timeoutHeight {
+			// the transaction can no longer be included
+			break
+		}
 
-		resp, err := broadcastTx(ctx, clientCtx, factory, msgs...)
+		var fees sdk.Coins
+		resp, err := broadcastTx(ctx, clientCtx, factory, func(txHash string, txFees sdk.Coins) {
+			fees = txFees
+			oc.Audit.Record(ctx, audit.Entry{
+				Operation: audit.OperationSignTx,
+				Signer:    oc.OracleAddrString,
+				MsgTypes:  msgTypes(msgs),
+				Height:    latestBlockHeight,
+				TxHash:    txHash,
+			})
+		}, func() {
+			oc.recordBroadcast(msgs)
+		}, msgs...)
+		if resp != nil && resp.Height > 0 {
+			// the fees of an included tx are paid even if it failed
+			oc.recordSpend(resp.GasUsed, fees)
+		}
 		if err != nil {
 			var (
 				code uint32
@@ -166,16 +341,28 @@ func (oc OracleClient) BroadcastTx(ctx context.Context, nextBlockHeight, timeout
 
 			oc.Logger.Debug().
 				Err(err).
-				Int64("max_height", maxBlockHeight).
+				Int64("timeout_height", timeoutHeight).
 				Int64("last_check_height", lastCheckHeight).
 				Str("tx_hash", hash).
 				Uint32("tx_code", code).
 				Msg("failed to broadcast tx; retrying...")
+			telemetry.IncrCounter(1, counterKeyBroadcastRetries...)
 
 			time.Sleep(time.Second * 1)
 			continue
 		}
 
+		if clientCtx.BroadcastMode == flags.BroadcastAsync {
+			oc.Logger.Info().
+				Str("tx_hash", resp.TxHash).
+				Msg("broadcasted tx; confirming in the background")
+			oc.trackConfirmations(1)
+			go oc.confirmTx(ctx, clientCtx, resp.TxHash, fees, timeoutHeight, msgs)
+
+			return nil
+		}
+		oc.recordInclusion(msgs)
+
 		oc.Logger.Info().
 			Uint32("tx_code", resp.Code).
 			Str("tx_hash", resp.TxHash).
@@ -188,17 +375,158 @@ func (oc OracleClient) BroadcastTx(ctx context.Context, nextBlockHeight, timeout
 	return errors.New("broadcasting tx timed out")
 }
 
+// confirmTx waits for the transaction broadcasted asynchronously with hash to be
+// included by timeoutHeight, logging its outcome since nothing awaits it, and
+// records its spend once included.
+func (oc ChainClient) confirmTx(
+	ctx context.Context,
+	clientCtx client.Context,
+	hash string,
+	fees sdk.Coins,
+	timeoutHeight int64,
+	msgs []sdk.Msg,
+) {
+	defer oc.trackConfirmations(-1)
+	logger := oc.Logger.With().Str("tx_hash", hash).Logger()
+
+	bz, err := hex.DecodeString(hash)
+	if err != nil {
+		logger.Err(err).Msg("failed to confirm tx")
+		return
+	}
+
+	for {
+		// the height is read first, so that a tx which is not found by then
+		// can no longer be included once the timeout height is reached
+		newBlock := oc.ChainHeight.NewBlock()
+		height, err := oc.ChainHeight.GetChainHeight()
+		if err != nil {
+			logger.Err(err).Msg("failed to confirm tx")
+			return
+		}
+
+		resp, err := clientCtx.Client.Tx(ctx, bz, false)
+		if err == nil {
+			oc.recordSpend(resp.TxResult.GasUsed, fees)
+		}
+
+		switch {
+		case err == nil && resp.TxResult.Code != 0:
+			logger.Error().
+				Uint32("tx_code", resp.TxResult.Code).
+				Int64("tx_height", resp.Height).
+				Str("log", resp.TxResult.Log).
+				Msg("tx failed")
+			return
+
+		case err == nil:
+			logger.Info().Int64("tx_height", resp.Height).Msg("successfully confirmed tx")
+			oc.recordInclusion(msgs)
+			return
+
+		case !strings.Contains(err.Error(), "not found"):
+			logger.Err(err).Msg("failed to confirm tx")
+			return
+
+		case height >= timeoutHeight:
+			logger.Error().Int64("timeout_height", timeoutHeight).Msg("tx was not included before its timeout height")
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-newBlock:
+		}
+	}
+}
+
+// trackConfirmations adds delta to the number of transactions awaiting their
+// confirmation in the background and emits it, so that a node slow to include
+// them shows as a growing backlog.
+func (oc ChainClient) trackConfirmations(delta int64) {
+	if oc.pendingConfirmations == nil {
+		return
+	}
+	telemetry.SetGauge(float32(oc.pendingConfirmations.Add(delta)), gaugeKeyPendingConfirmations...)
+}
+
+// recordSpend records the spend of an included transaction.
+func (oc ChainClient) recordSpend(gasUsed int64, fees sdk.Coins) {
+	if oc.Spend == nil {
+		return
+	}
+	oc.Spend.RecordSpend(gasUsed, fees)
+}
+
+// recordBroadcast records the broadcast of a transaction accepted by the node.
+func (oc ChainClient) recordBroadcast(msgs []sdk.Msg) {
+	if oc.Latency == nil {
+		return
+	}
+	oc.Latency.RecordBroadcast(msgTypes(msgs))
+}
+
+// recordInclusion records the inclusion of a transaction in a block.
+func (oc ChainClient) recordInclusion(msgs []sdk.Msg) {
+	if oc.Latency == nil {
+		return
+	}
+	oc.Latency.RecordInclusion(msgTypes(msgs))
+}
+
+// Sign signs an arbitrary message with the feeder key, returning the signature
+// and the public key it can be verified with.
+func (oc ChainClient) Sign(msg []byte) ([]byte, cryptotypes.PubKey, error) {
+	if oc.Keyring == nil {
+		return nil, nil, errors.New("keyring is not initialized")
+	}
+
+	sig, pubKey, err := oc.Keyring.SignByAddress(oc.OracleAddr, msg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var height int64
+	if oc.ChainHeight != nil {
+		height, _ = oc.ChainHeight.GetChainHeight()
+	}
+	msgHash := sha256.Sum256(msg)
+	oc.Audit.Record(context.Background(), audit.Entry{
+		Operation:   audit.OperationSignMessage,
+		Signer:      oc.OracleAddrString,
+		Height:      height,
+		MessageHash: hex.EncodeToString(msgHash[:]),
+	})
+
+	return sig, pubKey, nil
+}
+
+func msgTypes(msgs []sdk.Msg) []string {
+	typeURLs := make([]string, len(msgs))
+	for i, msg := range msgs {
+		typeURLs[i] = sdk.MsgTypeURL(msg)
+	}
+
+	return typeURLs
+}
+
 // createClientContext creates an SDK client Context instance used for transaction
 // generation, signing and broadcasting.
-func (oc OracleClient) createClientContext() (client.Context, error) {
-	httpClient, err := tmjsonclient.DefaultHTTPClient(oc.TMRPC)
+func (oc ChainClient) createClientContext() (client.Context, error) {
+	tmRPCEndpoint := oc.tmRPC()
+	httpClient, err := tmjsonclient.DefaultHTTPClient(tmRPCEndpoint)
 	if err != nil {
 		return client.Context{}, err
 	}
 
 	httpClient.Timeout = oc.RPCTimeout
+	if oc.broadcastMode() == flags.BroadcastBlock && httpClient.Timeout < broadcastBlockTimeout {
+		// the node only responds once the tx is committed
+		httpClient.Timeout = broadcastBlockTimeout
+	}
 
-	tmRPC, err := rpchttp.NewWithClient(oc.TMRPC, wsEndPoint, httpClient)
+	tmRPC, err := rpchttp.NewWithClient(tmRPCEndpoint, wsEndPoint, httpClient)
 	if err != nil {
 		return client.Context{}, err
 	}
@@ -212,13 +540,13 @@ func (oc OracleClient) createClientContext() (client.Context, error) {
 		ChainID:           oc.ChainID,
 		InterfaceRegistry: oc.Encoding.InterfaceRegistry,
 		Output:            os.Stderr,
-		BroadcastMode:     flags.BroadcastSync,
+		BroadcastMode:     oc.broadcastMode(),
 		TxConfig:          oc.Encoding.TransactionConfig,
 		AccountRetriever:  authtypes.AccountRetriever{},
 		Codec:             oc.Encoding.Marshaler,
 		LegacyAmino:       oc.Encoding.Amino,
 		Input:             os.Stdin,
-		NodeURI:           oc.TMRPC,
+		NodeURI:           tmRPCEndpoint,
 		Client:            tmRPC,
 		Keyring:           oc.Keyring,
 		FromAddress:       oc.OracleAddr,
@@ -235,9 +563,22 @@ func (oc OracleClient) createClientContext() (client.Context, error) {
 	return clientCtx, nil
 }
 
+// broadcastMode returns the configured broadcast mode, sync by default, or
+// the async one while the async broadcast feature is enabled.
+func (oc ChainClient) broadcastMode() string {
+	if oc.Features.Enabled(features.AsyncBroadcast) {
+		return flags.BroadcastAsync
+	}
+	if len(oc.BroadcastMode) == 0 {
+		return flags.BroadcastSync
+	}
+
+	return oc.BroadcastMode
+}
+
 // createTxFactory creates an SDK Factory instance used for transaction
 // generation, signing and broadcasting.
-func (oc OracleClient) createTxFactory() (tx.Factory, error) {
+func (oc ChainClient) createTxFactory() (tx.Factory, error) {
 	clientCtx, err := oc.createClientContext()
 	if err != nil {
 		return tx.Factory{}, err
@@ -0,0 +1,487 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+
+	"github.com/persistenceOne/oracle-feeder/pkg/features"
+	"github.com/persistenceOne/oracle-feeder/pkg/keyring"
+
+	oracletypes "github.com/persistenceOne/persistence-sdk/v2/x/oracle/types"
+)
+
+const (
+	testPrivKeyHex    = "e6888cb164d52e4880e08fdae7ce16e5d7ac5dc0bd4ac7c66e7ac3b8d9849ed1"
+	testFees          = "2000uxprt"
+	testAccountNumber = 7
+)
+
+// broadcastedTx defines a transaction received by the fakeNode.
+type broadcastedTx struct {
+	Sequence      uint64
+	Fee           sdk.Coins
+	TimeoutHeight uint64
+}
+
+// fakeNode defines a Tendermint RPC server serving the queries and
+// broadcasts of ChainClient.BroadcastTx, rejecting the successive broadcasts
+// with the given check tx errors, or transactions timing out before the next
+// block. Every broadcast is followed by a new block.
+type fakeNode struct {
+	t           *testing.T
+	encoding    EncodingConfig
+	address     sdk.AccAddress
+	chainHeight *ChainHeight
+
+	mtx          sync.Mutex
+	sequence     uint64
+	rejections   []*sdkerrors.Error
+	broadcasts   []broadcastedTx
+	committed    map[string][]byte
+	accountReads int
+}
+
+func newFakeNode(
+	t *testing.T,
+	encoding EncodingConfig,
+	address sdk.AccAddress,
+	chainHeight *ChainHeight,
+	sequence uint64,
+	rejections ...*sdkerrors.Error,
+) *fakeNode {
+	return &fakeNode{
+		t:           t,
+		encoding:    encoding,
+		address:     address,
+		chainHeight: chainHeight,
+		sequence:    sequence,
+		rejections:  rejections,
+		committed:   make(map[string][]byte),
+	}
+}
+
+func (fn *fakeNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	require.NoError(fn.t, json.NewDecoder(r.Body).Decode(&req))
+
+	var result interface{}
+	switch req.Method {
+	case "abci_query":
+		result = fn.abciQuery(req.Params)
+	case "broadcast_tx_sync":
+		result = fn.broadcastTxSync(req.Params)
+	case "broadcast_tx_async":
+		result = fn.broadcastTxAsync(req.Params)
+	case "broadcast_tx_commit":
+		result = fn.broadcastTxCommit(req.Params)
+	case "tx":
+		tx := fn.tx(req.Params)
+		if tx == nil {
+			w.Header().Set("Content-Type", "application/json")
+			_, err := fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":-32603,"message":"Internal error",`+
+				`"data":"tx not found"}}`, req.ID)
+			require.NoError(fn.t, err)
+			return
+		}
+		result = tx
+	default:
+		fn.t.Errorf("unexpected RPC method %s", req.Method)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	bz, err := tmjson.Marshal(result)
+	require.NoError(fn.t, err)
+
+	w.Header().Set("Content-Type", "application/json")
+	_, err = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s}`, req.ID, bz)
+	require.NoError(fn.t, err)
+}
+
+func (fn *fakeNode) abciQuery(params json.RawMessage) *ctypes.ResultABCIQuery {
+	var query struct {
+		Path string `json:"path"`
+	}
+	require.NoError(fn.t, json.Unmarshal(params, &query))
+
+	fn.mtx.Lock()
+	defer fn.mtx.Unlock()
+
+	var resp codec.ProtoMarshaler
+	switch query.Path {
+	case "/cosmos.auth.v1beta1.Query/Account":
+		fn.accountReads++
+		account, err := codectypes.NewAnyWithValue(
+			authtypes.NewBaseAccount(fn.address, nil, testAccountNumber, fn.sequence),
+		)
+		require.NoError(fn.t, err)
+		resp = &authtypes.QueryAccountResponse{Account: account}
+
+	case "/cosmos.tx.v1beta1.Service/Simulate":
+		resp = &txtypes.SimulateResponse{
+			GasInfo: &sdk.GasInfo{GasUsed: 100000},
+			Result:  &sdk.Result{},
+		}
+
+	default:
+		fn.t.Errorf("unexpected ABCI query %s", query.Path)
+		return &ctypes.ResultABCIQuery{Response: abci.ResponseQuery{Code: 1}}
+	}
+
+	bz, err := fn.encoding.Marshaler.Marshal(resp)
+	require.NoError(fn.t, err)
+	height, _ := fn.chainHeight.GetChainHeight()
+
+	return &ctypes.ResultABCIQuery{Response: abci.ResponseQuery{Value: bz, Height: height}}
+}
+
+func (fn *fakeNode) broadcastTxSync(params json.RawMessage) *ctypes.ResultBroadcastTx {
+	var broadcast struct {
+		Tx string `json:"tx"`
+	}
+	require.NoError(fn.t, json.Unmarshal(params, &broadcast))
+	txBytes, err := base64.StdEncoding.DecodeString(broadcast.Tx)
+	require.NoError(fn.t, err)
+
+	decoded, err := fn.encoding.TransactionConfig.TxDecoder()(txBytes)
+	require.NoError(fn.t, err)
+	sigTx, ok := decoded.(authsigning.SigVerifiableTx)
+	require.True(fn.t, ok)
+	sigs, err := sigTx.GetSignaturesV2()
+	require.NoError(fn.t, err)
+	require.Len(fn.t, sigs, 1)
+
+	fn.mtx.Lock()
+	defer fn.mtx.Unlock()
+
+	timeoutHeight := decoded.(sdk.TxWithTimeoutHeight).GetTimeoutHeight()
+	fn.broadcasts = append(fn.broadcasts, broadcastedTx{
+		Sequence:      sigs[0].Sequence,
+		Fee:           decoded.(sdk.FeeTx).GetFee(),
+		TimeoutHeight: timeoutHeight,
+	})
+	hash := tmhash.Sum(txBytes)
+
+	// a new block is committed after every broadcast
+	height, _ := fn.chainHeight.GetChainHeight()
+	defer fn.chainHeight.updateChainHeight(height+1, nil)
+
+	if timeoutHeight > 0 && uint64(height+1) > timeoutHeight {
+		fn.rejections = append([]*sdkerrors.Error{sdkerrors.ErrTxTimeoutHeight}, fn.rejections...)
+	}
+	if len(fn.rejections) > 0 {
+		rejection := fn.rejections[0]
+		fn.rejections = fn.rejections[1:]
+		if rejection.Is(sdkerrors.ErrWrongSequence) {
+			// another transaction of the account was included meanwhile
+			fn.sequence++
+		}
+
+		return &ctypes.ResultBroadcastTx{
+			Code:      rejection.ABCICode(),
+			Codespace: rejection.Codespace(),
+			Log:       rejection.Error(),
+			Hash:      hash,
+		}
+	}
+
+	fn.sequence++
+	fn.committed[hex.EncodeToString(hash)] = txBytes
+
+	return &ctypes.ResultBroadcastTx{Hash: hash}
+}
+
+// broadcastTxAsync checks the transaction like broadcastTxSync, responding
+// before it is checked, thus without any error.
+func (fn *fakeNode) broadcastTxAsync(params json.RawMessage) *ctypes.ResultBroadcastTx {
+	return &ctypes.ResultBroadcastTx{Hash: fn.broadcastTxSync(params).Hash}
+}
+
+// broadcastTxCommit checks the transaction like broadcastTxSync, responding
+// with the block it was committed in.
+func (fn *fakeNode) broadcastTxCommit(params json.RawMessage) *ctypes.ResultBroadcastTxCommit {
+	result := fn.broadcastTxSync(params)
+	height, _ := fn.chainHeight.GetChainHeight()
+
+	return &ctypes.ResultBroadcastTxCommit{
+		CheckTx: abci.ResponseCheckTx{
+			Code:      result.Code,
+			Codespace: result.Codespace,
+			Log:       result.Log,
+		},
+		Hash:   result.Hash,
+		Height: height,
+	}
+}
+
+// tx returns the committed transaction queried, or nil if not found.
+func (fn *fakeNode) tx(params json.RawMessage) *ctypes.ResultTx {
+	var query struct {
+		Hash []byte `json:"hash"`
+	}
+	require.NoError(fn.t, json.Unmarshal(params, &query))
+
+	fn.mtx.Lock()
+	defer fn.mtx.Unlock()
+
+	txBytes, ok := fn.committed[hex.EncodeToString(query.Hash)]
+	if !ok {
+		return nil
+	}
+	height, _ := fn.chainHeight.GetChainHeight()
+
+	return &ctypes.ResultTx{
+		Hash:   query.Hash,
+		Height: height,
+		Tx:     tmtypes.Tx(txBytes),
+	}
+}
+
+func (fn *fakeNode) Broadcasts() []broadcastedTx {
+	fn.mtx.Lock()
+	defer fn.mtx.Unlock()
+
+	return append([]broadcastedTx(nil), fn.broadcasts...)
+}
+
+func (fn *fakeNode) AccountReads() int {
+	fn.mtx.Lock()
+	defer fn.mtx.Unlock()
+
+	return fn.accountReads
+}
+
+// newTestChainClient returns a ChainClient broadcasting to a fakeNode at the
+// given height, which rejects the successive broadcasts with rejections.
+func newTestChainClient(
+	t *testing.T,
+	height int64,
+	sequence uint64,
+	rejections ...*sdkerrors.Error,
+) (ChainClient, *fakeNode) {
+	t.Helper()
+
+	encoding := MakeEncodingConfig()
+	address, kb, err := keyring.NewCosmosKeyring(encoding.Marshaler, keyring.WithPrivKeyHex(testPrivKeyHex))
+	require.NoError(t, err)
+
+	chainHeight := &ChainHeight{Logger: zerolog.Nop(), lastChainHeight: height}
+	node := newFakeNode(t, encoding, address, chainHeight, sequence, rejections...)
+	srv := httptest.NewServer(node)
+	t.Cleanup(srv.Close)
+
+	return ChainClient{
+		Logger:              zerolog.Nop(),
+		ChainID:             "test-chain",
+		TMRPC:               srv.URL,
+		RPCTimeout:          time.Second,
+		OracleAddr:          address,
+		OracleAddrString:    address.String(),
+		Keyring:             kb,
+		ValidatorAddrString: sdk.ValAddress(address).String(),
+		Encoding:            encoding,
+		GasAdjustment:       1.5,
+		ChainHeight:         chainHeight,
+		Fees:                testFees,
+
+		pendingConfirmations: new(atomic.Int64),
+	}, node
+}
+
+func testPrevote(oc ChainClient) *oracletypes.MsgAggregateExchangeRatePrevote {
+	return &oracletypes.MsgAggregateExchangeRatePrevote{
+		Hash:      "19c30cf9ea8aa0e0b03904162cadec0f2024a76d",
+		Feeder:    oc.OracleAddrString,
+		Validator: oc.ValidatorAddrString,
+	}
+}
+
+func TestBroadcastTxRetries(t *testing.T) {
+	fees := sdk.NewCoins(sdk.NewInt64Coin("uxprt", 2000))
+
+	testCases := map[string]struct {
+		rejections []*sdkerrors.Error
+		expected   []broadcastedTx
+	}{
+		"accepted": {
+			expected: []broadcastedTx{{Sequence: 4, Fee: fees, TimeoutHeight: 15}},
+		},
+		"mempool full": {
+			rejections: []*sdkerrors.Error{sdkerrors.ErrMempoolIsFull, sdkerrors.ErrMempoolIsFull},
+			expected: []broadcastedTx{
+				{Sequence: 4, Fee: fees, TimeoutHeight: 15},
+				{Sequence: 4, Fee: fees, TimeoutHeight: 15},
+				{Sequence: 4, Fee: fees, TimeoutHeight: 15},
+			},
+		},
+		"account sequence mismatch": {
+			rejections: []*sdkerrors.Error{sdkerrors.ErrWrongSequence},
+			expected: []broadcastedTx{
+				{Sequence: 4, Fee: fees, TimeoutHeight: 15},
+				{Sequence: 5, Fee: fees, TimeoutHeight: 15},
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			oc, node := newTestChainClient(t, 10, 4, tc.rejections...)
+
+			start := time.Now()
+			require.NoError(t, oc.BroadcastTx(context.Background(), 10, 15, testPrevote(oc)))
+
+			require.Equal(t, tc.expected, node.Broadcasts())
+			// the account is checked and its sequence queried before every attempt
+			require.Equal(t, 2*len(tc.expected), node.AccountReads())
+			// every retry waits for a second and a new block
+			require.GreaterOrEqual(t, time.Since(start), time.Duration(len(tc.rejections))*time.Second)
+		})
+	}
+}
+
+// syncBuffer defines a buffer logs can be written to and read from
+// concurrently.
+type syncBuffer struct {
+	mtx sync.Mutex
+	buf bytes.Buffer
+}
+
+func (sb *syncBuffer) Write(p []byte) (int, error) {
+	sb.mtx.Lock()
+	defer sb.mtx.Unlock()
+
+	return sb.buf.Write(p)
+}
+
+func (sb *syncBuffer) String() string {
+	sb.mtx.Lock()
+	defer sb.mtx.Unlock()
+
+	return sb.buf.String()
+}
+
+func TestBroadcastTxModes(t *testing.T) {
+	fees := sdk.NewCoins(sdk.NewInt64Coin("uxprt", 2000))
+
+	testCases := map[string]struct {
+		mode       string
+		features   map[string]bool
+		rejections []*sdkerrors.Error
+		expected   int
+		logged     string
+	}{
+		"block": {
+			mode:     flags.BroadcastBlock,
+			expected: 1,
+			logged:   "successfully broadcasted tx",
+		},
+		"block retried": {
+			mode:       flags.BroadcastBlock,
+			rejections: []*sdkerrors.Error{sdkerrors.ErrMempoolIsFull},
+			expected:   2,
+			logged:     "successfully broadcasted tx",
+		},
+		"async": {
+			mode:     flags.BroadcastAsync,
+			expected: 1,
+			logged:   "successfully confirmed tx",
+		},
+		"async feature": {
+			mode:     flags.BroadcastSync,
+			features: map[string]bool{string(features.AsyncBroadcast): true},
+			expected: 1,
+			logged:   "successfully confirmed tx",
+		},
+		"async not included": {
+			mode:       flags.BroadcastAsync,
+			rejections: []*sdkerrors.Error{sdkerrors.ErrOutOfGas},
+			expected:   1,
+			logged:     "tx was not included before its timeout height",
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			oc, node := newTestChainClient(t, 10, 4, tc.rejections...)
+			oc.BroadcastMode = tc.mode
+			featureFlags, err := features.New(tc.features)
+			require.NoError(t, err)
+			oc.Features = featureFlags
+			logs := &syncBuffer{}
+			oc.Logger = zerolog.New(logs)
+
+			require.NoError(t, oc.BroadcastTx(context.Background(), 10, 12, testPrevote(oc)))
+			// blocks keep being committed past the timeout height
+			oc.ChainHeight.updateChainHeight(13, nil)
+			require.Len(t, node.Broadcasts(), tc.expected)
+			require.Equal(t, fees, node.Broadcasts()[0].Fee)
+			require.Eventually(t, func() bool {
+				return strings.Contains(logs.String(), tc.logged)
+			}, 5*time.Second, 10*time.Millisecond)
+			// no transaction is left awaiting its confirmation
+			require.Eventually(t, func() bool {
+				return oc.pendingConfirmations.Load() == 0
+			}, 5*time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+func TestBroadcastTxTimeoutHeightExceeded(t *testing.T) {
+	rejections := make([]*sdkerrors.Error, 10)
+	for i := range rejections {
+		rejections[i] = sdkerrors.ErrMempoolIsFull
+	}
+	oc, node := newTestChainClient(t, 10, 4, rejections...)
+
+	err := oc.BroadcastTx(context.Background(), 10, 12, testPrevote(oc))
+	require.EqualError(t, err, "broadcasting tx timed out")
+
+	// one attempt per block until the next block is past the timeout height
+	require.Len(t, node.Broadcasts(), 2)
+	height, err := oc.GetHeight()
+	require.NoError(t, err)
+	require.Equal(t, int64(12), height)
+}
+
+func TestBroadcastTxTimeoutHeightBeforeNextBlock(t *testing.T) {
+	oc, node := newTestChainClient(t, 10, 4)
+
+	err := oc.BroadcastTx(context.Background(), 11, 10, testPrevote(oc))
+	require.EqualError(t, err, "timeout height 10 is before the next block 11")
+	require.Empty(t, node.Broadcasts())
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/std"
+	"github.com/cosmos/cosmos-sdk/x/auth/tx"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	oracletypes "github.com/persistenceOne/persistence-sdk/v2/x/oracle/types"
+)
+
+// EncodingConfig defines the encoding types used to build, sign and decode
+// the transactions of the feeder.
+type EncodingConfig struct {
+	InterfaceRegistry codectypes.InterfaceRegistry
+	Marshaler         codec.Codec
+	TransactionConfig client.TxConfig
+	Amino             *codec.LegacyAmino
+}
+
+// MakeEncodingConfig returns the encoding config with only the modules the
+// feeder interacts with registered: oracle for the votes, auth for accounts
+// and transactions, bank for the fees and feegrant and authz for the fee
+// grants and authorizations of the feeder account.
+func MakeEncodingConfig() EncodingConfig {
+	amino := codec.NewLegacyAmino()
+	interfaceRegistry := codectypes.NewInterfaceRegistry()
+	marshaler := codec.NewProtoCodec(interfaceRegistry)
+
+	std.RegisterLegacyAminoCodec(amino)
+	std.RegisterInterfaces(interfaceRegistry)
+
+	authtypes.RegisterLegacyAminoCodec(amino)
+	authtypes.RegisterInterfaces(interfaceRegistry)
+	banktypes.RegisterLegacyAminoCodec(amino)
+	banktypes.RegisterInterfaces(interfaceRegistry)
+	feegrant.RegisterLegacyAminoCodec(amino)
+	feegrant.RegisterInterfaces(interfaceRegistry)
+	authz.RegisterLegacyAminoCodec(amino)
+	authz.RegisterInterfaces(interfaceRegistry)
+	oracletypes.RegisterLegacyAminoCodec(amino)
+	oracletypes.RegisterInterfaces(interfaceRegistry)
+
+	return EncodingConfig{
+		InterfaceRegistry: interfaceRegistry,
+		Marshaler:         marshaler,
+		TransactionConfig: tx.NewTxConfig(marshaler, tx.DefaultSignModes),
+		Amino:             amino,
+	}
+}
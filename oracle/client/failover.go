@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	rpcclient "github.com/cosmos/cosmos-sdk/client/rpc"
+	"github.com/pkg/errors"
+)
+
+var errNoFallbackEndpoint = errors.New("no fallback Tendermint RPC endpoint configured")
+
+// rpcEndpoints holds the Tendermint RPC endpoint of the node and its fallbacks,
+// rotated through when the node in use stalls. It is shared by the copies of a
+// ChainClient.
+type rpcEndpoints struct {
+	mtx       sync.RWMutex
+	endpoints []string
+	active    int
+}
+
+func newRPCEndpoints(endpoint string, fallbacks ...string) *rpcEndpoints {
+	return &rpcEndpoints{endpoints: append([]string{endpoint}, fallbacks...)}
+}
+
+// current returns the endpoint in use.
+func (e *rpcEndpoints) current() string {
+	e.mtx.RLock()
+	defer e.mtx.RUnlock()
+
+	return e.endpoints[e.active]
+}
+
+// next switches to the endpoint following the one in use, wrapping around, and
+// returns its index, 0 being the primary endpoint. It returns false if there is
+// no other endpoint.
+func (e *rpcEndpoints) next() (int, bool) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	if len(e.endpoints) < 2 {
+		return 0, false
+	}
+	e.active = (e.active + 1) % len(e.endpoints)
+
+	return e.active, true
+}
+
+// tmRPC returns the Tendermint RPC endpoint in use.
+func (oc ChainClient) tmRPC() string {
+	if oc.rpcEndpoints == nil {
+		return oc.TMRPC
+	}
+
+	return oc.rpcEndpoints.current()
+}
+
+// HeightUpdatedAt returns when the block height last increased.
+func (oc ChainClient) HeightUpdatedAt() time.Time {
+	return oc.ChainHeight.UpdatedAt()
+}
+
+// Failover switches to the next Tendermint RPC endpoint and subscribes to its
+// new blocks. The endpoint is switched to even if it can't be reached, so that
+// the next failover tries the following one.
+func (oc ChainClient) Failover(ctx context.Context) error {
+	if oc.rpcEndpoints == nil {
+		return errNoFallbackEndpoint
+	}
+
+	// endpoints are logged by index as they may embed credentials
+	endpoint, ok := oc.rpcEndpoints.next()
+	if !ok {
+		return errNoFallbackEndpoint
+	}
+
+	clientCtx, err := oc.createClientContext()
+	if err != nil {
+		return err
+	}
+
+	blockHeight, err := rpcclient.GetChainHeight(clientCtx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the block height of endpoint %d", endpoint)
+	}
+
+	if err := oc.ChainHeight.resubscribe(ctx, clientCtx.Client, blockHeight); err != nil {
+		return errors.Wrapf(err, "failed to subscribe to the new blocks of endpoint %d", endpoint)
+	}
+
+	oc.Logger.Info().
+		Int("endpoint", endpoint).
+		Int64("height", blockHeight).
+		Msg("failed over to Tendermint RPC endpoint")
+
+	return nil
+}
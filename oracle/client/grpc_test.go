@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestProtocolAndAddress(t *testing.T) {
+	testCases := map[string]struct {
+		input            string
+		expectedProtocol string
+		expectedAddress  string
+	}{
+		"host and port":             {"localhost:9090", "tcp", "localhost:9090"},
+		"tcp prefix":                {"tcp://127.0.0.1:9090", "tcp", "127.0.0.1:9090"},
+		"IPv6 literal":              {"[::1]:9090", "tcp", "[::1]:9090"},
+		"IPv6 literal with prefix":  {"tcp6://[2001:db8::1]:9090", "tcp6", "[2001:db8::1]:9090"},
+		"IPv6 literal with zone":    {"[fe80::1%eth0]:9090", "tcp", "[fe80::1%eth0]:9090"},
+		"uppercase prefix":          {"TCP://localhost:9090", "tcp", "localhost:9090"},
+		"unix socket":               {"unix:///run/node/grpc.sock", "unix", "/run/node/grpc.sock"},
+		"unix socket, gRPC style":   {"unix:/run/node/grpc.sock", "unix", "/run/node/grpc.sock"},
+		"relative unix socket":      {"unix:grpc.sock", "unix", "grpc.sock"},
+		"surrounding whitespace":    {" localhost:9090\n", "tcp", "localhost:9090"},
+		"empty address":             {"", "tcp", ""},
+		"unix prefix without path":  {"unix:", "tcp", "unix:"},
+		"address containing unix":   {"unixhost:9090", "tcp", "unixhost:9090"},
+		"separator in the address":  {"tcp://host://9090", "tcp", "host://9090"},
+		"IPv4 only":                 {"tcp4://localhost:9090", "tcp4", "localhost:9090"},
+		"unix socket with a prefix": {"UNIX:///tmp/grpc.sock", "unix", "/tmp/grpc.sock"},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			protocol, address := protocolAndAddress(tc.input)
+			require.Equal(t, tc.expectedProtocol, protocol)
+			require.Equal(t, tc.expectedAddress, address)
+		})
+	}
+}
+
+// serveHealth serves the gRPC health service on listener.
+func serveHealth(t *testing.T, listener net.Listener) {
+	t.Helper()
+
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go func() {
+		_ = srv.Serve(listener)
+	}()
+	t.Cleanup(srv.Stop)
+}
+
+func TestDialGRPC(t *testing.T) {
+	endpoints := make(map[string]string)
+
+	tcpListener, err := net.Listen("tcp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	serveHealth(t, tcpListener)
+	endpoints["tcp"] = tcpListener.Addr().String()
+	endpoints["tcp prefix"] = "tcp://" + tcpListener.Addr().String()
+
+	if tcp6Listener, err := net.Listen("tcp6", "[::1]:0"); err == nil {
+		serveHealth(t, tcp6Listener)
+		endpoints["IPv6"] = tcp6Listener.Addr().String()
+	} else {
+		t.Logf("skipping IPv6 endpoint: %s", err)
+	}
+
+	socketPath := filepath.Join(t.TempDir(), "grpc.sock")
+	unixListener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	serveHealth(t, unixListener)
+	endpoints["unix socket"] = "unix://" + socketPath
+
+	for name, endpoint := range endpoints {
+		endpoint := endpoint
+
+		t.Run(name, func(t *testing.T) {
+			conn, err := dialGRPC(endpoint)
+			require.NoError(t, err)
+			defer conn.Close()
+
+			resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+			require.NoError(t, err)
+			require.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+		})
+	}
+}
+
+func TestDialGRPCQueryMetrics(t *testing.T) {
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+	metricsConfig := metrics.DefaultConfig("test")
+	metricsConfig.EnableHostname = false
+	metricsConfig.EnableRuntimeMetrics = false
+	_, err := metrics.NewGlobal(metricsConfig, sink)
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	serveHealth(t, listener)
+	endpoint := listener.Addr().String()
+
+	conn, err := dialGRPC(endpoint)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	healthClient := healthpb.NewHealthClient(conn)
+	_, err = healthClient.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, err = healthClient.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "unknown"})
+	require.Error(t, err)
+
+	labels := ";endpoint=" + endpoint + ";method=/grpc.health.v1.Health/Check"
+	data := sink.Data()
+	require.Len(t, data, 1)
+	require.Equal(t, 2, data[0].Samples["test.grpc.query.latency"+labels].Count)
+	require.Equal(t, 1, data[0].Counters["test.grpc.query.failures"+labels+";code=NotFound"].Count)
+}
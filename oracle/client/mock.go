@@ -0,0 +1,225 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+
+	oracletypes "github.com/persistenceOne/persistence-sdk/v2/x/oracle/types"
+)
+
+var _ OracleClient = (*MockOracleClient)(nil)
+
+// MockOracleClient defines an in-memory OracleClient recording the broadcasted
+// pre-votes and votes instead of submitting them to a node. The chain state it
+// reports and the errors it fails with are set by its caller.
+type MockOracleClient struct {
+	mtx sync.Mutex
+
+	height           int64
+	heightUpdatedAt  time.Time
+	newBlock         chan struct{}
+	failovers        int
+	params           oracletypes.Params
+	aggregatePrevote *oracletypes.AggregateExchangeRatePrevote
+	feeder           string
+	validator        string
+
+	prevoteErr error
+	voteErr    error
+
+	prevotes       []*oracletypes.MsgAggregateExchangeRatePrevote
+	votes          []*oracletypes.MsgAggregateExchangeRateVote
+	timeoutHeights []int64
+}
+
+// NewMockOracleClient returns a MockOracleClient voting for validator with the
+// feeder account at the given block height.
+func NewMockOracleClient(feeder, validator string, height int64, params oracletypes.Params) *MockOracleClient {
+	return &MockOracleClient{
+		height:          height,
+		heightUpdatedAt: time.Now(),
+		params:          params,
+		feeder:          feeder,
+		validator:       validator,
+	}
+}
+
+// SetHeight sets the block height reported by GetHeight, notifying a new
+// block if it is higher than the previous one.
+func (mc *MockOracleClient) SetHeight(height int64) {
+	mc.mtx.Lock()
+	defer mc.mtx.Unlock()
+
+	if height > mc.height {
+		mc.heightUpdatedAt = time.Now()
+		if mc.newBlock != nil {
+			close(mc.newBlock)
+			mc.newBlock = nil
+		}
+	}
+	mc.height = height
+}
+
+// SetHeightUpdatedAt sets when the block height reported by HeightUpdatedAt
+// last increased.
+func (mc *MockOracleClient) SetHeightUpdatedAt(updatedAt time.Time) {
+	mc.mtx.Lock()
+	defer mc.mtx.Unlock()
+
+	mc.heightUpdatedAt = updatedAt
+}
+
+// Failovers returns the number of times Failover was called.
+func (mc *MockOracleClient) Failovers() int {
+	mc.mtx.Lock()
+	defer mc.mtx.Unlock()
+
+	return mc.failovers
+}
+
+// SetAggregatePrevote sets the pre-vote reported by GetAggregatePrevote.
+func (mc *MockOracleClient) SetAggregatePrevote(prevote oracletypes.AggregateExchangeRatePrevote) {
+	mc.mtx.Lock()
+	defer mc.mtx.Unlock()
+
+	mc.aggregatePrevote = &prevote
+}
+
+// SetBroadcastErrors sets the errors the following pre-votes and votes fail
+// with, nil making them succeed.
+func (mc *MockOracleClient) SetBroadcastErrors(prevoteErr, voteErr error) {
+	mc.mtx.Lock()
+	defer mc.mtx.Unlock()
+
+	mc.prevoteErr = prevoteErr
+	mc.voteErr = voteErr
+}
+
+// Prevotes returns the pre-votes successfully broadcasted so far.
+func (mc *MockOracleClient) Prevotes() []*oracletypes.MsgAggregateExchangeRatePrevote {
+	mc.mtx.Lock()
+	defer mc.mtx.Unlock()
+
+	return append([]*oracletypes.MsgAggregateExchangeRatePrevote(nil), mc.prevotes...)
+}
+
+// TimeoutHeights returns the timeout heights of the pre-votes and votes
+// successfully broadcasted so far, in order.
+func (mc *MockOracleClient) TimeoutHeights() []int64 {
+	mc.mtx.Lock()
+	defer mc.mtx.Unlock()
+
+	return append([]int64(nil), mc.timeoutHeights...)
+}
+
+// Votes returns the votes successfully broadcasted so far.
+func (mc *MockOracleClient) Votes() []*oracletypes.MsgAggregateExchangeRateVote {
+	mc.mtx.Lock()
+	defer mc.mtx.Unlock()
+
+	return append([]*oracletypes.MsgAggregateExchangeRateVote(nil), mc.votes...)
+}
+
+func (mc *MockOracleClient) GetHeight() (int64, error) {
+	mc.mtx.Lock()
+	defer mc.mtx.Unlock()
+
+	return mc.height, nil
+}
+
+func (mc *MockOracleClient) NewBlock() <-chan struct{} {
+	mc.mtx.Lock()
+	defer mc.mtx.Unlock()
+
+	if mc.newBlock == nil {
+		mc.newBlock = make(chan struct{})
+	}
+
+	return mc.newBlock
+}
+
+func (mc *MockOracleClient) HeightUpdatedAt() time.Time {
+	mc.mtx.Lock()
+	defer mc.mtx.Unlock()
+
+	return mc.heightUpdatedAt
+}
+
+// Failover records the failover and resets the age of the block height, as
+// if the next node was at the same height.
+func (mc *MockOracleClient) Failover(context.Context) error {
+	mc.mtx.Lock()
+	defer mc.mtx.Unlock()
+
+	mc.failovers++
+	mc.heightUpdatedAt = time.Now()
+	return nil
+}
+
+func (mc *MockOracleClient) GetParams(context.Context) (oracletypes.Params, error) {
+	mc.mtx.Lock()
+	defer mc.mtx.Unlock()
+
+	return mc.params, nil
+}
+
+func (mc *MockOracleClient) GetAggregatePrevote(context.Context) (oracletypes.AggregateExchangeRatePrevote, error) {
+	mc.mtx.Lock()
+	defer mc.mtx.Unlock()
+
+	if mc.aggregatePrevote == nil {
+		return oracletypes.AggregateExchangeRatePrevote{}, errors.New("no aggregate pre-vote stored")
+	}
+
+	return *mc.aggregatePrevote, nil
+}
+
+func (mc *MockOracleClient) BroadcastPrevote(
+	_ context.Context,
+	_, timeoutHeight int64,
+	msg *oracletypes.MsgAggregateExchangeRatePrevote,
+) error {
+	mc.mtx.Lock()
+	defer mc.mtx.Unlock()
+
+	if mc.prevoteErr != nil {
+		return mc.prevoteErr
+	}
+	mc.prevotes = append(mc.prevotes, msg)
+	mc.timeoutHeights = append(mc.timeoutHeights, timeoutHeight)
+
+	return nil
+}
+
+func (mc *MockOracleClient) BroadcastVote(
+	_ context.Context,
+	_, timeoutHeight int64,
+	msg *oracletypes.MsgAggregateExchangeRateVote,
+) error {
+	mc.mtx.Lock()
+	defer mc.mtx.Unlock()
+
+	if mc.voteErr != nil {
+		return mc.voteErr
+	}
+	mc.votes = append(mc.votes, msg)
+	mc.timeoutHeights = append(mc.timeoutHeights, timeoutHeight)
+
+	return nil
+}
+
+func (mc *MockOracleClient) Sign([]byte) ([]byte, cryptotypes.PubKey, error) {
+	return nil, nil, errors.New("keyring is not initialized")
+}
+
+func (mc *MockOracleClient) FeederAddr() string {
+	return mc.feeder
+}
+
+func (mc *MockOracleClient) ValidatorAddr() string {
+	return mc.validator
+}
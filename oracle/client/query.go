@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	oracletypes "github.com/persistenceOne/persistence-sdk/v2/x/oracle/types"
+)
+
+const queryTimeout = 15 * time.Second
+
+// GetParams returns the current on-chain parameters of the x/oracle module.
+func (oc ChainClient) GetParams(ctx context.Context) (oracletypes.Params, error) {
+	grpcConn, err := dialGRPC(oc.GRPCEndpoint)
+	if err != nil {
+		return oracletypes.Params{}, err
+	}
+
+	defer grpcConn.Close()
+	queryClient := oracletypes.NewQueryClient(grpcConn)
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	queryResponse, err := queryClient.Params(ctx, &oracletypes.QueryParamsRequest{})
+	if err != nil {
+		return oracletypes.Params{}, fmt.Errorf("failed to get x/oracle params: %w", err)
+	}
+
+	return queryResponse.Params, nil
+}
+
+// GetAggregatePrevote returns the aggregate pre-vote currently stored on-chain
+// for the validator. The query fails when no pre-vote is stored.
+func (oc ChainClient) GetAggregatePrevote(ctx context.Context) (oracletypes.AggregateExchangeRatePrevote, error) {
+	grpcConn, err := dialGRPC(oc.GRPCEndpoint)
+	if err != nil {
+		return oracletypes.AggregateExchangeRatePrevote{}, err
+	}
+
+	defer grpcConn.Close()
+	queryClient := oracletypes.NewQueryClient(grpcConn)
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	queryResponse, err := queryClient.AggregatePrevote(ctx, &oracletypes.QueryAggregatePrevoteRequest{
+		ValidatorAddr: oc.ValidatorAddrString,
+	})
+	if err != nil {
+		return oracletypes.AggregateExchangeRatePrevote{}, fmt.Errorf("failed to get aggregate pre-vote: %w", err)
+	}
+
+	return queryResponse.AggregatePrevote, nil
+}
@@ -3,13 +3,16 @@ package client
 import (
 	"context"
 	"encoding/hex"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
 	"github.com/cosmos/cosmos-sdk/client/tx"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/pkg/errors"
+	"github.com/tendermint/tendermint/crypto/tmhash"
 	rpcclient "github.com/tendermint/tendermint/rpc/client"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 )
@@ -18,7 +21,10 @@ const awaitBlock = 1
 
 // broadcastTx attempts to generate, sign and broadcast a transaction with the
 // given set of messages. It will also simulate gas requirements if necessary.
-// It will return an error upon failure.
+// onSigned is called with the hash and the fees of the transaction once it is
+// signed. It will return an error upon failure, along with the response of the
+// node if the transaction was rejected. In sync mode, it waits for the
+// transaction to be included in a block.
 //
 // Note, broadcastTx is copied from the SDK except it removes a few unnecessary
 // things like prompting for confirmation and printing the response. Instead,
@@ -26,7 +32,10 @@ const awaitBlock = 1
 func broadcastTx(
 	ctx context.Context,
 	clientCtx client.Context,
-	txf tx.Factory, msgs ...sdk.Msg,
+	txf tx.Factory,
+	onSigned func(txHash string, fees sdk.Coins),
+	onBroadcast func(),
+	msgs ...sdk.Msg,
 ) (*sdk.TxResponse, error) {
 	txf, err := prepareFactory(clientCtx, txf)
 	if err != nil {
@@ -55,10 +64,17 @@ func broadcastTx(
 	if err != nil {
 		return nil, err
 	}
+	onSigned(fmt.Sprintf("%X", tmhash.Sum(txBytes)), unsignedTx.GetTx().GetFee())
 
 	resp, err := clientCtx.BroadcastTx(txBytes)
 	if err := handleBroadcastResult(resp, err); err != nil {
-		return nil, err
+		return resp, err
+	}
+	onBroadcast()
+	if clientCtx.BroadcastMode != flags.BroadcastSync {
+		// the block mode returns once the tx is committed and the async mode
+		// right away, leaving its confirmation to the caller
+		return resp, nil
 	}
 
 	res, err := waitForTx(ctx, clientCtx.Client, resp.TxHash)
@@ -37,20 +37,36 @@ func getUSDBasedProviders(
 // ConvertCandlesToUSD converts any candles which are not quoted in USD
 // to USD by other price feeds. It will also filter out any candles not
 // within the deviation threshold set by the config.
-//
-//nolint:funlen //No need to split this function
 func ConvertCandlesToUSD(
 	logger zerolog.Logger,
 	candles provider.AggregatedProviderCandles,
 	providerPairs map[provider.Name][]types.CurrencyPair,
 	deviationThresholds map[string]sdk.Dec,
+	sourceGroups SourceGroups,
+	window CandleWindow,
 ) (provider.AggregatedProviderCandles, error) {
+	converted, _, err := convertCandlesToUSD(logger, candles, providerPairs, deviationThresholds, sourceGroups, window)
+	return converted, err
+}
+
+// convertCandlesToUSD converts the candles as ConvertCandlesToUSD does and
+// also returns the USD conversion rate of every quote it converted from.
+//
+//nolint:funlen //No need to split this function
+func convertCandlesToUSD(
+	logger zerolog.Logger,
+	candles provider.AggregatedProviderCandles,
+	providerPairs map[provider.Name][]types.CurrencyPair,
+	deviationThresholds map[string]sdk.Dec,
+	sourceGroups SourceGroups,
+	window CandleWindow,
+) (provider.AggregatedProviderCandles, map[string]sdk.Dec, error) {
 	if len(candles) == 0 {
-		return candles, nil
+		return candles, nil, nil
 	}
 
 	conversionRates := make(map[string]sdk.Dec)
-	requiredConversions := make(map[provider.Name]types.CurrencyPair)
+	requiredConversions := make(map[provider.Name]map[string]string)
 
 	for pairProviderName, pairs := range providerPairs {
 		for _, pair := range pairs {
@@ -58,58 +74,58 @@ func ConvertCandlesToUSD(
 				// Get valid providers and use them to generate a USD-based price for this asset.
 				validProviders, err := getUSDBasedProviders(pair.Quote, providerPairs)
 				if err != nil {
-					return nil, err
+					return nil, nil, err
 				}
 
 				validCandleList, err := getValidCandles(candles, validProviders, pair.Quote)
 				if err != nil {
-					return nil, err
+					return nil, nil, err
 				}
 
-				filteredCandles, err := filterCandleDeviations(
+				filteredCandles, _, err := filterCandleDeviations(
 					logger,
 					validCandleList,
 					deviationThresholds,
+					sourceGroups,
+					window,
 				)
 				if err != nil {
-					return nil, err
+					return nil, nil, err
 				}
 
 				// TODO: we should revise ComputeTVWAP to avoid return empty slices
-				tvwap, err := ComputeTVWAP(filteredCandles)
+				tvwap, err := ComputeTVWAPWithin(filteredCandles, window)
 				if err != nil {
-					return nil, err
+					return nil, nil, err
 				}
 
 				cvRate, ok := tvwap[pair.Quote]
 				if !ok {
-					return nil, fmt.Errorf("error on computing tvwap for quote: %s, base: %s", pair.Quote, pair.Base)
+					return nil, nil, fmt.Errorf("error on computing tvwap for quote: %s, base: %s", pair.Quote, pair.Base)
 				}
 
 				conversionRates[pair.Quote] = cvRate
-				requiredConversions[pairProviderName] = pair
+				addRequiredConversion(requiredConversions, pairProviderName, pair)
 			}
 		}
 	}
 
 	// Convert assets to USD.
 	for provider, assetMap := range candles {
-		conversionRate, ok := conversionRates[requiredConversions[provider].Quote]
-		if !ok {
-			continue
-		}
 		for asset, assetCandles := range assetMap {
-			if requiredConversions[provider].Base == asset {
-				for i := range assetCandles {
-					assetCandles[i].Price = assetCandles[i].Price.Mul(
-						conversionRate,
-					)
-				}
+			conversionRate, ok := conversionRates[requiredConversions[provider][asset]]
+			if !ok {
+				continue
+			}
+			for i := range assetCandles {
+				assetCandles[i].Price = assetCandles[i].Price.Mul(
+					conversionRate,
+				)
 			}
 		}
 	}
 
-	return candles, nil
+	return candles, conversionRates, nil
 }
 
 func getValidCandles(candles provider.AggregatedProviderCandles, validProviders map[provider.Name]struct{}, quote string) (provider.AggregatedProviderCandles, error) { //nolint:lll //function args is in 1 line
@@ -140,20 +156,34 @@ func getValidCandles(candles provider.AggregatedProviderCandles, validProviders
 // ConvertTickersToUSD converts any tickers which are not quoted in USD to USD,
 // using the conversion rates of other tickers. It will also filter out any tickers
 // not within the deviation threshold set by the config.
-//
-//nolint:funlen //No need to split this function
 func ConvertTickersToUSD(
 	logger zerolog.Logger,
 	tickers provider.AggregatedProviderPrices,
 	providerPairs map[provider.Name][]types.CurrencyPair,
 	deviationThresholds map[string]sdk.Dec,
+	sourceGroups SourceGroups,
 ) (provider.AggregatedProviderPrices, error) {
+	converted, _, err := convertTickersToUSD(logger, tickers, providerPairs, deviationThresholds, sourceGroups)
+	return converted, err
+}
+
+// convertTickersToUSD converts the tickers as ConvertTickersToUSD does and
+// also returns the USD conversion rate of every quote it converted from.
+//
+//nolint:funlen //No need to split this function
+func convertTickersToUSD(
+	logger zerolog.Logger,
+	tickers provider.AggregatedProviderPrices,
+	providerPairs map[provider.Name][]types.CurrencyPair,
+	deviationThresholds map[string]sdk.Dec,
+	sourceGroups SourceGroups,
+) (provider.AggregatedProviderPrices, map[string]sdk.Dec, error) {
 	if len(tickers) == 0 {
-		return tickers, nil
+		return tickers, nil, nil
 	}
 
 	conversionRates := make(map[string]sdk.Dec)
-	requiredConversions := make(map[provider.Name]types.CurrencyPair)
+	requiredConversions := make(map[provider.Name]map[string]string)
 
 	for pairProviderName, pairs := range providerPairs {
 		for _, pair := range pairs {
@@ -161,7 +191,7 @@ func ConvertTickersToUSD(
 				// Get valid providers and use them to generate a USD-based price for this asset.
 				validProviders, err := getUSDBasedProviders(pair.Quote, providerPairs)
 				if err != nil {
-					return nil, err
+					return nil, nil, err
 				}
 
 				// Find valid candles, and then let's re-compute the tvwap.
@@ -183,22 +213,23 @@ func ConvertTickersToUSD(
 				}
 
 				if len(validTickerList) == 0 {
-					return nil, fmt.Errorf("there are no valid conversion rates for %s", pair.Quote)
+					return nil, nil, fmt.Errorf("there are no valid conversion rates for %s", pair.Quote)
 				}
 
-				filteredTickers, err := FilterTickerDeviations(
+				filteredTickers, _, err := FilterTickerDeviations(
 					logger,
 					validTickerList,
 					deviationThresholds,
+					sourceGroups,
 				)
 				if err != nil {
-					return nil, err
+					return nil, nil, err
 				}
 
 				vwap := ComputeVWAP(filteredTickers)
 
 				conversionRates[pair.Quote] = vwap[pair.Quote]
-				requiredConversions[pairProviderName] = pair
+				addRequiredConversion(requiredConversions, pairProviderName, pair)
 			}
 		}
 	}
@@ -206,16 +237,31 @@ func ConvertTickersToUSD(
 	// Convert assets to USD.
 	for providerName, assetMap := range tickers {
 		for asset := range assetMap {
-			if requiredConversions[providerName].Base == asset {
-				assetMap[asset] = types.TickerPrice{
-					Price: assetMap[asset].Price.Mul(
-						conversionRates[requiredConversions[providerName].Quote],
-					),
-					Volume: assetMap[asset].Volume,
-				}
+			conversionRate, ok := conversionRates[requiredConversions[providerName][asset]]
+			if !ok {
+				continue
+			}
+			assetMap[asset] = types.TickerPrice{
+				Price:  assetMap[asset].Price.Mul(conversionRate),
+				Volume: assetMap[asset].Volume,
 			}
 		}
 	}
 
-	return tickers, nil
+	return tickers, conversionRates, nil
+}
+
+// addRequiredConversion records that the prices of the base of pair returned
+// by the provider are quoted in the quote of pair and must be converted, as a
+// provider can quote several assets in other assets than USD, e.g. ATOM/KRW
+// and OSMO/KRW.
+func addRequiredConversion(
+	requiredConversions map[provider.Name]map[string]string,
+	providerName provider.Name,
+	pair types.CurrencyPair,
+) {
+	if _, ok := requiredConversions[providerName]; !ok {
+		requiredConversions[providerName] = make(map[string]string)
+	}
+	requiredConversions[providerName][pair.Base] = pair.Quote
 }
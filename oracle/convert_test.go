@@ -99,6 +99,8 @@ func TestConvertCandlesToUSD(t *testing.T) {
 		providerCandles,
 		providerPairs,
 		make(map[string]sdk.Dec),
+		nil,
+		DefaultCandleWindow,
 	)
 	require.NoError(t, err)
 
@@ -150,6 +152,8 @@ func TestConvertCandlesToUSDFiltering(t *testing.T) {
 		providerCandles,
 		providerPairs,
 		make(map[string]sdk.Dec),
+		nil,
+		DefaultCandleWindow,
 	)
 	require.NoError(t, err)
 
@@ -189,6 +193,7 @@ func TestConvertTickersToUSD(t *testing.T) {
 		providerPrices,
 		providerPairs,
 		make(map[string]sdk.Dec),
+		nil,
 	)
 	require.NoError(t, err)
 
@@ -199,6 +204,121 @@ func TestConvertTickersToUSD(t *testing.T) {
 	)
 }
 
+func TestConvertTickersToUSDMultiplePairs(t *testing.T) {
+	krwPrice := sdk.MustNewDecFromStr("0.00075")
+
+	providerPrices := provider.AggregatedProviderPrices{
+		provider.Upbit: {
+			"ATOM": {Price: sdk.MustNewDecFromStr("39907"), Volume: atomVolume},
+			"OSMO": {Price: sdk.MustNewDecFromStr("1307"), Volume: osmoVolume},
+			"KRW":  {Price: krwPrice, Volume: atomVolume},
+		},
+	}
+
+	providerPairs := map[provider.Name][]types.CurrencyPair{
+		provider.Upbit: {
+			{Base: "ATOM", Quote: "KRW"},
+			{Base: "OSMO", Quote: "KRW"},
+			{Base: "KRW", Quote: "USD"},
+		},
+	}
+
+	convertedTickers, err := ConvertTickersToUSD(
+		zerolog.Nop(),
+		providerPrices,
+		providerPairs,
+		make(map[string]sdk.Dec),
+		nil,
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, sdk.MustNewDecFromStr("29.93025"), convertedTickers[provider.Upbit]["ATOM"].Price)
+	require.Equal(t, sdk.MustNewDecFromStr("0.98025"), convertedTickers[provider.Upbit]["OSMO"].Price)
+	require.Equal(t, krwPrice, convertedTickers[provider.Upbit]["KRW"].Price)
+}
+
+func TestConvertIndexQuotesToUSD(t *testing.T) {
+	btcPrice := sdk.MustNewDecFromStr("30000")
+	ethPrice := sdk.MustNewDecFromStr("2000")
+
+	// long-tail assets only listed against BTC and ETH, converted with the
+	// BTC/USD and ETH/USD feeds of other providers
+	providerPairs := map[provider.Name][]types.CurrencyPair{
+		provider.Binance: {
+			{Base: "STARS", Quote: "BTC"},
+			{Base: "JUNO", Quote: "ETH"},
+		},
+		provider.Kraken: {
+			{Base: "BTC", Quote: "USD"},
+			{Base: "ETH", Quote: "USD"},
+		},
+	}
+
+	t.Run("tickers", func(t *testing.T) {
+		providerPrices := provider.AggregatedProviderPrices{
+			provider.Binance: {
+				"STARS": {Price: sdk.MustNewDecFromStr("0.0000005"), Volume: atomVolume},
+				"JUNO":  {Price: sdk.MustNewDecFromStr("0.00015"), Volume: osmoVolume},
+			},
+			provider.Kraken: {
+				"BTC": {Price: btcPrice, Volume: atomVolume},
+				"ETH": {Price: ethPrice, Volume: atomVolume},
+			},
+		}
+
+		convertedTickers, err := ConvertTickersToUSD(
+			zerolog.Nop(),
+			providerPrices,
+			providerPairs,
+			make(map[string]sdk.Dec),
+			nil,
+		)
+		require.NoError(t, err)
+
+		require.Equal(t, sdk.MustNewDecFromStr("0.015"), convertedTickers[provider.Binance]["STARS"].Price)
+		require.Equal(t, sdk.MustNewDecFromStr("0.3"), convertedTickers[provider.Binance]["JUNO"].Price)
+		require.Equal(t, btcPrice, convertedTickers[provider.Kraken]["BTC"].Price)
+	})
+
+	t.Run("candles", func(t *testing.T) {
+		timestamp := provider.PastUnixTime(1 * time.Minute)
+		providerCandles := provider.AggregatedProviderCandles{
+			provider.Binance: {
+				"STARS": {{Price: sdk.MustNewDecFromStr("0.0000005"), Volume: atomVolume, TimeStamp: timestamp}},
+				"JUNO":  {{Price: sdk.MustNewDecFromStr("0.00015"), Volume: osmoVolume, TimeStamp: timestamp}},
+			},
+			provider.Kraken: {
+				"BTC": {{Price: btcPrice, Volume: atomVolume, TimeStamp: timestamp}},
+				"ETH": {{Price: ethPrice, Volume: atomVolume, TimeStamp: timestamp}},
+			},
+		}
+
+		convertedCandles, err := ConvertCandlesToUSD(
+			zerolog.Nop(),
+			providerCandles,
+			providerPairs,
+			make(map[string]sdk.Dec),
+			nil,
+			DefaultCandleWindow,
+		)
+		require.NoError(t, err)
+
+		require.Equal(t, sdk.MustNewDecFromStr("0.015"), convertedCandles[provider.Binance]["STARS"][0].Price)
+		require.Equal(t, sdk.MustNewDecFromStr("0.3"), convertedCandles[provider.Binance]["JUNO"][0].Price)
+	})
+
+	t.Run("missing feed", func(t *testing.T) {
+		_, err := ConvertTickersToUSD(
+			zerolog.Nop(),
+			provider.AggregatedProviderPrices{provider.Binance: {"STARS": {Price: btcPrice, Volume: atomVolume}}},
+			map[provider.Name][]types.CurrencyPair{provider.Binance: {{Base: "STARS", Quote: "BTC"}}},
+			make(map[string]sdk.Dec),
+			nil,
+		)
+		require.Error(t, err)
+	})
+}
+
 func TestConvertTickersToUSDFiltering(t *testing.T) {
 	providerPrices := make(provider.AggregatedProviderPrices, 2)
 
@@ -234,6 +354,7 @@ func TestConvertTickersToUSDFiltering(t *testing.T) {
 		providerPrices,
 		providerPairs,
 		make(map[string]sdk.Dec),
+		nil,
 	)
 	require.NoError(t, err)
 
@@ -0,0 +1,258 @@
+package oracle
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+)
+
+const (
+	// DefaultCorrelationWindow is the period the provider correlations are
+	// computed over by default.
+	DefaultCorrelationWindow = 30 * time.Minute
+
+	// correlationCheckInterval defines how often the oracle checks for
+	// decorrelated providers.
+	correlationCheckInterval = time.Minute
+
+	// minCorrelationSamples is the minimum number of price returns two
+	// providers need in common for their correlation to be computed.
+	minCorrelationSamples = 10
+
+	// minProviderCorrelation is the average correlation with its peers below
+	// which a provider is flagged as decorrelated.
+	minProviderCorrelation = 0.5
+)
+
+type (
+	// AssetCorrelation defines the pairwise statistics of the providers of an
+	// asset and the providers whose feed decorrelated from their peers.
+	AssetCorrelation struct {
+		Pairs        []ProviderPairStats
+		Decorrelated []provider.Name
+	}
+
+	// ProviderPairStats defines the correlation of the price returns of two
+	// providers and their mean relative spread. Correlation is nil when it
+	// is undefined, e.g. when there are not enough samples or a price did not
+	// move.
+	ProviderPairStats struct {
+		Providers   [2]provider.Name
+		Samples     int
+		Correlation *float64
+		Spread      float64
+	}
+)
+
+// ComputeCorrelations computes the pairwise correlation and spread between the
+// providers of every asset from the given price samples, ordered from oldest
+// to newest. A provider is flagged as decorrelated when its average
+// correlation with its peers is below minProviderCorrelation while its peers
+// are correlated with each other, which is usually an early sign of an
+// exchange API incident.
+func ComputeCorrelations(samples []PriceSample) map[string]AssetCorrelation {
+	series := make(map[string]map[provider.Name][]float64)
+	for i, sample := range samples {
+		for providerName, prices := range sample.Prices {
+			for base, price := range prices {
+				if _, ok := series[base]; !ok {
+					series[base] = make(map[provider.Name][]float64)
+				}
+				if _, ok := series[base][providerName]; !ok {
+					series[base][providerName] = nanSeries(len(samples))
+				}
+
+				if f, err := price.Float64(); err == nil && f > 0 {
+					series[base][providerName][i] = f
+				}
+			}
+		}
+	}
+
+	correlations := make(map[string]AssetCorrelation, len(series))
+	for base, providerSeries := range series {
+		providers := make([]provider.Name, 0, len(providerSeries))
+		for providerName := range providerSeries {
+			providers = append(providers, providerName)
+		}
+		sort.Slice(providers, func(i, j int) bool { return providers[i] < providers[j] })
+
+		var ac AssetCorrelation
+		for i := 0; i < len(providers); i++ {
+			for j := i + 1; j < len(providers); j++ {
+				stats := pairStats(providerSeries[providers[i]], providerSeries[providers[j]])
+				stats.Providers = [2]provider.Name{providers[i], providers[j]}
+				ac.Pairs = append(ac.Pairs, stats)
+			}
+		}
+		ac.Decorrelated = decorrelatedProviders(providers, ac.Pairs)
+
+		correlations[base] = ac
+	}
+
+	return correlations
+}
+
+// GetCorrelations computes the provider correlations over the given window.
+func (o *Oracle) GetCorrelations(window time.Duration) map[string]AssetCorrelation {
+	return ComputeCorrelations(o.history.Since(time.Now().Add(-window)))
+}
+
+// checkCorrelations logs a warning and emits a gauge for every provider whose
+// feed decorrelated from its peers.
+func (o *Oracle) checkCorrelations() {
+	if time.Since(o.lastCorrelationCheck) < correlationCheckInterval {
+		return
+	}
+	o.lastCorrelationCheck = time.Now()
+
+	for base, ac := range o.GetCorrelations(DefaultCorrelationWindow) {
+		decorrelated := make(map[provider.Name]struct{}, len(ac.Decorrelated))
+		for _, providerName := range ac.Decorrelated {
+			decorrelated[providerName] = struct{}{}
+			o.logger.Warn().
+				Str("asset", base).
+				Str("provider", providerName.String()).
+				Msg("provider price feed decorrelated from its peers")
+		}
+
+		for _, pair := range ac.Pairs {
+			for _, providerName := range pair.Providers {
+				var flagged float32
+				if _, ok := decorrelated[providerName]; ok {
+					flagged = 1
+				}
+
+				telemetry.SetGaugeWithLabels(
+					[]string{"provider", "decorrelated"},
+					flagged,
+					[]metrics.Label{
+						telemetry.NewLabel("provider", providerName.String()),
+						telemetry.NewLabel("asset", base),
+					},
+				)
+			}
+		}
+	}
+}
+
+// pairStats returns the correlation of the returns of two price series and
+// their mean relative spread. Missing prices are NaN.
+func pairStats(a, b []float64) ProviderPairStats {
+	var (
+		stats              ProviderPairStats
+		spreadSum          float64
+		spreadSamples      int
+		returnsA, returnsB []float64
+		prevA, prevB       float64
+		prevAvailable      bool
+	)
+
+	for i := range a {
+		if math.IsNaN(a[i]) || math.IsNaN(b[i]) {
+			prevAvailable = false
+			continue
+		}
+
+		spreadSum += math.Abs(a[i]-b[i]) / ((a[i] + b[i]) / 2) //nolint:gomnd //mean of two values
+		spreadSamples++
+
+		if prevAvailable {
+			returnsA = append(returnsA, (a[i]-prevA)/prevA)
+			returnsB = append(returnsB, (b[i]-prevB)/prevB)
+		}
+		prevA, prevB, prevAvailable = a[i], b[i], true
+	}
+
+	if spreadSamples > 0 {
+		stats.Spread = spreadSum / float64(spreadSamples)
+	}
+
+	stats.Samples = len(returnsA)
+	if stats.Samples >= minCorrelationSamples {
+		if corr, ok := pearson(returnsA, returnsB); ok {
+			stats.Correlation = &corr
+		}
+	}
+
+	return stats
+}
+
+// pearson returns the Pearson correlation coefficient of x and y, which is
+// undefined if either of them has no variance.
+func pearson(x, y []float64) (float64, bool) {
+	n := float64(len(x))
+
+	var sumX, sumY float64
+	for i := range x {
+		sumX += x[i]
+		sumY += y[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+
+	var cov, varX, varY float64
+	for i := range x {
+		dx, dy := x[i]-meanX, y[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+
+	if varX == 0 || varY == 0 {
+		return 0, false
+	}
+
+	return cov / math.Sqrt(varX*varY), true
+}
+
+// decorrelatedProviders returns the providers whose average correlation with
+// their peers is below minProviderCorrelation while the average correlation
+// among the peers is not.
+func decorrelatedProviders(providers []provider.Name, pairs []ProviderPairStats) []provider.Name {
+	var decorrelated []provider.Name
+
+	for _, candidate := range providers {
+		var (
+			own, peers       float64
+			ownNum, peersNum int
+		)
+
+		for _, pair := range pairs {
+			if pair.Correlation == nil {
+				continue
+			}
+
+			if pair.Providers[0] == candidate || pair.Providers[1] == candidate {
+				own += *pair.Correlation
+				ownNum++
+			} else {
+				peers += *pair.Correlation
+				peersNum++
+			}
+		}
+
+		// at least two peers are needed to tell which side decorrelated
+		if ownNum < 2 || peersNum == 0 {
+			continue
+		}
+
+		if own/float64(ownNum) < minProviderCorrelation && peers/float64(peersNum) >= minProviderCorrelation {
+			decorrelated = append(decorrelated, candidate)
+		}
+	}
+
+	return decorrelated
+}
+
+func nanSeries(n int) []float64 {
+	s := make([]float64, n)
+	for i := range s {
+		s[i] = math.NaN()
+	}
+	return s
+}
@@ -0,0 +1,71 @@
+package oracle
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+)
+
+func TestComputeCorrelations(t *testing.T) {
+	start := time.Now()
+	samples := make([]PriceSample, 0, 30)
+	for i := 0; i < 30; i++ {
+		price := 10 + math.Sin(float64(i))
+		// a feed moving independently from the market
+		decorrelated := 10 + math.Cos(float64(i)*7)
+
+		samples = append(samples, PriceSample{
+			Time: start.Add(time.Duration(i) * time.Second),
+			Prices: PricesByProvider{
+				provider.Binance:  {"ATOM": sdk.MustNewDecFromStr(formatFloat(price))},
+				provider.Kraken:   {"ATOM": sdk.MustNewDecFromStr(formatFloat(price * 1.001))},
+				provider.Coinbase: {"ATOM": sdk.MustNewDecFromStr(formatFloat(price * 0.999))},
+				provider.Huobi:    {"ATOM": sdk.MustNewDecFromStr(formatFloat(decorrelated))},
+			},
+		})
+	}
+
+	correlations := ComputeCorrelations(samples)
+	atom := correlations["ATOM"]
+	require.Len(t, atom.Pairs, 6)
+	require.Equal(t, []provider.Name{provider.Huobi}, atom.Decorrelated)
+
+	for _, pair := range atom.Pairs {
+		require.Equal(t, 29, pair.Samples)
+		require.NotNil(t, pair.Correlation)
+		if pair.Providers == [2]provider.Name{provider.Binance, provider.Kraken} {
+			require.InDelta(t, 1, *pair.Correlation, 0.0001)
+			require.InDelta(t, 0.001, pair.Spread, 0.0001)
+		}
+	}
+
+	// not enough samples to compute a correlation
+	correlations = ComputeCorrelations(samples[:5])
+	require.Nil(t, correlations["ATOM"].Pairs[0].Correlation)
+	require.Empty(t, correlations["ATOM"].Decorrelated)
+}
+
+func TestPriceHistory(t *testing.T) {
+	start := time.Now()
+	history := NewPriceHistory(3)
+
+	for i := 0; i < 5; i++ {
+		history.Add(start.Add(time.Duration(i)*time.Second), PricesByProvider{})
+	}
+
+	samples := history.Since(start)
+	require.Len(t, samples, 3)
+	require.Equal(t, start.Add(2*time.Second), samples[0].Time)
+	require.Equal(t, start.Add(4*time.Second), samples[2].Time)
+
+	require.Len(t, history.Since(start.Add(3*time.Second)), 1)
+}
+
+func formatFloat(f float64) string {
+	return sdk.NewDecWithPrec(int64(f*1e6), 6).String()
+}
@@ -0,0 +1,179 @@
+package oracle
+
+import (
+	"errors"
+	"os"
+	"sync"
+
+	"github.com/armon/go-metrics"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	"github.com/rs/zerolog"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+	"github.com/persistenceOne/oracle-feeder/pkg/jsonfile"
+)
+
+// CountersFileName is the name of the file, relative to the data directory,
+// the cumulative oracle counters are persisted to.
+const CountersFileName = "counters.json"
+
+var (
+	counterKeyPrevotes         = []string{"prevotes", "submitted"}
+	counterKeyVotes            = []string{"votes", "submitted"}
+	counterKeyPrevoteFailures  = []string{"prevotes", "failed"}
+	counterKeyVoteFailures     = []string{"votes", "failed"}
+	counterKeyMisses           = []string{"votes", "missed"}
+	counterKeyProviderFailures = []string{"provider", "failures"}
+)
+
+type (
+	// Counters tracks the cumulative number of pre-votes and votes submitted,
+	// pre-votes and votes which failed to broadcast, missed votes and provider
+	// failures and emits them as telemetry counters.
+	// When created with a non-empty path the counts are persisted on every
+	// update and restored on start, so that Prometheus counters don't reset on
+	// restarts and rate() doesn't dip during upgrades.
+	Counters struct {
+		logger zerolog.Logger
+		path   string
+
+		mtx    sync.Mutex
+		values CounterValues
+	}
+
+	// CounterValues defines the persisted cumulative counter values.
+	CounterValues struct {
+		Prevotes         uint64                   `json:"prevotes"`
+		Votes            uint64                   `json:"votes"`
+		PrevoteFailures  uint64                   `json:"prevote_failures"`
+		VoteFailures     uint64                   `json:"vote_failures"`
+		Misses           uint64                   `json:"misses"`
+		ProviderFailures map[provider.Name]uint64 `json:"provider_failures"`
+	}
+)
+
+// NewCounters returns counters which are kept in memory only.
+func NewCounters(logger zerolog.Logger) *Counters {
+	return &Counters{
+		logger: logger.With().Str("module", "counters").Logger(),
+		values: CounterValues{ProviderFailures: make(map[provider.Name]uint64)},
+	}
+}
+
+// LoadCounters returns counters persisted to path, restoring the values of a
+// previous run if the file exists. The restored values are emitted right away
+// so the telemetry counters continue from where they stopped.
+func LoadCounters(logger zerolog.Logger, path string) (*Counters, error) {
+	c := NewCounters(logger)
+	c.path = path
+
+	if err := jsonfile.Read(path, &c.values); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if c.values.ProviderFailures == nil {
+		c.values.ProviderFailures = make(map[provider.Name]uint64)
+	}
+
+	c.emit(false)
+	return c, nil
+}
+
+// IncrPrevotes increments the number of pre-votes submitted.
+func (c *Counters) IncrPrevotes() {
+	c.update(func(v *CounterValues) { v.Prevotes++ })
+	telemetry.IncrCounter(1, counterKeyPrevotes...)
+}
+
+// IncrVotes increments the number of votes submitted.
+func (c *Counters) IncrVotes() {
+	c.update(func(v *CounterValues) { v.Votes++ })
+	telemetry.IncrCounter(1, counterKeyVotes...)
+}
+
+// IncrPrevoteFailures increments the number of pre-votes which failed to
+// broadcast.
+func (c *Counters) IncrPrevoteFailures() {
+	c.update(func(v *CounterValues) { v.PrevoteFailures++ })
+	telemetry.IncrCounter(1, counterKeyPrevoteFailures...)
+}
+
+// IncrVoteFailures increments the number of votes which failed to broadcast.
+func (c *Counters) IncrVoteFailures() {
+	c.update(func(v *CounterValues) { v.VoteFailures++ })
+	telemetry.IncrCounter(1, counterKeyVoteFailures...)
+}
+
+// IncrMisses increments the number of missed votes.
+func (c *Counters) IncrMisses() {
+	c.update(func(v *CounterValues) { v.Misses++ })
+	telemetry.IncrCounter(1, counterKeyMisses...)
+}
+
+// IncrProviderFailures increments the number of failed price requests of the
+// given provider.
+func (c *Counters) IncrProviderFailures(providerName provider.Name) {
+	c.update(func(v *CounterValues) { v.ProviderFailures[providerName]++ })
+	telemetry.IncrCounterWithLabels(counterKeyProviderFailures, 1, providerLabels(providerName))
+}
+
+// Values returns a copy of the current counter values.
+func (c *Counters) Values() CounterValues {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	failures := make(map[provider.Name]uint64, len(c.values.ProviderFailures))
+	for pn, n := range c.values.ProviderFailures {
+		failures[pn] = n
+	}
+
+	values := c.values
+	values.ProviderFailures = failures
+	return values
+}
+
+// Refresh emits all counters without changing their values. The Prometheus
+// sink expires series which were not updated within its retention time, which
+// would otherwise drop restored counters that rarely change.
+func (c *Counters) Refresh() {
+	c.emit(true)
+}
+
+func (c *Counters) update(fn func(v *CounterValues)) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	fn(&c.values)
+
+	if c.path == "" {
+		return
+	}
+	if err := jsonfile.Write(c.path, c.values); err != nil {
+		c.logger.Err(err).Str("path", c.path).Msg("failed to persist counters")
+	}
+}
+
+// emit increments the telemetry counters by their current values or, when
+// refresh is set, by zero.
+func (c *Counters) emit(refresh bool) {
+	values := c.Values()
+
+	value := func(n uint64) float32 {
+		if refresh {
+			return 0
+		}
+		return float32(n)
+	}
+
+	telemetry.IncrCounter(value(values.Prevotes), counterKeyPrevotes...)
+	telemetry.IncrCounter(value(values.Votes), counterKeyVotes...)
+	telemetry.IncrCounter(value(values.PrevoteFailures), counterKeyPrevoteFailures...)
+	telemetry.IncrCounter(value(values.VoteFailures), counterKeyVoteFailures...)
+	telemetry.IncrCounter(value(values.Misses), counterKeyMisses...)
+	for pn, n := range values.ProviderFailures {
+		telemetry.IncrCounterWithLabels(counterKeyProviderFailures, value(n), providerLabels(pn))
+	}
+}
+
+func providerLabels(providerName provider.Name) []metrics.Label {
+	return []metrics.Label{telemetry.NewLabel("provider", providerName.String())}
+}
@@ -0,0 +1,45 @@
+package oracle
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+)
+
+func TestCountersPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), CountersFileName)
+
+	counters, err := LoadCounters(zerolog.Nop(), path)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), counters.Values().Votes)
+
+	counters.IncrPrevotes()
+	counters.IncrVotes()
+	counters.IncrVotes()
+	counters.IncrVoteFailures()
+	counters.IncrMisses()
+	counters.IncrProviderFailures(provider.Binance)
+
+	// the values of a previous run are restored
+	restored, err := LoadCounters(zerolog.Nop(), path)
+	require.NoError(t, err)
+	require.Equal(t, CounterValues{
+		Prevotes:         1,
+		Votes:            2,
+		VoteFailures:     1,
+		Misses:           1,
+		ProviderFailures: map[provider.Name]uint64{provider.Binance: 1},
+	}, restored.Values())
+}
+
+func TestCountersInMemory(t *testing.T) {
+	counters := NewCounters(zerolog.Nop())
+	counters.IncrProviderFailures(provider.Kraken)
+	counters.Refresh()
+
+	require.Equal(t, uint64(1), counters.Values().ProviderFailures[provider.Kraken])
+}
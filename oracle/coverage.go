@@ -0,0 +1,261 @@
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/persistenceOne/oracle-feeder/config"
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+// coverageMinSources is the number of sources, counting the providers of a
+// source group once, below which an asset is reported as single-source.
+const coverageMinSources = 2
+
+type (
+	// CoverageReport defines how the configured pairs are covered by the
+	// supported providers. A provider covers a pair when it lists it and
+	// returns a ticker price with a volume for it.
+	CoverageReport struct {
+		Timestamp time.Time      `json:"timestamp"`
+		Pairs     []PairCoverage `json:"pairs"`
+
+		// SingleSourceAssets are the assets covered by fewer than two sources
+		// of their configured providers, across all of their pairs.
+		SingleSourceAssets []string `json:"single_source_assets"`
+
+		// ProviderErrors are the providers which couldn't be checked, e.g.
+		// unreachable ones, whose coverage is unknown.
+		ProviderErrors map[provider.Name]string `json:"provider_errors,omitempty"`
+	}
+
+	// PairCoverage defines the coverage of a configured pair.
+	PairCoverage struct {
+		Pair       string          `json:"pair"` // Pair ex.: ATOM/USD
+		Configured []provider.Name `json:"configured"`
+
+		// Unavailable are the configured providers not covering the pair.
+		Unavailable []provider.Name `json:"unavailable"`
+
+		// Unused are the providers covering the pair which aren't configured
+		// for it.
+		Unused []provider.Name `json:"unused"`
+	}
+
+	// availablePairsProvider defines a provider listing the pairs it serves.
+	availablePairsProvider interface {
+		GetAvailablePairs() (map[string]struct{}, error)
+	}
+)
+
+// CheckCoverage checks every configured pair against every supported
+// provider. The streaming providers are given the warmup duration to receive
+// their first prices before the pairs are checked.
+func CheckCoverage(
+	ctx context.Context,
+	logger zerolog.Logger,
+	cfg config.Config,
+	warmup time.Duration,
+) (CoverageReport, error) {
+	endpoints, err := cfg.ResolveProviderEndpoints()
+	if err != nil {
+		return CoverageReport{}, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pairs := make([]types.CurrencyPair, 0, len(cfg.CurrencyPairs))
+	seen := make(map[string]struct{}, len(cfg.CurrencyPairs))
+	for _, cp := range cfg.CurrencyPairs {
+		pair := types.CurrencyPair{Base: cp.Base, Quote: cp.Quote}
+		if _, ok := seen[pair.String()]; ok {
+			continue
+		}
+		seen[pair.String()] = struct{}{}
+		pairs = append(pairs, pair)
+	}
+
+	var (
+		mtx       sync.Mutex
+		providers = make(map[provider.Name]provider.Provider, len(config.SupportedProviders))
+		errs      = make(map[provider.Name]error)
+		wg        sync.WaitGroup
+	)
+	for providerName := range config.SupportedProviders {
+		if providerName == provider.Mock {
+			continue
+		}
+
+		wg.Add(1)
+		go func(providerName provider.Name) {
+			defer wg.Done()
+
+			p, err := NewProvider(ctx, providerName, logger, endpoints[providerName], pairs...)
+
+			mtx.Lock()
+			defer mtx.Unlock()
+			if err != nil {
+				errs[providerName] = err
+				return
+			}
+			providers[providerName] = p
+		}(providerName)
+	}
+	wg.Wait()
+
+	select {
+	case <-ctx.Done():
+		return CoverageReport{}, ctx.Err()
+	case <-time.After(warmup):
+	}
+
+	covered := make(map[provider.Name]map[string]struct{}, len(providers))
+	for providerName, p := range providers {
+		providerCovered, err := coveredPairs(ctx, p, pairs)
+		if err != nil {
+			errs[providerName] = err
+			continue
+		}
+		covered[providerName] = providerCovered
+	}
+
+	return newCoverageReport(cfg.CurrencyPairs, cfg.SourceGroupByProvider(), covered, errs), nil
+}
+
+// coveredPairs returns the pairs the provider lists and returns a ticker
+// price with a volume for, requested one by one as a provider fails a request
+// when any of its pairs is missing.
+func coveredPairs(
+	ctx context.Context,
+	p provider.Provider,
+	pairs []types.CurrencyPair,
+) (map[string]struct{}, error) {
+	var listed map[string]struct{}
+	if ap, ok := p.(availablePairsProvider); ok {
+		var err error
+		if listed, err = ap.GetAvailablePairs(); err != nil {
+			return nil, fmt.Errorf("failed to get available pairs: %w", err)
+		}
+	}
+
+	covered := make(map[string]struct{}, len(pairs))
+	for _, pair := range pairs {
+		if listed != nil {
+			if _, ok := listed[strings.ToUpper(pair.String())]; !ok {
+				continue
+			}
+		}
+
+		tickers, err := p.GetTickerPrices(ctx, pair)
+		if err != nil {
+			continue
+		}
+		ticker, ok := tickers[pair.String()]
+		if !ok || !ticker.Price.IsPositive() || ticker.Volume.IsNil() || !ticker.Volume.IsPositive() {
+			continue
+		}
+		covered[pair.String()] = struct{}{}
+	}
+
+	return covered, nil
+}
+
+// newCoverageReport returns the coverage of the configured pairs given the
+// pairs covered by every checked provider. The providers which failed are
+// neither reported as unavailable nor counted as a source.
+func newCoverageReport(
+	currencyPairs []config.CurrencyPair,
+	sourceGroups map[provider.Name]string,
+	covered map[provider.Name]map[string]struct{},
+	errs map[provider.Name]error,
+) CoverageReport {
+	report := CoverageReport{
+		Timestamp:          time.Now().UTC(),
+		Pairs:              make([]PairCoverage, 0, len(currencyPairs)),
+		SingleSourceAssets: []string{},
+	}
+
+	if len(errs) > 0 {
+		report.ProviderErrors = make(map[provider.Name]string, len(errs))
+		for providerName, err := range errs {
+			report.ProviderErrors[providerName] = err.Error()
+		}
+	}
+
+	checked := make([]provider.Name, 0, len(covered))
+	for providerName := range covered {
+		checked = append(checked, providerName)
+	}
+	sortProviderNames(checked)
+
+	assets := []string{}
+	assetSources := make(map[string]map[string]struct{})
+	for _, cp := range currencyPairs {
+		pair := types.CurrencyPair{Base: cp.Base, Quote: cp.Quote}
+		if _, ok := assetSources[cp.Base]; !ok {
+			assetSources[cp.Base] = make(map[string]struct{})
+			assets = append(assets, cp.Base)
+		}
+
+		pairCoverage := PairCoverage{
+			Pair:        cp.Base + "/" + cp.Quote,
+			Configured:  append([]provider.Name{}, cp.Providers...),
+			Unavailable: []provider.Name{},
+			Unused:      []provider.Name{},
+		}
+		sortProviderNames(pairCoverage.Configured)
+
+		configured := make(map[provider.Name]struct{}, len(cp.Providers))
+		for _, providerName := range cp.Providers {
+			configured[providerName] = struct{}{}
+
+			providerCovered, ok := covered[providerName]
+			if !ok {
+				continue
+			}
+			if _, ok := providerCovered[pair.String()]; !ok {
+				pairCoverage.Unavailable = append(pairCoverage.Unavailable, providerName)
+				continue
+			}
+
+			source := string(providerName)
+			if group, ok := sourceGroups[providerName]; ok {
+				source = group
+			}
+			assetSources[cp.Base][source] = struct{}{}
+		}
+		sortProviderNames(pairCoverage.Unavailable)
+
+		for _, providerName := range checked {
+			if _, ok := configured[providerName]; ok {
+				continue
+			}
+			if _, ok := covered[providerName][pair.String()]; ok {
+				pairCoverage.Unused = append(pairCoverage.Unused, providerName)
+			}
+		}
+
+		report.Pairs = append(report.Pairs, pairCoverage)
+	}
+
+	for _, asset := range assets {
+		if len(assetSources[asset]) < coverageMinSources {
+			report.SingleSourceAssets = append(report.SingleSourceAssets, asset)
+		}
+	}
+
+	return report
+}
+
+// sortProviderNames sorts the provider names alphabetically.
+func sortProviderNames(names []provider.Name) {
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+}
@@ -0,0 +1,76 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/config"
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+func TestCoveredPairs(t *testing.T) {
+	p := staticProvider{
+		"ATOMUSD": {Price: sdk.MustNewDecFromStr("29.93"), Volume: sdk.MustNewDecFromStr("894123")},
+		"OSMOUSD": {Price: sdk.MustNewDecFromStr("0.98"), Volume: sdk.ZeroDec()},
+	}
+
+	covered, err := coveredPairs(context.Background(), p, []types.CurrencyPair{
+		{Base: "ATOM", Quote: "USD"},
+		{Base: "OSMO", Quote: "USD"},
+		{Base: "UMEE", Quote: "USD"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]struct{}{"ATOMUSD": {}}, covered)
+}
+
+func TestNewCoverageReport(t *testing.T) {
+	currencyPairs := []config.CurrencyPair{
+		{Base: "ATOM", Quote: "USD", Providers: []provider.Name{provider.Kraken, provider.Binance}},
+		{Base: "OSMO", Quote: "USD", Providers: []provider.Name{provider.Osmosis, provider.Kraken}},
+		{Base: "USDC", Quote: "USD", Providers: []provider.Name{provider.Binance, provider.BinanceUS}},
+	}
+	covered := map[provider.Name]map[string]struct{}{
+		provider.Binance:   {"ATOMUSD": {}, "USDCUSD": {}},
+		provider.BinanceUS: {"USDCUSD": {}},
+		provider.Kraken:    {"ATOMUSD": {}},
+		provider.Okx:       {"ATOMUSD": {}, "OSMOUSD": {}},
+		provider.Coinbase:  {"ATOMUSD": {}},
+	}
+	errs := map[provider.Name]error{
+		provider.Osmosis: errors.New("connection refused"),
+	}
+	sourceGroups := map[provider.Name]string{
+		provider.Binance:   "binance",
+		provider.BinanceUS: "binance",
+	}
+
+	report := newCoverageReport(currencyPairs, sourceGroups, covered, errs)
+
+	require.Equal(t, []PairCoverage{
+		{
+			Pair:        "ATOM/USD",
+			Configured:  []provider.Name{provider.Binance, provider.Kraken},
+			Unavailable: []provider.Name{},
+			Unused:      []provider.Name{provider.Coinbase, provider.Okx},
+		},
+		{
+			Pair:        "OSMO/USD",
+			Configured:  []provider.Name{provider.Kraken, provider.Osmosis},
+			Unavailable: []provider.Name{provider.Kraken},
+			Unused:      []provider.Name{provider.Okx},
+		},
+		{
+			Pair:        "USDC/USD",
+			Configured:  []provider.Name{provider.Binance, provider.BinanceUS},
+			Unavailable: []provider.Name{},
+			Unused:      []provider.Name{},
+		},
+	}, report.Pairs)
+	require.Equal(t, []string{"OSMO", "USDC"}, report.SingleSourceAssets)
+	require.Equal(t, map[provider.Name]string{provider.Osmosis: "connection refused"}, report.ProviderErrors)
+}
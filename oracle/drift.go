@@ -0,0 +1,315 @@
+package oracle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+
+	"github.com/persistenceOne/oracle-feeder/config"
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+)
+
+const (
+	manifestTimeout      = 10 * time.Second
+	maxManifestBodyBytes = 1 << 20 // 1 MiB
+)
+
+// Drift kinds, see ConfigDrift.
+const (
+	DriftMissingAsset       = "missing_asset"
+	DriftUnexpectedAsset    = "unexpected_asset"
+	DriftMissingProvider    = "missing_provider"
+	DriftMinProviders       = "min_providers"
+	DriftDeviationThreshold = "deviation_threshold"
+)
+
+type (
+	// Manifest defines the recommended config manifest, published so that
+	// the fleet of feeders can coordinate config updates, e.g. once the accept
+	// list changes. Networks are keyed by chain ID.
+	Manifest struct {
+		Networks map[string]NetworkManifest `json:"networks"`
+	}
+
+	// NetworkManifest defines the recommended settings of the assets of a
+	// network, keyed by symbol. Every asset of the network is expected to be
+	// priced, and no other.
+	NetworkManifest struct {
+		Assets map[string]AssetManifest `json:"assets"`
+	}
+
+	// AssetManifest defines the recommended settings of an asset. Unset
+	// fields aren't checked.
+	AssetManifest struct {
+		Providers          []provider.Name `json:"providers,omitempty"`
+		MinProviders       int             `json:"min_providers,omitempty"`
+		DeviationThreshold string          `json:"deviation_threshold,omitempty"`
+	}
+
+	// ConfigDrift defines a difference between the config and the recommended
+	// settings of an asset.
+	ConfigDrift struct {
+		Asset    string
+		Kind     string
+		Expected string
+		Actual   string
+	}
+
+	// DriftReport defines the drift of the config found by the last check of
+	// the manifest, or the error it failed with.
+	DriftReport struct {
+		URL       string
+		Network   string
+		CheckedAt time.Time
+		Drifts    []ConfigDrift
+		Err       error
+	}
+
+	// DriftMonitor checks the config against the recommended config manifest
+	// every interval, logging the drifts as they appear or get resolved and
+	// emitting their count, and reports the drift of the last check.
+	DriftMonitor struct {
+		logger   zerolog.Logger
+		cfg      config.Config
+		interval time.Duration
+		client   *http.Client
+
+		mtx    sync.RWMutex
+		report DriftReport
+	}
+)
+
+// NewDriftMonitor returns a monitor of the drift of cfg from the manifest it
+// configures.
+func NewDriftMonitor(logger zerolog.Logger, cfg config.Config) (*DriftMonitor, error) {
+	interval, err := cfg.Manifest.Duration()
+	if err != nil {
+		return nil, err
+	}
+
+	return &DriftMonitor{
+		logger:   logger.With().Str("module", "drift").Logger(),
+		cfg:      cfg,
+		interval: interval,
+		client:   &http.Client{Timeout: manifestTimeout},
+		report:   DriftReport{URL: cfg.Manifest.URL, Network: cfg.Manifest.Network},
+	}, nil
+}
+
+// Start checks the manifest right away, then every interval until ctx is
+// done.
+func (m *DriftMonitor) Start(ctx context.Context) error {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.check(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Report returns the drift found by the last check.
+func (m *DriftMonitor) Report() DriftReport {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	report := m.report
+	report.Drifts = append([]ConfigDrift{}, m.report.Drifts...)
+	return report
+}
+
+// check fetches the manifest and records the drift of the config from it.
+// A manifest which can't be fetched keeps the drift of the last check.
+func (m *DriftMonitor) check(ctx context.Context) {
+	manifest, err := m.fetchManifest(ctx)
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.report.CheckedAt = time.Now()
+	m.report.Err = err
+	if err != nil {
+		m.logger.Error().Err(err).Str("url", m.cfg.Manifest.URL).Msg("failed to check the config manifest")
+		return
+	}
+
+	network, ok := manifest.Networks[m.cfg.Manifest.Network]
+	if !ok {
+		m.report.Err = fmt.Errorf("no network %s in the config manifest", m.cfg.Manifest.Network)
+		m.logger.Error().
+			Err(m.report.Err).
+			Str("url", m.cfg.Manifest.URL).
+			Msg("failed to check the config manifest")
+		return
+	}
+
+	drifts := ComputeDrift(m.cfg, network)
+	m.logChanges(m.report.Drifts, drifts)
+	m.report.Drifts = drifts
+	telemetry.SetGauge(float32(len(drifts)), "config", "drift")
+}
+
+// logChanges logs the drifts which appeared and those resolved since the
+// previous check.
+func (m *DriftMonitor) logChanges(previous, current []ConfigDrift) {
+	seen := make(map[ConfigDrift]struct{}, len(previous))
+	for _, drift := range previous {
+		seen[drift] = struct{}{}
+	}
+	for _, drift := range current {
+		if _, ok := seen[drift]; ok {
+			delete(seen, drift)
+			continue
+		}
+		m.logger.Warn().
+			Str("asset", drift.Asset).
+			Str("kind", drift.Kind).
+			Str("expected", drift.Expected).
+			Str("actual", drift.Actual).
+			Msg("config drifted from the recommended manifest")
+	}
+	for drift := range seen {
+		m.logger.Info().
+			Str("asset", drift.Asset).
+			Str("kind", drift.Kind).
+			Msg("config drift from the recommended manifest resolved")
+	}
+}
+
+// fetchManifest fetches and decodes the manifest.
+func (m *DriftMonitor) fetchManifest(ctx context.Context) (Manifest, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.cfg.Manifest.URL, nil)
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("failed to fetch manifest: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Manifest{}, fmt.Errorf("failed to fetch manifest: unexpected status %s", resp.Status)
+	}
+
+	var manifest Manifest
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxManifestBodyBytes)).Decode(&manifest); err != nil {
+		return Manifest{}, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// ComputeDrift returns the differences between the assets, providers and
+// deviation thresholds of cfg and the recommended settings of the network,
+// sorted by asset and kind.
+func ComputeDrift(cfg config.Config, network NetworkManifest) []ConfigDrift {
+	var (
+		providers = make(map[string]map[provider.Name]struct{})
+		quotes    = make(map[string]struct{})
+	)
+	for _, pair := range cfg.CurrencyPairs {
+		quotes[strings.ToUpper(pair.Quote)] = struct{}{}
+		base := strings.ToUpper(pair.Base)
+		if _, ok := providers[base]; !ok {
+			providers[base] = make(map[provider.Name]struct{})
+		}
+		for _, name := range pair.Providers {
+			providers[base][name] = struct{}{}
+		}
+	}
+	thresholds := make(map[string]string, len(cfg.Deviations))
+	for _, deviation := range cfg.Deviations {
+		thresholds[strings.ToUpper(deviation.Base)] = deviation.Threshold
+	}
+
+	var drifts []ConfigDrift
+	recommended := make(map[string]struct{}, len(network.Assets))
+	for symbol, asset := range network.Assets {
+		base := strings.ToUpper(symbol)
+		recommended[base] = struct{}{}
+		configured, ok := providers[base]
+		if !ok {
+			drifts = append(drifts, ConfigDrift{Asset: base, Kind: DriftMissingAsset})
+			continue
+		}
+
+		for _, name := range asset.Providers {
+			if _, ok := configured[name]; !ok {
+				drifts = append(drifts, ConfigDrift{Asset: base, Kind: DriftMissingProvider, Expected: name.String()})
+			}
+		}
+
+		if asset.MinProviders > len(configured) {
+			drifts = append(drifts, ConfigDrift{
+				Asset:    base,
+				Kind:     DriftMinProviders,
+				Expected: strconv.Itoa(asset.MinProviders),
+				Actual:   strconv.Itoa(len(configured)),
+			})
+		}
+
+		if len(asset.DeviationThreshold) > 0 && !equalThresholds(asset.DeviationThreshold, thresholds[base]) {
+			drifts = append(drifts, ConfigDrift{
+				Asset:    base,
+				Kind:     DriftDeviationThreshold,
+				Expected: asset.DeviationThreshold,
+				Actual:   thresholds[base],
+			})
+		}
+	}
+
+	for base := range providers {
+		// the pairs converting the quotes of other pairs aren't voted
+		if _, ok := quotes[base]; ok {
+			continue
+		}
+		if _, ok := recommended[base]; !ok {
+			drifts = append(drifts, ConfigDrift{Asset: base, Kind: DriftUnexpectedAsset})
+		}
+	}
+
+	sort.Slice(drifts, func(i, j int) bool {
+		if drifts[i].Asset != drifts[j].Asset {
+			return drifts[i].Asset < drifts[j].Asset
+		}
+		if drifts[i].Kind != drifts[j].Kind {
+			return drifts[i].Kind < drifts[j].Kind
+		}
+		return drifts[i].Expected < drifts[j].Expected
+	})
+
+	return drifts
+}
+
+// equalThresholds returns whether the deviation thresholds are equal as
+// decimals, e.g. "1.5" and "1.50".
+func equalThresholds(expected, actual string) bool {
+	expectedDec, err := sdk.NewDecFromStr(expected)
+	if err != nil {
+		return expected == actual
+	}
+	actualDec, err := sdk.NewDecFromStr(actual)
+	if err != nil {
+		return false
+	}
+
+	return expectedDec.Equal(actualDec)
+}
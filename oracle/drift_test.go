@@ -0,0 +1,78 @@
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/config"
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+)
+
+func TestComputeDrift(t *testing.T) {
+	cfg := config.Config{
+		CurrencyPairs: []config.CurrencyPair{
+			{Base: "ATOM", Quote: "USDT", Providers: []provider.Name{provider.Binance, provider.Okx}},
+			{Base: "ATOM", Quote: "USD", Providers: []provider.Name{provider.Kraken}},
+			{Base: "USDT", Quote: "USD", Providers: []provider.Name{provider.Kraken}},
+			{Base: "JUNO", Quote: "USDT", Providers: []provider.Name{provider.Binance}},
+		},
+		Deviations: []config.Deviation{{Base: "ATOM", Threshold: "1.50"}},
+	}
+
+	drifts := ComputeDrift(cfg, NetworkManifest{Assets: map[string]AssetManifest{
+		"atom": {
+			Providers:          []provider.Name{provider.Binance, provider.Coinbase},
+			MinProviders:       4,
+			DeviationThreshold: "1.5",
+		},
+		"XPRT": {},
+	}})
+	require.Equal(t, []ConfigDrift{
+		{Asset: "ATOM", Kind: DriftMinProviders, Expected: "4", Actual: "3"},
+		{Asset: "ATOM", Kind: DriftMissingProvider, Expected: "coinbase"},
+		{Asset: "JUNO", Kind: DriftUnexpectedAsset},
+		{Asset: "XPRT", Kind: DriftMissingAsset},
+	}, drifts)
+
+	drifts = ComputeDrift(cfg, NetworkManifest{Assets: map[string]AssetManifest{
+		"ATOM": {DeviationThreshold: "2"},
+		"JUNO": {},
+	}})
+	require.Equal(t, []ConfigDrift{
+		{Asset: "ATOM", Kind: DriftDeviationThreshold, Expected: "2", Actual: "1.50"},
+	}, drifts)
+}
+
+func TestDriftMonitor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(rw, `{"networks": {"core-1": {"assets": {"ATOM": {}, "XPRT": {}}}}}`)
+	}))
+	defer server.Close()
+
+	cfg := config.Config{
+		CurrencyPairs: []config.CurrencyPair{{Base: "ATOM", Quote: "USD", Providers: []provider.Name{provider.Kraken}}},
+	}
+	cfg.Manifest.URL = server.URL
+	cfg.Manifest.Network = "core-1"
+	cfg.Manifest.Interval = "1h"
+
+	m, err := NewDriftMonitor(zerolog.Nop(), cfg)
+	require.NoError(t, err)
+	m.check(context.Background())
+
+	report := m.Report()
+	require.NoError(t, report.Err)
+	require.False(t, report.CheckedAt.IsZero())
+	require.Equal(t, []ConfigDrift{{Asset: "XPRT", Kind: DriftMissingAsset}}, report.Drifts)
+
+	// a network missing from the manifest fails the check
+	m.cfg.Manifest.Network = "test"
+	m.check(context.Background())
+	require.EqualError(t, m.Report().Err, "no network test in the config manifest")
+}
@@ -0,0 +1,49 @@
+package oracle
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+// scaleExchangeRates returns the prices scaled from the unit of their
+// configured price exponent to the unit of their exponent in the accept list,
+// e.g. a price quoted per 10^6 base units of an asset with an exponent of 18
+// is multiplied by 10^12. Prices without a configured price exponent, or of
+// assets not in the accept list, are returned as is.
+func scaleExchangeRates(
+	prices map[string]sdk.Dec,
+	assets *types.AssetRegistry,
+	priceExponents map[string]uint32,
+) map[string]sdk.Dec {
+	if assets == nil || len(priceExponents) == 0 {
+		return prices
+	}
+
+	scaled := make(map[string]sdk.Dec, len(prices))
+	for base, price := range prices {
+		scaled[base] = price
+
+		priceExponent, ok := priceExponents[base]
+		if !ok {
+			continue
+		}
+		asset, ok := assets.Lookup(base)
+		if !ok {
+			continue
+		}
+
+		scaled[base] = scalePrice(price, int64(asset.Exponent)-int64(priceExponent))
+	}
+
+	return scaled
+}
+
+// scalePrice multiplies price by 10^exponent.
+func scalePrice(price sdk.Dec, exponent int64) sdk.Dec {
+	ten := sdk.NewDec(10) //nolint:gomnd //decimal base
+	if exponent >= 0 {
+		return price.Mul(ten.Power(uint64(exponent)))
+	}
+	return price.Quo(ten.Power(uint64(-exponent)))
+}
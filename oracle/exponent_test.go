@@ -0,0 +1,54 @@
+package oracle
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	oracletypes "github.com/persistenceOne/persistence-sdk/v2/x/oracle/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScaleExchangeRates(t *testing.T) {
+	assets := NewAssetRegistry(oracletypes.DenomList{
+		{BaseDenom: "uatom", SymbolDenom: "ATOM", Exponent: 6},
+		{BaseDenom: "aevmos", SymbolDenom: "EVMOS", Exponent: 18},
+		{BaseDenom: "uxprt", SymbolDenom: "XPRT", Exponent: 6},
+	})
+	prices := map[string]sdk.Dec{
+		"ATOM":  sdk.MustNewDecFromStr("10"),
+		"EVMOS": sdk.MustNewDecFromStr("0.000000000002"),
+		"XPRT":  sdk.MustNewDecFromStr("2000000000000"),
+		"OSMO":  sdk.MustNewDecFromStr("1"),
+	}
+
+	t.Run("prices without exponents are not scaled", func(t *testing.T) {
+		require.Equal(t, prices, scaleExchangeRates(prices, assets, nil))
+		require.Equal(t, prices, scaleExchangeRates(prices, nil, map[string]uint32{"ATOM": 0}))
+	})
+
+	t.Run("prices are scaled to the accept list exponent", func(t *testing.T) {
+		scaled := scaleExchangeRates(prices, assets, map[string]uint32{
+			"ATOM":  6,
+			"EVMOS": 6,
+			"XPRT":  18,
+			"OSMO":  18,
+		})
+
+		require.Equal(t, sdk.MustNewDecFromStr("10"), scaled["ATOM"])
+		require.Equal(t, sdk.MustNewDecFromStr("2"), scaled["EVMOS"])
+		require.Equal(t, sdk.MustNewDecFromStr("2"), scaled["XPRT"])
+		require.Equal(t, sdk.MustNewDecFromStr("1"), scaled["OSMO"])
+	})
+
+	t.Run("exchange rates string uses scaled prices", func(t *testing.T) {
+		scaled := scaleExchangeRates(
+			map[string]sdk.Dec{"EVMOS": sdk.MustNewDecFromStr("0.000000000002")},
+			assets,
+			map[string]uint32{"EVMOS": 6},
+		)
+
+		exchangeRates, err := generateExchangeRatesString(scaled, nil)
+		require.NoError(t, err)
+		require.Equal(t, "EVMOS:2", exchangeRates)
+	})
+}
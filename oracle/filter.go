@@ -1,6 +1,10 @@
 package oracle
 
 import (
+	"sort"
+
+	"github.com/armon/go-metrics"
+	"github.com/cosmos/cosmos-sdk/telemetry"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/rs/zerolog"
 
@@ -13,13 +17,164 @@ import (
 // in the config.
 var defaultDeviationThreshold = sdk.MustNewDecFromStr("1.0")
 
+var counterKeyDeviationDecisions = []string{"deviation", "decisions"}
+
+// DeviationOutcome defines whether the price of an asset of a provider passed
+// the deviation filter.
+type DeviationOutcome string
+
+// Deviation outcomes.
+const (
+	// DeviationAccepted means the price is within the deviation threshold.
+	DeviationAccepted DeviationOutcome = "accepted"
+	// DeviationUnchecked means the price is accepted since there were too few
+	// sources to compute the standard deviation of the asset.
+	DeviationUnchecked DeviationOutcome = "unchecked"
+	// DeviationFiltered means the price deviates from the other providers and
+	// is not used for the asset. The provider remains used for other assets.
+	DeviationFiltered DeviationOutcome = "filtered"
+)
+
+// DeviationDecision records the outcome of the deviation filter for the
+// price of an asset of a provider.
+type DeviationDecision struct {
+	Provider provider.Name    `json:"provider"`
+	Base     string           `json:"base"`
+	Price    sdk.Dec          `json:"price"`
+	Mean     sdk.Dec          `json:"mean"`
+	StdDev   sdk.Dec          `json:"std_dev"`
+	Margin   sdk.Dec          `json:"margin"`
+	Outcome  DeviationOutcome `json:"outcome"`
+}
+
+// decideDeviation returns whether price of base of a provider is within T
+// standard deviations of the mean. T is defined as the deviation threshold,
+// either set by the config or defaulted to 1. A price for which we couldn't
+// get 𝜎 is accepted unchecked.
+func decideDeviation(
+	providerName provider.Name,
+	base string,
+	price sdk.Dec,
+	deviations, means, deviationThresholds map[string]sdk.Dec,
+) DeviationDecision {
+	decision := DeviationDecision{
+		Provider: providerName,
+		Base:     base,
+		Price:    price,
+		Mean:     sdk.ZeroDec(),
+		StdDev:   sdk.ZeroDec(),
+		Margin:   sdk.ZeroDec(),
+		Outcome:  DeviationUnchecked,
+	}
+
+	d, ok := deviations[base]
+	if !ok {
+		return decision
+	}
+
+	t := defaultDeviationThreshold
+	if _, ok := deviationThresholds[base]; ok {
+		t = deviationThresholds[base]
+	}
+
+	decision.Mean = means[base]
+	decision.StdDev = d
+	decision.Margin = d.Mul(t)
+	decision.Outcome = DeviationAccepted
+	if !isBetween(price, decision.Mean, decision.Margin) {
+		decision.Outcome = DeviationFiltered
+	}
+
+	return decision
+}
+
+// sortDeviationDecisions sorts the decisions by base and provider.
+func sortDeviationDecisions(decisions []DeviationDecision) {
+	sort.Slice(decisions, func(i, j int) bool {
+		if decisions[i].Base != decisions[j].Base {
+			return decisions[i].Base < decisions[j].Base
+		}
+		return decisions[i].Provider < decisions[j].Provider
+	})
+}
+
+// emitDeviationDecisions counts the decisions per asset, provider and outcome.
+func emitDeviationDecisions(decisions []DeviationDecision) {
+	for _, decision := range decisions {
+		telemetry.IncrCounterWithLabels(counterKeyDeviationDecisions, 1, []metrics.Label{
+			telemetry.NewLabel("asset", decision.Base),
+			telemetry.NewLabel("provider", decision.Provider.String()),
+			telemetry.NewLabel("outcome", string(decision.Outcome)),
+		})
+	}
+}
+
+// SourceGroups maps providers to the source group they belong to. Providers
+// of the same group ultimately proxy the same venue, e.g. an exchange and an
+// aggregator backed by it, and count as a single source.
+type SourceGroups map[provider.Name]string
+
+// Source returns the source of the provider, which is its group if it belongs
+// to one, or the provider itself.
+func (sg SourceGroups) Source(providerName provider.Name) provider.Name {
+	if group, ok := sg[providerName]; ok {
+		return provider.Name(group)
+	}
+	return providerName
+}
+
+// groupSources averages the prices of the providers of the same source group,
+// so that a venue proxied by several providers weighs as one sample when
+// computing the standard deviations and means.
+func groupSources(
+	prices map[provider.Name]map[string]sdk.Dec,
+	sourceGroups SourceGroups,
+) map[provider.Name]map[string]sdk.Dec {
+	if len(sourceGroups) == 0 {
+		return prices
+	}
+
+	var (
+		sums   = make(map[provider.Name]map[string]sdk.Dec)
+		counts = make(map[provider.Name]map[string]int64)
+	)
+	for providerName, assetPrices := range prices {
+		source := sourceGroups.Source(providerName)
+		if _, ok := sums[source]; !ok {
+			sums[source] = make(map[string]sdk.Dec)
+			counts[source] = make(map[string]int64)
+		}
+
+		for base, price := range assetPrices {
+			if _, ok := sums[source][base]; !ok {
+				sums[source][base] = sdk.ZeroDec()
+			}
+			sums[source][base] = sums[source][base].Add(price)
+			counts[source][base]++
+		}
+	}
+
+	grouped := make(map[provider.Name]map[string]sdk.Dec, len(sums))
+	for source, assetSums := range sums {
+		grouped[source] = make(map[string]sdk.Dec, len(assetSums))
+		for base, sum := range assetSums {
+			grouped[source][base] = sum.QuoInt64(counts[source][base])
+		}
+	}
+
+	return grouped
+}
+
 // FilterTickerDeviations finds the standard deviations of the prices of
-// all assets, and filters out any providers that are not within 2𝜎 of the mean.
+// all assets, and filters out the prices of any provider that are not within
+// 2𝜎 of the mean, per asset. Providers of the same source group count as a
+// single source. The decision for every price is returned as well.
 func FilterTickerDeviations(
 	logger zerolog.Logger,
 	prices provider.AggregatedProviderPrices,
 	deviationThresholds map[string]sdk.Dec,
-) (provider.AggregatedProviderPrices, error) {
+	sourceGroups SourceGroups,
+) (provider.AggregatedProviderPrices, []DeviationDecision, error) {
 	priceMap := make(map[provider.Name]map[string]sdk.Dec)
 	for providerName, priceTickers := range prices {
 		priceMap[providerName] = make(map[string]sdk.Dec)
@@ -28,23 +183,21 @@ func FilterTickerDeviations(
 		}
 	}
 
-	deviations, means, err := ComputeStandardDeviationsAndMeans(priceMap)
+	deviations, means, err := ComputeStandardDeviationsAndMeans(groupSources(priceMap, sourceGroups))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	// We accept any prices that are within (2 * T)𝜎, or for which we couldn't get 𝜎.
-	// T is defined as the deviation threshold, either set by the config
-	// or defaulted to 1.
-	filteredPrices := make(provider.AggregatedProviderPrices)
+	var (
+		filteredPrices = make(provider.AggregatedProviderPrices)
+		decisions      []DeviationDecision
+	)
 	for providerName, priceTickers := range prices {
 		for base, tp := range priceTickers {
-			t := defaultDeviationThreshold
-			if _, ok := deviationThresholds[base]; ok {
-				t = deviationThresholds[base]
-			}
+			decision := decideDeviation(providerName, base, tp.Price, deviations, means, deviationThresholds)
+			decisions = append(decisions, decision)
 
-			if d, ok := deviations[base]; !ok || isBetween(tp.Price, means[base], d.Mul(t)) {
+			if decision.Outcome != DeviationFiltered {
 				if _, ok := filteredPrices[providerName]; !ok {
 					filteredPrices[providerName] = make(map[string]types.TickerPrice)
 				}
@@ -60,35 +213,35 @@ func FilterTickerDeviations(
 		}
 	}
 
-	return filteredPrices, nil
+	sortDeviationDecisions(decisions)
+	return filteredPrices, decisions, nil
 }
 
 // filterCandleDeviations finds the standard deviations of the tvwaps of
-// all assets, and filters out any providers that are not within 2𝜎 of the mean.
+// all assets, and filters out the candles of any provider that are not within
+// 2𝜎 of the mean, per asset. Providers of the same source group count as a
+// single source. The decision for every tvwap is returned as well.
 //
 //nolint:funlen //No need to split this function
 func filterCandleDeviations(
 	logger zerolog.Logger,
 	candles provider.AggregatedProviderCandles,
 	deviationThresholds map[string]sdk.Dec,
-) (provider.AggregatedProviderCandles, error) {
+	sourceGroups SourceGroups,
+	window CandleWindow,
+) (provider.AggregatedProviderCandles, []DeviationDecision, error) {
 	var (
 		filteredCandles = make(provider.AggregatedProviderCandles)
 		tvwaps          = make(map[provider.Name]map[string]sdk.Dec)
+		decisions       []DeviationDecision
 	)
 
 	for providerName, priceCandles := range candles {
-		candlePrices := make(provider.AggregatedProviderCandles)
-
-		for base, cp := range priceCandles {
-			candlePrices[providerName] = map[string][]types.CandlePrice{
-				base: cp,
-			}
-		}
+		candlePrices := provider.AggregatedProviderCandles{providerName: priceCandles}
 
-		tvwap, err := ComputeTVWAP(candlePrices)
+		tvwap, err := ComputeTVWAPWithin(candlePrices, window)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		for base, asset := range tvwap {
@@ -100,22 +253,17 @@ func filterCandleDeviations(
 		}
 	}
 
-	deviations, means, err := ComputeStandardDeviationsAndMeans(tvwaps)
+	deviations, means, err := ComputeStandardDeviationsAndMeans(groupSources(tvwaps, sourceGroups))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	// We accept any prices that are within (2 * T)𝜎, or for which we couldn't get 𝜎.
-	// T is defined as the deviation threshold, either set by the config
-	// or defaulted to 1.
 	for providerName, priceMap := range tvwaps {
 		for base, price := range priceMap {
-			t := defaultDeviationThreshold
-			if _, ok := deviationThresholds[base]; ok {
-				t = deviationThresholds[base]
-			}
+			decision := decideDeviation(providerName, base, price, deviations, means, deviationThresholds)
+			decisions = append(decisions, decision)
 
-			if d, ok := deviations[base]; !ok || isBetween(price, means[base], d.Mul(t)) {
+			if decision.Outcome != DeviationFiltered {
 				if _, ok := filteredCandles[providerName]; !ok {
 					filteredCandles[providerName] = make(map[string][]types.CandlePrice)
 				}
@@ -131,7 +279,8 @@ func filterCandleDeviations(
 		}
 	}
 
-	return filteredCandles, nil
+	sortDeviationDecisions(decisions)
+	return filteredCandles, decisions, nil
 }
 
 func isBetween(p, mean, margin sdk.Dec) bool {
@@ -46,23 +46,30 @@ func TestSuccessFilterCandleDeviations(t *testing.T) {
 		},
 	}
 
-	pricesFiltered, err := filterCandleDeviations(
+	pricesFiltered, decisions, err := filterCandleDeviations(
 		zerolog.Nop(),
 		providerCandles,
 		make(map[string]sdk.Dec),
+		nil,
+		DefaultCandleWindow,
 	)
 
 	_, ok := pricesFiltered[provider.Osmosis]
 	require.NoError(t, err, "It should successfully filter out the provider using candles")
 	require.False(t, ok, "The filtered candle deviation price at coinbase should be empty")
+	require.Len(t, decisions, 3)
+	require.Equal(t, provider.Osmosis, decisions[2].Provider)
+	require.Equal(t, DeviationFiltered, decisions[2].Outcome)
 
 	customDeviations := make(map[string]sdk.Dec, 1)
 	customDeviations[pair.Base] = sdk.NewDec(2)
 
-	pricesFilteredCustom, err := filterCandleDeviations(
+	pricesFilteredCustom, _, err := filterCandleDeviations(
 		zerolog.Nop(),
 		providerCandles,
 		customDeviations,
+		nil,
+		DefaultCandleWindow,
 	)
 
 	_, ok = pricesFilteredCustom[provider.Osmosis]
@@ -98,10 +105,11 @@ func TestSuccessFilterTickerDeviations(t *testing.T) {
 		},
 	}
 
-	pricesFiltered, err := FilterTickerDeviations(
+	pricesFiltered, _, err := FilterTickerDeviations(
 		zerolog.Nop(),
 		providerTickers,
 		make(map[string]sdk.Dec),
+		nil,
 	)
 
 	_, ok := pricesFiltered[provider.Osmosis]
@@ -111,13 +119,86 @@ func TestSuccessFilterTickerDeviations(t *testing.T) {
 	customDeviations := make(map[string]sdk.Dec, 1)
 	customDeviations[pair.Base] = sdk.NewDec(2)
 
-	pricesFilteredCustom, err := FilterTickerDeviations(
+	pricesFilteredCustom, _, err := FilterTickerDeviations(
 		zerolog.Nop(),
 		providerTickers,
 		customDeviations,
+		nil,
 	)
 
 	_, ok = pricesFilteredCustom[provider.Osmosis]
 	require.NoError(t, err, "It should successfully not filter out coinbase")
 	require.True(t, ok, "The filtered candle deviation price of coinbase should remain")
 }
+
+func TestFilterTickerDeviationsSourceGroups(t *testing.T) {
+	atomVolume := sdk.MustNewDecFromStr("1994674.34000000")
+	providerTickers := provider.AggregatedProviderPrices{
+		provider.Binance: {"ATOM": {Price: sdk.MustNewDecFromStr("29.93"), Volume: atomVolume}},
+		provider.Kraken:  {"ATOM": {Price: sdk.MustNewDecFromStr("29.93"), Volume: atomVolume}},
+		provider.Osmosis: {"ATOM": {Price: sdk.MustNewDecFromStr("27.1"), Volume: atomVolume}},
+	}
+
+	// grouped, binance and kraken count as a single source, leaving too few
+	// sources to compute a standard deviation and filter osmosis out
+	pricesFiltered, decisions, err := FilterTickerDeviations(
+		zerolog.Nop(),
+		providerTickers,
+		make(map[string]sdk.Dec),
+		SourceGroups{provider.Binance: "binance", provider.Kraken: "binance"},
+	)
+	require.NoError(t, err)
+	require.Len(t, pricesFiltered, 3)
+	for _, decision := range decisions {
+		require.Equal(t, DeviationUnchecked, decision.Outcome)
+	}
+}
+
+func TestFilterCandleDeviationsPerAsset(t *testing.T) {
+	candle := func(price string) []types.CandlePrice {
+		return []types.CandlePrice{{
+			Price:     sdk.MustNewDecFromStr(price),
+			Volume:    sdk.OneDec(),
+			TimeStamp: provider.PastUnixTime(time.Minute),
+		}}
+	}
+	providerCandles := provider.AggregatedProviderCandles{
+		provider.Binance: {"ATOM": candle("29.93"), "OSMO": candle("1.01")},
+		provider.Kraken:  {"ATOM": candle("29.93"), "OSMO": candle("1.01")},
+		provider.Osmosis: {"ATOM": candle("27.1"), "OSMO": candle("1.01")},
+	}
+
+	// osmosis deviates for ATOM only and keeps contributing to OSMO
+	filtered, decisions, err := filterCandleDeviations(
+		zerolog.Nop(),
+		providerCandles,
+		make(map[string]sdk.Dec),
+		nil,
+		DefaultCandleWindow,
+	)
+	require.NoError(t, err)
+	require.NotContains(t, filtered[provider.Osmosis], "ATOM")
+	require.Contains(t, filtered[provider.Osmosis], "OSMO")
+	require.Len(t, decisions, 6)
+	for _, decision := range decisions {
+		expected := DeviationAccepted
+		if decision.Provider == provider.Osmosis && decision.Base == "ATOM" {
+			expected = DeviationFiltered
+		}
+		require.Equal(t, expected, decision.Outcome, "%s %s", decision.Provider, decision.Base)
+	}
+}
+
+func TestGroupSources(t *testing.T) {
+	prices := map[provider.Name]map[string]sdk.Dec{
+		provider.Binance:   {"ATOM": sdk.NewDec(10)},
+		provider.BinanceUS: {"ATOM": sdk.NewDec(12)},
+		provider.Kraken:    {"ATOM": sdk.NewDec(13)},
+	}
+
+	grouped := groupSources(prices, SourceGroups{provider.Binance: "binance", provider.BinanceUS: "binance"})
+	require.Equal(t, map[provider.Name]map[string]sdk.Dec{
+		"binance":       {"ATOM": sdk.NewDec(11)},
+		provider.Kraken: {"ATOM": sdk.NewDec(13)},
+	}, grouped)
+}
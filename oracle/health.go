@@ -0,0 +1,102 @@
+package oracle
+
+import (
+	"time"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+)
+
+type (
+	// Health defines how current the oracle is, as reported by the health
+	// probes: when its prices were last synced, the block height it follows
+	// and whether each of its providers answered its last requests.
+	//
+	// Stale is set once the last price sync is older than the maximum sync
+	// age, see WithMaxSyncAge, and HeightStale once the block height didn't
+	// increase for the maximum height age, see WithMaxHeightAge.
+	Health struct {
+		LastSync        time.Time
+		SyncAge         time.Duration
+		Stale           bool
+		Height          int64
+		HeightErr       error
+		HeightUpdatedAt time.Time
+		HeightAge       time.Duration
+		HeightStale     bool
+		Providers       map[provider.Name]ProviderHealth
+	}
+
+	// ProviderHealth defines the outcome of the last requests of a provider.
+	// A provider is connected once its last tickers and candles were fetched,
+	// and is skipped while its exchange is under maintenance.
+	ProviderHealth struct {
+		Connected        bool
+		UnderMaintenance bool
+		LastSuccess      time.Time
+		LastFailure      time.Time
+		LastError        string
+	}
+)
+
+// WithMaxSyncAge sets the age of the last price sync past which the oracle
+// is reported as stale by GetHealth, typically a few vote periods. By default
+// the age of the last sync is not checked.
+func WithMaxSyncAge(maxAge time.Duration) Option {
+	return func(o *Oracle) {
+		o.maxSyncAge = maxAge
+	}
+}
+
+// GetHealth returns how current the oracle is, see Health.
+func (o *Oracle) GetHealth() Health {
+	now := time.Now()
+	health := Health{
+		LastSync:  o.GetLastPriceSyncTimestamp(),
+		Providers: o.getProviderHealth(),
+	}
+	if !health.LastSync.IsZero() {
+		health.SyncAge = now.Sub(health.LastSync)
+		health.Stale = o.maxSyncAge > 0 && health.SyncAge > o.maxSyncAge
+	}
+
+	health.Height, health.HeightErr = o.client.GetHeight()
+	health.HeightUpdatedAt = o.client.HeightUpdatedAt()
+	if !health.HeightUpdatedAt.IsZero() {
+		health.HeightAge = now.Sub(health.HeightUpdatedAt)
+		health.HeightStale = o.maxHeightAge > 0 && health.HeightAge > o.maxHeightAge
+	}
+
+	return health
+}
+
+// getProviderHealth returns a copy of the health of the providers, flagging
+// those under maintenance.
+func (o *Oracle) getProviderHealth() map[provider.Name]ProviderHealth {
+	o.healthMutex.RLock()
+	defer o.healthMutex.RUnlock()
+
+	providerHealth := make(map[provider.Name]ProviderHealth, len(o.providerHealth))
+	for providerName, health := range o.providerHealth {
+		health.UnderMaintenance = o.isUnderMaintenance(providerName)
+		providerHealth[providerName] = health
+	}
+
+	return providerHealth
+}
+
+// recordProviderHealth records the outcome of the requests of a provider
+// during a tick, err being nil if they succeeded.
+func (o *Oracle) recordProviderHealth(providerName provider.Name, err error) {
+	o.healthMutex.Lock()
+	defer o.healthMutex.Unlock()
+
+	health := o.providerHealth[providerName]
+	health.Connected = err == nil
+	if err != nil {
+		health.LastFailure = time.Now()
+		health.LastError = err.Error()
+	} else {
+		health.LastSuccess = time.Now()
+	}
+	o.providerHealth[providerName] = health
+}
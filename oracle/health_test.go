@@ -0,0 +1,53 @@
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+)
+
+func TestGetHealth(t *testing.T) {
+	o, mc := newVotingOracle(t, 20)
+	WithMaxSyncAge(time.Minute)(o)
+	WithMaxHeightAge(15 * time.Second)(o)
+	mc.SetHeightUpdatedAt(time.Now())
+
+	health := o.GetHealth()
+	require.True(t, health.LastSync.IsZero())
+	require.False(t, health.Stale)
+	require.Equal(t, int64(20), health.Height)
+	require.False(t, health.HeightStale)
+	require.Empty(t, health.Providers)
+
+	o.tick(context.Background())
+	o.recordProviderHealth(provider.Kraken, fmt.Errorf("connection refused"))
+	o.updateProviderStatus(context.Background(), provider.Kraken, statusChecker(true))
+
+	health = o.GetHealth()
+	require.False(t, health.LastSync.IsZero())
+	require.False(t, health.Stale)
+	require.True(t, health.Providers[provider.Binance].Connected)
+	require.False(t, health.Providers[provider.Binance].LastSuccess.IsZero())
+	require.False(t, health.Providers[provider.Kraken].Connected)
+	require.True(t, health.Providers[provider.Kraken].UnderMaintenance)
+	require.Equal(t, "connection refused", health.Providers[provider.Kraken].LastError)
+
+	// a provider is connected again once its requests succeed
+	o.recordProviderHealth(provider.Kraken, nil)
+	require.True(t, o.GetHealth().Providers[provider.Kraken].Connected)
+
+	o.pricesMutex.Lock()
+	o.lastPriceSyncTS = time.Now().Add(-2 * time.Minute)
+	o.pricesMutex.Unlock()
+	mc.SetHeightUpdatedAt(time.Now().Add(-20 * time.Second))
+
+	health = o.GetHealth()
+	require.True(t, health.Stale)
+	require.GreaterOrEqual(t, health.SyncAge, 2*time.Minute)
+	require.True(t, health.HeightStale)
+}
@@ -0,0 +1,80 @@
+package oracle
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultHistorySize is the number of samples kept by the price history,
+// about an hour of ticks.
+const defaultHistorySize = 720
+
+type (
+	// PriceHistory keeps the per-provider prices of the most recent oracle
+	// ticks in a fixed size ring buffer.
+	PriceHistory struct {
+		mtx     sync.RWMutex
+		samples []PriceSample
+		next    int
+		full    bool
+	}
+
+	// PriceSample defines the prices each provider reported in a single tick.
+	PriceSample struct {
+		Time   time.Time
+		Prices PricesByProvider
+	}
+)
+
+// NewPriceHistory returns a price history keeping the given number of samples.
+func NewPriceHistory(size int) *PriceHistory {
+	return &PriceHistory{
+		samples: make([]PriceSample, size),
+	}
+}
+
+// Add records the provider prices of a tick, evicting the oldest sample once
+// the history is full.
+func (ph *PriceHistory) Add(t time.Time, prices PricesByProvider) {
+	ph.mtx.Lock()
+	defer ph.mtx.Unlock()
+
+	if len(ph.samples) == 0 {
+		return
+	}
+
+	ph.samples[ph.next] = PriceSample{Time: t, Prices: prices}
+	ph.next = (ph.next + 1) % len(ph.samples)
+	if ph.next == 0 {
+		ph.full = true
+	}
+}
+
+// Restore records the given samples, ordered from oldest to newest, e.g.
+// persisted by a previous run.
+func (ph *PriceHistory) Restore(samples []PriceSample) {
+	for _, sample := range samples {
+		ph.Add(sample.Time, sample.Prices)
+	}
+}
+
+// Since returns the samples recorded after t, ordered from oldest to newest.
+// The returned samples must not be modified.
+func (ph *PriceHistory) Since(t time.Time) []PriceSample {
+	ph.mtx.RLock()
+	defer ph.mtx.RUnlock()
+
+	ordered := ph.samples[:ph.next]
+	if ph.full {
+		ordered = append(append([]PriceSample{}, ph.samples[ph.next:]...), ph.samples[:ph.next]...)
+	}
+
+	samples := make([]PriceSample, 0, len(ordered))
+	for _, sample := range ordered {
+		if sample.Time.After(t) {
+			samples = append(samples, sample)
+		}
+	}
+
+	return samples
+}
@@ -0,0 +1,213 @@
+package oracle
+
+import (
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+
+	oracletypes "github.com/persistenceOne/persistence-sdk/v2/x/oracle/types"
+)
+
+// LatencyStage defines a stage of the path of a vote, from fetching the
+// provider prices to the inclusion of the vote transaction in a block.
+type LatencyStage string
+
+const (
+	LatencyStageFetch       LatencyStage = "fetch"
+	LatencyStageAggregation LatencyStage = "aggregation"
+	LatencyStageHash        LatencyStage = "hash"
+	LatencyStageBroadcast   LatencyStage = "broadcast"
+	LatencyStageInclusion   LatencyStage = "inclusion"
+
+	// LatencyStageTotal labels the latency of the whole path.
+	LatencyStageTotal LatencyStage = "total"
+)
+
+// LatencyStages are the stages of the path of a vote, in order.
+var LatencyStages = []LatencyStage{
+	LatencyStageFetch,
+	LatencyStageAggregation,
+	LatencyStageHash,
+	LatencyStageBroadcast,
+	LatencyStageInclusion,
+}
+
+const (
+	voteKindPrevote = "prevote"
+	voteKindVote    = "vote"
+)
+
+type (
+	// VoteLatency measures the latency of the path of every pre-vote and vote,
+	// stage by stage: from the start of the tick, the fetch of the provider
+	// prices, their aggregation, the hash of the pre-vote or the build of the
+	// vote, the broadcast of the transaction until the node accepts it and its
+	// inclusion in a block. The latencies of the included transactions are
+	// observed in a histogram labelled by kind and stage, so that operators
+	// can check that the path fits within the vote period.
+	VoteLatency struct {
+		logger    zerolog.Logger
+		histogram *prometheus.HistogramVec
+		now       func() time.Time
+
+		mtx     sync.Mutex
+		tick    latencyTrace
+		pending map[string]latencyTrace // vote kind => trace awaiting inclusion
+	}
+
+	latencyTrace struct {
+		kind  string
+		start time.Time
+		marks map[LatencyStage]time.Time
+	}
+)
+
+// NewVoteLatency returns a vote latency tracker whose histogram is registered
+// with registerer, unless nil.
+func NewVoteLatency(logger zerolog.Logger, registerer prometheus.Registerer) (*VoteLatency, error) {
+	histogram := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "price_feeder",
+		Subsystem: "vote",
+		Name:      "latency_seconds",
+		Help:      "Latency of the stages of the path of the included pre-votes and votes.",
+		Buckets:   prometheus.ExponentialBuckets(0.05, 2, 12), //nolint: gomnd // 50ms to ~100s
+	}, []string{"kind", "stage"})
+	if registerer != nil {
+		if err := registerer.Register(histogram); err != nil {
+			return nil, err
+		}
+	}
+
+	return &VoteLatency{
+		logger:    logger.With().Str("module", "latency").Logger(),
+		histogram: histogram,
+		now:       time.Now,
+		pending:   make(map[string]latencyTrace),
+	}, nil
+}
+
+// StartTick starts tracing the path of the vote of a new tick, dropping the
+// trace of the previous tick if it didn't broadcast.
+func (l *VoteLatency) StartTick() {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	l.tick = latencyTrace{start: l.now(), marks: make(map[LatencyStage]time.Time, len(LatencyStages))}
+}
+
+// Mark marks the end of the stage of the path of the vote of the tick.
+func (l *VoteLatency) Mark(stage LatencyStage) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if l.tick.marks != nil {
+		l.tick.marks[stage] = l.now()
+	}
+}
+
+// MarkHash marks the end of the hash stage of the tick, whose vote is a
+// pre-vote or a vote.
+func (l *VoteLatency) MarkHash(prevote bool) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if l.tick.marks == nil {
+		return
+	}
+	l.tick.kind = voteKindVote
+	if prevote {
+		l.tick.kind = voteKindPrevote
+	}
+	l.tick.marks[LatencyStageHash] = l.now()
+}
+
+// RecordBroadcast records that the node accepted the transaction with the
+// messages of type URLs msgTypes, which, if it holds the pre-vote or vote of
+// the tick, then awaits its inclusion. A transaction broadcast again, e.g.
+// after failing to be included, is measured from its last broadcast.
+func (l *VoteLatency) RecordBroadcast(msgTypes []string) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	kind := voteKind(msgTypes)
+	if len(kind) == 0 {
+		return
+	}
+
+	// the trace of the tick takes over a pending one, whose transaction was
+	// never confirmed
+	trace, ok := l.pending[kind]
+	if l.tick.kind == kind {
+		trace, ok = l.tick, true
+		l.tick = latencyTrace{}
+	}
+	if !ok {
+		return
+	}
+	trace.marks[LatencyStageBroadcast] = l.now()
+	l.pending[kind] = trace
+}
+
+// RecordInclusion records that the transaction with the messages of type URLs
+// msgTypes was included in a block, and observes the latencies of the path of
+// its pre-vote or vote.
+func (l *VoteLatency) RecordInclusion(msgTypes []string) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	kind := voteKind(msgTypes)
+	trace, ok := l.pending[kind]
+	if !ok {
+		return
+	}
+	delete(l.pending, kind)
+	trace.marks[LatencyStageInclusion] = l.now()
+
+	latencies := trace.latencies()
+	for stage, latency := range latencies {
+		l.histogram.WithLabelValues(kind, string(stage)).Observe(latency.Seconds())
+	}
+
+	event := l.logger.Debug().Str("kind", kind)
+	for _, stage := range append(LatencyStages, LatencyStageTotal) {
+		event = event.Dur(string(stage), latencies[stage])
+	}
+	event.Msg("vote latency")
+}
+
+// latencies returns the latency of every marked stage, since the end of the
+// previous marked stage, and of the whole path.
+func (t latencyTrace) latencies() map[LatencyStage]time.Duration {
+	latencies := make(map[LatencyStage]time.Duration, len(LatencyStages)+1)
+
+	prev := t.start
+	for _, stage := range LatencyStages {
+		mark, ok := t.marks[stage]
+		if !ok {
+			continue
+		}
+		latencies[stage] = mark.Sub(prev)
+		prev = mark
+	}
+	latencies[LatencyStageTotal] = prev.Sub(t.start)
+
+	return latencies
+}
+
+// voteKind returns the kind of the vote held by the messages of type URLs
+// msgTypes, if any.
+func voteKind(msgTypes []string) string {
+	for _, msgType := range msgTypes {
+		switch msgType {
+		case sdk.MsgTypeURL(&oracletypes.MsgAggregateExchangeRatePrevote{}):
+			return voteKindPrevote
+		case sdk.MsgTypeURL(&oracletypes.MsgAggregateExchangeRateVote{}):
+			return voteKindVote
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,73 @@
+package oracle
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	oracletypes "github.com/persistenceOne/persistence-sdk/v2/x/oracle/types"
+)
+
+func TestVoteLatency(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	l, err := NewVoteLatency(zerolog.Nop(), registry)
+	require.NoError(t, err)
+
+	now := time.Unix(1_700_000_000, 0)
+	l.now = func() time.Time { return now }
+	step := func(d time.Duration) { now = now.Add(d) }
+
+	var (
+		prevote = []string{sdk.MsgTypeURL(&oracletypes.MsgAggregateExchangeRatePrevote{})}
+		vote    = []string{sdk.MsgTypeURL(&oracletypes.MsgAggregateExchangeRateVote{})}
+	)
+
+	l.StartTick()
+	step(2 * time.Second)
+	l.Mark(LatencyStageFetch)
+	step(100 * time.Millisecond)
+	l.Mark(LatencyStageAggregation)
+	step(10 * time.Millisecond)
+	l.MarkHash(true)
+	step(200 * time.Millisecond)
+	l.RecordBroadcast(prevote)
+
+	// the vote of the next tick doesn't complete the pre-vote
+	l.StartTick()
+	l.RecordInclusion(vote)
+	require.Zero(t, testutil.CollectAndCount(registry))
+
+	step(5 * time.Second)
+	l.RecordInclusion(prevote)
+	require.Equal(t, 6, testutil.CollectAndCount(registry))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	require.Equal(t, "price_feeder_vote_latency_seconds", families[0].GetName())
+
+	sums := make(map[string]float64)
+	for _, metric := range families[0].GetMetric() {
+		labels := make(map[string]string)
+		for _, label := range metric.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+		require.Equal(t, voteKindPrevote, labels["kind"])
+		sums[labels["stage"]] = metric.GetHistogram().GetSampleSum()
+	}
+	require.InDelta(t, 2, sums[string(LatencyStageFetch)], 1e-9)
+	require.InDelta(t, 0.1, sums[string(LatencyStageAggregation)], 1e-9)
+	require.InDelta(t, 0.01, sums[string(LatencyStageHash)], 1e-9)
+	require.InDelta(t, 0.2, sums[string(LatencyStageBroadcast)], 1e-9)
+	require.InDelta(t, 5, sums[string(LatencyStageInclusion)], 1e-9)
+	require.InDelta(t, 7.31, sums[string(LatencyStageTotal)], 1e-9)
+
+	// a pre-vote included once isn't observed again
+	l.RecordInclusion(prevote)
+	require.Equal(t, 6, testutil.CollectAndCount(registry))
+}
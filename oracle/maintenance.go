@@ -0,0 +1,76 @@
+package oracle
+
+import (
+	"context"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+)
+
+// providerStatusInterval defines how often the system status of the exchanges
+// is polled.
+const providerStatusInterval = time.Minute
+
+// isUnderMaintenance returns true if the exchange of the provider announced a
+// maintenance on its last status check.
+func (o *Oracle) isUnderMaintenance(providerName provider.Name) bool {
+	o.maintenanceMutex.RLock()
+	defer o.maintenanceMutex.RUnlock()
+
+	return o.maintenance[providerName]
+}
+
+// checkProviderStatus polls, in the background, the system status of every
+// provider implementing provider.StatusChecker, so that providers whose
+// exchange is under maintenance are skipped instead of timing out during a
+// vote. A failed status request leaves the provider's status unchanged.
+func (o *Oracle) checkProviderStatus(ctx context.Context) {
+	if time.Since(o.lastStatusCheck) < providerStatusInterval {
+		return
+	}
+	o.lastStatusCheck = time.Now()
+
+	checkers := make(map[provider.Name]provider.StatusChecker)
+	for providerName, priceProvider := range o.priceProviders {
+		if checker, ok := priceProvider.(provider.StatusChecker); ok {
+			checkers[providerName] = checker
+		}
+	}
+
+	for providerName, checker := range checkers {
+		go o.updateProviderStatus(ctx, providerName, checker)
+	}
+}
+
+func (o *Oracle) updateProviderStatus(ctx context.Context, providerName provider.Name, checker provider.StatusChecker) {
+	underMaintenance, err := checker.UnderMaintenance(ctx)
+	if err != nil {
+		o.logger.Debug().Err(err).Str("provider", providerName.String()).Msg("failed to check provider status")
+		return
+	}
+
+	o.maintenanceMutex.Lock()
+	wasUnderMaintenance := o.maintenance[providerName]
+	o.maintenance[providerName] = underMaintenance
+	o.maintenanceMutex.Unlock()
+
+	switch {
+	case underMaintenance && !wasUnderMaintenance:
+		o.logger.Warn().Str("provider", providerName.String()).Msg("provider is under maintenance; skipping it")
+	case !underMaintenance && wasUnderMaintenance:
+		o.logger.Info().Str("provider", providerName.String()).Msg("provider maintenance is over")
+	}
+
+	var gauge float32
+	if underMaintenance {
+		gauge = 1
+	}
+	telemetry.SetGaugeWithLabels(
+		[]string{"provider", "maintenance"},
+		gauge,
+		[]metrics.Label{telemetry.NewLabel("provider", providerName.String())},
+	)
+}
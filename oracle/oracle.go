@@ -4,22 +4,24 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math"
 	"math/rand"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/armon/go-metrics"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/cosmos/cosmos-sdk/telemetry"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/rs/zerolog"
 	"golang.org/x/sync/errgroup"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/persistenceOne/oracle-feeder/config"
 	"github.com/persistenceOne/oracle-feeder/oracle/provider"
 	"github.com/persistenceOne/oracle-feeder/oracle/types"
+	"github.com/persistenceOne/oracle-feeder/pkg/features"
 	pfsync "github.com/persistenceOne/oracle-feeder/pkg/sync"
 
 	oracletypes "github.com/persistenceOne/persistence-sdk/v2/x/oracle/types"
@@ -30,16 +32,28 @@ import (
 var (
 	errExpectedPositiveBlockHeight = errors.New("expected positive block height")
 	errNoPriceAvailable            = errors.New("price is not available")
+
+	counterKeyStaleHeights        = []string{"chain", "stale_height"}
+	counterKeyMissingPrices       = []string{"price", "missing"}
+	counterKeyDroppedPriceUpdates = []string{"price", "subscribers", "dropped"}
+	gaugeKeyPriceSubscribers      = []string{"price", "subscribers"}
 )
 
-// We define tickerTimeout as the minimum timeout between each oracle loop. We
-// define this value empirically based on enough time to collect exchange rates,
-// and broadcast pre-vote and vote transactions such that they're committed in a
-// block during each voting period.
+// Ticks are triggered by new blocks. We define tickerTimeout as the timeout
+// after which the block height is polled again, in case a new block was not
+// notified, or retried after failing to get it.
 const (
 	tickerTimeout = 5 * time.Second
 )
 
+// maxExchangeRatesLength is the length of the exchange rates string above
+// which x/oracle rejects the vote. It accepts a single aggregate vote per
+// validator and vote period, checked against a single pre-vote hash, so the
+// rates cannot be split across messages: they are formatted without trailing
+// zeros instead, and their precision can be reduced further, see
+// config.CurrencyPair.
+const maxExchangeRatesLength = 4096
+
 // PreviousPrevote defines a structure for defining the previous prevote
 // submitted on-chain.
 type PreviousPrevote struct {
@@ -52,25 +66,219 @@ type PreviousPrevote struct {
 // for a given set of currency pairs and determining the correct exchange rates
 // to submit to the on-chain price oracle adhering the oracle specification.
 type Oracle struct {
-	logger zerolog.Logger
-	closer *pfsync.Closer
-
-	providerTimeout    time.Duration
-	providerPairs      map[provider.Name][]types.CurrencyPair
-	previousPrevote    *PreviousPrevote
-	previousVotePeriod float64
-	priceProviders     map[provider.Name]provider.Provider
-	client             client.OracleClient
-	deviations         map[string]sdk.Dec
-	endpoints          map[provider.Name]provider.Endpoint
-	paramCache         ParamCache
-
-	pricesMutex     sync.RWMutex
-	lastPriceSyncTS time.Time
-	prices          map[string]sdk.Dec
+	logger  zerolog.Logger
+	closer  *pfsync.Closer
+	stopped *pfsync.Closer
+	started atomic.Bool
+
+	providerTimeout time.Duration
+	providerPairs   map[provider.Name][]types.CurrencyPair
+	priceExponents  map[string]uint32
+	precisions      map[string]PricePrecision
+	critical        map[string]struct{}
+	backupPools     []*BackupPool
+	statePath       string
+	votes           VoteMachine
+	priceProviders  map[provider.Name]provider.Provider
+	client          client.OracleClient
+	endpoints       map[provider.Name]provider.Endpoint
+	paramCache      ParamCache
+	paused          atomic.Bool
+	restart         atomic.Bool
+	counters        *Counters
+	slo             *SLOTracker
+	spend           *SpendTracker
+	latency         *VoteLatency
+	drift           *DriftMonitor
+	weights         *ProviderWeights
+	history         *PriceHistory
+	sourceGroups    SourceGroups
+	anomalies       *AnomalyDetector
+	candleWindow    CandleWindow
+	timeoutMargin   int64
+	maxHeightAge    time.Duration
+	maxSyncAge      time.Duration
+	hub             *provider.HubClient
+
+	// criticalAssetPolicy is applied when critical assets are missing a
+	// price, see WithCriticalAssetPolicy.
+	criticalAssetPolicy string
+
+	// features gates the experimental subsystems, see WithFeatures.
+	features *features.Flags
+
+	// voteOnlyProviders are the providers whose prices must not be
+	// re-exposed, see WithVoteOnlyProviders.
+	voteOnlyProviders []provider.Name
+
+	// unchangedEpsilon enables the unchanged path, see WithUnchangedEpsilon.
+	unchangedEpsilon sdk.Dec
+	lastVote         *LastVote
+	aggregatedInputs aggregationInputs
+	pricesUnchanged  bool
+
+	assetsMutex sync.RWMutex
+	assets      *types.AssetRegistry
+
+	lastCorrelationCheck time.Time
+	lastStatusCheck      time.Time
+
+	maintenanceMutex sync.RWMutex
+	maintenance      map[provider.Name]bool
+
+	healthMutex    sync.RWMutex
+	providerHealth map[provider.Name]ProviderHealth
+
+	leaderMutex    sync.RWMutex
+	leaderActivity LeaderActivity
+
+	deviationsMutex sync.RWMutex
+	deviations      map[string]sdk.Dec
+
+	pricesMutex       sync.RWMutex
+	lastPriceSyncTS   time.Time
+	prices            map[string]sdk.Dec
+	priceTimestamps   map[string]time.Time
+	providerSnapshots map[provider.Name]provider.Snapshot
+
+	subscribersMutex sync.Mutex
+	subscribers      map[chan map[string]sdk.Dec]struct{}
 
 	tvwapsByProvider PricesWithMutex
 	vwapsByProvider  PricesWithMutex
+
+	aggregationsMutex sync.RWMutex
+	aggregations      map[string]AssetAggregation
+	aggregationCounts map[string]map[AggregationMethod]uint64
+	voteAction        VoteAction
+}
+
+// Option configures optional components of the Oracle.
+type Option func(*Oracle)
+
+// WithSLOTracker sets the tracker recording the outcome of the vote of each
+// vote period. By default the vote success ratio is tracked without a target.
+func WithSLOTracker(slo *SLOTracker) Option {
+	return func(o *Oracle) {
+		o.slo = slo
+	}
+}
+
+// WithSpendTracker sets the tracker the oracle client records the spend of the
+// feeder account to, which the oracle reports. By default no spend is tracked.
+func WithSpendTracker(spend *SpendTracker) Option {
+	return func(o *Oracle) {
+		o.spend = spend
+	}
+}
+
+// WithVoteLatency sets the tracker measuring the latency of the path of the
+// votes, which the oracle client records their broadcast and inclusion to. By
+// default the latencies are measured without being exported.
+func WithVoteLatency(latency *VoteLatency) Option {
+	return func(o *Oracle) {
+		o.latency = latency
+	}
+}
+
+// WithDriftMonitor sets the monitor of the drift of the config from the
+// recommended config manifest, which the oracle reports. By default no drift
+// is monitored.
+func WithDriftMonitor(drift *DriftMonitor) Option {
+	return func(o *Oracle) {
+		o.drift = drift
+	}
+}
+
+// WithCounters sets the counters the oracle records submitted votes, misses
+// and provider failures to. By default they are kept in memory only.
+func WithCounters(counters *Counters) Option {
+	return func(o *Oracle) {
+		o.counters = counters
+	}
+}
+
+// WithSourceGroups sets the source groups of the providers, which count as a
+// single source when filtering deviating prices. By default every provider is
+// its own source.
+func WithSourceGroups(sourceGroups SourceGroups) Option {
+	return func(o *Oracle) {
+		o.sourceGroups = sourceGroups
+	}
+}
+
+// WithAnomalyDetector sets the detector holding the vote of assets whose
+// price jumps without corroboration. By default no vote is held.
+func WithAnomalyDetector(anomalies *AnomalyDetector) Option {
+	return func(o *Oracle) {
+		o.anomalies = anomalies
+	}
+}
+
+// WithCandleWindow sets the TVWAP period and the maximum age of candles. By
+// default both are five minutes.
+func WithCandleWindow(window CandleWindow) Option {
+	return func(o *Oracle) {
+		o.candleWindow = window
+	}
+}
+
+// WithTimeoutMargin sets the number of blocks before the end of the vote
+// period past which pre-votes and votes time out, leaving room for the node
+// to be behind the chain. By default they are valid up to the last block of
+// the period.
+func WithTimeoutMargin(blocks int64) Option {
+	return func(o *Oracle) {
+		o.timeoutMargin = blocks
+	}
+}
+
+// WithMaxHeightAge sets the time after which a block height which didn't
+// increase is considered stale, typically about two block times. Voting is
+// skipped while the height is stale and the client fails over to its next
+// node. By default the age of the height is not checked.
+func WithMaxHeightAge(maxAge time.Duration) Option {
+	return func(o *Oracle) {
+		o.maxHeightAge = maxAge
+	}
+}
+
+// WithHub sets the hub the prices of the providers are received from, instead
+// of connecting to the exchanges. By default the providers connect to their
+// exchange.
+func WithHub(hub *provider.HubClient) Option {
+	return func(o *Oracle) {
+		o.hub = hub
+	}
+}
+
+// WithCriticalAssetPolicy sets the policy applied when the price of a critical
+// asset is missing: config.CriticalAssetSkip doesn't pre-vote until it is
+// priced again, and config.CriticalAssetAbstain pre-votes the other assets
+// without it. By default pre-votes are skipped.
+func WithCriticalAssetPolicy(policy string) Option {
+	return func(o *Oracle) {
+		o.criticalAssetPolicy = policy
+	}
+}
+
+// WithFeatures sets the feature flags gating the experimental subsystems of
+// the oracle, which may be toggled while it runs. By default the flags have
+// their default state.
+func WithFeatures(flags *features.Flags) Option {
+	return func(o *Oracle) {
+		o.features = flags
+	}
+}
+
+// WithVoteOnlyProviders sets the providers whose prices are only used to vote,
+// see config.Config.IsVoteOnly: the prices of the assets priced by them alone
+// are left out of the metrics, which are served by the API. By default every
+// price is emitted.
+func WithVoteOnlyProviders(providers []provider.Name) Option {
+	return func(o *Oracle) {
+		o.voteOnlyProviders = providers
+	}
 }
 
 func New(
@@ -80,10 +288,26 @@ func New(
 	providerTimeout time.Duration,
 	deviations map[string]sdk.Dec,
 	endpoints map[provider.Name]provider.Endpoint,
+	opts ...Option,
 ) *Oracle {
 	providerPairs := make(map[provider.Name][]types.CurrencyPair)
+	priceExponents := make(map[string]uint32)
+	precisions := make(map[string]PricePrecision)
+	critical := make(map[string]struct{})
 
 	for _, pair := range currencyPairs {
+		if pair.Exponent != nil {
+			priceExponents[pair.Base] = *pair.Exponent
+		}
+		if pair.Precision != nil {
+			precisions[pair.Base] = PricePrecision{
+				Decimals: *pair.Precision,
+				Truncate: pair.Rounding == config.RoundingTruncate,
+			}
+		}
+		if pair.Priority == config.PriorityCritical {
+			critical[pair.Base] = struct{}{}
+		}
 		for _, provider := range pair.Providers {
 			providerPairs[provider] = append(providerPairs[provider], types.CurrencyPair{
 				Base:  pair.Base,
@@ -91,71 +315,267 @@ func New(
 			})
 		}
 	}
-	return &Oracle{
+	o := &Oracle{
 		logger:          logger.With().Str("module", "oracle").Logger(),
 		closer:          pfsync.NewCloser(),
+		stopped:         pfsync.NewCloser(),
 		client:          oc,
 		providerPairs:   providerPairs,
+		priceExponents:  priceExponents,
+		precisions:      precisions,
+		critical:        critical,
+		backupPools:     newBackupPools(currencyPairs),
 		priceProviders:  make(map[provider.Name]provider.Provider),
-		previousPrevote: nil,
 		providerTimeout: providerTimeout,
 		deviations:      deviations,
 		paramCache:      ParamCache{},
 		endpoints:       endpoints,
+		priceTimestamps: make(map[string]time.Time),
+		subscribers:     make(map[chan map[string]sdk.Dec]struct{}),
+		weights:         NewProviderWeights(),
+		history:         NewPriceHistory(defaultHistorySize),
+		maintenance:     make(map[provider.Name]bool),
+		providerHealth:  make(map[provider.Name]ProviderHealth),
+		candleWindow:    DefaultCandleWindow,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.counters == nil {
+		o.counters = NewCounters(logger)
 	}
+	if o.slo == nil {
+		o.slo = NewSLOTracker(logger, 0)
+	}
+	if o.spend == nil {
+		o.spend = NewSpendTracker(logger, nil)
+	}
+	if o.latency == nil {
+		// the histogram can't fail to register without a registerer
+		o.latency, _ = NewVoteLatency(logger, nil)
+	}
+
+	return o
 }
 
 /*
 This function is a method of a struct called Oracle in Go language.
-The function starts an infinite loop that repeatedly performs an "oracle tick"
-and sleeps for a period of time defined by the tickerTimeout variable.
-
-Each tick of the loop performs the following operations:
+The function starts an infinite loop that performs an "oracle tick" on every new
+block of the chain and otherwise waits for the next one.
 
- - It checks if the context is done and if so, it closes the closer object and exit the loop.
+Each iteration of the loop performs the following operations:
 
- - It logs a message at the debug level, indicating that the oracle tick has begun.
+ - It checks if the context is done or the oracle is stopped and if so, it exits the loop,
+which disconnects the providers.
 
- - It stores the current time in the startTime variable.
+ - It subscribes to the next new block before reading the block height, so that a block
+received while ticking triggers the next tick right away.
 
- - It calls another function called "executeTick" and pass the context. If this function
-returns an error, it increments a counter for failures and logs the error message returned.
+ - Unless the block height was already evaluated, it calls tick, which calls "executeTick"
+and logs the summary of the tick. This guarantees a single evaluation per block: blocks
+received during a tick are evaluated once, at the latest height. A stale block height is
+evaluated again on every poll, so that the tick fails over to the next node.
 
- - It sets the value of the lastPriceSyncTS variable to the current time.
+ - It waits for the next block, unless stopped, polling the block height again after the
+tickerTimeout in case a new block was not notified.
 
- - It sleeps for a period of time defined by the tickerTimeout variable.
-
-It is likely that this function is designed to run continuously in the background and periodically
-update some sort of price data which is being used by the smart contract. The executeTick function
-which is being called inside the loop could be doing the price fetching and updating job.
+It is likely that this function is designed to run continuously in the background and
+update some sort of price data which is being used by the smart contract. The executeTick
+function which is being called inside the loop could be doing the price fetching and
+updating job.
 */
 
 // Start starts the oracle process in a blocking fashion.
 func (o *Oracle) Start(ctx context.Context) error {
+	o.started.Store(true)
+	defer o.stopped.Close()
+
+	// the providers are bound to this context and stopped once the loop
+	// exits, closing their connections
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer o.stopProviders()
+	defer o.saveState()
+
+	var evaluatedHeight int64
 	for {
 		select {
 		case <-ctx.Done():
 			o.closer.Close()
+			return nil
+
+		case <-o.closer.Done():
+			o.logger.Info().Msg("oracle stopped; disconnecting providers")
+			return nil
 
 		default:
-			o.logger.Debug().Msg("starting oracle tick")
+		}
 
-			if err := o.executeTick(ctx); err != nil {
-				o.logger.Err(err).Msg("oracle tick failed")
-			}
+		// the providers are only used by the tick loop, hence restarted here
+		if o.restart.Swap(false) {
+			o.stopProviders()
+		}
 
-			o.lastPriceSyncTS = time.Now()
+		newBlock := o.client.NewBlock()
+		blockHeight, err := o.client.GetHeight()
+		if err != nil || blockHeight != evaluatedHeight || o.heightAge() > o.maxHeightAge {
+			// a failure to get the height is reported by the tick itself
+			evaluatedHeight = blockHeight
+			o.tick(ctx)
+		}
 
-			o.logger.Debug().Msg("New tick")
-			time.Sleep(tickerTimeout)
+		select {
+		case <-ctx.Done():
+		case <-o.closer.Done():
+		case <-newBlock:
+		case <-time.After(tickerTimeout):
+			o.logger.Debug().Int64("height", evaluatedHeight).Msg("no new block received")
 		}
 	}
 }
 
-// Stop stops the oracle process and waits for it to gracefully exit.
+// heightAge returns how long ago the block height last increased, or zero if
+// its age is not checked.
+func (o *Oracle) heightAge() time.Duration {
+	if o.maxHeightAge <= 0 {
+		return 0
+	}
+
+	return time.Since(o.client.HeightUpdatedAt())
+}
+
+// handleStaleHeight alerts that the block height is stale and fails over to
+// the next node of the client.
+func (o *Oracle) handleStaleHeight(ctx context.Context, blockHeight int64, age time.Duration) {
+	o.logger.Error().
+		Int64("height", blockHeight).
+		Dur("age", age).
+		Dur("max_age", o.maxHeightAge).
+		Msg("block height is stale; skipping vote and failing over to the next node")
+	telemetry.IncrCounter(1, counterKeyStaleHeights...)
+
+	if err := o.client.Failover(ctx); err != nil {
+		o.logger.Err(err).Msg("failed to fail over to the next node")
+	}
+}
+
+// tick executes an oracle tick and reports it.
+func (o *Oracle) tick(ctx context.Context) {
+	o.logger.Debug().Msg("starting oracle tick")
+	startTime := time.Now()
+
+	err := o.executeTick(ctx)
+	if err != nil {
+		o.logger.Err(err).Msg("oracle tick failed")
+	}
+	o.logTickSummary(startTime, err)
+
+	o.pricesMutex.Lock()
+	o.lastPriceSyncTS = time.Now()
+	emitPriceAges(o.lastPriceSyncTS, o.priceTimestamps)
+	emitPrices(o.publicPrices(o.prices))
+	o.pricesMutex.Unlock()
+	o.counters.Refresh()
+	o.slo.Report()
+}
+
+// Stop stops the oracle process and waits for it to gracefully exit: the tick
+// in progress, including any vote being broadcast, completes and the
+// providers are disconnected before Stop returns.
 func (o *Oracle) Stop() {
 	o.closer.Close()
-	<-o.closer.Done()
+	if o.started.Load() {
+		<-o.stopped.Done()
+	}
+}
+
+// IsStopping returns true once the oracle has been asked to stop.
+func (o *Oracle) IsStopping() bool {
+	select {
+	case <-o.closer.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// Pause stops the oracle from broadcasting pre-votes and votes. Prices keep
+// being fetched and served while paused.
+func (o *Oracle) Pause() {
+	if !o.paused.Swap(true) {
+		o.logger.Info().Msg("oracle voting paused")
+	}
+}
+
+// Resume re-enables voting after a call to Pause.
+func (o *Oracle) Resume() {
+	if o.paused.Swap(false) {
+		o.logger.Info().Msg("oracle voting resumed")
+	}
+}
+
+// IsPaused returns true if voting is currently paused.
+func (o *Oracle) IsPaused() bool {
+	return o.paused.Load()
+}
+
+// RestartProviders stops the providers before the next tick, which connects
+// to them again, e.g. to release the goroutines and connections leaked by a
+// provider.
+func (o *Oracle) RestartProviders() {
+	if !o.restart.Swap(true) {
+		o.logger.Warn().Msg("provider restart requested")
+	}
+}
+
+// SetDeviations replaces the deviation thresholds used to filter provider
+// prices, starting with the next tick.
+func (o *Oracle) SetDeviations(deviations map[string]sdk.Dec) {
+	o.deviationsMutex.Lock()
+	defer o.deviationsMutex.Unlock()
+
+	o.deviations = deviations
+}
+
+// getDeviations returns the deviation thresholds currently in use.
+func (o *Oracle) getDeviations() map[string]sdk.Dec {
+	o.deviationsMutex.RLock()
+	defer o.deviationsMutex.RUnlock()
+
+	return o.deviations
+}
+
+// GetProviderWeights returns the aggregation weights learned per provider and
+// asset.
+func (o *Oracle) GetProviderWeights() map[provider.Name]map[string]ProviderWeight {
+	return o.weights.All()
+}
+
+// ResetProviderWeights resets the weight of every provider to one.
+func (o *Oracle) ResetProviderWeights() {
+	o.weights.Reset()
+	o.logger.Info().Msg("provider weights reset")
+}
+
+// GetVoteSLO returns the vote success ratios over the SLO windows.
+func (o *Oracle) GetVoteSLO() SLOReport {
+	return o.slo.Report()
+}
+
+// GetSpend returns the gas used and fees paid by the feeder account, in total
+// and over the spend windows.
+func (o *Oracle) GetSpend() SpendReport {
+	return o.spend.Report()
+}
+
+// GetConfigDrift returns the drift of the config from the recommended config
+// manifest, and whether it is monitored.
+func (o *Oracle) GetConfigDrift() (DriftReport, bool) {
+	if o.drift == nil {
+		return DriftReport{}, false
+	}
+	return o.drift.Report(), true
 }
 
 // GetLastPriceSyncTimestamp returns the latest timestamp at which prices where
@@ -183,6 +603,98 @@ func (o *Oracle) GetPrices() map[string]sdk.Dec {
 	return prices
 }
 
+// GetPriceTimestamps returns the time at which the price of every asset was
+// last computed, including the assets left out of the current prices since.
+// Unlike the last price sync, which is updated on every tick, it tells apart
+// the individual assets whose prices are stale.
+func (o *Oracle) GetPriceTimestamps() map[string]time.Time {
+	o.pricesMutex.RLock()
+	defer o.pricesMutex.RUnlock()
+
+	timestamps := make(map[string]time.Time, len(o.priceTimestamps))
+	for base, timestamp := range o.priceTimestamps {
+		timestamps[base] = timestamp
+	}
+
+	return timestamps
+}
+
+// GetProviderSnapshots returns the ticker and candle prices every provider
+// returned on the last tick, as streamed to the instances using this one as
+// their hub.
+func (o *Oracle) GetProviderSnapshots() map[provider.Name]provider.Snapshot {
+	o.pricesMutex.RLock()
+	defer o.pricesMutex.RUnlock()
+
+	snapshots := make(map[provider.Name]provider.Snapshot, len(o.providerSnapshots))
+	for providerName, snapshot := range o.providerSnapshots {
+		snapshots[providerName] = snapshot
+	}
+
+	return snapshots
+}
+
+// SubscribePrices returns a channel receiving a copy of the computed prices
+// after every tick, along with a function cancelling the subscription. Updates
+// are dropped for subscribers which did not consume the previous one, so that
+// slow consumers never block the oracle.
+func (o *Oracle) SubscribePrices() (<-chan map[string]sdk.Dec, func()) {
+	ch := make(chan map[string]sdk.Dec, 1)
+
+	o.subscribersMutex.Lock()
+	o.subscribers[ch] = struct{}{}
+	o.subscribersMutex.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			o.subscribersMutex.Lock()
+			delete(o.subscribers, ch)
+			o.subscribersMutex.Unlock()
+		})
+	}
+}
+
+// publishPrices sends the current prices to every subscriber, emitting the
+// number of subscribers and counting the updates dropped for slow ones.
+func (o *Oracle) publishPrices() {
+	o.subscribersMutex.Lock()
+	defer o.subscribersMutex.Unlock()
+
+	for ch := range o.subscribers {
+		select {
+		case ch <- o.GetPrices():
+		default:
+			o.logger.Debug().Msg("dropping prices update for slow subscriber")
+			telemetry.IncrCounter(1, counterKeyDroppedPriceUpdates...)
+		}
+	}
+	telemetry.SetGauge(float32(len(o.subscribers)), gaugeKeyPriceSubscribers...)
+}
+
+// Sign signs msg with the feeder key, see client.OracleClient.Sign.
+func (o *Oracle) Sign(msg []byte) ([]byte, cryptotypes.PubKey, error) {
+	return o.client.Sign(msg)
+}
+
+// providerPricesSnapshot returns the latest price of every provider, which is
+// its TVWAP when candles were available or its VWAP otherwise.
+func (o *Oracle) providerPricesSnapshot() PricesByProvider {
+	prices := o.GetTVWAPPrices()
+	for providerName, vwaps := range o.GetVWAPPrices() {
+		if _, ok := prices[providerName]; !ok {
+			prices[providerName] = make(map[string]sdk.Dec, len(vwaps))
+		}
+		for base, price := range vwaps {
+			if _, ok := prices[providerName][base]; !ok {
+				prices[providerName][base] = price
+			}
+		}
+	}
+
+	return prices
+}
+
 // GetTVWAPPrices returns a copy of the tvwapsByProvider map.
 func (o *Oracle) GetTVWAPPrices() PricesByProvider {
 	return o.tvwapsByProvider.GetPricesClone()
@@ -228,28 +740,48 @@ func (o *Oracle) setPrices(ctx context.Context) error {
 	mtx := sync.Mutex{}
 	providerPrices := provider.AggregatedProviderPrices{}
 	providerCandles := provider.AggregatedProviderCandles{}
+	providerPairs := o.tickProviderPairs()
+	providerSnapshots := make(map[provider.Name]provider.Snapshot, len(providerPairs))
 	requiredRates := map[string]struct{}{}
 
-	for providerName, currencyPairs := range o.providerPairs {
+	for providerName, currencyPairs := range providerPairs {
 		pn := providerName
 		priceProvider, err := o.getOrSetProvider(ctx, pn)
 		if err != nil {
-			return err
+			if _, ok := o.providerPairs[pn]; ok {
+				return err
+			}
+			// a backup provider failing to connect must not affect the others
+			o.logger.Err(err).Str("provider", pn.String()).Msg("failed to connect backup provider")
+			continue
 		}
 
 		for _, pair := range currencyPairs {
 			requiredRates[pair.Base] = struct{}{}
 		}
 
+		if o.isUnderMaintenance(pn) {
+			o.logger.Debug().Str("provider", pn.String()).Msg("skipping provider under maintenance")
+			continue
+		}
+
 		cp := currencyPairs
 		g.Go(func() error {
-			prices, err := priceProvider.GetTickerPrices(cp...)
+			start := time.Now()
+			prices, err := priceProvider.GetTickerPrices(ctx, cp...)
+			measureProviderFetch(pn, providerFetchTickers, start, err)
 			if err != nil {
+				o.counters.IncrProviderFailures(pn)
+				o.recordProviderHealth(pn, err)
 				return err
 			}
 
-			candles, err := priceProvider.GetCandlePrices(cp...)
+			start = time.Now()
+			candles, err := priceProvider.GetCandlePrices(ctx, cp...)
+			measureProviderFetch(pn, providerFetchCandles, start, err)
+			o.recordProviderHealth(pn, err)
 			if err != nil {
+				o.counters.IncrProviderFailures(pn)
 				return err
 			}
 
@@ -257,10 +789,12 @@ func (o *Oracle) setPrices(ctx context.Context) error {
 			//
 			// e.g.: {Kraken: {"ATOM": <price, volume>, ...}}
 			mtx.Lock()
+			providerSnapshots[pn] = provider.Snapshot{Tickers: prices, Candles: candles}
 			for _, pair := range cp {
 				success := SetProviderTickerPricesAndCandles(pn, providerPrices, providerCandles, prices, candles, pair)
 				if !success {
 					mtx.Unlock()
+					o.counters.IncrProviderFailures(pn)
 					return fmt.Errorf("failed to find any exchange rates in provider responses")
 				}
 			}
@@ -273,26 +807,54 @@ func (o *Oracle) setPrices(ctx context.Context) error {
 	if err := g.Wait(); err != nil {
 		o.logger.Err(err).Msg("failed to get ticker prices from provider")
 	}
-
-	computedPrices, err := o.GetComputedPrices(
-		providerCandles,
-		providerPrices,
-		o.providerPairs,
-		o.deviations,
-	)
-	if err != nil {
-		return err
+	o.checkProviderStatus(ctx)
+	o.updateBackups(ctx, providerPrices, providerCandles)
+	o.latency.Mark(LatencyStageFetch)
+
+	// on the unchanged path, the prices aggregated from the same inputs are
+	// kept instead of being computed again
+	inputs := newAggregationInputs(providerPrices, providerCandles)
+	unchanged := len(o.prices) > 0 && o.pricedByVWAP() && inputs.unchanged(o.aggregatedInputs, o.unchangedEpsilon)
+	computedPrices := o.prices
+	if unchanged {
+		telemetry.IncrCounter(1, "prices", "unchanged")
+	} else {
+		var err error
+		computedPrices, err = o.GetComputedPrices(
+			providerCandles,
+			providerPrices,
+			providerPairs,
+			o.getDeviations(),
+		)
+		if err != nil {
+			return err
+		}
+		o.aggregatedInputs = inputs
 	}
 
 	for base := range requiredRates {
 		if _, ok := computedPrices[base]; !ok {
-			o.logger.Warn().Str("asset", base).Msg("unable to report price for expected asset")
+			o.reportMissingPrice(base)
 		}
 	}
 
+	now := time.Now()
 	o.pricesMutex.Lock()
+	o.pricesUnchanged = unchanged
 	o.prices = computedPrices
+	o.providerSnapshots = providerSnapshots
+	for base := range computedPrices {
+		o.priceTimestamps[base] = now
+	}
 	o.pricesMutex.Unlock()
+
+	o.history.Add(time.Now(), o.providerPricesSnapshot())
+	if !unchanged {
+		o.checkCorrelations()
+	}
+	o.latency.Mark(LatencyStageAggregation)
+
+	o.publishPrices()
 	return nil
 }
 
@@ -306,12 +868,12 @@ returns an error if any.
 - it computes TVWAP by provider using computeTvwapsByProvider function and sets the values to
 o.tvwapsByProvider.
 - it computes TVWAP using ComputeTVWAP function.
-- If TVWAP candles are not available or were filtered out, the function uses most recent prices
-and VWAP instead:
+- For the assets whose TVWAP candles are not available or were filtered out, the function uses
+most recent prices and VWAP instead:
 - it converts tickers to USD using ConvertTickersToUSD function.
 - it filters tickers deviations using FilterTickerDeviations function.
 - it computes VWAP by provider using computeVwapsByProvider function and sets the values to o.vwapsByProvider.
-- it computes VWAP using ComputeVWAP function and return the prices and error if any.
+- it computes VWAP using ComputeVWAP function and merges the prices with the TVWAP ones.
 This function appears to be responsible for taking in data from multiple providers, performing
 some computations and calculations on that data (such as converting to USD and filtering out outliers),
 and then returning the final computed prices.
@@ -322,9 +884,9 @@ calculate price, it also consider the deviation rate to filter out inaccurate da
 */
 
 // GetComputedPrices gets the candle and ticker prices and computes it.
-// It returns candles' TVWAP if possible, if not possible (not available
-// or due to some staleness) it will use the most recent ticker prices
-// and the VWAP formula instead.
+// It returns candles' TVWAP per asset if possible, if not possible (not
+// available or due to some staleness) it will use the most recent ticker
+// prices and the VWAP formula instead for that asset.
 //
 //nolint:funlen //No need to split this function
 func (o *Oracle) GetComputedPrices(
@@ -334,65 +896,141 @@ func (o *Oracle) GetComputedPrices(
 	deviations map[string]sdk.Dec,
 ) (prices map[string]sdk.Dec, err error) {
 	// convert any non-USD denominated candles into USD
-	convertedCandles, err := ConvertCandlesToUSD(
+	convertedCandles, candleRates, err := convertCandlesToUSD(
 		o.logger,
 		providerCandles,
 		providerPairs,
 		deviations,
+		o.sourceGroups,
+		o.candleWindow,
 	)
 	if err != nil {
 		return nil, err
 	}
 
 	// filter out any erroneous candles
-	filteredCandles, err := filterCandleDeviations(
+	filteredCandles, candleDecisions, err := filterCandleDeviations(
 		o.logger,
 		convertedCandles,
 		deviations,
+		o.sourceGroups,
+		o.candleWindow,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	computedPrices, _ := computeTvwapsByProvider(filteredCandles)
+	computedPrices, _ := computeTvwapsByProvider(filteredCandles, o.candleWindow)
 	o.tvwapsByProvider.SetPrices(computedPrices)
 
-	// attempt to use candles for TVWAP calculations
-	tvwapPrices, err := ComputeTVWAP(filteredCandles)
+	// attempt to use candles for TVWAP calculations, weighting each provider
+	// by its historical accuracy
+	weightedCandles := filteredCandles
+	if o.features.Enabled(features.ProviderWeights) {
+		weightedCandles = o.weights.ApplyToCandles(filteredCandles)
+	}
+	tvwapPrices, err := ComputeTVWAPWithin(weightedCandles, o.candleWindow)
 	if err != nil {
 		return nil, err
 	}
 
-	// If TVWAP candles are not available or were filtered out due to staleness,
-	// use most recent prices & VWAP instead.
-	if len(tvwapPrices) == 0 {
-		convertedTickers, err := ConvertTickersToUSD(
-			o.logger,
-			providerPrices,
-			providerPairs,
-			deviations,
-		)
-		if err != nil {
-			return nil, err
-		}
+	o.weights.Update(computedPrices, tvwapPrices)
+	aggregations := pricedAggregations(tvwapPrices, withConversions(
+		newAssetAggregations(AggregationTVWAP, candleDecisions), providerPairs, candleRates,
+	))
 
-		filteredProviderPrices, err := FilterTickerDeviations(
-			o.logger,
-			convertedTickers,
-			deviations,
-		)
-		if err != nil {
+	// Assets whose candles are not available or were filtered out due to
+	// staleness use the most recent ticker prices & VWAP instead.
+	if !hasUnpricedBases(providerPairs, tvwapPrices) {
+		o.setAggregations(aggregations)
+		return tvwapPrices, nil
+	}
+
+	vwapPrices, vwapAggregations, err := o.computeTickerPrices(providerPrices, providerPairs, deviations, tvwapPrices)
+	if err != nil {
+		if len(tvwapPrices) == 0 {
 			return nil, err
 		}
+		o.logger.Warn().Err(err).Msg("failed to compute ticker prices; using candle prices only")
+		o.setAggregations(aggregations)
+		return tvwapPrices, nil
+	}
+
+	prices = make(map[string]sdk.Dec, len(tvwapPrices)+len(vwapPrices))
+	for base, price := range tvwapPrices {
+		prices[base] = price
+	}
+	for base, price := range vwapPrices {
+		prices[base] = price
+		aggregations[base] = vwapAggregations[base]
+	}
+
+	o.setAggregations(aggregations)
+	return prices, nil
+}
+
+// computeTickerPrices converts the tickers to USD, filters out erroneous ones
+// and returns the VWAP of every asset not already priced along with its
+// aggregation.
+func (o *Oracle) computeTickerPrices(
+	providerPrices provider.AggregatedProviderPrices,
+	providerPairs map[provider.Name][]types.CurrencyPair,
+	deviations map[string]sdk.Dec,
+	priced map[string]sdk.Dec,
+) (map[string]sdk.Dec, map[string]AssetAggregation, error) {
+	convertedTickers, tickerRates, err := convertTickersToUSD(
+		o.logger,
+		providerPrices,
+		providerPairs,
+		deviations,
+		o.sourceGroups,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		o.vwapsByProvider.SetPrices(computeVwapsByProvider(filteredProviderPrices))
+	filteredProviderPrices, tickerDecisions, err := FilterTickerDeviations(
+		o.logger,
+		convertedTickers,
+		deviations,
+		o.sourceGroups,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		vwapPrices := ComputeVWAP(filteredProviderPrices)
+	vwapsByProvider := computeVwapsByProvider(filteredProviderPrices)
+	o.vwapsByProvider.SetPrices(vwapsByProvider)
 
-		return vwapPrices, nil
+	weightedPrices := filteredProviderPrices
+	if o.features.Enabled(features.ProviderWeights) {
+		weightedPrices = o.weights.ApplyToTickers(filteredProviderPrices)
 	}
+	vwapPrices := ComputeVWAP(weightedPrices)
+	for base := range priced {
+		delete(vwapPrices, base)
+	}
+	o.weights.Update(vwapsByProvider, vwapPrices)
+
+	aggregations := pricedAggregations(vwapPrices, withConversions(
+		newAssetAggregations(AggregationVWAP, tickerDecisions), providerPairs, tickerRates,
+	))
 
-	return tvwapPrices, nil
+	return vwapPrices, aggregations, nil
+}
+
+// hasUnpricedBases returns true if any base of the provider pairs has no
+// price.
+func hasUnpricedBases(providerPairs map[provider.Name][]types.CurrencyPair, prices map[string]sdk.Dec) bool {
+	for _, pairs := range providerPairs {
+		for _, pair := range pairs {
+			if _, ok := prices[pair.Base]; !ok {
+				return true
+			}
+		}
+	}
+
+	return false
 }
 
 func (o *Oracle) getOrSetProvider(ctx context.Context, providerName provider.Name) (provider.Provider, error) {
@@ -402,17 +1040,25 @@ func (o *Oracle) getOrSetProvider(ctx context.Context, providerName provider.Nam
 	)
 
 	priceProvider, ok = o.priceProviders[providerName]
-	if !ok {
+	if !ok && o.hub != nil {
+		priceProvider = provider.NewHubProvider(o.hub, providerName)
+		o.priceProviders[providerName] = priceProvider
+	} else if !ok {
 		newProvider, err := NewProvider(
 			ctx,
 			providerName,
 			o.logger,
 			o.endpoints[providerName],
-			o.providerPairs[providerName]...,
+			o.pairsOf(providerName)...,
 		)
 		if err != nil {
 			return nil, err
 		}
+		if lifecycle, ok := newProvider.(provider.Lifecycle); ok {
+			if err := lifecycle.Start(); err != nil {
+				return nil, fmt.Errorf("failed to start provider %s: %w", providerName, err)
+			}
+		}
 		priceProvider = newProvider
 
 		o.priceProviders[providerName] = priceProvider
@@ -421,6 +1067,23 @@ func (o *Oracle) getOrSetProvider(ctx context.Context, providerName provider.Nam
 	return priceProvider, nil
 }
 
+// removeProvider stops the provider, if started, and forgets it, so that it
+// is constructed and started again the next time it is needed.
+func (o *Oracle) removeProvider(providerName provider.Name) {
+	if lifecycle, ok := o.priceProviders[providerName].(provider.Lifecycle); ok {
+		lifecycle.Stop()
+	}
+	delete(o.priceProviders, providerName)
+}
+
+// stopProviders stops the started providers, closing their connections.
+func (o *Oracle) stopProviders() {
+	for providerName := range o.priceProviders {
+		o.removeProvider(providerName)
+	}
+	o.logger.Debug().Msg("stopped providers")
+}
+
 func NewProvider(
 	ctx context.Context,
 	providerName provider.Name,
@@ -428,40 +1091,64 @@ func NewProvider(
 	endpoint provider.Endpoint,
 	providerPairs ...types.CurrencyPair,
 ) (provider.Provider, error) {
-	switch providerName {
-	case provider.Binance:
-		return provider.NewBinanceProvider(ctx, logger, endpoint, false, providerPairs...)
+	newProvider, ok := provider.Lookup(providerName)
+	if !ok {
+		return nil, fmt.Errorf("provider %s not found", providerName)
+	}
 
-	case provider.BinanceUS:
-		return provider.NewBinanceProvider(ctx, logger, endpoint, true, providerPairs...)
+	return newProvider(ctx, logger, endpoint, providerPairs...)
+}
 
-	case provider.Kraken:
-		return provider.NewKrakenProvider(ctx, logger, endpoint, providerPairs...)
+// NewAssetRegistry returns the registry of the assets of the x/oracle accept
+// list.
+func NewAssetRegistry(acceptList oracletypes.DenomList) *types.AssetRegistry {
+	assets := make([]types.Asset, 0, len(acceptList))
+	for _, denom := range acceptList {
+		assets = append(assets, types.Asset{
+			Symbol:   types.NewSymbol(denom.SymbolDenom),
+			Denom:    denom.BaseDenom,
+			Exponent: denom.Exponent,
+		})
+	}
+
+	return types.NewAssetRegistry(assets...)
+}
 
-	case provider.Osmosis:
-		return provider.NewOsmosisProvider(endpoint), nil
+// GetAssets returns the registry of the assets accepted on chain, as of the
+// last parameters update, or nil if the parameters were not queried yet.
+func (o *Oracle) GetAssets() *types.AssetRegistry {
+	o.assetsMutex.RLock()
+	defer o.assetsMutex.RUnlock()
 
-	case provider.Huobi:
-		return provider.NewHuobiProvider(ctx, logger, endpoint, providerPairs...)
+	return o.assets
+}
 
-	case provider.Coinbase:
-		return provider.NewCoinbaseProvider(ctx, logger, endpoint, providerPairs...)
+// checkAcceptList updates the asset registry from the accept list, warning
+// about the accepted assets the oracle has no price for and the configured
+// assets which are not accepted.
+func (o *Oracle) checkAcceptList(params oracletypes.Params) {
+	assets := NewAssetRegistry(params.AcceptList)
 
-	case provider.Crypto:
-		return provider.NewCryptoProvider(ctx, logger, endpoint, providerPairs...)
+	o.assetsMutex.Lock()
+	o.assets = assets
+	o.assetsMutex.Unlock()
 
-	case provider.Mock:
-		return provider.NewMockProvider("", nil)
+	prices := o.GetPrices()
+	for _, asset := range assets.Assets() {
+		if _, ok := prices[asset.Symbol.String()]; !ok {
+			o.logger.Warn().Str("denom", asset.Symbol.String()).Msg("price missing for required denom")
+		}
 	}
 
-	return nil, fmt.Errorf("provider %s not found", providerName)
-}
-
-func (o *Oracle) checkAcceptList(params oracletypes.Params) {
-	for _, denom := range params.AcceptList {
-		symbol := strings.ToUpper(denom.SymbolDenom)
-		if _, ok := o.prices[symbol]; !ok {
-			o.logger.Warn().Str("denom", symbol).Msg("price missing for required denom")
+	configured := make(map[string]struct{})
+	for _, pairs := range o.providerPairs {
+		for _, pair := range pairs {
+			configured[pair.Base] = struct{}{}
+		}
+	}
+	for base := range configured {
+		if err := assets.Validate(base); err != nil {
+			o.logger.Warn().Err(err).Msg("configured asset is not voted on")
 		}
 	}
 }
@@ -469,8 +1156,10 @@ func (o *Oracle) checkAcceptList(params oracletypes.Params) {
 //nolint:funlen //No need to split this function
 func (o *Oracle) executeTick(ctx context.Context) error {
 	o.logger.Debug().Msg("executing oracle tick")
+	o.voteAction = VoteActionNone
+	o.latency.StartTick()
 
-	blockHeight, err := o.client.ChainHeight.GetChainHeight()
+	blockHeight, err := o.client.GetHeight()
 	if err != nil {
 		return err
 	}
@@ -488,97 +1177,233 @@ func (o *Oracle) executeTick(ctx context.Context) error {
 		return err
 	}
 
-	// Get oracle vote period, next block height, current vote period, and index
-	// in the vote period.
+	if age := o.heightAge(); age > o.maxHeightAge {
+		// the node stalled, votes broadcasted to it would likely not land
+		o.voteAction = VoteActionStaleHeight
+		o.handleStaleHeight(ctx, blockHeight, age)
+		return nil
+	}
+
+	o.reportRole()
+	if o.IsPaused() {
+		o.voteAction = VoteActionPaused
+		o.logger.Debug().Msg("oracle voting is paused; skipping vote")
+		if o.features.Enabled(features.StandbyObservation) {
+			o.observeLeader(ctx)
+		}
+		return nil
+	}
+
 	oracleVotePeriod := int64(oracleParams.VotePeriod)
 	nextBlockHeight := blockHeight + 1
-	currentVotePeriod := math.Floor(float64(nextBlockHeight) / float64(oracleVotePeriod))
-	indexInVotePeriod := nextBlockHeight % oracleVotePeriod
 
-	ok := o.checkVotingPeriod(currentVotePeriod, oracleVotePeriod, indexInVotePeriod)
-	if !ok {
-		// either we are past the voting period or skipping this voting period
+	tick := o.votes.Next(nextBlockHeight, oracleVotePeriod)
+	if !tick.Broadcast {
+		o.voteAction = VoteActionSkipped
+		o.logVoteSkipped(tick, oracleVotePeriod)
 		return nil
 	}
 
-	salt, err := generateSalt(32)
-	if err != nil {
-		return err
+	// the broadcast times out before it could land in the following period
+	timeoutHeight := tick.WindowEnd - o.timeoutMargin
+	if timeoutHeight < nextBlockHeight {
+		o.voteAction = VoteActionSkipped
+		o.logger.Info().
+			Int64("next_block_height", nextBlockHeight).
+			Int64("timeout_height", timeoutHeight).
+			Str("state", string(tick.State)).
+			Msg("vote window closes within the timeout margin; skipping")
+		return nil
 	}
 
-	valAddr, err := sdk.ValAddressFromBech32(o.client.ValidatorAddrString)
+	valAddr, err := sdk.ValAddressFromBech32(o.client.ValidatorAddr())
 	if err != nil {
 		return err
 	}
 
-	exchangeRatesStr, err := generateExchangeRatesString(o.prices)
-	if err != nil {
-		return fmt.Errorf("failed to generate exchange rate string %w", err)
-	}
+	if tick.State == VoteStateIdle {
+		if o.skipForMissingCriticalAssets(tick) {
+			o.voteAction = VoteActionCriticalMissing
+			return nil
+		}
 
-	hash := oracletypes.GetAggregateVoteHash(salt, exchangeRatesStr, valAddr)
-	preVoteMsg := &oracletypes.MsgAggregateExchangeRatePrevote{
-		Hash:      hash.String(), // hash of prices from the oracle
-		Feeder:    o.client.OracleAddrString,
-		Validator: valAddr.String(),
-	}
+		salt, err := generateSalt(32)
+		if err != nil {
+			return err
+		}
+
+		exchangeRatesStr, err := o.prevoteExchangeRates(tick)
+		if err != nil {
+			return err
+		}
+
+		hash := oracletypes.GetAggregateVoteHash(salt, exchangeRatesStr, valAddr)
+		preVoteMsg := &oracletypes.MsgAggregateExchangeRatePrevote{
+			Hash:      hash.String(), // hash of prices from the oracle
+			Feeder:    o.client.FeederAddr(),
+			Validator: valAddr.String(),
+		}
+		o.latency.MarkHash(true)
 
-	if o.previousPrevote == nil {
-		// This timeout could be as small as oracleVotePeriod-indexInVotePeriod,
-		// but we give it some extra time just in case.
 		o.logger.Info().
 			Str("hash", hash.String()).
 			Str("validator", preVoteMsg.Validator).
 			Str("feeder", preVoteMsg.Feeder).
 			Msg("broadcasting pre-vote")
-		if err := o.client.BroadcastTx(ctx,
-			nextBlockHeight,
-			oracleVotePeriod*2,
-			preVoteMsg); err != nil { //nolint:gomnd // const
+		if err := o.client.BroadcastPrevote(ctx, nextBlockHeight, timeoutHeight, preVoteMsg); err != nil {
+			o.voteAction = VoteActionPrevoteFailed
+			o.counters.IncrPrevoteFailures()
 			return err
 		}
+		o.voteAction = VoteActionPrevote
+		o.counters.IncrPrevotes()
 
-		currentHeight, err := o.client.ChainHeight.GetChainHeight()
+		currentHeight, err := o.client.GetHeight()
 		if err != nil {
 			return err
 		}
 
-		o.previousVotePeriod = math.Floor(float64(currentHeight) / float64(oracleVotePeriod))
-		o.previousPrevote = &PreviousPrevote{
+		o.votes.PrevoteSubmitted(PreviousPrevote{
 			Salt:              salt,
 			ExchangeRates:     exchangeRatesStr,
 			SubmitBlockHeight: currentHeight,
-		}
+		}, currentHeight, oracleVotePeriod)
+		o.saveState()
 	} else {
 		// otherwise, we're in the next voting period and thus we vote
+		prevote := o.votes.Prevote()
 		voteMsg := &oracletypes.MsgAggregateExchangeRateVote{
-			Salt:          o.previousPrevote.Salt,
-			ExchangeRates: o.previousPrevote.ExchangeRates,
-			Feeder:        o.client.OracleAddrString,
+			Salt:          prevote.Salt,
+			ExchangeRates: prevote.ExchangeRates,
+			Feeder:        o.client.FeederAddr(),
 			Validator:     valAddr.String(),
 		}
+		o.latency.MarkHash(false)
 
 		o.logger.Info().
 			Str("exchange_rates", voteMsg.ExchangeRates).
 			Str("validator", voteMsg.Validator).
 			Str("feeder", voteMsg.Feeder).
 			Msg("broadcasting vote")
-		if err := o.client.BroadcastTx(
+		if err := o.client.BroadcastVote(
 			ctx,
 			nextBlockHeight,
-			oracleVotePeriod-indexInVotePeriod,
+			timeoutHeight,
 			voteMsg,
 		); err != nil {
+			o.voteAction = VoteActionVoteFailed
+			o.counters.IncrVoteFailures()
+			o.slo.Record(tick.VotePeriod, false)
 			return err
 		}
+		o.voteAction = VoteActionVote
+		o.counters.IncrVotes()
+		o.slo.Record(tick.VotePeriod, true)
 
-		o.previousPrevote = nil
-		o.previousVotePeriod = 0
+		o.votes.Reset()
+		o.saveState()
 	}
 
 	return nil
 }
 
+// reportMissingPrice alerts that no price could be computed for the expected
+// asset, at error level if the asset is critical.
+func (o *Oracle) reportMissingPrice(base string) {
+	priority, event := config.PriorityBestEffort, o.logger.Warn()
+	if _, ok := o.critical[base]; ok {
+		priority, event = config.PriorityCritical, o.logger.Error()
+	}
+
+	event.Str("asset", base).Str("priority", priority).Msg("unable to report price for expected asset")
+	telemetry.IncrCounterWithLabels(counterKeyMissingPrices, 1, []metrics.Label{
+		telemetry.NewLabel("asset", base),
+		telemetry.NewLabel("priority", priority),
+	})
+}
+
+// missingCriticalAssets returns the sorted critical assets without a price.
+func (o *Oracle) missingCriticalAssets() []string {
+	o.pricesMutex.RLock()
+	defer o.pricesMutex.RUnlock()
+
+	var missing []string
+	for base := range o.critical {
+		if _, ok := o.prices[base]; !ok {
+			missing = append(missing, base)
+		}
+	}
+	sort.Strings(missing)
+
+	return missing
+}
+
+// skipForMissingCriticalAssets applies the critical asset policy when critical
+// assets are missing a price and returns whether the pre-vote of the vote
+// period of tick must be skipped. Best-effort assets without a price are
+// simply left out of the pre-vote.
+func (o *Oracle) skipForMissingCriticalAssets(tick VoteTick) bool {
+	missing := o.missingCriticalAssets()
+	if len(missing) == 0 {
+		return false
+	}
+
+	if o.criticalAssetPolicy == config.CriticalAssetAbstain {
+		o.logger.Error().
+			Strs("assets", missing).
+			Uint64("vote_period", tick.VotePeriod).
+			Msg("critical assets are missing a price; abstaining from them in the pre-vote")
+		return false
+	}
+
+	o.logger.Error().
+		Strs("assets", missing).
+		Uint64("vote_period", tick.VotePeriod).
+		Msg("critical assets are missing a price; skipping pre-vote")
+	return true
+}
+
+// prevoteExchangeRates returns the exchange rates string to pre-vote in the
+// vote period of tick. On the unchanged path, i.e. when no price left after
+// the anomaly detection moved by more than the unchanged epsilon since the
+// last pre-vote, the exchange rates of the last pre-vote are returned as is,
+// skipping the scaling and the formatting of the prices.
+func (o *Oracle) prevoteExchangeRates(tick VoteTick) (string, error) {
+	prices := o.prices
+	if o.anomalies != nil {
+		// held assets are left out of the pre-vote, and thus of the vote
+		prices, _ = o.anomalies.Filter(tick.VotePeriod, prices, o.providerPricesSnapshot())
+	}
+
+	if o.lastVote != nil && pricesUnchanged(o.lastVote.Prices, prices, o.unchangedEpsilon) {
+		o.logger.Info().
+			Uint64("vote_period", tick.VotePeriod).
+			Msg("prices unchanged since the last pre-vote; pre-voting its exchange rates again")
+		telemetry.IncrCounter(1, "prevotes", "unchanged")
+		return o.lastVote.ExchangeRates, nil
+	}
+
+	exchangeRates := scaleExchangeRates(prices, o.GetAssets(), o.priceExponents)
+	exchangeRatesStr, err := generateExchangeRatesString(exchangeRates, o.precisions)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate exchange rate string %w", err)
+	}
+	if len(exchangeRatesStr) > maxExchangeRatesLength {
+		return "", fmt.Errorf(
+			"exchange rates string of %d characters exceeds the %d accepted by x/oracle; "+
+				"reduce the precision of the voted prices",
+			len(exchangeRatesStr),
+			maxExchangeRatesLength,
+		)
+	}
+
+	if !o.unchangedEpsilon.IsNil() {
+		o.lastVote = &LastVote{Prices: prices, ExchangeRates: exchangeRatesStr}
+	}
+
+	return exchangeRatesStr, nil
+}
+
 // SetProviderTickerPricesAndCandles flattens and collects prices for
 // candles and tickers based on the base currency per provider.
 // Returns true if at least one of price or candle exists.
@@ -617,7 +1442,7 @@ func (o *Oracle) getParamCache(ctx context.Context, currentBlockHeigh int64) (or
 		return *o.paramCache.params, nil
 	}
 
-	params, err := o.getParams(ctx)
+	params, err := o.client.GetParams(ctx)
 	if err != nil {
 		return oracletypes.Params{}, err
 	}
@@ -627,61 +1452,26 @@ func (o *Oracle) getParamCache(ctx context.Context, currentBlockHeigh int64) (or
 	return params, nil
 }
 
-// getParams returns the current on-chain parameters of the x/oracle module.
-func (o *Oracle) getParams(ctx context.Context) (oracletypes.Params, error) {
-	grpcConn, err := grpc.Dial(
-		o.client.GRPCEndpoint,
-		// the Cosmos SDK doesn't support any transport security mechanism
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithContextDialer(dialerFunc),
-	)
-	if err != nil {
-		return oracletypes.Params{}, fmt.Errorf("failed to dial Cosmos gRPC service: %w", err)
-	}
-
-	defer grpcConn.Close()
-	queryClient := oracletypes.NewQueryClient(grpcConn)
-
-	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
-	defer cancel()
-
-	queryResponse, err := queryClient.Params(ctx, &oracletypes.QueryParamsRequest{})
-	if err != nil {
-		return oracletypes.Params{}, fmt.Errorf("failed to get x/oracle params: %w", err)
-	}
-
-	return queryResponse.Params, nil
-}
-
-func (o *Oracle) checkVotingPeriod(currentVotePeriod float64, oracleVotePeriod, indexInVotePeriod int64) bool {
-	// Skip until new voting period. Specifically, skip when:
-	// index [0, oracleVotePeriod - 1] > oracleVotePeriod - 2 OR index is 0
-	if (o.previousVotePeriod != 0 && currentVotePeriod == o.previousVotePeriod) ||
-		oracleVotePeriod-indexInVotePeriod < 2 {
-		o.logger.Info().
-			Int64("vote_period", oracleVotePeriod).
-			Float64("previous_vote_period", o.previousVotePeriod).
-			Float64("current_vote_period", currentVotePeriod).
-			Msg("skipping until next voting period")
-
-		return false
-	}
-
-	// If we're past the voting period we needed to hit, reset and submit another
-	// prevote.
-	if o.previousVotePeriod != 0 && currentVotePeriod-o.previousVotePeriod != 1 {
+// logVoteSkipped logs why tick broadcasts nothing and records the vote missed,
+// if any.
+func (o *Oracle) logVoteSkipped(tick VoteTick, oracleVotePeriod int64) {
+	if tick.State == VoteStateMissed {
 		o.logger.Info().
 			Int64("vote_period", oracleVotePeriod).
-			Float64("previous_vote_period", o.previousVotePeriod).
-			Float64("current_vote_period", currentVotePeriod).
+			Uint64("missed_vote_period", tick.MissedVotePeriod).
+			Uint64("current_vote_period", tick.VotePeriod).
 			Msg("missing vote during voting period")
-
-		o.previousVotePeriod = 0
-		o.previousPrevote = nil
-		return false
+		o.counters.IncrMisses()
+		o.slo.Record(tick.MissedVotePeriod, false)
+		o.saveState()
+		return
 	}
 
-	return true
+	o.logger.Info().
+		Int64("vote_period", oracleVotePeriod).
+		Str("vote_state", string(tick.State)).
+		Uint64("current_vote_period", tick.VotePeriod).
+		Msg("skipping until next voting period")
 }
 
 // generateSalt generates a random salt, size length/2,  as a HEX encoded string.
@@ -701,8 +1491,8 @@ func generateSalt(length int) (string, error) {
 }
 
 // generateExchangeRatesString generates a canonical string representation of
-// the aggregated exchange rates.
-func generateExchangeRatesString(prices map[string]sdk.Dec) (string, error) {
+// the aggregated exchange rates, with the precision of their asset if any.
+func generateExchangeRatesString(prices map[string]sdk.Dec, precisions map[string]PricePrecision) (string, error) {
 	if len(prices) == 0 {
 		return "", errNoPriceAvailable
 	}
@@ -712,7 +1502,7 @@ func generateExchangeRatesString(prices map[string]sdk.Dec) (string, error) {
 
 	// aggregate exchange rates as "<base>:<price>"
 	for base, avgPrice := range prices {
-		exchangeRates[i] = fmt.Sprintf("%s:%s", base, avgPrice.String())
+		exchangeRates[i] = fmt.Sprintf("%s:%s", base, formatExchangeRate(base, avgPrice, precisions))
 		i++
 	}
 
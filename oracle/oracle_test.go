@@ -1,9 +1,13 @@
 package oracle
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/armon/go-metrics"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/require"
@@ -13,6 +17,8 @@ import (
 	"github.com/persistenceOne/oracle-feeder/oracle/client"
 	"github.com/persistenceOne/oracle-feeder/oracle/provider"
 	"github.com/persistenceOne/oracle-feeder/oracle/types"
+
+	oracletypes "github.com/persistenceOne/persistence-sdk/v2/x/oracle/types"
 )
 
 type OracleTestSuite struct {
@@ -25,7 +31,7 @@ type OracleTestSuite struct {
 func (ots *OracleTestSuite) SetupSuite() {
 	ots.oracle = New(
 		zerolog.Nop(),
-		client.OracleClient{},
+		&client.MockOracleClient{},
 		[]config.CurrencyPair{
 			{
 				Base:      "ATOM",
@@ -57,6 +63,7 @@ func (ots *OracleTestSuite) TestStop() {
 		5*time.Second,
 		time.Second,
 	)
+	ots.Require().True(ots.oracle.IsStopping())
 }
 
 func (ots *OracleTestSuite) TestGetLastPriceSyncTimestamp() {
@@ -89,7 +96,7 @@ func TestGenerateExchangeRatesString(t *testing.T) {
 			input: map[string]sdk.Dec{
 				"ATOM": sdk.MustNewDecFromStr("3.72"),
 			},
-			expected: "ATOM:3.720000000000000000",
+			expected: "ATOM:3.72",
 		},
 		"multi denom": {
 			input: map[string]sdk.Dec{
@@ -97,7 +104,7 @@ func TestGenerateExchangeRatesString(t *testing.T) {
 				"ATOM":    sdk.MustNewDecFromStr("40.13"),
 				"OSMO":    sdk.MustNewDecFromStr("8.69"),
 			},
-			expected: "ATOM:40.130000000000000000,AXLUSDC:3.720000000000000000,OSMO:8.690000000000000000",
+			expected: "ATOM:40.13,AXLUSDC:3.72,OSMO:8.69",
 		},
 	}
 
@@ -105,13 +112,53 @@ func TestGenerateExchangeRatesString(t *testing.T) {
 		tc := tc
 
 		t.Run(name, func(t *testing.T) {
-			out, err := generateExchangeRatesString(tc.input)
+			out, err := generateExchangeRatesString(tc.input, nil)
 			require.Equal(t, err, tc.err)
 			require.Equal(t, tc.expected, out)
 		})
 	}
 }
 
+func TestPrevoteExchangeRatesLength(t *testing.T) {
+	o, _ := newVotingOracle(t, 20)
+	tick := VoteTick{State: VoteStateIdle, Broadcast: true, VotePeriod: 3}
+
+	// 150 assets take 4649 characters with 18 decimals, over the limit of
+	// x/oracle, and 2099 once their trailing zeros are stripped
+	o.prices = make(map[string]sdk.Dec, 150)
+	for i := 0; i < 150; i++ {
+		o.prices[fmt.Sprintf("ASSET%03d", i)] = sdk.MustNewDecFromStr("12.5")
+	}
+	rates, err := o.prevoteExchangeRates(tick)
+	require.NoError(t, err)
+	require.Len(t, rates, 2099)
+
+	salt, err := generateSalt(32)
+	require.NoError(t, err)
+	msg := oracletypes.NewMsgAggregateExchangeRateVote(
+		salt,
+		rates,
+		sdk.AccAddress([]byte("feeder______________")),
+		sdk.ValAddress([]byte("validator___________")),
+	)
+	require.NoError(t, msg.ValidateBasic())
+
+	tuples, err := oracletypes.ParseExchangeRateTuples(rates)
+	require.NoError(t, err)
+	require.Len(t, tuples, 150)
+	for _, tuple := range tuples {
+		require.Equal(t, o.prices[tuple.Denom], tuple.ExchangeRate)
+	}
+
+	// prices that don't compact are refused rather than pre-voted and
+	// rejected by the chain
+	for base := range o.prices {
+		o.prices[base] = sdk.MustNewDecFromStr("12.123456789012345678")
+	}
+	_, err = o.prevoteExchangeRates(tick)
+	require.ErrorContains(t, err, "exceeds the 4096 accepted by x/oracle")
+}
+
 func TestSuccessSetProviderTickerPricesAndCandles(t *testing.T) {
 	providerPrices := make(provider.AggregatedProviderPrices, 1)
 	providerCandles := make(provider.AggregatedProviderCandles, 1)
@@ -201,6 +248,14 @@ func (ots *OracleTestSuite) TestSuccessGetComputedPricesCandles() {
 
 	require.NoError(ots.T(), err, "It should successfully get computed candle prices")
 	require.Equal(ots.T(), prices[pair.Base], atomPrice)
+	require.Equal(ots.T(), map[string]AssetAggregation{
+		pair.Base: {
+			Method:    AggregationTVWAP,
+			Providers: []provider.Name{provider.Binance},
+			Decisions: []DeviationDecision{uncheckedDecision(provider.Binance, pair.Base, atomPrice)},
+		},
+	}, ots.oracle.GetAggregations())
+	require.NotZero(ots.T(), ots.oracle.GetAggregationCounts()[pair.Base][AggregationTVWAP])
 }
 
 func (ots *OracleTestSuite) TestSuccessGetComputedPricesTickers() {
@@ -233,4 +288,385 @@ func (ots *OracleTestSuite) TestSuccessGetComputedPricesTickers() {
 
 	require.NoError(ots.T(), err, "It should successfully get computed ticker prices")
 	require.Equal(ots.T(), prices[pair.Base], atomPrice)
+	require.Equal(ots.T(), map[string]AssetAggregation{
+		pair.Base: {
+			Method:    AggregationVWAP,
+			Providers: []provider.Name{provider.Binance},
+			Decisions: []DeviationDecision{uncheckedDecision(provider.Binance, pair.Base, atomPrice)},
+		},
+	}, ots.oracle.GetAggregations())
+}
+
+func (ots *OracleTestSuite) TestSuccessGetComputedPricesMixed() {
+	atomPair := types.CurrencyPair{Base: "ATOM", Quote: "USD"}
+	xprtPair := types.CurrencyPair{Base: "XPRT", Quote: "USD"}
+
+	atomPrice := sdk.MustNewDecFromStr("29.93")
+	xprtPrice := sdk.MustNewDecFromStr("1.13")
+	volume := sdk.MustNewDecFromStr("894123.00")
+
+	// only ATOM has candles, XPRT falls back to its tickers
+	providerCandles := provider.AggregatedProviderCandles{
+		provider.Binance: {
+			atomPair.Base: {{Price: atomPrice, Volume: volume, TimeStamp: provider.PastUnixTime(time.Minute)}},
+		},
+	}
+	providerPrices := provider.AggregatedProviderPrices{
+		provider.Binance: {
+			atomPair.Base: {Price: sdk.MustNewDecFromStr("30.5"), Volume: volume},
+			xprtPair.Base: {Price: xprtPrice, Volume: volume},
+		},
+	}
+
+	prices, err := ots.oracle.GetComputedPrices(
+		providerCandles,
+		providerPrices,
+		map[provider.Name][]types.CurrencyPair{provider.Binance: {atomPair, xprtPair}},
+		make(map[string]sdk.Dec),
+	)
+
+	ots.Require().NoError(err)
+	ots.Require().Equal(map[string]sdk.Dec{atomPair.Base: atomPrice, xprtPair.Base: xprtPrice}, prices)
+	ots.Require().Equal(map[string]AssetAggregation{
+		atomPair.Base: {
+			Method:    AggregationTVWAP,
+			Providers: []provider.Name{provider.Binance},
+			Decisions: []DeviationDecision{uncheckedDecision(provider.Binance, atomPair.Base, atomPrice)},
+		},
+		xprtPair.Base: {
+			Method:    AggregationVWAP,
+			Providers: []provider.Name{provider.Binance},
+			Decisions: []DeviationDecision{uncheckedDecision(provider.Binance, xprtPair.Base, xprtPrice)},
+		},
+	}, ots.oracle.GetAggregations())
+}
+
+// uncheckedDecision returns the decision for the price of a single provider,
+// which is too few to compute a standard deviation.
+func uncheckedDecision(providerName provider.Name, base string, price sdk.Dec) DeviationDecision {
+	return DeviationDecision{
+		Provider: providerName,
+		Base:     base,
+		Price:    price,
+		Mean:     sdk.ZeroDec(),
+		StdDev:   sdk.ZeroDec(),
+		Margin:   sdk.ZeroDec(),
+		Outcome:  DeviationUnchecked,
+	}
+}
+
+func (ots *OracleTestSuite) TestVoterStatus() {
+	ots.Require().Equal(RoleActive, ots.oracle.GetVoterStatus().Role)
+
+	ots.oracle.Pause()
+	defer ots.oracle.Resume()
+
+	status := ots.oracle.GetVoterStatus()
+	ots.Require().Equal(RoleStandby, status.Role)
+	ots.Require().True(status.LeaderActivity.ObservedAt.IsZero())
+}
+
+func (ots *OracleTestSuite) TestSubscribePrices() {
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+	metricsConfig := metrics.DefaultConfig("test")
+	metricsConfig.EnableHostname = false
+	metricsConfig.EnableRuntimeMetrics = false
+	_, err := metrics.NewGlobal(metricsConfig, sink)
+	ots.Require().NoError(err)
+
+	updates, cancel := ots.oracle.SubscribePrices()
+
+	ots.oracle.publishPrices()
+	// the second update is dropped since the first one was not consumed yet
+	ots.oracle.publishPrices()
+
+	ots.Require().Len(updates, 1)
+	data := sink.Data()
+	ots.Require().Equal(float32(1), data[0].Gauges["test.price.subscribers"].Value)
+	ots.Require().Equal(1, data[0].Counters["test.price.subscribers.dropped"].Count)
+	<-updates
+
+	cancel()
+	// cancelling twice is a no-op
+	cancel()
+
+	ots.oracle.publishPrices()
+	ots.Require().Len(updates, 0)
+}
+
+type statusChecker bool
+
+func (sc statusChecker) UnderMaintenance(context.Context) (bool, error) {
+	return bool(sc), nil
+}
+
+func (ots *OracleTestSuite) TestProviderMaintenance() {
+	ots.Require().False(ots.oracle.isUnderMaintenance(provider.Kraken))
+
+	ots.oracle.updateProviderStatus(context.Background(), provider.Kraken, statusChecker(true))
+	ots.Require().True(ots.oracle.isUnderMaintenance(provider.Kraken))
+
+	ots.oracle.updateProviderStatus(context.Background(), provider.Kraken, statusChecker(false))
+	ots.Require().False(ots.oracle.isUnderMaintenance(provider.Kraken))
+}
+
+// staticProvider defines a provider returning the same ticker prices on every
+// call.
+type staticProvider map[string]types.TickerPrice
+
+func (sp staticProvider) GetTickerPrices(
+	_ context.Context,
+	_ ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
+	return sp, nil
+}
+
+func (sp staticProvider) GetCandlePrices(
+	_ context.Context,
+	_ ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
+	return map[string][]types.CandlePrice{}, nil
+}
+
+func (sp staticProvider) SubscribeCurrencyPairs(context.Context, ...types.CurrencyPair) error {
+	return nil
+}
+
+// lifecycleProvider defines a static provider recording whether it was
+// stopped.
+type lifecycleProvider struct {
+	staticProvider
+	stopped bool
+}
+
+func (lp *lifecycleProvider) Start() error {
+	return nil
+}
+
+func (lp *lifecycleProvider) Stop() {
+	lp.stopped = true
+}
+
+func TestStopProviders(t *testing.T) {
+	o := New(zerolog.Nop(), nil, nil, time.Second, nil, nil)
+	started := &lifecycleProvider{}
+	o.priceProviders[provider.Kraken] = started
+	o.priceProviders[provider.Binance] = staticProvider{}
+
+	o.stopProviders()
+	require.True(t, started.stopped)
+	require.Empty(t, o.priceProviders)
+}
+
+const testVotePeriod = 10
+
+// newVotingOracle returns an oracle pricing ATOM from a static provider and
+// voting through a mocked client at the given block height.
+func newVotingOracle(t *testing.T, height int64) (*Oracle, *client.MockOracleClient) {
+	t.Helper()
+
+	mc := client.NewMockOracleClient(
+		sdk.AccAddress([]byte("feeder______________")).String(),
+		sdk.ValAddress([]byte("validator___________")).String(),
+		height,
+		oracletypes.Params{VotePeriod: testVotePeriod},
+	)
+	pair := config.CurrencyPair{Base: "ATOM", Quote: "USD", Providers: []provider.Name{provider.Binance}}
+	o := New(zerolog.Nop(), mc, []config.CurrencyPair{pair}, time.Second, nil, nil)
+	o.priceProviders[provider.Binance] = staticProvider{
+		"ATOMUSD": {Price: sdk.MustNewDecFromStr("29.93"), Volume: sdk.MustNewDecFromStr("894123")},
+	}
+
+	return o, mc
+}
+
+func TestExecuteTickPrevoteThenVote(t *testing.T) {
+	o, mc := newVotingOracle(t, 20)
+
+	require.NoError(t, o.executeTick(context.Background()))
+	require.Equal(t, VoteActionPrevote, o.voteAction)
+	require.Len(t, mc.Prevotes(), 1)
+	require.Equal(t, mc.FeederAddr(), mc.Prevotes()[0].Feeder)
+	require.NotNil(t, o.votes.Prevote())
+
+	// the pre-vote period is not over yet
+	mc.SetHeight(25)
+	require.NoError(t, o.executeTick(context.Background()))
+	require.Equal(t, VoteActionSkipped, o.voteAction)
+	require.Empty(t, mc.Votes())
+
+	mc.SetHeight(30)
+	require.NoError(t, o.executeTick(context.Background()))
+	require.Equal(t, VoteActionVote, o.voteAction)
+	require.Len(t, mc.Votes(), 1)
+
+	vote := mc.Votes()[0]
+	require.Equal(t, "ATOM:29.93", vote.ExchangeRates)
+	require.Equal(t, mc.ValidatorAddr(), vote.Validator)
+	valAddr, err := sdk.ValAddressFromBech32(vote.Validator)
+	require.NoError(t, err)
+	require.Equal(t, oracletypes.GetAggregateVoteHash(vote.Salt, vote.ExchangeRates, valAddr).String(), mc.Prevotes()[0].Hash)
+	require.Nil(t, o.votes.Prevote())
+	require.Equal(t, uint64(1), o.counters.Values().Votes)
+
+	// both time out at the end of their vote period
+	require.Equal(t, []int64{29, 39}, mc.TimeoutHeights())
+}
+
+func TestProviderSnapshots(t *testing.T) {
+	o, _ := newVotingOracle(t, 20)
+
+	require.NoError(t, o.executeTick(context.Background()))
+	snapshots := o.GetProviderSnapshots()
+	require.Len(t, snapshots, 1)
+	require.Equal(t, sdk.MustNewDecFromStr("29.93"), snapshots[provider.Binance].Tickers["ATOMUSD"].Price)
+
+	// with a hub, the providers receive their prices from it
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hub := provider.NewHubClient(ctx, zerolog.Nop(), "http://127.0.0.1:0")
+	o = New(zerolog.Nop(), nil, nil, time.Second, nil, nil, WithHub(hub))
+	priceProvider, err := o.getOrSetProvider(context.Background(), provider.Kraken)
+	require.NoError(t, err)
+	require.IsType(t, &provider.HubProvider{}, priceProvider)
+}
+
+func TestPriceTimestamps(t *testing.T) {
+	o, _ := newVotingOracle(t, 20)
+
+	require.NoError(t, o.setPrices(context.Background()))
+	timestamp := o.GetPriceTimestamps()["ATOM"]
+	require.False(t, timestamp.IsZero())
+
+	// an asset no longer priced keeps the time it was last priced at
+	o.priceProviders[provider.Binance] = staticProvider{}
+	_ = o.setPrices(context.Background())
+	require.NotContains(t, o.GetPrices(), "ATOM")
+	require.Equal(t, timestamp, o.GetPriceTimestamps()["ATOM"])
+}
+
+func TestExecuteTickTimeoutMargin(t *testing.T) {
+	o, mc := newVotingOracle(t, 20)
+	WithTimeoutMargin(3)(o)
+
+	require.NoError(t, o.executeTick(context.Background()))
+	require.Equal(t, VoteActionPrevote, o.voteAction)
+
+	mc.SetHeight(33)
+	require.NoError(t, o.executeTick(context.Background()))
+	require.Equal(t, VoteActionVote, o.voteAction)
+	require.Equal(t, []int64{26, 36}, mc.TimeoutHeights())
+
+	// the pre-vote would time out before the next block
+	mc.SetHeight(37)
+	require.NoError(t, o.executeTick(context.Background()))
+	require.Equal(t, VoteActionSkipped, o.voteAction)
+	require.Len(t, mc.Prevotes(), 1)
+}
+
+func TestExecuteTickMissingCriticalAsset(t *testing.T) {
+	o, mc := newVotingOracle(t, 20)
+	// STKATOM is critical but not priced by any provider
+	o.critical["STKATOM"] = struct{}{}
+
+	require.NoError(t, o.executeTick(context.Background()))
+	require.Equal(t, VoteActionCriticalMissing, o.voteAction)
+	require.Empty(t, mc.Prevotes())
+
+	WithCriticalAssetPolicy(config.CriticalAssetAbstain)(o)
+	require.NoError(t, o.executeTick(context.Background()))
+	require.Equal(t, VoteActionPrevote, o.voteAction)
+	require.Len(t, mc.Prevotes(), 1)
+
+	mc.SetHeight(30)
+	require.NoError(t, o.executeTick(context.Background()))
+	require.Equal(t, "ATOM:29.93", mc.Votes()[0].ExchangeRates)
+}
+
+func TestExecuteTickStaleHeight(t *testing.T) {
+	o, mc := newVotingOracle(t, 20)
+	WithMaxHeightAge(15 * time.Second)(o)
+
+	mc.SetHeightUpdatedAt(time.Now().Add(-20 * time.Second))
+	require.NoError(t, o.executeTick(context.Background()))
+	require.Equal(t, VoteActionStaleHeight, o.voteAction)
+	require.Empty(t, mc.Prevotes())
+	require.Equal(t, 1, mc.Failovers())
+
+	// the node failed over to is given time to catch up
+	require.NoError(t, o.executeTick(context.Background()))
+	require.Equal(t, VoteActionPrevote, o.voteAction)
+	require.Equal(t, 1, mc.Failovers())
+}
+
+func TestExecuteTickMissedVotePeriod(t *testing.T) {
+	o, mc := newVotingOracle(t, 20)
+	require.NoError(t, o.executeTick(context.Background()))
+
+	// the vote period following the pre-vote was missed
+	mc.SetHeight(45)
+	require.NoError(t, o.executeTick(context.Background()))
+	require.Equal(t, VoteActionSkipped, o.voteAction)
+	require.Nil(t, o.votes.Prevote())
+	require.Empty(t, mc.Votes())
+	require.Equal(t, uint64(1), o.counters.Values().Misses)
+
+	require.NoError(t, o.executeTick(context.Background()))
+	require.Equal(t, VoteActionPrevote, o.voteAction)
+	require.Len(t, mc.Prevotes(), 2)
+}
+
+func TestExecuteTickBroadcastFailures(t *testing.T) {
+	o, mc := newVotingOracle(t, 20)
+	broadcastErr := errors.New("broadcasting tx timed out")
+
+	mc.SetBroadcastErrors(broadcastErr, nil)
+	require.ErrorIs(t, o.executeTick(context.Background()), broadcastErr)
+	require.Equal(t, VoteActionPrevoteFailed, o.voteAction)
+	require.Nil(t, o.votes.Prevote())
+
+	mc.SetBroadcastErrors(nil, broadcastErr)
+	require.NoError(t, o.executeTick(context.Background()))
+
+	mc.SetHeight(30)
+	require.ErrorIs(t, o.executeTick(context.Background()), broadcastErr)
+	require.Equal(t, VoteActionVoteFailed, o.voteAction)
+	require.Empty(t, mc.Votes())
+}
+
+func TestStartTicksOnNewBlocks(t *testing.T) {
+	o, mc := newVotingOracle(t, 20)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		require.NoError(t, o.Start(context.Background()))
+	}()
+	defer func() {
+		o.Stop()
+		<-done
+	}()
+
+	require.Eventually(t, func() bool { return len(mc.Prevotes()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	synced := o.GetLastPriceSyncTimestamp()
+
+	// no tick happens until the next block
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, synced, o.GetLastPriceSyncTimestamp())
+
+	mc.SetHeight(30)
+	require.Eventually(t, func() bool { return len(mc.Votes()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	require.True(t, o.GetLastPriceSyncTimestamp().After(synced))
+	require.Len(t, mc.Prevotes(), 1)
+}
+
+func TestExecuteTickPaused(t *testing.T) {
+	o, mc := newVotingOracle(t, 20)
+	mc.SetAggregatePrevote(oracletypes.AggregateExchangeRatePrevote{SubmitBlock: 19})
+
+	o.Pause()
+	require.NoError(t, o.executeTick(context.Background()))
+	require.Equal(t, VoteActionPaused, o.voteAction)
+	require.Empty(t, mc.Prevotes())
+	require.Equal(t, uint64(19), o.GetVoterStatus().LeaderActivity.SubmitBlock)
 }
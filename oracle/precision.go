@@ -0,0 +1,54 @@
+package oracle
+
+import (
+	"math/big"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// PricePrecision defines the number of decimals the price of an asset is
+// voted with and whether it is truncated, rather than rounded half away from
+// zero, to them.
+type PricePrecision struct {
+	Decimals uint32
+	Truncate bool
+}
+
+// format returns the price reduced to the precision, without trailing
+// decimals, e.g. "12.35" for 12.3456 with two decimals.
+func (p PricePrecision) format(price sdk.Dec) string {
+	if p.Decimals >= sdk.Precision {
+		return price.String()
+	}
+
+	dropped := int64(sdk.Precision - p.Decimals)
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(dropped), nil) //nolint:gomnd //decimal base
+
+	i := price.BigInt()
+	if !p.Truncate {
+		half := new(big.Int).Quo(unit, big.NewInt(2)) //nolint:gomnd //half of the unit
+		if i.Sign() < 0 {
+			i.Sub(i, half)
+		} else {
+			i.Add(i, half)
+		}
+	}
+	// Quo truncates towards zero
+	i.Quo(i, unit)
+
+	s := sdk.NewDecFromBigIntWithPrec(i, int64(p.Decimals)).String()
+	return strings.TrimSuffix(s[:len(s)-int(dropped)], ".")
+}
+
+// formatExchangeRate returns the price of base as voted, with the precision
+// of the asset if any, and otherwise with its 18 decimals stripped of their
+// trailing zeros, e.g. "34.84" rather than "34.840000000000000000", which
+// x/oracle parses back to the same price.
+func formatExchangeRate(base string, price sdk.Dec, precisions map[string]PricePrecision) string {
+	precision, ok := precisions[base]
+	if !ok {
+		return strings.TrimSuffix(strings.TrimRight(price.String(), "0"), ".")
+	}
+	return precision.format(price)
+}
@@ -0,0 +1,52 @@
+package oracle
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	oracletypes "github.com/persistenceOne/persistence-sdk/v2/x/oracle/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPricePrecisionFormat(t *testing.T) {
+	testCases := []struct {
+		name      string
+		price     string
+		precision PricePrecision
+		expected  string
+	}{
+		{"rounded up", "12.3456", PricePrecision{Decimals: 2}, "12.35"},
+		{"rounded down", "12.3449", PricePrecision{Decimals: 2}, "12.34"},
+		{"half rounded away from zero", "0.125", PricePrecision{Decimals: 2}, "0.13"},
+		{"truncated", "12.3499", PricePrecision{Decimals: 2, Truncate: true}, "12.34"},
+		{"zero decimals", "12.5", PricePrecision{Decimals: 0}, "13"},
+		{"zero decimals truncated", "0.99", PricePrecision{Decimals: 0, Truncate: true}, "0"},
+		{"shorter price padded", "1.5", PricePrecision{Decimals: 4}, "1.5000"},
+		{"full precision", "1.5", PricePrecision{Decimals: 18}, "1.500000000000000000"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.precision.format(sdk.MustNewDecFromStr(tc.price)))
+		})
+	}
+}
+
+func TestGenerateExchangeRatesStringPrecision(t *testing.T) {
+	prices := map[string]sdk.Dec{
+		"ATOM": sdk.MustNewDecFromStr("11.123456789"),
+		"XPRT": sdk.MustNewDecFromStr("0.5555"),
+	}
+
+	exchangeRates, err := generateExchangeRatesString(prices, map[string]PricePrecision{
+		"ATOM": {Decimals: 6},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "ATOM:11.123457,XPRT:0.5555", exchangeRates)
+
+	// the voted rates are parsed back to the rounded prices
+	tuples, err := oracletypes.ParseExchangeRateTuples(exchangeRates)
+	require.NoError(t, err)
+	require.Equal(t, sdk.MustNewDecFromStr("11.123457"), tuples[0].ExchangeRate)
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/rs/zerolog"
@@ -20,9 +22,22 @@ const (
 	binanceWSPath     = "/ws/persistencestream"
 	binanceRestHost   = "https://api1.binance.com"
 	binanceRestUSHost = "https://api.binance.us"
+
+	binanceSystemStatusPath = "/sapi/v1/system/status"
+	binanceStatusNormal     = 0
+
+	// binanceUserDataStreamPath creates and keeps alive the listen key of a
+	// user data stream, which requires an API key. Listen keys expire after
+	// 60 minutes unless kept alive, as recommended, every 30 minutes.
+	binanceUserDataStreamPath = "/api/v3/userDataStream"
+	binanceListenKeyKeepAlive = 30 * time.Minute
+	binanceAPIKeyHeader       = "X-MBX-APIKEY"
 )
 
-var _ Provider = (*BinanceProvider)(nil)
+var (
+	_ Provider      = (*BinanceProvider)(nil)
+	_ StatusChecker = (*BinanceProvider)(nil)
+)
 
 type (
 	// BinanceProvider defines an Oracle provider implemented by the Binance public
@@ -78,6 +93,23 @@ type (
 		ID     uint16 `json:"id"`
 	}
 
+	// BinanceSystemStatus defines the response structure of the Binance system
+	// status, where status 0 is normal and 1 system maintenance.
+	//
+	// REF: https://binance-docs.github.io/apidocs/spot/en/#system-status-system
+	BinanceSystemStatus struct {
+		Status int    `json:"status"`
+		Msg    string `json:"msg"`
+	}
+
+	// BinanceListenKey defines the response structure of the creation of a
+	// user data stream listen key.
+	//
+	// REF: https://binance-docs.github.io/apidocs/spot/en/#listen-key-spot
+	BinanceListenKey struct {
+		ListenKey string `json:"listenKey"`
+	}
+
 	// BinancePairSummary defines the response structure for a Binance pair
 	// summary.
 	BinancePairSummary struct {
@@ -98,12 +130,16 @@ func NewBinanceProvider(
 				Name:      Binance,
 				Rest:      binanceRestHost,
 				Websocket: binanceWSHost,
+				APIKey:    endpoints.APIKey,
+				APISecret: endpoints.APISecret,
 			}
 		} else {
 			endpoints = Endpoint{
 				Name:      BinanceUS,
 				Rest:      binanceRestUSHost,
 				Websocket: binanceUSWSHost,
+				APIKey:    endpoints.APIKey,
+				APISecret: endpoints.APISecret,
 			}
 		}
 	}
@@ -136,11 +172,27 @@ func NewBinanceProvider(
 		websocket.PingMessage,
 		binanceLogger,
 	)
-	go provider.wsc.Start()
 
 	return provider, nil
 }
 
+// Start connects to the websocket in the background and, with an API key,
+// keeps a user data stream alive, see Lifecycle.
+func (p *BinanceProvider) Start() error {
+	go p.wsc.Start()
+	if len(p.endpoints.APIKey) > 0 {
+		go p.keepUserDataStreamAlive(p.wsc.parentCtx)
+	}
+
+	return nil
+}
+
+// Stop closes the websocket and stops keeping the user data stream alive,
+// see Lifecycle.
+func (p *BinanceProvider) Stop() {
+	p.wsc.Stop()
+}
+
 func (p *BinanceProvider) getSubscriptionMsgs(cps ...types.CurrencyPair) []interface{} {
 	subscriptionMsgs := make([]interface{}, 0, len(p.subscribedPairs)*2)
 	for _, cp := range cps {
@@ -155,7 +207,7 @@ func (p *BinanceProvider) getSubscriptionMsgs(cps ...types.CurrencyPair) []inter
 
 // SubscribeCurrencyPairs sends the new subscription messages to the websocket
 // and adds them to the providers subscribedPairs array.
-func (p *BinanceProvider) SubscribeCurrencyPairs(cps ...types.CurrencyPair) error {
+func (p *BinanceProvider) SubscribeCurrencyPairs(_ context.Context, cps ...types.CurrencyPair) error {
 	p.mtx.Lock()
 	defer p.mtx.Unlock()
 
@@ -175,7 +227,10 @@ func (p *BinanceProvider) SubscribeCurrencyPairs(cps ...types.CurrencyPair) erro
 }
 
 // GetTickerPrices returns the tickerPrices based on the provided pairs.
-func (p *BinanceProvider) GetTickerPrices(pairs ...types.CurrencyPair) (map[string]types.TickerPrice, error) {
+func (p *BinanceProvider) GetTickerPrices(
+	_ context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
 	tickerPrices := make(map[string]types.TickerPrice, len(pairs))
 
 	for _, cp := range pairs {
@@ -191,7 +246,10 @@ func (p *BinanceProvider) GetTickerPrices(pairs ...types.CurrencyPair) (map[stri
 }
 
 // GetCandlePrices returns the candlePrices based on the provided pairs.
-func (p *BinanceProvider) GetCandlePrices(pairs ...types.CurrencyPair) (map[string][]types.CandlePrice, error) {
+func (p *BinanceProvider) GetCandlePrices(
+	_ context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
 	candlePrices := make(map[string][]types.CandlePrice, len(pairs))
 
 	for _, cp := range pairs {
@@ -336,3 +394,75 @@ func newBinanceSubscriptionMsg(params ...string) BinanceSubscriptionMsg {
 		ID:     1,
 	}
 }
+
+// UnderMaintenance implements StatusChecker.
+func (p *BinanceProvider) UnderMaintenance(ctx context.Context) (bool, error) {
+	var status BinanceSystemStatus
+	if err := getJSON(ctx, p.endpoints.Rest+binanceSystemStatusPath, p.authHeader(), &status); err != nil {
+		return false, err
+	}
+
+	return status.Status != binanceStatusNormal, nil
+}
+
+// authHeader returns the header authenticating REST requests with the
+// configured API key, which grants higher rate limits, or nil if there is none.
+func (p *BinanceProvider) authHeader() http.Header {
+	if len(p.endpoints.APIKey) == 0 {
+		return nil
+	}
+
+	header := http.Header{}
+	header.Set(binanceAPIKeyHeader, p.endpoints.APIKey)
+	return header
+}
+
+// keepUserDataStreamAlive creates a user data stream listen key and keeps it
+// alive until ctx is done. Binance accounts the connection limits of API key
+// holders with an active user data stream more generously.
+func (p *BinanceProvider) keepUserDataStreamAlive(ctx context.Context) {
+	listenKey, err := p.createListenKey(ctx)
+	if err != nil {
+		p.logger.Err(err).Msg("failed to create user data stream listen key")
+	}
+
+	ticker := time.NewTicker(binanceListenKeyKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			// a listen key which expired or was never created is recreated
+			if len(listenKey) == 0 || p.keepListenKeyAlive(ctx, listenKey) != nil {
+				if listenKey, err = p.createListenKey(ctx); err != nil {
+					p.logger.Err(err).Msg("failed to renew user data stream listen key")
+				}
+			}
+		}
+	}
+}
+
+// createListenKey creates a user data stream and returns its listen key.
+func (p *BinanceProvider) createListenKey(ctx context.Context) (string, error) {
+	var resp BinanceListenKey
+	err := requestJSON(ctx, http.MethodPost, p.endpoints.Rest+binanceUserDataStreamPath, p.authHeader(), &resp)
+	if err != nil {
+		return "", err
+	}
+	if len(resp.ListenKey) == 0 {
+		return "", fmt.Errorf("empty listen key")
+	}
+
+	return resp.ListenKey, nil
+}
+
+// keepListenKeyAlive extends the validity of the listen key by 60 minutes.
+func (p *BinanceProvider) keepListenKeyAlive(ctx context.Context, listenKey string) error {
+	reqURL := p.endpoints.Rest + binanceUserDataStreamPath + "?" + url.Values{"listenKey": {listenKey}}.Encode()
+
+	var resp struct{}
+	return requestJSON(ctx, http.MethodPut, reqURL, p.authHeader(), &resp)
+}
@@ -3,6 +3,8 @@ package provider
 import (
 	"context"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -38,7 +40,7 @@ func TestBinanceProvider_GetTickerPrices(t *testing.T) {
 
 		p.tickers = tickerMap
 
-		prices, err := p.GetTickerPrices(types.CurrencyPair{Base: "ATOM", Quote: "USD"})
+		prices, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "ATOM", Quote: "USD"})
 		require.NoError(t, err)
 		require.Len(t, prices, 1)
 		require.Equal(t, sdk.MustNewDecFromStr(lastPriceAtom), prices["ATOMUSD"].Price)
@@ -61,6 +63,7 @@ func TestBinanceProvider_GetTickerPrices(t *testing.T) {
 
 		p.tickers = tickerMap
 		prices, err := p.GetTickerPrices(
+			context.Background(),
 			types.CurrencyPair{Base: "ATOM", Quote: "USD"},
 			types.CurrencyPair{Base: "OSMO", Quote: "USD"},
 		)
@@ -73,7 +76,7 @@ func TestBinanceProvider_GetTickerPrices(t *testing.T) {
 	})
 
 	t.Run("invalid_request_invalid_ticker", func(t *testing.T) {
-		prices, err := p.GetTickerPrices(types.CurrencyPair{Base: "FOO", Quote: "BAR"})
+		prices, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "FOO", Quote: "BAR"})
 		require.EqualError(t, err, "binance failed to get ticker price for FOOBAR")
 		require.Nil(t, prices)
 	})
@@ -101,3 +104,56 @@ func TestBinanceProvider_getSubscriptionMsgs(t *testing.T) {
 	msg, _ = json.Marshal(subMsgs[1])
 	require.Equal(t, "{\"method\":\"SUBSCRIBE\",\"params\":[\"atomusd@kline_1m\"],\"id\":1}", string(msg))
 }
+
+func TestBinanceProvider_UnderMaintenance(t *testing.T) {
+	for response, maintenance := range map[string]bool{
+		`{"status":0,"msg":"normal"}`:             false,
+		`{"status":1,"msg":"system_maintenance"}`: true,
+	} {
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			require.Equal(t, binanceSystemStatusPath, req.URL.Path)
+			_, _ = rw.Write([]byte(response))
+		}))
+
+		p := &BinanceProvider{endpoints: Endpoint{Name: Binance, Rest: server.URL}}
+		got, err := p.UnderMaintenance(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, maintenance, got)
+
+		server.Close()
+	}
+}
+
+func TestBinanceProvider_UserDataStream(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		require.Equal(t, binanceUserDataStreamPath, req.URL.Path)
+		require.Equal(t, "key", req.Header.Get(binanceAPIKeyHeader))
+
+		methods = append(methods, req.Method)
+		switch req.Method {
+		case http.MethodPost:
+			_, _ = rw.Write([]byte(`{"listenKey":"listen"}`))
+		case http.MethodPut:
+			require.Equal(t, "listen", req.URL.Query().Get("listenKey"))
+			_, _ = rw.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	p := &BinanceProvider{endpoints: Endpoint{Name: Binance, Rest: server.URL, APIKey: "key"}}
+
+	listenKey, err := p.createListenKey(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "listen", listenKey)
+	require.NoError(t, p.keepListenKeyAlive(context.Background(), listenKey))
+	require.Equal(t, []string{http.MethodPost, http.MethodPut}, methods)
+}
+
+func TestBinanceProvider_AuthHeader(t *testing.T) {
+	p := &BinanceProvider{endpoints: Endpoint{Name: Binance}}
+	require.Nil(t, p.authHeader())
+
+	p.endpoints.APIKey = "key"
+	require.Equal(t, "key", p.authHeader().Get(binanceAPIKeyHeader))
+}
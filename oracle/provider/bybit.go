@@ -0,0 +1,391 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+const (
+	bybitWSHost          = "stream.bybit.com"
+	bybitWSPath          = "/v5/public/spot"
+	bybitRestHost        = "https://api.bybit.com"
+	bybitRestPath        = "/v5/market/instruments-info?category=spot"
+	bybitTickerTopic     = "tickers."
+	bybitCandleTopic     = "kline.1."
+	bybitOpSubscribe     = "subscribe"
+	bybitMaxSubscription = 10 // maximum number of topics per subscription message
+)
+
+var _ Provider = (*BybitProvider)(nil)
+
+type (
+	// BybitProvider defines an Oracle provider implemented by the Bybit public
+	// API.
+	//
+	// REF: https://bybit-exchange.github.io/docs/v5/websocket/public/ticker
+	// REF: https://bybit-exchange.github.io/docs/v5/websocket/public/kline
+	BybitProvider struct {
+		wsc             *WebsocketController
+		logger          zerolog.Logger
+		mtx             sync.RWMutex
+		endpoints       Endpoint
+		tickers         map[string]BybitTicker        // Symbol => BybitTicker
+		candles         map[string][]BybitCandle      // Symbol => BybitCandle
+		subscribedPairs map[string]types.CurrencyPair // Symbol => types.CurrencyPair
+	}
+
+	// BybitTicker defines the data of the "tickers" topic of a spot symbol.
+	BybitTicker struct {
+		Symbol    string `json:"symbol"`    // Symbol ex.: ATOMUSDT
+		LastPrice string `json:"lastPrice"` // Last price ex.: 11.05
+		Volume    string `json:"volume24h"` // 24h traded base asset volume ex.: 1000
+	}
+
+	// BybitCandle defines a candle of the "kline" topic of a spot symbol.
+	BybitCandle struct {
+		Symbol    string `json:"-"`      // Symbol ex.: ATOMUSDT, from the topic
+		Start     int64  `json:"start"`  // Start time in unix epoch ex.: 1645756140000
+		End       int64  `json:"end"`    // End time in unix epoch ex.: 1645756199999
+		Close     string `json:"close"`  // Price at close, or last price while open
+		Volume    string `json:"volume"` // Volume during period
+		Confirmed bool   `json:"confirm"`
+	}
+
+	// BybitTickerResponse defines a message pushed on the "tickers" topic.
+	BybitTickerResponse struct {
+		Topic string      `json:"topic"` // ex.: tickers.ATOMUSDT
+		Data  BybitTicker `json:"data"`
+	}
+
+	// BybitCandleResponse defines a message pushed on the "kline" topic.
+	BybitCandleResponse struct {
+		Topic string        `json:"topic"` // ex.: kline.1.ATOMUSDT
+		Data  []BybitCandle `json:"data"`
+	}
+
+	// BybitSubscriptionMsg Msg to subscribe to topics.
+	BybitSubscriptionMsg struct {
+		Op   string   `json:"op"`   // subscribe, unsubscribe
+		Args []string `json:"args"` // topics to subscribe ex.: tickers.ATOMUSDT
+	}
+
+	// BybitOperationResp defines the response to an operation, e.g. a
+	// subscription.
+	BybitOperationResp struct {
+		Op      string `json:"op"`
+		Success bool   `json:"success"`
+		RetMsg  string `json:"ret_msg"`
+	}
+
+	// BybitPairsSummary defines the response structure of the spot
+	// instruments.
+	BybitPairsSummary struct {
+		Result struct {
+			List []struct {
+				BaseCoin  string `json:"baseCoin"`
+				QuoteCoin string `json:"quoteCoin"`
+			} `json:"list"`
+		} `json:"result"`
+	}
+)
+
+func NewBybitProvider(
+	ctx context.Context,
+	logger zerolog.Logger,
+	endpoints Endpoint,
+	pairs ...types.CurrencyPair,
+) (*BybitProvider, error) {
+	if endpoints.Name != Bybit {
+		endpoints = Endpoint{
+			Name:      Bybit,
+			Rest:      bybitRestHost,
+			Websocket: bybitWSHost,
+		}
+	}
+
+	wsURL := url.URL{
+		Scheme: "wss",
+		Host:   endpoints.Websocket,
+		Path:   bybitWSPath,
+	}
+
+	bybitLogger := logger.With().Str("provider", "bybit").Logger()
+
+	provider := &BybitProvider{
+		logger:          bybitLogger,
+		endpoints:       endpoints,
+		tickers:         map[string]BybitTicker{},
+		candles:         map[string][]BybitCandle{},
+		subscribedPairs: map[string]types.CurrencyPair{},
+	}
+
+	provider.setSubscribedPairs(pairs...)
+
+	provider.wsc = NewWebsocketController(
+		ctx,
+		Bybit,
+		wsURL,
+		provider.getSubscriptionMsgs(pairs...),
+		provider.messageReceived,
+		defaultPingDuration,
+		websocket.PingMessage,
+		bybitLogger,
+	)
+
+	return provider, nil
+}
+
+// Start connects to the websocket in the background, see Lifecycle.
+func (p *BybitProvider) Start() error {
+	go p.wsc.Start()
+	return nil
+}
+
+// Stop closes the websocket, see Lifecycle.
+func (p *BybitProvider) Stop() {
+	p.wsc.Stop()
+}
+
+// getSubscriptionMsgs returns the messages subscribing to the ticker and
+// candle topics of the pairs, in batches of at most bybitMaxSubscription
+// topics as Bybit rejects larger ones.
+func (p *BybitProvider) getSubscriptionMsgs(cps ...types.CurrencyPair) []interface{} {
+	topics := make([]string, 0, len(cps)*2) //nolint: gomnd //const
+	for _, cp := range cps {
+		topics = append(topics, bybitTickerTopic+cp.String(), bybitCandleTopic+cp.String())
+	}
+
+	subscriptionMsgs := make([]interface{}, 0, len(topics)/bybitMaxSubscription+1)
+	for start := 0; start < len(topics); start += bybitMaxSubscription {
+		end := start + bybitMaxSubscription
+		if end > len(topics) {
+			end = len(topics)
+		}
+		subscriptionMsgs = append(subscriptionMsgs, newBybitSubscriptionMsg(topics[start:end]...))
+	}
+
+	return subscriptionMsgs
+}
+
+// SubscribeCurrencyPairs sends the new subscription messages to the websocket
+// and adds them to the providers subscribedPairs array.
+func (p *BybitProvider) SubscribeCurrencyPairs(_ context.Context, cps ...types.CurrencyPair) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	newPairs := []types.CurrencyPair{}
+	for _, cp := range cps {
+		if _, ok := p.subscribedPairs[cp.String()]; !ok {
+			newPairs = append(newPairs, cp)
+		}
+	}
+
+	newSubscriptionMsgs := p.getSubscriptionMsgs(newPairs...)
+	if err := p.wsc.AddSubscriptionMsgs(newSubscriptionMsgs); err != nil {
+		return err
+	}
+	p.setSubscribedPairs(newPairs...)
+	return nil
+}
+
+// GetTickerPrices returns the tickerPrices based on the provided pairs.
+func (p *BybitProvider) GetTickerPrices(
+	_ context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
+	tickerPrices := make(map[string]types.TickerPrice, len(pairs))
+
+	for _, cp := range pairs {
+		key := cp.String()
+		price, err := p.getTickerPrice(key)
+		if err != nil {
+			return nil, err
+		}
+		tickerPrices[key] = price
+	}
+
+	return tickerPrices, nil
+}
+
+// GetCandlePrices returns the candlePrices based on the provided pairs.
+func (p *BybitProvider) GetCandlePrices(
+	_ context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
+	candlePrices := make(map[string][]types.CandlePrice, len(pairs))
+
+	for _, cp := range pairs {
+		key := cp.String()
+		prices, err := p.getCandlePrices(key)
+		if err != nil {
+			return nil, err
+		}
+		candlePrices[key] = prices
+	}
+
+	return candlePrices, nil
+}
+
+func (p *BybitProvider) getTickerPrice(key string) (types.TickerPrice, error) {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	ticker, ok := p.tickers[key]
+	if !ok {
+		return types.TickerPrice{}, fmt.Errorf("bybit failed to get ticker price for %s", key)
+	}
+
+	return ticker.toTickerPrice()
+}
+
+func (p *BybitProvider) getCandlePrices(key string) ([]types.CandlePrice, error) {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	candles, ok := p.candles[key]
+	if !ok {
+		return []types.CandlePrice{}, fmt.Errorf("bybit failed to get candle prices for %s", key)
+	}
+
+	candleList := []types.CandlePrice{}
+	for _, candle := range candles {
+		cp, err := candle.toCandlePrice()
+		if err != nil {
+			return []types.CandlePrice{}, err
+		}
+		candleList = append(candleList, cp)
+	}
+	return candleList, nil
+}
+
+func (p *BybitProvider) messageReceived(_ int, bz []byte) {
+	var (
+		tickerResp    BybitTickerResponse
+		tickerErr     error
+		candleResp    BybitCandleResponse
+		candleErr     error
+		operationResp BybitOperationResp
+		operationErr  error
+	)
+
+	tickerErr = json.Unmarshal(bz, &tickerResp)
+	if strings.HasPrefix(tickerResp.Topic, bybitTickerTopic) {
+		p.setTickerPair(tickerResp.Data)
+		p.logger.Trace().
+			Str(Bybit.String(), messageTypeTicker).
+			Msg("Websocket message received")
+		return
+	}
+
+	candleErr = json.Unmarshal(bz, &candleResp)
+	if strings.HasPrefix(candleResp.Topic, bybitCandleTopic) {
+		symbol := strings.TrimPrefix(candleResp.Topic, bybitCandleTopic)
+		for _, candle := range candleResp.Data {
+			candle.Symbol = symbol
+			p.setCandlePair(candle)
+		}
+		p.logger.Trace().
+			Str(Bybit.String(), messageTypeCandle).
+			Msg("Websocket message received")
+		return
+	}
+
+	operationErr = json.Unmarshal(bz, &operationResp)
+	if len(operationResp.Op) != 0 {
+		if !operationResp.Success {
+			p.logger.Error().
+				Str("op", operationResp.Op).
+				Str("msg", operationResp.RetMsg).
+				Msg("bybit: operation failed")
+		}
+		return
+	}
+
+	p.logger.Error().
+		Int("length", len(bz)).
+		AnErr("ticker", tickerErr).
+		AnErr("candle", candleErr).
+		AnErr("operation", operationErr).
+		Msg("Error on receive message")
+}
+
+func (p *BybitProvider) setTickerPair(ticker BybitTicker) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.tickers[ticker.Symbol] = ticker
+}
+
+// setCandlePair stores the candle, replacing the previous update of the same
+// candle since Bybit pushes the candle in progress until it is confirmed.
+func (p *BybitProvider) setCandlePair(candle BybitCandle) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	staleTime := PastUnixTime(providerCandlePeriod)
+	var candleList []BybitCandle
+	candleList = append(candleList, candle)
+
+	for _, c := range p.candles[candle.Symbol] {
+		if staleTime < c.End && c.Start != candle.Start {
+			candleList = append(candleList, c)
+		}
+	}
+	p.candles[candle.Symbol] = candleList
+}
+
+func (ticker BybitTicker) toTickerPrice() (types.TickerPrice, error) {
+	return types.NewTickerPrice(string(Bybit), ticker.Symbol, ticker.LastPrice, ticker.Volume)
+}
+
+func (candle BybitCandle) toCandlePrice() (types.CandlePrice, error) {
+	return types.NewCandlePrice(string(Bybit), candle.Symbol, candle.Close, candle.Volume, candle.End)
+}
+
+// setSubscribedPairs sets N currency pairs to the map of subscribed pairs.
+func (p *BybitProvider) setSubscribedPairs(cps ...types.CurrencyPair) {
+	for _, cp := range cps {
+		p.subscribedPairs[cp.String()] = cp
+	}
+}
+
+// GetAvailablePairs returns all pairs to which the provider can subscribe.
+// ex.: map["ATOMUSDT" => {}, "XPRTUSDT" => {}].
+func (p *BybitProvider) GetAvailablePairs() (map[string]struct{}, error) {
+	resp, err := newDefaultHTTPClient().Get(p.endpoints.Rest + bybitRestPath)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var pairsSummary BybitPairsSummary
+	if err := json.NewDecoder(resp.Body).Decode(&pairsSummary); err != nil {
+		return nil, err
+	}
+
+	availablePairs := make(map[string]struct{}, len(pairsSummary.Result.List))
+	for _, pair := range pairsSummary.Result.List {
+		cp := types.CurrencyPair{
+			Base:  strings.ToUpper(pair.BaseCoin),
+			Quote: strings.ToUpper(pair.QuoteCoin),
+		}
+		availablePairs[cp.String()] = struct{}{}
+	}
+
+	return availablePairs, nil
+}
+
+// newBybitSubscriptionMsg returns a new subscription Msg.
+func newBybitSubscriptionMsg(topics ...string) BybitSubscriptionMsg {
+	return BybitSubscriptionMsg{
+		Op:   bybitOpSubscribe,
+		Args: topics,
+	}
+}
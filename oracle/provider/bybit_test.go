@@ -0,0 +1,183 @@
+package provider
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+func TestBybitProvider_GetTickerPrices(t *testing.T) {
+	p, err := NewBybitProvider(
+		context.TODO(),
+		zerolog.Nop(),
+		Endpoint{},
+		types.CurrencyPair{Base: "ATOM", Quote: "USDT"},
+	)
+	require.NoError(t, err)
+
+	t.Run("valid_request_single_ticker", func(t *testing.T) {
+		lastPrice := "34.69000000"
+		volume := "2396974.02000000"
+
+		tickerMap := map[string]BybitTicker{}
+		tickerMap["ATOMUSDT"] = BybitTicker{
+			Symbol:    "ATOMUSDT",
+			LastPrice: lastPrice,
+			Volume:    volume,
+		}
+
+		p.tickers = tickerMap
+
+		prices, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "ATOM", Quote: "USDT"})
+		require.NoError(t, err)
+		require.Len(t, prices, 1)
+		require.Equal(t, sdk.MustNewDecFromStr(lastPrice), prices["ATOMUSDT"].Price)
+		require.Equal(t, sdk.MustNewDecFromStr(volume), prices["ATOMUSDT"].Volume)
+	})
+
+	t.Run("valid_request_multi_ticker", func(t *testing.T) {
+		lastPriceAtom := "34.69000000"
+		lastPriceXprt := "0.41350000"
+		volume := "2396974.02000000"
+
+		tickerMap := map[string]BybitTicker{}
+		tickerMap["ATOMUSDT"] = BybitTicker{
+			Symbol:    "ATOMUSDT",
+			LastPrice: lastPriceAtom,
+			Volume:    volume,
+		}
+
+		tickerMap["XPRTUSDT"] = BybitTicker{
+			Symbol:    "XPRTUSDT",
+			LastPrice: lastPriceXprt,
+			Volume:    volume,
+		}
+
+		p.tickers = tickerMap
+		prices, err := p.GetTickerPrices(
+			context.Background(),
+			types.CurrencyPair{Base: "ATOM", Quote: "USDT"},
+			types.CurrencyPair{Base: "XPRT", Quote: "USDT"},
+		)
+		require.NoError(t, err)
+		require.Len(t, prices, 2)
+		require.Equal(t, sdk.MustNewDecFromStr(lastPriceAtom), prices["ATOMUSDT"].Price)
+		require.Equal(t, sdk.MustNewDecFromStr(volume), prices["ATOMUSDT"].Volume)
+		require.Equal(t, sdk.MustNewDecFromStr(lastPriceXprt), prices["XPRTUSDT"].Price)
+		require.Equal(t, sdk.MustNewDecFromStr(volume), prices["XPRTUSDT"].Volume)
+	})
+
+	t.Run("invalid_request_invalid_ticker", func(t *testing.T) {
+		prices, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "FOO", Quote: "BAR"})
+		require.EqualError(t, err, "bybit failed to get ticker price for FOOBAR")
+		require.Nil(t, prices)
+	})
+}
+
+func TestBybitProvider_GetCandlePrices(t *testing.T) {
+	p, err := NewBybitProvider(
+		context.TODO(),
+		zerolog.Nop(),
+		Endpoint{},
+		types.CurrencyPair{Base: "ATOM", Quote: "USDT"},
+	)
+	require.NoError(t, err)
+
+	t.Run("valid_request_single_candle", func(t *testing.T) {
+		price := "34.689998626708984000"
+		volume := "2396974.000000000000000000"
+		timeStamp := PastUnixTime(0)
+
+		p.setCandlePair(BybitCandle{
+			Symbol: "ATOMUSDT",
+			Start:  timeStamp - 60000,
+			End:    timeStamp,
+			Close:  price,
+			Volume: volume,
+		})
+
+		prices, err := p.GetCandlePrices(context.Background(), types.CurrencyPair{Base: "ATOM", Quote: "USDT"})
+		require.NoError(t, err)
+		require.Len(t, prices, 1)
+		require.Equal(t, sdk.MustNewDecFromStr(price), prices["ATOMUSDT"][0].Price)
+		require.Equal(t, sdk.MustNewDecFromStr(volume), prices["ATOMUSDT"][0].Volume)
+		require.Equal(t, timeStamp, prices["ATOMUSDT"][0].TimeStamp)
+	})
+
+	t.Run("updated_candle_replaced", func(t *testing.T) {
+		timeStamp := PastUnixTime(0)
+
+		candle := BybitCandle{Symbol: "ATOMUSDT", Start: timeStamp, End: timeStamp + 59999, Close: "34.5", Volume: "100"}
+		p.setCandlePair(candle)
+		candle.Close, candle.Volume = "34.6", "120"
+		p.setCandlePair(candle)
+
+		prices, err := p.GetCandlePrices(context.Background(), types.CurrencyPair{Base: "ATOM", Quote: "USDT"})
+		require.NoError(t, err)
+		require.Len(t, prices["ATOMUSDT"], 2)
+		require.Equal(t, sdk.MustNewDecFromStr("34.6"), prices["ATOMUSDT"][0].Price)
+	})
+
+	t.Run("invalid_request_invalid_candle", func(t *testing.T) {
+		prices, err := p.GetCandlePrices(context.Background(), types.CurrencyPair{Base: "FOO", Quote: "BAR"})
+		require.EqualError(t, err, "bybit failed to get candle prices for FOOBAR")
+		require.Nil(t, prices)
+	})
+}
+
+func TestBybitProvider_MessageReceived(t *testing.T) {
+	p, err := NewBybitProvider(
+		context.TODO(),
+		zerolog.Nop(),
+		Endpoint{},
+		types.CurrencyPair{Base: "ATOM", Quote: "USDT"},
+	)
+	require.NoError(t, err)
+	cp := types.CurrencyPair{Base: "ATOM", Quote: "USDT"}
+
+	p.messageReceived(websocket.TextMessage,
+		[]byte(`{"success":true,"ret_msg":"subscribe","conn_id":"1","op":"subscribe"}`))
+	_, err = p.GetTickerPrices(context.Background(), cp)
+	require.Error(t, err)
+
+	p.messageReceived(websocket.TextMessage, []byte(`{"topic":"tickers.ATOMUSDT","ts":1673853746003,"type":"snapshot",`+
+		`"data":{"symbol":"ATOMUSDT","lastPrice":"11.05","volume24h":"894123.5","turnover24h":"9880064.1"}}`))
+	prices, err := p.GetTickerPrices(context.Background(), cp)
+	require.NoError(t, err)
+	require.Equal(t, sdk.MustNewDecFromStr("11.05"), prices["ATOMUSDT"].Price)
+	require.Equal(t, sdk.MustNewDecFromStr("894123.5"), prices["ATOMUSDT"].Volume)
+
+	start := PastUnixTime(0)
+	p.messageReceived(websocket.TextMessage, []byte(`{"topic":"kline.1.ATOMUSDT","type":"snapshot","data":[{`+
+		`"start":`+strconv.FormatInt(start, 10)+`,"end":`+strconv.FormatInt(start+59999, 10)+`,"interval":"1",`+
+		`"open":"11","close":"11.02","high":"11.1","low":"10.9","volume":"1234.5","confirm":false}]}`))
+	candles, err := p.GetCandlePrices(context.Background(), cp)
+	require.NoError(t, err)
+	require.Len(t, candles["ATOMUSDT"], 1)
+	require.Equal(t, sdk.MustNewDecFromStr("11.02"), candles["ATOMUSDT"][0].Price)
+	require.Equal(t, sdk.MustNewDecFromStr("1234.5"), candles["ATOMUSDT"][0].Volume)
+	require.Equal(t, start+59999, candles["ATOMUSDT"][0].TimeStamp)
+}
+
+func TestBybitProvider_GetSubscriptionMsgs(t *testing.T) {
+	p := &BybitProvider{}
+
+	msgs := p.getSubscriptionMsgs(
+		types.CurrencyPair{Base: "ATOM", Quote: "USDT"},
+		types.CurrencyPair{Base: "XPRT", Quote: "USDT"},
+		types.CurrencyPair{Base: "OSMO", Quote: "USDT"},
+		types.CurrencyPair{Base: "BTC", Quote: "USDT"},
+		types.CurrencyPair{Base: "ETH", Quote: "USDT"},
+		types.CurrencyPair{Base: "STARS", Quote: "USDT"},
+	)
+	require.Len(t, msgs, 2)
+	require.Len(t, msgs[0].(BybitSubscriptionMsg).Args, bybitMaxSubscription)
+	require.Equal(t, []string{"tickers.STARSUSDT", "kline.1.STARSUSDT"}, msgs[1].(BybitSubscriptionMsg).Args)
+}
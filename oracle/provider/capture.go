@@ -0,0 +1,196 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxCaptureDuration bounds how long a payload capture can run.
+	MaxCaptureDuration = time.Hour
+
+	// maxCaptureBytes bounds the size of a capture file, after which the
+	// capture stops early.
+	maxCaptureBytes = 64 << 20
+)
+
+// captureAssetRegex matches the assets that can be captured, which are also
+// part of the capture file name.
+var captureAssetRegex = regexp.MustCompile(`^[A-Z0-9._-]+$`)
+
+// payloadCapture is the capture the providers record their raw payloads to,
+// if debug capture is enabled.
+var (
+	payloadCaptureMtx sync.RWMutex
+	payloadCapture    *PayloadCapture
+)
+
+type (
+	// PayloadCapture records the raw payloads received from the providers
+	// which mention an asset to a file, for a bounded duration, so that
+	// exchange format regressions can be reported upstream with evidence.
+	PayloadCapture struct {
+		dir string
+		now func() time.Time
+
+		mtx     sync.Mutex
+		file    *os.File
+		status  CaptureStatus
+		written int64
+	}
+
+	// CaptureStatus defines the state of the current or last capture.
+	CaptureStatus struct {
+		Active   bool      `json:"active"`
+		Asset    string    `json:"asset,omitempty"`
+		Path     string    `json:"path,omitempty"`
+		Until    time.Time `json:"until,omitempty"`
+		Payloads int       `json:"payloads"`
+	}
+
+	// capturedPayload defines a line of a capture file.
+	capturedPayload struct {
+		Time     time.Time   `json:"time"`
+		Provider Name        `json:"provider"`
+		Payload  interface{} `json:"payload"`
+	}
+)
+
+// NewPayloadCapture returns a capture writing its files to dir.
+func NewPayloadCapture(dir string) *PayloadCapture {
+	return &PayloadCapture{dir: dir, now: time.Now}
+}
+
+// SetPayloadCapture enables debug capture of the payloads of all providers to
+// pc, or disables it if pc is nil.
+func SetPayloadCapture(pc *PayloadCapture) {
+	payloadCaptureMtx.Lock()
+	defer payloadCaptureMtx.Unlock()
+
+	payloadCapture = pc
+}
+
+// capturePayload records a raw payload received from a provider to the
+// payload capture, if any.
+func capturePayload(providerName Name, payload []byte) {
+	payloadCaptureMtx.RLock()
+	pc := payloadCapture
+	payloadCaptureMtx.RUnlock()
+
+	if pc != nil {
+		pc.Record(providerName, payload)
+	}
+}
+
+// Start starts capturing the payloads mentioning asset, e.g. "ATOM", for the
+// given duration to a new file, stopping any capture in progress.
+func (pc *PayloadCapture) Start(asset string, duration time.Duration) (CaptureStatus, error) {
+	asset = strings.ToUpper(strings.TrimSpace(asset))
+	if !captureAssetRegex.MatchString(asset) {
+		return CaptureStatus{}, fmt.Errorf("invalid asset: %q", asset)
+	}
+	if duration <= 0 || duration > MaxCaptureDuration {
+		return CaptureStatus{}, fmt.Errorf("duration must be positive and at most %s", MaxCaptureDuration)
+	}
+
+	pc.mtx.Lock()
+	defer pc.mtx.Unlock()
+
+	pc.stop()
+
+	if err := os.MkdirAll(pc.dir, 0o700); err != nil {
+		return CaptureStatus{}, fmt.Errorf("failed to create capture directory: %w", err)
+	}
+
+	now := pc.now()
+	path := filepath.Join(pc.dir, fmt.Sprintf("capture-%s-%d.jsonl", asset, now.Unix()))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return CaptureStatus{}, fmt.Errorf("failed to create capture file: %w", err)
+	}
+
+	pc.file = file
+	pc.written = 0
+	pc.status = CaptureStatus{
+		Active: true,
+		Asset:  asset,
+		Path:   path,
+		Until:  now.Add(duration),
+	}
+
+	return pc.status, nil
+}
+
+// Stop stops the capture in progress, if any.
+func (pc *PayloadCapture) Stop() CaptureStatus {
+	pc.mtx.Lock()
+	defer pc.mtx.Unlock()
+
+	pc.stop()
+	return pc.status
+}
+
+// Status returns the state of the current or last capture.
+func (pc *PayloadCapture) Status() CaptureStatus {
+	pc.mtx.Lock()
+	defer pc.mtx.Unlock()
+
+	if pc.status.Active && pc.now().After(pc.status.Until) {
+		pc.stop()
+	}
+	return pc.status
+}
+
+// Record appends the payload to the capture file if a capture is in progress
+// and the payload mentions the captured asset.
+func (pc *PayloadCapture) Record(providerName Name, payload []byte) {
+	pc.mtx.Lock()
+	defer pc.mtx.Unlock()
+
+	if !pc.status.Active {
+		return
+	}
+
+	now := pc.now()
+	if now.After(pc.status.Until) || pc.written >= maxCaptureBytes {
+		pc.stop()
+		return
+	}
+
+	if !bytes.Contains(bytes.ToUpper(payload), []byte(pc.status.Asset)) {
+		return
+	}
+
+	record := capturedPayload{Time: now, Provider: providerName, Payload: string(payload)}
+	if json.Valid(payload) {
+		record.Payload = json.RawMessage(payload)
+	}
+
+	bz, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+
+	n, err := pc.file.Write(append(bz, '\n'))
+	pc.written += int64(n)
+	if err != nil {
+		pc.stop()
+		return
+	}
+	pc.status.Payloads++
+}
+
+func (pc *PayloadCapture) stop() {
+	if pc.file != nil {
+		_ = pc.file.Close()
+		pc.file = nil
+	}
+	pc.status.Active = false
+}
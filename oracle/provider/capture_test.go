@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayloadCapture(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	pc := NewPayloadCapture(t.TempDir())
+	pc.now = func() time.Time { return now }
+
+	// nothing is recorded before a capture is started
+	pc.Record(Binance, []byte(`{"s":"ATOMUSDT"}`))
+
+	_, err := pc.Start("../atom", time.Minute)
+	require.Error(t, err)
+	_, err = pc.Start("atom", 2*MaxCaptureDuration)
+	require.Error(t, err)
+
+	status, err := pc.Start("atom", time.Minute)
+	require.NoError(t, err)
+	require.True(t, status.Active)
+	require.Equal(t, "ATOM", status.Asset)
+
+	pc.Record(Binance, []byte(`{"s":"ATOMUSDT","c":"10.1"}`))
+	pc.Record(Binance, []byte(`{"s":"OSMOUSDT","c":"1.1"}`))
+	pc.Record(Kraken, []byte(`not json atom/usd`))
+	require.Equal(t, 2, pc.Status().Payloads)
+
+	// the capture stops once expired
+	now = now.Add(2 * time.Minute)
+	pc.Record(Binance, []byte(`{"s":"ATOMUSDT","c":"10.2"}`))
+	require.False(t, pc.Status().Active)
+	require.Equal(t, 2, pc.Status().Payloads)
+
+	bz, err := os.ReadFile(status.Path)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(bz)), "\n")
+	require.Len(t, lines, 2)
+
+	var record struct {
+		Provider Name            `json:"provider"`
+		Payload  json.RawMessage `json:"payload"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	require.Equal(t, Binance, record.Provider)
+	require.JSONEq(t, `{"s":"ATOMUSDT","c":"10.1"}`, string(record.Payload))
+
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+	require.Equal(t, `"not json atom/usd"`, string(record.Payload))
+}
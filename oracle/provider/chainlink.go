@@ -0,0 +1,202 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+const chainlinkRPCURL = "https://cloudflare-eth.com"
+
+var (
+	// chainlinkLatestRoundDataSelector is the selector of latestRoundData(),
+	// returning the round id, answer, start and update times and the round
+	// the answer was computed in.
+	chainlinkLatestRoundDataSelector = []byte{0xfe, 0xaf, 0x96, 0x8c}
+	// chainlinkDecimalsSelector is the selector of decimals().
+	chainlinkDecimalsSelector = []byte{0x31, 0x3c, 0xe5, 0x67}
+)
+
+var _ Provider = (*ChainlinkProvider)(nil)
+
+type (
+	// ChainlinkProvider defines an Oracle provider reading the latest answer
+	// of Chainlink price feed aggregators through an EVM RPC endpoint. Every
+	// pool is identified by the Contract address of the aggregator, or of its
+	// proxy, answering the price of its base in its quote. The decimals of the
+	// answers are read from the aggregator.
+	//
+	// Aggregators don't expose traded volumes, so the volumes are zero and
+	// candles are synthetic: one is built per round, timestamped with the
+	// time the round was updated at.
+	//
+	// REF: https://docs.chain.link/data-feeds/api-reference
+	ChainlinkProvider struct {
+		baseURL string
+		client  *http.Client
+		pools   map[string]Pool // CurrencyPair.String() => Pool
+
+		mtx      sync.Mutex
+		decimals map[string]int64               // CurrencyPair.String() => answer decimals
+		rounds   map[string]*big.Int            // CurrencyPair.String() => id of the last candle round
+		candles  map[string][]types.CandlePrice // CurrencyPair.String() => candles
+	}
+
+	// chainlinkRound defines the latest round of an aggregator.
+	chainlinkRound struct {
+		ID        *big.Int
+		Price     sdk.Dec
+		UpdatedAt int64 // unix time in milliseconds
+	}
+)
+
+// NewChainlinkProvider returns a Chainlink provider reading the aggregators
+// of the endpoint through the EVM RPC endpoint at its REST URL, or a public
+// Ethereum one.
+func NewChainlinkProvider(endpoint Endpoint) *ChainlinkProvider {
+	p := &ChainlinkProvider{
+		baseURL:  chainlinkRPCURL,
+		client:   newDefaultHTTPClient(),
+		pools:    make(map[string]Pool, len(endpoint.Pools)),
+		decimals: make(map[string]int64, len(endpoint.Pools)),
+		rounds:   make(map[string]*big.Int, len(endpoint.Pools)),
+		candles:  make(map[string][]types.CandlePrice, len(endpoint.Pools)),
+	}
+	if endpoint.Name == Chainlink && len(endpoint.Rest) > 0 {
+		p.baseURL = endpoint.Rest
+	}
+	for _, pool := range endpoint.Pools {
+		cp := types.CurrencyPair{Base: pool.Base, Quote: pool.Quote}
+		p.pools[cp.String()] = pool
+	}
+
+	return p
+}
+
+// SubscribeCurrencyPairs performs a no-op since the aggregators are called on
+// every request.
+func (*ChainlinkProvider) SubscribeCurrencyPairs(context.Context, ...types.CurrencyPair) error {
+	return nil
+}
+
+// GetTickerPrices returns the latest answers of the aggregators of the given
+// pairs.
+func (p *ChainlinkProvider) GetTickerPrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
+	tickerPrices := make(map[string]types.TickerPrice, len(pairs))
+	for _, cp := range pairs {
+		round, err := p.latestRound(ctx, cp)
+		if err != nil {
+			return nil, err
+		}
+
+		tickerPrices[cp.String()] = types.TickerPrice{Price: round.Price, Volume: sdk.ZeroDec()}
+	}
+
+	return tickerPrices, nil
+}
+
+// GetCandlePrices appends a candle with the latest answer to the candles of
+// the given pairs, unless its round already has one, and returns the candles
+// within providerCandlePeriod.
+func (p *ChainlinkProvider) GetCandlePrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
+	candles := make(map[string][]types.CandlePrice, len(pairs))
+	for _, cp := range pairs {
+		round, err := p.latestRound(ctx, cp)
+		if err != nil {
+			return nil, err
+		}
+
+		p.mtx.Lock()
+		pairCandles := p.candles[cp.String()]
+		if last, ok := p.rounds[cp.String()]; !ok || round.ID.Cmp(last) != 0 {
+			pairCandles = append(pairCandles, types.CandlePrice{
+				Price:     round.Price,
+				Volume:    sdk.ZeroDec(),
+				TimeStamp: round.UpdatedAt,
+			})
+			p.rounds[cp.String()] = round.ID
+		}
+
+		staleTime := PastUnixTime(providerCandlePeriod)
+		fresh := pairCandles[:0]
+		for _, candle := range pairCandles {
+			if candle.TimeStamp > staleTime {
+				fresh = append(fresh, candle)
+			}
+		}
+		p.candles[cp.String()] = fresh
+		candles[cp.String()] = append([]types.CandlePrice{}, fresh...)
+		p.mtx.Unlock()
+	}
+
+	return candles, nil
+}
+
+// latestRound returns the latest round of the aggregator of the pair, with
+// its answer in whole quote coins.
+func (p *ChainlinkProvider) latestRound(ctx context.Context, cp types.CurrencyPair) (chainlinkRound, error) {
+	pool, ok := p.pools[cp.String()]
+	if !ok {
+		return chainlinkRound{}, fmt.Errorf("no Chainlink aggregator configured for %s", cp.String())
+	}
+
+	decimals, err := p.answerDecimals(ctx, cp, pool)
+	if err != nil {
+		return chainlinkRound{}, err
+	}
+
+	words, err := ethCall(ctx, p.client, p.baseURL, Chainlink, pool.Contract, chainlinkLatestRoundDataSelector)
+	if err != nil {
+		return chainlinkRound{}, fmt.Errorf("chainlink failed to get aggregator %s round: %w", pool.Contract, err)
+	}
+	if len(words) < 5 { //nolint:gomnd //latestRoundData returns five values
+		return chainlinkRound{}, fmt.Errorf("chainlink aggregator %s returned an invalid round", pool.Contract)
+	}
+
+	// the answer is an int256, whose sign bit is set when negative
+	answer, updatedAt := words[1], words[3]
+	if answer.Sign() == 0 || answer.Bit(255) == 1 || updatedAt.Sign() == 0 { //nolint:gomnd //int256 sign bit
+		return chainlinkRound{}, fmt.Errorf("chainlink aggregator %s has no positive answer", pool.Contract)
+	}
+
+	return chainlinkRound{
+		ID:        words[0],
+		Price:     scalePoolAmount(sdk.NewIntFromBigInt(answer), decimals),
+		UpdatedAt: updatedAt.Int64() * 1000, //nolint:gomnd //seconds to milliseconds
+	}, nil
+}
+
+// answerDecimals returns the decimals of the answers of the aggregator of the
+// pair, read once from the aggregator.
+func (p *ChainlinkProvider) answerDecimals(ctx context.Context, cp types.CurrencyPair, pool Pool) (int64, error) {
+	p.mtx.Lock()
+	decimals, ok := p.decimals[cp.String()]
+	p.mtx.Unlock()
+	if ok {
+		return decimals, nil
+	}
+
+	words, err := ethCall(ctx, p.client, p.baseURL, Chainlink, pool.Contract, chainlinkDecimalsSelector)
+	if err != nil {
+		return 0, fmt.Errorf("chainlink failed to get aggregator %s decimals: %w", pool.Contract, err)
+	}
+	decimals = words[0].Int64()
+
+	p.mtx.Lock()
+	p.decimals[cp.String()] = decimals
+	p.mtx.Unlock()
+
+	return decimals, nil
+}
@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+const (
+	testChainlinkETHUSD = "0x5f4ec3df9cbd43714fe2740f5e3616155c5b8419"
+	testChainlinkBadUSD = "0x0000000000000000000000000000000000000bad"
+)
+
+func TestChainlinkProvider(t *testing.T) {
+	var (
+		roundID   = int64(100)
+		updatedAt = time.Now().Add(-time.Minute).Unix()
+	)
+	word := func(v *big.Int) string {
+		return hex.EncodeToString(v.FillBytes(make([]byte, 32)))
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var ethReq struct {
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&ethReq))
+
+		var params ethCallParams
+		require.NoError(t, json.Unmarshal(ethReq.Params[0], &params))
+		data, err := hex.DecodeString(strings.TrimPrefix(params.Data, "0x"))
+		require.NoError(t, err)
+
+		answer := big.NewInt(185_012_345_678) // 1850.12345678 with 8 decimals
+		if params.To == testChainlinkBadUSD {
+			// -1 as an int256
+			answer = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+		}
+
+		switch {
+		case bytes.HasPrefix(data, chainlinkDecimalsSelector):
+			fmt.Fprintf(rw, `{"jsonrpc":"2.0","id":1,"result":"0x%s"}`, word(big.NewInt(8)))
+
+		case bytes.HasPrefix(data, chainlinkLatestRoundDataSelector):
+			fmt.Fprintf(rw, `{"jsonrpc":"2.0","id":1,"result":"0x%s%s%s%s%s"}`,
+				word(big.NewInt(roundID)),
+				word(answer),
+				word(big.NewInt(updatedAt)),
+				word(big.NewInt(updatedAt)),
+				word(big.NewInt(roundID)),
+			)
+
+		default:
+			t.Fatalf("unexpected call %s", params.Data)
+		}
+	}))
+	defer server.Close()
+
+	p := NewChainlinkProvider(Endpoint{
+		Name: Chainlink,
+		Rest: server.URL,
+		Pools: []Pool{
+			{Base: "ETH", Quote: "USD", Contract: testChainlinkETHUSD},
+			{Base: "BAD", Quote: "USD", Contract: testChainlinkBadUSD},
+		},
+	})
+	p.client = server.Client()
+	pair := types.CurrencyPair{Base: "ETH", Quote: "USD"}
+
+	t.Run("ticker", func(t *testing.T) {
+		prices, err := p.GetTickerPrices(context.Background(), pair)
+		require.NoError(t, err)
+		require.Equal(t, sdk.MustNewDecFromStr("1850.12345678"), prices["ETHUSD"].Price)
+		require.True(t, prices["ETHUSD"].Volume.IsZero())
+	})
+
+	t.Run("candle_per_round", func(t *testing.T) {
+		_, err := p.GetCandlePrices(context.Background(), pair)
+		require.NoError(t, err)
+		candles, err := p.GetCandlePrices(context.Background(), pair)
+		require.NoError(t, err)
+		require.Len(t, candles["ETHUSD"], 1)
+		require.Equal(t, updatedAt*1000, candles["ETHUSD"][0].TimeStamp)
+
+		roundID++
+		updatedAt += 30
+		candles, err = p.GetCandlePrices(context.Background(), pair)
+		require.NoError(t, err)
+		require.Len(t, candles["ETHUSD"], 2)
+		require.Equal(t, updatedAt*1000, candles["ETHUSD"][1].TimeStamp)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "BTC", Quote: "USD"})
+		require.EqualError(t, err, "no Chainlink aggregator configured for BTCUSD")
+
+		_, err = p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "BAD", Quote: "USD"})
+		require.EqualError(t, err, "chainlink aggregator "+testChainlinkBadUSD+" has no positive answer")
+	})
+}
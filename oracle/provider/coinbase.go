@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"net/url"
 	"sort"
 	"strings"
@@ -132,11 +131,21 @@ func NewCoinbaseProvider(
 		websocket.PingMessage,
 		coinbaseLogger,
 	)
-	go provider.wsc.Start()
 
 	return provider, nil
 }
 
+// Start connects to the websocket in the background, see Lifecycle.
+func (p *CoinbaseProvider) Start() error {
+	go p.wsc.Start()
+	return nil
+}
+
+// Stop closes the websocket, see Lifecycle.
+func (p *CoinbaseProvider) Stop() {
+	p.wsc.Stop()
+}
+
 func (p *CoinbaseProvider) getSubscriptionMsgs(cps ...types.CurrencyPair) []interface{} {
 	subscriptionMsgs := make([]interface{}, 0, 1)
 
@@ -154,7 +163,7 @@ func (p *CoinbaseProvider) getSubscriptionMsgs(cps ...types.CurrencyPair) []inte
 
 // SubscribeCurrencyPairs sends the new subscription messages to the websocket
 // and adds them to the providers subscribedPairs array.
-func (p *CoinbaseProvider) SubscribeCurrencyPairs(cps ...types.CurrencyPair) error {
+func (p *CoinbaseProvider) SubscribeCurrencyPairs(_ context.Context, cps ...types.CurrencyPair) error {
 	p.mtx.Lock()
 	defer p.mtx.Unlock()
 
@@ -174,7 +183,10 @@ func (p *CoinbaseProvider) SubscribeCurrencyPairs(cps ...types.CurrencyPair) err
 }
 
 // GetTickerPrices returns the tickerPrices based on the saved map.
-func (p *CoinbaseProvider) GetTickerPrices(pairs ...types.CurrencyPair) (map[string]types.TickerPrice, error) {
+func (p *CoinbaseProvider) GetTickerPrices(
+	_ context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
 	tickerPrices := make(map[string]types.TickerPrice, len(pairs))
 
 	for _, currencyPair := range pairs {
@@ -193,7 +205,10 @@ func (p *CoinbaseProvider) GetTickerPrices(pairs ...types.CurrencyPair) (map[str
 // Candles need to be cut up into one-minute intervals.
 
 //nolint:funlen // No need to split this function
-func (p *CoinbaseProvider) GetCandlePrices(pairs ...types.CurrencyPair) (map[string][]types.CandlePrice, error) {
+func (p *CoinbaseProvider) GetCandlePrices(
+	_ context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
 	tradeMap := make(map[string][]CoinbaseTrade, len(pairs))
 
 	for _, cp := range pairs {
@@ -263,7 +278,7 @@ func (p *CoinbaseProvider) GetCandlePrices(pairs ...types.CurrencyPair) (map[str
 
 // GetAvailablePairs returns all pairs to which the provider can subscribe.
 func (p *CoinbaseProvider) GetAvailablePairs() (map[string]struct{}, error) {
-	resp, err := http.Get(p.endpoints.Rest + coinbaseRestPath)
+	resp, err := newDefaultHTTPClient().Get(p.endpoints.Rest + coinbaseRestPath)
 	if err != nil {
 		return nil, err
 	}
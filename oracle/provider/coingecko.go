@@ -0,0 +1,364 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+const (
+	coinGeckoRestURL             = "https://api.coingecko.com/api/v3"
+	coinGeckoProRestURL          = "https://pro-api.coingecko.com/api/v3"
+	coinGeckoSimplePriceEndpoint = "/simple/price"
+	coinGeckoMarketChartEndpoint = "/coins/%s/market_chart"
+	coinGeckoListEndpoint        = "/coins/list"
+	coinGeckoAPIKeyHeader        = "x-cg-pro-api-key"
+
+	// coinGeckoTickerTTL and coinGeckoChartTTL bound how often the prices and
+	// the market charts are requested, CoinGecko aggregating them at a much
+	// lower pace than the oracle ticks and rate limiting the public API.
+	coinGeckoTickerTTL = 30 * time.Second
+	coinGeckoChartTTL  = 5 * time.Minute
+)
+
+var _ Provider = (*CoinGeckoProvider)(nil)
+
+type (
+	// CoinGeckoProvider defines an Oracle provider implemented by the CoinGecko
+	// API, which aggregates the prices of many exchanges. The coin of a pair is
+	// the BaseDenom of its pool when configured, otherwise the only CoinGecko
+	// coin with the symbol of its base, and it is priced in the QuoteDenom of
+	// the pool or the lowercase quote, e.g. "usd".
+	//
+	// CoinGecko doesn't serve candles, so they are synthesized from the points
+	// of the market chart of the last day within providerCandlePeriod, with
+	// the 24h volume at each point converted to the base.
+	//
+	// Setting an API key switches to the pro API.
+	//
+	// REF: https://www.coingecko.com/en/api/documentation
+	CoinGeckoProvider struct {
+		baseURL string
+		apiKey  string
+		client  *http.Client
+		pools   map[string]Pool // CurrencyPair.String() => Pool
+
+		mtx       sync.Mutex
+		coinIDs   map[string]string // lowercase symbol => coin id, or "" when ambiguous
+		tickers   map[string]types.TickerPrice
+		tickersAt time.Time
+		charts    map[string][]types.CandlePrice // CurrencyPair.String() => candles
+		chartsAt  map[string]time.Time
+	}
+
+	// CoinGeckoMarketChart defines the response structure of the market chart
+	// of a coin, whose points are [unix time in milliseconds, value] pairs.
+	CoinGeckoMarketChart struct {
+		Prices       [][2]float64 `json:"prices"`
+		TotalVolumes [][2]float64 `json:"total_volumes"`
+	}
+
+	// CoinGeckoCoin defines a coin listed by CoinGecko.
+	CoinGeckoCoin struct {
+		ID     string `json:"id"`     // ex.: cosmos
+		Symbol string `json:"symbol"` // ex.: atom
+	}
+)
+
+// NewCoinGeckoProvider returns a CoinGecko provider using the REST URL of the
+// endpoint, or the public API, or the pro API when the endpoint has an API
+// key.
+func NewCoinGeckoProvider(endpoint Endpoint) *CoinGeckoProvider {
+	p := &CoinGeckoProvider{
+		baseURL:  coinGeckoRestURL,
+		apiKey:   endpoint.APIKey,
+		client:   newDefaultHTTPClient(),
+		pools:    make(map[string]Pool, len(endpoint.Pools)),
+		charts:   make(map[string][]types.CandlePrice),
+		chartsAt: make(map[string]time.Time),
+	}
+
+	// the endpoint only holds the API key when its provider_endpoints entry
+	// is missing, in which case its name is unset
+	switch {
+	case endpoint.Name == CoinGecko && len(endpoint.Rest) > 0:
+		p.baseURL = endpoint.Rest
+	case len(p.apiKey) > 0:
+		p.baseURL = coinGeckoProRestURL
+	}
+	if endpoint.Name == CoinGecko {
+		for _, pool := range endpoint.Pools {
+			cp := types.CurrencyPair{Base: pool.Base, Quote: pool.Quote}
+			p.pools[cp.String()] = pool
+		}
+	}
+
+	return p
+}
+
+// SubscribeCurrencyPairs performs a no-op since CoinGecko is requested on
+// demand.
+func (*CoinGeckoProvider) SubscribeCurrencyPairs(context.Context, ...types.CurrencyPair) error {
+	return nil
+}
+
+// GetTickerPrices returns the simple prices of the given pairs, with their
+// 24h volumes converted to the base.
+func (p *CoinGeckoProvider) GetTickerPrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	tickers := p.tickers
+	if !p.hasTickers(pairs) || time.Since(p.tickersAt) >= coinGeckoTickerTTL {
+		var err error
+		if tickers, err = p.getTickers(ctx, pairs); err != nil {
+			return nil, err
+		}
+		p.tickers, p.tickersAt = tickers, time.Now()
+	}
+
+	tickerPrices := make(map[string]types.TickerPrice, len(pairs))
+	for _, cp := range pairs {
+		ticker, ok := tickers[cp.String()]
+		if !ok {
+			return nil, fmt.Errorf("coingecko failed to get ticker price for %s", cp.String())
+		}
+		tickerPrices[cp.String()] = ticker
+	}
+
+	return tickerPrices, nil
+}
+
+// GetCandlePrices returns the candles of the given pairs synthesized from
+// their market charts.
+func (p *CoinGeckoProvider) GetCandlePrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	candles := make(map[string][]types.CandlePrice, len(pairs))
+	for _, cp := range pairs {
+		if time.Since(p.chartsAt[cp.String()]) >= coinGeckoChartTTL {
+			chart, err := p.getMarketChart(ctx, cp)
+			if err != nil {
+				return nil, err
+			}
+			p.charts[cp.String()], p.chartsAt[cp.String()] = chart.toCandlePrices(), time.Now()
+		}
+
+		staleTime := PastUnixTime(providerCandlePeriod)
+		pairCandles := []types.CandlePrice{}
+		for _, candle := range p.charts[cp.String()] {
+			if candle.TimeStamp > staleTime {
+				pairCandles = append(pairCandles, candle)
+			}
+		}
+		candles[cp.String()] = pairCandles
+	}
+
+	return candles, nil
+}
+
+// hasTickers returns whether the cached tickers price every pair.
+func (p *CoinGeckoProvider) hasTickers(pairs []types.CurrencyPair) bool {
+	for _, cp := range pairs {
+		if _, ok := p.tickers[cp.String()]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// getTickers requests the simple prices of the coins of the pairs in their
+// quotes.
+func (p *CoinGeckoProvider) getTickers(
+	ctx context.Context,
+	pairs []types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
+	var (
+		ids        = make([]string, 0, len(pairs))
+		currencies = make([]string, 0, len(pairs))
+		seen       = make(map[string]struct{}, 2*len(pairs))
+	)
+	for _, cp := range pairs {
+		id, currency, err := p.coin(ctx, cp)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen["id:"+id]; !ok {
+			seen["id:"+id] = struct{}{}
+			ids = append(ids, id)
+		}
+		if _, ok := seen["vs:"+currency]; !ok {
+			seen["vs:"+currency] = struct{}{}
+			currencies = append(currencies, currency)
+		}
+	}
+
+	query := url.Values{}
+	query.Set("ids", strings.Join(ids, ","))
+	query.Set("vs_currencies", strings.Join(currencies, ","))
+	query.Set("include_24hr_vol", "true")
+
+	var resp map[string]map[string]float64 // coin id => currency => price, and currency_24h_vol => volume
+	if err := p.get(ctx, coinGeckoSimplePriceEndpoint, query, &resp); err != nil {
+		return nil, fmt.Errorf("coingecko failed to get simple prices: %w", err)
+	}
+
+	tickers := make(map[string]types.TickerPrice, len(pairs))
+	for _, cp := range pairs {
+		id, currency, _ := p.coin(ctx, cp)
+		price, ok := resp[id][currency]
+		if !ok || price <= 0 {
+			continue
+		}
+
+		priceDec := floatToDec(price)
+		tickers[cp.String()] = types.TickerPrice{
+			Price:  priceDec,
+			Volume: floatToDec(resp[id][currency+"_24h_vol"]).Quo(priceDec),
+		}
+	}
+
+	return tickers, nil
+}
+
+// getMarketChart requests the market chart of the last day of the coin of
+// the pair in its quote.
+func (p *CoinGeckoProvider) getMarketChart(ctx context.Context, cp types.CurrencyPair) (CoinGeckoMarketChart, error) {
+	id, currency, err := p.coin(ctx, cp)
+	if err != nil {
+		return CoinGeckoMarketChart{}, err
+	}
+
+	query := url.Values{}
+	query.Set("vs_currency", currency)
+	query.Set("days", "1")
+
+	var chart CoinGeckoMarketChart
+	if err := p.get(ctx, fmt.Sprintf(coinGeckoMarketChartEndpoint, url.PathEscape(id)), query, &chart); err != nil {
+		return CoinGeckoMarketChart{}, fmt.Errorf("coingecko failed to get %s market chart: %w", id, err)
+	}
+
+	return chart, nil
+}
+
+// coin returns the CoinGecko coin id of the base of the pair and the currency
+// it is priced in.
+func (p *CoinGeckoProvider) coin(ctx context.Context, cp types.CurrencyPair) (string, string, error) {
+	currency := strings.ToLower(cp.Quote)
+	pool, ok := p.pools[cp.String()]
+	if ok && len(pool.QuoteDenom) > 0 {
+		currency = pool.QuoteDenom
+	}
+	if ok && len(pool.BaseDenom) > 0 {
+		return pool.BaseDenom, currency, nil
+	}
+
+	if p.coinIDs == nil {
+		var coins []CoinGeckoCoin
+		if err := p.get(ctx, coinGeckoListEndpoint, nil, &coins); err != nil {
+			return "", "", fmt.Errorf("coingecko failed to get coin list: %w", err)
+		}
+
+		p.coinIDs = make(map[string]string, len(coins))
+		for _, coin := range coins {
+			symbol := strings.ToLower(coin.Symbol)
+			if _, ok := p.coinIDs[symbol]; ok {
+				p.coinIDs[symbol] = ""
+				continue
+			}
+			p.coinIDs[symbol] = coin.ID
+		}
+	}
+
+	id, ok := p.coinIDs[strings.ToLower(cp.Base)]
+	switch {
+	case !ok:
+		return "", "", fmt.Errorf("coingecko has no coin with symbol %s", cp.Base)
+	case len(id) == 0:
+		return "", "", fmt.Errorf("coingecko has several coins with symbol %s, configure its base_denom", cp.Base)
+	}
+
+	return id, currency, nil
+}
+
+// get requests the endpoint with the query, bounded by ctx, and decodes the
+// JSON response into resp.
+func (p *CoinGeckoProvider) get(ctx context.Context, endpoint string, query url.Values, resp interface{}) error {
+	reqURL := p.baseURL + endpoint
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return err
+	}
+	if len(p.apiKey) > 0 {
+		req.Header.Set(coinGeckoAPIKeyHeader, p.apiKey)
+	}
+
+	httpResp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+
+	if err := checkHTTPStatus(httpResp); err != nil {
+		return err
+	}
+
+	bz, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read coingecko response body: %w", err)
+	}
+	capturePayload(CoinGecko, bz)
+
+	return json.Unmarshal(bz, resp)
+}
+
+// toCandlePrices returns a candle per positive price point of the chart,
+// with the volume at the same time converted to the base.
+func (chart CoinGeckoMarketChart) toCandlePrices() []types.CandlePrice {
+	volumes := make(map[float64]float64, len(chart.TotalVolumes))
+	for _, point := range chart.TotalVolumes {
+		volumes[point[0]] = point[1]
+	}
+
+	candles := make([]types.CandlePrice, 0, len(chart.Prices))
+	for _, point := range chart.Prices {
+		if point[1] <= 0 {
+			continue
+		}
+
+		price := floatToDec(point[1])
+		volume := sdk.ZeroDec()
+		if quoteVolume, ok := volumes[point[0]]; ok {
+			volume = floatToDec(quoteVolume).Quo(price)
+		}
+		candles = append(candles, types.CandlePrice{
+			Price:     price,
+			Volume:    volume,
+			TimeStamp: int64(point[0]),
+		})
+	}
+
+	return candles
+}
@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+func TestCoinGeckoProvider(t *testing.T) {
+	var (
+		now          = time.Now().UnixMilli()
+		stale        = time.Now().Add(-2 * providerCandlePeriod).UnixMilli()
+		priceQueries int
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		require.Equal(t, "secret", req.Header.Get(coinGeckoAPIKeyHeader))
+
+		switch req.URL.Path {
+		case coinGeckoListEndpoint:
+			fmt.Fprint(rw, `[{"id":"cosmos","symbol":"atom"},{"id":"xprt","symbol":"xprt"},`+
+				`{"id":"usd-coin","symbol":"usdc"},{"id":"bridged-usdc","symbol":"usdc"}]`)
+
+		case coinGeckoSimplePriceEndpoint:
+			priceQueries++
+			require.Equal(t, "cosmos,persistence", req.URL.Query().Get("ids"))
+			require.Equal(t, "usd", req.URL.Query().Get("vs_currencies"))
+			fmt.Fprint(rw, `{"cosmos":{"usd":10.5,"usd_24h_vol":2100},"persistence":{"usd":0.25,"usd_24h_vol":50}}`)
+
+		case "/coins/cosmos/market_chart":
+			require.Equal(t, "usd", req.URL.Query().Get("vs_currency"))
+			fmt.Fprintf(rw, `{"prices":[[%d,9.5],[%d,10],[%d,0]],"total_volumes":[[%d,950],[%d,2000]]}`,
+				stale, now, now+1, stale, now)
+
+		default:
+			t.Fatalf("unexpected request %s", req.URL)
+		}
+	}))
+	defer server.Close()
+
+	p := NewCoinGeckoProvider(Endpoint{
+		Name:   CoinGecko,
+		Rest:   server.URL,
+		APIKey: "secret",
+		Pools:  []Pool{{Base: "XPRT", Quote: "USD", BaseDenom: "persistence"}},
+	})
+	p.client = server.Client()
+	atom := types.CurrencyPair{Base: "ATOM", Quote: "USD"}
+	xprt := types.CurrencyPair{Base: "XPRT", Quote: "USD"}
+
+	t.Run("ticker", func(t *testing.T) {
+		prices, err := p.GetTickerPrices(context.Background(), atom, xprt)
+		require.NoError(t, err)
+		require.Equal(t, sdk.MustNewDecFromStr("10.5"), prices["ATOMUSD"].Price)
+		require.Equal(t, sdk.NewDec(200), prices["ATOMUSD"].Volume)
+		require.Equal(t, sdk.MustNewDecFromStr("0.25"), prices["XPRTUSD"].Price)
+		require.Equal(t, sdk.NewDec(200), prices["XPRTUSD"].Volume)
+
+		_, err = p.GetTickerPrices(context.Background(), atom)
+		require.NoError(t, err)
+		require.Equal(t, 1, priceQueries)
+	})
+
+	t.Run("candles", func(t *testing.T) {
+		candles, err := p.GetCandlePrices(context.Background(), atom)
+		require.NoError(t, err)
+		require.Equal(t, []types.CandlePrice{
+			{Price: sdk.NewDec(10), Volume: sdk.NewDec(200), TimeStamp: now},
+		}, candles["ATOMUSD"])
+	})
+
+	t.Run("credentials_only", func(t *testing.T) {
+		require.Equal(t, coinGeckoRestURL, NewCoinGeckoProvider(Endpoint{}).baseURL)
+		require.Equal(t, coinGeckoProRestURL, NewCoinGeckoProvider(Endpoint{APIKey: "secret"}).baseURL)
+	})
+
+	t.Run("ambiguous_symbol", func(t *testing.T) {
+		_, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "USDC", Quote: "USD"})
+		require.EqualError(t, err, "coingecko has several coins with symbol USDC, configure its base_denom")
+	})
+}
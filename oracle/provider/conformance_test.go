@@ -0,0 +1,165 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/provider/providertest"
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+// pushJSON marshals msg and passes it to the websocket message handler.
+func pushJSON(t *testing.T, handler MessageHandler, msg interface{}) {
+	t.Helper()
+
+	bz, err := json.Marshal(msg)
+	require.NoError(t, err)
+	handler(websocket.TextMessage, bz)
+}
+
+func TestBinanceProvider_Conformance(t *testing.T) {
+	providertest.Run(t, providertest.Harness{
+		Name: Binance.String(),
+		New: func(t *testing.T, pairs ...types.CurrencyPair) providertest.Provider {
+			p, err := NewBinanceProvider(context.TODO(), zerolog.Nop(), Endpoint{}, false, pairs...)
+			require.NoError(t, err)
+			return p
+		},
+		PushTicker: func(t *testing.T, p providertest.Provider, ticker providertest.Ticker) {
+			pushJSON(t, p.(*BinanceProvider).messageReceived, BinanceTicker{
+				Symbol:    ticker.Pair.String(),
+				LastPrice: ticker.Price,
+				Volume:    ticker.Volume,
+			})
+		},
+		PushCandle: func(t *testing.T, p providertest.Provider, candle providertest.Candle) {
+			pushJSON(t, p.(*BinanceProvider).messageReceived, BinanceCandle{
+				Symbol: candle.Pair.String(),
+				Metadata: BinanceCandleMetadata{
+					Close:     candle.Price,
+					TimeStamp: candle.TimeStamp,
+					Volume:    candle.Volume,
+				},
+			})
+		},
+	})
+}
+
+func TestBybitProvider_Conformance(t *testing.T) {
+	providertest.Run(t, providertest.Harness{
+		Name: Bybit.String(),
+		New: func(t *testing.T, pairs ...types.CurrencyPair) providertest.Provider {
+			p, err := NewBybitProvider(context.TODO(), zerolog.Nop(), Endpoint{}, pairs...)
+			require.NoError(t, err)
+			return p
+		},
+		PushTicker: func(t *testing.T, p providertest.Provider, ticker providertest.Ticker) {
+			pushJSON(t, p.(*BybitProvider).messageReceived, BybitTickerResponse{
+				Topic: bybitTickerTopic + ticker.Pair.String(),
+				Data: BybitTicker{
+					Symbol:    ticker.Pair.String(),
+					LastPrice: ticker.Price,
+					Volume:    ticker.Volume,
+				},
+			})
+		},
+		PushCandle: func(t *testing.T, p providertest.Provider, candle providertest.Candle) {
+			pushJSON(t, p.(*BybitProvider).messageReceived, BybitCandleResponse{
+				Topic: bybitCandleTopic + candle.Pair.String(),
+				Data: []BybitCandle{{
+					Start:  candle.TimeStamp - 59999,
+					End:    candle.TimeStamp,
+					Close:  candle.Price,
+					Volume: candle.Volume,
+				}},
+			})
+		},
+	})
+}
+
+func TestOkxProvider_Conformance(t *testing.T) {
+	providertest.Run(t, providertest.Harness{
+		Name: Okx.String(),
+		New: func(t *testing.T, pairs ...types.CurrencyPair) providertest.Provider {
+			p, err := NewOkxProvider(context.TODO(), zerolog.Nop(), Endpoint{}, pairs...)
+			require.NoError(t, err)
+			return p
+		},
+		PushTicker: func(t *testing.T, p providertest.Provider, ticker providertest.Ticker) {
+			instID := currencyPairToOkxPair(ticker.Pair)
+			pushJSON(t, p.(*OkxProvider).messageReceived, map[string]interface{}{
+				"arg": map[string]string{"channel": "tickers", "instId": instID},
+				"data": []map[string]string{
+					{"instType": "SPOT", "instId": instID, "last": ticker.Price, "vol24h": ticker.Volume},
+				},
+			})
+		},
+		PushCandle: func(t *testing.T, p providertest.Provider, candle providertest.Candle) {
+			timeStamp := strconv.FormatInt(candle.TimeStamp, 10)
+			pushJSON(t, p.(*OkxProvider).messageReceived, map[string]interface{}{
+				"arg": map[string]string{"channel": "candle1m", "instId": currencyPairToOkxPair(candle.Pair)},
+				"data": [][]string{
+					{timeStamp, candle.Price, candle.Price, candle.Price, candle.Price, candle.Volume, "0", "0", "0"},
+				},
+			})
+		},
+	})
+}
+
+func TestMexcProvider_Conformance(t *testing.T) {
+	providertest.Run(t, providertest.Harness{
+		Name: Mexc.String(),
+		New: func(t *testing.T, pairs ...types.CurrencyPair) providertest.Provider {
+			p, err := NewMexcProvider(context.TODO(), zerolog.Nop(), Endpoint{}, pairs...)
+			require.NoError(t, err)
+			return p
+		},
+		PushTicker: func(t *testing.T, p providertest.Provider, ticker providertest.Ticker) {
+			resp := MexcDealsResponse{Channel: currencyPairToMexcDeals(ticker.Pair), Symbol: ticker.Pair.String()}
+			resp.Data.Deals = []MexcDeal{{Price: ticker.Price, Volume: ticker.Volume, TimeStamp: time.Now().UnixMilli()}}
+			pushJSON(t, p.(*MexcProvider).messageReceived, resp)
+		},
+		PushCandle: func(t *testing.T, p providertest.Provider, candle providertest.Candle) {
+			resp := MexcCandleResponse{Channel: currencyPairToMexcCandle(candle.Pair), Symbol: candle.Pair.String()}
+			end := candle.TimeStamp / int64(time.Second/time.Millisecond)
+			resp.Data.Candle = MexcCandle{Start: end - 60, End: end, Close: candle.Price, Volume: candle.Volume}
+			pushJSON(t, p.(*MexcProvider).messageReceived, resp)
+		},
+	})
+}
+
+func TestGeminiProvider_Conformance(t *testing.T) {
+	providertest.Run(t, providertest.Harness{
+		Name: Gemini.String(),
+		New: func(t *testing.T, pairs ...types.CurrencyPair) providertest.Provider {
+			p, err := NewGeminiProvider(context.TODO(), zerolog.Nop(), Endpoint{}, pairs...)
+			require.NoError(t, err)
+			return p
+		},
+		PushTicker: func(t *testing.T, p providertest.Provider, ticker providertest.Ticker) {
+			pushJSON(t, p.(*GeminiProvider).messageReceived, GeminiTrade{
+				Type:      geminiTradeType,
+				Symbol:    ticker.Pair.String(),
+				Price:     ticker.Price,
+				Quantity:  ticker.Volume,
+				TimeStamp: time.Now().UnixMilli(),
+			})
+		},
+		PushCandle: func(t *testing.T, p providertest.Provider, candle providertest.Candle) {
+			start := json.Number(strconv.FormatInt(candle.TimeStamp-geminiCandlePeriod, 10))
+			price, volume := json.Number(candle.Price), json.Number(candle.Volume)
+			pushJSON(t, p.(*GeminiProvider).messageReceived, GeminiCandleResponse{
+				Type:    geminiCandleUpdatesType,
+				Symbol:  candle.Pair.String(),
+				Changes: [][]json.Number{{start, price, price, price, price, volume}},
+			})
+		},
+	})
+}
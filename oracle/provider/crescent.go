@@ -0,0 +1,217 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+const (
+	crescentRestURL      = "https://mainnet.crescent.network:1317"
+	crescentPairEndpoint = "/crescent/liquidity/v1beta1/pairs/"
+
+	// crescentHeightHeader is the header the gRPC gateway of a Cosmos node
+	// sets to the height a query was served at.
+	crescentHeightHeader = "Grpc-Metadata-X-Cosmos-Block-Height"
+)
+
+var _ Provider = (*CrescentProvider)(nil)
+
+type (
+	// CrescentProvider defines an Oracle provider reading the last price of
+	// the configured pairs of the Crescent liquidity module through the REST
+	// API of a Crescent node. Every pool is identified by the ID of its pair
+	// and its BaseDenom and QuoteDenom are the denoms of the base and quote
+	// coins, which may be in either order in the pair, and its exponents their
+	// decimals.
+	//
+	// The liquidity module doesn't expose traded volumes, so the volumes are
+	// zero and candles are synthetic: one is built from the last price every
+	// time it is read at a new block height.
+	//
+	// REF: https://docs.crescent.network/other-information/endpoints
+	CrescentProvider struct {
+		baseURL string
+		client  *http.Client
+		pools   map[string]Pool // CurrencyPair.String() => Pool
+
+		mtx     sync.Mutex
+		heights map[string]int64               // CurrencyPair.String() => height of the last candle
+		candles map[string][]types.CandlePrice // CurrencyPair.String() => candles
+	}
+
+	// CrescentPairResponse defines the response of the pair query.
+	CrescentPairResponse struct {
+		Pair CrescentPair `json:"pair"`
+	}
+
+	// CrescentPair defines a pair of the liquidity module, whose last price
+	// is the price of the base coin in quote coin, in their atomic units.
+	CrescentPair struct {
+		ID             string `json:"id"`
+		BaseCoinDenom  string `json:"base_coin_denom"`
+		QuoteCoinDenom string `json:"quote_coin_denom"`
+		LastPrice      string `json:"last_price"`
+	}
+)
+
+// NewCrescentProvider returns a Crescent provider querying the pairs of the
+// endpoint through the node REST API at its REST URL, or a public one.
+func NewCrescentProvider(endpoint Endpoint) *CrescentProvider {
+	p := &CrescentProvider{
+		baseURL: crescentRestURL,
+		client:  newDefaultHTTPClient(),
+		pools:   make(map[string]Pool, len(endpoint.Pools)),
+		heights: make(map[string]int64, len(endpoint.Pools)),
+		candles: make(map[string][]types.CandlePrice, len(endpoint.Pools)),
+	}
+	if endpoint.Name == Crescent && len(endpoint.Rest) > 0 {
+		p.baseURL = endpoint.Rest
+	}
+	for _, pool := range endpoint.Pools {
+		cp := types.CurrencyPair{Base: pool.Base, Quote: pool.Quote}
+		p.pools[cp.String()] = pool
+	}
+
+	return p
+}
+
+// SubscribeCurrencyPairs performs a no-op since the pairs are queried on
+// every request.
+func (*CrescentProvider) SubscribeCurrencyPairs(context.Context, ...types.CurrencyPair) error {
+	return nil
+}
+
+// GetTickerPrices returns the last prices of the pairs of the given currency
+// pairs.
+func (p *CrescentProvider) GetTickerPrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
+	tickerPrices := make(map[string]types.TickerPrice, len(pairs))
+	for _, cp := range pairs {
+		price, _, err := p.price(ctx, cp)
+		if err != nil {
+			return nil, err
+		}
+
+		tickerPrices[cp.String()] = types.TickerPrice{Price: price, Volume: sdk.ZeroDec()}
+	}
+
+	return tickerPrices, nil
+}
+
+// GetCandlePrices appends a candle with the last price to the candles of the
+// given pairs, unless it was read at the height of their last candle, and
+// returns the candles within providerCandlePeriod.
+func (p *CrescentProvider) GetCandlePrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
+	candles := make(map[string][]types.CandlePrice, len(pairs))
+	for _, cp := range pairs {
+		price, height, err := p.price(ctx, cp)
+		if err != nil {
+			return nil, err
+		}
+
+		p.mtx.Lock()
+		pairCandles := p.candles[cp.String()]
+		// a node which doesn't report the height gets a candle per request
+		if height == 0 || height > p.heights[cp.String()] {
+			pairCandles = append(pairCandles, types.CandlePrice{
+				Price:     price,
+				Volume:    sdk.ZeroDec(),
+				TimeStamp: time.Now().UnixMilli(),
+			})
+			p.heights[cp.String()] = height
+		}
+
+		staleTime := PastUnixTime(providerCandlePeriod)
+		fresh := pairCandles[:0]
+		for _, candle := range pairCandles {
+			if candle.TimeStamp > staleTime {
+				fresh = append(fresh, candle)
+			}
+		}
+		p.candles[cp.String()] = fresh
+		candles[cp.String()] = append([]types.CandlePrice{}, fresh...)
+		p.mtx.Unlock()
+	}
+
+	return candles, nil
+}
+
+// price returns the last price of the pair of the currency pair, in whole
+// coins, along with the height it was read at, if known.
+func (p *CrescentProvider) price(ctx context.Context, cp types.CurrencyPair) (sdk.Dec, int64, error) {
+	pool, ok := p.pools[cp.String()]
+	if !ok {
+		return sdk.Dec{}, 0, fmt.Errorf("no Crescent pair configured for %s", cp.String())
+	}
+
+	var resp CrescentPairResponse
+	height, err := p.get(ctx, crescentPairEndpoint+strconv.FormatUint(pool.ID, 10), &resp)
+	if err != nil {
+		return sdk.Dec{}, 0, fmt.Errorf("crescent failed to query pair %d: %w", pool.ID, err)
+	}
+
+	lastPrice, err := sdk.NewDecFromStr(resp.Pair.LastPrice)
+	if err != nil || !lastPrice.IsPositive() {
+		return sdk.Dec{}, 0, fmt.Errorf("crescent pair %d has no last price", pool.ID)
+	}
+
+	switch {
+	case resp.Pair.BaseCoinDenom == pool.BaseDenom && resp.Pair.QuoteCoinDenom == pool.QuoteDenom:
+	case resp.Pair.BaseCoinDenom == pool.QuoteDenom && resp.Pair.QuoteCoinDenom == pool.BaseDenom:
+		lastPrice = sdk.OneDec().Quo(lastPrice)
+	default:
+		return sdk.Dec{}, 0, fmt.Errorf(
+			"crescent pair %d is %s/%s, not %s/%s",
+			pool.ID, resp.Pair.BaseCoinDenom, resp.Pair.QuoteCoinDenom, pool.BaseDenom, pool.QuoteDenom,
+		)
+	}
+
+	return lastPrice.Mul(pow10(pool.BaseExponent)).Quo(pow10(pool.QuoteExponent)), height, nil
+}
+
+// get sends a GET request to the endpoint, bounded by ctx, decodes the JSON
+// response into resp and returns the height it was served at, if reported.
+func (p *CrescentProvider) get(ctx context.Context, endpoint string, resp interface{}) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+endpoint, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	httpResp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer httpResp.Body.Close()
+
+	if err := checkHTTPStatus(httpResp); err != nil {
+		return 0, err
+	}
+
+	bz, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read crescent response body: %w", err)
+	}
+	capturePayload(Crescent, bz)
+
+	if err := json.Unmarshal(bz, resp); err != nil {
+		return 0, err
+	}
+
+	height, _ := strconv.ParseInt(httpResp.Header.Get(crescentHeightHeader), 10, 64)
+	return height, nil
+}
@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+func TestCrescentProvider(t *testing.T) {
+	height := int64(100)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set(crescentHeightHeader, strconv.FormatInt(height, 10))
+		switch req.URL.Path {
+		case crescentPairEndpoint + "3":
+			// bCRE/CRE, priced in atomic units with the same exponents
+			fmt.Fprint(rw, `{"pair":{"id":"3","base_coin_denom":"ubcre","quote_coin_denom":"ucre",
+				"last_price":"1.250000000000000000"}}`)
+		case crescentPairEndpoint + "12":
+			// ATOM/CRE, quoted the other way around than configured
+			fmt.Fprint(rw, `{"pair":{"id":"12","base_coin_denom":"ucre","quote_coin_denom":"ibc/ATOM",
+				"last_price":"0.025000000000000000"}}`)
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p := NewCrescentProvider(Endpoint{
+		Name: Crescent,
+		Rest: server.URL,
+		Pools: []Pool{
+			{Base: "BCRE", Quote: "CRE", ID: 3, BaseDenom: "ubcre", QuoteDenom: "ucre", BaseExponent: 6, QuoteExponent: 6},
+			{Base: "ATOM", Quote: "CRE", ID: 12, BaseDenom: "ibc/ATOM", QuoteDenom: "ucre", BaseExponent: 6, QuoteExponent: 6},
+			{Base: "FOO", Quote: "CRE", ID: 13, BaseDenom: "ufoo", QuoteDenom: "ucre"},
+		},
+	})
+	p.client = server.Client()
+
+	t.Run("ticker", func(t *testing.T) {
+		prices, err := p.GetTickerPrices(
+			context.Background(),
+			types.CurrencyPair{Base: "BCRE", Quote: "CRE"},
+			types.CurrencyPair{Base: "ATOM", Quote: "CRE"},
+		)
+		require.NoError(t, err)
+		require.Equal(t, sdk.MustNewDecFromStr("1.25"), prices["BCRECRE"].Price)
+		require.Equal(t, sdk.MustNewDecFromStr("40"), prices["ATOMCRE"].Price)
+		require.True(t, prices["ATOMCRE"].Volume.IsZero())
+	})
+
+	t.Run("candle_per_block", func(t *testing.T) {
+		pair := types.CurrencyPair{Base: "BCRE", Quote: "CRE"}
+		_, err := p.GetCandlePrices(context.Background(), pair)
+		require.NoError(t, err)
+		candles, err := p.GetCandlePrices(context.Background(), pair)
+		require.NoError(t, err)
+		require.Len(t, candles["BCRECRE"], 1)
+
+		height++
+		candles, err = p.GetCandlePrices(context.Background(), pair)
+		require.NoError(t, err)
+		require.Len(t, candles["BCRECRE"], 2)
+		require.Equal(t, sdk.MustNewDecFromStr("1.25"), candles["BCRECRE"][1].Price)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "OSMO", Quote: "CRE"})
+		require.EqualError(t, err, "no Crescent pair configured for OSMOCRE")
+
+		_, err = p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "FOO", Quote: "CRE"})
+		require.EqualError(t, err, "crescent failed to query pair 13: unexpected status: 404 Not Found")
+	})
+}
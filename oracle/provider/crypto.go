@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"net/url"
 	"strings"
 	"sync"
@@ -144,11 +143,21 @@ func NewCryptoProvider(
 		websocket.PingMessage,
 		cryptoLogger,
 	)
-	go provider.wsc.Start()
 
 	return provider, nil
 }
 
+// Start connects to the websocket in the background, see Lifecycle.
+func (p *CryptoProvider) Start() error {
+	go p.wsc.Start()
+	return nil
+}
+
+// Stop closes the websocket, see Lifecycle.
+func (p *CryptoProvider) Stop() {
+	p.wsc.Stop()
+}
+
 func (p *CryptoProvider) getSubscriptionMsgs(cps ...types.CurrencyPair) []interface{} {
 	subscriptionMsgs := make([]interface{}, 0, len(cps)*2) //nolint: gomnd //const
 	for _, cp := range cps {
@@ -167,7 +176,7 @@ func (p *CryptoProvider) getSubscriptionMsgs(cps ...types.CurrencyPair) []interf
 
 // SubscribeCurrencyPairs sends the new subscription messages to the websocket
 // and adds them to the providers subscribedPairs array.
-func (p *CryptoProvider) SubscribeCurrencyPairs(cps ...types.CurrencyPair) error {
+func (p *CryptoProvider) SubscribeCurrencyPairs(_ context.Context, cps ...types.CurrencyPair) error {
 	p.mtx.Lock()
 	defer p.mtx.Unlock()
 
@@ -187,7 +196,10 @@ func (p *CryptoProvider) SubscribeCurrencyPairs(cps ...types.CurrencyPair) error
 }
 
 // GetTickerPrices returns the tickerPrices based on the saved map.
-func (p *CryptoProvider) GetTickerPrices(pairs ...types.CurrencyPair) (map[string]types.TickerPrice, error) {
+func (p *CryptoProvider) GetTickerPrices(
+	_ context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
 	tickerPrices := make(map[string]types.TickerPrice, len(pairs))
 
 	for _, cp := range pairs {
@@ -203,7 +215,10 @@ func (p *CryptoProvider) GetTickerPrices(pairs ...types.CurrencyPair) (map[strin
 }
 
 // GetCandlePrices returns the candlePrices based on the saved map.
-func (p *CryptoProvider) GetCandlePrices(pairs ...types.CurrencyPair) (map[string][]types.CandlePrice, error) {
+func (p *CryptoProvider) GetCandlePrices(
+	_ context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
 	candlePrices := make(map[string][]types.CandlePrice, len(pairs))
 
 	for _, cp := range pairs {
@@ -388,7 +403,7 @@ func (p *CryptoProvider) setSubscribedPairs(cps ...types.CurrencyPair) {
 // GetAvailablePairs returns all pairs to which the provider can subscribe.
 // ex.: map["ATOMUSDT" => {}, "USDCUSDT" => {}].
 func (p *CryptoProvider) GetAvailablePairs() (map[string]struct{}, error) {
-	resp, err := http.Get(p.endpoints.Rest + cryptoRestPath)
+	resp, err := newDefaultHTTPClient().Get(p.endpoints.Rest + cryptoRestPath)
 	if err != nil {
 		return nil, err
 	}
@@ -33,7 +33,7 @@ func TestCryptoProvider_GetTickerPrices(t *testing.T) {
 
 		p.tickers = tickerMap
 
-		prices, err := p.GetTickerPrices(types.CurrencyPair{Base: "ATOM", Quote: "USDT"})
+		prices, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "ATOM", Quote: "USDT"})
 		require.NoError(t, err)
 		require.Len(t, prices, 1)
 		require.Equal(t, lastPrice, prices["ATOMUSDT"].Price)
@@ -59,6 +59,7 @@ func TestCryptoProvider_GetTickerPrices(t *testing.T) {
 
 		p.tickers = tickerMap
 		prices, err := p.GetTickerPrices(
+			context.Background(),
 			types.CurrencyPair{Base: "ATOM", Quote: "USDT"},
 			types.CurrencyPair{Base: "LUNA", Quote: "USDT"},
 		)
@@ -71,7 +72,7 @@ func TestCryptoProvider_GetTickerPrices(t *testing.T) {
 	})
 
 	t.Run("invalid_request_invalid_ticker", func(t *testing.T) {
-		prices, err := p.GetTickerPrices(types.CurrencyPair{Base: "FOO", Quote: "BAR"})
+		prices, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "FOO", Quote: "BAR"})
 		require.Error(t, err)
 		require.Equal(t, "crypto failed to get ticker price for FOO_BAR", err.Error())
 		require.Nil(t, prices)
@@ -100,7 +101,7 @@ func TestCryptoProvider_GetCandlePrices(t *testing.T) {
 
 		p.setCandlePair("ATOM_USDT", candle)
 
-		prices, err := p.GetCandlePrices(types.CurrencyPair{Base: "ATOM", Quote: "USDT"})
+		prices, err := p.GetCandlePrices(context.Background(), types.CurrencyPair{Base: "ATOM", Quote: "USDT"})
 		require.NoError(t, err)
 		require.Len(t, prices, 1)
 		priceDec, _ := sdk.NewDecFromStr(price)
@@ -112,7 +113,7 @@ func TestCryptoProvider_GetCandlePrices(t *testing.T) {
 	})
 
 	t.Run("invalid_request_invalid_candle", func(t *testing.T) {
-		prices, err := p.GetCandlePrices(types.CurrencyPair{Base: "FOO", Quote: "BAR"})
+		prices, err := p.GetCandlePrices(context.Background(), types.CurrencyPair{Base: "FOO", Quote: "BAR"})
 		require.EqualError(t, err, "crypto failed to get candle price for FOO_BAR")
 		require.Nil(t, prices)
 	})
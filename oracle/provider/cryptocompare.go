@@ -0,0 +1,218 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+const (
+	cryptoCompareRestURL         = "https://min-api.cryptocompare.com"
+	cryptoComparePriceEndpoint   = "/data/pricemultifull"
+	cryptoCompareHistoEndpoint   = "/data/v2/histominute"
+	cryptoCompareResponseError   = "Error"
+	cryptoCompareCandleDuration  = time.Minute
+	cryptoCompareAuthHeader      = "Authorization"
+	cryptoCompareAuthHeaderValue = "Apikey "
+)
+
+// cryptoCompareCandleCount is the number of one minute candles requested per
+// pair, covering the candle period of the providers.
+var cryptoCompareCandleCount = int(providerCandlePeriod / cryptoCompareCandleDuration)
+
+var _ Provider = (*CryptoCompareProvider)(nil)
+
+type (
+	// CryptoCompareProvider defines an Oracle provider implemented by the
+	// CryptoCompare API, whose prices and candles aggregate many exchanges,
+	// which makes it a convenient fallback when the connections to single
+	// exchanges are degraded. An API key, optional, raises its rate limits.
+	//
+	// REF: https://min-api.cryptocompare.com/documentation
+	CryptoCompareProvider struct {
+		baseURL string
+		apiKey  string
+		client  *http.Client
+	}
+
+	// CryptoComparePriceResponse defines the response structure of the
+	// prices of multiple symbols, keyed by base and quote.
+	CryptoComparePriceResponse struct {
+		Response string                                       `json:"Response"` // Only set on error
+		Message  string                                       `json:"Message"`
+		Raw      map[string]map[string]CryptoCompareRawTicker `json:"RAW"`
+	}
+
+	// CryptoCompareRawTicker defines the aggregated ticker of a pair.
+	CryptoCompareRawTicker struct {
+		Price  float64 `json:"PRICE"`        // Last price ex.: 10.41
+		Volume float64 `json:"VOLUME24HOUR"` // Volume of the base over 24h
+	}
+
+	// CryptoCompareHistoResponse defines the response structure of the
+	// candles of a pair, ordered from the oldest.
+	CryptoCompareHistoResponse struct {
+		Response string `json:"Response"`
+		Message  string `json:"Message"`
+		Data     struct {
+			Data []CryptoCompareCandle `json:"Data"`
+		} `json:"Data"`
+	}
+
+	// CryptoCompareCandle defines a one minute candle of a pair.
+	CryptoCompareCandle struct {
+		Time   int64   `json:"time"`       // Start time in unix seconds
+		Close  float64 `json:"close"`      // Price at close
+		Volume float64 `json:"volumefrom"` // Volume of the base during period
+	}
+)
+
+// NewCryptoCompareProvider returns a CryptoCompare provider using the REST
+// URL of the endpoint, or the public API, authenticated with the API key of
+// the endpoint if any.
+func NewCryptoCompareProvider(endpoint Endpoint) *CryptoCompareProvider {
+	p := &CryptoCompareProvider{
+		baseURL: cryptoCompareRestURL,
+		apiKey:  endpoint.APIKey,
+		client:  newDefaultHTTPClient(),
+	}
+	if endpoint.Name == CryptoCompare && len(endpoint.Rest) > 0 {
+		p.baseURL = endpoint.Rest
+	}
+
+	return p
+}
+
+// SubscribeCurrencyPairs performs a no-op since CryptoCompare is requested on
+// demand.
+func (*CryptoCompareProvider) SubscribeCurrencyPairs(context.Context, ...types.CurrencyPair) error {
+	return nil
+}
+
+// GetTickerPrices returns the aggregated tickers of the given pairs, all
+// requested at once.
+func (p *CryptoCompareProvider) GetTickerPrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
+	var (
+		bases  = make([]string, 0, len(pairs))
+		quotes = make([]string, 0, len(pairs))
+		seen   = make(map[string]struct{}, 2*len(pairs))
+	)
+	for _, cp := range pairs {
+		if _, ok := seen["base:"+cp.Base]; !ok {
+			seen["base:"+cp.Base] = struct{}{}
+			bases = append(bases, cp.Base)
+		}
+		if _, ok := seen["quote:"+cp.Quote]; !ok {
+			seen["quote:"+cp.Quote] = struct{}{}
+			quotes = append(quotes, cp.Quote)
+		}
+	}
+
+	query := url.Values{}
+	query.Set("fsyms", strings.Join(bases, ","))
+	query.Set("tsyms", strings.Join(quotes, ","))
+
+	var resp CryptoComparePriceResponse
+	if err := p.get(ctx, cryptoComparePriceEndpoint, query, &resp); err != nil {
+		return nil, fmt.Errorf("cryptocompare failed to get prices: %w", err)
+	}
+	if resp.Response == cryptoCompareResponseError {
+		return nil, fmt.Errorf("cryptocompare failed to get prices: %s", resp.Message)
+	}
+
+	tickerPrices := make(map[string]types.TickerPrice, len(pairs))
+	for _, cp := range pairs {
+		ticker, ok := resp.Raw[cp.Base][cp.Quote]
+		if !ok || ticker.Price <= 0 {
+			return nil, fmt.Errorf(types.ErrMissingExchangeRate, cp.String())
+		}
+
+		tickerPrices[cp.String()] = types.TickerPrice{
+			Price:  floatToDec(ticker.Price),
+			Volume: floatToDec(ticker.Volume),
+		}
+	}
+
+	return tickerPrices, nil
+}
+
+// GetCandlePrices returns the one minute candles of the given pairs within
+// providerCandlePeriod, skipping those without trades.
+func (p *CryptoCompareProvider) GetCandlePrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
+	candles := make(map[string][]types.CandlePrice, len(pairs))
+	for _, cp := range pairs {
+		query := url.Values{}
+		query.Set("fsym", cp.Base)
+		query.Set("tsym", cp.Quote)
+		query.Set("limit", fmt.Sprint(cryptoCompareCandleCount))
+
+		var resp CryptoCompareHistoResponse
+		if err := p.get(ctx, cryptoCompareHistoEndpoint, query, &resp); err != nil {
+			return nil, fmt.Errorf("cryptocompare failed to get %s candles: %w", cp.String(), err)
+		}
+		if resp.Response == cryptoCompareResponseError {
+			return nil, fmt.Errorf("cryptocompare failed to get %s candles: %s", cp.String(), resp.Message)
+		}
+
+		staleTime := PastUnixTime(providerCandlePeriod)
+		pairCandles := make([]types.CandlePrice, 0, len(resp.Data.Data))
+		for _, candle := range resp.Data.Data {
+			end := time.Unix(candle.Time, 0).Add(cryptoCompareCandleDuration).UnixMilli()
+			if candle.Close <= 0 || candle.Volume <= 0 || end <= staleTime {
+				continue
+			}
+
+			pairCandles = append(pairCandles, types.CandlePrice{
+				Price:     floatToDec(candle.Close),
+				Volume:    floatToDec(candle.Volume),
+				TimeStamp: end,
+			})
+		}
+		candles[cp.String()] = pairCandles
+	}
+
+	return candles, nil
+}
+
+// get requests the endpoint with the query, bounded by ctx, and decodes the
+// JSON response into resp.
+func (p *CryptoCompareProvider) get(ctx context.Context, endpoint string, query url.Values, resp interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	if len(p.apiKey) > 0 {
+		req.Header.Set(cryptoCompareAuthHeader, cryptoCompareAuthHeaderValue+p.apiKey)
+	}
+
+	httpResp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+
+	if err := checkHTTPStatus(httpResp); err != nil {
+		return err
+	}
+
+	bz, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read cryptocompare response body: %w", err)
+	}
+	capturePayload(CryptoCompare, bz)
+
+	return json.Unmarshal(bz, resp)
+}
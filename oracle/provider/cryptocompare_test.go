@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+func TestCryptoCompareProvider(t *testing.T) {
+	var (
+		minute = time.Now().Truncate(time.Minute).Unix()
+		stale  = time.Now().Add(-2 * providerCandlePeriod).Unix()
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		require.Equal(t, "Apikey secret", req.Header.Get(cryptoCompareAuthHeader))
+
+		switch req.URL.Path {
+		case cryptoComparePriceEndpoint:
+			if req.URL.Query().Get("tsyms") == "USD" {
+				require.Equal(t, "ATOM,OSMO", req.URL.Query().Get("fsyms"))
+			}
+			fmt.Fprint(rw, `{"RAW":{"ATOM":{"USD":{"PRICE":10.41,"VOLUME24HOUR":1200}},`+
+				`"OSMO":{"USD":{"PRICE":0.5,"VOLUME24HOUR":300}}}}`)
+
+		case cryptoCompareHistoEndpoint:
+			if req.URL.Query().Get("fsym") != "ATOM" {
+				fmt.Fprint(rw, `{"Response":"Error","Message":"market does not exist"}`)
+				return
+			}
+			fmt.Fprintf(rw, `{"Response":"Success","Data":{"Data":[`+
+				`{"time":%d,"close":9,"volumefrom":100},`+
+				`{"time":%d,"close":10.4,"volumefrom":0},`+
+				`{"time":%d,"close":10.41,"volumefrom":12}]}}`,
+				stale, minute-60, minute)
+
+		default:
+			t.Fatalf("unexpected request %s", req.URL)
+		}
+	}))
+	defer server.Close()
+
+	p := NewCryptoCompareProvider(Endpoint{Name: CryptoCompare, Rest: server.URL, APIKey: "secret"})
+	p.client = server.Client()
+	atom := types.CurrencyPair{Base: "ATOM", Quote: "USD"}
+	osmo := types.CurrencyPair{Base: "OSMO", Quote: "USD"}
+
+	t.Run("ticker", func(t *testing.T) {
+		prices, err := p.GetTickerPrices(context.Background(), atom, osmo)
+		require.NoError(t, err)
+		require.Equal(t, types.TickerPrice{Price: sdk.MustNewDecFromStr("10.41"), Volume: sdk.NewDec(1200)},
+			prices["ATOMUSD"])
+		require.Equal(t, types.TickerPrice{Price: sdk.MustNewDecFromStr("0.5"), Volume: sdk.NewDec(300)},
+			prices["OSMOUSD"])
+
+		_, err = p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "ATOM", Quote: "USDT"})
+		require.ErrorContains(t, err, "missing exchange rate for ATOMUSDT")
+	})
+
+	t.Run("candles", func(t *testing.T) {
+		candles, err := p.GetCandlePrices(context.Background(), atom)
+		require.NoError(t, err)
+		require.Equal(t, []types.CandlePrice{
+			{Price: sdk.MustNewDecFromStr("10.41"), Volume: sdk.NewDec(12), TimeStamp: (minute + 60) * 1000},
+		}, candles["ATOMUSD"])
+
+		_, err = p.GetCandlePrices(context.Background(), osmo)
+		require.EqualError(t, err, "cryptocompare failed to get OSMOUSD candles: market does not exist")
+	})
+}
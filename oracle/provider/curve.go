@@ -0,0 +1,226 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+const (
+	curveRPCURL = "https://cloudflare-eth.com"
+
+	// curveMaxCoins is the maximum number of coins of a Curve pool.
+	curveMaxCoins = 8
+)
+
+var (
+	// curveGetDySelector is the selector of get_dy(int128,int128,uint256),
+	// the amount of coin j received for an amount of coin i.
+	curveGetDySelector = []byte{0x5e, 0x0d, 0x44, 0x3f}
+	// curveGetVirtualPriceSelector is the selector of get_virtual_price().
+	curveGetVirtualPriceSelector = []byte{0xbb, 0x7b, 0x8b, 0x80}
+	// curveCoinsSelector is the selector of coins(uint256).
+	curveCoinsSelector = []byte{0xc6, 0x61, 0x06, 0x57}
+)
+
+var _ Provider = (*CurveProvider)(nil)
+
+type (
+	// CurveProvider defines an Oracle provider reading the Curve stable pools
+	// configured through an Ethereum RPC endpoint, so that stablecoins can be
+	// priced from on-chain liquidity. Every pool is identified by its
+	// Contract address, its BaseDenom and QuoteDenom are the addresses of the
+	// base and quote coins and its exponents their decimals.
+	//
+	// Ticker prices are the swap rates of a whole base coin, from get_dy. A
+	// pair whose base denom is the pool address, i.e. its LP token, is priced
+	// with the pool virtual price instead. Curve pools don't expose their
+	// traded volume, so the volumes are zero and candles are synthetic: one is
+	// built per request from the ticker price.
+	//
+	// REF: https://docs.curve.fi/stableswap-exchange/stableswap/pools/plain_pools/
+	CurveProvider struct {
+		baseURL string
+		client  *http.Client
+		pools   map[string]Pool // CurrencyPair.String() => Pool
+
+		mtx     sync.Mutex
+		indexes map[string][2]int64            // CurrencyPair.String() => coin indexes
+		candles map[string][]types.CandlePrice // CurrencyPair.String() => candles
+	}
+)
+
+// NewCurveProvider returns a Curve provider calling the pools of the endpoint
+// through the Ethereum RPC endpoint at its REST URL, or a public one.
+func NewCurveProvider(endpoint Endpoint) *CurveProvider {
+	p := &CurveProvider{
+		baseURL: curveRPCURL,
+		client:  newDefaultHTTPClient(),
+		pools:   make(map[string]Pool, len(endpoint.Pools)),
+		indexes: make(map[string][2]int64, len(endpoint.Pools)),
+		candles: make(map[string][]types.CandlePrice, len(endpoint.Pools)),
+	}
+	if endpoint.Name == Curve && len(endpoint.Rest) > 0 {
+		p.baseURL = endpoint.Rest
+	}
+	for _, pool := range endpoint.Pools {
+		cp := types.CurrencyPair{Base: pool.Base, Quote: pool.Quote}
+		p.pools[cp.String()] = pool
+	}
+
+	return p
+}
+
+// SubscribeCurrencyPairs performs a no-op since the pools are called on every
+// request.
+func (*CurveProvider) SubscribeCurrencyPairs(context.Context, ...types.CurrencyPair) error {
+	return nil
+}
+
+// GetTickerPrices returns the swap rates of the pools of the given pairs.
+func (p *CurveProvider) GetTickerPrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
+	tickerPrices := make(map[string]types.TickerPrice, len(pairs))
+	for _, cp := range pairs {
+		price, err := p.price(ctx, cp)
+		if err != nil {
+			return nil, err
+		}
+
+		tickerPrices[cp.String()] = types.TickerPrice{Price: price, Volume: sdk.ZeroDec()}
+	}
+
+	return tickerPrices, nil
+}
+
+// GetCandlePrices appends a candle with the current swap rate to the candles
+// of the given pairs and returns the candles within providerCandlePeriod.
+func (p *CurveProvider) GetCandlePrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
+	candles := make(map[string][]types.CandlePrice, len(pairs))
+	for _, cp := range pairs {
+		price, err := p.price(ctx, cp)
+		if err != nil {
+			return nil, err
+		}
+
+		p.mtx.Lock()
+		pairCandles := append(p.candles[cp.String()], types.CandlePrice{
+			Price:     price,
+			Volume:    sdk.ZeroDec(),
+			TimeStamp: time.Now().UnixMilli(),
+		})
+
+		staleTime := PastUnixTime(providerCandlePeriod)
+		fresh := pairCandles[:0]
+		for _, candle := range pairCandles {
+			if candle.TimeStamp > staleTime {
+				fresh = append(fresh, candle)
+			}
+		}
+		p.candles[cp.String()] = fresh
+		candles[cp.String()] = append([]types.CandlePrice{}, fresh...)
+		p.mtx.Unlock()
+	}
+
+	return candles, nil
+}
+
+// price returns the price of the pair base in quote, from the swap rate of a
+// whole base coin or the pool virtual price.
+func (p *CurveProvider) price(ctx context.Context, cp types.CurrencyPair) (sdk.Dec, error) {
+	pool, ok := p.pools[cp.String()]
+	if !ok {
+		return sdk.Dec{}, fmt.Errorf("no Curve pool configured for %s", cp.String())
+	}
+
+	if strings.EqualFold(pool.BaseDenom, pool.Contract) {
+		virtualPrice, err := p.call(ctx, pool.Contract, curveGetVirtualPriceSelector)
+		if err != nil {
+			return sdk.Dec{}, fmt.Errorf("curve failed to get pool %s virtual price: %w", pool.Contract, err)
+		}
+		// the virtual price has 18 decimals
+		return sdk.NewDecFromBigIntWithPrec(virtualPrice, sdk.Precision), nil
+	}
+
+	indexes, err := p.coinIndexes(ctx, cp, pool)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+
+	dx := new(big.Int).Exp(big.NewInt(10), big.NewInt(pool.BaseExponent), nil) //nolint:gomnd //decimal base
+	dy, err := p.call(ctx, pool.Contract, curveGetDySelector, big.NewInt(indexes[0]), big.NewInt(indexes[1]), dx)
+	if err != nil {
+		return sdk.Dec{}, fmt.Errorf("curve failed to get pool %s swap rate: %w", pool.Contract, err)
+	}
+	if dy.Sign() <= 0 {
+		return sdk.Dec{}, fmt.Errorf("curve pool %s has no liquidity for %s", pool.Contract, cp.String())
+	}
+
+	return scalePoolAmount(sdk.NewIntFromBigInt(dy), pool.QuoteExponent), nil
+}
+
+// coinIndexes returns the indexes of the base and quote coins in the pool,
+// looked up once from its coins.
+func (p *CurveProvider) coinIndexes(ctx context.Context, cp types.CurrencyPair, pool Pool) ([2]int64, error) {
+	p.mtx.Lock()
+	indexes, ok := p.indexes[cp.String()]
+	p.mtx.Unlock()
+	if ok {
+		return indexes, nil
+	}
+
+	indexes = [2]int64{-1, -1}
+	for i := int64(0); i < curveMaxCoins && (indexes[0] < 0 || indexes[1] < 0); i++ {
+		coin, err := p.call(ctx, pool.Contract, curveCoinsSelector, big.NewInt(i))
+		if err != nil {
+			// calling coins past the last coin of the pool reverts
+			break
+		}
+
+		address := fmt.Sprintf("0x%040x", coin)
+		switch {
+		case strings.EqualFold(address, pool.BaseDenom):
+			indexes[0] = i
+		case strings.EqualFold(address, pool.QuoteDenom):
+			indexes[1] = i
+		}
+	}
+	if indexes[0] < 0 || indexes[1] < 0 {
+		return indexes, fmt.Errorf("curve pool %s doesn't hold both coins of %s", pool.Contract, cp.String())
+	}
+
+	p.mtx.Lock()
+	p.indexes[cp.String()] = indexes
+	p.mtx.Unlock()
+
+	return indexes, nil
+}
+
+// call calls the contract method with the given selector and uint256 args
+// and returns the first word of its result.
+func (p *CurveProvider) call(
+	ctx context.Context,
+	contract string,
+	selector []byte,
+	args ...*big.Int,
+) (*big.Int, error) {
+	words, err := ethCall(ctx, p.client, p.baseURL, Curve, contract, selector, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return words[0], nil
+}
@@ -0,0 +1,140 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+const (
+	testCurvePool = "0xbebc44782c7db0a1a60cb6fe97d0b483032ff1c7"
+	testCurveDAI  = "0x6b175474e89094c44da98b954eedeac495271d0f"
+	testCurveUSDC = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	testCurveUSDT = "0xdac17f958d2ee523a2206206994597c13d831ec7"
+)
+
+// newTestCurveNode serves eth_call for a pool holding DAI, USDC and USDT,
+// swapping a whole USDT for 0.9995 USDC.
+func newTestCurveNode(t *testing.T) *httptest.Server {
+	coins := []string{testCurveDAI, testCurveUSDC, testCurveUSDT}
+
+	word := func(v *big.Int) string {
+		return "0x" + hex.EncodeToString(v.FillBytes(make([]byte, 32)))
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var ethReq struct {
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&ethReq))
+
+		var params ethCallParams
+		require.NoError(t, json.Unmarshal(ethReq.Params[0], &params))
+		require.Equal(t, testCurvePool, params.To)
+
+		data, err := hex.DecodeString(strings.TrimPrefix(params.Data, "0x"))
+		require.NoError(t, err)
+		arg := func(i int) *big.Int { return new(big.Int).SetBytes(data[4+32*i : 4+32*(i+1)]) }
+
+		switch {
+		case bytes.HasPrefix(data, curveCoinsSelector):
+			i := arg(0).Int64()
+			if i >= int64(len(coins)) {
+				fmt.Fprint(rw, `{"jsonrpc":"2.0","id":1,"error":{"code":3,"message":"execution reverted"}}`)
+				return
+			}
+			coin, _ := new(big.Int).SetString(strings.TrimPrefix(coins[i], "0x"), 16)
+			fmt.Fprintf(rw, `{"jsonrpc":"2.0","id":1,"result":"%s"}`, word(coin))
+
+		case bytes.HasPrefix(data, curveGetDySelector):
+			require.Equal(t, int64(2), arg(0).Int64())
+			require.Equal(t, int64(1), arg(1).Int64())
+			require.Equal(t, int64(1_000_000), arg(2).Int64())
+			fmt.Fprintf(rw, `{"jsonrpc":"2.0","id":1,"result":"%s"}`, word(big.NewInt(999_500)))
+
+		case bytes.HasPrefix(data, curveGetVirtualPriceSelector):
+			virtualPrice, _ := new(big.Int).SetString("1025000000000000000", 10)
+			fmt.Fprintf(rw, `{"jsonrpc":"2.0","id":1,"result":"%s"}`, word(virtualPrice))
+
+		default:
+			t.Fatalf("unexpected call %s", params.Data)
+		}
+	}))
+}
+
+func TestCurveProvider(t *testing.T) {
+	server := newTestCurveNode(t)
+	defer server.Close()
+
+	p := NewCurveProvider(Endpoint{
+		Name: Curve,
+		Rest: server.URL,
+		Pools: []Pool{
+			{
+				Base:          "USDT",
+				Quote:         "USDC",
+				Contract:      testCurvePool,
+				BaseDenom:     testCurveUSDT,
+				QuoteDenom:    testCurveUSDC,
+				BaseExponent:  6,
+				QuoteExponent: 6,
+			},
+			{
+				Base:       "3CRV",
+				Quote:      "USD",
+				Contract:   testCurvePool,
+				BaseDenom:  testCurvePool,
+				QuoteDenom: testCurveUSDC,
+			},
+			{
+				Base:       "FRAX",
+				Quote:      "USDC",
+				Contract:   testCurvePool,
+				BaseDenom:  "0x853d955acef822db058eb8505911ed77f175b99e",
+				QuoteDenom: testCurveUSDC,
+			},
+		},
+	})
+	p.client = server.Client()
+
+	t.Run("ticker_is_swap_rate", func(t *testing.T) {
+		prices, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "USDT", Quote: "USDC"})
+		require.NoError(t, err)
+		require.Equal(t, sdk.MustNewDecFromStr("0.9995"), prices["USDTUSDC"].Price)
+		require.True(t, prices["USDTUSDC"].Volume.IsZero())
+		require.Equal(t, [2]int64{2, 1}, p.indexes["USDTUSDC"])
+	})
+
+	t.Run("lp_token_is_virtual_price", func(t *testing.T) {
+		prices, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "3CRV", Quote: "USD"})
+		require.NoError(t, err)
+		require.Equal(t, sdk.MustNewDecFromStr("1.025"), prices["3CRVUSD"].Price)
+	})
+
+	t.Run("candles", func(t *testing.T) {
+		pair := types.CurrencyPair{Base: "USDT", Quote: "USDC"}
+		_, err := p.GetCandlePrices(context.Background(), pair)
+		require.NoError(t, err)
+		candles, err := p.GetCandlePrices(context.Background(), pair)
+		require.NoError(t, err)
+		require.Len(t, candles["USDTUSDC"], 2)
+		require.Equal(t, sdk.MustNewDecFromStr("0.9995"), candles["USDTUSDC"][1].Price)
+	})
+
+	t.Run("coin_not_in_pool", func(t *testing.T) {
+		_, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "FRAX", Quote: "USDC"})
+		require.EqualError(t, err, "curve pool "+testCurvePool+" doesn't hold both coins of FRAXUSDC")
+	})
+}
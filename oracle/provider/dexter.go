@@ -0,0 +1,303 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+const wasmSmartContractStateMethod = "/cosmwasm.wasm.v1.Query/SmartContractState"
+
+var _ Provider = (*DexterProvider)(nil)
+
+type (
+	// DexterProvider defines an Oracle provider querying the Dexter pool
+	// contracts of a Persistence node over the CosmWasm smart query gRPC
+	// service, so that the liquidity of the native chain, e.g. of XPRT and the
+	// stk assets, is part of the aggregation.
+	//
+	// Ticker prices are the pool spot prices. Candles are synthetic: one is
+	// built per request from the pool cumulative price, i.e. the TWAP since
+	// the previous candle, or from the spot price when the cumulative price
+	// didn't move. Dexter pools don't expose their traded volume, so the
+	// volumes are zero and weighted as the minimum candle volume.
+	//
+	// REF: https://github.com/dexter-zone/dexter_core/tree/main/packages/dexter/src/pool.rs
+	DexterProvider struct {
+		conn  *grpc.ClientConn
+		mtx   sync.Mutex
+		pools map[string]Pool             // CurrencyPair.String() => Pool
+		state map[string]*dexterPoolState // CurrencyPair.String() => state
+	}
+
+	// dexterPoolState defines the candles built for a pool and the pool
+	// cumulative price they were built from.
+	dexterPoolState struct {
+		candles        []types.CandlePrice
+		lastTime       time.Time
+		lastCumulative sdk.Int
+	}
+
+	// dexterQueryMsg defines the Dexter pool QueryMsg variants the provider
+	// uses, of which a single one is set.
+	dexterQueryMsg struct {
+		SpotPrice       *dexterAssetsQuery `json:"spot_price,omitempty"`
+		CumulativePrice *dexterAssetsQuery `json:"cumulative_price,omitempty"`
+	}
+
+	dexterAssetsQuery struct {
+		OfferAsset dexterAssetInfo `json:"offer_asset"`
+		AskAsset   dexterAssetInfo `json:"ask_asset"`
+	}
+
+	dexterAssetInfo struct {
+		NativeToken dexterNativeToken `json:"native_token"`
+	}
+
+	dexterNativeToken struct {
+		Denom string `json:"denom"`
+	}
+
+	// dexterSpotPriceResponse defines the SpotPrice response, the price of
+	// the offer denom in ask denom.
+	dexterSpotPriceResponse struct {
+		Price sdk.Dec `json:"price"`
+	}
+
+	// dexterCumulativePriceResponse defines the CumulativePriceResponse, the
+	// price of the offer denom in ask denom, as decimal atomics, accumulated
+	// over every second of the pool lifetime.
+	dexterCumulativePriceResponse struct {
+		CumulativePrice sdk.Int `json:"cumulative_price"`
+	}
+
+	// wasmSmartQueryRequest defines the wasm QuerySmartContractStateRequest.
+	wasmSmartQueryRequest struct {
+		Address   string
+		QueryData []byte
+	}
+
+	// wasmSmartQueryResponse defines the wasm QuerySmartContractStateResponse,
+	// whose data is the JSON response of the contract.
+	wasmSmartQueryResponse struct {
+		Data []byte
+	}
+)
+
+// NewDexterProvider returns a Dexter provider querying the pool contracts of
+// the node at the endpoint gRPC address.
+func NewDexterProvider(endpoint Endpoint) (*DexterProvider, error) {
+	if len(endpoint.GRPC) == 0 {
+		return nil, fmt.Errorf("dexter requires the gRPC endpoint of a Persistence node")
+	}
+
+	conn, err := grpc.Dial(
+		endpoint.GRPC,
+		// the Cosmos SDK doesn't support any transport security mechanism
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(nodeCodec{})),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial Persistence gRPC service: %w", err)
+	}
+
+	return newDexterProvider(conn, endpoint.Pools), nil
+}
+
+func newDexterProvider(conn *grpc.ClientConn, pools []Pool) *DexterProvider {
+	p := &DexterProvider{
+		conn:  conn,
+		pools: make(map[string]Pool, len(pools)),
+		state: make(map[string]*dexterPoolState, len(pools)),
+	}
+	for _, pool := range pools {
+		cp := types.CurrencyPair{Base: pool.Base, Quote: pool.Quote}
+		p.pools[cp.String()] = pool
+		p.state[cp.String()] = &dexterPoolState{}
+	}
+
+	return p
+}
+
+// SubscribeCurrencyPairs performs a no-op since the pools are queried on
+// every request.
+func (*DexterProvider) SubscribeCurrencyPairs(context.Context, ...types.CurrencyPair) error {
+	return nil
+}
+
+// GetTickerPrices returns the spot prices of the pools of the given pairs.
+func (p *DexterProvider) GetTickerPrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	tickerPrices := make(map[string]types.TickerPrice, len(pairs))
+	for _, cp := range pairs {
+		pool, ok := p.pools[cp.String()]
+		if !ok {
+			return nil, fmt.Errorf("no Dexter pool configured for %s", cp.String())
+		}
+
+		price, err := p.spotPrice(ctx, pool)
+		if err != nil {
+			return nil, err
+		}
+
+		tickerPrices[cp.String()] = types.TickerPrice{
+			Price:  price,
+			Volume: sdk.ZeroDec(),
+		}
+	}
+
+	return tickerPrices, nil
+}
+
+// GetCandlePrices appends a candle with the TWAP since the previous candle to
+// the candles of the given pairs and returns the candles within
+// providerCandlePeriod.
+func (p *DexterProvider) GetCandlePrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	candles := make(map[string][]types.CandlePrice, len(pairs))
+	for _, cp := range pairs {
+		pool, ok := p.pools[cp.String()]
+		if !ok {
+			return nil, fmt.Errorf("no Dexter pool configured for %s", cp.String())
+		}
+		state := p.state[cp.String()]
+
+		query := dexterQueryMsg{CumulativePrice: newDexterAssetsQuery(pool)}
+		var cumulativeResp dexterCumulativePriceResponse
+		if err := p.smartQuery(ctx, pool, query, &cumulativeResp); err != nil {
+			return nil, fmt.Errorf("failed to query Dexter pool %s cumulative price: %w", pool.Contract, err)
+		}
+		cumulative := cumulativeResp.CumulativePrice
+		now := time.Now()
+
+		var price sdk.Dec
+		elapsed := now.Sub(state.lastTime).Milliseconds()
+		if !state.lastCumulative.IsNil() && !cumulative.IsNil() && cumulative.GT(state.lastCumulative) && elapsed > 0 {
+			twap := sdk.NewDecFromBigIntWithPrec(cumulative.Sub(state.lastCumulative).BigInt(), sdk.Precision).
+				MulInt64(time.Second.Milliseconds()).
+				QuoInt64(elapsed)
+			price = scalePoolPrice(pool, twap)
+		} else {
+			// the first candle has no previous cumulative price, and the
+			// cumulative price doesn't move without swaps
+			var err error
+			if price, err = p.spotPrice(ctx, pool); err != nil {
+				return nil, err
+			}
+		}
+
+		state.candles = append(state.candles, types.CandlePrice{
+			Price:     price,
+			Volume:    sdk.ZeroDec(),
+			TimeStamp: now.UnixMilli(),
+		})
+		state.lastTime = now
+		state.lastCumulative = cumulative
+
+		staleTime := PastUnixTime(providerCandlePeriod)
+		fresh := state.candles[:0]
+		for _, candle := range state.candles {
+			if candle.TimeStamp > staleTime {
+				fresh = append(fresh, candle)
+			}
+		}
+		state.candles = fresh
+
+		candles[cp.String()] = append([]types.CandlePrice{}, state.candles...)
+	}
+
+	return candles, nil
+}
+
+// spotPrice returns the spot price of the pool base symbol in quote symbol.
+func (p *DexterProvider) spotPrice(ctx context.Context, pool Pool) (sdk.Dec, error) {
+	var resp dexterSpotPriceResponse
+	if err := p.smartQuery(ctx, pool, dexterQueryMsg{SpotPrice: newDexterAssetsQuery(pool)}, &resp); err != nil {
+		return sdk.Dec{}, fmt.Errorf("failed to query Dexter pool %s spot price: %w", pool.Contract, err)
+	}
+	if resp.Price.IsNil() || !resp.Price.IsPositive() {
+		return sdk.Dec{}, fmt.Errorf("invalid Dexter pool %s spot price", pool.Contract)
+	}
+
+	return scalePoolPrice(pool, resp.Price), nil
+}
+
+// smartQuery sends the JSON encoded query to the pool contract and decodes
+// its JSON response into resp.
+func (p *DexterProvider) smartQuery(ctx context.Context, pool Pool, query dexterQueryMsg, resp interface{}) error {
+	bz, err := json.Marshal(query)
+	if err != nil {
+		return err
+	}
+
+	req := wasmSmartQueryRequest{Address: pool.Contract, QueryData: bz}
+	var wasmResp wasmSmartQueryResponse
+	if err := p.conn.Invoke(ctx, wasmSmartContractStateMethod, &req, &wasmResp); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(wasmResp.Data, resp)
+}
+
+// newDexterAssetsQuery returns the query of the pool base denom in quote
+// denom.
+func newDexterAssetsQuery(pool Pool) *dexterAssetsQuery {
+	return &dexterAssetsQuery{
+		OfferAsset: dexterAssetInfo{NativeToken: dexterNativeToken{Denom: pool.BaseDenom}},
+		AskAsset:   dexterAssetInfo{NativeToken: dexterNativeToken{Denom: pool.QuoteDenom}},
+	}
+}
+
+func (m *wasmSmartQueryRequest) Marshal() ([]byte, error) {
+	var b []byte
+	b = appendStringField(b, 1, m.Address)
+	b = appendStringField(b, 2, string(m.QueryData))
+	return b, nil
+}
+
+func (m *wasmSmartQueryRequest) Unmarshal(data []byte) error {
+	return unmarshalFields(data, func(num protowire.Number, _ uint64, bz []byte) error {
+		switch num {
+		case 1:
+			m.Address = string(bz)
+		case 2:
+			m.QueryData = append([]byte{}, bz...)
+		}
+		return nil
+	})
+}
+
+func (m *wasmSmartQueryResponse) Marshal() ([]byte, error) {
+	return appendStringField(nil, 1, string(m.Data)), nil
+}
+
+func (m *wasmSmartQueryResponse) Unmarshal(data []byte) error {
+	return unmarshalFields(data, func(num protowire.Number, _ uint64, bz []byte) error {
+		if num == 1 {
+			m.Data = append([]byte{}, bz...)
+		}
+		return nil
+	})
+}
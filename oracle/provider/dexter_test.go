@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+const testDexterPool = "persistence1dexterpool"
+
+// newTestDexterNode serves the Dexter pool queries with the given cumulative
+// price, returned by pointer so that tests can update it.
+func newTestDexterNode(t *testing.T, cumulative *sdk.Int) *grpc.ClientConn {
+	handler := func(_ interface{}, stream grpc.ServerStream) error {
+		method, _ := grpc.MethodFromServerStream(stream)
+		require.Equal(t, wasmSmartContractStateMethod, method)
+
+		var raw rawNodeMessage
+		if err := stream.RecvMsg(&raw); err != nil {
+			return err
+		}
+
+		var req wasmSmartQueryRequest
+		require.NoError(t, req.Unmarshal(raw))
+		require.Equal(t, testDexterPool, req.Address)
+
+		var query dexterQueryMsg
+		require.NoError(t, json.Unmarshal(req.QueryData, &query))
+
+		var resp interface{}
+		switch {
+		case query.SpotPrice != nil:
+			require.Equal(t, "uxprt", query.SpotPrice.OfferAsset.NativeToken.Denom)
+			require.Equal(t, "uusdc", query.SpotPrice.AskAsset.NativeToken.Denom)
+			resp = map[string]string{"price": "0.5"}
+
+		case query.CumulativePrice != nil:
+			resp = map[string]string{"cumulative_price": cumulative.String()}
+		}
+
+		bz, err := json.Marshal(resp)
+		require.NoError(t, err)
+		return stream.SendMsg(&wasmSmartQueryResponse{Data: bz})
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer(grpc.ForceServerCodec(nodeCodec{}), grpc.UnknownServiceHandler(handler))
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial(
+		listener.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(nodeCodec{})),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func TestDexterProvider(t *testing.T) {
+	cumulative := sdk.NewInt(1_000_000)
+	p := newDexterProvider(newTestDexterNode(t, &cumulative), []Pool{{
+		Base:          "XPRT",
+		Quote:         "USDC",
+		Contract:      testDexterPool,
+		BaseDenom:     "uxprt",
+		QuoteDenom:    "uusdc",
+		BaseExponent:  6,
+		QuoteExponent: 6,
+	}})
+	pair := types.CurrencyPair{Base: "XPRT", Quote: "USDC"}
+
+	t.Run("ticker", func(t *testing.T) {
+		prices, err := p.GetTickerPrices(context.Background(), pair)
+		require.NoError(t, err)
+		require.Equal(t, sdk.MustNewDecFromStr("0.5"), prices["XPRTUSDC"].Price)
+		require.True(t, prices["XPRTUSDC"].Volume.IsZero())
+	})
+
+	t.Run("first_candle_is_spot_price", func(t *testing.T) {
+		candles, err := p.GetCandlePrices(context.Background(), pair)
+		require.NoError(t, err)
+		require.Len(t, candles["XPRTUSDC"], 1)
+		require.Equal(t, sdk.MustNewDecFromStr("0.5"), candles["XPRTUSDC"][0].Price)
+	})
+
+	t.Run("unmoved_cumulative_price_is_spot_price", func(t *testing.T) {
+		candles, err := p.GetCandlePrices(context.Background(), pair)
+		require.NoError(t, err)
+		require.Len(t, candles["XPRTUSDC"], 2)
+		require.Equal(t, sdk.MustNewDecFromStr("0.5"), candles["XPRTUSDC"][1].Price)
+	})
+
+	t.Run("candle_is_twap_since_previous_candle", func(t *testing.T) {
+		// a price of 0.6 over the last 10 seconds
+		p.state["XPRTUSDC"].lastTime = time.Now().Add(-10 * time.Second)
+		cumulative = cumulative.Add(sdk.NewIntFromBigInt(sdk.NewDec(6).BigInt()))
+
+		candles, err := p.GetCandlePrices(context.Background(), pair)
+		require.NoError(t, err)
+		require.Len(t, candles["XPRTUSDC"], 3)
+
+		price := candles["XPRTUSDC"][2].Price
+		require.True(t, price.LTE(sdk.MustNewDecFromStr("0.6")))
+		require.True(t, price.GT(sdk.MustNewDecFromStr("0.59")))
+	})
+
+	t.Run("unconfigured_pool", func(t *testing.T) {
+		_, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "STKATOM", Quote: "ATOM"})
+		require.Error(t, err)
+	})
+}
+
+func TestNewDexterProviderRequiresGRPC(t *testing.T) {
+	_, err := NewDexterProvider(Endpoint{Name: Dexter})
+	require.Error(t, err)
+}
@@ -0,0 +1,185 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// happyEyeballsDelay is the delay after which the next address of a host
+	// is dialed while the previous attempts are still pending, as recommended
+	// by RFC 8305.
+	happyEyeballsDelay = 250 * time.Millisecond
+
+	defaultWebsocketDialTimeout      = 10 * time.Second
+	defaultWebsocketHandshakeTimeout = 45 * time.Second
+)
+
+// websocketDialer dials the websockets of the providers, resolving their host
+// again on every connection and racing its addresses.
+var websocketDialer = &websocket.Dialer{
+	Proxy:            http.ProxyFromEnvironment,
+	HandshakeTimeout: defaultWebsocketHandshakeTimeout,
+	NetDialContext:   newRaceDialer(&net.Dialer{Timeout: defaultWebsocketDialTimeout}).DialContext,
+}
+
+type (
+	// raceDialer dials a host by resolving it on every dial, so that rotated
+	// addresses are picked up on reconnection, and by racing connections to its
+	// addresses, staggered by a delay, so that the fastest reachable address
+	// wins instead of pinning an address which degraded.
+	raceDialer struct {
+		dial         func(ctx context.Context, network, addr string) (net.Conn, error)
+		lookupIPAddr func(ctx context.Context, host string) ([]net.IPAddr, error)
+		delay        time.Duration
+	}
+
+	dialResult struct {
+		conn net.Conn
+		err  error
+	}
+)
+
+func newRaceDialer(dialer *net.Dialer) *raceDialer {
+	return &raceDialer{
+		dial:         dialer.DialContext,
+		lookupIPAddr: net.DefaultResolver.LookupIPAddr,
+		delay:        happyEyeballsDelay,
+	}
+}
+
+// DialContext connects to the address on the named network, returning the
+// first connection established to any address of its host.
+func (d *raceDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || isIPLiteral(host) {
+		// unix sockets and IP addresses are dialed as is
+		return d.dial(ctx, network, addr)
+	}
+
+	resolved, err := d.lookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	ipAddrs := make([]net.IPAddr, 0, len(resolved))
+	for _, ipAddr := range resolved {
+		if matchesNetwork(network, ipAddr.IP) {
+			ipAddrs = append(ipAddrs, ipAddr)
+		}
+	}
+	if len(ipAddrs) == 0 {
+		return nil, fmt.Errorf("no %s address found for %s", network, host)
+	}
+	addrs := interleaveAddrFamilies(ipAddrs)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan dialResult, len(addrs))
+	next := time.NewTimer(0)
+	defer next.Stop()
+
+	var started, failed int
+	for {
+		select {
+		case <-next.C:
+			target := net.JoinHostPort(addrs[started], port)
+			started++
+			go func() {
+				conn, err := d.dial(ctx, network, target)
+				results <- dialResult{conn: conn, err: err}
+			}()
+			if started < len(addrs) {
+				next.Reset(d.delay)
+			}
+
+		case result := <-results:
+			if result.err == nil {
+				go closeDialResults(results, started-failed-1)
+				return result.conn, nil
+			}
+
+			failed++
+			if failed == len(addrs) {
+				return nil, result.err
+			}
+			if started < len(addrs) && started == failed {
+				// every pending attempt failed, dial the next address right away
+				if !next.Stop() {
+					select {
+					case <-next.C:
+					default:
+					}
+				}
+				next.Reset(0)
+			}
+
+		case <-ctx.Done():
+			go closeDialResults(results, started-failed)
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// closeDialResults waits for the n pending attempts of a race, closing the
+// connections of the ones which lost it.
+func closeDialResults(results <-chan dialResult, n int) {
+	for i := 0; i < n; i++ {
+		if result := <-results; result.conn != nil {
+			result.conn.Close()
+		}
+	}
+}
+
+// interleaveAddrFamilies returns the addresses alternating between IPv6 and
+// IPv4, starting with the family of the first address, so that a broken
+// family doesn't delay the connection by more than a race delay.
+func interleaveAddrFamilies(ipAddrs []net.IPAddr) []string {
+	var primary, secondary []string
+	primaryIsIPv4 := ipAddrs[0].IP.To4() != nil
+	for _, ipAddr := range ipAddrs {
+		if (ipAddr.IP.To4() != nil) == primaryIsIPv4 {
+			primary = append(primary, ipAddr.String())
+		} else {
+			secondary = append(secondary, ipAddr.String())
+		}
+	}
+
+	addrs := make([]string, 0, len(ipAddrs))
+	for i := 0; i < len(primary) || i < len(secondary); i++ {
+		if i < len(primary) {
+			addrs = append(addrs, primary[i])
+		}
+		if i < len(secondary) {
+			addrs = append(addrs, secondary[i])
+		}
+	}
+
+	return addrs
+}
+
+// isIPLiteral returns true if host is an IP address rather than a name,
+// including IPv6 addresses with a zone, e.g. "fe80::1%eth0".
+func isIPLiteral(host string) bool {
+	_, err := netip.ParseAddr(host)
+	return err == nil
+}
+
+// matchesNetwork returns true if ip can be dialed on network, e.g. only IPv4
+// addresses on "tcp4". Any address matches dual-stack networks.
+func matchesNetwork(network string, ip net.IP) bool {
+	switch network {
+	case "tcp4", "udp4":
+		return ip.To4() != nil
+	case "tcp6", "udp6":
+		return ip.To4() == nil
+	default:
+		return true
+	}
+}
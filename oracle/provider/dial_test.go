@@ -0,0 +1,140 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRaceDialer returns a raceDialer resolving every host to addrs and
+// dialing them with dial.
+func newTestRaceDialer(
+	addrs []string,
+	dial func(ctx context.Context, addr string) (net.Conn, error),
+) (*raceDialer, *int) {
+	lookups := 0
+	return &raceDialer{
+		dial: func(ctx context.Context, _, addr string) (net.Conn, error) {
+			return dial(ctx, addr)
+		},
+		lookupIPAddr: func(context.Context, string) ([]net.IPAddr, error) {
+			lookups++
+			ipAddrs := make([]net.IPAddr, len(addrs))
+			for i, addr := range addrs {
+				ipAddrs[i] = net.IPAddr{IP: net.ParseIP(addr)}
+			}
+			return ipAddrs, nil
+		},
+		delay: 10 * time.Millisecond,
+	}, &lookups
+}
+
+func TestRaceDialerPrefersFastestAddress(t *testing.T) {
+	canceled := make(chan struct{}, 2)
+	d, lookups := newTestRaceDialer([]string{"10.0.0.1", "10.0.0.2"}, func(ctx context.Context, addr string) (net.Conn, error) {
+		if addr == "10.0.0.1:443" {
+			// the first address is blackholed
+			<-ctx.Done()
+			canceled <- struct{}{}
+			return nil, ctx.Err()
+		}
+		conn, _ := net.Pipe()
+		return conn, nil
+	})
+
+	conn, err := d.DialContext(context.Background(), "tcp", "stream.example.com:443")
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+	require.Equal(t, 1, *lookups)
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("the losing attempt was not canceled")
+	}
+
+	// the host is resolved again on reconnection
+	_, err = d.DialContext(context.Background(), "tcp", "stream.example.com:443")
+	require.NoError(t, err)
+	require.Equal(t, 2, *lookups)
+}
+
+func TestRaceDialerFallsBackOnFailure(t *testing.T) {
+	var dialed []string
+	d, _ := newTestRaceDialer([]string{"10.0.0.1", "10.0.0.2"}, func(_ context.Context, addr string) (net.Conn, error) {
+		dialed = append(dialed, addr)
+		if addr == "10.0.0.1:443" {
+			return nil, errors.New("connection refused")
+		}
+		conn, _ := net.Pipe()
+		return conn, nil
+	})
+	// the next address is dialed as soon as the previous one failed
+	d.delay = time.Hour
+
+	conn, err := d.DialContext(context.Background(), "tcp", "stream.example.com:443")
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+	require.Equal(t, []string{"10.0.0.1:443", "10.0.0.2:443"}, dialed)
+}
+
+func TestRaceDialerAllAddressesFail(t *testing.T) {
+	dialErr := errors.New("connection refused")
+	d, _ := newTestRaceDialer([]string{"10.0.0.1", "10.0.0.2"}, func(context.Context, string) (net.Conn, error) {
+		return nil, dialErr
+	})
+
+	_, err := d.DialContext(context.Background(), "tcp", "stream.example.com:443")
+	require.ErrorIs(t, err, dialErr)
+}
+
+func TestInterleaveAddrFamilies(t *testing.T) {
+	ipAddrs := []net.IPAddr{
+		{IP: net.ParseIP("2001:db8::1")},
+		{IP: net.ParseIP("2001:db8::2")},
+		{IP: net.ParseIP("2001:db8::3")},
+		{IP: net.ParseIP("192.0.2.1")},
+	}
+
+	require.Equal(t,
+		[]string{"2001:db8::1", "192.0.2.1", "2001:db8::2", "2001:db8::3"},
+		interleaveAddrFamilies(ipAddrs),
+	)
+}
+
+func TestRaceDialerNetworkFamily(t *testing.T) {
+	var dialed []string
+	d, _ := newTestRaceDialer([]string{"2001:db8::1", "192.0.2.1"}, func(_ context.Context, addr string) (net.Conn, error) {
+		dialed = append(dialed, addr)
+		conn, _ := net.Pipe()
+		return conn, nil
+	})
+
+	_, err := d.DialContext(context.Background(), "tcp4", "stream.example.com:443")
+	require.NoError(t, err)
+	require.Equal(t, []string{"192.0.2.1:443"}, dialed)
+
+	// IPv6 literals are dialed as is, without resolution
+	_, err = d.DialContext(context.Background(), "tcp", "[fe80::1%eth0]:443")
+	require.NoError(t, err)
+	require.Equal(t, "[fe80::1%eth0]:443", dialed[1])
+
+	d, _ = newTestRaceDialer([]string{"192.0.2.1"}, func(context.Context, string) (net.Conn, error) {
+		t.Fatal("no address should be dialed")
+		return nil, nil
+	})
+	_, err = d.DialContext(context.Background(), "tcp6", "stream.example.com:443")
+	require.EqualError(t, err, "no tcp6 address found for stream.example.com")
+}
+
+func TestIsIPLiteral(t *testing.T) {
+	require.True(t, isIPLiteral("192.0.2.1"))
+	require.True(t, isIPLiteral("2001:db8::1"))
+	require.True(t, isIPLiteral("fe80::1%eth0"))
+	require.False(t, isIPLiteral("[2001:db8::1]"))
+	require.False(t, isIPLiteral("stream.example.com"))
+}
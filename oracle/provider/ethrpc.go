@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+)
+
+// ethWordSize is the size of an ABI encoded word.
+const ethWordSize = 32
+
+type (
+	ethCallRequest struct {
+		JSONRPC string        `json:"jsonrpc"`
+		ID      int           `json:"id"`
+		Method  string        `json:"method"`
+		Params  []interface{} `json:"params"`
+	}
+
+	ethCallParams struct {
+		To   string `json:"to"`
+		Data string `json:"data"`
+	}
+
+	ethCallResponse struct {
+		Result string `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+)
+
+// ethCall calls the contract method with the given selector and uint256 args
+// at the latest block through the Ethereum JSON-RPC endpoint at url, bounded
+// by ctx, and returns the ABI encoded words of its result. The response is
+// captured as a payload of the named provider.
+func ethCall(
+	ctx context.Context,
+	client *http.Client,
+	url string,
+	name Name,
+	contract string,
+	selector []byte,
+	args ...*big.Int,
+) ([]*big.Int, error) {
+	data := append([]byte{}, selector...)
+	for _, arg := range args {
+		data = append(data, arg.FillBytes(make([]byte, ethWordSize))...)
+	}
+
+	body, err := json.Marshal(ethCallRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "eth_call",
+		Params:  []interface{}{ethCallParams{To: contract, Data: "0x" + hex.EncodeToString(data)}, "latest"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	if err := checkHTTPStatus(httpResp); err != nil {
+		return nil, err
+	}
+
+	bz, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s response body: %w", name, err)
+	}
+	capturePayload(name, bz)
+
+	var resp ethCallResponse
+	if err := json.Unmarshal(bz, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, fmt.Errorf("eth_call failed: %s", resp.Error.Message)
+	}
+
+	result, err := hex.DecodeString(strings.TrimPrefix(resp.Result, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid eth_call result: %w", err)
+	}
+	if len(result) == 0 || len(result)%ethWordSize != 0 {
+		return nil, fmt.Errorf("invalid eth_call result length: %d", len(result))
+	}
+
+	words := make([]*big.Int, len(result)/ethWordSize)
+	for i := range words {
+		words[i] = new(big.Int).SetBytes(result[i*ethWordSize : (i+1)*ethWordSize])
+	}
+
+	return words, nil
+}
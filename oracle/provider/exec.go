@@ -0,0 +1,378 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+const (
+	execMethodTickers = "tickers"
+	execMethodCandles = "candles"
+
+	// execMinBackoff and execMaxBackoff bound the delay before restarting an
+	// executable which exited, failed to start or timed out, doubled on every
+	// consecutive failure.
+	execMinBackoff = time.Second
+	execMaxBackoff = time.Minute
+
+	// execMaxLineBytes is the size of the largest response line.
+	execMaxLineBytes = 4 << 20 // 4 MiB
+)
+
+var _ Provider = (*ExecProvider)(nil)
+
+type (
+	// ExecProvider defines an Oracle provider backed by an executable supplied
+	// by the operator, so that custom data sources can be plugged in without
+	// forking the feeder. The executable is run with the Command of the
+	// endpoint and answers requests over its stdio, one JSON object per line:
+	//
+	//	-> {"id": 1, "method": "tickers", "pairs": [{"base": "ATOM", "quote": "USDT"}]}
+	//	<- {"id": 1, "tickers": {"ATOMUSDT": {"price": "10.5", "volume": "2500"}}}
+	//	-> {"id": 2, "method": "candles", "pairs": [{"base": "ATOM", "quote": "USDT"}]}
+	//	<- {"id": 2, "candles": {"ATOMUSDT": [{"price": "10.5", "volume": "80", "timestamp": 1688169600000}]}}
+	//
+	// A response sets error instead when the request failed. Requests are sent
+	// one at a time, each bounded by a timeout. The executable is supervised:
+	// once it exits or times out, it is killed and restarted on a following
+	// request, after a backoff. Its stderr is logged.
+	ExecProvider struct {
+		logger  zerolog.Logger
+		command []string
+		ctx     context.Context
+		cancel  context.CancelFunc
+
+		mtx       sync.Mutex
+		proc      *execProcess
+		nextID    uint64
+		backoff   time.Duration
+		restartAt time.Time
+	}
+
+	// execProcess defines a running executable, whose stdout lines are sent
+	// to lines until it exits, which closes done, or gets killed, which closes
+	// killed.
+	execProcess struct {
+		cmd    *exec.Cmd
+		stdin  io.WriteCloser
+		lines  chan []byte
+		done   chan struct{}
+		killed chan struct{}
+	}
+
+	// ExecRequest defines a request sent to the executable.
+	ExecRequest struct {
+		ID     uint64     `json:"id"`
+		Method string     `json:"method"`
+		Pairs  []ExecPair `json:"pairs"`
+	}
+
+	// ExecPair defines a currency pair of a request.
+	ExecPair struct {
+		Base  string `json:"base"`
+		Quote string `json:"quote"`
+	}
+
+	// ExecResponse defines the response of the executable to a request, keyed
+	// by CurrencyPair.String().
+	ExecResponse struct {
+		ID      uint64                  `json:"id"`
+		Tickers map[string]ExecTicker   `json:"tickers,omitempty"`
+		Candles map[string][]ExecCandle `json:"candles,omitempty"`
+		Error   string                  `json:"error,omitempty"`
+	}
+
+	// ExecTicker defines a ticker of a response, with decimal strings.
+	ExecTicker struct {
+		Price  string `json:"price"`
+		Volume string `json:"volume"`
+	}
+
+	// ExecCandle defines a candle of a response, with decimal strings and the
+	// unix time of its end in milliseconds.
+	ExecCandle struct {
+		Price     string `json:"price"`
+		Volume    string `json:"volume"`
+		Timestamp int64  `json:"timestamp"`
+	}
+
+	// execStderr logs the lines written to the stderr of the executable.
+	execStderr struct {
+		logger zerolog.Logger
+		buf    bytes.Buffer
+	}
+)
+
+// NewExecProvider returns a provider backed by the executable run with the
+// command of the endpoint. The executable is only run once started.
+func NewExecProvider(ctx context.Context, logger zerolog.Logger, endpoint Endpoint) (*ExecProvider, error) {
+	if len(endpoint.Command) == 0 || len(endpoint.Command[0]) == 0 {
+		return nil, fmt.Errorf("exec provider requires the command of its executable")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	return &ExecProvider{
+		logger:  logger.With().Str("provider", string(Exec)).Logger(),
+		command: endpoint.Command,
+		ctx:     ctx,
+		cancel:  cancel,
+		backoff: execMinBackoff,
+	}, nil
+}
+
+// Start runs the executable, see Lifecycle. An executable failing to start is
+// restarted on a following request.
+func (p *ExecProvider) Start() error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if err := p.spawn(); err != nil {
+		p.logger.Error().Err(err).Msg("failed to start executable")
+		p.fail()
+	}
+
+	return nil
+}
+
+// Stop kills the executable, see Lifecycle.
+func (p *ExecProvider) Stop() {
+	p.cancel()
+}
+
+// SubscribeCurrencyPairs performs a no-op since the executable is requested
+// the pairs on every request.
+func (*ExecProvider) SubscribeCurrencyPairs(context.Context, ...types.CurrencyPair) error {
+	return nil
+}
+
+// GetTickerPrices returns the tickers of the given pairs served by the
+// executable.
+func (p *ExecProvider) GetTickerPrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
+	resp, err := p.request(ctx, execMethodTickers, pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	tickerPrices := make(map[string]types.TickerPrice, len(pairs))
+	for _, cp := range pairs {
+		ticker, ok := resp.Tickers[cp.String()]
+		if !ok {
+			return nil, fmt.Errorf("exec failed to get ticker price for %s", cp.String())
+		}
+
+		price, err := sdk.NewDecFromStr(ticker.Price)
+		if err != nil {
+			return nil, fmt.Errorf("exec returned an invalid %s price: %w", cp.String(), err)
+		}
+		volume, err := sdk.NewDecFromStr(ticker.Volume)
+		if err != nil {
+			return nil, fmt.Errorf("exec returned an invalid %s volume: %w", cp.String(), err)
+		}
+
+		tickerPrices[cp.String()] = types.TickerPrice{Price: price, Volume: volume}
+	}
+
+	return tickerPrices, nil
+}
+
+// GetCandlePrices returns the candles of the given pairs served by the
+// executable.
+func (p *ExecProvider) GetCandlePrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
+	resp, err := p.request(ctx, execMethodCandles, pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	candlePrices := make(map[string][]types.CandlePrice, len(pairs))
+	for _, cp := range pairs {
+		candles, ok := resp.Candles[cp.String()]
+		if !ok {
+			return nil, fmt.Errorf("exec failed to get candle prices for %s", cp.String())
+		}
+
+		for _, candle := range candles {
+			price, err := sdk.NewDecFromStr(candle.Price)
+			if err != nil {
+				return nil, fmt.Errorf("exec returned an invalid %s candle price: %w", cp.String(), err)
+			}
+			volume, err := sdk.NewDecFromStr(candle.Volume)
+			if err != nil {
+				return nil, fmt.Errorf("exec returned an invalid %s candle volume: %w", cp.String(), err)
+			}
+
+			candlePrices[cp.String()] = append(candlePrices[cp.String()], types.CandlePrice{
+				Price:     price,
+				Volume:    volume,
+				TimeStamp: candle.Timestamp,
+			})
+		}
+	}
+
+	return candlePrices, nil
+}
+
+// request sends the request of method for the pairs to the executable,
+// restarting it if needed, and returns its response. An executable which
+// doesn't respond within the timeout is killed.
+func (p *ExecProvider) request(ctx context.Context, method string, pairs []types.CurrencyPair) (ExecResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.proc == nil {
+		if p.ctx.Err() != nil {
+			return ExecResponse{}, fmt.Errorf("exec provider is stopped")
+		}
+		if wait := time.Until(p.restartAt); wait > 0 {
+			return ExecResponse{}, fmt.Errorf("exec restarts the executable in %s", wait.Round(time.Millisecond))
+		}
+		if err := p.spawn(); err != nil {
+			p.fail()
+			return ExecResponse{}, fmt.Errorf("exec failed to start the executable: %w", err)
+		}
+	}
+
+	p.nextID++
+	req := ExecRequest{ID: p.nextID, Method: method, Pairs: make([]ExecPair, len(pairs))}
+	for i, cp := range pairs {
+		req.Pairs[i] = ExecPair{Base: cp.Base, Quote: cp.Quote}
+	}
+	bz, err := json.Marshal(req)
+	if err != nil {
+		return ExecResponse{}, err
+	}
+	if _, err := p.proc.stdin.Write(append(bz, '\n')); err != nil {
+		p.kill()
+		return ExecResponse{}, fmt.Errorf("exec failed to write the request: %w", err)
+	}
+
+	for {
+		select {
+		case line := <-p.proc.lines:
+			var resp ExecResponse
+			if err := json.Unmarshal(line, &resp); err != nil {
+				p.kill()
+				return ExecResponse{}, fmt.Errorf("exec returned an invalid response: %w", err)
+			}
+			if resp.ID != req.ID {
+				// a late response to a previous request
+				continue
+			}
+
+			p.backoff = execMinBackoff
+			if len(resp.Error) > 0 {
+				return ExecResponse{}, fmt.Errorf("exec failed to serve %s: %s", method, resp.Error)
+			}
+			return resp, nil
+
+		case <-p.proc.done:
+			p.kill()
+			return ExecResponse{}, fmt.Errorf("exec executable exited")
+
+		case <-ctx.Done():
+			p.kill()
+			return ExecResponse{}, fmt.Errorf("exec executable didn't respond: %w", ctx.Err())
+		}
+	}
+}
+
+// spawn runs the executable.
+func (p *ExecProvider) spawn() error {
+	//nolint:gosec // the command is set by the operator
+	cmd := exec.CommandContext(p.ctx, p.command[0], p.command[1:]...)
+	cmd.Stderr = &execStderr{logger: p.logger}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	proc := &execProcess{
+		cmd:    cmd,
+		stdin:  stdin,
+		lines:  make(chan []byte),
+		done:   make(chan struct{}),
+		killed: make(chan struct{}),
+	}
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(nil, execMaxLineBytes)
+		for scanner.Scan() {
+			line := append([]byte{}, scanner.Bytes()...)
+			select {
+			case proc.lines <- line:
+			case <-proc.killed:
+			}
+		}
+
+		err := cmd.Wait()
+		p.logger.Warn().Err(err).Msg("executable exited")
+		close(proc.done)
+	}()
+
+	p.proc = proc
+	p.logger.Info().Int("pid", cmd.Process.Pid).Msg("started executable")
+
+	return nil
+}
+
+// kill kills the executable, to be restarted after the backoff.
+func (p *ExecProvider) kill() {
+	close(p.proc.killed)
+	_ = p.proc.stdin.Close()
+	_ = p.proc.cmd.Process.Kill()
+	p.proc = nil
+	p.fail()
+}
+
+// fail delays the restart of the executable by the backoff, which doubles.
+func (p *ExecProvider) fail() {
+	p.restartAt = time.Now().Add(p.backoff)
+	p.backoff *= 2
+	if p.backoff > execMaxBackoff {
+		p.backoff = execMaxBackoff
+	}
+}
+
+// Write logs every complete line of p.
+func (w *execStderr) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			// keep the incomplete line for the next write
+			w.buf.Reset()
+			w.buf.WriteString(line)
+			return len(p), nil
+		}
+		w.logger.Info().Str("stderr", strings.TrimRight(line, "\r\n")).Msg("executable output")
+	}
+}
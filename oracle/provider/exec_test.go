@@ -0,0 +1,159 @@
+package provider
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+const execHelperEnv = "PRICE_FEEDER_EXEC_HELPER"
+
+// TestExecHelperProcess isn't a test: it is run by the exec provider as its
+// executable, behaving as set by its last argument.
+func TestExecHelperProcess(t *testing.T) {
+	if os.Getenv(execHelperEnv) != "1" {
+		t.Skip("run by the exec provider")
+	}
+	mode := os.Args[len(os.Args)-1]
+
+	fmt.Fprintln(os.Stderr, "helper started")
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var req ExecRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			os.Exit(1)
+		}
+
+		switch mode {
+		case "hang":
+			select {}
+		case "exit":
+			os.Exit(1)
+		}
+
+		resp := ExecResponse{ID: req.ID}
+		for _, pair := range req.Pairs {
+			symbol := pair.Base + pair.Quote
+			switch {
+			case pair.Base == "FAIL":
+				resp.Error = "unknown pair"
+			case req.Method == execMethodTickers:
+				if resp.Tickers == nil {
+					resp.Tickers = make(map[string]ExecTicker)
+				}
+				resp.Tickers[symbol] = ExecTicker{Price: "10.5", Volume: "2500"}
+			case req.Method == execMethodCandles:
+				if resp.Candles == nil {
+					resp.Candles = make(map[string][]ExecCandle)
+				}
+				resp.Candles[symbol] = []ExecCandle{{Price: "10.5", Volume: "80", Timestamp: 1688169600000}}
+			}
+		}
+		bz, _ := json.Marshal(resp)
+		fmt.Println(string(bz))
+	}
+	os.Exit(0)
+}
+
+func newExecHelperProvider(t *testing.T, mode string) *ExecProvider {
+	t.Setenv(execHelperEnv, "1")
+
+	p, err := NewExecProvider(context.Background(), zerolog.Nop(), Endpoint{
+		Name:    Exec,
+		Command: execHelperCommand(mode),
+	})
+	require.NoError(t, err)
+	require.NoError(t, p.Start())
+	t.Cleanup(p.Stop)
+
+	return p
+}
+
+func execHelperCommand(mode string) []string {
+	return []string{os.Args[0], "-test.run=TestExecHelperProcess", "--", mode}
+}
+
+func TestExecProvider(t *testing.T) {
+	atomUSDT := types.CurrencyPair{Base: "ATOM", Quote: "USDT"}
+
+	t.Run("no command", func(t *testing.T) {
+		_, err := NewExecProvider(context.Background(), zerolog.Nop(), Endpoint{Name: Exec})
+		require.ErrorContains(t, err, "requires the command")
+	})
+
+	t.Run("ticker", func(t *testing.T) {
+		p := newExecHelperProvider(t, "serve")
+
+		prices, err := p.GetTickerPrices(context.Background(), atomUSDT)
+		require.NoError(t, err)
+		require.Equal(t, types.TickerPrice{Price: sdk.MustNewDecFromStr("10.5"), Volume: sdk.NewDec(2500)},
+			prices["ATOMUSDT"])
+
+		_, err = p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "FAIL", Quote: "USDT"})
+		require.ErrorContains(t, err, "unknown pair")
+
+		// the executable keeps serving after a failed request
+		_, err = p.GetTickerPrices(context.Background(), atomUSDT)
+		require.NoError(t, err)
+	})
+
+	t.Run("candles", func(t *testing.T) {
+		p := newExecHelperProvider(t, "serve")
+
+		candles, err := p.GetCandlePrices(context.Background(), atomUSDT)
+		require.NoError(t, err)
+		require.Equal(t, []types.CandlePrice{{
+			Price:     sdk.MustNewDecFromStr("10.5"),
+			Volume:    sdk.NewDec(80),
+			TimeStamp: 1688169600000,
+		}}, candles["ATOMUSDT"])
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		p := newExecHelperProvider(t, "hang")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		_, err := p.GetTickerPrices(ctx, atomUSDT)
+		require.ErrorContains(t, err, "didn't respond")
+
+		// the killed executable is only restarted after the backoff
+		_, err = p.GetTickerPrices(context.Background(), atomUSDT)
+		require.ErrorContains(t, err, "restarts the executable")
+	})
+
+	t.Run("restart", func(t *testing.T) {
+		p := newExecHelperProvider(t, "exit")
+
+		_, err := p.GetTickerPrices(context.Background(), atomUSDT)
+		require.ErrorContains(t, err, "exited")
+		require.Equal(t, 2*execMinBackoff, p.backoff)
+
+		p.command = execHelperCommand("serve")
+		p.restartAt = time.Time{}
+		_, err = p.GetTickerPrices(context.Background(), atomUSDT)
+		require.NoError(t, err)
+		require.Equal(t, execMinBackoff, p.backoff)
+	})
+
+	t.Run("stopped", func(t *testing.T) {
+		p := newExecHelperProvider(t, "exit")
+		_, err := p.GetTickerPrices(context.Background(), atomUSDT)
+		require.Error(t, err)
+
+		p.Stop()
+		p.restartAt = time.Time{}
+		_, err = p.GetTickerPrices(context.Background(), atomUSDT)
+		require.ErrorContains(t, err, "stopped")
+	})
+}
@@ -0,0 +1,410 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+const (
+	ecbRatesURL   = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+	ecbReference  = "EUR"
+	ecbDateLayout = "2006-01-02"
+
+	exchangeRateHostRestURL      = "https://api.exchangerate.host"
+	exchangeRateHostLiveEndpoint = "/live"
+	exchangeRateHostReference    = "USD"
+
+	metalsAPIRestURL        = "https://metals-api.com/api"
+	metalsAPILatestEndpoint = "/latest"
+	metalsAPIReference      = "USD"
+
+	// ecbRatesTTL, exchangeRateHostRatesTTL and metalsAPIRatesTTL bound how
+	// often the rates are requested: the ECB publishes its reference rates
+	// once a working day, exchangerate.host updates them every minute at best,
+	// and metals-api plans grant a few thousand requests a month.
+	ecbRatesTTL              = time.Hour
+	exchangeRateHostRatesTTL = time.Minute
+	metalsAPIRatesTTL        = 10 * time.Minute
+
+	// forexRetryInterval is the time a failed request of the rates is retried
+	// after, unless rate limited for longer, so that failures don't spend the
+	// request quotas of the APIs on every tick.
+	forexRetryInterval = time.Minute
+
+	// ecbMaxRatesAge is the age above which the ECB reference rates are
+	// considered stale, covering the weekends and the TARGET holidays.
+	ecbMaxRatesAge = 4 * 24 * time.Hour
+)
+
+var _ Provider = (*ForexProvider)(nil)
+
+type (
+	// ForexProvider defines an Oracle provider of fiat exchange rates, e.g.
+	// KRW/USD or EUR/USD, to convert the prices of the pairs quoted in fiat
+	// currencies other than USD, or of precious metals spot rates, e.g.
+	// XAU/USD, for commodity-backed assets. Every pair of currencies is priced
+	// from their rates against the reference currency of the source: the ECB
+	// reference rates against EUR, or the exchangerate.host live rates or the
+	// metals-api latest rates against USD, both of which require an API key.
+	//
+	// As these rates have no traded volume, every rate weighs as a unit of
+	// volume, and candles are the current rates.
+	//
+	// REF: https://www.ecb.europa.eu/stats/policy_and_exchange_rates/euro_reference_exchange_rates
+	// REF: https://exchangerate.host/documentation
+	// REF: https://metals-api.com/documentation
+	ForexProvider struct {
+		name     Name
+		baseURL  string
+		apiKey   string
+		ttl      time.Duration
+		client   *http.Client
+		getRates func(ctx context.Context) (map[string]sdk.Dec, error)
+
+		mtx     sync.Mutex
+		rates   map[string]sdk.Dec // units of each currency per unit of the reference
+		ratesAt time.Time
+		retryAt time.Time // requests of the rates are held off until then
+	}
+
+	// ECBRatesEnvelope defines the structure of the ECB daily reference rates,
+	// as units of each currency per EUR.
+	ECBRatesEnvelope struct {
+		Cube struct {
+			Cube struct {
+				Time  string    `xml:"time,attr"` // Publication date ex.: 2023-06-30
+				Rates []ECBRate `xml:"Cube"`
+			} `xml:"Cube"`
+		} `xml:"Cube"`
+	}
+
+	// ECBRate defines the ECB reference rate of a currency.
+	ECBRate struct {
+		Currency string `xml:"currency,attr"` // Currency ex.: KRW
+		Rate     string `xml:"rate,attr"`     // Units per EUR ex.: 1430.01
+	}
+
+	// ExchangeRateHostResponse defines the response structure of the
+	// exchangerate.host live rates, as units of each currency per USD.
+	ExchangeRateHostResponse struct {
+		Success bool                  `json:"success"`
+		Error   ExchangeRateHostError `json:"error"`  // Only set on failure
+		Quotes  map[string]float64    `json:"quotes"` // Keyed by source and currency ex.: USDKRW
+	}
+
+	// ExchangeRateHostError defines the error of a failed exchangerate.host
+	// request.
+	ExchangeRateHostError struct {
+		Code int    `json:"code"`
+		Info string `json:"info"`
+	}
+
+	// MetalsAPIResponse defines the response structure of the metals-api
+	// latest rates, as units of each metal or currency per USD.
+	MetalsAPIResponse struct {
+		Success bool                  `json:"success"`
+		Error   ExchangeRateHostError `json:"error"` // Only set on failure, same as exchangerate.host
+		Rates   map[string]float64    `json:"rates"` // Keyed by symbol ex.: XAU
+	}
+)
+
+// NewECBProvider returns a forex provider of the ECB reference rates, read
+// from the REST URL of the endpoint if set.
+func NewECBProvider(endpoint Endpoint) *ForexProvider {
+	p := &ForexProvider{
+		name:    ECB,
+		baseURL: ecbRatesURL,
+		ttl:     ecbRatesTTL,
+		client:  newDefaultHTTPClient(),
+	}
+	if endpoint.Name == ECB && len(endpoint.Rest) > 0 {
+		p.baseURL = endpoint.Rest
+	}
+	p.getRates = p.getECBRates
+
+	return p
+}
+
+// NewExchangeRateHostProvider returns a forex provider of the
+// exchangerate.host live rates, authenticated with the API key of the
+// endpoint, using its REST URL if set.
+func NewExchangeRateHostProvider(endpoint Endpoint) (*ForexProvider, error) {
+	if len(endpoint.APIKey) == 0 {
+		return nil, fmt.Errorf("exchangerate.host requires an api key in the provider credentials")
+	}
+
+	p := &ForexProvider{
+		name:    ExchangeRateHost,
+		baseURL: exchangeRateHostRestURL,
+		apiKey:  endpoint.APIKey,
+		ttl:     exchangeRateHostRatesTTL,
+		client:  newDefaultHTTPClient(),
+	}
+	if endpoint.Name == ExchangeRateHost && len(endpoint.Rest) > 0 {
+		p.baseURL = endpoint.Rest
+	}
+	p.getRates = p.getExchangeRateHostRates
+
+	return p, nil
+}
+
+// NewMetalsAPIProvider returns a forex provider of the metals-api latest
+// rates, e.g. of XAU or XAG, authenticated with the API key of the endpoint,
+// using its REST URL if set.
+func NewMetalsAPIProvider(endpoint Endpoint) (*ForexProvider, error) {
+	if len(endpoint.APIKey) == 0 {
+		return nil, fmt.Errorf("metals-api requires an api key in the provider credentials")
+	}
+
+	p := &ForexProvider{
+		name:    MetalsAPI,
+		baseURL: metalsAPIRestURL,
+		apiKey:  endpoint.APIKey,
+		ttl:     metalsAPIRatesTTL,
+		client:  newDefaultHTTPClient(),
+	}
+	if endpoint.Name == MetalsAPI && len(endpoint.Rest) > 0 {
+		p.baseURL = endpoint.Rest
+	}
+	p.getRates = p.getMetalsAPIRates
+
+	return p, nil
+}
+
+// SubscribeCurrencyPairs performs a no-op since the rates are requested on
+// demand.
+func (*ForexProvider) SubscribeCurrencyPairs(context.Context, ...types.CurrencyPair) error {
+	return nil
+}
+
+// GetTickerPrices returns the exchange rates of the given pairs.
+func (p *ForexProvider) GetTickerPrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
+	prices, err := p.getPrices(ctx, pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	tickerPrices := make(map[string]types.TickerPrice, len(prices))
+	for symbol, price := range prices {
+		tickerPrices[symbol] = types.TickerPrice{Price: price, Volume: sdk.OneDec()}
+	}
+
+	return tickerPrices, nil
+}
+
+// GetCandlePrices returns a candle of the current exchange rate of each of
+// the given pairs.
+func (p *ForexProvider) GetCandlePrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
+	prices, err := p.getPrices(ctx, pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	candles := make(map[string][]types.CandlePrice, len(prices))
+	for symbol, price := range prices {
+		candles[symbol] = []types.CandlePrice{{Price: price, Volume: sdk.OneDec(), TimeStamp: now}}
+	}
+
+	return candles, nil
+}
+
+// getPrices returns the price of the base of each pair in its quote, from
+// the rates requested at most once per TTL, and no sooner than the retry
+// time after a failure.
+func (p *ForexProvider) getPrices(ctx context.Context, pairs []types.CurrencyPair) (map[string]sdk.Dec, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.rates == nil || time.Since(p.ratesAt) >= p.ttl {
+		if time.Now().Before(p.retryAt) {
+			return nil, fmt.Errorf("%s rates requests held off until %s", p.name, p.retryAt.Format(time.RFC3339))
+		}
+
+		rates, err := p.getRates(ctx)
+		if err != nil {
+			if retryAt := time.Now().Add(forexRetryInterval); retryAt.After(p.retryAt) {
+				p.retryAt = retryAt
+			}
+			return nil, fmt.Errorf("%s failed to get rates: %w", p.name, err)
+		}
+		p.rates, p.ratesAt = rates, time.Now()
+	}
+
+	prices := make(map[string]sdk.Dec, len(pairs))
+	for _, cp := range pairs {
+		baseRate, ok := p.rates[strings.ToUpper(cp.Base)]
+		if !ok {
+			return nil, fmt.Errorf(types.ErrMissingExchangeRate, cp.String())
+		}
+		quoteRate, ok := p.rates[strings.ToUpper(cp.Quote)]
+		if !ok {
+			return nil, fmt.Errorf(types.ErrMissingExchangeRate, cp.String())
+		}
+
+		prices[cp.String()] = quoteRate.Quo(baseRate)
+	}
+
+	return prices, nil
+}
+
+// getECBRates returns the latest ECB reference rates, as units of each
+// currency per EUR.
+func (p *ForexProvider) getECBRates(ctx context.Context) (map[string]sdk.Dec, error) {
+	bz, err := p.get(ctx, p.baseURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var envelope ECBRatesEnvelope
+	if err := xml.Unmarshal(bz, &envelope); err != nil {
+		return nil, err
+	}
+
+	publishedAt, err := time.Parse(ecbDateLayout, envelope.Cube.Cube.Time)
+	if err != nil {
+		return nil, fmt.Errorf("invalid publication date: %w", err)
+	}
+	if time.Since(publishedAt) > ecbMaxRatesAge {
+		return nil, fmt.Errorf("stale rates published on %s", envelope.Cube.Cube.Time)
+	}
+
+	rates := map[string]sdk.Dec{ecbReference: sdk.OneDec()}
+	for _, rate := range envelope.Cube.Cube.Rates {
+		dec, err := sdk.NewDecFromStr(rate.Rate)
+		if err != nil || !dec.IsPositive() {
+			return nil, fmt.Errorf("invalid %s rate: %s", rate.Currency, rate.Rate)
+		}
+		rates[rate.Currency] = dec
+	}
+
+	return rates, nil
+}
+
+// getExchangeRateHostRates returns the exchangerate.host live rates, as units
+// of each currency per USD.
+func (p *ForexProvider) getExchangeRateHostRates(ctx context.Context) (map[string]sdk.Dec, error) {
+	query := url.Values{}
+	query.Set("access_key", p.apiKey)
+	query.Set("source", exchangeRateHostReference)
+
+	bz, err := p.get(ctx, p.baseURL+exchangeRateHostLiveEndpoint, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp ExchangeRateHostResponse
+	if err := json.Unmarshal(bz, &resp); err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("%s (code %d)", resp.Error.Info, resp.Error.Code)
+	}
+
+	rates := map[string]sdk.Dec{exchangeRateHostReference: sdk.OneDec()}
+	for symbol, rate := range resp.Quotes {
+		currency := strings.TrimPrefix(symbol, exchangeRateHostReference)
+		if rate <= 0 {
+			return nil, fmt.Errorf("invalid %s rate: %v", currency, rate)
+		}
+		rates[currency] = floatToDec(rate)
+	}
+
+	return rates, nil
+}
+
+// getMetalsAPIRates returns the metals-api latest rates, as units of each
+// metal or currency per USD.
+func (p *ForexProvider) getMetalsAPIRates(ctx context.Context) (map[string]sdk.Dec, error) {
+	query := url.Values{}
+	query.Set("access_key", p.apiKey)
+	query.Set("base", metalsAPIReference)
+
+	bz, err := p.get(ctx, p.baseURL+metalsAPILatestEndpoint, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp MetalsAPIResponse
+	if err := json.Unmarshal(bz, &resp); err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("%s (code %d)", resp.Error.Info, resp.Error.Code)
+	}
+
+	rates := map[string]sdk.Dec{metalsAPIReference: sdk.OneDec()}
+	for symbol, rate := range resp.Rates {
+		// the inverse rates are listed as well, e.g. USDXAU
+		if strings.HasPrefix(symbol, metalsAPIReference) && symbol != metalsAPIReference {
+			continue
+		}
+		if rate <= 0 {
+			return nil, fmt.Errorf("invalid %s rate: %v", symbol, rate)
+		}
+		rates[symbol] = floatToDec(rate)
+	}
+
+	return rates, nil
+}
+
+// get requests the URL with the query, bounded by ctx, and returns the
+// response body. Requests rate limited by the API hold off the following ones
+// for as long as it asks to.
+func (p *ForexProvider) get(ctx context.Context, rawURL string, query url.Values) ([]byte, error) {
+	if len(query) > 0 {
+		rawURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		// don't leak the access key of the query into the logs
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return nil, urlErr.Err
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			p.retryAt = time.Now().Add(time.Duration(seconds) * time.Second)
+		}
+	}
+	if err := checkHTTPStatus(resp); err != nil {
+		return nil, err
+	}
+
+	bz, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s response body: %w", p.name, err)
+	}
+	capturePayload(p.name, bz)
+
+	return bz, nil
+}
@@ -0,0 +1,153 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+func TestECBProvider(t *testing.T) {
+	published := time.Now().Format(ecbDateLayout)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		fmt.Fprintf(rw, `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01"
+	xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<Cube>
+		<Cube time="%s">
+			<Cube currency="USD" rate="1.25"/>
+			<Cube currency="KRW" rate="1500"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`, published)
+	}))
+	defer server.Close()
+
+	p := NewECBProvider(Endpoint{Name: ECB, Rest: server.URL})
+	p.client = server.Client()
+
+	t.Run("ticker", func(t *testing.T) {
+		prices, err := p.GetTickerPrices(context.Background(),
+			types.CurrencyPair{Base: "KRW", Quote: "USD"},
+			types.CurrencyPair{Base: "EUR", Quote: "USD"},
+		)
+		require.NoError(t, err)
+		require.Equal(t, types.TickerPrice{Price: sdk.MustNewDecFromStr("0.000833333333333333"), Volume: sdk.OneDec()},
+			prices["KRWUSD"])
+		require.Equal(t, types.TickerPrice{Price: sdk.MustNewDecFromStr("1.25"), Volume: sdk.OneDec()},
+			prices["EURUSD"])
+
+		_, err = p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "JPY", Quote: "USD"})
+		require.ErrorContains(t, err, "missing exchange rate for JPYUSD")
+	})
+
+	t.Run("candles", func(t *testing.T) {
+		candles, err := p.GetCandlePrices(context.Background(), types.CurrencyPair{Base: "USD", Quote: "KRW"})
+		require.NoError(t, err)
+		require.Len(t, candles["USDKRW"], 1)
+		require.Equal(t, sdk.NewDec(1200), candles["USDKRW"][0].Price)
+		require.Greater(t, candles["USDKRW"][0].TimeStamp, PastUnixTime(providerCandlePeriod))
+	})
+
+	t.Run("stale", func(t *testing.T) {
+		published = "2023-01-02"
+		p.rates = nil
+		_, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "EUR", Quote: "USD"})
+		require.EqualError(t, err, "ecb failed to get rates: stale rates published on 2023-01-02")
+	})
+}
+
+func TestExchangeRateHostProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		require.Equal(t, exchangeRateHostLiveEndpoint, req.URL.Path)
+		require.Equal(t, exchangeRateHostReference, req.URL.Query().Get("source"))
+
+		if req.URL.Query().Get("access_key") != "secret" {
+			fmt.Fprint(rw, `{"success":false,"error":{"code":101,"info":"invalid access key"}}`)
+			return
+		}
+		fmt.Fprint(rw, `{"success":true,"source":"USD","quotes":{"USDEUR":0.8,"USDKRW":1200}}`)
+	}))
+	defer server.Close()
+
+	_, err := NewExchangeRateHostProvider(Endpoint{Name: ExchangeRateHost, Rest: server.URL})
+	require.EqualError(t, err, "exchangerate.host requires an api key in the provider credentials")
+
+	p, err := NewExchangeRateHostProvider(Endpoint{Name: ExchangeRateHost, Rest: server.URL, APIKey: "secret"})
+	require.NoError(t, err)
+	p.client = server.Client()
+
+	prices, err := p.GetTickerPrices(context.Background(),
+		types.CurrencyPair{Base: "EUR", Quote: "USD"},
+		types.CurrencyPair{Base: "EUR", Quote: "KRW"},
+	)
+	require.NoError(t, err)
+	require.Equal(t, sdk.MustNewDecFromStr("1.25"), prices["EURUSD"].Price)
+	require.Equal(t, sdk.NewDec(1500), prices["EURKRW"].Price)
+
+	p, err = NewExchangeRateHostProvider(Endpoint{Name: ExchangeRateHost, Rest: server.URL, APIKey: "invalid"})
+	require.NoError(t, err)
+	p.client = server.Client()
+
+	_, err = p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "EUR", Quote: "USD"})
+	require.EqualError(t, err, "exchangeratehost failed to get rates: invalid access key (code 101)")
+}
+
+func TestMetalsAPIProvider(t *testing.T) {
+	var (
+		requests    int
+		rateLimited bool
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		require.Equal(t, metalsAPILatestEndpoint, req.URL.Path)
+		require.Equal(t, metalsAPIReference, req.URL.Query().Get("base"))
+		require.Equal(t, "secret", req.URL.Query().Get("access_key"))
+
+		if rateLimited {
+			rw.Header().Set("Retry-After", "3600")
+			rw.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(rw, `{"success":true,"base":"USD","rates":{"XAU":0.0005,"USDXAU":2000,"XAG":0.04,"EUR":0.8}}`)
+	}))
+	defer server.Close()
+
+	_, err := NewMetalsAPIProvider(Endpoint{Name: MetalsAPI, Rest: server.URL})
+	require.EqualError(t, err, "metals-api requires an api key in the provider credentials")
+
+	p, err := NewMetalsAPIProvider(Endpoint{Name: MetalsAPI, Rest: server.URL, APIKey: "secret"})
+	require.NoError(t, err)
+	p.client = server.Client()
+
+	prices, err := p.GetTickerPrices(context.Background(),
+		types.CurrencyPair{Base: "XAU", Quote: "USD"},
+		types.CurrencyPair{Base: "XAG", Quote: "EUR"},
+	)
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewDec(2000), prices["XAUUSD"].Price)
+	require.Equal(t, sdk.NewDec(20), prices["XAGEUR"].Price)
+
+	// the rates are requested once per TTL
+	_, err = p.GetCandlePrices(context.Background(), types.CurrencyPair{Base: "XAU", Quote: "USD"})
+	require.NoError(t, err)
+	require.Equal(t, 1, requests)
+
+	// rate limited requests hold off the following ones
+	rateLimited = true
+	p.ratesAt = time.Now().Add(-metalsAPIRatesTTL)
+	_, err = p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "XAU", Quote: "USD"})
+	require.EqualError(t, err, "metalsapi failed to get rates: unexpected status: 429 Too Many Requests")
+	require.WithinDuration(t, time.Now().Add(time.Hour), p.retryAt, time.Minute)
+
+	_, err = p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "XAU", Quote: "USD"})
+	require.ErrorContains(t, err, "metalsapi rates requests held off until")
+	require.Equal(t, 2, requests)
+}
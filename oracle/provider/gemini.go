@@ -0,0 +1,447 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+const (
+	geminiWSHost             = "api.gemini.com"
+	geminiWSPath             = "/v2/marketdata"
+	geminiRestHost           = "https://api.gemini.com"
+	geminiRestPath           = "/v1/symbols"
+	geminiTypeSubscribe      = "subscribe"
+	geminiL2Subscription     = "l2"
+	geminiCandleSubscription = "candles_1m"
+	geminiTradeType          = "trade"
+	geminiL2UpdatesType      = "l2_updates"
+	geminiCandleUpdatesType  = "candles_1m_updates"
+	geminiHeartbeatType      = "heartbeat"
+	geminiCandleFields       = 6 // time, open, high, low, close and volume
+)
+
+// geminiCandlePeriod is the period of the candles, in milliseconds.
+var geminiCandlePeriod = int64(time.Minute / time.Millisecond)
+
+var _ Provider = (*GeminiProvider)(nil)
+
+type (
+	// GeminiProvider defines an Oracle provider implemented by the Gemini
+	// public market data API. Gemini doesn't push 24h tickers, so the ticker
+	// of a pair is the price of its last trade and the volume of its trades
+	// over the last 24 hours since subscribed. The trades are pushed on the
+	// l2 subscription, along with the order book updates which are ignored.
+	//
+	// REF: https://docs.gemini.com/websocket-api/#market-data-version-2
+	GeminiProvider struct {
+		wsc             *WebsocketController
+		logger          zerolog.Logger
+		mtx             sync.RWMutex
+		endpoints       Endpoint
+		trades          map[string]GeminiTrade        // Symbol => last GeminiTrade
+		volumes         map[string]*tradeVolume       // Symbol => volume of the trades
+		candles         map[string][]GeminiCandle     // Symbol => GeminiCandle
+		subscribedPairs map[string]types.CurrencyPair // Symbol => types.CurrencyPair
+	}
+
+	// GeminiMessage defines the type every message of the market data API
+	// has, which tells how to decode it.
+	GeminiMessage struct {
+		Type string `json:"type"` // ex.: trade, l2_updates, candles_1m_updates, heartbeat
+	}
+
+	// GeminiTrade defines a trade, pushed on its own or along with the initial
+	// order book of a pair.
+	GeminiTrade struct {
+		Type      string `json:"type"`      // trade
+		Symbol    string `json:"symbol"`    // Symbol ex.: ATOMUSD
+		Price     string `json:"price"`     // Price ex.: 11.05
+		Quantity  string `json:"quantity"`  // Quantity ex.: 12.5
+		TimeStamp int64  `json:"timestamp"` // Time in unix epoch ex.: 1678420554935
+	}
+
+	// GeminiL2UpdatesResponse defines an order book update, of which only the
+	// trades of the initial update are used.
+	GeminiL2UpdatesResponse struct {
+		Type   string        `json:"type"`   // l2_updates
+		Symbol string        `json:"symbol"` // Symbol ex.: ATOMUSD
+		Trades []GeminiTrade `json:"trades"`
+	}
+
+	// GeminiCandleResponse defines an update of the one minute candles of a
+	// pair, each change being the time in unix milliseconds, open, high, low,
+	// close and volume of a candle.
+	GeminiCandleResponse struct {
+		Type    string          `json:"type"`   // candles_1m_updates
+		Symbol  string          `json:"symbol"` // Symbol ex.: ATOMUSD
+		Changes [][]json.Number `json:"changes"`
+	}
+
+	// GeminiCandle defines a one minute candle of a pair.
+	GeminiCandle struct {
+		Symbol string // Symbol ex.: ATOMUSD
+		Start  int64  // Start time in unix epoch ex.: 1678775400000
+		Close  string // Price at close, or last price while open
+		Volume string // Volume during period
+	}
+
+	// GeminiSubscriptionMsg Msg to subscribe to the l2 and candles_1m feeds.
+	GeminiSubscriptionMsg struct {
+		Type          string               `json:"type"` // subscribe, unsubscribe
+		Subscriptions []GeminiSubscription `json:"subscriptions"`
+	}
+
+	// GeminiSubscription defines a feed subscribed to for symbols.
+	GeminiSubscription struct {
+		Name    string   `json:"name"`    // ex.: l2, candles_1m
+		Symbols []string `json:"symbols"` // ex.: ATOMUSD
+	}
+)
+
+func NewGeminiProvider(
+	ctx context.Context,
+	logger zerolog.Logger,
+	endpoints Endpoint,
+	pairs ...types.CurrencyPair,
+) (*GeminiProvider, error) {
+	if endpoints.Name != Gemini {
+		endpoints = Endpoint{
+			Name:      Gemini,
+			Rest:      geminiRestHost,
+			Websocket: geminiWSHost,
+		}
+	}
+
+	wsURL := url.URL{
+		Scheme: "wss",
+		Host:   endpoints.Websocket,
+		Path:   geminiWSPath,
+	}
+
+	geminiLogger := logger.With().Str("provider", "gemini").Logger()
+
+	provider := &GeminiProvider{
+		logger:          geminiLogger,
+		endpoints:       endpoints,
+		trades:          map[string]GeminiTrade{},
+		volumes:         map[string]*tradeVolume{},
+		candles:         map[string][]GeminiCandle{},
+		subscribedPairs: map[string]types.CurrencyPair{},
+	}
+
+	provider.setSubscribedPairs(pairs...)
+
+	provider.wsc = NewWebsocketController(
+		ctx,
+		Gemini,
+		wsURL,
+		provider.getSubscriptionMsgs(pairs...),
+		provider.messageReceived,
+		defaultPingDuration,
+		websocket.PingMessage,
+		geminiLogger,
+	)
+
+	return provider, nil
+}
+
+// Start connects to the websocket in the background, see Lifecycle.
+func (p *GeminiProvider) Start() error {
+	go p.wsc.Start()
+	return nil
+}
+
+// Stop closes the websocket, see Lifecycle.
+func (p *GeminiProvider) Stop() {
+	p.wsc.Stop()
+}
+
+// getSubscriptionMsgs returns the message subscribing to the l2 and
+// candles_1m feeds of the pairs.
+func (p *GeminiProvider) getSubscriptionMsgs(cps ...types.CurrencyPair) []interface{} {
+	if len(cps) == 0 {
+		return []interface{}{}
+	}
+
+	symbols := make([]string, len(cps))
+	for i, cp := range cps {
+		symbols[i] = cp.String()
+	}
+
+	return []interface{}{newGeminiSubscriptionMsg(symbols...)}
+}
+
+// SubscribeCurrencyPairs sends the new subscription messages to the websocket
+// and adds them to the providers subscribedPairs array.
+func (p *GeminiProvider) SubscribeCurrencyPairs(_ context.Context, cps ...types.CurrencyPair) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	newPairs := []types.CurrencyPair{}
+	for _, cp := range cps {
+		if _, ok := p.subscribedPairs[cp.String()]; !ok {
+			newPairs = append(newPairs, cp)
+		}
+	}
+
+	newSubscriptionMsgs := p.getSubscriptionMsgs(newPairs...)
+	if err := p.wsc.AddSubscriptionMsgs(newSubscriptionMsgs); err != nil {
+		return err
+	}
+	p.setSubscribedPairs(newPairs...)
+	return nil
+}
+
+// GetTickerPrices returns the tickerPrices based on the provided pairs.
+func (p *GeminiProvider) GetTickerPrices(
+	_ context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
+	tickerPrices := make(map[string]types.TickerPrice, len(pairs))
+
+	for _, cp := range pairs {
+		key := cp.String()
+		price, err := p.getTickerPrice(key)
+		if err != nil {
+			return nil, err
+		}
+		tickerPrices[key] = price
+	}
+
+	return tickerPrices, nil
+}
+
+// GetCandlePrices returns the candlePrices based on the provided pairs.
+func (p *GeminiProvider) GetCandlePrices(
+	_ context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
+	candlePrices := make(map[string][]types.CandlePrice, len(pairs))
+
+	for _, cp := range pairs {
+		key := cp.String()
+		prices, err := p.getCandlePrices(key)
+		if err != nil {
+			return nil, err
+		}
+		candlePrices[key] = prices
+	}
+
+	return candlePrices, nil
+}
+
+func (p *GeminiProvider) getTickerPrice(key string) (types.TickerPrice, error) {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	trade, ok := p.trades[key]
+	if !ok {
+		return types.TickerPrice{}, fmt.Errorf("gemini failed to get ticker price for %s", key)
+	}
+
+	price, err := sdk.NewDecFromStr(trade.Price)
+	if err != nil {
+		return types.TickerPrice{}, fmt.Errorf("failed to parse gemini price (%s) for %s: %w", trade.Price, key, err)
+	}
+
+	return types.TickerPrice{
+		Price:  price,
+		Volume: p.volumes[key].sum(time.Now()),
+	}, nil
+}
+
+func (p *GeminiProvider) getCandlePrices(key string) ([]types.CandlePrice, error) {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	candles, ok := p.candles[key]
+	if !ok {
+		return []types.CandlePrice{}, fmt.Errorf("gemini failed to get candle prices for %s", key)
+	}
+
+	candleList := []types.CandlePrice{}
+	for _, candle := range candles {
+		cp, err := candle.toCandlePrice()
+		if err != nil {
+			return []types.CandlePrice{}, err
+		}
+		candleList = append(candleList, cp)
+	}
+	return candleList, nil
+}
+
+func (p *GeminiProvider) messageReceived(_ int, bz []byte) {
+	var msg GeminiMessage
+	if err := json.Unmarshal(bz, &msg); err != nil {
+		p.logger.Error().
+			Int("length", len(bz)).
+			Err(err).
+			Msg("Error on receive message")
+		return
+	}
+
+	var err error
+	switch msg.Type {
+	case geminiTradeType:
+		var trade GeminiTrade
+		if err = json.Unmarshal(bz, &trade); err == nil {
+			p.setTrades(trade.Symbol, []GeminiTrade{trade})
+			p.logger.Trace().
+				Str(Gemini.String(), messageTypeTrade).
+				Msg("Websocket message received")
+		}
+
+	case geminiL2UpdatesType:
+		var l2Resp GeminiL2UpdatesResponse
+		if err = json.Unmarshal(bz, &l2Resp); err == nil && len(l2Resp.Trades) != 0 {
+			p.setTrades(l2Resp.Symbol, l2Resp.Trades)
+		}
+
+	case geminiCandleUpdatesType:
+		var candleResp GeminiCandleResponse
+		if err = json.Unmarshal(bz, &candleResp); err == nil {
+			err = p.setCandleChanges(candleResp)
+			p.logger.Trace().
+				Str(Gemini.String(), messageTypeCandle).
+				Msg("Websocket message received")
+		}
+
+	case geminiHeartbeatType:
+
+	default:
+		err = fmt.Errorf("unexpected message type %q", msg.Type)
+	}
+
+	if err != nil {
+		p.logger.Error().
+			Int("length", len(bz)).
+			Str("type", msg.Type).
+			Err(err).
+			Msg("Error on receive message")
+	}
+}
+
+// setTrades stores the last of the trades and adds their volume to the volume
+// of the pair.
+func (p *GeminiProvider) setTrades(symbol string, trades []GeminiTrade) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	volume, ok := p.volumes[symbol]
+	if !ok {
+		volume = &tradeVolume{}
+		p.volumes[symbol] = volume
+	}
+
+	for _, trade := range trades {
+		quantity, err := sdk.NewDecFromStr(trade.Quantity)
+		if err != nil {
+			p.logger.Error().Err(err).Str("symbol", symbol).Msg("failed to parse trade quantity")
+			continue
+		}
+		volume.add(trade.TimeStamp, quantity)
+
+		if last, ok := p.trades[symbol]; !ok || trade.TimeStamp >= last.TimeStamp {
+			p.trades[symbol] = trade
+		}
+	}
+}
+
+// setCandleChanges stores the candles of an update.
+func (p *GeminiProvider) setCandleChanges(candleResp GeminiCandleResponse) error {
+	for _, change := range candleResp.Changes {
+		if len(change) != geminiCandleFields {
+			return fmt.Errorf("unexpected number of candle fields: %d", len(change))
+		}
+		start, err := change[0].Int64()
+		if err != nil {
+			return err
+		}
+
+		p.setCandlePair(GeminiCandle{
+			Symbol: candleResp.Symbol,
+			Start:  start,
+			Close:  change[4].String(),
+			Volume: change[5].String(),
+		})
+	}
+	return nil
+}
+
+// setCandlePair stores the candle, replacing the previous update of the same
+// candle since Gemini pushes the candle in progress.
+func (p *GeminiProvider) setCandlePair(candle GeminiCandle) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	staleTime := PastUnixTime(providerCandlePeriod)
+	var candleList []GeminiCandle
+	candleList = append(candleList, candle)
+
+	for _, c := range p.candles[candle.Symbol] {
+		if staleTime < c.end() && c.Start != candle.Start {
+			candleList = append(candleList, c)
+		}
+	}
+	p.candles[candle.Symbol] = candleList
+}
+
+// end returns the end time of the candle, in unix milliseconds.
+func (candle GeminiCandle) end() int64 {
+	return candle.Start + geminiCandlePeriod
+}
+
+func (candle GeminiCandle) toCandlePrice() (types.CandlePrice, error) {
+	return types.NewCandlePrice(string(Gemini), candle.Symbol, candle.Close, candle.Volume, candle.end())
+}
+
+// setSubscribedPairs sets N currency pairs to the map of subscribed pairs.
+func (p *GeminiProvider) setSubscribedPairs(cps ...types.CurrencyPair) {
+	for _, cp := range cps {
+		p.subscribedPairs[cp.String()] = cp
+	}
+}
+
+// GetAvailablePairs returns all pairs to which the provider can subscribe.
+// ex.: map["ATOMUSD" => {}, "ETHUSD" => {}].
+func (p *GeminiProvider) GetAvailablePairs() (map[string]struct{}, error) {
+	resp, err := newDefaultHTTPClient().Get(p.endpoints.Rest + geminiRestPath)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var symbols []string
+	if err := json.NewDecoder(resp.Body).Decode(&symbols); err != nil {
+		return nil, err
+	}
+
+	availablePairs := make(map[string]struct{}, len(symbols))
+	for _, symbol := range symbols {
+		availablePairs[strings.ToUpper(symbol)] = struct{}{}
+	}
+
+	return availablePairs, nil
+}
+
+// newGeminiSubscriptionMsg returns a new subscription Msg to the l2 and
+// candles_1m feeds of the symbols.
+func newGeminiSubscriptionMsg(symbols ...string) GeminiSubscriptionMsg {
+	return GeminiSubscriptionMsg{
+		Type: geminiTypeSubscribe,
+		Subscriptions: []GeminiSubscription{
+			{Name: geminiL2Subscription, Symbols: symbols},
+			{Name: geminiCandleSubscription, Symbols: symbols},
+		},
+	}
+}
@@ -0,0 +1,170 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+func TestGeminiProvider_GetTickerPrices(t *testing.T) {
+	p, err := NewGeminiProvider(
+		context.TODO(),
+		zerolog.Nop(),
+		Endpoint{},
+		types.CurrencyPair{Base: "ATOM", Quote: "USD"},
+	)
+	require.NoError(t, err)
+
+	now := time.Now().UnixMilli()
+
+	t.Run("valid_request_single_ticker", func(t *testing.T) {
+		p.setTrades("ATOMUSD", []GeminiTrade{
+			{Price: "34.5", Quantity: "1000", TimeStamp: now - 1000},
+			{Price: lastPriceAtom, Quantity: "1396974.02", TimeStamp: now},
+		})
+
+		prices, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "ATOM", Quote: "USD"})
+		require.NoError(t, err)
+		require.Len(t, prices, 1)
+		require.Equal(t, sdk.MustNewDecFromStr(lastPriceAtom), prices["ATOMUSD"].Price)
+		require.Equal(t, sdk.MustNewDecFromStr("1397974.02"), prices["ATOMUSD"].Volume)
+	})
+
+	t.Run("valid_request_multi_ticker", func(t *testing.T) {
+		p.setTrades("OSMOUSD", []GeminiTrade{{Price: lastPriceOsmo, Quantity: volume, TimeStamp: now}})
+
+		prices, err := p.GetTickerPrices(
+			context.Background(),
+			types.CurrencyPair{Base: "ATOM", Quote: "USD"},
+			types.CurrencyPair{Base: "OSMO", Quote: "USD"},
+		)
+		require.NoError(t, err)
+		require.Len(t, prices, 2)
+		require.Equal(t, sdk.MustNewDecFromStr(lastPriceAtom), prices["ATOMUSD"].Price)
+		require.Equal(t, sdk.MustNewDecFromStr(lastPriceOsmo), prices["OSMOUSD"].Price)
+		require.Equal(t, sdk.MustNewDecFromStr(volume), prices["OSMOUSD"].Volume)
+	})
+
+	t.Run("late_trade_keeps_last_price", func(t *testing.T) {
+		p.setTrades("ATOMUSD", []GeminiTrade{{Price: "30", Quantity: "1", TimeStamp: now - 2000}})
+
+		prices, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "ATOM", Quote: "USD"})
+		require.NoError(t, err)
+		require.Equal(t, sdk.MustNewDecFromStr(lastPriceAtom), prices["ATOMUSD"].Price)
+		require.Equal(t, sdk.MustNewDecFromStr("1397975.02"), prices["ATOMUSD"].Volume)
+	})
+
+	t.Run("invalid_request_invalid_ticker", func(t *testing.T) {
+		prices, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "FOO", Quote: "BAR"})
+		require.EqualError(t, err, "gemini failed to get ticker price for FOOBAR")
+		require.Nil(t, prices)
+	})
+}
+
+func TestGeminiProvider_GetCandlePrices(t *testing.T) {
+	p, err := NewGeminiProvider(
+		context.TODO(),
+		zerolog.Nop(),
+		Endpoint{},
+		types.CurrencyPair{Base: "ATOM", Quote: "USD"},
+	)
+	require.NoError(t, err)
+
+	start := time.Now().UnixMilli()
+
+	t.Run("updated_candle_replaced", func(t *testing.T) {
+		candle := GeminiCandle{Symbol: "ATOMUSD", Start: start, Close: "34.5", Volume: "100"}
+		p.setCandlePair(candle)
+		candle.Close, candle.Volume = lastPriceAtom, "120"
+		p.setCandlePair(candle)
+
+		prices, err := p.GetCandlePrices(context.Background(), types.CurrencyPair{Base: "ATOM", Quote: "USD"})
+		require.NoError(t, err)
+		require.Len(t, prices["ATOMUSD"], 1)
+		require.Equal(t, sdk.MustNewDecFromStr(lastPriceAtom), prices["ATOMUSD"][0].Price)
+		require.Equal(t, sdk.MustNewDecFromStr("120"), prices["ATOMUSD"][0].Volume)
+		require.Equal(t, start+60000, prices["ATOMUSD"][0].TimeStamp)
+	})
+
+	t.Run("stale_candle_pruned", func(t *testing.T) {
+		p.setCandlePair(GeminiCandle{Symbol: "OSMOUSD", Start: start - 3600000, Close: "1", Volume: "1"})
+		p.setCandlePair(GeminiCandle{Symbol: "OSMOUSD", Start: start, Close: lastPriceOsmo, Volume: "1"})
+
+		prices, err := p.GetCandlePrices(context.Background(), types.CurrencyPair{Base: "OSMO", Quote: "USD"})
+		require.NoError(t, err)
+		require.Len(t, prices["OSMOUSD"], 1)
+		require.Equal(t, sdk.MustNewDecFromStr(lastPriceOsmo), prices["OSMOUSD"][0].Price)
+	})
+
+	t.Run("invalid_request_invalid_candle", func(t *testing.T) {
+		prices, err := p.GetCandlePrices(context.Background(), types.CurrencyPair{Base: "FOO", Quote: "BAR"})
+		require.EqualError(t, err, "gemini failed to get candle prices for FOOBAR")
+		require.Nil(t, prices)
+	})
+}
+
+func TestGeminiProvider_MessageReceived(t *testing.T) {
+	p, err := NewGeminiProvider(
+		context.TODO(),
+		zerolog.Nop(),
+		Endpoint{},
+		types.CurrencyPair{Base: "ATOM", Quote: "USD"},
+	)
+	require.NoError(t, err)
+	cp := types.CurrencyPair{Base: "ATOM", Quote: "USD"}
+
+	p.messageReceived(websocket.TextMessage, []byte(`{"type":"heartbeat","timestamp":1678420554935}`))
+	_, err = p.GetTickerPrices(context.Background(), cp)
+	require.Error(t, err)
+
+	// the initial order book update holds the recent trades
+	p.messageReceived(websocket.TextMessage, []byte(`{"type":"l2_updates","symbol":"ATOMUSD",`+
+		`"changes":[["buy","11.00","10"]],"trades":[{"type":"trade","symbol":"ATOMUSD","event_id":1,`+
+		`"timestamp":1678420554935,"price":"11.05","quantity":"12.5","side":"buy"}]}`))
+	prices, err := p.GetTickerPrices(context.Background(), cp)
+	require.NoError(t, err)
+	require.Equal(t, sdk.MustNewDecFromStr("11.05"), prices["ATOMUSD"].Price)
+
+	p.messageReceived(websocket.TextMessage, []byte(`{"type":"trade","symbol":"ATOMUSD","event_id":2,`+
+		`"timestamp":1678420555935,"price":"11.07","quantity":"1.5","side":"sell"}`))
+	prices, err = p.GetTickerPrices(context.Background(), cp)
+	require.NoError(t, err)
+	require.Equal(t, sdk.MustNewDecFromStr("11.07"), prices["ATOMUSD"].Price)
+
+	startTime := time.Now().Truncate(time.Minute).UnixMilli()
+	p.messageReceived(websocket.TextMessage, []byte(`{"type":"candles_1m_updates","symbol":"ATOMUSD",`+
+		`"changes":[[`+strconv.FormatInt(startTime, 10)+`,11,11.1,10.9,11.02,1234.5]]}`))
+	candles, err := p.GetCandlePrices(context.Background(), cp)
+	require.NoError(t, err)
+	require.Len(t, candles["ATOMUSD"], 1)
+	require.Equal(t, sdk.MustNewDecFromStr("11.02"), candles["ATOMUSD"][0].Price)
+	require.Equal(t, sdk.MustNewDecFromStr("1234.5"), candles["ATOMUSD"][0].Volume)
+	require.Equal(t, startTime+60000, candles["ATOMUSD"][0].TimeStamp)
+}
+
+func TestGeminiProvider_getSubscriptionMsgs(t *testing.T) {
+	provider := &GeminiProvider{
+		subscribedPairs: map[string]types.CurrencyPair{},
+	}
+	cps := []types.CurrencyPair{
+		{Base: "ATOM", Quote: "USD"},
+		{Base: "ETH", Quote: "USD"},
+	}
+
+	subMsgs := provider.getSubscriptionMsgs(cps...)
+
+	msg, _ := json.Marshal(subMsgs[0])
+	require.Equal(t, `{"type":"subscribe","subscriptions":[{"name":"l2","symbols":["ATOMUSD","ETHUSD"]},`+
+		`{"name":"candles_1m","symbols":["ATOMUSD","ETHUSD"]}]}`, string(msg))
+
+	require.Empty(t, provider.getSubscriptionMsgs())
+}
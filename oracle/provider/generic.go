@@ -0,0 +1,267 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+	"github.com/persistenceOne/oracle-feeder/pkg/jsonpath"
+)
+
+// defaultGenericInterval is the interval a query of the generic provider is
+// polled at most once per, unless set.
+const defaultGenericInterval = time.Minute
+
+var _ Provider = (*GenericProvider)(nil)
+
+type (
+	// GenericProvider defines an Oracle provider polling any REST API quoting
+	// the currency pairs, configured by the queries of its endpoint, so that
+	// minor exchanges can be onboarded without new code. Every query requests
+	// a URL, at most once per interval, and selects the price and, if set,
+	// the volume of its pair in the JSON response with JSONPath expressions.
+	//
+	// Without a volume, every price weighs as a unit of volume. Candles are
+	// the current prices.
+	GenericProvider struct {
+		client  *http.Client
+		queries map[string]genericQuery // keyed by pair symbol
+
+		mtx     sync.Mutex
+		results map[string]genericResult // keyed by pair symbol
+	}
+
+	genericQuery struct {
+		url      string
+		price    jsonpath.Path
+		volume   *jsonpath.Path
+		interval time.Duration
+	}
+
+	// genericResult defines the result of the last poll of a query, either
+	// its ticker or the error it failed with.
+	genericResult struct {
+		ticker   types.TickerPrice
+		err      error
+		polledAt time.Time
+	}
+)
+
+// NewGenericProvider returns a generic provider of the queries of the
+// endpoint.
+func NewGenericProvider(endpoint Endpoint) (*GenericProvider, error) {
+	if len(endpoint.Queries) == 0 {
+		return nil, fmt.Errorf("generic provider requires queries")
+	}
+
+	queries := make(map[string]genericQuery, len(endpoint.Queries))
+	for _, q := range endpoint.Queries {
+		query, err := q.parse()
+		if err != nil {
+			return nil, err
+		}
+		queries[q.pair().String()] = query
+	}
+
+	return &GenericProvider{
+		client:  newDefaultHTTPClient(),
+		queries: queries,
+		results: make(map[string]genericResult, len(queries)),
+	}, nil
+}
+
+// Validate returns whether the query is valid, see GenericProvider.
+func (q Query) Validate() error {
+	_, err := q.parse()
+	return err
+}
+
+func (q Query) pair() types.CurrencyPair {
+	return types.CurrencyPair{Base: strings.ToUpper(q.Base), Quote: strings.ToUpper(q.Quote)}
+}
+
+func (q Query) parse() (genericQuery, error) {
+	if len(q.Base) == 0 || len(q.Quote) == 0 || len(q.URL) == 0 {
+		return genericQuery{}, fmt.Errorf("generic query requires a base, a quote and a url")
+	}
+
+	query := genericQuery{url: q.URL, interval: defaultGenericInterval}
+	var err error
+	if query.price, err = jsonpath.Parse(q.Price); err != nil {
+		return genericQuery{}, fmt.Errorf("generic query of %s has an invalid price: %w", q.pair(), err)
+	}
+	if len(q.Volume) > 0 {
+		volume, err := jsonpath.Parse(q.Volume)
+		if err != nil {
+			return genericQuery{}, fmt.Errorf("generic query of %s has an invalid volume: %w", q.pair(), err)
+		}
+		query.volume = &volume
+	}
+	if len(q.Interval) > 0 {
+		if query.interval, err = time.ParseDuration(q.Interval); err != nil || query.interval <= 0 {
+			return genericQuery{}, fmt.Errorf("generic query of %s has an invalid interval: %s", q.pair(), q.Interval)
+		}
+	}
+
+	return query, nil
+}
+
+// SubscribeCurrencyPairs performs a no-op since the queries are polled on
+// demand.
+func (*GenericProvider) SubscribeCurrencyPairs(context.Context, ...types.CurrencyPair) error {
+	return nil
+}
+
+// GetTickerPrices returns the tickers of the given pairs.
+func (p *GenericProvider) GetTickerPrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
+	return p.getTickers(ctx, pairs)
+}
+
+// GetCandlePrices returns a candle of the current price of each of the given
+// pairs.
+func (p *GenericProvider) GetCandlePrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
+	tickers, err := p.getTickers(ctx, pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	candles := make(map[string][]types.CandlePrice, len(tickers))
+	for symbol, ticker := range tickers {
+		candles[symbol] = []types.CandlePrice{{Price: ticker.Price, Volume: ticker.Volume, TimeStamp: now}}
+	}
+
+	return candles, nil
+}
+
+// getTickers returns the tickers of the pairs, polling the queries whose last
+// poll is older than their interval. The queries sharing a URL request it
+// once.
+func (p *GenericProvider) getTickers(
+	ctx context.Context,
+	pairs []types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	responses := make(map[string][]byte)
+	tickers := make(map[string]types.TickerPrice, len(pairs))
+	for _, cp := range pairs {
+		query, ok := p.queries[cp.String()]
+		if !ok {
+			return nil, fmt.Errorf("generic has no query for %s", cp.String())
+		}
+
+		result, ok := p.results[cp.String()]
+		if !ok || time.Since(result.polledAt) >= query.interval {
+			result = genericResult{polledAt: time.Now()}
+			bz, ok := responses[query.url]
+			if !ok {
+				bz, result.err = p.get(ctx, query.url)
+				if result.err == nil {
+					responses[query.url] = bz
+				}
+			}
+			if result.err == nil {
+				result.ticker, result.err = query.ticker(bz)
+			}
+			p.results[cp.String()] = result
+		}
+
+		if result.err != nil {
+			return nil, fmt.Errorf("generic failed to get %s: %w", cp.String(), result.err)
+		}
+		tickers[cp.String()] = result.ticker
+	}
+
+	return tickers, nil
+}
+
+// ticker returns the ticker selected by the query in the response bz.
+func (q genericQuery) ticker(bz []byte) (types.TickerPrice, error) {
+	price, err := q.selectDec(q.price, bz)
+	if err != nil {
+		return types.TickerPrice{}, err
+	}
+	if !price.IsPositive() {
+		return types.TickerPrice{}, fmt.Errorf("invalid price: %s", price)
+	}
+
+	volume := sdk.OneDec()
+	if q.volume != nil {
+		if volume, err = q.selectDec(*q.volume, bz); err != nil {
+			return types.TickerPrice{}, err
+		}
+	}
+
+	return types.TickerPrice{Price: price, Volume: volume}, nil
+}
+
+// selectDec returns the number, or numeric string, selected by path in bz.
+func (genericQuery) selectDec(path jsonpath.Path, bz []byte) (sdk.Dec, error) {
+	value, err := path.Get(bz)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+
+	var s string
+	switch v := value.(type) {
+	case json.Number:
+		s = v.String()
+	case string:
+		s = v
+	default:
+		return sdk.Dec{}, fmt.Errorf("%s: not a number: %v", path, value)
+	}
+
+	if dec, err := sdk.NewDecFromStr(s); err == nil {
+		return dec, nil
+	}
+	// e.g. exponents
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return sdk.Dec{}, fmt.Errorf("%s: not a number: %s", path, s)
+	}
+	return floatToDec(f), nil
+}
+
+// get requests the URL, bounded by ctx, and returns the response body.
+func (p *GenericProvider) get(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkHTTPStatus(resp); err != nil {
+		return nil, err
+	}
+
+	bz, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s response body: %w", Generic, err)
+	}
+	capturePayload(Generic, bz)
+
+	return bz, nil
+}
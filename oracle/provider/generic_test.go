@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+func TestGenericProvider(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		switch req.URL.Path {
+		case "/tickers":
+			_, _ = rw.Write([]byte(`{"data": [
+				{"symbol": "ATOM_USDT", "last": "10.5", "vol": 2500},
+				{"symbol": "OSMO_USDT", "last": 5e-1, "vol": "1000.25"}
+			]}`))
+		case "/price":
+			_, _ = rw.Write([]byte(`{"result": {"price": "0"}}`))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p, err := NewGenericProvider(Endpoint{
+		Name: Generic,
+		Queries: []Query{
+			{Base: "ATOM", Quote: "USDT", URL: server.URL + "/tickers", Price: "$.data[0].last", Volume: "$.data[0].vol"},
+			{Base: "osmo", Quote: "usdt", URL: server.URL + "/tickers", Price: "$.data[1].last", Volume: "$.data[1].vol"},
+			{Base: "JUNO", Quote: "USDT", URL: server.URL + "/price", Price: "$.result.price", Interval: "1h"},
+			{Base: "STARS", Quote: "USDT", URL: server.URL + "/missing", Price: "$.price"},
+		},
+	})
+	require.NoError(t, err)
+	p.client = server.Client()
+
+	atomUSDT := types.CurrencyPair{Base: "ATOM", Quote: "USDT"}
+	osmoUSDT := types.CurrencyPair{Base: "OSMO", Quote: "USDT"}
+
+	t.Run("ticker", func(t *testing.T) {
+		prices, err := p.GetTickerPrices(context.Background(), atomUSDT, osmoUSDT)
+		require.NoError(t, err)
+		require.Equal(t, types.TickerPrice{Price: sdk.MustNewDecFromStr("10.5"), Volume: sdk.NewDec(2500)},
+			prices["ATOMUSDT"])
+		require.Equal(t, types.TickerPrice{Price: sdk.MustNewDecFromStr("0.5"), Volume: sdk.MustNewDecFromStr("1000.25")},
+			prices["OSMOUSDT"])
+
+		// the queries sharing a URL request it once, and only once per
+		// interval
+		require.Equal(t, 1, requests)
+		_, err = p.GetTickerPrices(context.Background(), atomUSDT)
+		require.NoError(t, err)
+		require.Equal(t, 1, requests)
+
+		p.results["ATOMUSDT"] = genericResult{polledAt: time.Now().Add(-defaultGenericInterval)}
+		_, err = p.GetTickerPrices(context.Background(), atomUSDT)
+		require.NoError(t, err)
+		require.Equal(t, 2, requests)
+	})
+
+	t.Run("candles", func(t *testing.T) {
+		candles, err := p.GetCandlePrices(context.Background(), atomUSDT)
+		require.NoError(t, err)
+		require.Len(t, candles["ATOMUSDT"], 1)
+		require.Equal(t, sdk.MustNewDecFromStr("10.5"), candles["ATOMUSDT"][0].Price)
+		require.Greater(t, candles["ATOMUSDT"][0].TimeStamp, PastUnixTime(providerCandlePeriod))
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		_, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "JUNO", Quote: "USDT"})
+		require.ErrorContains(t, err, "invalid price")
+
+		_, err = p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "STARS", Quote: "USDT"})
+		require.ErrorContains(t, err, "unexpected status")
+
+		_, err = p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "BTC", Quote: "USDT"})
+		require.ErrorContains(t, err, "no query for BTCUSDT")
+	})
+}
+
+func TestQueryValidate(t *testing.T) {
+	valid := Query{Base: "ATOM", Quote: "USDT", URL: "https://example.com", Price: "$.last", Interval: "30s"}
+	require.NoError(t, valid.Validate())
+
+	invalid := valid
+	invalid.URL = ""
+	require.Error(t, invalid.Validate())
+
+	invalid = valid
+	invalid.Price = "last"
+	require.ErrorContains(t, invalid.Validate(), "invalid price")
+
+	invalid = valid
+	invalid.Volume = "$..vol"
+	require.ErrorContains(t, invalid.Validate(), "invalid volume")
+
+	invalid = valid
+	invalid.Interval = "-1m"
+	require.ErrorContains(t, invalid.Validate(), "invalid interval")
+}
@@ -0,0 +1,190 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultHTTPClientConfig defines the transport settings of the REST
+// providers unless configured otherwise.
+var DefaultHTTPClientConfig = HTTPClientConfig{
+	DialTimeout:         5 * time.Second,
+	KeepAlive:           30 * time.Second,
+	TLSHandshakeTimeout: 5 * time.Second,
+	IdleConnTimeout:     90 * time.Second,
+	MaxIdleConns:        100,
+	MaxIdleConnsPerHost: 10,
+	DNSCacheTTL:         time.Minute,
+}
+
+// httpTransport is the transport shared by the HTTP clients of all providers.
+var (
+	httpTransportMtx sync.RWMutex
+	httpTransport    = NewHTTPTransport(DefaultHTTPClientConfig)
+)
+
+type (
+	// HTTPClientConfig defines the transport shared by the HTTP clients of the
+	// REST providers, so that connections and resolved addresses are reused
+	// across ticks instead of being set up again for every request. A zero
+	// MaxConnsPerHost means no limit and a zero DNSCacheTTL disables caching.
+	HTTPClientConfig struct {
+		DialTimeout         time.Duration
+		KeepAlive           time.Duration
+		TLSHandshakeTimeout time.Duration
+		IdleConnTimeout     time.Duration
+		MaxIdleConns        int
+		MaxIdleConnsPerHost int
+		MaxConnsPerHost     int
+		DNSCacheTTL         time.Duration
+	}
+
+	// dnsCache caches the addresses hosts resolve to for a TTL. The entry of a
+	// host is dropped when none of its addresses can be dialed, so that a
+	// rotated address is picked up on the next attempt.
+	dnsCache struct {
+		ttl        time.Duration
+		lookupHost func(ctx context.Context, host string) ([]string, error)
+		now        func() time.Time
+
+		mtx     sync.Mutex
+		entries map[string]dnsCacheEntry
+	}
+
+	dnsCacheEntry struct {
+		addrs   []string
+		expires time.Time
+	}
+)
+
+// NewHTTPTransport returns a transport pooling and keeping alive connections
+// as configured by cfg.
+func NewHTTPTransport(cfg HTTPClientConfig) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   cfg.DialTimeout,
+		KeepAlive: cfg.KeepAlive,
+	}
+
+	dialContext := dialer.DialContext
+	if cfg.DNSCacheTTL > 0 {
+		dialContext = newDNSCache(cfg.DNSCacheTTL, net.DefaultResolver.LookupHost).dialContext(dialer)
+	}
+
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialContext,
+		ForceAttemptHTTP2:     true,
+		TLSHandshakeTimeout:   cfg.TLSHandshakeTimeout,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// SetHTTPClientConfig replaces the transport shared by the HTTP clients of
+// the providers created from now on.
+func SetHTTPClientConfig(cfg HTTPClientConfig) {
+	transport := NewHTTPTransport(cfg)
+
+	httpTransportMtx.Lock()
+	previous := httpTransport
+	httpTransport = transport
+	httpTransportMtx.Unlock()
+
+	previous.CloseIdleConnections()
+}
+
+// sharedHTTPTransport returns the transport shared by the HTTP clients of the
+// providers.
+func sharedHTTPTransport() *http.Transport {
+	httpTransportMtx.RLock()
+	defer httpTransportMtx.RUnlock()
+
+	return httpTransport
+}
+
+// NewHTTPClient returns a client with the given timeout using the transport
+// shared by the providers, for the other outbound requests of the feeder.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: sharedHTTPTransport(),
+		Timeout:   timeout,
+	}
+}
+
+func newDNSCache(ttl time.Duration, lookupHost func(context.Context, string) ([]string, error)) *dnsCache {
+	return &dnsCache{
+		ttl:        ttl,
+		lookupHost: lookupHost,
+		now:        time.Now,
+		entries:    make(map[string]dnsCacheEntry),
+	}
+}
+
+// lookup returns the cached addresses of host, resolving them if missing or
+// expired.
+func (c *dnsCache) lookup(ctx context.Context, host string) ([]string, error) {
+	c.mtx.Lock()
+	entry, ok := c.entries[host]
+	c.mtx.Unlock()
+	if ok && c.now().Before(entry.expires) {
+		return entry.addrs, nil
+	}
+
+	addrs, err := c.lookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mtx.Lock()
+	c.entries[host] = dnsCacheEntry{addrs: addrs, expires: c.now().Add(c.ttl)}
+	c.mtx.Unlock()
+
+	return addrs, nil
+}
+
+// forget drops the cached addresses of host.
+func (c *dnsCache) forget(host string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	delete(c.entries, host)
+}
+
+// dialContext returns a dial function connecting with dialer to the first
+// reachable cached address of the host.
+func (c *dnsCache) dialContext(dialer *net.Dialer) func(context.Context, string, string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || isIPLiteral(host) {
+			return dialer.DialContext(ctx, network, addr)
+		}
+
+		addrs, err := c.lookup(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		err = fmt.Errorf("no %s address found for %s", network, host)
+		for _, ip := range addrs {
+			if !matchesNetwork(network, net.ParseIP(ip)) {
+				continue
+			}
+
+			var conn net.Conn
+			conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				return conn, nil
+			}
+		}
+
+		c.forget(host)
+		return nil, err
+	}
+}
@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDNSCacheLookup(t *testing.T) {
+	lookups := 0
+	cache := newDNSCache(time.Minute, func(context.Context, string) ([]string, error) {
+		lookups++
+		return []string{"127.0.0.1"}, nil
+	})
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		addrs, err := cache.lookup(context.Background(), "api.example.com")
+		require.NoError(t, err)
+		require.Equal(t, []string{"127.0.0.1"}, addrs)
+	}
+	require.Equal(t, 1, lookups)
+
+	now = now.Add(time.Minute)
+	_, err := cache.lookup(context.Background(), "api.example.com")
+	require.NoError(t, err)
+	require.Equal(t, 2, lookups)
+}
+
+func TestDNSCacheDial(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	_, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+	require.NoError(t, err)
+
+	addrs := []string{"127.0.0.1"}
+	cache := newDNSCache(time.Minute, func(context.Context, string) ([]string, error) {
+		return addrs, nil
+	})
+	dial := cache.dialContext(&net.Dialer{Timeout: time.Second})
+
+	conn, err := dial(context.Background(), "tcp", net.JoinHostPort("api.example.com", port))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+	require.Contains(t, cache.entries, "api.example.com")
+
+	// the server moved, the stale address is dropped after failing to dial it
+	srv.Close()
+	_, err = dial(context.Background(), "tcp", net.JoinHostPort("api.example.com", port))
+	require.Error(t, err)
+	require.NotContains(t, cache.entries, "api.example.com")
+}
+
+func TestDNSCacheLookupError(t *testing.T) {
+	lookupErr := errors.New("no such host")
+	cache := newDNSCache(time.Minute, func(context.Context, string) ([]string, error) {
+		return nil, lookupErr
+	})
+
+	_, err := cache.dialContext(&net.Dialer{})(context.Background(), "tcp", "api.example.com:443")
+	require.ErrorIs(t, err, lookupErr)
+	require.Empty(t, cache.entries)
+}
+
+func TestSetHTTPClientConfig(t *testing.T) {
+	defer SetHTTPClientConfig(DefaultHTTPClientConfig)
+
+	cfg := DefaultHTTPClientConfig
+	cfg.MaxConnsPerHost = 4
+	SetHTTPClientConfig(cfg)
+
+	client := newDefaultHTTPClient()
+	transport, ok := client.Transport.(*http.Transport)
+	require.True(t, ok)
+	require.Equal(t, 4, transport.MaxConnsPerHost)
+	require.Same(t, transport, newDefaultHTTPClient().Transport)
+}
@@ -0,0 +1,269 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+const (
+	// HubStreamPath is the path of the provider stream served by a hub.
+	HubStreamPath = "/api/v1/providers/stream"
+
+	// HubEventProviders is the type of the events of the provider stream.
+	HubEventProviders = "providers"
+
+	// hubMaxEventSize bounds the size of an event of the provider stream,
+	// which holds the candles of every pair of every provider of the hub.
+	hubMaxEventSize = 16 << 20
+
+	// hubMaxSnapshotAge is the age of the last event of a hub after which its
+	// prices are no longer served, e.g. while it's unreachable.
+	hubMaxSnapshotAge = time.Minute
+)
+
+var _ Provider = (*HubProvider)(nil)
+
+type (
+	// Snapshot defines the ticker and candle prices a provider returned on the
+	// last tick of an instance, keyed by pair.
+	Snapshot struct {
+		Tickers map[string]types.TickerPrice   `json:"tickers"`
+		Candles map[string][]types.CandlePrice `json:"candles"`
+	}
+
+	// HubEvent defines an event of the provider stream of a hub, sent after
+	// every tick with the snapshots of all of its providers.
+	HubEvent struct {
+		Timestamp int64             `json:"timestamp"` // Time in unix epoch ex.: 1678420554935
+		Providers map[Name]Snapshot `json:"providers"`
+	}
+
+	// HubClient consumes the provider stream of a price-feeder instance acting
+	// as a hub, i.e. maintaining the exchange connections on behalf of other
+	// instances on the same network. The stream is a Server-Sent Events
+	// stream, reconnected to whenever it fails.
+	HubClient struct {
+		logger zerolog.Logger
+		url    string
+		client *http.Client
+
+		mtx        sync.RWMutex
+		event      HubEvent
+		receivedAt time.Time
+	}
+
+	// HubProvider defines an Oracle provider serving the prices a hub
+	// received from one of its providers, under the name of that provider.
+	// It doesn't subscribe to pairs: the hub must be configured with the
+	// pairs of the instances consuming it.
+	HubProvider struct {
+		hub  *HubClient
+		name Name
+	}
+)
+
+// NewHubClient returns a client of the provider stream of the hub at
+// hubURL, ex.: "http://10.0.0.5:7171", which starts consuming it in the
+// background until ctx is done.
+func NewHubClient(ctx context.Context, logger zerolog.Logger, hubURL string) *HubClient {
+	hub := &HubClient{
+		logger: logger.With().Str("module", "hub").Logger(),
+		url:    strings.TrimSuffix(hubURL, "/") + HubStreamPath,
+		// the stream is long-lived, its liveness is checked by the age of its
+		// last event instead
+		client: newHTTPClientWithTimeout(0),
+	}
+	go hub.start(ctx)
+
+	return hub
+}
+
+// start consumes the stream until ctx is done. A stream which delivered
+// events is reconnected to right away, e.g. once closed by the write timeout
+// of the hub, otherwise with an increasing delay.
+func (hub *HubClient) start(ctx context.Context) {
+	var retries int64
+	for {
+		connectedAt := time.Now()
+		err := hub.consume(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		hub.mtx.RLock()
+		delivered := hub.receivedAt.After(connectedAt)
+		hub.mtx.RUnlock()
+		if delivered {
+			retries = 0
+			continue
+		}
+		hub.logger.Err(err).Msg("hub stream failed")
+
+		retries++
+		delay := time.Duration(retries) * startingReconnectDuration
+		if delay > hubMaxSnapshotAge {
+			delay = hubMaxSnapshotAge
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// consume reads the events of the stream until it ends.
+func (hub *HubClient) consume(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, hub.url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := hub.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := checkHTTPStatus(resp); err != nil {
+		return err
+	}
+
+	hub.logger.Info().Msg("connected to hub stream")
+	return hub.readEvents(resp.Body)
+}
+
+// readEvents reads the Server-Sent Events of r, storing the provider ones.
+func (hub *HubClient) readEvents(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), hubMaxEventSize)
+
+	var (
+		eventType string
+		data      bytes.Buffer
+	)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case len(line) == 0:
+			// a blank line dispatches the event
+			if eventType == HubEventProviders && data.Len() > 0 {
+				hub.setEvent(data.Bytes())
+			}
+			eventType = ""
+			data.Reset()
+
+		case strings.HasPrefix(line, "event:"):
+			eventType = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return io.ErrUnexpectedEOF
+}
+
+// setEvent stores a provider event as the latest prices of the hub.
+func (hub *HubClient) setEvent(bz []byte) {
+	var event HubEvent
+	if err := json.Unmarshal(bz, &event); err != nil {
+		hub.logger.Err(err).Msg("failed to decode hub event")
+		return
+	}
+
+	hub.mtx.Lock()
+	defer hub.mtx.Unlock()
+
+	hub.event = event
+	hub.receivedAt = time.Now()
+}
+
+// snapshot returns the latest snapshot of a provider of the hub, which is
+// missing when the hub didn't send any event for hubMaxSnapshotAge.
+func (hub *HubClient) snapshot(name Name) (Snapshot, bool) {
+	hub.mtx.RLock()
+	defer hub.mtx.RUnlock()
+
+	if time.Since(hub.receivedAt) > hubMaxSnapshotAge {
+		return Snapshot{}, false
+	}
+
+	snapshot, ok := hub.event.Providers[name]
+	return snapshot, ok
+}
+
+// NewHubProvider returns a provider serving the prices the hub received from
+// the provider name.
+func NewHubProvider(hub *HubClient, name Name) *HubProvider {
+	return &HubProvider{
+		hub:  hub,
+		name: name,
+	}
+}
+
+// SubscribeCurrencyPairs is a no-op, as the pairs are subscribed to by the
+// hub.
+func (p *HubProvider) SubscribeCurrencyPairs(context.Context, ...types.CurrencyPair) error {
+	return nil
+}
+
+// GetTickerPrices returns the tickerPrices based on the provided pairs.
+func (p *HubProvider) GetTickerPrices(
+	_ context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
+	snapshot, _ := p.hub.snapshot(p.name)
+
+	tickerPrices := make(map[string]types.TickerPrice, len(pairs))
+	for _, cp := range pairs {
+		key := cp.String()
+		price, ok := snapshot.Tickers[key]
+		if !ok {
+			return nil, fmt.Errorf("%s failed to get ticker price for %s from hub", p.name, key)
+		}
+		tickerPrices[key] = price
+	}
+
+	return tickerPrices, nil
+}
+
+// GetCandlePrices returns the candlePrices based on the provided pairs.
+func (p *HubProvider) GetCandlePrices(
+	_ context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
+	snapshot, _ := p.hub.snapshot(p.name)
+
+	candlePrices := make(map[string][]types.CandlePrice, len(pairs))
+	for _, cp := range pairs {
+		key := cp.String()
+		candles, ok := snapshot.Candles[key]
+		if !ok {
+			return nil, fmt.Errorf("%s failed to get candle prices for %s from hub", p.name, key)
+		}
+		candlePrices[key] = candles
+	}
+
+	return candlePrices, nil
+}
@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+func TestHubProvider(t *testing.T) {
+	event := HubEvent{
+		Timestamp: time.Now().UnixMilli(),
+		Providers: map[Name]Snapshot{
+			Binance: {
+				Tickers: map[string]types.TickerPrice{
+					"ATOMUSDT": {Price: sdk.MustNewDecFromStr(lastPriceAtom), Volume: sdk.MustNewDecFromStr(volume)},
+				},
+				Candles: map[string][]types.CandlePrice{
+					"ATOMUSDT": {{
+						Price:     sdk.MustNewDecFromStr(lastPriceAtom),
+						Volume:    sdk.MustNewDecFromStr(volume),
+						TimeStamp: 1678776360000,
+					}},
+				},
+			},
+		},
+	}
+	bz, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != HubStreamPath {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "retry: 5000\n\n: keep-alive\n\nevent: prices\ndata: {}\n\n")
+		fmt.Fprintf(w, "id: 1\nevent: %s\ndata: %s\n\n", HubEventProviders, bz)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hub := NewHubClient(ctx, zerolog.Nop(), server.URL+"/")
+	p := NewHubProvider(hub, Binance)
+	cp := types.CurrencyPair{Base: "ATOM", Quote: "USDT"}
+
+	require.Eventually(t, func() bool {
+		_, err := p.GetTickerPrices(context.Background(), cp)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	prices, err := p.GetTickerPrices(context.Background(), cp)
+	require.NoError(t, err)
+	require.Equal(t, sdk.MustNewDecFromStr(lastPriceAtom), prices["ATOMUSDT"].Price)
+	require.Equal(t, sdk.MustNewDecFromStr(volume), prices["ATOMUSDT"].Volume)
+
+	candles, err := p.GetCandlePrices(context.Background(), cp)
+	require.NoError(t, err)
+	require.Equal(t, event.Providers[Binance].Candles["ATOMUSDT"], candles["ATOMUSDT"])
+
+	require.NoError(t, p.SubscribeCurrencyPairs(context.Background(), types.CurrencyPair{Base: "FOO", Quote: "BAR"}))
+	_, err = p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "FOO", Quote: "BAR"})
+	require.EqualError(t, err, "binance failed to get ticker price for FOOBAR from hub")
+
+	_, err = NewHubProvider(hub, Kraken).GetCandlePrices(context.Background(), cp)
+	require.EqualError(t, err, "kraken failed to get candle prices for ATOMUSDT from hub")
+}
+
+func TestHubClient_StaleSnapshot(t *testing.T) {
+	hub := &HubClient{logger: zerolog.Nop()}
+	err := hub.readEvents(strings.NewReader(
+		"event: providers\ndata: {\"providers\":{\"binance\":{\"tickers\":{}}}}\n\n",
+	))
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	_, ok := hub.snapshot(Binance)
+	require.True(t, ok)
+
+	hub.receivedAt = time.Now().Add(-2 * hubMaxSnapshotAge)
+	_, ok = hub.snapshot(Binance)
+	require.False(t, ok)
+}
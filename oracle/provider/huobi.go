@@ -7,7 +7,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
@@ -139,11 +138,21 @@ func NewHuobiProvider(
 		websocket.PingMessage,
 		huobiLogger,
 	)
-	go provider.wsc.Start()
 
 	return provider, nil
 }
 
+// Start connects to the websocket in the background, see Lifecycle.
+func (p *HuobiProvider) Start() error {
+	go p.wsc.Start()
+	return nil
+}
+
+// Stop closes the websocket, see Lifecycle.
+func (p *HuobiProvider) Stop() {
+	p.wsc.Stop()
+}
+
 func (p *HuobiProvider) getSubscriptionMsgs(cps ...types.CurrencyPair) []interface{} {
 	subscriptionMsgs := make([]interface{}, 0, len(cps)*2) //nolint: gomnd //const
 	for _, cp := range cps {
@@ -155,7 +164,7 @@ func (p *HuobiProvider) getSubscriptionMsgs(cps ...types.CurrencyPair) []interfa
 
 // SubscribeCurrencyPairs sends the new subscription messages to the websocket
 // and adds them to the providers subscribedPairs array.
-func (p *HuobiProvider) SubscribeCurrencyPairs(cps ...types.CurrencyPair) error {
+func (p *HuobiProvider) SubscribeCurrencyPairs(_ context.Context, cps ...types.CurrencyPair) error {
 	p.mtx.Lock()
 	defer p.mtx.Unlock()
 
@@ -175,7 +184,10 @@ func (p *HuobiProvider) SubscribeCurrencyPairs(cps ...types.CurrencyPair) error
 }
 
 // GetTickerPrices returns the tickerPrices based on the saved map.
-func (p *HuobiProvider) GetTickerPrices(pairs ...types.CurrencyPair) (map[string]types.TickerPrice, error) {
+func (p *HuobiProvider) GetTickerPrices(
+	_ context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
 	tickerPrices := make(map[string]types.TickerPrice, len(pairs))
 
 	for _, cp := range pairs {
@@ -190,7 +202,10 @@ func (p *HuobiProvider) GetTickerPrices(pairs ...types.CurrencyPair) (map[string
 }
 
 // GetTickerPrices returns the tickerPrices based on the saved map.
-func (p *HuobiProvider) GetCandlePrices(pairs ...types.CurrencyPair) (map[string][]types.CandlePrice, error) {
+func (p *HuobiProvider) GetCandlePrices(
+	_ context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
 	candlePrices := make(map[string][]types.CandlePrice, len(pairs))
 
 	for _, cp := range pairs {
@@ -217,6 +232,7 @@ func (p *HuobiProvider) messageReceived(messageType int, bz []byte) {
 		p.logger.Err(err).Msg("failed to decompress gziped message")
 		return
 	}
+	capturePayload(Huobi, bz)
 
 	if bytes.Contains(bz, ping) {
 		p.pong(bz)
@@ -354,7 +370,7 @@ func (p *HuobiProvider) setSubscribedPairs(cps ...types.CurrencyPair) {
 
 // GetAvailablePairs returns all pairs to which the provider can subscribe.
 func (p *HuobiProvider) GetAvailablePairs() (map[string]struct{}, error) {
-	resp, err := http.Get(p.endpoints.Rest + huobiRestPath)
+	resp, err := newDefaultHTTPClient().Get(p.endpoints.Rest + huobiRestPath)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,256 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+// interchainOracleMethods are the exchange rates queries of the x/oracle
+// modules of the chains the interchain oracle providers read, all of which
+// answer with the USD exchange rates of every denom when no denom is given.
+var interchainOracleMethods = map[Name]string{
+	Umee:   "/umee.oracle.v1.Query/ExchangeRates",
+	Ojo:    "/ojo.oracle.v1.Query/ExchangeRates",
+	Kujira: "/kujira.oracle.Query/ExchangeRates",
+}
+
+// interchainOracleQuote is the quote of the exchange rates of the oracles.
+const interchainOracleQuote = "USD"
+
+var _ Provider = (*InterchainOracleProvider)(nil)
+
+type (
+	// InterchainOracleProvider defines an Oracle provider reading the exchange
+	// rates voted by the validators of another Cosmos chain from its x/oracle
+	// module over gRPC, e.g. of Umee, Ojo or Kujira, to cross-reference them
+	// with the prices of the exchanges.
+	//
+	// The rates are quoted in USD, so only the pairs quoted in USD are served.
+	// The denom of the rate of a pair is its base, unless a pool of the pair
+	// sets its BaseDenom. The rates have no traded volume, so the volumes are
+	// zero and candles are synthetic: one per request.
+	//
+	// REF: https://github.com/ojo-network/ojo/tree/main/proto/ojo/oracle/v1
+	InterchainOracleProvider struct {
+		name    Name
+		method  string
+		conn    *grpc.ClientConn
+		denoms  map[string]string // CurrencyPair.String() => denom
+		mtx     sync.Mutex
+		candles map[string][]types.CandlePrice // CurrencyPair.String() => candles
+	}
+
+	// interchainExchangeRatesRequest defines the x/oracle
+	// QueryExchangeRates request, for the rates of every denom.
+	interchainExchangeRatesRequest struct{}
+
+	// interchainExchangeRatesResponse defines the x/oracle
+	// QueryExchangeRatesResponse.
+	interchainExchangeRatesResponse struct {
+		ExchangeRates sdk.DecCoins
+	}
+)
+
+// NewInterchainOracleProvider returns a provider of the oracle of the chain
+// name, querying the node at the endpoint gRPC address.
+func NewInterchainOracleProvider(name Name, endpoint Endpoint) (*InterchainOracleProvider, error) {
+	method, ok := interchainOracleMethods[name]
+	if !ok {
+		return nil, fmt.Errorf("no x/oracle module known for %s", name)
+	}
+	if len(endpoint.GRPC) == 0 {
+		return nil, fmt.Errorf("%s requires the gRPC endpoint of a node", name)
+	}
+
+	conn, err := grpc.Dial(
+		endpoint.GRPC,
+		// the Cosmos SDK doesn't support any transport security mechanism
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(nodeCodec{})),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s gRPC service: %w", name, err)
+	}
+
+	return newInterchainOracleProvider(name, method, conn, endpoint.Pools), nil
+}
+
+func newInterchainOracleProvider(
+	name Name,
+	method string,
+	conn *grpc.ClientConn,
+	pools []Pool,
+) *InterchainOracleProvider {
+	p := &InterchainOracleProvider{
+		name:    name,
+		method:  method,
+		conn:    conn,
+		denoms:  make(map[string]string, len(pools)),
+		candles: make(map[string][]types.CandlePrice),
+	}
+	for _, pool := range pools {
+		cp := types.CurrencyPair{Base: pool.Base, Quote: pool.Quote}
+		p.denoms[cp.String()] = pool.BaseDenom
+	}
+
+	return p
+}
+
+// SubscribeCurrencyPairs performs a no-op since the rates are queried on
+// every request.
+func (*InterchainOracleProvider) SubscribeCurrencyPairs(context.Context, ...types.CurrencyPair) error {
+	return nil
+}
+
+// GetTickerPrices returns the exchange rates of the given pairs.
+func (p *InterchainOracleProvider) GetTickerPrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
+	rates, err := p.exchangeRates(ctx, pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	tickerPrices := make(map[string]types.TickerPrice, len(rates))
+	for symbol, rate := range rates {
+		tickerPrices[symbol] = types.TickerPrice{Price: rate, Volume: sdk.ZeroDec()}
+	}
+
+	return tickerPrices, nil
+}
+
+// GetCandlePrices appends a candle with the exchange rate to the candles of
+// the given pairs and returns the candles within providerCandlePeriod.
+func (p *InterchainOracleProvider) GetCandlePrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
+	rates, err := p.exchangeRates(ctx, pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	now := time.Now().UnixMilli()
+	staleTime := PastUnixTime(providerCandlePeriod)
+	candles := make(map[string][]types.CandlePrice, len(rates))
+	for symbol, rate := range rates {
+		p.candles[symbol] = append(p.candles[symbol], types.CandlePrice{
+			Price:     rate,
+			Volume:    sdk.ZeroDec(),
+			TimeStamp: now,
+		})
+
+		fresh := p.candles[symbol][:0]
+		for _, candle := range p.candles[symbol] {
+			if candle.TimeStamp > staleTime {
+				fresh = append(fresh, candle)
+			}
+		}
+		p.candles[symbol] = fresh
+
+		candles[symbol] = append([]types.CandlePrice{}, fresh...)
+	}
+
+	return candles, nil
+}
+
+// exchangeRates returns the exchange rates of the given pairs, from a single
+// query of the rates of every denom.
+func (p *InterchainOracleProvider) exchangeRates(
+	ctx context.Context,
+	pairs []types.CurrencyPair,
+) (map[string]sdk.Dec, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	var resp interchainExchangeRatesResponse
+	if err := p.conn.Invoke(ctx, p.method, &interchainExchangeRatesRequest{}, &resp); err != nil {
+		return nil, fmt.Errorf("failed to query %s exchange rates: %w", p.name, err)
+	}
+
+	byDenom := make(map[string]sdk.Dec, len(resp.ExchangeRates))
+	for _, rate := range resp.ExchangeRates {
+		byDenom[strings.ToUpper(rate.Denom)] = rate.Amount
+	}
+
+	rates := make(map[string]sdk.Dec, len(pairs))
+	for _, cp := range pairs {
+		if strings.ToUpper(cp.Quote) != interchainOracleQuote {
+			return nil, fmt.Errorf("%s only serves pairs quoted in %s, not %s", p.name, interchainOracleQuote, cp.String())
+		}
+
+		denom := p.denoms[cp.String()]
+		if len(denom) == 0 {
+			denom = cp.Base
+		}
+		rate, ok := byDenom[strings.ToUpper(denom)]
+		if !ok || rate.IsNil() || !rate.IsPositive() {
+			return nil, fmt.Errorf(types.ErrMissingExchangeRate, cp.String())
+		}
+
+		rates[cp.String()] = rate
+	}
+
+	return rates, nil
+}
+
+func (*interchainExchangeRatesRequest) Marshal() ([]byte, error) {
+	return nil, nil
+}
+
+func (*interchainExchangeRatesRequest) Unmarshal([]byte) error {
+	return nil
+}
+
+func (m *interchainExchangeRatesResponse) Marshal() ([]byte, error) {
+	var b []byte
+	for _, rate := range m.ExchangeRates {
+		amount, err := rate.Amount.Marshal()
+		if err != nil {
+			return nil, err
+		}
+
+		var c []byte
+		c = appendStringField(c, 1, rate.Denom)
+		c = appendStringField(c, 2, string(amount))
+
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, c)
+	}
+	return b, nil
+}
+
+func (m *interchainExchangeRatesResponse) Unmarshal(data []byte) error {
+	return unmarshalFields(data, func(num protowire.Number, _ uint64, bz []byte) error {
+		if num != 1 {
+			return nil
+		}
+
+		var rate sdk.DecCoin
+		err := unmarshalFields(bz, func(num protowire.Number, _ uint64, bz []byte) error {
+			switch num {
+			case 1:
+				rate.Denom = string(bz)
+			case 2:
+				return rate.Amount.Unmarshal(bz)
+			}
+			return nil
+		})
+		m.ExchangeRates = append(m.ExchangeRates, rate)
+		return err
+	})
+}
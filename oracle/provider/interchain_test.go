@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+// newTestOracleNode serves the Ojo exchange rates query.
+func newTestOracleNode(t *testing.T) *grpc.ClientConn {
+	handler := func(_ interface{}, stream grpc.ServerStream) error {
+		method, _ := grpc.MethodFromServerStream(stream)
+		require.Equal(t, interchainOracleMethods[Ojo], method)
+
+		var raw rawNodeMessage
+		if err := stream.RecvMsg(&raw); err != nil {
+			return err
+		}
+
+		return stream.SendMsg(&interchainExchangeRatesResponse{ExchangeRates: sdk.NewDecCoins(
+			sdk.NewDecCoinFromDec("ATOM", sdk.MustNewDecFromStr("10.41")),
+			sdk.NewDecCoinFromDec("STATOM", sdk.MustNewDecFromStr("11.5")),
+		)})
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer(grpc.ForceServerCodec(nodeCodec{}), grpc.UnknownServiceHandler(handler))
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial(
+		listener.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(nodeCodec{})),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func TestInterchainOracleProvider(t *testing.T) {
+	var (
+		atom    = types.CurrencyPair{Base: "ATOM", Quote: "USD"}
+		stkATOM = types.CurrencyPair{Base: "STKATOM", Quote: "USD"}
+	)
+	p := newInterchainOracleProvider(Ojo, interchainOracleMethods[Ojo], newTestOracleNode(t), []Pool{
+		{Base: "STKATOM", Quote: "USD", BaseDenom: "stATOM"},
+	})
+
+	t.Run("ticker", func(t *testing.T) {
+		prices, err := p.GetTickerPrices(context.Background(), atom, stkATOM)
+		require.NoError(t, err)
+		require.Equal(t, types.TickerPrice{Price: sdk.MustNewDecFromStr("10.41"), Volume: sdk.ZeroDec()}, prices["ATOMUSD"])
+		require.Equal(t, sdk.MustNewDecFromStr("11.5"), prices["STKATOMUSD"].Price)
+	})
+
+	t.Run("candles", func(t *testing.T) {
+		candles, err := p.GetCandlePrices(context.Background(), atom)
+		require.NoError(t, err)
+		require.Len(t, candles["ATOMUSD"], 1)
+		require.Equal(t, sdk.MustNewDecFromStr("10.41"), candles["ATOMUSD"][0].Price)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "OSMO", Quote: "USD"})
+		require.ErrorContains(t, err, "missing exchange rate for OSMOUSD")
+
+		_, err = p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "ATOM", Quote: "KRW"})
+		require.EqualError(t, err, "ojo only serves pairs quoted in USD, not ATOMKRW")
+
+		_, err = NewInterchainOracleProvider(Ojo, Endpoint{Name: Ojo})
+		require.EqualError(t, err, "ojo requires the gRPC endpoint of a node")
+	})
+}
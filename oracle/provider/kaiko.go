@@ -0,0 +1,201 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+const (
+	kaikoRestURL          = "https://us.market-api.kaiko.io"
+	kaikoRateEndpoint     = "/v2/data/trades.v1/spot_direct_exchange_rate/%s/%s/recent"
+	kaikoAPIKeyHeader     = "X-Api-Key"
+	kaikoInterval         = "1m"
+	kaikoResultSuccess    = "success"
+	kaikoIntervalDuration = time.Minute
+)
+
+// kaikoIntervalCount is the number of one minute intervals requested per
+// pair, covering the candle period of the providers.
+var kaikoIntervalCount = int(providerCandlePeriod / kaikoIntervalDuration)
+
+var _ Provider = (*KaikoProvider)(nil)
+
+type (
+	// KaikoProvider defines an Oracle provider implemented by the Kaiko market
+	// data API, which requires an API key. It serves the direct exchange rate
+	// of a pair, i.e. the VWAP of its trades across the exchanges covered by
+	// Kaiko, as an institutional reference rate. Every one minute interval of
+	// the rate is a candle, and tickers are the rate of the latest interval
+	// with the volume traded over the candle period.
+	//
+	// REF: https://docs.kaiko.com/#direct-exchange-rate
+	KaikoProvider struct {
+		baseURL string
+		apiKey  string
+		client  *http.Client
+	}
+
+	// KaikoRateResponse defines the response structure of a Kaiko exchange
+	// rate request, whose intervals are ordered from the most recent.
+	KaikoRateResponse struct {
+		Result string              `json:"result"`
+		Data   []KaikoRateInterval `json:"data"`
+	}
+
+	// KaikoRateInterval defines an interval of a Kaiko exchange rate. Price is
+	// null for the intervals without trades.
+	KaikoRateInterval struct {
+		Timestamp int64   `json:"timestamp"` // Start of the interval in unix milliseconds
+		Price     *string `json:"price"`     // VWAP of the interval ex.: "10.4512"
+		Volume    string  `json:"volume"`    // Volume of the base during the interval
+	}
+)
+
+// NewKaikoProvider returns a Kaiko provider authenticated with the API key of
+// the endpoint, using its REST URL if set.
+func NewKaikoProvider(endpoint Endpoint) (*KaikoProvider, error) {
+	if len(endpoint.APIKey) == 0 {
+		return nil, fmt.Errorf("kaiko requires an api key in the provider credentials")
+	}
+
+	p := &KaikoProvider{
+		baseURL: kaikoRestURL,
+		apiKey:  endpoint.APIKey,
+		client:  newDefaultHTTPClient(),
+	}
+	if endpoint.Name == Kaiko && len(endpoint.Rest) > 0 {
+		p.baseURL = endpoint.Rest
+	}
+
+	return p, nil
+}
+
+// SubscribeCurrencyPairs performs a no-op since Kaiko is requested on demand.
+func (*KaikoProvider) SubscribeCurrencyPairs(context.Context, ...types.CurrencyPair) error {
+	return nil
+}
+
+// GetTickerPrices returns the exchange rates of the latest intervals of the
+// given pairs with a trade, along with the volumes of the candle period.
+func (p *KaikoProvider) GetTickerPrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
+	tickerPrices := make(map[string]types.TickerPrice, len(pairs))
+	for _, cp := range pairs {
+		candles, err := p.getCandles(ctx, cp)
+		if err != nil {
+			return nil, err
+		}
+		if len(candles) == 0 {
+			return nil, fmt.Errorf(types.ErrMissingExchangeRate, cp.String())
+		}
+
+		volume := sdk.ZeroDec()
+		for _, candle := range candles {
+			volume = volume.Add(candle.Volume)
+		}
+		tickerPrices[cp.String()] = types.TickerPrice{Price: candles[len(candles)-1].Price, Volume: volume}
+	}
+
+	return tickerPrices, nil
+}
+
+// GetCandlePrices returns the intervals of the exchange rates of the given
+// pairs within providerCandlePeriod as candles.
+func (p *KaikoProvider) GetCandlePrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
+	candles := make(map[string][]types.CandlePrice, len(pairs))
+	for _, cp := range pairs {
+		pairCandles, err := p.getCandles(ctx, cp)
+		if err != nil {
+			return nil, err
+		}
+		candles[cp.String()] = pairCandles
+	}
+
+	return candles, nil
+}
+
+// getCandles returns the intervals with trades of the exchange rate of the
+// pair within providerCandlePeriod, ordered from the oldest.
+func (p *KaikoProvider) getCandles(ctx context.Context, cp types.CurrencyPair) ([]types.CandlePrice, error) {
+	endpoint := fmt.Sprintf(kaikoRateEndpoint, strings.ToLower(cp.Base), strings.ToLower(cp.Quote))
+	query := url.Values{}
+	query.Set("interval", kaikoInterval)
+	query.Set("limit", fmt.Sprint(kaikoIntervalCount))
+
+	var resp KaikoRateResponse
+	if err := p.get(ctx, endpoint, query, &resp); err != nil {
+		return nil, fmt.Errorf("kaiko failed to get %s exchange rate: %w", cp.String(), err)
+	}
+	if resp.Result != kaikoResultSuccess {
+		return nil, fmt.Errorf("kaiko failed to get %s exchange rate: result %q", cp.String(), resp.Result)
+	}
+
+	staleTime := PastUnixTime(providerCandlePeriod)
+	candles := make([]types.CandlePrice, 0, len(resp.Data))
+	for i := len(resp.Data) - 1; i >= 0; i-- {
+		interval := resp.Data[i]
+		if interval.Price == nil || interval.Timestamp <= staleTime {
+			continue
+		}
+
+		price, err := sdk.NewDecFromStr(*interval.Price)
+		if err != nil {
+			return nil, fmt.Errorf("kaiko returned an invalid %s price: %w", cp.String(), err)
+		}
+		volume, err := sdk.NewDecFromStr(interval.Volume)
+		if err != nil {
+			return nil, fmt.Errorf("kaiko returned an invalid %s volume: %w", cp.String(), err)
+		}
+		candles = append(candles, types.CandlePrice{
+			Price:     price,
+			Volume:    volume,
+			TimeStamp: interval.Timestamp + kaikoIntervalDuration.Milliseconds(),
+		})
+	}
+
+	return candles, nil
+}
+
+// get requests the endpoint with the query, bounded by ctx, and decodes the
+// JSON response into resp.
+func (p *KaikoProvider) get(ctx context.Context, endpoint string, query url.Values, resp interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(kaikoAPIKeyHeader, p.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	httpResp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+
+	if err := checkHTTPStatus(httpResp); err != nil {
+		return err
+	}
+
+	bz, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read kaiko response body: %w", err)
+	}
+	capturePayload(Kaiko, bz)
+
+	return json.Unmarshal(bz, resp)
+}
@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+func TestKaikoProvider(t *testing.T) {
+	var (
+		minute = time.Now().Truncate(time.Minute).UnixMilli()
+		stale  = time.Now().Add(-2 * providerCandlePeriod).UnixMilli()
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		require.Equal(t, "secret", req.Header.Get(kaikoAPIKeyHeader))
+		require.Equal(t, kaikoInterval, req.URL.Query().Get("interval"))
+
+		switch req.URL.Path {
+		case fmt.Sprintf(kaikoRateEndpoint, "atom", "usd"):
+			fmt.Fprintf(rw, `{"result":"success","data":[`+
+				`{"timestamp":%d,"price":"10.2","volume":"150"},`+
+				`{"timestamp":%d,"price":null,"volume":"0"},`+
+				`{"timestamp":%d,"price":"10.0","volume":"50"},`+
+				`{"timestamp":%d,"price":"9.0","volume":"1000"}]}`,
+				minute, minute-60_000, minute-120_000, stale)
+
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	_, err := NewKaikoProvider(Endpoint{Name: Kaiko, Rest: server.URL})
+	require.EqualError(t, err, "kaiko requires an api key in the provider credentials")
+
+	p, err := NewKaikoProvider(Endpoint{Name: Kaiko, Rest: server.URL, APIKey: "secret"})
+	require.NoError(t, err)
+	p.client = server.Client()
+	pair := types.CurrencyPair{Base: "ATOM", Quote: "USD"}
+
+	t.Run("ticker", func(t *testing.T) {
+		prices, err := p.GetTickerPrices(context.Background(), pair)
+		require.NoError(t, err)
+		require.Equal(t, types.TickerPrice{Price: sdk.MustNewDecFromStr("10.2"), Volume: sdk.NewDec(200)}, prices["ATOMUSD"])
+	})
+
+	t.Run("candles", func(t *testing.T) {
+		candles, err := p.GetCandlePrices(context.Background(), pair)
+		require.NoError(t, err)
+		require.Equal(t, []types.CandlePrice{
+			{Price: sdk.NewDec(10), Volume: sdk.NewDec(50), TimeStamp: minute - 60_000},
+			{Price: sdk.MustNewDecFromStr("10.2"), Volume: sdk.NewDec(150), TimeStamp: minute + 60_000},
+		}, candles["ATOMUSD"])
+	})
+
+	t.Run("unknown_pair", func(t *testing.T) {
+		_, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "FOO", Quote: "USD"})
+		require.ErrorContains(t, err, "kaiko failed to get FOOUSD exchange rate: unexpected status")
+	})
+}
@@ -19,11 +19,18 @@ import (
 const (
 	krakenWSHost                  = "ws.kraken.com"
 	KrakenRestHost                = "https://api.kraken.com"
+	krakenSystemStatusPath        = "/0/public/SystemStatus"
+	krakenStatusMaintenance       = "maintenance"
+	krakenStatusCancelOnly        = "cancel_only"
 	krakenEventSystemStatus       = "systemStatus"
 	krakenEventSubscriptionStatus = "subscriptionStatus"
+	krakenMaxSilence              = time.Minute
 )
 
-var _ Provider = (*KrakenProvider)(nil)
+var (
+	_ Provider      = (*KrakenProvider)(nil)
+	_ StatusChecker = (*KrakenProvider)(nil)
+)
 
 type (
 	// KrakenProvider defines an Oracle provider implemented by the Kraken public
@@ -80,6 +87,19 @@ type (
 		ErrorMessage string `json:"errorMessage"` // error description
 	}
 
+	// KrakenSystemStatus defines the response structure of the Kraken system
+	// status. The status is one of online, maintenance, cancel_only or
+	// post_only.
+	//
+	// REF: https://docs.kraken.com/rest/#operation/getSystemStatus
+	KrakenSystemStatus struct {
+		Error  []string `json:"error"`
+		Result struct {
+			Status    string `json:"status"`
+			Timestamp string `json:"timestamp"`
+		} `json:"result"`
+	}
+
 	// KrakenPairsSummary defines the response structure for an Kraken pairs summary.
 	KrakenPairsSummary struct {
 		Result map[string]KrakenPairData `json:"result"`
@@ -133,11 +153,24 @@ func NewKrakenProvider(
 		websocket.PingMessage,
 		krakenLogger,
 	)
-	go provider.wsc.Start()
+	// Kraken sends a heartbeat every second without any other update, so a
+	// minute without any message means the subscriptions were lost
+	provider.wsc.SetMaxSilence(krakenMaxSilence)
 
 	return provider, nil
 }
 
+// Start connects to the websocket in the background, see Lifecycle.
+func (p *KrakenProvider) Start() error {
+	go p.wsc.Start()
+	return nil
+}
+
+// Stop closes the websocket, see Lifecycle.
+func (p *KrakenProvider) Stop() {
+	p.wsc.Stop()
+}
+
 func (p *KrakenProvider) getSubscriptionMsgs(cps ...types.CurrencyPair) []interface{} {
 	subscriptionMsgs := make([]interface{}, 0, len(cps)*2) //nolint: gomnd //const
 	for _, cp := range cps {
@@ -150,7 +183,7 @@ func (p *KrakenProvider) getSubscriptionMsgs(cps ...types.CurrencyPair) []interf
 
 // SubscribeCurrencyPairs sends the new subscription messages to the websocket
 // and adds them to the providers subscribedPairs array.
-func (p *KrakenProvider) SubscribeCurrencyPairs(cps ...types.CurrencyPair) error {
+func (p *KrakenProvider) SubscribeCurrencyPairs(_ context.Context, cps ...types.CurrencyPair) error {
 	p.mtx.Lock()
 	defer p.mtx.Unlock()
 
@@ -170,7 +203,10 @@ func (p *KrakenProvider) SubscribeCurrencyPairs(cps ...types.CurrencyPair) error
 }
 
 // GetTickerPrices returns the tickerPrices based on the saved map.
-func (p *KrakenProvider) GetTickerPrices(pairs ...types.CurrencyPair) (map[string]types.TickerPrice, error) {
+func (p *KrakenProvider) GetTickerPrices(
+	_ context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
 	p.mtx.RLock()
 	defer p.mtx.RUnlock()
 
@@ -189,7 +225,10 @@ func (p *KrakenProvider) GetTickerPrices(pairs ...types.CurrencyPair) (map[strin
 }
 
 // GetCandlePrices returns the candlePrices based on the saved map.
-func (p *KrakenProvider) GetCandlePrices(pairs ...types.CurrencyPair) (map[string][]types.CandlePrice, error) {
+func (p *KrakenProvider) GetCandlePrices(
+	_ context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
 	candlePrices := make(map[string][]types.CandlePrice, len(pairs))
 
 	for _, cp := range pairs {
@@ -520,3 +559,22 @@ func currencyPairToKrakenPair(cp types.CurrencyPair) string {
 func normalizeKrakenBTCPair(ticker string) string {
 	return strings.Replace(ticker, "XBT", "BTC", 1)
 }
+
+// UnderMaintenance implements StatusChecker. No trades are executed while the
+// exchange is in maintenance or cancel_only mode, so prices go stale.
+func (p *KrakenProvider) UnderMaintenance(ctx context.Context) (bool, error) {
+	var status KrakenSystemStatus
+	if err := getJSON(ctx, p.endpoints.Rest+krakenSystemStatusPath, nil, &status); err != nil {
+		return false, err
+	}
+	if len(status.Error) != 0 {
+		return false, fmt.Errorf("kraken system status: %s", strings.Join(status.Error, ", "))
+	}
+
+	switch status.Result.Status {
+	case krakenStatusMaintenance, krakenStatusCancelOnly:
+		return true, nil
+	default:
+		return false, nil
+	}
+}
@@ -3,6 +3,8 @@ package provider
 import (
 	"context"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -34,7 +36,7 @@ func TestKrakenProvider_GetTickerPrices(t *testing.T) {
 
 		p.tickers = tickerMap
 
-		prices, err := p.GetTickerPrices(types.CurrencyPair{Base: "ATOM", Quote: "USD"})
+		prices, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "ATOM", Quote: "USD"})
 		require.NoError(t, err)
 		require.Len(t, prices, 1)
 		require.Equal(t, lastPrice, prices["ATOMUSD"].Price)
@@ -60,6 +62,7 @@ func TestKrakenProvider_GetTickerPrices(t *testing.T) {
 
 		p.tickers = tickerMap
 		prices, err := p.GetTickerPrices(
+			context.Background(),
 			types.CurrencyPair{Base: "ATOM", Quote: "USD"},
 			types.CurrencyPair{Base: "OSMO", Quote: "USD"},
 		)
@@ -72,7 +75,7 @@ func TestKrakenProvider_GetTickerPrices(t *testing.T) {
 	})
 
 	t.Run("invalid_request_invalid_ticker", func(t *testing.T) {
-		prices, err := p.GetTickerPrices(types.CurrencyPair{Base: "FOO", Quote: "BAR"})
+		prices, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "FOO", Quote: "BAR"})
 		require.EqualError(t, err, "kraken failed to get ticker price for FOOBAR")
 		require.Nil(t, prices)
 	})
@@ -114,3 +117,36 @@ func TestKrakenProvider_getSubscriptionMsgs(t *testing.T) {
 	msg, _ = json.Marshal(subMsgs[1])
 	require.Equal(t, "{\"event\":\"subscribe\",\"pair\":[\"ATOM/USD\"],\"subscription\":{\"name\":\"ohlc\"}}", string(msg))
 }
+
+func TestKrakenProvider_UnderMaintenance(t *testing.T) {
+	testCases := map[string]struct {
+		response    string
+		maintenance bool
+		err         bool
+	}{
+		"online":      {response: `{"error":[],"result":{"status":"online"}}`},
+		"maintenance": {response: `{"error":[],"result":{"status":"maintenance"}}`, maintenance: true},
+		"cancel only": {response: `{"error":[],"result":{"status":"cancel_only"}}`, maintenance: true},
+		"error":       {response: `{"error":["EService:Unavailable"]}`, err: true},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				require.Equal(t, krakenSystemStatusPath, req.URL.Path)
+				_, _ = rw.Write([]byte(tc.response))
+			}))
+			defer server.Close()
+
+			p := &KrakenProvider{endpoints: Endpoint{Name: Kraken, Rest: server.URL}}
+			maintenance, err := p.UnderMaintenance(context.Background())
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.maintenance, maintenance)
+		})
+	}
+}
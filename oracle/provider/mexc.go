@@ -0,0 +1,441 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+const (
+	mexcWSHost          = "wbs.mexc.com"
+	mexcWSPath          = "/ws"
+	mexcRestHost        = "https://api.mexc.com"
+	mexcRestPath        = "/api/v3/exchangeInfo"
+	mexcDealsChannel    = "spot@public.deals.v3.api@"
+	mexcCandleChannel   = "spot@public.kline.v3.api@"
+	mexcCandleInterval  = "@Min1"
+	mexcMethodSubscribe = "SUBSCRIPTION"
+	mexcMaxSubscription = 30 // maximum number of channels per connection
+)
+
+var _ Provider = (*MexcProvider)(nil)
+
+type (
+	// MexcProvider defines an Oracle provider implemented by the MEXC public
+	// API. MEXC doesn't push 24h tickers, so the ticker of a pair is the price
+	// of its last deal and the volume of its deals over the last 24 hours
+	// since subscribed.
+	//
+	// REF: https://mexcdevelop.github.io/apidocs/spot_v3_en/#websocket-market-streams
+	MexcProvider struct {
+		wsc             *WebsocketController
+		logger          zerolog.Logger
+		mtx             sync.RWMutex
+		endpoints       Endpoint
+		deals           map[string]MexcDeal           // Symbol => last MexcDeal
+		volumes         map[string]*tradeVolume       // Symbol => volume of the deals
+		candles         map[string][]MexcCandle       // Symbol => MexcCandle
+		subscribedPairs map[string]types.CurrencyPair // Symbol => types.CurrencyPair
+	}
+
+	// MexcDeal defines a deal pushed on the deals channel.
+	MexcDeal struct {
+		Price     string `json:"p"` // Price ex.: 11.05
+		Volume    string `json:"v"` // Volume ex.: 12.5
+		TimeStamp int64  `json:"t"` // Time in unix epoch ex.: 1678420554935
+	}
+
+	// MexcDealsResponse defines a message of the deals channel.
+	MexcDealsResponse struct {
+		Channel string `json:"c"` // ex.: spot@public.deals.v3.api@ATOMUSDT
+		Symbol  string `json:"s"` // ex.: ATOMUSDT
+		Data    struct {
+			Deals []MexcDeal `json:"deals"`
+		} `json:"d"`
+	}
+
+	// MexcCandle defines a candle of the kline channel.
+	MexcCandle struct {
+		Symbol string `json:"-"` // Symbol ex.: ATOMUSDT, from the message
+		Start  int64  `json:"t"` // Start time in unix seconds ex.: 1678775400
+		End    int64  `json:"T"` // End time in unix seconds ex.: 1678775460
+		Close  string `json:"c"` // Price at close, or last price while open
+		Volume string `json:"v"` // Volume during period
+	}
+
+	// MexcCandleResponse defines a message of the kline channel.
+	MexcCandleResponse struct {
+		Channel string `json:"c"` // ex.: spot@public.kline.v3.api@ATOMUSDT@Min1
+		Symbol  string `json:"s"` // ex.: ATOMUSDT
+		Data    struct {
+			Candle MexcCandle `json:"k"`
+		} `json:"d"`
+	}
+
+	// MexcSubscriptionMsg Msg to subscribe to channels.
+	MexcSubscriptionMsg struct {
+		Method string   `json:"method"` // SUBSCRIPTION, UNSUBSCRIPTION
+		Params []string `json:"params"` // channels ex.: spot@public.deals.v3.api@ATOMUSDT
+	}
+
+	// MexcSubscriptionResp defines the response to a subscription, whose
+	// message is the channel subscribed to or the error.
+	MexcSubscriptionResp struct {
+		ID   int64  `json:"id"`
+		Code int64  `json:"code"`
+		Msg  string `json:"msg"`
+	}
+
+	// MexcPairsSummary defines the response structure of the exchange
+	// information.
+	MexcPairsSummary struct {
+		Symbols []struct {
+			BaseAsset  string `json:"baseAsset"`
+			QuoteAsset string `json:"quoteAsset"`
+		} `json:"symbols"`
+	}
+)
+
+func NewMexcProvider(
+	ctx context.Context,
+	logger zerolog.Logger,
+	endpoints Endpoint,
+	pairs ...types.CurrencyPair,
+) (*MexcProvider, error) {
+	if endpoints.Name != Mexc {
+		endpoints = Endpoint{
+			Name:      Mexc,
+			Rest:      mexcRestHost,
+			Websocket: mexcWSHost,
+		}
+	}
+
+	wsURL := url.URL{
+		Scheme: "wss",
+		Host:   endpoints.Websocket,
+		Path:   mexcWSPath,
+	}
+
+	mexcLogger := logger.With().Str("provider", "mexc").Logger()
+
+	provider := &MexcProvider{
+		logger:          mexcLogger,
+		endpoints:       endpoints,
+		deals:           map[string]MexcDeal{},
+		volumes:         map[string]*tradeVolume{},
+		candles:         map[string][]MexcCandle{},
+		subscribedPairs: map[string]types.CurrencyPair{},
+	}
+
+	provider.setSubscribedPairs(pairs...)
+	provider.checkSubscriptionLimit()
+
+	provider.wsc = NewWebsocketController(
+		ctx,
+		Mexc,
+		wsURL,
+		provider.getSubscriptionMsgs(pairs...),
+		provider.messageReceived,
+		defaultPingDuration,
+		websocket.PingMessage,
+		mexcLogger,
+	)
+
+	return provider, nil
+}
+
+// Start connects to the websocket in the background, see Lifecycle.
+func (p *MexcProvider) Start() error {
+	go p.wsc.Start()
+	return nil
+}
+
+// Stop closes the websocket, see Lifecycle.
+func (p *MexcProvider) Stop() {
+	p.wsc.Stop()
+}
+
+// getSubscriptionMsgs returns the message subscribing to the deals and kline
+// channels of the pairs.
+func (p *MexcProvider) getSubscriptionMsgs(cps ...types.CurrencyPair) []interface{} {
+	if len(cps) == 0 {
+		return []interface{}{}
+	}
+
+	channels := make([]string, 0, len(cps)*2) //nolint: gomnd //const
+	for _, cp := range cps {
+		channels = append(channels, currencyPairToMexcDeals(cp), currencyPairToMexcCandle(cp))
+	}
+
+	return []interface{}{newMexcSubscriptionMsg(channels...)}
+}
+
+// checkSubscriptionLimit logs an error if more channels are subscribed to than
+// MEXC accepts on a connection, in which case the prices of the pairs past the
+// limit are missing.
+func (p *MexcProvider) checkSubscriptionLimit() {
+	if channels := len(p.subscribedPairs) * 2; channels > mexcMaxSubscription { //nolint: gomnd //const
+		p.logger.Error().
+			Int("channels", channels).
+			Int("max_channels", mexcMaxSubscription).
+			Msg("too many channels subscribed")
+	}
+}
+
+// SubscribeCurrencyPairs sends the new subscription messages to the websocket
+// and adds them to the providers subscribedPairs array.
+func (p *MexcProvider) SubscribeCurrencyPairs(_ context.Context, cps ...types.CurrencyPair) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	newPairs := []types.CurrencyPair{}
+	for _, cp := range cps {
+		if _, ok := p.subscribedPairs[cp.String()]; !ok {
+			newPairs = append(newPairs, cp)
+		}
+	}
+
+	newSubscriptionMsgs := p.getSubscriptionMsgs(newPairs...)
+	if err := p.wsc.AddSubscriptionMsgs(newSubscriptionMsgs); err != nil {
+		return err
+	}
+	p.setSubscribedPairs(newPairs...)
+	p.checkSubscriptionLimit()
+	return nil
+}
+
+// GetTickerPrices returns the tickerPrices based on the provided pairs.
+func (p *MexcProvider) GetTickerPrices(
+	_ context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
+	tickerPrices := make(map[string]types.TickerPrice, len(pairs))
+
+	for _, cp := range pairs {
+		key := cp.String()
+		price, err := p.getTickerPrice(key)
+		if err != nil {
+			return nil, err
+		}
+		tickerPrices[key] = price
+	}
+
+	return tickerPrices, nil
+}
+
+// GetCandlePrices returns the candlePrices based on the provided pairs.
+func (p *MexcProvider) GetCandlePrices(
+	_ context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
+	candlePrices := make(map[string][]types.CandlePrice, len(pairs))
+
+	for _, cp := range pairs {
+		key := cp.String()
+		prices, err := p.getCandlePrices(key)
+		if err != nil {
+			return nil, err
+		}
+		candlePrices[key] = prices
+	}
+
+	return candlePrices, nil
+}
+
+func (p *MexcProvider) getTickerPrice(key string) (types.TickerPrice, error) {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	deal, ok := p.deals[key]
+	if !ok {
+		return types.TickerPrice{}, fmt.Errorf("mexc failed to get ticker price for %s", key)
+	}
+
+	price, err := sdk.NewDecFromStr(deal.Price)
+	if err != nil {
+		return types.TickerPrice{}, fmt.Errorf("failed to parse mexc price (%s) for %s: %w", deal.Price, key, err)
+	}
+
+	return types.TickerPrice{
+		Price:  price,
+		Volume: p.volumes[key].sum(time.Now()),
+	}, nil
+}
+
+func (p *MexcProvider) getCandlePrices(key string) ([]types.CandlePrice, error) {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	candles, ok := p.candles[key]
+	if !ok {
+		return []types.CandlePrice{}, fmt.Errorf("mexc failed to get candle prices for %s", key)
+	}
+
+	candleList := []types.CandlePrice{}
+	for _, candle := range candles {
+		cp, err := candle.toCandlePrice()
+		if err != nil {
+			return []types.CandlePrice{}, err
+		}
+		candleList = append(candleList, cp)
+	}
+	return candleList, nil
+}
+
+func (p *MexcProvider) messageReceived(_ int, bz []byte) {
+	var (
+		dealsResp     MexcDealsResponse
+		dealsErr      error
+		candleResp    MexcCandleResponse
+		candleErr     error
+		subscribeResp MexcSubscriptionResp
+		subscribeErr  error
+	)
+
+	dealsErr = json.Unmarshal(bz, &dealsResp)
+	if strings.HasPrefix(dealsResp.Channel, mexcDealsChannel) {
+		p.setDeals(dealsResp.Symbol, dealsResp.Data.Deals)
+		p.logger.Trace().
+			Str(Mexc.String(), messageTypeTrade).
+			Msg("Websocket message received")
+		return
+	}
+
+	candleErr = json.Unmarshal(bz, &candleResp)
+	if strings.HasPrefix(candleResp.Channel, mexcCandleChannel) {
+		candleResp.Data.Candle.Symbol = candleResp.Symbol
+		p.setCandlePair(candleResp.Data.Candle)
+		p.logger.Trace().
+			Str(Mexc.String(), messageTypeCandle).
+			Msg("Websocket message received")
+		return
+	}
+
+	subscribeErr = json.Unmarshal(bz, &subscribeResp)
+	if len(subscribeResp.Msg) != 0 {
+		if subscribeResp.Code != 0 {
+			p.logger.Error().
+				Int64("code", subscribeResp.Code).
+				Str("msg", subscribeResp.Msg).
+				Msg("mexc: subscription failed")
+		}
+		return
+	}
+
+	p.logger.Error().
+		Int("length", len(bz)).
+		AnErr("deals", dealsErr).
+		AnErr("candle", candleErr).
+		AnErr("subscribe", subscribeErr).
+		Msg("Error on receive message")
+}
+
+// setDeals stores the last of the deals and adds their volume to the volume
+// of the pair.
+func (p *MexcProvider) setDeals(symbol string, deals []MexcDeal) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	volume, ok := p.volumes[symbol]
+	if !ok {
+		volume = &tradeVolume{}
+		p.volumes[symbol] = volume
+	}
+
+	for _, deal := range deals {
+		dealVolume, err := sdk.NewDecFromStr(deal.Volume)
+		if err != nil {
+			p.logger.Error().Err(err).Str("symbol", symbol).Msg("failed to parse deal volume")
+			continue
+		}
+		volume.add(deal.TimeStamp, dealVolume)
+
+		if last, ok := p.deals[symbol]; !ok || deal.TimeStamp >= last.TimeStamp {
+			p.deals[symbol] = deal
+		}
+	}
+}
+
+// setCandlePair stores the candle, replacing the previous update of the same
+// candle since MEXC pushes the candle in progress.
+func (p *MexcProvider) setCandlePair(candle MexcCandle) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	staleTime := PastUnixTime(providerCandlePeriod)
+	var candleList []MexcCandle
+	candleList = append(candleList, candle)
+
+	for _, c := range p.candles[candle.Symbol] {
+		if staleTime < secondsToMilli(c.End) && c.Start != candle.Start {
+			candleList = append(candleList, c)
+		}
+	}
+	p.candles[candle.Symbol] = candleList
+}
+
+func (candle MexcCandle) toCandlePrice() (types.CandlePrice, error) {
+	return types.NewCandlePrice(string(Mexc), candle.Symbol, candle.Close, candle.Volume, secondsToMilli(candle.End))
+}
+
+// setSubscribedPairs sets N currency pairs to the map of subscribed pairs.
+func (p *MexcProvider) setSubscribedPairs(cps ...types.CurrencyPair) {
+	for _, cp := range cps {
+		p.subscribedPairs[cp.String()] = cp
+	}
+}
+
+// GetAvailablePairs returns all pairs to which the provider can subscribe.
+// ex.: map["ATOMUSDT" => {}, "XPRTUSDT" => {}].
+func (p *MexcProvider) GetAvailablePairs() (map[string]struct{}, error) {
+	resp, err := newDefaultHTTPClient().Get(p.endpoints.Rest + mexcRestPath)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var pairsSummary MexcPairsSummary
+	if err := json.NewDecoder(resp.Body).Decode(&pairsSummary); err != nil {
+		return nil, err
+	}
+
+	availablePairs := make(map[string]struct{}, len(pairsSummary.Symbols))
+	for _, pair := range pairsSummary.Symbols {
+		cp := types.CurrencyPair{
+			Base:  strings.ToUpper(pair.BaseAsset),
+			Quote: strings.ToUpper(pair.QuoteAsset),
+		}
+		availablePairs[cp.String()] = struct{}{}
+	}
+
+	return availablePairs, nil
+}
+
+// currencyPairToMexcDeals returns the deals channel of a pair.
+// ex.: ATOM/USDT => spot@public.deals.v3.api@ATOMUSDT.
+func currencyPairToMexcDeals(cp types.CurrencyPair) string {
+	return mexcDealsChannel + cp.String()
+}
+
+// currencyPairToMexcCandle returns the one minute kline channel of a pair.
+// ex.: ATOM/USDT => spot@public.kline.v3.api@ATOMUSDT@Min1.
+func currencyPairToMexcCandle(cp types.CurrencyPair) string {
+	return mexcCandleChannel + cp.String() + mexcCandleInterval
+}
+
+// newMexcSubscriptionMsg returns a new subscription Msg.
+func newMexcSubscriptionMsg(channels ...string) MexcSubscriptionMsg {
+	return MexcSubscriptionMsg{
+		Method: mexcMethodSubscribe,
+		Params: channels,
+	}
+}
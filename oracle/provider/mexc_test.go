@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+func TestMexcProvider_GetTickerPrices(t *testing.T) {
+	p, err := NewMexcProvider(
+		context.TODO(),
+		zerolog.Nop(),
+		Endpoint{},
+		types.CurrencyPair{Base: "ATOM", Quote: "USDT"},
+	)
+	require.NoError(t, err)
+
+	now := time.Now().UnixMilli()
+
+	t.Run("valid_request_single_ticker", func(t *testing.T) {
+		p.setDeals("ATOMUSDT", []MexcDeal{
+			{Price: "34.5", Volume: "1000", TimeStamp: now - 1000},
+			{Price: lastPriceAtom, Volume: "1396974.02", TimeStamp: now},
+		})
+
+		prices, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "ATOM", Quote: "USDT"})
+		require.NoError(t, err)
+		require.Len(t, prices, 1)
+		require.Equal(t, sdk.MustNewDecFromStr(lastPriceAtom), prices["ATOMUSDT"].Price)
+		require.Equal(t, sdk.MustNewDecFromStr("1397974.02"), prices["ATOMUSDT"].Volume)
+	})
+
+	t.Run("valid_request_multi_ticker", func(t *testing.T) {
+		p.setDeals("OSMOUSDT", []MexcDeal{{Price: lastPriceOsmo, Volume: volume, TimeStamp: now}})
+
+		prices, err := p.GetTickerPrices(
+			context.Background(),
+			types.CurrencyPair{Base: "ATOM", Quote: "USDT"},
+			types.CurrencyPair{Base: "OSMO", Quote: "USDT"},
+		)
+		require.NoError(t, err)
+		require.Len(t, prices, 2)
+		require.Equal(t, sdk.MustNewDecFromStr(lastPriceAtom), prices["ATOMUSDT"].Price)
+		require.Equal(t, sdk.MustNewDecFromStr(lastPriceOsmo), prices["OSMOUSDT"].Price)
+		require.Equal(t, sdk.MustNewDecFromStr(volume), prices["OSMOUSDT"].Volume)
+	})
+
+	t.Run("late_deal_keeps_last_price", func(t *testing.T) {
+		p.setDeals("ATOMUSDT", []MexcDeal{{Price: "30", Volume: "1", TimeStamp: now - 2000}})
+
+		prices, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "ATOM", Quote: "USDT"})
+		require.NoError(t, err)
+		require.Equal(t, sdk.MustNewDecFromStr(lastPriceAtom), prices["ATOMUSDT"].Price)
+		require.Equal(t, sdk.MustNewDecFromStr("1397975.02"), prices["ATOMUSDT"].Volume)
+	})
+
+	t.Run("invalid_request_invalid_ticker", func(t *testing.T) {
+		prices, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "FOO", Quote: "BAR"})
+		require.EqualError(t, err, "mexc failed to get ticker price for FOOBAR")
+		require.Nil(t, prices)
+	})
+}
+
+func TestMexcProvider_GetCandlePrices(t *testing.T) {
+	p, err := NewMexcProvider(
+		context.TODO(),
+		zerolog.Nop(),
+		Endpoint{},
+		types.CurrencyPair{Base: "ATOM", Quote: "USDT"},
+	)
+	require.NoError(t, err)
+
+	start := time.Now().Unix()
+
+	t.Run("updated_candle_replaced", func(t *testing.T) {
+		candle := MexcCandle{Symbol: "ATOMUSDT", Start: start, End: start + 60, Close: "34.5", Volume: "100"}
+		p.setCandlePair(candle)
+		candle.Close, candle.Volume = lastPriceAtom, "120"
+		p.setCandlePair(candle)
+
+		prices, err := p.GetCandlePrices(context.Background(), types.CurrencyPair{Base: "ATOM", Quote: "USDT"})
+		require.NoError(t, err)
+		require.Len(t, prices["ATOMUSDT"], 1)
+		require.Equal(t, sdk.MustNewDecFromStr(lastPriceAtom), prices["ATOMUSDT"][0].Price)
+		require.Equal(t, sdk.MustNewDecFromStr("120"), prices["ATOMUSDT"][0].Volume)
+		require.Equal(t, (start+60)*1000, prices["ATOMUSDT"][0].TimeStamp)
+	})
+
+	t.Run("stale_candle_pruned", func(t *testing.T) {
+		p.setCandlePair(MexcCandle{Symbol: "OSMOUSDT", Start: start - 3600, End: start - 3540, Close: "1", Volume: "1"})
+		p.setCandlePair(MexcCandle{Symbol: "OSMOUSDT", Start: start, End: start + 60, Close: lastPriceOsmo, Volume: "1"})
+
+		prices, err := p.GetCandlePrices(context.Background(), types.CurrencyPair{Base: "OSMO", Quote: "USDT"})
+		require.NoError(t, err)
+		require.Len(t, prices["OSMOUSDT"], 1)
+		require.Equal(t, sdk.MustNewDecFromStr(lastPriceOsmo), prices["OSMOUSDT"][0].Price)
+	})
+
+	t.Run("invalid_request_invalid_candle", func(t *testing.T) {
+		prices, err := p.GetCandlePrices(context.Background(), types.CurrencyPair{Base: "FOO", Quote: "BAR"})
+		require.EqualError(t, err, "mexc failed to get candle prices for FOOBAR")
+		require.Nil(t, prices)
+	})
+}
+
+func TestMexcProvider_MessageReceived(t *testing.T) {
+	p, err := NewMexcProvider(
+		context.TODO(),
+		zerolog.Nop(),
+		Endpoint{},
+		types.CurrencyPair{Base: "ATOM", Quote: "USDT"},
+	)
+	require.NoError(t, err)
+	cp := types.CurrencyPair{Base: "ATOM", Quote: "USDT"}
+
+	p.messageReceived(websocket.TextMessage, []byte(`{"id":0,"code":0,"msg":"spot@public.deals.v3.api@ATOMUSDT"}`))
+	_, err = p.GetTickerPrices(context.Background(), cp)
+	require.Error(t, err)
+
+	p.messageReceived(websocket.TextMessage, []byte(`{"c":"spot@public.deals.v3.api@ATOMUSDT","d":{"deals":[`+
+		`{"S":1,"p":"11.05","t":1678420554935,"v":"12.5"}],"e":"spot@public.deals.v3.api"},"s":"ATOMUSDT"}`))
+	prices, err := p.GetTickerPrices(context.Background(), cp)
+	require.NoError(t, err)
+	require.Equal(t, sdk.MustNewDecFromStr("11.05"), prices["ATOMUSDT"].Price)
+
+	p.messageReceived(websocket.TextMessage, []byte(`{"c":"spot@public.kline.v3.api@ATOMUSDT@Min1","d":{"k":{`+
+		`"T":1678776360,"c":"11.02","i":"Min1","o":"11","t":1678776300,"v":"1234.5"},`+
+		`"e":"spot@public.kline.v3.api"},"s":"ATOMUSDT"}`))
+	candles, err := p.GetCandlePrices(context.Background(), cp)
+	require.NoError(t, err)
+	require.Len(t, candles["ATOMUSDT"], 1)
+	require.Equal(t, sdk.MustNewDecFromStr("11.02"), candles["ATOMUSDT"][0].Price)
+	require.Equal(t, sdk.MustNewDecFromStr("1234.5"), candles["ATOMUSDT"][0].Volume)
+	require.Equal(t, int64(1678776360000), candles["ATOMUSDT"][0].TimeStamp)
+}
+
+func TestMexcCurrencyPairToMexcPair(t *testing.T) {
+	cp := types.CurrencyPair{Base: "ATOM", Quote: "USDT"}
+	require.Equal(t, "spot@public.deals.v3.api@ATOMUSDT", currencyPairToMexcDeals(cp))
+	require.Equal(t, "spot@public.kline.v3.api@ATOMUSDT@Min1", currencyPairToMexcCandle(cp))
+}
+
+func TestMexcProvider_getSubscriptionMsgs(t *testing.T) {
+	provider := &MexcProvider{
+		subscribedPairs: map[string]types.CurrencyPair{},
+	}
+	cps := []types.CurrencyPair{
+		{Base: "ATOM", Quote: "USDT"},
+	}
+
+	subMsgs := provider.getSubscriptionMsgs(cps...)
+
+	msg, _ := json.Marshal(subMsgs[0])
+	require.Equal(t, `{"method":"SUBSCRIPTION","params":["spot@public.deals.v3.api@ATOMUSDT",`+
+		`"spot@public.kline.v3.api@ATOMUSDT@Min1"]}`, string(msg))
+
+	require.Empty(t, provider.getSubscriptionMsgs())
+}
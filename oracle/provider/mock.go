@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"context"
 	"encoding/csv"
 	"fmt"
 	"net/http"
@@ -105,7 +106,10 @@ func (p *MockProvider) loadPrices() error {
 }
 
 // GetTickerPrices returns the mocked ticker price for the given symbol.
-func (p *MockProvider) GetTickerPrices(pairs ...types.CurrencyPair) (map[string]types.TickerPrice, error) {
+func (p *MockProvider) GetTickerPrices(
+	_ context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
 	prices := make(map[string]types.TickerPrice, len(pairs))
 	for _, pair := range pairs {
 		ticker := strings.ToUpper(pair.String())
@@ -127,7 +131,10 @@ func (p *MockProvider) GetTickerPrices(pairs ...types.CurrencyPair) (map[string]
 	return prices, nil
 }
 
-func (p *MockProvider) GetCandlePrices(pairs ...types.CurrencyPair) (map[string][]types.CandlePrice, error) {
+func (p *MockProvider) GetCandlePrices(
+	_ context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
 	candles := make(map[string][]types.CandlePrice, len(pairs))
 	for _, pair := range pairs {
 		ticker := strings.ToUpper(pair.String())
@@ -148,6 +155,6 @@ func (p *MockProvider) GetCandlePrices(pairs ...types.CurrencyPair) (map[string]
 }
 
 // SubscribeCurrencyPairs performs a no-op since mock does not use websocket.
-func (p MockProvider) SubscribeCurrencyPairs(...types.CurrencyPair) error {
+func (p MockProvider) SubscribeCurrencyPairs(context.Context, ...types.CurrencyPair) error {
 	return fmt.Errorf("mock provider does not support subscriptions")
 }
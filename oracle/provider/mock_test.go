@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -28,7 +29,7 @@ ATOM,USD,21.84,1827884.77
 		mp, err := NewMockProvider(server.URL, server.Client())
 		require.NoError(t, err)
 
-		prices, err := mp.GetTickerPrices(types.CurrencyPair{Base: "OSMO", Quote: "USD"})
+		prices, err := mp.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "OSMO", Quote: "USD"})
 		require.NoError(t, err)
 		require.Len(t, prices, 1)
 		require.Equal(t, sdk.MustNewDecFromStr("3.04"), prices["OSMOUSD"].Price)
@@ -51,6 +52,7 @@ ATOM,USD,21.84,1827884.77
 		require.NoError(t, err)
 
 		prices, err := mp.GetTickerPrices(
+			context.Background(),
 			types.CurrencyPair{Base: "OSMO", Quote: "USD"},
 			types.CurrencyPair{Base: "ATOM", Quote: "USD"},
 		)
@@ -73,7 +75,7 @@ ATOM,USD,21.84,1827884.77
 		mp, err := NewMockProvider(server.URL, server.Client())
 		require.NoError(t, err)
 
-		prices, err := mp.GetTickerPrices(types.CurrencyPair{Base: "OSMO", Quote: "USD"})
+		prices, err := mp.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "OSMO", Quote: "USD"})
 		require.Error(t, err)
 		require.Nil(t, prices)
 	})
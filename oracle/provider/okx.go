@@ -0,0 +1,427 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+const (
+	okxWSHost        = "ws.okx.com:8443"
+	okxWSPath        = "/ws/v5/public"
+	okxRestHost      = "https://www.okx.com"
+	okxRestPath      = "/api/v5/market/tickers?instType=SPOT"
+	okxTickerChannel = "tickers"
+	okxCandleChannel = "candle1m"
+	okxEventError    = "error"
+)
+
+var _ Provider = (*OkxProvider)(nil)
+
+type (
+	// OkxProvider defines an Oracle provider implemented by the OKX public
+	// API.
+	//
+	// REF: https://www.okx.com/docs-v5/en/#websocket-api-public-channel
+	OkxProvider struct {
+		wsc             *WebsocketController
+		logger          zerolog.Logger
+		mtx             sync.RWMutex
+		endpoints       Endpoint
+		tickers         map[string]types.TickerPrice   // InstId => TickerPrice
+		candles         map[string][]types.CandlePrice // InstId => CandlePrice
+		subscribedPairs map[string]types.CurrencyPair  // Symbol => types.CurrencyPair
+	}
+
+	// OkxSubscriptionTopic defines the channel of an instrument, subscribed to
+	// and sent along the messages it pushes.
+	OkxSubscriptionTopic struct {
+		Channel string `json:"channel"` // ex.: tickers
+		InstID  string `json:"instId"`  // ex.: ATOM-USDT
+	}
+
+	OkxSubscriptionMsg struct {
+		Op   string                 `json:"op"` // subscribe, unsubscribe
+		Args []OkxSubscriptionTopic `json:"args"`
+	}
+
+	// OkxEventResponse defines the response to a subscription, which reports
+	// its errors.
+	OkxEventResponse struct {
+		Event string `json:"event"` // ex.: subscribe, error
+		Code  string `json:"code"`
+		Msg   string `json:"msg"`
+	}
+
+	OkxTickerResponse struct {
+		Arg  OkxSubscriptionTopic `json:"arg"`
+		Data []OkxTicker          `json:"data"`
+	}
+	OkxTicker struct {
+		InstID string `json:"instId"` // Instrument ID, ex.: ATOM-USDT
+		Last   string `json:"last"`   // Last traded price
+		Vol24h string `json:"vol24h"` // 24h trading volume in the base currency
+	}
+
+	// OkxCandleResponse defines a candle message, whose candles are arrays of
+	// the start time in milliseconds, the open, high, low and close prices and
+	// the volume in the base currency, followed by other fields.
+	OkxCandleResponse struct {
+		Arg  OkxSubscriptionTopic `json:"arg"`
+		Data [][]string           `json:"data"`
+	}
+
+	OkxPairsSummary struct {
+		Data []OkxTicker `json:"data"`
+	}
+)
+
+func NewOkxProvider(
+	ctx context.Context,
+	logger zerolog.Logger,
+	endpoints Endpoint,
+	pairs ...types.CurrencyPair,
+) (*OkxProvider, error) {
+	if endpoints.Name != Okx {
+		endpoints = Endpoint{
+			Name:      Okx,
+			Rest:      okxRestHost,
+			Websocket: okxWSHost,
+		}
+	}
+
+	wsURL := url.URL{
+		Scheme: "wss",
+		Host:   endpoints.Websocket,
+		Path:   okxWSPath,
+	}
+
+	okxLogger := logger.With().Str("provider", "okx").Logger()
+
+	provider := &OkxProvider{
+		logger:          okxLogger,
+		endpoints:       endpoints,
+		tickers:         map[string]types.TickerPrice{},
+		candles:         map[string][]types.CandlePrice{},
+		subscribedPairs: map[string]types.CurrencyPair{},
+	}
+
+	provider.setSubscribedPairs(pairs...)
+
+	// OKX closes connections without any message for 30 seconds, which a
+	// "ping" text message every defaultPingDuration prevents
+	provider.wsc = NewWebsocketController(
+		ctx,
+		Okx,
+		wsURL,
+		provider.getSubscriptionMsgs(pairs...),
+		provider.messageReceived,
+		defaultPingDuration,
+		websocket.TextMessage,
+		okxLogger,
+	)
+
+	return provider, nil
+}
+
+// Start connects to the websocket in the background, see Lifecycle.
+func (p *OkxProvider) Start() error {
+	go p.wsc.Start()
+	return nil
+}
+
+// Stop closes the websocket, see Lifecycle.
+func (p *OkxProvider) Stop() {
+	p.wsc.Stop()
+}
+
+func (p *OkxProvider) getSubscriptionMsgs(cps ...types.CurrencyPair) []interface{} {
+	if len(cps) == 0 {
+		return []interface{}{}
+	}
+
+	topics := make([]OkxSubscriptionTopic, 0, len(cps)*2) //nolint: gomnd //const
+	for _, cp := range cps {
+		instID := currencyPairToOkxPair(cp)
+		topics = append(topics,
+			OkxSubscriptionTopic{Channel: okxTickerChannel, InstID: instID},
+			OkxSubscriptionTopic{Channel: okxCandleChannel, InstID: instID},
+		)
+	}
+
+	return []interface{}{newOkxSubscriptionMsg(topics)}
+}
+
+// SubscribeCurrencyPairs sends the new subscription messages to the websocket
+// and adds them to the providers subscribedPairs array.
+func (p *OkxProvider) SubscribeCurrencyPairs(_ context.Context, cps ...types.CurrencyPair) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	newPairs := []types.CurrencyPair{}
+	for _, cp := range cps {
+		if _, ok := p.subscribedPairs[cp.String()]; !ok {
+			newPairs = append(newPairs, cp)
+		}
+	}
+
+	newSubscriptionMsgs := p.getSubscriptionMsgs(newPairs...)
+	if err := p.wsc.AddSubscriptionMsgs(newSubscriptionMsgs); err != nil {
+		return err
+	}
+	p.setSubscribedPairs(newPairs...)
+	return nil
+}
+
+// GetTickerPrices returns the tickerPrices based on the saved map.
+func (p *OkxProvider) GetTickerPrices(
+	_ context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
+	tickerPrices := make(map[string]types.TickerPrice, len(pairs))
+
+	for _, cp := range pairs {
+		price, err := p.getTickerPrice(currencyPairToOkxPair(cp))
+		if err != nil {
+			return nil, err
+		}
+		tickerPrices[cp.String()] = price
+	}
+
+	return tickerPrices, nil
+}
+
+// GetCandlePrices returns the candlePrices based on the saved map.
+func (p *OkxProvider) GetCandlePrices(
+	_ context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
+	candlePrices := make(map[string][]types.CandlePrice, len(pairs))
+
+	for _, cp := range pairs {
+		prices, err := p.getCandlePrices(currencyPairToOkxPair(cp))
+		if err != nil {
+			return nil, err
+		}
+		candlePrices[cp.String()] = prices
+	}
+
+	return candlePrices, nil
+}
+
+func (p *OkxProvider) getTickerPrice(key string) (types.TickerPrice, error) {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	ticker, ok := p.tickers[key]
+	if !ok {
+		return types.TickerPrice{}, fmt.Errorf(
+			types.ErrTickerNotFound,
+			Okx,
+			key,
+		)
+	}
+
+	return ticker, nil
+}
+
+func (p *OkxProvider) getCandlePrices(key string) ([]types.CandlePrice, error) {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	candles, ok := p.candles[key]
+	if !ok {
+		return []types.CandlePrice{}, fmt.Errorf(
+			types.ErrCandleNotFound,
+			Okx,
+			key,
+		)
+	}
+
+	candleList := []types.CandlePrice{}
+	candleList = append(candleList, candles...)
+
+	return candleList, nil
+}
+
+func (p *OkxProvider) messageReceived(messageType int, bz []byte) {
+	if messageType != websocket.TextMessage {
+		return
+	}
+
+	var (
+		eventResp  OkxEventResponse
+		eventErr   error
+		tickerResp OkxTickerResponse
+		tickerErr  error
+		candleResp OkxCandleResponse
+		candleErr  error
+	)
+
+	// subscriptions are answered with an event instead of pushed data
+	eventErr = json.Unmarshal(bz, &eventResp)
+	if len(eventResp.Event) > 0 {
+		if eventResp.Event == okxEventError {
+			p.logger.Error().
+				Str("code", eventResp.Code).
+				Str("msg", eventResp.Msg).
+				Msg("okx: subscription failed")
+		}
+		return
+	}
+
+	tickerErr = json.Unmarshal(bz, &tickerResp)
+	if tickerResp.Arg.Channel == okxTickerChannel {
+		for _, ticker := range tickerResp.Data {
+			p.setTickerPair(ticker)
+
+			p.logger.Debug().
+				Str("provider", Okx.String()).
+				Str("messageType", messageTypeTicker).
+				Msg("Message received")
+		}
+		return
+	}
+
+	candleErr = json.Unmarshal(bz, &candleResp)
+	if candleResp.Arg.Channel == okxCandleChannel {
+		for _, candle := range candleResp.Data {
+			p.setCandlePair(candleResp.Arg.InstID, candle)
+
+			p.logger.Debug().
+				Str("provider", Okx.String()).
+				Str("messageType", messageTypeCandle).
+				Msg("Message received")
+		}
+		return
+	}
+
+	p.logger.Error().
+		Int("length", len(bz)).
+		AnErr("event", eventErr).
+		AnErr("ticker", tickerErr).
+		AnErr("candle", candleErr).
+		Msg("Error on receive message")
+}
+
+func (p *OkxProvider) setTickerPair(ticker OkxTicker) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	tickerPrice, err := types.NewTickerPrice(
+		string(Okx),
+		ticker.InstID,
+		ticker.Last,
+		ticker.Vol24h,
+	)
+	if err != nil {
+		p.logger.Warn().Err(err).Msg("okx: failed to parse ticker")
+		return
+	}
+
+	p.tickers[ticker.InstID] = tickerPrice
+}
+
+// setCandlePair stores the candle of instID, replacing the previous update of
+// the same candle since OKX pushes the candle in progress on every trade.
+func (p *OkxProvider) setCandlePair(instID string, data []string) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if len(data) < 6 { //nolint:gomnd // start time, OHLC and volume
+		p.logger.Warn().Int("length", len(data)).Msg("okx: failed to parse candle")
+		return
+	}
+
+	timeStamp, err := strconv.ParseInt(data[0], 10, 64)
+	if err != nil {
+		p.logger.Warn().Err(err).Msg("okx: failed to parse candle")
+		return
+	}
+
+	candle, err := types.NewCandlePrice(
+		string(Okx),
+		instID,
+		data[4],
+		data[5],
+		timeStamp,
+	)
+	if err != nil {
+		p.logger.Warn().Err(err).Msg("okx: failed to parse candle")
+		return
+	}
+
+	staleTime := PastUnixTime(providerCandlePeriod)
+	candleList := []types.CandlePrice{}
+	candleList = append(candleList, candle)
+
+	for _, c := range p.candles[instID] {
+		if staleTime < c.TimeStamp && c.TimeStamp != candle.TimeStamp {
+			candleList = append(candleList, c)
+		}
+	}
+
+	p.candles[instID] = candleList
+}
+
+// setSubscribedPairs sets N currency pairs to the map of subscribed pairs.
+func (p *OkxProvider) setSubscribedPairs(cps ...types.CurrencyPair) {
+	for _, cp := range cps {
+		p.subscribedPairs[cp.String()] = cp
+	}
+}
+
+// GetAvailablePairs returns all pairs to which the provider can subscribe.
+// ex.: map["ATOMUSDT" => {}, "XPRTUSDT" => {}].
+func (p *OkxProvider) GetAvailablePairs() (map[string]struct{}, error) {
+	resp, err := newDefaultHTTPClient().Get(p.endpoints.Rest + okxRestPath)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var pairsSummary OkxPairsSummary
+	if err := json.NewDecoder(resp.Body).Decode(&pairsSummary); err != nil {
+		return nil, err
+	}
+
+	availablePairs := make(map[string]struct{}, len(pairsSummary.Data))
+	for _, pair := range pairsSummary.Data {
+		splitInstID := strings.Split(pair.InstID, "-")
+		if len(splitInstID) != 2 { //nolint: gomnd //const
+			continue
+		}
+
+		cp := types.CurrencyPair{
+			Base:  strings.ToUpper(splitInstID[0]),
+			Quote: strings.ToUpper(splitInstID[1]),
+		}
+
+		availablePairs[cp.String()] = struct{}{}
+	}
+
+	return availablePairs, nil
+}
+
+// currencyPairToOkxPair receives a currency pair and return okx
+// instrument ID, ex.: ATOM-USDT.
+func currencyPairToOkxPair(cp types.CurrencyPair) string {
+	return strings.ToUpper(cp.Base + "-" + cp.Quote)
+}
+
+// newOkxSubscriptionMsg returns a new subscription Msg.
+func newOkxSubscriptionMsg(topics []OkxSubscriptionTopic) OkxSubscriptionMsg {
+	return OkxSubscriptionMsg{
+		Op:   "subscribe",
+		Args: topics,
+	}
+}
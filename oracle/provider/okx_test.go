@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+//nolint:funlen // test
+func TestOkxProvider_GetTickerPrices(t *testing.T) {
+	p, err := NewOkxProvider(
+		context.TODO(),
+		zerolog.Nop(),
+		Endpoint{},
+		types.CurrencyPair{Base: "ATOM", Quote: "USDT"},
+	)
+	require.NoError(t, err)
+
+	t.Run("valid_request_single_ticker", func(t *testing.T) {
+		lastPrice := sdk.MustNewDecFromStr("34.69000000")
+		volume := sdk.MustNewDecFromStr("2396974.02000000")
+
+		tickerMap := map[string]types.TickerPrice{}
+		tickerMap["ATOM-USDT"] = types.TickerPrice{
+			Price:  lastPrice,
+			Volume: volume,
+		}
+
+		p.tickers = tickerMap
+
+		prices, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "ATOM", Quote: "USDT"})
+		require.NoError(t, err)
+		require.Len(t, prices, 1)
+		require.Equal(t, lastPrice, prices["ATOMUSDT"].Price)
+		require.Equal(t, volume, prices["ATOMUSDT"].Volume)
+	})
+
+	//nolint:dupl // test
+	t.Run("valid_request_multi_ticker", func(t *testing.T) {
+		lastPriceAtom := sdk.MustNewDecFromStr("34.69000000")
+		lastPriceXprt := sdk.MustNewDecFromStr("0.41350000")
+		volume := sdk.MustNewDecFromStr("2396974.02000000")
+
+		tickerMap := map[string]types.TickerPrice{}
+		tickerMap["ATOM-USDT"] = types.TickerPrice{
+			Price:  lastPriceAtom,
+			Volume: volume,
+		}
+
+		tickerMap["XPRT-USDT"] = types.TickerPrice{
+			Price:  lastPriceXprt,
+			Volume: volume,
+		}
+
+		p.tickers = tickerMap
+		prices, err := p.GetTickerPrices(
+			context.Background(),
+			types.CurrencyPair{Base: "ATOM", Quote: "USDT"},
+			types.CurrencyPair{Base: "XPRT", Quote: "USDT"},
+		)
+		require.NoError(t, err)
+		require.Len(t, prices, 2)
+		require.Equal(t, lastPriceAtom, prices["ATOMUSDT"].Price)
+		require.Equal(t, volume, prices["ATOMUSDT"].Volume)
+		require.Equal(t, lastPriceXprt, prices["XPRTUSDT"].Price)
+		require.Equal(t, volume, prices["XPRTUSDT"].Volume)
+	})
+
+	t.Run("invalid_request_invalid_ticker", func(t *testing.T) {
+		prices, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "FOO", Quote: "BAR"})
+		require.Error(t, err)
+		require.Equal(t, "okx failed to get ticker price for FOO-BAR", err.Error())
+		require.Nil(t, prices)
+	})
+}
+
+func TestOkxProvider_GetCandlePrices(t *testing.T) {
+	p, err := NewOkxProvider(
+		context.TODO(),
+		zerolog.Nop(),
+		Endpoint{},
+		types.CurrencyPair{Base: "ATOM", Quote: "USDT"},
+	)
+	require.NoError(t, err)
+
+	t.Run("valid_request_single_candle", func(t *testing.T) {
+		price := "34.689998626708984000"
+		volume := "2396974.000000000000000000"
+		timeStamp := PastUnixTime(0)
+
+		p.setCandlePair("ATOM-USDT", []string{
+			strconv.FormatInt(timeStamp, 10), "34.1", "34.9", "33.8", price, volume, "0", "0", "0",
+		})
+
+		prices, err := p.GetCandlePrices(context.Background(), types.CurrencyPair{Base: "ATOM", Quote: "USDT"})
+		require.NoError(t, err)
+		require.Len(t, prices, 1)
+
+		require.Equal(t, sdk.MustNewDecFromStr(price), prices["ATOMUSDT"][0].Price)
+		require.Equal(t, sdk.MustNewDecFromStr(volume), prices["ATOMUSDT"][0].Volume)
+		require.Equal(t, timeStamp, prices["ATOMUSDT"][0].TimeStamp)
+	})
+
+	t.Run("updated_candle_replaced", func(t *testing.T) {
+		timeStamp := PastUnixTime(0)
+
+		p.setCandlePair("ATOM-USDT", []string{strconv.FormatInt(timeStamp, 10), "34.1", "34.9", "33.8", "34.5", "100"})
+		p.setCandlePair("ATOM-USDT", []string{strconv.FormatInt(timeStamp, 10), "34.1", "34.9", "33.8", "34.6", "120"})
+
+		prices, err := p.GetCandlePrices(context.Background(), types.CurrencyPair{Base: "ATOM", Quote: "USDT"})
+		require.NoError(t, err)
+		require.Len(t, prices["ATOMUSDT"], 1)
+		require.Equal(t, sdk.MustNewDecFromStr("34.6"), prices["ATOMUSDT"][0].Price)
+	})
+
+	t.Run("invalid_request_invalid_candle", func(t *testing.T) {
+		prices, err := p.GetCandlePrices(context.Background(), types.CurrencyPair{Base: "FOO", Quote: "BAR"})
+		require.EqualError(t, err, "okx failed to get candle price for FOO-BAR")
+		require.Nil(t, prices)
+	})
+}
+
+func TestOkxProvider_MessageReceived(t *testing.T) {
+	p, err := NewOkxProvider(
+		context.TODO(),
+		zerolog.Nop(),
+		Endpoint{},
+		types.CurrencyPair{Base: "ATOM", Quote: "USDT"},
+	)
+	require.NoError(t, err)
+	cp := types.CurrencyPair{Base: "ATOM", Quote: "USDT"}
+
+	p.messageReceived(websocket.TextMessage,
+		[]byte(`{"event":"subscribe","arg":{"channel":"tickers","instId":"ATOM-USDT"}}`))
+	_, err = p.GetTickerPrices(context.Background(), cp)
+	require.Error(t, err)
+
+	p.messageReceived(websocket.TextMessage, []byte(`{"arg":{"channel":"tickers","instId":"ATOM-USDT"},`+
+		`"data":[{"instType":"SPOT","instId":"ATOM-USDT","last":"11.05","vol24h":"894123.5"}]}`))
+	prices, err := p.GetTickerPrices(context.Background(), cp)
+	require.NoError(t, err)
+	require.Equal(t, sdk.MustNewDecFromStr("11.05"), prices["ATOMUSDT"].Price)
+	require.Equal(t, sdk.MustNewDecFromStr("894123.5"), prices["ATOMUSDT"].Volume)
+
+	timeStamp := strconv.FormatInt(PastUnixTime(0), 10)
+	p.messageReceived(websocket.TextMessage, []byte(`{"arg":{"channel":"candle1m","instId":"ATOM-USDT"},`+
+		`"data":[["`+timeStamp+`","11","11.1","10.9","11.02","1234.5","13600","13600","0"]]}`))
+	candles, err := p.GetCandlePrices(context.Background(), cp)
+	require.NoError(t, err)
+	require.Len(t, candles["ATOMUSDT"], 1)
+	require.Equal(t, sdk.MustNewDecFromStr("11.02"), candles["ATOMUSDT"][0].Price)
+	require.Equal(t, sdk.MustNewDecFromStr("1234.5"), candles["ATOMUSDT"][0].Volume)
+}
+
+func TestOkxCurrencyPairToOkxPair(t *testing.T) {
+	cp := types.CurrencyPair{Base: "ATOM", Quote: "USDT"}
+	okxSymbol := currencyPairToOkxPair(cp)
+	require.Equal(t, okxSymbol, "ATOM-USDT")
+}
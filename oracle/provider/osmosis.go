@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -72,14 +73,17 @@ func NewOsmosisProvider(endpoint Endpoint) *OsmosisProvider {
 }
 
 // SubscribeCurrencyPairs performs a no-op since osmosis does not use websockets.
-func (OsmosisProvider) SubscribeCurrencyPairs(...types.CurrencyPair) error {
+func (OsmosisProvider) SubscribeCurrencyPairs(context.Context, ...types.CurrencyPair) error {
 	return nil
 }
 
-func (p OsmosisProvider) GetTickerPrices(pairs ...types.CurrencyPair) (map[string]types.TickerPrice, error) {
+func (p OsmosisProvider) GetTickerPrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
 	path := fmt.Sprintf("%s%s/all", p.baseURL, osmosisTokenEndpoint)
 
-	resp, err := p.client.Get(path)
+	resp, err := p.get(ctx, path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make Osmosis request: %w", err)
 	}
@@ -94,6 +98,7 @@ func (p OsmosisProvider) GetTickerPrices(pairs ...types.CurrencyPair) (map[strin
 	if err != nil {
 		return nil, fmt.Errorf("failed to read Osmosis response body: %w", err)
 	}
+	capturePayload(Osmosis, bz)
 
 	var tokensResp []OsmosisTokenResponse
 	if err := json.Unmarshal(bz, &tokensResp); err != nil {
@@ -134,7 +139,10 @@ func (p OsmosisProvider) GetTickerPrices(pairs ...types.CurrencyPair) (map[strin
 	return tickerPrices, nil
 }
 
-func (p OsmosisProvider) GetCandlePrices(pairs ...types.CurrencyPair) (map[string][]types.CandlePrice, error) {
+func (p OsmosisProvider) GetCandlePrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
 	candles := make(map[string][]types.CandlePrice)
 	for _, pair := range pairs {
 		if _, ok := candles[pair.Base]; !ok {
@@ -143,7 +151,7 @@ func (p OsmosisProvider) GetCandlePrices(pairs ...types.CurrencyPair) (map[strin
 
 		path := fmt.Sprintf("%s%s/%s/chart?tf=5", p.baseURL, osmosisCandleEndpoint, pair.Base)
 
-		resp, err := p.client.Get(path)
+		resp, err := p.get(ctx, path)
 		if err != nil {
 			return nil, fmt.Errorf("failed to make Osmosis request: %w", err)
 		}
@@ -158,6 +166,7 @@ func (p OsmosisProvider) GetCandlePrices(pairs ...types.CurrencyPair) (map[strin
 		if err != nil {
 			return nil, fmt.Errorf("failed to read Osmosis response body: %w", err)
 		}
+		capturePayload(Osmosis, bz)
 
 		var candlesResp []OsmosisCandleResponse
 		if err := json.Unmarshal(bz, &candlesResp); err != nil {
@@ -216,3 +225,13 @@ func (p OsmosisProvider) GetAvailablePairs() (map[string]struct{}, error) {
 
 	return availablePairs, nil
 }
+
+// get requests the url, bounded by ctx.
+func (p OsmosisProvider) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.client.Do(req)
+}
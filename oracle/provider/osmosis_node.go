@@ -0,0 +1,517 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+const (
+	osmosisSpotPriceMethod          = "/osmosis.poolmanager.v1beta1.Query/SpotPrice"
+	osmosisTotalVolumeForPoolMethod = "/osmosis.poolmanager.v1beta1.Query/TotalVolumeForPool"
+	osmosisArithmeticTwapMethod     = "/osmosis.twap.v1beta1.Query/ArithmeticTwapToNow"
+
+	// osmosisFirstCandlePeriod is the period the TWAP of the first candle of a
+	// pool is computed over, as there is no previous candle to start from.
+	osmosisFirstCandlePeriod = time.Minute
+)
+
+var _ Provider = (*OsmosisNodeProvider)(nil)
+
+type (
+	// OsmosisNodeProvider defines an Oracle provider querying the pools of an
+	// Osmosis node directly over gRPC, without depending on an indexer.
+	//
+	// Ticker prices are the pool spot prices. Candles are built from the
+	// arithmetic TWAP and the volume traded in the pool since the previous
+	// candle, one per request, and ticker volumes are the volume of the
+	// candles within providerCandlePeriod.
+	//
+	// REF: https://github.com/osmosis-labs/osmosis/tree/main/proto/osmosis/poolmanager/v1beta1
+	// REF: https://github.com/osmosis-labs/osmosis/tree/main/proto/osmosis/twap/v1beta1
+	OsmosisNodeProvider struct {
+		conn  *grpc.ClientConn
+		mtx   sync.Mutex
+		pools map[string]Pool              // CurrencyPair.String() => Pool
+		state map[string]*osmosisPoolState // CurrencyPair.String() => state
+	}
+
+	// osmosisPoolState defines the candles built for a pool and the pool
+	// cumulative volume they were built from.
+	osmosisPoolState struct {
+		candles      []types.CandlePrice
+		lastTime     time.Time
+		lastVolume   sdk.Int
+		volumeLoaded bool
+	}
+)
+
+// NewOsmosisNodeProvider returns an Osmosis provider querying the node at the
+// endpoint gRPC address for the configured pools.
+func NewOsmosisNodeProvider(endpoint Endpoint) (*OsmosisNodeProvider, error) {
+	conn, err := grpc.Dial(
+		endpoint.GRPC,
+		// the Cosmos SDK doesn't support any transport security mechanism
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(nodeCodec{})),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial Osmosis gRPC service: %w", err)
+	}
+
+	return newOsmosisNodeProvider(conn, endpoint.Pools), nil
+}
+
+func newOsmosisNodeProvider(conn *grpc.ClientConn, pools []Pool) *OsmosisNodeProvider {
+	p := &OsmosisNodeProvider{
+		conn:  conn,
+		pools: make(map[string]Pool, len(pools)),
+		state: make(map[string]*osmosisPoolState, len(pools)),
+	}
+	for _, pool := range pools {
+		cp := types.CurrencyPair{Base: pool.Base, Quote: pool.Quote}
+		p.pools[cp.String()] = pool
+		p.state[cp.String()] = &osmosisPoolState{}
+	}
+
+	return p
+}
+
+// SubscribeCurrencyPairs performs a no-op since the pools are queried on
+// every request.
+func (*OsmosisNodeProvider) SubscribeCurrencyPairs(context.Context, ...types.CurrencyPair) error {
+	return nil
+}
+
+// GetTickerPrices returns the spot prices of the pools of the given pairs.
+func (p *OsmosisNodeProvider) GetTickerPrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	tickerPrices := make(map[string]types.TickerPrice, len(pairs))
+	for _, cp := range pairs {
+		pool, ok := p.pools[cp.String()]
+		if !ok {
+			return nil, fmt.Errorf("no Osmosis pool configured for %s", cp.String())
+		}
+
+		req := osmosisSpotPriceRequest{PoolID: pool.ID, BaseDenom: pool.BaseDenom, QuoteDenom: pool.QuoteDenom}
+		var resp osmosisSpotPriceResponse
+		if err := p.conn.Invoke(ctx, osmosisSpotPriceMethod, &req, &resp); err != nil {
+			return nil, fmt.Errorf("failed to query Osmosis pool %d spot price: %w", pool.ID, err)
+		}
+
+		tickerPrices[cp.String()] = types.TickerPrice{
+			Price:  scalePoolPrice(pool, resp.SpotPrice),
+			Volume: p.recentVolume(cp),
+		}
+	}
+
+	return tickerPrices, nil
+}
+
+// GetCandlePrices appends a candle with the TWAP and the volume traded since
+// the previous candle to the candles of the given pairs and returns the
+// candles within providerCandlePeriod.
+func (p *OsmosisNodeProvider) GetCandlePrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	candles := make(map[string][]types.CandlePrice, len(pairs))
+	for _, cp := range pairs {
+		pool, ok := p.pools[cp.String()]
+		if !ok {
+			return nil, fmt.Errorf("no Osmosis pool configured for %s", cp.String())
+		}
+		state := p.state[cp.String()]
+
+		now := time.Now()
+		start := state.lastTime
+		if start.IsZero() {
+			start = now.Add(-osmosisFirstCandlePeriod)
+		}
+
+		twapReq := osmosisArithmeticTwapRequest{
+			PoolID:     pool.ID,
+			BaseDenom:  pool.BaseDenom,
+			QuoteDenom: pool.QuoteDenom,
+			StartTime:  start,
+		}
+		var twapResp osmosisArithmeticTwapResponse
+		if err := p.conn.Invoke(ctx, osmosisArithmeticTwapMethod, &twapReq, &twapResp); err != nil {
+			return nil, fmt.Errorf("failed to query Osmosis pool %d TWAP: %w", pool.ID, err)
+		}
+
+		volumeReq := osmosisTotalVolumeRequest{PoolID: pool.ID}
+		var volumeResp osmosisTotalVolumeResponse
+		if err := p.conn.Invoke(ctx, osmosisTotalVolumeForPoolMethod, &volumeReq, &volumeResp); err != nil {
+			return nil, fmt.Errorf("failed to query Osmosis pool %d volume: %w", pool.ID, err)
+		}
+		totalVolume := volumeResp.AmountOf(pool.BaseDenom)
+
+		// the volume of the first candle is unknown, as the volume is cumulative
+		volume := sdk.ZeroInt()
+		if state.volumeLoaded && totalVolume.GT(state.lastVolume) {
+			volume = totalVolume.Sub(state.lastVolume)
+		}
+
+		state.candles = append(state.candles, types.CandlePrice{
+			Price:     scalePoolPrice(pool, twapResp.ArithmeticTwap),
+			Volume:    scalePoolAmount(volume, pool.BaseExponent),
+			TimeStamp: now.UnixMilli(),
+		})
+		state.lastTime = now
+		state.lastVolume = totalVolume
+		state.volumeLoaded = true
+
+		staleTime := PastUnixTime(providerCandlePeriod)
+		fresh := state.candles[:0]
+		for _, candle := range state.candles {
+			if candle.TimeStamp > staleTime {
+				fresh = append(fresh, candle)
+			}
+		}
+		state.candles = fresh
+
+		candles[cp.String()] = append([]types.CandlePrice{}, state.candles...)
+	}
+
+	return candles, nil
+}
+
+// recentVolume returns the volume of the candles of the pair within
+// providerCandlePeriod.
+func (p *OsmosisNodeProvider) recentVolume(cp types.CurrencyPair) sdk.Dec {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	volume := sdk.ZeroDec()
+	staleTime := PastUnixTime(providerCandlePeriod)
+	for _, candle := range p.state[cp.String()].candles {
+		if candle.TimeStamp > staleTime {
+			volume = volume.Add(candle.Volume)
+		}
+	}
+
+	return volume
+}
+
+// scalePoolPrice converts a price of the pool base denom in quote denom to
+// the price of the base symbol in quote symbol.
+func scalePoolPrice(pool Pool, price sdk.Dec) sdk.Dec {
+	exponent := pool.BaseExponent - pool.QuoteExponent
+	if exponent >= 0 {
+		return price.Mul(pow10(exponent))
+	}
+	return price.Quo(pow10(-exponent))
+}
+
+// scalePoolAmount converts an amount of a pool denom to its symbol amount.
+func scalePoolAmount(amount sdk.Int, exponent int64) sdk.Dec {
+	return sdk.NewDecFromInt(amount).Quo(pow10(exponent))
+}
+
+func pow10(exponent int64) sdk.Dec {
+	return sdk.NewDec(10).Power(uint64(exponent)) //nolint:gomnd //decimal base
+}
+
+// nodeCodec encodes the query messages of the on-chain providers, which are
+// encoded by hand as the feeder does not depend on the modules of their
+// chains.
+type nodeCodec struct{}
+
+type nodeMessage interface {
+	Marshal() ([]byte, error)
+	Unmarshal([]byte) error
+}
+
+func (nodeCodec) Marshal(v interface{}) ([]byte, error) {
+	msg, ok := v.(nodeMessage)
+	if !ok {
+		return nil, fmt.Errorf("unsupported message type %T", v)
+	}
+	return msg.Marshal()
+}
+
+func (nodeCodec) Unmarshal(data []byte, v interface{}) error {
+	msg, ok := v.(nodeMessage)
+	if !ok {
+		return fmt.Errorf("unsupported message type %T", v)
+	}
+	return msg.Unmarshal(data)
+}
+
+func (nodeCodec) Name() string {
+	return "proto"
+}
+
+type (
+	// osmosisSpotPriceRequest defines the poolmanager SpotPriceRequest.
+	osmosisSpotPriceRequest struct {
+		PoolID     uint64
+		BaseDenom  string
+		QuoteDenom string
+	}
+
+	// osmosisSpotPriceResponse defines the poolmanager SpotPriceResponse,
+	// whose spot price is a decimal string.
+	osmosisSpotPriceResponse struct {
+		SpotPrice sdk.Dec
+	}
+
+	// osmosisArithmeticTwapRequest defines the twap ArithmeticTwapToNowRequest.
+	osmosisArithmeticTwapRequest struct {
+		PoolID     uint64
+		BaseDenom  string
+		QuoteDenom string
+		StartTime  time.Time
+	}
+
+	// osmosisArithmeticTwapResponse defines the twap
+	// ArithmeticTwapToNowResponse, whose TWAP is an encoded sdk.Dec.
+	osmosisArithmeticTwapResponse struct {
+		ArithmeticTwap sdk.Dec
+	}
+
+	// osmosisTotalVolumeRequest defines the poolmanager
+	// TotalVolumeForPoolRequest.
+	osmosisTotalVolumeRequest struct {
+		PoolID uint64
+	}
+
+	// osmosisTotalVolumeResponse defines the poolmanager
+	// TotalVolumeForPoolResponse, the cumulative volume per denom.
+	osmosisTotalVolumeResponse struct {
+		Volume sdk.Coins
+	}
+)
+
+func (m *osmosisSpotPriceRequest) Marshal() ([]byte, error) {
+	var b []byte
+	b = appendUint64Field(b, 1, m.PoolID)
+	b = appendStringField(b, 2, m.BaseDenom)
+	b = appendStringField(b, 3, m.QuoteDenom)
+	return b, nil
+}
+
+func (m *osmosisSpotPriceRequest) Unmarshal(data []byte) error {
+	return unmarshalFields(data, func(num protowire.Number, v uint64, bz []byte) error {
+		switch num {
+		case 1:
+			m.PoolID = v
+		case 2:
+			m.BaseDenom = string(bz)
+		case 3:
+			m.QuoteDenom = string(bz)
+		}
+		return nil
+	})
+}
+
+func (m *osmosisSpotPriceResponse) Marshal() ([]byte, error) {
+	return appendStringField(nil, 1, m.SpotPrice.String()), nil
+}
+
+func (m *osmosisSpotPriceResponse) Unmarshal(data []byte) error {
+	return unmarshalFields(data, func(num protowire.Number, _ uint64, bz []byte) (err error) {
+		if num == 1 {
+			m.SpotPrice, err = sdk.NewDecFromStr(string(bz))
+		}
+		return err
+	})
+}
+
+func (m *osmosisArithmeticTwapRequest) Marshal() ([]byte, error) {
+	var timestamp []byte
+	timestamp = appendUint64Field(timestamp, 1, uint64(m.StartTime.Unix()))
+	timestamp = appendUint64Field(timestamp, 2, uint64(m.StartTime.Nanosecond()))
+
+	var b []byte
+	b = appendUint64Field(b, 1, m.PoolID)
+	b = appendStringField(b, 2, m.BaseDenom)
+	b = appendStringField(b, 3, m.QuoteDenom)
+	b = protowire.AppendTag(b, 4, protowire.BytesType)
+	b = protowire.AppendBytes(b, timestamp)
+	return b, nil
+}
+
+func (m *osmosisArithmeticTwapRequest) Unmarshal(data []byte) error {
+	return unmarshalFields(data, func(num protowire.Number, v uint64, bz []byte) error {
+		switch num {
+		case 1:
+			m.PoolID = v
+		case 2:
+			m.BaseDenom = string(bz)
+		case 3:
+			m.QuoteDenom = string(bz)
+		case 4:
+			var seconds, nanos uint64
+			err := unmarshalFields(bz, func(num protowire.Number, v uint64, _ []byte) error {
+				switch num {
+				case 1:
+					seconds = v
+				case 2:
+					nanos = v
+				}
+				return nil
+			})
+			m.StartTime = time.Unix(int64(seconds), int64(nanos))
+			return err
+		}
+		return nil
+	})
+}
+
+func (m *osmosisArithmeticTwapResponse) Marshal() ([]byte, error) {
+	bz, err := m.ArithmeticTwap.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return appendStringField(nil, 1, string(bz)), nil
+}
+
+func (m *osmosisArithmeticTwapResponse) Unmarshal(data []byte) error {
+	return unmarshalFields(data, func(num protowire.Number, _ uint64, bz []byte) error {
+		if num == 1 {
+			return m.ArithmeticTwap.Unmarshal(bz)
+		}
+		return nil
+	})
+}
+
+func (m *osmosisTotalVolumeRequest) Marshal() ([]byte, error) {
+	return appendUint64Field(nil, 1, m.PoolID), nil
+}
+
+func (m *osmosisTotalVolumeRequest) Unmarshal(data []byte) error {
+	return unmarshalFields(data, func(num protowire.Number, v uint64, _ []byte) error {
+		if num == 1 {
+			m.PoolID = v
+		}
+		return nil
+	})
+}
+
+func (m *osmosisTotalVolumeResponse) Marshal() ([]byte, error) {
+	var b []byte
+	for _, coin := range m.Volume {
+		var c []byte
+		c = appendStringField(c, 1, coin.Denom)
+		c = appendStringField(c, 2, coin.Amount.String())
+
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, c)
+	}
+	return b, nil
+}
+
+func (m *osmosisTotalVolumeResponse) Unmarshal(data []byte) error {
+	return unmarshalFields(data, func(num protowire.Number, _ uint64, bz []byte) error {
+		if num != 1 {
+			return nil
+		}
+
+		var coin sdk.Coin
+		err := unmarshalFields(bz, func(num protowire.Number, _ uint64, bz []byte) error {
+			switch num {
+			case 1:
+				coin.Denom = string(bz)
+			case 2:
+				amount, ok := sdk.NewIntFromString(string(bz))
+				if !ok {
+					return fmt.Errorf("invalid coin amount: %s", bz)
+				}
+				coin.Amount = amount
+			}
+			return nil
+		})
+		m.Volume = append(m.Volume, coin)
+		return err
+	})
+}
+
+// AmountOf returns the cumulative volume of the denom.
+func (m *osmosisTotalVolumeResponse) AmountOf(denom string) sdk.Int {
+	for _, coin := range m.Volume {
+		if coin.Denom == denom && !coin.Amount.IsNil() {
+			return coin.Amount
+		}
+	}
+	return sdk.ZeroInt()
+}
+
+func appendUint64Field(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func appendStringField(b []byte, num protowire.Number, v string) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+// unmarshalFields calls fn with the number and value of every varint and
+// length-delimited field of a protobuf message, skipping other field types.
+func unmarshalFields(data []byte, fn func(num protowire.Number, v uint64, bz []byte) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		switch typ {
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(data)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			if err := fn(num, v, nil); err != nil {
+				return err
+			}
+			data = data[n:]
+
+		case protowire.BytesType:
+			bz, n := protowire.ConsumeBytes(data)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			if err := fn(num, 0, bz); err != nil {
+				return err
+			}
+			data = data[n:]
+
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			data = data[n:]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+// rawNodeMessage receives any message in the test chain nodes.
+type rawNodeMessage []byte
+
+func (m *rawNodeMessage) Marshal() ([]byte, error) { return *m, nil }
+
+func (m *rawNodeMessage) Unmarshal(bz []byte) error {
+	*m = append([]byte{}, bz...)
+	return nil
+}
+
+// newTestOsmosisNode serves the Osmosis queries with the given total pool
+// volume, returned by pointer so that tests can update it.
+func newTestOsmosisNode(t *testing.T, totalVolume *sdk.Int) *grpc.ClientConn {
+	handler := func(_ interface{}, stream grpc.ServerStream) error {
+		method, _ := grpc.MethodFromServerStream(stream)
+
+		var raw rawNodeMessage
+		if err := stream.RecvMsg(&raw); err != nil {
+			return err
+		}
+
+		switch method {
+		case osmosisSpotPriceMethod:
+			var req osmosisSpotPriceRequest
+			require.NoError(t, req.Unmarshal(raw))
+			require.Equal(t, osmosisSpotPriceRequest{PoolID: 1, BaseDenom: "uatom", QuoteDenom: "uusdc"}, req)
+			return stream.SendMsg(&osmosisSpotPriceResponse{SpotPrice: sdk.MustNewDecFromStr("10.5")})
+
+		case osmosisArithmeticTwapMethod:
+			var req osmosisArithmeticTwapRequest
+			require.NoError(t, req.Unmarshal(raw))
+			require.Equal(t, uint64(1), req.PoolID)
+			require.WithinDuration(t, time.Now(), req.StartTime, 2*osmosisFirstCandlePeriod)
+			return stream.SendMsg(&osmosisArithmeticTwapResponse{ArithmeticTwap: sdk.MustNewDecFromStr("10.25")})
+
+		case osmosisTotalVolumeForPoolMethod:
+			return stream.SendMsg(&osmosisTotalVolumeResponse{Volume: sdk.NewCoins(
+				sdk.NewCoin("uatom", *totalVolume),
+				sdk.NewCoin("uusdc", totalVolume.MulRaw(10)),
+			)})
+		}
+
+		t.Fatalf("unexpected method %s", method)
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer(grpc.ForceServerCodec(nodeCodec{}), grpc.UnknownServiceHandler(handler))
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial(
+		listener.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(nodeCodec{})),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func TestOsmosisNodeProvider(t *testing.T) {
+	totalVolume := sdk.NewInt(1_000_000_000)
+	p := newOsmosisNodeProvider(newTestOsmosisNode(t, &totalVolume), []Pool{{
+		Base:          "ATOM",
+		Quote:         "USDC",
+		ID:            1,
+		BaseDenom:     "uatom",
+		QuoteDenom:    "uusdc",
+		BaseExponent:  6,
+		QuoteExponent: 6,
+	}})
+	pair := types.CurrencyPair{Base: "ATOM", Quote: "USDC"}
+
+	t.Run("first_candle_has_no_volume", func(t *testing.T) {
+		candles, err := p.GetCandlePrices(context.Background(), pair)
+		require.NoError(t, err)
+		require.Len(t, candles["ATOMUSDC"], 1)
+		require.Equal(t, sdk.MustNewDecFromStr("10.25"), candles["ATOMUSDC"][0].Price)
+		require.True(t, candles["ATOMUSDC"][0].Volume.IsZero())
+	})
+
+	t.Run("candle_volume_is_traded_since_previous_candle", func(t *testing.T) {
+		totalVolume = totalVolume.Add(sdk.NewInt(2_500_000))
+
+		candles, err := p.GetCandlePrices(context.Background(), pair)
+		require.NoError(t, err)
+		require.Len(t, candles["ATOMUSDC"], 2)
+		require.Equal(t, sdk.MustNewDecFromStr("2.5"), candles["ATOMUSDC"][1].Volume)
+	})
+
+	t.Run("ticker", func(t *testing.T) {
+		prices, err := p.GetTickerPrices(context.Background(), pair)
+		require.NoError(t, err)
+		require.Equal(t, sdk.MustNewDecFromStr("10.5"), prices["ATOMUSDC"].Price)
+		require.Equal(t, sdk.MustNewDecFromStr("2.5"), prices["ATOMUSDC"].Volume)
+	})
+
+	t.Run("unconfigured_pool", func(t *testing.T) {
+		_, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "OSMO", Quote: "USDC"})
+		require.Error(t, err)
+	})
+}
+
+func TestScalePoolPrice(t *testing.T) {
+	price := sdk.MustNewDecFromStr("0.000000002")
+
+	// 1 wei = 0.000000002 uusdc, i.e. 1 ETH = 2000 USDC
+	pool := Pool{BaseExponent: 18, QuoteExponent: 6}
+	require.Equal(t, sdk.NewDec(2000), scalePoolPrice(pool, price))
+
+	pool = Pool{BaseExponent: 6, QuoteExponent: 18}
+	require.Equal(t, sdk.MustNewDecFromStr("2"), scalePoolPrice(pool, sdk.MustNewDecFromStr("2000000000000")))
+}
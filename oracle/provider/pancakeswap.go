@@ -0,0 +1,17 @@
+package provider
+
+const (
+	pancakeSwapSubgraphURL = "https://api.thegraph.com/subgraphs/name/pancakeswap/exchange-v3-bsc"
+)
+
+// NewPancakeSwapProvider returns a provider querying the PancakeSwap v3
+// subgraph of BSC at the endpoint REST URL, or the hosted one, for the pools
+// of the endpoint, for assets whose liquidity is mostly on BSC. PancakeSwap
+// v3 is a fork of Uniswap v3 whose subgraph has the same schema, so pools are
+// configured and priced as by the UniswapProvider: identified by their
+// Contract address, with the token addresses as BaseDenom and QuoteDenom.
+//
+// REF: https://docs.pancakeswap.finance/developers/api/subgraph
+func NewPancakeSwapProvider(endpoint Endpoint) *UniswapProvider {
+	return newUniswapV3Provider(PancakeSwap, "PancakeSwap", pancakeSwapSubgraphURL, endpoint)
+}
@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+func TestPancakeSwapProvider(t *testing.T) {
+	const (
+		pool = "0x36696169c63e42cd08ce11f5deebbcebae652050"
+		wbnb = "0xbb4cdb9cbd36b01bd1cbaebf2de08d9173bc095c"
+		usdt = "0x55d398326f99059ff775485246999027b3197955"
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// token1 is WBNB, so its price is token0Price, in USDT
+		fmt.Fprintf(rw, `{"data":{
+			"pool":{"token0":{"id":"%s"},"token1":{"id":"%s"},"token0Price":"322.5","token1Price":"0.0031"},
+			"poolHourDatas":[{"periodStartUnix":0,"volumeToken0":"387000","volumeToken1":"1200"}]
+		}}`, usdt, wbnb)
+	}))
+	defer server.Close()
+
+	p := NewPancakeSwapProvider(Endpoint{
+		Name: PancakeSwap,
+		Rest: server.URL,
+		Pools: []Pool{{
+			Base:       "BNB",
+			Quote:      "USDT",
+			Contract:   pool,
+			BaseDenom:  wbnb,
+			QuoteDenom: usdt,
+		}},
+	})
+	require.Equal(t, server.URL, p.baseURL)
+
+	prices, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "BNB", Quote: "USDT"})
+	require.NoError(t, err)
+	require.Equal(t, sdk.MustNewDecFromStr("322.5"), prices["BNBUSDT"].Price)
+	require.Equal(t, sdk.MustNewDecFromStr("1200"), prices["BNBUSDT"].Volume)
+
+	_, err = p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "CAKE", Quote: "USDT"})
+	require.EqualError(t, err, "no PancakeSwap pool configured for CAKEUSDT")
+}
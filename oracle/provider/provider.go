@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -15,35 +16,75 @@ const (
 	defaultTimeout       = 10 * time.Second
 	providerCandlePeriod = 10 * time.Minute
 
-	Kraken    Name = "kraken"
-	Binance   Name = "binance"
-	BinanceUS Name = "binanceus"
-	Osmosis   Name = "osmosis"
-	Crypto    Name = "crypto"
-	Coinbase  Name = "coinbase"
-	Huobi     Name = "huobi"
-	Mock      Name = "mock"
+	Kraken           Name = "kraken"
+	Binance          Name = "binance"
+	BinanceUS        Name = "binanceus"
+	Osmosis          Name = "osmosis"
+	Crypto           Name = "crypto"
+	Coinbase         Name = "coinbase"
+	Huobi            Name = "huobi"
+	Okx              Name = "okx"
+	Bybit            Name = "bybit"
+	Mexc             Name = "mexc"
+	Gemini           Name = "gemini"
+	Upbit            Name = "upbit"
+	Dexter           Name = "dexter"
+	UniswapV3        Name = "uniswapv3"
+	Curve            Name = "curve"
+	PancakeSwap      Name = "pancakeswap"
+	Crescent         Name = "crescent"
+	Chainlink        Name = "chainlink"
+	CoinGecko        Name = "coingecko"
+	Kaiko            Name = "kaiko"
+	CryptoCompare    Name = "cryptocompare"
+	ECB              Name = "ecb"
+	ExchangeRateHost Name = "exchangeratehost"
+	MetalsAPI        Name = "metalsapi"
+	PStake           Name = "pstake"
+	Umee             Name = "umee"
+	Ojo              Name = "ojo"
+	Kujira           Name = "kujira"
+	Exec             Name = "exec"
+	Generic          Name = "generic"
+	Mock             Name = "mock"
 )
 
 const (
 	messageTypeCandle = string("candle")
 	messageTypeTicker = string("ticker")
+	messageTypeTrade  = string("trade")
 )
 
 var ping = []byte("ping")
 
 type (
 	// Provider defines an interface an exchange price provider must implement.
+	// The context bounds any request the provider makes to serve a call, so
+	// that callers can enforce timeouts and cancel on shutdown.
 	Provider interface {
 		// GetTickerPrices returns the tickerPrices based on the provided pairs.
-		GetTickerPrices(...types.CurrencyPair) (map[string]types.TickerPrice, error)
+		GetTickerPrices(context.Context, ...types.CurrencyPair) (map[string]types.TickerPrice, error)
 
 		// GetCandlePrices returns the candlePrices based on the provided pairs.
-		GetCandlePrices(...types.CurrencyPair) (map[string][]types.CandlePrice, error)
+		GetCandlePrices(context.Context, ...types.CurrencyPair) (map[string][]types.CandlePrice, error)
 
 		// SubscribeCurrencyPairs sends subscription messages for the new currency
 		// pairs and adds them to the providers subscribed pairs
-		SubscribeCurrencyPairs(...types.CurrencyPair) error
+		SubscribeCurrencyPairs(context.Context, ...types.CurrencyPair) error
+	}
+
+	// Lifecycle is implemented by the providers holding connections, which
+	// are only established once started, so that constructing a provider has
+	// no side effect, and closed once stopped. The Oracle starts a provider
+	// right after constructing it and stops it once it no longer needs it. A
+	// stopped provider can't be started again.
+	Lifecycle interface {
+		// Start establishes the connections of the provider in the
+		// background, retrying them until it is stopped.
+		Start() error
+
+		// Stop closes the connections of the provider.
+		Stop()
 	}
 
 	// Name name of an oracle provider. Usually it is an exchange
@@ -70,6 +111,58 @@ type (
 
 		// Websocket endpoint for the provider, ex. "stream.binance.com:9443"
 		Websocket string `toml:"websocket"`
+
+		// GRPC endpoint of a chain node for on-chain providers, ex.
+		// "osmosis-grpc.example.com:9090". When set, the provider queries the
+		// node directly instead of its REST API.
+		GRPC string `toml:"grpc"`
+
+		// Pools maps the currency pairs to the on-chain pools quoting them
+		// when querying a chain node.
+		Pools []Pool `toml:"pools"`
+
+		// Command runs the executable of the exec provider, followed by its
+		// arguments, ex. ["/usr/local/bin/my-source", "--verbose"].
+		Command []string `toml:"command" mapstructure:"command"`
+
+		// Queries maps the currency pairs to the REST APIs quoting them for
+		// the generic provider.
+		Queries []Query `toml:"queries"`
+
+		// APIKey and APISecret authenticate the requests of providers which
+		// support it, granting higher rate limits. They are resolved from the
+		// provider_credentials config and never set from provider_endpoints.
+		APIKey    string `toml:"-" mapstructure:"-"`
+		APISecret string `toml:"-" mapstructure:"-"`
+	}
+
+	// Pool defines an on-chain liquidity pool quoting a currency pair. Pool
+	// prices are quoted in the pool denoms, which are scaled by their
+	// exponents to the symbols of the pair, e.g. "uatom" has exponent 6.
+	// Pools are identified by their ID, or by their Contract address for the
+	// CosmWasm pools of Dexter.
+	Pool struct {
+		Base          string `toml:"base" mapstructure:"base"`
+		Quote         string `toml:"quote" mapstructure:"quote"`
+		ID            uint64 `toml:"id" mapstructure:"id"`
+		Contract      string `toml:"contract" mapstructure:"contract"`
+		BaseDenom     string `toml:"base_denom" mapstructure:"base_denom"`
+		QuoteDenom    string `toml:"quote_denom" mapstructure:"quote_denom"`
+		BaseExponent  int64  `toml:"base_exponent" mapstructure:"base_exponent"`
+		QuoteExponent int64  `toml:"quote_exponent" mapstructure:"quote_exponent"`
+	}
+
+	// Query defines the REST API quoting a currency pair for the generic
+	// provider: the URL requested at most once per Interval, ex. "30s", and
+	// the JSONPath expressions selecting the Price and, optionally, the Volume
+	// of the pair in its response, ex. "$.data.last".
+	Query struct {
+		Base     string `toml:"base" mapstructure:"base"`
+		Quote    string `toml:"quote" mapstructure:"quote"`
+		URL      string `toml:"url" mapstructure:"url"`
+		Price    string `toml:"price" mapstructure:"price"`
+		Volume   string `toml:"volume" mapstructure:"volume"`
+		Interval string `toml:"interval" mapstructure:"interval"`
 	}
 )
 
@@ -83,8 +176,11 @@ func newDefaultHTTPClient() *http.Client {
 	return newHTTPClientWithTimeout(defaultTimeout)
 }
 
+// newHTTPClientWithTimeout returns a client using the transport shared by all
+// providers.
 func newHTTPClientWithTimeout(timeout time.Duration) *http.Client {
 	return &http.Client{
+		Transport:     sharedHTTPTransport(),
 		Timeout:       timeout,
 		CheckRedirect: preventRedirect,
 	}
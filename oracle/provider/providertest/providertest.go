@@ -0,0 +1,257 @@
+// Package providertest implements a conformance test suite for price
+// providers, checking the behavior the oracle relies on regardless of the
+// exchange: the mapping of the exchange symbols to currency pairs, idempotent
+// subscriptions, the pruning of stale candles and the errors returned for
+// missing prices.
+//
+// A provider runs the suite from its tests by describing how to create it and
+// how to push the messages of its exchange to it, e.g.:
+//
+//	func TestExampleProvider_Conformance(t *testing.T) {
+//		providertest.Run(t, providertest.Harness{
+//			Name: "example",
+//			New: func(t *testing.T, pairs ...types.CurrencyPair) providertest.Provider {
+//				p, err := NewExampleProvider(context.TODO(), zerolog.Nop(), Endpoint{}, pairs...)
+//				require.NoError(t, err)
+//				return p
+//			},
+//			PushTicker: func(t *testing.T, p providertest.Provider, ticker providertest.Ticker) {
+//				p.(*ExampleProvider).messageReceived(websocket.TextMessage, exampleTickerMsg(ticker))
+//			},
+//			PushCandle: func(t *testing.T, p providertest.Provider, candle providertest.Candle) {
+//				p.(*ExampleProvider).messageReceived(websocket.TextMessage, exampleCandleMsg(candle))
+//			},
+//		})
+//	}
+package providertest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+// staleCandleAge is the age of the candles expected to be pruned, above the
+// candle period of every provider.
+const staleCandleAge = time.Hour
+
+type (
+	// Provider defines the methods of a price provider checked by the suite.
+	// It is a subset of provider.Provider, declared here so that the tests of
+	// the provider package itself can run the suite.
+	Provider interface {
+		GetTickerPrices(ctx context.Context, pairs ...types.CurrencyPair) (map[string]types.TickerPrice, error)
+		GetCandlePrices(ctx context.Context, pairs ...types.CurrencyPair) (map[string][]types.CandlePrice, error)
+		SubscribeCurrencyPairs(ctx context.Context, pairs ...types.CurrencyPair) error
+	}
+
+	// Ticker defines a ticker of a pair pushed to the provider.
+	Ticker struct {
+		Pair   types.CurrencyPair
+		Price  string
+		Volume string
+	}
+
+	// Candle defines a one minute candle of a pair pushed to the provider,
+	// closing at TimeStamp, in unix milliseconds.
+	Candle struct {
+		Pair      types.CurrencyPair
+		Price     string
+		Volume    string
+		TimeStamp int64
+	}
+
+	// Harness defines how the suite creates and feeds the provider under
+	// test. The provider must not depend on the availability of its exchange,
+	// e.g. its websocket connection may fail in the background.
+	Harness struct {
+		// Name is the name of the provider, which its errors mention.
+		Name string
+
+		// Pairs are the pairs the provider is tested with, at least two.
+		// They default to ATOM/USDT and XPRT/USDT.
+		Pairs []types.CurrencyPair
+
+		// New returns a new provider subscribed to pairs.
+		New func(t *testing.T, pairs ...types.CurrencyPair) Provider
+
+		// PushTicker makes the provider receive the ticker the way its
+		// exchange sends it, e.g. by passing a websocket message to its
+		// handler.
+		PushTicker func(t *testing.T, p Provider, ticker Ticker)
+
+		// PushCandle makes the provider receive the candle the way its
+		// exchange sends it.
+		PushCandle func(t *testing.T, p Provider, candle Candle)
+	}
+)
+
+// Run runs the conformance test suite against the provider of the harness.
+func Run(t *testing.T, h Harness) {
+	t.Helper()
+
+	require.NotEmpty(t, h.Name, "the harness must name the provider")
+	require.NotNil(t, h.New, "the harness must create the provider")
+	require.NotNil(t, h.PushTicker, "the harness must push tickers")
+	require.NotNil(t, h.PushCandle, "the harness must push candles")
+	if len(h.Pairs) == 0 {
+		h.Pairs = []types.CurrencyPair{
+			{Base: "ATOM", Quote: "USDT"},
+			{Base: "XPRT", Quote: "USDT"},
+		}
+	}
+	require.GreaterOrEqual(t, len(h.Pairs), 2, "the harness must test at least two pairs")
+
+	t.Run("ticker_symbol_mapping", h.testTickerSymbolMapping)
+	t.Run("candle_symbol_mapping", h.testCandleSymbolMapping)
+	t.Run("ticker_update", h.testTickerUpdate)
+	t.Run("stale_candles_pruned", h.testStaleCandlesPruned)
+	t.Run("subscription_idempotency", h.testSubscriptionIdempotency)
+	t.Run("missing_ticker_error", h.testMissingTickerError)
+	t.Run("missing_candle_error", h.testMissingCandleError)
+}
+
+// testTickerSymbolMapping checks that the ticker of every pair is returned
+// keyed by the pair, whatever the symbol of the exchange.
+func (h Harness) testTickerSymbolMapping(t *testing.T) {
+	p := h.New(t, h.Pairs...)
+
+	tickers := make([]Ticker, len(h.Pairs))
+	for i, pair := range h.Pairs {
+		tickers[i] = Ticker{Pair: pair, Price: price(i), Volume: "1000.5"}
+		h.PushTicker(t, p, tickers[i])
+	}
+
+	prices, err := p.GetTickerPrices(context.Background(), h.Pairs...)
+	require.NoError(t, err)
+	require.Len(t, prices, len(h.Pairs))
+	for _, ticker := range tickers {
+		require.Contains(t, prices, ticker.Pair.String())
+		require.Equal(t, sdk.MustNewDecFromStr(ticker.Price), prices[ticker.Pair.String()].Price)
+		require.Equal(t, sdk.MustNewDecFromStr(ticker.Volume), prices[ticker.Pair.String()].Volume)
+	}
+}
+
+// testCandleSymbolMapping checks that the candles of every pair are returned
+// keyed by the pair, whatever the symbol of the exchange.
+func (h Harness) testCandleSymbolMapping(t *testing.T) {
+	p := h.New(t, h.Pairs...)
+
+	timeStamp := unixMilli(time.Now())
+	candles := make([]Candle, len(h.Pairs))
+	for i, pair := range h.Pairs {
+		candles[i] = Candle{Pair: pair, Price: price(i), Volume: "250.25", TimeStamp: timeStamp}
+		h.PushCandle(t, p, candles[i])
+	}
+
+	prices, err := p.GetCandlePrices(context.Background(), h.Pairs...)
+	require.NoError(t, err)
+	require.Len(t, prices, len(h.Pairs))
+	for _, candle := range candles {
+		require.Len(t, prices[candle.Pair.String()], 1)
+		require.Equal(t, sdk.MustNewDecFromStr(candle.Price), prices[candle.Pair.String()][0].Price)
+		require.Equal(t, sdk.MustNewDecFromStr(candle.Volume), prices[candle.Pair.String()][0].Volume)
+		require.Equal(t, candle.TimeStamp, prices[candle.Pair.String()][0].TimeStamp)
+	}
+}
+
+// testTickerUpdate checks that the price of a ticker replaces the previous one
+// of the pair. Its volume isn't checked, as providers deriving tickers from
+// trades add the volumes up.
+func (h Harness) testTickerUpdate(t *testing.T) {
+	p := h.New(t, h.Pairs...)
+	pair := h.Pairs[0]
+
+	h.PushTicker(t, p, Ticker{Pair: pair, Price: "10.5", Volume: "100"})
+	h.PushTicker(t, p, Ticker{Pair: pair, Price: "10.75", Volume: "120"})
+
+	prices, err := p.GetTickerPrices(context.Background(), pair)
+	require.NoError(t, err)
+	require.Equal(t, sdk.MustNewDecFromStr("10.75"), prices[pair.String()].Price)
+}
+
+// testStaleCandlesPruned checks that candles older than the candle period of
+// the provider are dropped once a new candle is received.
+func (h Harness) testStaleCandlesPruned(t *testing.T) {
+	p := h.New(t, h.Pairs...)
+	pair := h.Pairs[0]
+
+	now := time.Now()
+	stale := Candle{Pair: pair, Price: "9.5", Volume: "100", TimeStamp: unixMilli(now.Add(-staleCandleAge))}
+	fresh := Candle{Pair: pair, Price: "10.5", Volume: "100", TimeStamp: unixMilli(now)}
+	h.PushCandle(t, p, stale)
+	h.PushCandle(t, p, fresh)
+
+	prices, err := p.GetCandlePrices(context.Background(), pair)
+	require.NoError(t, err)
+	require.Len(t, prices[pair.String()], 1, "stale candles must be pruned")
+	require.Equal(t, fresh.TimeStamp, prices[pair.String()][0].TimeStamp)
+}
+
+// testSubscriptionIdempotency checks that subscribing to pairs the provider
+// is already subscribed to is a no-op, which doesn't even need the exchange to
+// be reachable.
+func (h Harness) testSubscriptionIdempotency(t *testing.T) {
+	p := h.New(t, h.Pairs...)
+	pair := h.Pairs[0]
+	h.PushTicker(t, p, Ticker{Pair: pair, Price: "10.5", Volume: "100"})
+
+	require.NoError(t, p.SubscribeCurrencyPairs(context.Background(), h.Pairs...))
+	require.NoError(t, p.SubscribeCurrencyPairs(context.Background(), pair))
+
+	prices, err := p.GetTickerPrices(context.Background(), pair)
+	require.NoError(t, err)
+	require.Equal(t, sdk.MustNewDecFromStr("10.5"), prices[pair.String()].Price)
+}
+
+// testMissingTickerError checks that requesting a pair without ticker fails
+// without partial results, with an error naming the provider and the pair.
+func (h Harness) testMissingTickerError(t *testing.T) {
+	p := h.New(t, h.Pairs...)
+	h.PushTicker(t, p, Ticker{Pair: h.Pairs[0], Price: "10.5", Volume: "100"})
+
+	missing := types.CurrencyPair{Base: "FOO", Quote: "BAR"}
+	prices, err := p.GetTickerPrices(context.Background(), h.Pairs[0], missing)
+	require.Error(t, err)
+	require.Nil(t, prices)
+	h.requireErrorNames(t, err, missing)
+}
+
+// testMissingCandleError checks that requesting a pair without candles fails
+// without partial results, with an error naming the provider and the pair.
+func (h Harness) testMissingCandleError(t *testing.T) {
+	p := h.New(t, h.Pairs...)
+	h.PushCandle(t, p, Candle{Pair: h.Pairs[0], Price: "10.5", Volume: "100", TimeStamp: unixMilli(time.Now())})
+
+	missing := types.CurrencyPair{Base: "FOO", Quote: "BAR"}
+	prices, err := p.GetCandlePrices(context.Background(), h.Pairs[0], missing)
+	require.Error(t, err)
+	require.Nil(t, prices)
+	h.requireErrorNames(t, err, missing)
+}
+
+// requireErrorNames requires err to mention the provider and both assets of
+// the pair, case-insensitively.
+func (h Harness) requireErrorNames(t *testing.T, err error, pair types.CurrencyPair) {
+	t.Helper()
+
+	msg := strings.ToUpper(err.Error())
+	require.Contains(t, msg, strings.ToUpper(h.Name), "the error must name the provider")
+	require.Contains(t, msg, pair.Base, "the error must name the pair")
+	require.Contains(t, msg, pair.Quote, "the error must name the pair")
+}
+
+// price returns a distinct price for the i-th pair.
+func price(i int) string {
+	return sdk.NewDecWithPrec(int64(1050+i*100), 2).String()
+}
+
+func unixMilli(t time.Time) int64 {
+	return t.Unix() * int64(time.Second/time.Millisecond)
+}
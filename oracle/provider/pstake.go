@@ -0,0 +1,311 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+const (
+	pstakeHostChainsMethod = "/pstake.liquidstakeibc.v1beta1.Query/HostChains"
+	pstakeStatesMethod     = "/pstake.liquidstake.v1beta1.Query/States"
+
+	// pstakeNativeDenom is the denom of XPRT, liquid staked by the
+	// liquidstake module rather than by the liquidstakeibc module.
+	pstakeNativeDenom = "uxprt"
+)
+
+var _ Provider = (*PStakeProvider)(nil)
+
+type (
+	// PStakeProvider defines an Oracle provider of the redemption rates of the
+	// pSTAKE liquid staking tokens of a Persistence node over gRPC, e.g. the
+	// price of stkATOM in ATOM, so that the stk assets can be priced without a
+	// liquid market: their pairs are quoted in their underlying asset, whose
+	// USD price converts them, see ConvertTickersToUSD.
+	//
+	// Every pool is identified by the QuoteDenom of the underlying asset: the
+	// C-value of its host chain in the liquidstakeibc module, or the mint rate
+	// of the liquidstake module for XPRT, i.e. the stk minted per underlying,
+	// whose inverse is the redemption rate. The rates have no traded volume,
+	// so the volumes are zero and candles are synthetic: one per request.
+	//
+	// REF: https://github.com/persistenceOne/pstake-native/tree/main/proto/pstake
+	PStakeProvider struct {
+		conn    *grpc.ClientConn
+		pools   map[string]Pool // CurrencyPair.String() => Pool
+		mtx     sync.Mutex
+		candles map[string][]types.CandlePrice // CurrencyPair.String() => candles
+	}
+
+	// pstakeHostChainsRequest defines the liquidstakeibc
+	// QueryHostChainsRequest, without pagination.
+	pstakeHostChainsRequest struct{}
+
+	// pstakeHostChainsResponse defines the liquidstakeibc
+	// QueryHostChainsResponse, of which only the host denom and C-value of
+	// every host chain are decoded.
+	pstakeHostChainsResponse struct {
+		HostChains []pstakeHostChain
+	}
+
+	pstakeHostChain struct {
+		HostDenom string
+		CValue    sdk.Dec
+	}
+
+	// pstakeStatesRequest defines the liquidstake QueryStatesRequest.
+	pstakeStatesRequest struct{}
+
+	// pstakeStatesResponse defines the liquidstake QueryStatesResponse, of
+	// which only the mint rate of the net amount state is decoded.
+	pstakeStatesResponse struct {
+		MintRate sdk.Dec
+	}
+)
+
+// NewPStakeProvider returns a pSTAKE provider querying the redemption rates
+// of the configured pools from the node at the endpoint gRPC address.
+func NewPStakeProvider(endpoint Endpoint) (*PStakeProvider, error) {
+	if len(endpoint.GRPC) == 0 {
+		return nil, fmt.Errorf("pstake requires the gRPC endpoint of a Persistence node")
+	}
+
+	conn, err := grpc.Dial(
+		endpoint.GRPC,
+		// the Cosmos SDK doesn't support any transport security mechanism
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(nodeCodec{})),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial Persistence gRPC service: %w", err)
+	}
+
+	return newPStakeProvider(conn, endpoint.Pools), nil
+}
+
+func newPStakeProvider(conn *grpc.ClientConn, pools []Pool) *PStakeProvider {
+	p := &PStakeProvider{
+		conn:    conn,
+		pools:   make(map[string]Pool, len(pools)),
+		candles: make(map[string][]types.CandlePrice, len(pools)),
+	}
+	for _, pool := range pools {
+		cp := types.CurrencyPair{Base: pool.Base, Quote: pool.Quote}
+		p.pools[cp.String()] = pool
+	}
+
+	return p
+}
+
+// SubscribeCurrencyPairs performs a no-op since the rates are queried on
+// every request.
+func (*PStakeProvider) SubscribeCurrencyPairs(context.Context, ...types.CurrencyPair) error {
+	return nil
+}
+
+// GetTickerPrices returns the redemption rates of the given pairs.
+func (p *PStakeProvider) GetTickerPrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
+	rates, err := p.redemptionRates(ctx, pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	tickerPrices := make(map[string]types.TickerPrice, len(rates))
+	for symbol, rate := range rates {
+		tickerPrices[symbol] = types.TickerPrice{Price: rate, Volume: sdk.ZeroDec()}
+	}
+
+	return tickerPrices, nil
+}
+
+// GetCandlePrices appends a candle with the redemption rate to the candles of
+// the given pairs and returns the candles within providerCandlePeriod.
+func (p *PStakeProvider) GetCandlePrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
+	rates, err := p.redemptionRates(ctx, pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	now := time.Now().UnixMilli()
+	staleTime := PastUnixTime(providerCandlePeriod)
+	candles := make(map[string][]types.CandlePrice, len(rates))
+	for symbol, rate := range rates {
+		p.candles[symbol] = append(p.candles[symbol], types.CandlePrice{
+			Price:     rate,
+			Volume:    sdk.ZeroDec(),
+			TimeStamp: now,
+		})
+
+		fresh := p.candles[symbol][:0]
+		for _, candle := range p.candles[symbol] {
+			if candle.TimeStamp > staleTime {
+				fresh = append(fresh, candle)
+			}
+		}
+		p.candles[symbol] = fresh
+
+		candles[symbol] = append([]types.CandlePrice{}, fresh...)
+	}
+
+	return candles, nil
+}
+
+// redemptionRates returns the price of the stk asset of each pair in its
+// underlying asset, querying the host chains and the liquidstake state at
+// most once.
+func (p *PStakeProvider) redemptionRates(
+	ctx context.Context,
+	pairs []types.CurrencyPair,
+) (map[string]sdk.Dec, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	var (
+		mintRates = make(map[string]sdk.Dec) // underlying denom => stk minted per underlying
+		rates     = make(map[string]sdk.Dec, len(pairs))
+	)
+	for _, cp := range pairs {
+		pool, ok := p.pools[cp.String()]
+		if !ok {
+			return nil, fmt.Errorf("no pSTAKE pool configured for %s", cp.String())
+		}
+
+		if _, ok := mintRates[pool.QuoteDenom]; !ok {
+			if err := p.queryMintRates(ctx, pool.QuoteDenom, mintRates); err != nil {
+				return nil, err
+			}
+		}
+		mintRate, ok := mintRates[pool.QuoteDenom]
+		if !ok {
+			return nil, fmt.Errorf("no pSTAKE host chain of denom %s", pool.QuoteDenom)
+		}
+		if mintRate.IsNil() || !mintRate.IsPositive() {
+			return nil, fmt.Errorf("invalid pSTAKE %s C-value", pool.QuoteDenom)
+		}
+
+		rates[cp.String()] = sdk.OneDec().Quo(mintRate)
+	}
+
+	return rates, nil
+}
+
+// queryMintRates adds the mint rate of XPRT, or those of every host chain, to
+// mintRates, depending on denom.
+func (p *PStakeProvider) queryMintRates(ctx context.Context, denom string, mintRates map[string]sdk.Dec) error {
+	if denom == pstakeNativeDenom {
+		var resp pstakeStatesResponse
+		if err := p.conn.Invoke(ctx, pstakeStatesMethod, &pstakeStatesRequest{}, &resp); err != nil {
+			return fmt.Errorf("failed to query pSTAKE liquidstake states: %w", err)
+		}
+		mintRates[denom] = resp.MintRate
+		return nil
+	}
+
+	var resp pstakeHostChainsResponse
+	if err := p.conn.Invoke(ctx, pstakeHostChainsMethod, &pstakeHostChainsRequest{}, &resp); err != nil {
+		return fmt.Errorf("failed to query pSTAKE host chains: %w", err)
+	}
+	for _, hostChain := range resp.HostChains {
+		mintRates[hostChain.HostDenom] = hostChain.CValue
+	}
+
+	return nil
+}
+
+func (*pstakeHostChainsRequest) Marshal() ([]byte, error) {
+	return nil, nil
+}
+
+func (*pstakeHostChainsRequest) Unmarshal([]byte) error {
+	return nil
+}
+
+func (m *pstakeHostChainsResponse) Marshal() ([]byte, error) {
+	var b []byte
+	for _, hostChain := range m.HostChains {
+		cValue, err := hostChain.CValue.Marshal()
+		if err != nil {
+			return nil, err
+		}
+
+		var c []byte
+		c = appendStringField(c, 4, hostChain.HostDenom)
+		c = appendStringField(c, 11, string(cValue))
+
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, c)
+	}
+	return b, nil
+}
+
+func (m *pstakeHostChainsResponse) Unmarshal(data []byte) error {
+	return unmarshalFields(data, func(num protowire.Number, _ uint64, bz []byte) error {
+		if num != 1 {
+			return nil
+		}
+
+		var hostChain pstakeHostChain
+		err := unmarshalFields(bz, func(num protowire.Number, _ uint64, bz []byte) error {
+			switch num {
+			case 4:
+				hostChain.HostDenom = string(bz)
+			case 11:
+				return hostChain.CValue.Unmarshal(bz)
+			}
+			return nil
+		})
+		m.HostChains = append(m.HostChains, hostChain)
+		return err
+	})
+}
+
+func (*pstakeStatesRequest) Marshal() ([]byte, error) {
+	return nil, nil
+}
+
+func (*pstakeStatesRequest) Unmarshal([]byte) error {
+	return nil
+}
+
+func (m *pstakeStatesResponse) Marshal() ([]byte, error) {
+	mintRate, err := m.MintRate.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	state := appendStringField(nil, 1, string(mintRate))
+	b := protowire.AppendTag(nil, 1, protowire.BytesType)
+	return protowire.AppendBytes(b, state), nil
+}
+
+func (m *pstakeStatesResponse) Unmarshal(data []byte) error {
+	return unmarshalFields(data, func(num protowire.Number, _ uint64, bz []byte) error {
+		if num != 1 {
+			return nil
+		}
+		return unmarshalFields(bz, func(num protowire.Number, _ uint64, bz []byte) error {
+			if num == 1 {
+				return m.MintRate.Unmarshal(bz)
+			}
+			return nil
+		})
+	})
+}
@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+// newTestPStakeNode serves the pSTAKE queries with the stkATOM C-value and
+// the stkXPRT mint rate.
+func newTestPStakeNode(t *testing.T) *grpc.ClientConn {
+	handler := func(_ interface{}, stream grpc.ServerStream) error {
+		method, _ := grpc.MethodFromServerStream(stream)
+
+		var raw rawNodeMessage
+		if err := stream.RecvMsg(&raw); err != nil {
+			return err
+		}
+
+		switch method {
+		case pstakeHostChainsMethod:
+			return stream.SendMsg(&pstakeHostChainsResponse{HostChains: []pstakeHostChain{
+				{HostDenom: "uosmo", CValue: sdk.MustNewDecFromStr("0.95")},
+				{HostDenom: "uatom", CValue: sdk.MustNewDecFromStr("0.8")},
+			}})
+
+		case pstakeStatesMethod:
+			return stream.SendMsg(&pstakeStatesResponse{MintRate: sdk.MustNewDecFromStr("0.5")})
+		}
+
+		t.Fatalf("unexpected method %s", method)
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer(grpc.ForceServerCodec(nodeCodec{}), grpc.UnknownServiceHandler(handler))
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial(
+		listener.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(nodeCodec{})),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func TestPStakeProvider(t *testing.T) {
+	var (
+		stkATOM = types.CurrencyPair{Base: "STKATOM", Quote: "ATOM"}
+		stkXPRT = types.CurrencyPair{Base: "STKXPRT", Quote: "XPRT"}
+		stkJUNO = types.CurrencyPair{Base: "STKJUNO", Quote: "JUNO"}
+	)
+	p := newPStakeProvider(newTestPStakeNode(t), []Pool{
+		{Base: "STKATOM", Quote: "ATOM", QuoteDenom: "uatom"},
+		{Base: "STKXPRT", Quote: "XPRT", QuoteDenom: "uxprt"},
+		{Base: "STKJUNO", Quote: "JUNO", QuoteDenom: "ujuno"},
+	})
+
+	t.Run("ticker", func(t *testing.T) {
+		prices, err := p.GetTickerPrices(context.Background(), stkATOM, stkXPRT)
+		require.NoError(t, err)
+		require.Equal(t, types.TickerPrice{Price: sdk.MustNewDecFromStr("1.25"), Volume: sdk.ZeroDec()},
+			prices["STKATOMATOM"])
+		require.Equal(t, types.TickerPrice{Price: sdk.NewDec(2), Volume: sdk.ZeroDec()}, prices["STKXPRTXPRT"])
+	})
+
+	t.Run("candles", func(t *testing.T) {
+		_, err := p.GetCandlePrices(context.Background(), stkATOM)
+		require.NoError(t, err)
+		candles, err := p.GetCandlePrices(context.Background(), stkATOM)
+		require.NoError(t, err)
+		require.Len(t, candles["STKATOMATOM"], 2)
+		require.Equal(t, sdk.MustNewDecFromStr("1.25"), candles["STKATOMATOM"][1].Price)
+	})
+
+	t.Run("unknown_host_chain", func(t *testing.T) {
+		_, err := p.GetTickerPrices(context.Background(), stkJUNO)
+		require.EqualError(t, err, "no pSTAKE host chain of denom ujuno")
+	})
+}
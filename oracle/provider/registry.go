@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/rs/zerolog"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+// Constructor returns a provider of the given pairs, configured by the
+// endpoint.
+type Constructor func(
+	ctx context.Context,
+	logger zerolog.Logger,
+	endpoint Endpoint,
+	pairs ...types.CurrencyPair,
+) (Provider, error)
+
+// registry holds the constructor of every provider built into the binary.
+// The on-chain providers, only querying nodes, indexers or subgraphs of the
+// chains, and the exec provider, running an executable of the operator, are
+// always registered, while the exchange and market data API
+// providers are left out of the binaries built with the lite tag, see
+// registry_cex.go, for the operators which don't open egress to those APIs.
+var registry = make(map[Name]Constructor)
+
+// Register registers the constructor of the provider name, which must be
+// registered once.
+func Register(name Name, constructor Constructor) {
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("provider %s registered twice", name))
+	}
+	registry[name] = constructor
+}
+
+// Lookup returns the constructor of the provider name, if registered.
+func Lookup(name Name) (Constructor, bool) {
+	constructor, ok := registry[name]
+	return constructor, ok
+}
+
+// Registered returns the names of the registered providers, sorted.
+func Registered() []Name {
+	names := make([]Name, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+
+	return names
+}
+
+// endpointOnly adapts the constructor of a provider only configured by its
+// endpoint.
+func endpointOnly(newProvider func(Endpoint) (Provider, error)) Constructor {
+	return func(_ context.Context, _ zerolog.Logger, endpoint Endpoint, _ ...types.CurrencyPair) (Provider, error) {
+		return newProvider(endpoint)
+	}
+}
+
+func init() {
+	Register(Osmosis, endpointOnly(func(endpoint Endpoint) (Provider, error) {
+		if len(endpoint.GRPC) > 0 {
+			return NewOsmosisNodeProvider(endpoint)
+		}
+		return NewOsmosisProvider(endpoint), nil
+	}))
+	Register(Dexter, endpointOnly(func(endpoint Endpoint) (Provider, error) {
+		return NewDexterProvider(endpoint)
+	}))
+	Register(UniswapV3, endpointOnly(func(endpoint Endpoint) (Provider, error) {
+		return NewUniswapProvider(endpoint), nil
+	}))
+	Register(Curve, endpointOnly(func(endpoint Endpoint) (Provider, error) {
+		return NewCurveProvider(endpoint), nil
+	}))
+	Register(PancakeSwap, endpointOnly(func(endpoint Endpoint) (Provider, error) {
+		return NewPancakeSwapProvider(endpoint), nil
+	}))
+	Register(Crescent, endpointOnly(func(endpoint Endpoint) (Provider, error) {
+		return NewCrescentProvider(endpoint), nil
+	}))
+	Register(Chainlink, endpointOnly(func(endpoint Endpoint) (Provider, error) {
+		return NewChainlinkProvider(endpoint), nil
+	}))
+	Register(PStake, endpointOnly(func(endpoint Endpoint) (Provider, error) {
+		return NewPStakeProvider(endpoint)
+	}))
+	for _, name := range []Name{Umee, Ojo, Kujira} {
+		name := name
+		Register(name, endpointOnly(func(endpoint Endpoint) (Provider, error) {
+			return NewInterchainOracleProvider(name, endpoint)
+		}))
+	}
+	Register(Exec, func(
+		ctx context.Context,
+		logger zerolog.Logger,
+		endpoint Endpoint,
+		_ ...types.CurrencyPair,
+	) (Provider, error) {
+		return NewExecProvider(ctx, logger, endpoint)
+	})
+	Register(Mock, endpointOnly(func(Endpoint) (Provider, error) {
+		return NewMockProvider("", nil)
+	}))
+}
@@ -0,0 +1,90 @@
+//go:build !lite
+
+package provider
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+// The exchange and market data API providers are left out of the binaries
+// built with the lite tag, e.g. `make build-lite`.
+func init() {
+	Register(Binance, func(
+		ctx context.Context, logger zerolog.Logger, endpoint Endpoint, pairs ...types.CurrencyPair,
+	) (Provider, error) {
+		return NewBinanceProvider(ctx, logger, endpoint, false, pairs...)
+	})
+	Register(BinanceUS, func(
+		ctx context.Context, logger zerolog.Logger, endpoint Endpoint, pairs ...types.CurrencyPair,
+	) (Provider, error) {
+		return NewBinanceProvider(ctx, logger, endpoint, true, pairs...)
+	})
+	Register(Kraken, func(
+		ctx context.Context, logger zerolog.Logger, endpoint Endpoint, pairs ...types.CurrencyPair,
+	) (Provider, error) {
+		return NewKrakenProvider(ctx, logger, endpoint, pairs...)
+	})
+	Register(Huobi, func(
+		ctx context.Context, logger zerolog.Logger, endpoint Endpoint, pairs ...types.CurrencyPair,
+	) (Provider, error) {
+		return NewHuobiProvider(ctx, logger, endpoint, pairs...)
+	})
+	Register(Coinbase, func(
+		ctx context.Context, logger zerolog.Logger, endpoint Endpoint, pairs ...types.CurrencyPair,
+	) (Provider, error) {
+		return NewCoinbaseProvider(ctx, logger, endpoint, pairs...)
+	})
+	Register(Crypto, func(
+		ctx context.Context, logger zerolog.Logger, endpoint Endpoint, pairs ...types.CurrencyPair,
+	) (Provider, error) {
+		return NewCryptoProvider(ctx, logger, endpoint, pairs...)
+	})
+	Register(Okx, func(
+		ctx context.Context, logger zerolog.Logger, endpoint Endpoint, pairs ...types.CurrencyPair,
+	) (Provider, error) {
+		return NewOkxProvider(ctx, logger, endpoint, pairs...)
+	})
+	Register(Bybit, func(
+		ctx context.Context, logger zerolog.Logger, endpoint Endpoint, pairs ...types.CurrencyPair,
+	) (Provider, error) {
+		return NewBybitProvider(ctx, logger, endpoint, pairs...)
+	})
+	Register(Mexc, func(
+		ctx context.Context, logger zerolog.Logger, endpoint Endpoint, pairs ...types.CurrencyPair,
+	) (Provider, error) {
+		return NewMexcProvider(ctx, logger, endpoint, pairs...)
+	})
+	Register(Gemini, func(
+		ctx context.Context, logger zerolog.Logger, endpoint Endpoint, pairs ...types.CurrencyPair,
+	) (Provider, error) {
+		return NewGeminiProvider(ctx, logger, endpoint, pairs...)
+	})
+	Register(Upbit, endpointOnly(func(endpoint Endpoint) (Provider, error) {
+		return NewUpbitProvider(endpoint), nil
+	}))
+	Register(CoinGecko, endpointOnly(func(endpoint Endpoint) (Provider, error) {
+		return NewCoinGeckoProvider(endpoint), nil
+	}))
+	Register(Kaiko, endpointOnly(func(endpoint Endpoint) (Provider, error) {
+		return NewKaikoProvider(endpoint)
+	}))
+	Register(CryptoCompare, endpointOnly(func(endpoint Endpoint) (Provider, error) {
+		return NewCryptoCompareProvider(endpoint), nil
+	}))
+	Register(ECB, endpointOnly(func(endpoint Endpoint) (Provider, error) {
+		return NewECBProvider(endpoint), nil
+	}))
+	Register(ExchangeRateHost, endpointOnly(func(endpoint Endpoint) (Provider, error) {
+		return NewExchangeRateHostProvider(endpoint)
+	}))
+	Register(MetalsAPI, endpointOnly(func(endpoint Endpoint) (Provider, error) {
+		return NewMetalsAPIProvider(endpoint)
+	}))
+	Register(Generic, endpointOnly(func(endpoint Endpoint) (Provider, error) {
+		return NewGenericProvider(endpoint)
+	}))
+}
@@ -0,0 +1,28 @@
+package provider
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	names := Registered()
+	require.True(t, sort.SliceIsSorted(names, func(i, j int) bool { return names[i] < names[j] }))
+	require.Contains(t, names, Osmosis)
+	require.Contains(t, names, Dexter)
+
+	newProvider, ok := Lookup(Crescent)
+	require.True(t, ok)
+	p, err := newProvider(context.Background(), zerolog.Nop(), Endpoint{})
+	require.NoError(t, err)
+	require.IsType(t, &CrescentProvider{}, p)
+
+	_, ok = Lookup(Name("unknown"))
+	require.False(t, ok)
+
+	require.Panics(t, func() { Register(Osmosis, nil) })
+}
@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// statusTimeout defines the timeout of exchange system status and other
+// auxiliary REST requests.
+const statusTimeout = 5 * time.Second
+
+// StatusChecker is implemented by providers whose exchange publishes its
+// system status, which allows to detect announced maintenance windows before
+// price requests start to time out.
+type StatusChecker interface {
+	// UnderMaintenance returns true if the exchange announced it is under
+	// maintenance and its prices can't be relied on.
+	UnderMaintenance(ctx context.Context) (bool, error)
+}
+
+// getJSON requests url with the given headers and decodes the JSON response
+// into v.
+func getJSON(ctx context.Context, url string, header http.Header, v interface{}) error {
+	return requestJSON(ctx, http.MethodGet, url, header, v)
+}
+
+// requestJSON sends a request with the given method and headers to url and
+// decodes the JSON response into v.
+func requestJSON(ctx context.Context, method, url string, header http.Header, v interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, statusTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := newDefaultHTTPClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := checkHTTPStatus(resp); err != nil {
+		return err
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response of %s: %w", url, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,313 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+const (
+	uniswapSubgraphURL = "https://api.thegraph.com/subgraphs/name/uniswap/uniswap-v3"
+
+	// uniswapVolumePeriod is the period the ticker volumes are summed over,
+	// from the hourly data of the pools.
+	uniswapVolumePeriod = 24 * time.Hour
+
+	uniswapPoolQuery = `query($pool: ID!, $since: Int!) {
+  pool(id: $pool) { token0 { id } token1 { id } token0Price token1Price }
+  poolHourDatas(
+    first: 24, orderBy: periodStartUnix, orderDirection: desc,
+    where: { pool: $pool, periodStartUnix_gte: $since }
+  ) { periodStartUnix token0Price token1Price volumeToken0 volumeToken1 }
+}`
+)
+
+var _ Provider = (*UniswapProvider)(nil)
+
+type (
+	// UniswapProvider defines an Oracle provider querying the Uniswap v3
+	// subgraph for the configured pools, e.g. of bridged ATOM. Every pool is
+	// identified by its Contract address and its BaseDenom and QuoteDenom are
+	// the addresses of the base and quote tokens. The subgraph prices are
+	// already scaled by the token decimals, so the pool exponents are unused.
+	//
+	// Ticker prices are the pool prices with the volume of the last 24 hours.
+	// The pools only have hourly data, so the candle of a pair is the hour in
+	// progress, closing at the time of the request.
+	//
+	// REF: https://docs.uniswap.org/api/subgraph/overview
+	UniswapProvider struct {
+		name    Name
+		label   string // name of the DEX in errors, e.g. "Uniswap"
+		baseURL string
+		client  *http.Client
+		pools   map[string]Pool // CurrencyPair.String() => Pool
+	}
+
+	uniswapRequest struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+
+	uniswapResponse struct {
+		Data struct {
+			Pool          *UniswapPool      `json:"pool"`
+			PoolHourDatas []UniswapHourData `json:"poolHourDatas"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	// UniswapPool defines the current state of a Uniswap v3 pool, where
+	// token0Price is the price of token1 in token0 and token1Price the price
+	// of token0 in token1.
+	UniswapPool struct {
+		Token0      UniswapToken `json:"token0"`
+		Token1      UniswapToken `json:"token1"`
+		Token0Price string       `json:"token0Price"`
+		Token1Price string       `json:"token1Price"`
+	}
+
+	// UniswapToken defines a token of a pool by its address.
+	UniswapToken struct {
+		ID string `json:"id"`
+	}
+
+	// UniswapHourData defines the prices at the end of an hour of a pool and
+	// the volume of both its tokens traded during the hour.
+	UniswapHourData struct {
+		PeriodStart  int64  `json:"periodStartUnix"`
+		Token0Price  string `json:"token0Price"`
+		Token1Price  string `json:"token1Price"`
+		VolumeToken0 string `json:"volumeToken0"`
+		VolumeToken1 string `json:"volumeToken1"`
+	}
+)
+
+// NewUniswapProvider returns a Uniswap v3 provider querying the subgraph at
+// the endpoint REST URL, or the hosted one, for the pools of the endpoint.
+func NewUniswapProvider(endpoint Endpoint) *UniswapProvider {
+	return newUniswapV3Provider(UniswapV3, "Uniswap", uniswapSubgraphURL, endpoint)
+}
+
+// newUniswapV3Provider returns a provider querying the subgraph of a Uniswap
+// v3 deployment, or fork, at the endpoint REST URL, or at baseURL, for the
+// pools of the endpoint.
+func newUniswapV3Provider(name Name, label, baseURL string, endpoint Endpoint) *UniswapProvider {
+	p := &UniswapProvider{
+		name:    name,
+		label:   label,
+		baseURL: baseURL,
+		client:  newDefaultHTTPClient(),
+		pools:   make(map[string]Pool, len(endpoint.Pools)),
+	}
+	if endpoint.Name == name && len(endpoint.Rest) > 0 {
+		p.baseURL = endpoint.Rest
+	}
+	for _, pool := range endpoint.Pools {
+		cp := types.CurrencyPair{Base: pool.Base, Quote: pool.Quote}
+		p.pools[cp.String()] = pool
+	}
+
+	return p
+}
+
+// SubscribeCurrencyPairs performs a no-op since the pools are queried on
+// every request.
+func (*UniswapProvider) SubscribeCurrencyPairs(context.Context, ...types.CurrencyPair) error {
+	return nil
+}
+
+// GetTickerPrices returns the prices of the pools of the given pairs, with
+// their volume over the last 24 hours.
+func (p *UniswapProvider) GetTickerPrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
+	tickerPrices := make(map[string]types.TickerPrice, len(pairs))
+	for _, cp := range pairs {
+		pool, resp, err := p.queryPool(ctx, cp)
+		if err != nil {
+			return nil, err
+		}
+
+		price, err := pool.price(resp.Data.Pool)
+		if err != nil {
+			return nil, err
+		}
+
+		volume := sdk.ZeroDec()
+		for _, hour := range resp.Data.PoolHourDatas {
+			hourVolume, err := pool.volume(resp.Data.Pool, hour)
+			if err != nil {
+				return nil, err
+			}
+			volume = volume.Add(hourVolume)
+		}
+
+		tickerPrices[cp.String()] = types.TickerPrice{Price: price, Volume: volume}
+	}
+
+	return tickerPrices, nil
+}
+
+// GetCandlePrices returns the candle of the hour in progress of the pools of
+// the given pairs, priced at the current pool price.
+func (p *UniswapProvider) GetCandlePrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
+	candles := make(map[string][]types.CandlePrice, len(pairs))
+	for _, cp := range pairs {
+		pool, resp, err := p.queryPool(ctx, cp)
+		if err != nil {
+			return nil, err
+		}
+
+		price, err := pool.price(resp.Data.Pool)
+		if err != nil {
+			return nil, err
+		}
+
+		// the hourly data is ordered from the latest hour
+		now := time.Now()
+		candlePrices := []types.CandlePrice{}
+		hours := resp.Data.PoolHourDatas
+		if len(hours) > 0 && hours[0].PeriodStart >= now.Truncate(time.Hour).Unix() {
+			volume, err := pool.volume(resp.Data.Pool, hours[0])
+			if err != nil {
+				return nil, err
+			}
+			candlePrices = append(candlePrices, types.CandlePrice{
+				Price:     price,
+				Volume:    volume,
+				TimeStamp: now.UnixMilli(),
+			})
+		}
+		candles[cp.String()] = candlePrices
+	}
+
+	return candles, nil
+}
+
+// queryPool returns the pool of the pair, with its current state and hourly
+// data over uniswapVolumePeriod.
+func (p *UniswapProvider) queryPool(ctx context.Context, cp types.CurrencyPair) (uniswapPool, uniswapResponse, error) {
+	pool, ok := p.pools[cp.String()]
+	if !ok {
+		return uniswapPool{}, uniswapResponse{}, fmt.Errorf("no %s pool configured for %s", p.label, cp.String())
+	}
+
+	req := uniswapRequest{
+		Query: uniswapPoolQuery,
+		Variables: map[string]interface{}{
+			"pool":  strings.ToLower(pool.Contract),
+			"since": time.Now().Add(-uniswapVolumePeriod).Unix(),
+		},
+	}
+	var resp uniswapResponse
+	if err := p.post(ctx, req, &resp); err != nil {
+		return uniswapPool{}, uniswapResponse{}, fmt.Errorf(
+			"%s failed to query pool %s: %w", strings.ToLower(p.label), pool.Contract, err,
+		)
+	}
+	if len(resp.Errors) > 0 {
+		return uniswapPool{}, uniswapResponse{}, fmt.Errorf(
+			"%s failed to query pool %s: %s", strings.ToLower(p.label), pool.Contract, resp.Errors[0].Message,
+		)
+	}
+	if resp.Data.Pool == nil {
+		return uniswapPool{}, uniswapResponse{}, fmt.Errorf("%s pool %s not found", strings.ToLower(p.label), pool.Contract)
+	}
+	if !uniswapPool(pool).hasBaseToken(resp.Data.Pool) {
+		return uniswapPool{}, uniswapResponse{}, fmt.Errorf(
+			"%s pool %s has no token %s", strings.ToLower(p.label), pool.Contract, pool.BaseDenom,
+		)
+	}
+
+	return uniswapPool(pool), resp, nil
+}
+
+// post sends the GraphQL request to the subgraph, bounded by ctx, and decodes
+// the JSON response into resp.
+func (p *UniswapProvider) post(ctx context.Context, uniswapReq uniswapRequest, resp interface{}) error {
+	body, err := json.Marshal(uniswapReq)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+
+	if err := checkHTTPStatus(httpResp); err != nil {
+		return err
+	}
+
+	bz, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s response body: %w", p.name, err)
+	}
+	capturePayload(p.name, bz)
+
+	return json.Unmarshal(bz, resp)
+}
+
+// uniswapPool maps the tokens of a Uniswap pool to the pair it quotes.
+type uniswapPool Pool
+
+// isBaseToken0 returns whether the base of the pair is token0 of the pool.
+func (pool uniswapPool) isBaseToken0(state *UniswapPool) bool {
+	return strings.EqualFold(state.Token0.ID, pool.BaseDenom)
+}
+
+// hasBaseToken returns whether the base of the pair is a token of the pool.
+func (pool uniswapPool) hasBaseToken(state *UniswapPool) bool {
+	return strings.EqualFold(state.Token0.ID, pool.BaseDenom) || strings.EqualFold(state.Token1.ID, pool.BaseDenom)
+}
+
+// price returns the price of the base token in quote token.
+func (pool uniswapPool) price(state *UniswapPool) (sdk.Dec, error) {
+	if pool.isBaseToken0(state) {
+		return uniswapStrToDec(state.Token1Price)
+	}
+	return uniswapStrToDec(state.Token0Price)
+}
+
+// volume returns the volume of the base token traded during the hour.
+func (pool uniswapPool) volume(state *UniswapPool, hour UniswapHourData) (sdk.Dec, error) {
+	if pool.isBaseToken0(state) {
+		return uniswapStrToDec(hour.VolumeToken0)
+	}
+	return uniswapStrToDec(hour.VolumeToken1)
+}
+
+// uniswapStrToDec converts a subgraph BigDecimal, which may have more decimals
+// than sdk.Dec or an exponent, to a decimal.
+func uniswapStrToDec(s string) (sdk.Dec, error) {
+	f, ok := new(big.Float).SetPrec(256).SetString(s) //nolint:gomnd //precision of the parsed decimals
+	if !ok {
+		return sdk.Dec{}, fmt.Errorf("invalid uniswap decimal: %s", s)
+	}
+
+	return sdk.NewDecFromStr(f.Text('f', sdk.Precision))
+}
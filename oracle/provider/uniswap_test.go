@@ -0,0 +1,102 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+const (
+	testUniswapPool = "0x1234567890ABCDEF1234567890ABCDEF12345678"
+	testUniswapATOM = "0x8d983cb9388eac77af0474fa441c4815500cb7bb"
+	testUniswapUSDC = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+)
+
+func newTestUniswapProvider(t *testing.T, response string) *UniswapProvider {
+	p := NewUniswapProvider(Endpoint{
+		Name: UniswapV3,
+		Pools: []Pool{{
+			Base:       "ATOM",
+			Quote:      "USDC",
+			Contract:   testUniswapPool,
+			BaseDenom:  testUniswapATOM,
+			QuoteDenom: testUniswapUSDC,
+		}},
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		require.Equal(t, http.MethodPost, req.Method)
+
+		var uniswapReq uniswapRequest
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&uniswapReq))
+		require.Equal(t, "0x1234567890abcdef1234567890abcdef12345678", uniswapReq.Variables["pool"])
+
+		fmt.Fprint(rw, response)
+	}))
+	t.Cleanup(server.Close)
+
+	p.client = server.Client()
+	p.baseURL = server.URL
+	return p
+}
+
+func TestUniswapProvider(t *testing.T) {
+	hour := time.Now().Truncate(time.Hour).Unix()
+	// token1 is ATOM, so its price is token0Price, in USDC
+	p := newTestUniswapProvider(t, fmt.Sprintf(`{"data":{
+		"pool":{
+			"token0":{"id":"%s"},"token1":{"id":"%s"},
+			"token0Price":"10.4000000000000000000123","token1Price":"0.0961538461538461538461"
+		},
+		"poolHourDatas":[
+			{"periodStartUnix":%d,"volumeToken0":"5000","volumeToken1":"480.5"},
+			{"periodStartUnix":%d,"volumeToken0":"10000","volumeToken1":"1.5e3"}
+		]
+	}}`, testUniswapUSDC, testUniswapATOM, hour, hour-3600))
+	pair := types.CurrencyPair{Base: "ATOM", Quote: "USDC"}
+
+	t.Run("ticker", func(t *testing.T) {
+		prices, err := p.GetTickerPrices(context.Background(), pair)
+		require.NoError(t, err)
+		require.Equal(t, sdk.MustNewDecFromStr("10.4"), prices["ATOMUSDC"].Price)
+		require.Equal(t, sdk.MustNewDecFromStr("1980.5"), prices["ATOMUSDC"].Volume)
+	})
+
+	t.Run("candle_is_hour_in_progress", func(t *testing.T) {
+		candles, err := p.GetCandlePrices(context.Background(), pair)
+		require.NoError(t, err)
+		require.Len(t, candles["ATOMUSDC"], 1)
+		require.Equal(t, sdk.MustNewDecFromStr("10.4"), candles["ATOMUSDC"][0].Price)
+		require.Equal(t, sdk.MustNewDecFromStr("480.5"), candles["ATOMUSDC"][0].Volume)
+	})
+
+	t.Run("unconfigured_pool", func(t *testing.T) {
+		_, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "OSMO", Quote: "USDC"})
+		require.EqualError(t, err, "no Uniswap pool configured for OSMOUSDC")
+	})
+}
+
+func TestUniswapProviderErrors(t *testing.T) {
+	pair := types.CurrencyPair{Base: "ATOM", Quote: "USDC"}
+
+	p := newTestUniswapProvider(t, `{"errors":[{"message":"indexing error"}]}`)
+	_, err := p.GetTickerPrices(context.Background(), pair)
+	require.EqualError(t, err, "uniswap failed to query pool "+testUniswapPool+": indexing error")
+
+	p = newTestUniswapProvider(t, `{"data":{"pool":null,"poolHourDatas":[]}}`)
+	_, err = p.GetTickerPrices(context.Background(), pair)
+	require.EqualError(t, err, "uniswap pool "+testUniswapPool+" not found")
+
+	p = newTestUniswapProvider(t, `{"data":{"pool":{"token0":{"id":"0x1"},"token1":{"id":"0x2"}},"poolHourDatas":[]}}`)
+	_, err = p.GetTickerPrices(context.Background(), pair)
+	require.EqualError(t, err, "uniswap pool "+testUniswapPool+" has no token "+testUniswapATOM)
+}
@@ -0,0 +1,290 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+const (
+	upbitRestURL         = "https://api.upbit.com"
+	upbitTickerEndpoint  = "/v1/ticker"
+	upbitCandleEndpoint  = "/v1/candles/minutes/1"
+	upbitMarketsEndpoint = "/v1/market/all"
+	upbitCandleTimeFmt   = "2006-01-02T15:04:05"
+
+	// upbitUSDMarket is the market the KRW/USD rate is derived from, as
+	// Upbit doesn't list fiat pairs: the inverse of the KRW price of USDT.
+	upbitUSDMarket = "KRW-USDT"
+)
+
+// upbitCandleCount is the number of one minute candles requested per pair,
+// covering the candle period of the providers.
+var upbitCandleCount = int(providerCandlePeriod / time.Minute)
+
+var _ Provider = (*UpbitProvider)(nil)
+
+type (
+	// UpbitProvider defines an Oracle provider implemented by the Upbit public
+	// API, whose markets are mostly quoted in KRW, e.g. ATOM/KRW. Those prices
+	// are converted to USD with a KRW/USD feed, which Upbit can serve as well
+	// from the inverse of its USDT/KRW market, taking USDT as USD.
+	//
+	// REF: https://global-docs.upbit.com/reference/list-tickers
+	UpbitProvider struct {
+		baseURL string
+		client  *http.Client
+	}
+
+	// UpbitTicker defines the response structure of an Upbit ticker.
+	UpbitTicker struct {
+		Market      string  `json:"market"`               // Market ex.: KRW-ATOM
+		Price       float64 `json:"trade_price"`          // Last price ex.: 14850
+		Volume      float64 `json:"acc_trade_volume_24h"` // Volume of the base over 24h
+		QuoteVolume float64 `json:"acc_trade_price_24h"`  // Volume of the quote over 24h
+	}
+
+	// UpbitCandle defines the response structure of an Upbit one minute
+	// candle.
+	UpbitCandle struct {
+		Market      string  `json:"market"`                  // Market ex.: KRW-ATOM
+		Start       string  `json:"candle_date_time_utc"`    // Start time ex.: 2023-03-14T06:46:00
+		Close       float64 `json:"trade_price"`             // Price at close, or last price while open
+		Volume      float64 `json:"candle_acc_trade_volume"` // Volume of the base during period
+		QuoteVolume float64 `json:"candle_acc_trade_price"`  // Volume of the quote during period
+	}
+
+	// UpbitMarket defines a market listed by Upbit.
+	UpbitMarket struct {
+		Market string `json:"market"` // Market ex.: KRW-ATOM
+	}
+)
+
+func NewUpbitProvider(endpoint Endpoint) *UpbitProvider {
+	if endpoint.Name == Upbit {
+		return &UpbitProvider{
+			baseURL: endpoint.Rest,
+			client:  newDefaultHTTPClient(),
+		}
+	}
+	return &UpbitProvider{
+		baseURL: upbitRestURL,
+		client:  newDefaultHTTPClient(),
+	}
+}
+
+// SubscribeCurrencyPairs performs a no-op since the prices are requested on
+// every tick.
+func (UpbitProvider) SubscribeCurrencyPairs(context.Context, ...types.CurrencyPair) error {
+	return nil
+}
+
+// GetTickerPrices returns the tickerPrices based on the provided pairs, with a
+// single request.
+func (p UpbitProvider) GetTickerPrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string]types.TickerPrice, error) {
+	markets := make([]string, len(pairs))
+	for i, cp := range pairs {
+		markets[i] = currencyPairToUpbitMarket(cp)
+	}
+
+	var tickersResp []UpbitTicker
+	query := url.Values{"markets": {strings.Join(markets, ",")}}
+	if err := p.get(ctx, upbitTickerEndpoint, query, &tickersResp); err != nil {
+		return nil, fmt.Errorf("upbit failed to get ticker prices: %w", err)
+	}
+
+	tickers := make(map[string]UpbitTicker, len(tickersResp))
+	for _, ticker := range tickersResp {
+		tickers[ticker.Market] = ticker
+	}
+
+	tickerPrices := make(map[string]types.TickerPrice, len(pairs))
+	for _, cp := range pairs {
+		ticker, ok := tickers[currencyPairToUpbitMarket(cp)]
+		if !ok {
+			return nil, fmt.Errorf("upbit failed to get ticker price for %s", cp.String())
+		}
+		tickerPrice, err := ticker.toTickerPrice(isUpbitUSDPair(cp))
+		if err != nil {
+			return nil, err
+		}
+		tickerPrices[cp.String()] = tickerPrice
+	}
+
+	return tickerPrices, nil
+}
+
+// GetCandlePrices returns the candlePrices based on the provided pairs, with
+// a request per pair.
+func (p UpbitProvider) GetCandlePrices(
+	ctx context.Context,
+	pairs ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
+	candles := make(map[string][]types.CandlePrice, len(pairs))
+	for _, cp := range pairs {
+		var candlesResp []UpbitCandle
+		query := url.Values{
+			"market": {currencyPairToUpbitMarket(cp)},
+			"count":  {strconv.Itoa(upbitCandleCount)},
+		}
+		if err := p.get(ctx, upbitCandleEndpoint, query, &candlesResp); err != nil {
+			return nil, fmt.Errorf("upbit failed to get candle prices for %s: %w", cp.String(), err)
+		}
+
+		staleTime := PastUnixTime(providerCandlePeriod)
+
+		candlePrices := []types.CandlePrice{}
+		for _, candle := range candlesResp {
+			candlePrice, err := candle.toCandlePrice(isUpbitUSDPair(cp))
+			if err != nil {
+				return nil, err
+			}
+			if staleTime >= candlePrice.TimeStamp {
+				continue
+			}
+			candlePrices = append(candlePrices, candlePrice)
+		}
+		candles[cp.String()] = candlePrices
+	}
+
+	return candles, nil
+}
+
+// GetAvailablePairs returns all pairs to which the provider can subscribe,
+// including KRW/USD.
+// ex.: map["ATOMKRW" => {}, "KRWUSD" => {}].
+func (p UpbitProvider) GetAvailablePairs() (map[string]struct{}, error) {
+	var marketsResp []UpbitMarket
+	if err := p.get(context.Background(), upbitMarketsEndpoint, nil, &marketsResp); err != nil {
+		return nil, err
+	}
+
+	availablePairs := make(map[string]struct{}, len(marketsResp))
+	for _, market := range marketsResp {
+		if market.Market == upbitUSDMarket {
+			availablePairs[upbitUSDPair.String()] = struct{}{}
+		}
+
+		quote, base, ok := strings.Cut(market.Market, "-")
+		if !ok {
+			continue
+		}
+		cp := types.CurrencyPair{Base: base, Quote: quote}
+		availablePairs[cp.String()] = struct{}{}
+	}
+
+	return availablePairs, nil
+}
+
+// get requests the endpoint with the query, bounded by ctx, and decodes the
+// JSON response into resp.
+func (p UpbitProvider) get(ctx context.Context, endpoint string, query url.Values, resp interface{}) error {
+	reqURL := p.baseURL + endpoint
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return err
+	}
+
+	httpResp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+
+	if err := checkHTTPStatus(httpResp); err != nil {
+		return err
+	}
+
+	bz, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read upbit response body: %w", err)
+	}
+	capturePayload(Upbit, bz)
+
+	return json.Unmarshal(bz, resp)
+}
+
+// toTickerPrice returns the ticker price of the market, or of KRW in USD when
+// inverse, whose volume is then the KRW volume of the USDT market.
+func (ticker UpbitTicker) toTickerPrice(inverse bool) (types.TickerPrice, error) {
+	if !inverse {
+		return types.TickerPrice{
+			Price:  floatToDec(ticker.Price),
+			Volume: floatToDec(ticker.Volume),
+		}, nil
+	}
+
+	price, err := invertUpbitPrice(ticker.Price, ticker.Market)
+	if err != nil {
+		return types.TickerPrice{}, err
+	}
+	return types.TickerPrice{
+		Price:  price,
+		Volume: floatToDec(ticker.QuoteVolume),
+	}, nil
+}
+
+// toCandlePrice returns the candle price of the market, or of KRW in USD when
+// inverse, closing a minute after its start.
+func (candle UpbitCandle) toCandlePrice(inverse bool) (types.CandlePrice, error) {
+	start, err := time.Parse(upbitCandleTimeFmt, candle.Start)
+	if err != nil {
+		return types.CandlePrice{}, fmt.Errorf("failed to parse upbit candle time (%s): %w", candle.Start, err)
+	}
+
+	candlePrice := types.CandlePrice{
+		Price:     floatToDec(candle.Close),
+		Volume:    floatToDec(candle.Volume),
+		TimeStamp: start.Add(time.Minute).UnixMilli(),
+	}
+	if inverse {
+		if candlePrice.Price, err = invertUpbitPrice(candle.Close, candle.Market); err != nil {
+			return types.CandlePrice{}, err
+		}
+		candlePrice.Volume = floatToDec(candle.QuoteVolume)
+	}
+
+	return candlePrice, nil
+}
+
+// invertUpbitPrice returns the inverse of the price of the market.
+func invertUpbitPrice(price float64, market string) (sdk.Dec, error) {
+	if price <= 0 {
+		return sdk.Dec{}, fmt.Errorf("invalid upbit price (%f) for %s", price, market)
+	}
+	return sdk.OneDec().Quo(floatToDec(price)), nil
+}
+
+// upbitUSDPair is the KRW/USD pair derived from upbitUSDMarket.
+var upbitUSDPair = types.CurrencyPair{Base: "KRW", Quote: "USD"}
+
+// isUpbitUSDPair returns whether cp is the KRW/USD pair.
+func isUpbitUSDPair(cp types.CurrencyPair) bool {
+	return strings.EqualFold(cp.Base, upbitUSDPair.Base) && strings.EqualFold(cp.Quote, upbitUSDPair.Quote)
+}
+
+// currencyPairToUpbitMarket returns the market of a pair, quote first.
+// ex.: ATOM/KRW => KRW-ATOM, KRW/USD => KRW-USDT.
+func currencyPairToUpbitMarket(cp types.CurrencyPair) string {
+	if isUpbitUSDPair(cp) {
+		return upbitUSDMarket
+	}
+	return strings.ToUpper(cp.Quote + "-" + cp.Base)
+}
@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+func TestUpbitProvider_GetTickerPrices(t *testing.T) {
+	p := NewUpbitProvider(Endpoint{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != upbitTickerEndpoint {
+			http.NotFound(rw, req)
+			return
+		}
+		fmt.Fprint(rw, `[
+			{"market":"KRW-ATOM","trade_price":39907,"acc_trade_volume_24h":1000.5,"acc_trade_price_24h":39927453.5},
+			{"market":"KRW-USDT","trade_price":1250,"acc_trade_volume_24h":2000,"acc_trade_price_24h":2500000}
+		]`)
+	}))
+	defer server.Close()
+
+	p.client = server.Client()
+	p.baseURL = server.URL
+
+	t.Run("valid_request_multi_ticker", func(t *testing.T) {
+		prices, err := p.GetTickerPrices(
+			context.Background(),
+			types.CurrencyPair{Base: "ATOM", Quote: "KRW"},
+			types.CurrencyPair{Base: "KRW", Quote: "USD"},
+		)
+		require.NoError(t, err)
+		require.Len(t, prices, 2)
+		require.Equal(t, sdk.MustNewDecFromStr("39907"), prices["ATOMKRW"].Price)
+		require.Equal(t, sdk.MustNewDecFromStr("1000.5"), prices["ATOMKRW"].Volume)
+		require.Equal(t, sdk.MustNewDecFromStr("0.0008"), prices["KRWUSD"].Price)
+		require.Equal(t, sdk.MustNewDecFromStr("2500000"), prices["KRWUSD"].Volume)
+	})
+
+	t.Run("invalid_request_invalid_ticker", func(t *testing.T) {
+		prices, err := p.GetTickerPrices(context.Background(), types.CurrencyPair{Base: "FOO", Quote: "BAR"})
+		require.EqualError(t, err, "upbit failed to get ticker price for FOOBAR")
+		require.Nil(t, prices)
+	})
+}
+
+func TestUpbitProvider_GetCandlePrices(t *testing.T) {
+	p := NewUpbitProvider(Endpoint{})
+
+	start := time.Now().UTC().Truncate(time.Minute)
+	stale := start.Add(-time.Hour)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != upbitCandleEndpoint || req.URL.Query().Get("count") != "10" {
+			http.NotFound(rw, req)
+			return
+		}
+		market := req.URL.Query().Get("market")
+		fmt.Fprintf(rw, `[
+			{"market":%q,"candle_date_time_utc":%q,"trade_price":1250,
+			"candle_acc_trade_volume":12.5,"candle_acc_trade_price":15625},
+			{"market":%q,"candle_date_time_utc":%q,"trade_price":1000,
+			"candle_acc_trade_volume":1,"candle_acc_trade_price":1000}
+		]`, market, start.Format(upbitCandleTimeFmt), market, stale.Format(upbitCandleTimeFmt))
+	}))
+	defer server.Close()
+
+	p.client = server.Client()
+	p.baseURL = server.URL
+
+	candles, err := p.GetCandlePrices(
+		context.Background(),
+		types.CurrencyPair{Base: "ATOM", Quote: "KRW"},
+		types.CurrencyPair{Base: "KRW", Quote: "USD"},
+	)
+	require.NoError(t, err)
+
+	require.Len(t, candles["ATOMKRW"], 1)
+	require.Equal(t, sdk.MustNewDecFromStr("1250"), candles["ATOMKRW"][0].Price)
+	require.Equal(t, sdk.MustNewDecFromStr("12.5"), candles["ATOMKRW"][0].Volume)
+	require.Equal(t, start.Add(time.Minute).UnixMilli(), candles["ATOMKRW"][0].TimeStamp)
+
+	require.Len(t, candles["KRWUSD"], 1)
+	require.Equal(t, sdk.MustNewDecFromStr("0.0008"), candles["KRWUSD"][0].Price)
+	require.Equal(t, sdk.MustNewDecFromStr("15625"), candles["KRWUSD"][0].Volume)
+
+	server.Close()
+	_, err = p.GetCandlePrices(context.Background(), types.CurrencyPair{Base: "ATOM", Quote: "KRW"})
+	require.ErrorContains(t, err, "upbit failed to get candle prices for ATOMKRW")
+}
+
+func TestUpbitProvider_GetAvailablePairs(t *testing.T) {
+	p := NewUpbitProvider(Endpoint{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		require.Equal(t, upbitMarketsEndpoint, req.URL.Path)
+		fmt.Fprint(rw, `[{"market":"KRW-ATOM"},{"market":"BTC-ATOM"},{"market":"KRW-USDT"}]`)
+	}))
+	defer server.Close()
+
+	p.client = server.Client()
+	p.baseURL = server.URL
+
+	pairs, err := p.GetAvailablePairs()
+	require.NoError(t, err)
+	require.Equal(t, map[string]struct{}{
+		"ATOMKRW": {},
+		"ATOMBTC": {},
+		"USDTKRW": {},
+		"KRWUSD":  {},
+	}, pairs)
+}
+
+func TestCurrencyPairToUpbitMarket(t *testing.T) {
+	require.Equal(t, "KRW-ATOM", currencyPairToUpbitMarket(types.CurrencyPair{Base: "atom", Quote: "krw"}))
+	require.Equal(t, "KRW-USDT", currencyPairToUpbitMarket(types.CurrencyPair{Base: "KRW", Quote: "USD"}))
+}
@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// tradeVolumeBuckets is the number of hourly buckets the volume of the trades
+// is summed over, for a rolling 24h volume.
+const tradeVolumeBuckets = 24
+
+// tradeVolume sums the volume of the trades of a pair in hourly buckets, for
+// the providers deriving their tickers from trades, as their exchange doesn't
+// push 24h tickers.
+type tradeVolume struct {
+	hours   [tradeVolumeBuckets]int64
+	volumes [tradeVolumeBuckets]sdk.Dec
+}
+
+// add adds the volume of a trade at timeStamp, in unix milliseconds.
+func (v *tradeVolume) add(timeStamp int64, volume sdk.Dec) {
+	hour := timeStamp / int64(time.Hour/time.Millisecond)
+	i := hour % tradeVolumeBuckets
+	if v.hours[i] != hour || v.volumes[i].IsNil() {
+		v.hours[i] = hour
+		v.volumes[i] = sdk.ZeroDec()
+	}
+	v.volumes[i] = v.volumes[i].Add(volume)
+}
+
+// sum returns the volume of the trades of the 24 hours up to now.
+func (v *tradeVolume) sum(now time.Time) sdk.Dec {
+	sum := sdk.ZeroDec()
+	if v == nil {
+		return sum
+	}
+
+	hour := now.UnixMilli() / int64(time.Hour/time.Millisecond)
+	for i, bucketHour := range v.hours {
+		if hour-bucketHour < tradeVolumeBuckets && !v.volumes[i].IsNil() {
+			sum = sum.Add(v.volumes[i])
+		}
+	}
+	return sum
+}
@@ -0,0 +1,26 @@
+package provider
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTradeVolume(t *testing.T) {
+	now := time.Now()
+	v := &tradeVolume{}
+
+	v.add(now.Add(-25*time.Hour).UnixMilli(), sdk.NewDec(5))
+	v.add(now.Add(-2*time.Hour).UnixMilli(), sdk.NewDec(3))
+	v.add(now.UnixMilli(), sdk.NewDec(2))
+	v.add(now.UnixMilli(), sdk.NewDec(1))
+	require.Equal(t, sdk.NewDec(6), v.sum(now))
+
+	// the bucket of a day earlier is reused
+	v.add(now.Add(24*time.Hour).UnixMilli(), sdk.NewDec(4))
+	require.Equal(t, sdk.NewDec(4), v.sum(now.Add(24*time.Hour)))
+
+	require.Equal(t, sdk.ZeroDec(), (*tradeVolume)(nil).sum(now))
+}
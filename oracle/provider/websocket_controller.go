@@ -8,6 +8,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/armon/go-metrics"
+	"github.com/cosmos/cosmos-sdk/telemetry"
 	"github.com/gorilla/websocket"
 	"github.com/rs/zerolog"
 
@@ -17,11 +19,15 @@ import (
 const (
 	defaultReadNewWSMessage   = 50 * time.Millisecond
 	defaultMaxConnectionTime  = time.Hour * 23 // should be < 24h
+	defaultMaxSilence         = 5 * time.Minute
+	disabledMaxSilence        = time.Duration(0)
 	defaultPingDuration       = 15 * time.Second
 	disabledPingDuration      = time.Duration(0)
 	startingReconnectDuration = 5 * time.Second
 )
 
+var metricKeyWebsocketHandleLatency = []string{"websocket", "handle", "latency"}
+
 type (
 	MessageHandler func(int, []byte)
 
@@ -29,6 +35,7 @@ type (
 	// that manages reconnecting, subscribing, and receiving messages.
 	WebsocketController struct {
 		parentCtx           context.Context
+		stopFunc            context.CancelFunc
 		websocketCtx        context.Context
 		websocketCancelFunc context.CancelFunc
 		providerName        Name
@@ -37,16 +44,21 @@ type (
 		messageHandler      MessageHandler
 		pingInterval        time.Duration
 		pingMessageType     uint
+		maxConnectionTime   time.Duration
+		maxSilence          time.Duration
 		logger              zerolog.Logger
 
 		mtx              sync.Mutex
 		client           *websocket.Conn
 		reconnectCounter uint
+		lastMessageAt    time.Time
+		interrupted      bool
 	}
 )
 
 // NewWebsocketController does nothing except initialize a new WebsocketController
-// and provider a reminder for what fields need to be passed in.
+// and provider a reminder for what fields need to be passed in. It doesn't
+// connect until started, and stops once parentCtx is done or it is stopped.
 func NewWebsocketController(
 	parentCtx context.Context,
 	providerName Name,
@@ -57,18 +69,39 @@ func NewWebsocketController(
 	pingMessageType uint,
 	logger zerolog.Logger,
 ) *WebsocketController {
+	ctx, stop := context.WithCancel(parentCtx)
 	return &WebsocketController{
-		parentCtx:        parentCtx,
-		providerName:     providerName,
-		url:              url,
-		subscriptionMsgs: subscriptionMsgs,
-		messageHandler:   messageHandler,
-		pingInterval:     pingDuration,
-		pingMessageType:  pingMessageType,
-		logger:           logger,
+		parentCtx:         ctx,
+		stopFunc:          stop,
+		providerName:      providerName,
+		url:               url,
+		subscriptionMsgs:  subscriptionMsgs,
+		messageHandler:    messageHandler,
+		pingInterval:      pingDuration,
+		pingMessageType:   pingMessageType,
+		maxConnectionTime: defaultMaxConnectionTime,
+		maxSilence:        defaultMaxSilence,
+		logger:            logger,
 	}
 }
 
+// SetMaxConnectionTime sets the time after which the websocket is proactively
+// reconnected and subscribed again, ahead of the cutoff of the exchange, e.g.
+// Binance disconnects every connection after 24 hours. It must be set before
+// the controller is started.
+func (wsc *WebsocketController) SetMaxConnectionTime(maxConnectionTime time.Duration) {
+	wsc.maxConnectionTime = maxConnectionTime
+}
+
+// SetMaxSilence sets the time without any message after which the data flow
+// of the subscriptions is deemed lost, e.g. once the exchange reset them or
+// stopped sending its heartbeats, and the websocket is reconnected and
+// subscribed again. It must be set before the controller is started, and
+// disabledMaxSilence disables the check.
+func (wsc *WebsocketController) SetMaxSilence(maxSilence time.Duration) {
+	wsc.maxSilence = maxSilence
+}
+
 // Start will continuously loop and attempt connecting to the websocket
 // until a successful connection is made. It then starts the ping
 // service and read listener in new go routines and sends subscription
@@ -91,6 +124,7 @@ func (wsc *WebsocketController) Start() {
 
 		go wsc.readWebSocket()
 		go wsc.pingLoop()
+		go wsc.watchConnection()
 
 		if err := wsc.subscribe(wsc.subscriptionMsgs); err != nil {
 			wsc.logger.Err(err).Send()
@@ -101,21 +135,31 @@ func (wsc *WebsocketController) Start() {
 	}
 }
 
+// Stop stops connecting to the websocket and closes it, if connected. A
+// stopped controller can't be started again.
+func (wsc *WebsocketController) Stop() {
+	wsc.stopFunc()
+}
+
 // connect dials the websocket and sets the client to the established connection.
+// The host is resolved again on every connection, see raceDialer.
 func (wsc *WebsocketController) connect() error {
 	wsc.mtx.Lock()
 	defer wsc.mtx.Unlock()
 
 	wsc.logger.Debug().Msg("connecting to websocket")
-	conn, resp, err := websocket.DefaultDialer.Dial(wsc.url.String(), nil)
+	conn, resp, err := websocketDialer.DialContext(wsc.parentCtx, wsc.url.String(), nil)
 	if err != nil {
 		return fmt.Errorf(types.ErrWebsocketDial, wsc.providerName, err)
 	}
 	defer resp.Body.Close()
+	wsc.logger.Debug().Str("remote_addr", conn.RemoteAddr().String()).Msg("connected to websocket")
 	wsc.client = conn
 	wsc.websocketCtx, wsc.websocketCancelFunc = context.WithCancel(wsc.parentCtx)
 	wsc.client.SetPingHandler(wsc.pingHandler)
 	wsc.reconnectCounter = 0
+	wsc.lastMessageAt = time.Now()
+	wsc.interrupted = false
 	return nil
 }
 
@@ -140,13 +184,22 @@ func (wsc *WebsocketController) subscribe(msgs []interface{}) error {
 	return nil
 }
 
-// AddSubscriptionMsgs immediately sends the new subscription messages and
-// adds them to the subscriptionMsgs array if successful.
+// AddSubscriptionMsgs immediately sends the new subscription messages, if
+// connected, and adds them to the subscriptionMsgs array if successful, so
+// that a controller not started yet subscribes to them once connected.
 func (wsc *WebsocketController) AddSubscriptionMsgs(msgs []interface{}) error {
-	if err := wsc.subscribe(msgs); err != nil {
-		return err
+	wsc.mtx.Lock()
+	connected := wsc.client != nil
+	wsc.mtx.Unlock()
+
+	if connected {
+		if err := wsc.subscribe(msgs); err != nil {
+			return err
+		}
 	}
+	wsc.mtx.Lock()
 	wsc.subscriptionMsgs = append(wsc.subscriptionMsgs, msgs...)
+	wsc.mtx.Unlock()
 	return nil
 }
 
@@ -205,12 +258,7 @@ func (wsc *WebsocketController) ping() error {
 // readWebSocket continuously reads from the websocket and relays messages
 // to the passed in messageHandler. On websocket error this function
 // terminates and starts the reconnect process.
-// Some providers (Binance) will only allow a valid connection for 24 hours
-// so we manually disconnect and reconnect every 23 hours (defaultMaxConnectionTime).
 func (wsc *WebsocketController) readWebSocket() {
-	reconnectTicker := time.NewTicker(defaultMaxConnectionTime)
-	defer reconnectTicker.Stop()
-
 	for {
 		select {
 		case <-wsc.websocketCtx.Done():
@@ -219,18 +267,86 @@ func (wsc *WebsocketController) readWebSocket() {
 		case <-time.After(defaultReadNewWSMessage):
 			messageType, bz, err := wsc.client.ReadMessage()
 			if err != nil {
-				wsc.logger.Err(fmt.Errorf(types.ErrWebsocketRead, wsc.providerName, err)).Send()
+				if !wsc.isInterrupted() {
+					wsc.logger.Err(fmt.Errorf(types.ErrWebsocketRead, wsc.providerName, err)).Send()
+				}
 				wsc.reconnect()
 				return
 			}
 			wsc.readSuccess(messageType, bz)
-		case <-reconnectTicker.C:
-			wsc.reconnect()
+		}
+	}
+}
+
+// watchConnection interrupts the websocket, to be reconnected and subscribed
+// again, once connected for maxConnectionTime, since some exchanges only
+// allow a connection for a limited time (Binance for 24 hours), or once no
+// message was received for maxSilence while subscribed, since some exchanges
+// reset their subscriptions or stop sending their heartbeats without closing
+// the connection (Kraken). The data flow of the new subscriptions is checked
+// the same way.
+func (wsc *WebsocketController) watchConnection() {
+	cutoffTimer := time.NewTimer(wsc.maxConnectionTime)
+	defer cutoffTimer.Stop()
+
+	var silenceCheck <-chan time.Time
+	if wsc.maxSilence != disabledMaxSilence {
+		silenceTicker := time.NewTicker(wsc.maxSilence / 4) //nolint: gomnd // checks 4 times per period
+		defer silenceTicker.Stop()
+		silenceCheck = silenceTicker.C
+	}
+
+	for {
+		select {
+		case <-wsc.websocketCtx.Done():
+			return
+		case <-cutoffTimer.C:
+			wsc.interrupt("reconnecting websocket ahead of the connection cutoff")
 			return
+		case <-silenceCheck:
+			if silence := wsc.silence(); silence > wsc.maxSilence {
+				wsc.logger.Warn().Dur("silence", silence).Msg("no data received on websocket")
+				wsc.interrupt("reconnecting websocket to restore its subscriptions")
+				return
+			}
 		}
 	}
 }
 
+// silence returns the time since the last message, or since connected, if
+// subscribed to anything.
+func (wsc *WebsocketController) silence() time.Duration {
+	wsc.mtx.Lock()
+	defer wsc.mtx.Unlock()
+
+	if len(wsc.subscriptionMsgs) == 0 {
+		return 0
+	}
+	return time.Since(wsc.lastMessageAt)
+}
+
+// interrupt interrupts the read of the websocket, which then reconnects.
+func (wsc *WebsocketController) interrupt(reason string) {
+	wsc.mtx.Lock()
+	defer wsc.mtx.Unlock()
+
+	if wsc.client == nil {
+		return
+	}
+	wsc.logger.Info().Msg(reason)
+	wsc.interrupted = true
+	if err := wsc.client.SetReadDeadline(time.Now()); err != nil {
+		wsc.logger.Err(err).Msg("failed to interrupt websocket")
+	}
+}
+
+func (wsc *WebsocketController) isInterrupted() bool {
+	wsc.mtx.Lock()
+	defer wsc.mtx.Unlock()
+
+	return wsc.interrupted
+}
+
 func (wsc *WebsocketController) readSuccess(messageType int, bz []byte) {
 	if len(bz) == 0 {
 		return
@@ -238,6 +354,19 @@ func (wsc *WebsocketController) readSuccess(messageType int, bz []byte) {
 	if string(bz) == "pong" {
 		return
 	}
+	wsc.mtx.Lock()
+	wsc.lastMessageAt = time.Now()
+	wsc.mtx.Unlock()
+	// binary messages are compressed and captured by the provider instead
+	if messageType == websocket.TextMessage {
+		capturePayload(wsc.providerName, bz)
+	}
+
+	// the messages are read one at a time, so a slow handler backs up the
+	// messages of the exchange, which then lag behind
+	defer metrics.MeasureSinceWithLabels(metricKeyWebsocketHandleLatency, time.Now(), []metrics.Label{
+		telemetry.NewLabel("provider", wsc.providerName.String()),
+	})
 	wsc.messageHandler(messageType, bz)
 }
 
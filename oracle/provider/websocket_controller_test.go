@@ -0,0 +1,162 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebsocketControllerLifecycle(t *testing.T) {
+	var (
+		upgrader      websocket.Upgrader
+		subscriptions = make(chan string, 2)
+		closed        = make(chan struct{})
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		conn, err := upgrader.Upgrade(rw, req, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		for {
+			_, bz, err := conn.ReadMessage()
+			if err != nil {
+				close(closed)
+				return
+			}
+			subscriptions <- strings.TrimSpace(string(bz))
+		}
+	}))
+	defer server.Close()
+
+	wsURL, err := url.Parse(strings.Replace(server.URL, "http", "ws", 1))
+	require.NoError(t, err)
+
+	wsc := NewWebsocketController(
+		context.Background(),
+		Mock,
+		*wsURL,
+		[]interface{}{"ticker"},
+		func(int, []byte) {},
+		disabledPingDuration,
+		websocket.PingMessage,
+		zerolog.Nop(),
+	)
+
+	// subscriptions added before the controller is started are sent once
+	// connected
+	require.NoError(t, wsc.AddSubscriptionMsgs([]interface{}{"candle"}))
+	select {
+	case msg := <-subscriptions:
+		t.Fatalf("unexpected subscription before start: %s", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	go wsc.Start()
+	require.Equal(t, `"ticker"`, <-subscriptions)
+	require.Equal(t, `"candle"`, <-subscriptions)
+
+	wsc.Stop()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("websocket not closed once stopped")
+	}
+}
+
+func TestWebsocketControllerReconnect(t *testing.T) {
+	testCases := []struct {
+		name              string
+		maxConnectionTime time.Duration
+		maxSilence        time.Duration
+		streaming         bool
+	}{
+		{
+			// the connection is cut off ahead of the exchange while data flows
+			name:              "max connection time",
+			maxConnectionTime: 300 * time.Millisecond,
+			maxSilence:        disabledMaxSilence,
+			streaming:         true,
+		},
+		{
+			// the exchange reset the subscriptions without closing the
+			// connection
+			name:              "max silence",
+			maxConnectionTime: defaultMaxConnectionTime,
+			maxSilence:        200 * time.Millisecond,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				upgrader      websocket.Upgrader
+				subscriptions = make(chan string, 10)
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				conn, err := upgrader.Upgrade(rw, req, nil)
+				require.NoError(t, err)
+				defer conn.Close()
+
+				_, bz, err := conn.ReadMessage()
+				if err != nil {
+					return
+				}
+				subscriptions <- strings.TrimSpace(string(bz))
+
+				for {
+					// only the first update is sent without streaming
+					if err := conn.WriteMessage(websocket.TextMessage, []byte("update")); err != nil {
+						return
+					}
+					if !tc.streaming {
+						break
+					}
+					time.Sleep(20 * time.Millisecond)
+				}
+				for {
+					if _, _, err := conn.ReadMessage(); err != nil {
+						return
+					}
+				}
+			}))
+			defer server.Close()
+
+			wsURL, err := url.Parse(strings.Replace(server.URL, "http", "ws", 1))
+			require.NoError(t, err)
+
+			wsc := NewWebsocketController(
+				context.Background(),
+				Mock,
+				*wsURL,
+				[]interface{}{"ticker"},
+				func(int, []byte) {},
+				disabledPingDuration,
+				websocket.PingMessage,
+				zerolog.Nop(),
+			)
+			wsc.SetMaxConnectionTime(tc.maxConnectionTime)
+			wsc.SetMaxSilence(tc.maxSilence)
+			go wsc.Start()
+			defer wsc.Stop()
+
+			// the websocket is reconnected and subscribed again
+			for i := 0; i < 2; i++ {
+				select {
+				case msg := <-subscriptions:
+					require.Equal(t, `"ticker"`, msg)
+				case <-time.After(10 * time.Second):
+					t.Fatal("websocket not subscribed again")
+				}
+			}
+		})
+	}
+}
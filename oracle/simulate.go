@@ -0,0 +1,193 @@
+package oracle
+
+import (
+	"fmt"
+	"sort"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+type (
+	// SimulationRecord defines the prices the feeder computed at a block
+	// height and the exchange rates the chain settled on at that height, e.g.
+	// exported from the feeder logs and the x/oracle queries of an archive
+	// node. Prices are keyed by base.
+	SimulationRecord struct {
+		Height int64              `json:"height"`
+		Feed   map[string]sdk.Dec `json:"feed"`
+		Median map[string]sdk.Dec `json:"median"`
+	}
+
+	// SimulationParams defines the hypothetical x/oracle params a history is
+	// replayed with. Votes deviating from the median by more than half of
+	// RewardBand, relative to the median, miss. When SlashWindow is set, the
+	// windows of that many blocks in which less than MinValidPerWindow of the
+	// votes were valid are reported as slashable.
+	SimulationParams struct {
+		VotePeriod        int64   `json:"vote_period"`
+		RewardBand        sdk.Dec `json:"reward_band"`
+		SlashWindow       int64   `json:"slash_window,omitempty"`
+		MinValidPerWindow sdk.Dec `json:"min_valid_per_window"`
+	}
+
+	// SimulationReport defines the outcome of the votes of the feeder over a
+	// history under the simulated params. A vote period is rewarded when
+	// every asset was voted within the band and missed otherwise, as counted
+	// by the x/oracle miss counter.
+	SimulationReport struct {
+		Params           SimulationParams  `json:"params"`
+		Periods          int               `json:"periods"`
+		Rewarded         int               `json:"rewarded"`
+		Missed           int               `json:"missed"`
+		MissRate         sdk.Dec           `json:"miss_rate"`
+		Assets           []AssetSimulation `json:"assets"`
+		Windows          int               `json:"windows,omitempty"`
+		SlashableWindows int               `json:"slashable_windows,omitempty"`
+	}
+
+	// AssetSimulation defines the outcome of the votes of an asset. Missing
+	// counts the periods the feeder had no price for it and OutOfBand those
+	// its price was outside the band. MaxDeviation is the largest relative
+	// deviation of a vote from the median.
+	AssetSimulation struct {
+		Asset        string  `json:"asset"`
+		Votes        int     `json:"votes"`
+		InBand       int     `json:"in_band"`
+		OutOfBand    int     `json:"out_of_band"`
+		Missing      int     `json:"missing"`
+		MaxDeviation sdk.Dec `json:"max_deviation"`
+	}
+)
+
+// Simulate replays the history under the params. The votes are tallied at
+// the last block of every vote period covered by the history: the median is
+// the one recorded in that period, and the vote of the feeder the prices it
+// recorded a vote period earlier, when they were pre-voted, so that longer
+// vote periods show how stale the votes get. Periods without a recorded
+// median are skipped.
+func Simulate(records []SimulationRecord, params SimulationParams) (SimulationReport, error) {
+	switch {
+	case params.VotePeriod <= 0:
+		return SimulationReport{}, fmt.Errorf("vote period must be positive")
+	case params.RewardBand.IsNil() || params.RewardBand.IsNegative():
+		return SimulationReport{}, fmt.Errorf("reward band must be a non-negative number")
+	case params.SlashWindow < 0:
+		return SimulationReport{}, fmt.Errorf("slash window must not be negative")
+	case params.SlashWindow > 0 && params.MinValidPerWindow.IsNil():
+		return SimulationReport{}, fmt.Errorf("min valid per window is required with a slash window")
+	}
+	if len(records) == 0 {
+		return SimulationReport{}, fmt.Errorf("no records to simulate")
+	}
+
+	sorted := append([]SimulationRecord{}, records...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Height < sorted[j].Height
+	})
+
+	var (
+		report  = SimulationReport{Params: params, MissRate: sdk.ZeroDec()}
+		assets  = make(map[string]*AssetSimulation)
+		windows = make(map[int64][2]int)        // window => periods, valid periods
+		spread  = params.RewardBand.QuoInt64(2) //nolint:gomnd //half of the band on either side
+		first   = sorted[0].Height
+		last    = sorted[len(sorted)-1].Height
+	)
+
+	// the first tally height whose pre-vote is covered by the history
+	tally := (first/params.VotePeriod+2)*params.VotePeriod - 1
+	for ; tally <= last; tally += params.VotePeriod {
+		median, ok := recordAt(sorted, tally, params.VotePeriod)
+		if !ok || len(median.Median) == 0 {
+			continue
+		}
+		vote, _ := recordAt(sorted, tally-params.VotePeriod, params.VotePeriod)
+
+		valid := true
+		for base, medianPrice := range median.Median {
+			asset, ok := assets[base]
+			if !ok {
+				asset = &AssetSimulation{Asset: base, MaxDeviation: sdk.ZeroDec()}
+				assets[base] = asset
+			}
+			asset.Votes++
+
+			price, ok := vote.Feed[base]
+			if !ok || price.IsNil() || !price.IsPositive() || !medianPrice.IsPositive() {
+				asset.Missing++
+				valid = false
+				continue
+			}
+
+			deviation := price.Sub(medianPrice).Abs().Quo(medianPrice)
+			if deviation.GT(asset.MaxDeviation) {
+				asset.MaxDeviation = deviation
+			}
+			if deviation.GT(spread) {
+				asset.OutOfBand++
+				valid = false
+				continue
+			}
+			asset.InBand++
+		}
+
+		report.Periods++
+		windowKey := windowOf(tally, params.SlashWindow)
+		window := windows[windowKey]
+		window[0]++
+		if valid {
+			report.Rewarded++
+			window[1]++
+		} else {
+			report.Missed++
+		}
+		windows[windowKey] = window
+	}
+
+	if report.Periods == 0 {
+		return SimulationReport{}, fmt.Errorf("history covers no vote period of %d blocks", params.VotePeriod)
+	}
+	report.MissRate = sdk.NewDec(int64(report.Missed)).QuoInt64(int64(report.Periods))
+
+	if params.SlashWindow > 0 {
+		report.Windows = len(windows)
+		for _, window := range windows {
+			validRate := sdk.NewDec(int64(window[1])).QuoInt64(int64(window[0]))
+			if validRate.LT(params.MinValidPerWindow) {
+				report.SlashableWindows++
+			}
+		}
+	}
+
+	report.Assets = make([]AssetSimulation, 0, len(assets))
+	for _, asset := range assets {
+		report.Assets = append(report.Assets, *asset)
+	}
+	sort.Slice(report.Assets, func(i, j int) bool {
+		return report.Assets[i].Asset < report.Assets[j].Asset
+	})
+
+	return report, nil
+}
+
+// recordAt returns the latest of the records sorted by height at or before
+// height, if it is within the vote period ending there.
+func recordAt(sorted []SimulationRecord, height, votePeriod int64) (SimulationRecord, bool) {
+	i := sort.Search(len(sorted), func(i int) bool {
+		return sorted[i].Height > height
+	})
+	if i == 0 || sorted[i-1].Height <= height-votePeriod {
+		return SimulationRecord{}, false
+	}
+
+	return sorted[i-1], true
+}
+
+// windowOf returns the slash window of the height, or 0 if there are none.
+func windowOf(height, slashWindow int64) int64 {
+	if slashWindow <= 0 {
+		return 0
+	}
+
+	return height / slashWindow
+}
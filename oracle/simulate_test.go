@@ -0,0 +1,63 @@
+package oracle_test
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle"
+)
+
+func TestSimulate(t *testing.T) {
+	record := func(height int64, feed, median string) oracle.SimulationRecord {
+		r := oracle.SimulationRecord{Height: height, Feed: map[string]sdk.Dec{}, Median: map[string]sdk.Dec{}}
+		if len(feed) > 0 {
+			r.Feed["ATOM"] = sdk.MustNewDecFromStr(feed)
+		}
+		r.Median["ATOM"] = sdk.MustNewDecFromStr(median)
+		return r
+	}
+	// the price drifts by 1% every 5 blocks, which the feed follows
+	var records []oracle.SimulationRecord
+	for height, price := int64(0), 10.0; height < 40; height++ {
+		if height%5 == 0 && height > 0 {
+			price *= 1.01
+		}
+		p := sdk.NewDecWithPrec(int64(price*1e6), 6)
+		feed := p.String()
+		if height == 24 {
+			feed = ""
+		}
+		records = append(records, record(height, feed, p.String()))
+	}
+
+	// the votes of 5 block periods lag the median by 1%
+	report, err := oracle.Simulate(records, oracle.SimulationParams{
+		VotePeriod: 5,
+		RewardBand: sdk.MustNewDecFromStr("0.03"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, 7, report.Periods)
+	require.Equal(t, 6, report.Rewarded)
+	require.Equal(t, 1, report.Missed)
+	require.Equal(t, 1, report.Assets[0].Missing)
+	require.InDelta(t, 0.0099, report.Assets[0].MaxDeviation.MustFloat64(), 1e-3)
+
+	// those of 10 block periods lag it by 2%, beyond a 3% band
+	report, err = oracle.Simulate(records, oracle.SimulationParams{
+		VotePeriod:        10,
+		RewardBand:        sdk.MustNewDecFromStr("0.03"),
+		SlashWindow:       20,
+		MinValidPerWindow: sdk.MustNewDecFromStr("0.5"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, report.Periods)
+	require.Equal(t, 0, report.Rewarded)
+	require.Equal(t, 3, report.Assets[0].OutOfBand)
+	require.Equal(t, 2, report.Windows)
+	require.Equal(t, 2, report.SlashableWindows)
+
+	_, err = oracle.Simulate(records, oracle.SimulationParams{VotePeriod: 100, RewardBand: sdk.OneDec()})
+	require.EqualError(t, err, "history covers no vote period of 100 blocks")
+}
@@ -0,0 +1,158 @@
+package oracle
+
+import (
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	"github.com/rs/zerolog"
+)
+
+// SLOWindows defines the sliding windows the vote success ratio is computed
+// over, keyed by their name.
+var SLOWindows = map[string]time.Duration{
+	"1h":  time.Hour,
+	"24h": 24 * time.Hour,
+}
+
+type (
+	// SLOTracker tracks the outcome of the vote of each vote period over a
+	// sliding window and reports the share of vote periods whose vote was
+	// included. A warning is logged once the ratio of any window drops below
+	// the target.
+	SLOTracker struct {
+		logger zerolog.Logger
+		target float64
+		now    func() time.Time
+
+		mtx      sync.Mutex
+		outcomes []voteOutcome
+		breached map[string]bool
+	}
+
+	// SLOReport defines the vote success ratios per window and whether they
+	// are below the target. A zero target disables alerting.
+	SLOReport struct {
+		Target  float64
+		Windows map[string]SLOWindowReport
+	}
+
+	// SLOWindowReport defines the vote success ratio over a single window.
+	// The ratio is 1 if no vote period ended within the window.
+	SLOWindowReport struct {
+		Periods  int
+		Success  int
+		Ratio    float64
+		Breached bool
+	}
+
+	voteOutcome struct {
+		period  uint64
+		success bool
+		time    time.Time
+	}
+)
+
+// NewSLOTracker returns a tracker alerting when the vote success ratio drops
+// below target, which must be within [0, 1].
+func NewSLOTracker(logger zerolog.Logger, target float64) *SLOTracker {
+	return &SLOTracker{
+		logger:   logger.With().Str("module", "slo").Logger(),
+		target:   target,
+		now:      time.Now,
+		breached: make(map[string]bool, len(SLOWindows)),
+	}
+}
+
+// Record records whether the vote of the given vote period was included. A
+// vote period can be recorded several times, e.g. when a failed vote is
+// retried, in which case the last outcome wins.
+func (t *SLOTracker) Record(period uint64, success bool) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	now := t.now()
+	t.prune(now)
+
+	outcome := voteOutcome{period: period, success: success, time: now}
+	if n := len(t.outcomes); n > 0 && t.outcomes[n-1].period == period {
+		t.outcomes[n-1] = outcome
+	} else {
+		t.outcomes = append(t.outcomes, outcome)
+	}
+
+	t.report(now)
+}
+
+// Report returns the vote success ratio of every window.
+func (t *SLOTracker) Report() SLOReport {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	return t.report(t.now())
+}
+
+// report computes the success ratios, emits them as gauges and logs a warning
+// for every window which newly dropped below the target.
+func (t *SLOTracker) report(now time.Time) SLOReport {
+	report := SLOReport{
+		Target:  t.target,
+		Windows: make(map[string]SLOWindowReport, len(SLOWindows)),
+	}
+
+	for name, window := range SLOWindows {
+		var wr SLOWindowReport
+		for _, outcome := range t.outcomes {
+			if now.Sub(outcome.time) > window {
+				continue
+			}
+
+			wr.Periods++
+			if outcome.success {
+				wr.Success++
+			}
+		}
+
+		wr.Ratio = 1
+		if wr.Periods > 0 {
+			wr.Ratio = float64(wr.Success) / float64(wr.Periods)
+		}
+		wr.Breached = t.target > 0 && wr.Ratio < t.target
+		report.Windows[name] = wr
+
+		telemetry.SetGaugeWithLabels(
+			[]string{"vote", "success_ratio"},
+			float32(wr.Ratio),
+			[]metrics.Label{telemetry.NewLabel("window", name)},
+		)
+
+		if wr.Breached && !t.breached[name] {
+			t.logger.Warn().
+				Str("window", name).
+				Float64("ratio", wr.Ratio).
+				Float64("target", t.target).
+				Int("periods", wr.Periods).
+				Msg("vote success ratio dropped below SLO")
+		}
+		t.breached[name] = wr.Breached
+	}
+
+	return report
+}
+
+// prune drops the outcomes which fell out of the largest window.
+func (t *SLOTracker) prune(now time.Time) {
+	var maxWindow time.Duration
+	for _, window := range SLOWindows {
+		if window > maxWindow {
+			maxWindow = window
+		}
+	}
+
+	i := 0
+	for i < len(t.outcomes) && now.Sub(t.outcomes[i].time) > maxWindow {
+		i++
+	}
+	t.outcomes = t.outcomes[i:]
+}
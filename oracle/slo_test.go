@@ -0,0 +1,42 @@
+package oracle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSLOTracker(t *testing.T) {
+	now := time.Now()
+	tracker := NewSLOTracker(zerolog.Nop(), 0.9)
+	tracker.now = func() time.Time { return now }
+
+	// no vote period ended yet
+	report := tracker.Report()
+	require.Equal(t, 1.0, report.Windows["1h"].Ratio)
+	require.False(t, report.Windows["1h"].Breached)
+
+	// a failed vote retried within the same period counts once
+	tracker.Record(1, false)
+	tracker.Record(1, true)
+	tracker.Record(2, true)
+	report = tracker.Report()
+	require.Equal(t, SLOWindowReport{Periods: 2, Success: 2, Ratio: 1}, report.Windows["1h"])
+
+	// periods older than 1h only count towards the 24h window
+	now = now.Add(2 * time.Hour)
+	tracker.Record(3, false)
+	report = tracker.Report()
+	require.Equal(t, SLOWindowReport{Periods: 1, Ratio: 0, Breached: true}, report.Windows["1h"])
+	require.Equal(t, 3, report.Windows["24h"].Periods)
+	require.True(t, report.Windows["24h"].Breached)
+
+	// periods older than 24h are dropped
+	now = now.Add(25 * time.Hour)
+	tracker.Record(4, true)
+	report = tracker.Report()
+	require.Equal(t, SLOWindowReport{Periods: 1, Success: 1, Ratio: 1}, report.Windows["24h"])
+	require.Len(t, tracker.outcomes, 1)
+}
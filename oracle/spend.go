@@ -0,0 +1,216 @@
+package oracle
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+
+	"github.com/persistenceOne/oracle-feeder/pkg/jsonfile"
+)
+
+// SpendFileName is the name of the file, relative to the data directory, the
+// gas and fees spent by the feeder account are persisted to.
+const SpendFileName = "spend.json"
+
+// SpendWindows defines the sliding windows the spend of the feeder account is
+// totalled over, keyed by their name.
+var SpendWindows = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+}
+
+type (
+	// SpendTracker tracks the gas used and fees paid by the transactions of
+	// the feeder account included in a block, cumulatively and over sliding
+	// windows. A warning is logged once the fees of a window exceed its
+	// budget. When created with a non-empty path the spend is persisted on
+	// every transaction and restored on start.
+	SpendTracker struct {
+		logger  zerolog.Logger
+		budgets map[string]sdk.Coins
+		path    string
+		now     func() time.Time
+
+		mtx      sync.Mutex
+		values   SpendValues
+		breached map[string]bool
+	}
+
+	// SpendValues defines the persisted spend: the cumulative totals and the
+	// transactions within the largest window.
+	SpendValues struct {
+		Txs     uint64        `json:"txs"`
+		GasUsed uint64        `json:"gas_used"`
+		Fees    sdk.Coins     `json:"fees"`
+		Recent  []SpendRecord `json:"recent"`
+	}
+
+	// SpendRecord defines the spend of a single transaction.
+	SpendRecord struct {
+		Time    time.Time `json:"time"`
+		GasUsed int64     `json:"gas_used"`
+		Fees    sdk.Coins `json:"fees"`
+	}
+
+	// SpendReport defines the cumulative spend and the spend per window.
+	SpendReport struct {
+		Txs     uint64
+		GasUsed uint64
+		Fees    sdk.Coins
+		Windows map[string]SpendWindowReport
+	}
+
+	// SpendWindowReport defines the spend over a single window. Exceeded is
+	// set when the window has a budget and its fees exceed it in any denom.
+	SpendWindowReport struct {
+		Txs      int
+		GasUsed  int64
+		Fees     sdk.Coins
+		Budget   sdk.Coins
+		Exceeded bool
+	}
+)
+
+// NewSpendTracker returns a tracker keeping the spend in memory only and
+// alerting when the fees of a window exceed its budget, keyed by the name of
+// the window. Windows without a budget don't alert.
+func NewSpendTracker(logger zerolog.Logger, budgets map[string]sdk.Coins) *SpendTracker {
+	return &SpendTracker{
+		logger:   logger.With().Str("module", "spend").Logger(),
+		budgets:  budgets,
+		now:      time.Now,
+		breached: make(map[string]bool, len(SpendWindows)),
+	}
+}
+
+// LoadSpendTracker returns a tracker persisting the spend to path, restoring
+// the spend of a previous run if the file exists.
+func LoadSpendTracker(logger zerolog.Logger, path string, budgets map[string]sdk.Coins) (*SpendTracker, error) {
+	t := NewSpendTracker(logger, budgets)
+	t.path = path
+
+	if err := jsonfile.Read(path, &t.values); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	t.Report()
+	return t, nil
+}
+
+// RecordSpend records the gas used and the fees paid by a transaction
+// included in a block, whether it succeeded or not.
+func (t *SpendTracker) RecordSpend(gasUsed int64, fees sdk.Coins) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	now := t.now()
+	t.prune(now)
+
+	t.values.Txs++
+	if gasUsed > 0 {
+		t.values.GasUsed += uint64(gasUsed)
+	}
+	t.values.Fees = t.values.Fees.Add(fees...)
+	t.values.Recent = append(t.values.Recent, SpendRecord{Time: now, GasUsed: gasUsed, Fees: fees})
+
+	t.report(now)
+
+	if t.path == "" {
+		return
+	}
+	if err := jsonfile.Write(t.path, t.values); err != nil {
+		t.logger.Err(err).Str("path", t.path).Msg("failed to persist spend")
+	}
+}
+
+// Report returns the cumulative spend and the spend of every window.
+func (t *SpendTracker) Report() SpendReport {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	return t.report(t.now())
+}
+
+// report totals the spend per window, emits the totals as gauges and logs a
+// warning for every window which newly exceeded its budget.
+func (t *SpendTracker) report(now time.Time) SpendReport {
+	report := SpendReport{
+		Txs:     t.values.Txs,
+		GasUsed: t.values.GasUsed,
+		Fees:    t.values.Fees,
+		Windows: make(map[string]SpendWindowReport, len(SpendWindows)),
+	}
+	emitSpend("total", int64(t.values.GasUsed), t.values.Fees, t.values.Fees)
+
+	for name, window := range SpendWindows {
+		wr := SpendWindowReport{
+			Fees:   sdk.NewCoins(),
+			Budget: t.budgets[name],
+		}
+		for _, record := range t.values.Recent {
+			if now.Sub(record.Time) > window {
+				continue
+			}
+
+			wr.Txs++
+			wr.GasUsed += record.GasUsed
+			wr.Fees = wr.Fees.Add(record.Fees...)
+		}
+		wr.Exceeded = !wr.Budget.Empty() && !wr.Fees.IsAllLTE(wr.Budget)
+		report.Windows[name] = wr
+
+		emitSpend(name, wr.GasUsed, wr.Fees, t.values.Fees)
+
+		if wr.Exceeded && !t.breached[name] {
+			t.logger.Warn().
+				Str("window", name).
+				Str("fees", wr.Fees.String()).
+				Str("budget", wr.Budget.String()).
+				Int("txs", wr.Txs).
+				Msg("fees paid by the feeder account exceeded the budget")
+		}
+		t.breached[name] = wr.Exceeded
+	}
+
+	return report
+}
+
+// prune drops the transactions which fell out of the largest window.
+func (t *SpendTracker) prune(now time.Time) {
+	var maxWindow time.Duration
+	for _, window := range SpendWindows {
+		if window > maxWindow {
+			maxWindow = window
+		}
+	}
+
+	i := 0
+	for i < len(t.values.Recent) && now.Sub(t.values.Recent[i].Time) > maxWindow {
+		i++
+	}
+	t.values.Recent = t.values.Recent[i:]
+}
+
+// emitSpend sets the gas and fee gauges of a window, the fees per denom of
+// denoms so that the gauge of a denom no longer paid in the window drops to
+// zero.
+func emitSpend(window string, gasUsed int64, fees, denoms sdk.Coins) {
+	windowLabel := telemetry.NewLabel("window", window)
+
+	telemetry.SetGaugeWithLabels([]string{"spend", "gas_used"}, float32(gasUsed), []metrics.Label{windowLabel})
+	for _, denom := range denoms {
+		amount, _ := new(big.Float).SetInt(fees.AmountOf(denom.Denom).BigInt()).Float32()
+		telemetry.SetGaugeWithLabels(
+			[]string{"spend", "fees"},
+			amount,
+			[]metrics.Label{windowLabel, telemetry.NewLabel("denom", denom.Denom)},
+		)
+	}
+}
@@ -0,0 +1,76 @@
+package oracle
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpendTracker(t *testing.T) {
+	now := time.Now()
+	fee := sdk.NewCoins(sdk.NewInt64Coin("uxprt", 50))
+	tracker := NewSpendTracker(zerolog.Nop(), map[string]sdk.Coins{
+		"24h": sdk.NewCoins(sdk.NewInt64Coin("uxprt", 120)),
+	})
+	tracker.now = func() time.Time { return now }
+
+	report := tracker.Report()
+	require.Equal(t, 0, report.Windows["24h"].Txs)
+	require.False(t, report.Windows["24h"].Exceeded)
+
+	tracker.RecordSpend(100000, fee)
+	tracker.RecordSpend(120000, fee)
+	report = tracker.Report()
+	require.Equal(t, SpendWindowReport{
+		Txs:     2,
+		GasUsed: 220000,
+		Fees:    sdk.NewCoins(sdk.NewInt64Coin("uxprt", 100)),
+		Budget:  sdk.NewCoins(sdk.NewInt64Coin("uxprt", 120)),
+	}, report.Windows["24h"])
+
+	// the third tx exceeds the daily budget, the weekly one has no budget
+	tracker.RecordSpend(100000, fee)
+	report = tracker.Report()
+	require.True(t, report.Windows["24h"].Exceeded)
+	require.False(t, report.Windows["7d"].Exceeded)
+
+	// txs older than a day only count towards the weekly window
+	now = now.Add(25 * time.Hour)
+	tracker.RecordSpend(90000, fee)
+	report = tracker.Report()
+	require.Equal(t, 1, report.Windows["24h"].Txs)
+	require.False(t, report.Windows["24h"].Exceeded)
+	require.Equal(t, 4, report.Windows["7d"].Txs)
+	require.Equal(t, int64(410000), report.Windows["7d"].GasUsed)
+
+	// txs older than a week are dropped, the totals are kept
+	now = now.Add(8 * 24 * time.Hour)
+	tracker.RecordSpend(100000, fee)
+	report = tracker.Report()
+	require.Equal(t, 1, report.Windows["7d"].Txs)
+	require.Len(t, tracker.values.Recent, 1)
+	require.Equal(t, uint64(5), report.Txs)
+	require.Equal(t, uint64(510000), report.GasUsed)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("uxprt", 250)), report.Fees)
+}
+
+func TestSpendTrackerPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), SpendFileName)
+
+	tracker, err := LoadSpendTracker(zerolog.Nop(), path, nil)
+	require.NoError(t, err)
+	tracker.RecordSpend(100000, sdk.NewCoins(sdk.NewInt64Coin("uxprt", 50)))
+
+	// the spend of a previous run is restored
+	restored, err := LoadSpendTracker(zerolog.Nop(), path, nil)
+	require.NoError(t, err)
+	report := restored.Report()
+	require.Equal(t, uint64(1), report.Txs)
+	require.Equal(t, uint64(100000), report.GasUsed)
+	require.Equal(t, 1, report.Windows["24h"].Txs)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("uxprt", 50)), report.Windows["24h"].Fees)
+}
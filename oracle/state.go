@@ -0,0 +1,71 @@
+package oracle
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+	"github.com/persistenceOne/oracle-feeder/pkg/jsonfile"
+)
+
+// StateFileName is the name of the file, relative to the data directory, the
+// oracle runtime state is persisted to.
+const StateFileName = "state.json"
+
+// State defines the runtime state of the oracle which is persisted, so that a
+// restarted or migrated feeder can still reveal its last pre-vote and keeps
+// its price history, learned provider weights and last pre-voted prices.
+type State struct {
+	PreviousPrevote    *PreviousPrevote                            `json:"previous_prevote,omitempty"`
+	PreviousVotePeriod float64                                     `json:"previous_vote_period"`
+	ProviderWeights    map[provider.Name]map[string]ProviderWeight `json:"provider_weights"`
+	PriceHistory       []PriceSample                               `json:"price_history"`
+	LastVote           *LastVote                                   `json:"last_vote,omitempty"`
+}
+
+// LoadState reads the state persisted to path. A missing file yields an empty
+// state.
+func LoadState(path string) (State, error) {
+	var state State
+	if err := jsonfile.Read(path, &state); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return State{}, err
+	}
+
+	return state, nil
+}
+
+// WithState restores the oracle from state and persists its state to path
+// after every pre-vote and vote. By default the state is kept in memory only.
+func WithState(path string, state State) Option {
+	return func(o *Oracle) {
+		o.statePath = path
+		o.votes = NewVoteMachine(state.PreviousPrevote, state.PreviousVotePeriod)
+		o.weights.Restore(state.ProviderWeights)
+		o.history.Restore(state.PriceHistory)
+		o.lastVote = state.LastVote
+	}
+}
+
+// State returns the current runtime state of the oracle. It must only be
+// called from the tick goroutine, or once the oracle is stopped.
+func (o *Oracle) State() State {
+	return State{
+		PreviousPrevote:    o.votes.Prevote(),
+		PreviousVotePeriod: o.votes.PrevotePeriod(),
+		ProviderWeights:    o.weights.All(),
+		PriceHistory:       o.history.Since(time.Time{}),
+		LastVote:           o.lastVote,
+	}
+}
+
+// saveState persists the runtime state, if a state path is set.
+func (o *Oracle) saveState() {
+	if len(o.statePath) == 0 {
+		return
+	}
+
+	if err := jsonfile.Write(o.statePath, o.State()); err != nil {
+		o.logger.Error().Err(err).Msg("failed to persist oracle state")
+	}
+}
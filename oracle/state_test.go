@@ -0,0 +1,44 @@
+package oracle
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/client"
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+)
+
+func TestStatePersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), StateFileName)
+
+	state, err := LoadState(path)
+	require.NoError(t, err)
+	require.Equal(t, State{}, state)
+
+	o := New(zerolog.Nop(), &client.MockOracleClient{}, nil, 0, nil, nil, WithState(path, state))
+	o.votes.PrevoteSubmitted(PreviousPrevote{ExchangeRates: "ATOM:10.000000000000000000", Salt: "salt", SubmitBlockHeight: 42}, 42, 5)
+	o.weights.Restore(map[provider.Name]map[string]ProviderWeight{
+		provider.Kraken: {"ATOM": {Weight: sdk.MustNewDecFromStr("0.5"), Bias: sdk.MustNewDecFromStr("0.01")}},
+	})
+	sampleTime := time.Unix(1700000000, 0).UTC()
+	o.history.Add(sampleTime, PricesByProvider{provider.Kraken: {"ATOM": sdk.MustNewDecFromStr("10")}})
+	o.saveState()
+
+	state, err = LoadState(path)
+	require.NoError(t, err)
+
+	restored := New(zerolog.Nop(), &client.MockOracleClient{}, nil, 0, nil, nil, WithState(path, state))
+	require.Equal(t, o.votes, restored.votes)
+	require.Equal(t, float64(8), restored.votes.PrevotePeriod())
+	require.Equal(t, sdk.MustNewDecFromStr("0.5"), restored.weights.Get(provider.Kraken, "ATOM"))
+
+	samples := restored.history.Since(time.Time{})
+	require.Len(t, samples, 1)
+	require.True(t, sampleTime.Equal(samples[0].Time))
+	require.Equal(t, sdk.MustNewDecFromStr("10"), samples[0].Prices[provider.Kraken]["ATOM"])
+}
@@ -0,0 +1,90 @@
+package oracle
+
+import (
+	"context"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/telemetry"
+)
+
+// Voter roles of an oracle instance.
+const (
+	// RoleActive is the role of an instance broadcasting pre-votes and votes.
+	RoleActive = "active"
+	// RoleStandby is the role of a paused instance which only fetches prices.
+	RoleStandby = "standby"
+)
+
+type (
+	// VoterStatus defines whether the oracle is the active voter of its
+	// validator and what it last observed of the active voter while standing
+	// by.
+	VoterStatus struct {
+		Role           string
+		LeaderActivity LeaderActivity
+	}
+
+	// LeaderActivity defines the latest aggregate pre-vote observed on-chain
+	// for the validator while the oracle was on standby. A zero value means no
+	// activity was observed.
+	LeaderActivity struct {
+		SubmitBlock uint64
+		ObservedAt  time.Time
+	}
+)
+
+// Role returns the voter role of the oracle. There is no leader election, an
+// instance is on standby while its voting is paused, see Pause.
+func (o *Oracle) Role() string {
+	if o.IsPaused() {
+		return RoleStandby
+	}
+	return RoleActive
+}
+
+// GetVoterStatus returns the voter role of the oracle and the last observed
+// activity of the active voter.
+func (o *Oracle) GetVoterStatus() VoterStatus {
+	o.leaderMutex.RLock()
+	defer o.leaderMutex.RUnlock()
+
+	return VoterStatus{
+		Role:           o.Role(),
+		LeaderActivity: o.leaderActivity,
+	}
+}
+
+// reportRole emits whether the oracle is the active voter as a gauge, so
+// alerts can detect none or several active voters for the same validator.
+func (o *Oracle) reportRole() {
+	var active float32
+	if !o.IsPaused() {
+		active = 1
+	}
+	telemetry.SetGauge(active, "voter", "active")
+}
+
+// observeLeader records the aggregate pre-vote currently stored on-chain for
+// the validator, which is submitted by the active voter while this oracle is
+// on standby. Failures are only logged since they must not affect the tick.
+func (o *Oracle) observeLeader(ctx context.Context) {
+	prevote, err := o.client.GetAggregatePrevote(ctx)
+	if err != nil {
+		// the query fails when no pre-vote is stored for the current period
+		o.logger.Debug().Err(err).Msg("no active voter pre-vote observed")
+		return
+	}
+
+	o.leaderMutex.Lock()
+	defer o.leaderMutex.Unlock()
+
+	if prevote.SubmitBlock <= o.leaderActivity.SubmitBlock {
+		return
+	}
+
+	o.leaderActivity = LeaderActivity{
+		SubmitBlock: prevote.SubmitBlock,
+		ObservedAt:  time.Now(),
+	}
+	telemetry.SetGauge(float32(o.leaderActivity.SubmitBlock), "voter", "leader_prevote_height")
+}
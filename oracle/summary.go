@@ -0,0 +1,322 @@
+package oracle
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+
+	"github.com/persistenceOne/oracle-feeder/config"
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+// AggregationMethod defines how the price of an asset was computed.
+type AggregationMethod string
+
+// Aggregation methods.
+const (
+	// AggregationTVWAP is the time volume weighted average of the candles.
+	AggregationTVWAP AggregationMethod = "tvwap"
+	// AggregationVWAP is the volume weighted average of the tickers, used for
+	// the assets without candles available, e.g. because they are stale.
+	AggregationVWAP AggregationMethod = "vwap"
+)
+
+var counterKeyAggregations = []string{"aggregation", "method"}
+
+// VoteAction defines what the oracle did about voting during a tick.
+type VoteAction string
+
+// Vote actions.
+const (
+	VoteActionNone            VoteAction = "none"
+	VoteActionPaused          VoteAction = "paused"
+	VoteActionSkipped         VoteAction = "skipped"
+	VoteActionStaleHeight     VoteAction = "stale_height"
+	VoteActionCriticalMissing VoteAction = "critical_missing"
+	VoteActionPrevote         VoteAction = "prevote"
+	VoteActionPrevoteFailed   VoteAction = "prevote_failed"
+	VoteActionVote            VoteAction = "vote"
+	VoteActionVoteFailed      VoteAction = "vote_failed"
+)
+
+// AssetAggregation defines how the price of an asset was computed during a
+// tick: the method, the providers whose prices were used or filtered out as
+// deviating, the deviation filter decision for each of them and the USD
+// conversions applied to the prices of the providers quoting it in another
+// asset beforehand.
+type AssetAggregation struct {
+	Method      AggregationMethod   `json:"method"`
+	Providers   []provider.Name     `json:"providers"`
+	Filtered    []provider.Name     `json:"filtered,omitempty"`
+	Decisions   []DeviationDecision `json:"decisions,omitempty"`
+	Conversions []Conversion        `json:"conversions,omitempty"`
+}
+
+// Conversion defines the conversion of the prices of an asset of a provider,
+// quoted in Quote, to USD at Rate, the USD price of Quote.
+type Conversion struct {
+	Provider provider.Name `json:"provider"`
+	Quote    string        `json:"quote"`
+	Rate     sdk.Dec       `json:"rate"`
+}
+
+// Confidence returns the share of the providers of the asset which passed
+// the deviation filter.
+func (a AssetAggregation) Confidence() sdk.Dec {
+	total := len(a.Providers) + len(a.Filtered)
+	if total == 0 {
+		return sdk.ZeroDec()
+	}
+
+	return sdk.NewDec(int64(len(a.Providers))).QuoInt64(int64(total))
+}
+
+// newAssetAggregations returns the aggregation of every asset from the
+// deviation filter decisions, which are sorted by base and provider.
+func newAssetAggregations(method AggregationMethod, decisions []DeviationDecision) map[string]AssetAggregation {
+	aggregations := make(map[string]AssetAggregation)
+	for _, decision := range decisions {
+		aggregation := aggregations[decision.Base]
+		aggregation.Method = method
+		if decision.Outcome == DeviationFiltered {
+			aggregation.Filtered = append(aggregation.Filtered, decision.Provider)
+		} else {
+			aggregation.Providers = append(aggregation.Providers, decision.Provider)
+		}
+		aggregation.Decisions = append(aggregation.Decisions, decision)
+		aggregations[decision.Base] = aggregation
+	}
+
+	return aggregations
+}
+
+// withConversions records, in the aggregation of every asset, the conversions
+// of the prices of the providers whose pair of the asset is quoted in another
+// asset, at the given USD rates.
+func withConversions(
+	aggregations map[string]AssetAggregation,
+	providerPairs map[provider.Name][]types.CurrencyPair,
+	rates map[string]sdk.Dec,
+) map[string]AssetAggregation {
+	if len(rates) == 0 {
+		return aggregations
+	}
+
+	for base, aggregation := range aggregations {
+		for _, decision := range aggregation.Decisions {
+			for _, pair := range providerPairs[decision.Provider] {
+				if pair.Base != base {
+					continue
+				}
+				if rate, ok := rates[pair.Quote]; ok {
+					aggregation.Conversions = append(aggregation.Conversions, Conversion{
+						Provider: decision.Provider,
+						Quote:    pair.Quote,
+						Rate:     rate,
+					})
+				}
+			}
+		}
+		aggregations[base] = aggregation
+	}
+
+	return aggregations
+}
+
+// pricedAggregations returns the aggregations of the assets with a price.
+func pricedAggregations(
+	prices map[string]sdk.Dec,
+	aggregations map[string]AssetAggregation,
+) map[string]AssetAggregation {
+	for base := range aggregations {
+		if _, ok := prices[base]; !ok {
+			delete(aggregations, base)
+		}
+	}
+
+	return aggregations
+}
+
+// GetAggregations returns how the price of every asset was computed during
+// the last tick.
+func (o *Oracle) GetAggregations() map[string]AssetAggregation {
+	o.aggregationsMutex.RLock()
+	defer o.aggregationsMutex.RUnlock()
+
+	aggregations := make(map[string]AssetAggregation, len(o.aggregations))
+	for base, aggregation := range o.aggregations {
+		aggregations[base] = aggregation
+	}
+
+	return aggregations
+}
+
+// GetAggregationCounts returns the number of ticks the price of every asset
+// was computed with each method since the start, which shows a chronic
+// fallback from candles to tickers.
+func (o *Oracle) GetAggregationCounts() map[string]map[AggregationMethod]uint64 {
+	o.aggregationsMutex.RLock()
+	defer o.aggregationsMutex.RUnlock()
+
+	counts := make(map[string]map[AggregationMethod]uint64, len(o.aggregationCounts))
+	for base, methodCounts := range o.aggregationCounts {
+		counts[base] = make(map[AggregationMethod]uint64, len(methodCounts))
+		for method, count := range methodCounts {
+			counts[base][method] = count
+		}
+	}
+
+	return counts
+}
+
+// setAggregations records the aggregations of a tick and counts the method
+// used per asset, which is also emitted as a telemetry counter along with the
+// deviation filter decisions.
+func (o *Oracle) setAggregations(aggregations map[string]AssetAggregation) {
+	o.aggregationsMutex.Lock()
+	defer o.aggregationsMutex.Unlock()
+
+	o.aggregations = aggregations
+
+	if o.aggregationCounts == nil {
+		o.aggregationCounts = make(map[string]map[AggregationMethod]uint64)
+	}
+	for base, aggregation := range aggregations {
+		if _, ok := o.aggregationCounts[base]; !ok {
+			o.aggregationCounts[base] = make(map[AggregationMethod]uint64)
+		}
+		o.aggregationCounts[base][aggregation.Method]++
+
+		telemetry.IncrCounterWithLabels(counterKeyAggregations, 1, []metrics.Label{
+			telemetry.NewLabel("asset", base),
+			telemetry.NewLabel("method", string(aggregation.Method)),
+		})
+		emitDeviationDecisions(aggregation.Decisions)
+	}
+}
+
+// logTickSummary logs a single record summarizing the tick which started at
+// start: the assets priced, how their prices were computed, the vote action
+// taken and the tick duration, so that a tick can be reconstructed from the
+// logs alone. On the unchanged path, the per asset details are left out as
+// they match the ones of the last logged summary. The tick duration is also
+// emitted, labeled by whether the prices were unchanged.
+func (o *Oracle) logTickSummary(start time.Time, tickErr error) {
+	o.pricesMutex.RLock()
+	unchanged := o.pricesUnchanged
+	o.pricesMutex.RUnlock()
+
+	metrics.MeasureSinceWithLabels([]string{"tick", "duration"}, start, []metrics.Label{
+		telemetry.NewLabel("unchanged", strconv.FormatBool(unchanged)),
+	})
+
+	if unchanged && tickErr == nil {
+		o.logger.Info().
+			Int("assets_priced", len(o.GetPrices())).
+			Bool("prices_unchanged", true).
+			Str("vote_action", string(o.voteAction)).
+			Dur("duration", time.Since(start)).
+			Msg("tick summary")
+		return
+	}
+
+	prices := o.GetPrices()
+	aggregations := o.GetAggregations()
+
+	assets := zerolog.Dict()
+	for base, aggregation := range aggregations {
+		asset := zerolog.Dict().
+			Str("method", string(aggregation.Method)).
+			Interface("providers", aggregation.Providers).
+			Interface("filtered", aggregation.Filtered)
+		if price, ok := prices[base]; ok {
+			asset = asset.Str("price", price.String())
+		}
+		assets = assets.Dict(base, asset)
+	}
+
+	event := o.logger.Info()
+	if tickErr != nil {
+		event = o.logger.Warn().Err(tickErr)
+	}
+
+	event.
+		Int("assets_priced", len(prices)).
+		Dict("assets", assets).
+		Str("vote_action", string(o.voteAction)).
+		Dur("duration", time.Since(start)).
+		Msg("tick summary")
+}
+
+// emitPriceAges sets the age in seconds of the price of every asset, so that
+// an asset whose price is no longer computed can be alerted on by itself.
+func emitPriceAges(now time.Time, timestamps map[string]time.Time) {
+	for base, timestamp := range timestamps {
+		telemetry.SetGaugeWithLabels(
+			[]string{"price", "age_seconds"},
+			float32(now.Sub(timestamp).Seconds()),
+			[]metrics.Label{telemetry.NewLabel("asset", base)},
+		)
+	}
+}
+
+// emitPrices sets the computed price of every asset. The gauges lose the
+// precision of the decimals beyond float32, so they only suit dashboards and
+// alerts, the exact prices being served by the API. Like the API, they must
+// leave out the assets priced by vote-only providers alone, see
+// Oracle.publicPrices.
+func emitPrices(prices map[string]sdk.Dec) {
+	for base, price := range prices {
+		value, err := price.Float64()
+		if err != nil {
+			continue
+		}
+		telemetry.SetGaugeWithLabels(
+			[]string{"price", "computed"},
+			float32(value),
+			[]metrics.Label{telemetry.NewLabel("asset", base)},
+		)
+	}
+}
+
+// publicPrices returns the prices without the assets priced by vote-only
+// providers alone, see WithVoteOnlyProviders.
+func (o *Oracle) publicPrices(prices map[string]sdk.Dec) map[string]sdk.Dec {
+	if len(o.voteOnlyProviders) == 0 {
+		return prices
+	}
+
+	cfg := config.Config{VoteOnlyProviders: o.voteOnlyProviders}
+	aggregations := o.GetAggregations()
+	public := make(map[string]sdk.Dec, len(prices))
+	for base, price := range prices {
+		if cfg.IsVoteOnlyAsset(aggregations[base].Providers) {
+			continue
+		}
+		public[base] = price
+	}
+
+	return public
+}
+
+// Kinds of the provider requests, see measureProviderFetch.
+const (
+	providerFetchTickers = "tickers"
+	providerFetchCandles = "candles"
+)
+
+// measureProviderFetch measures the latency of the request of the tickers or
+// candles of the provider started at start, labeled by whether it failed with
+// err.
+func measureProviderFetch(providerName provider.Name, kind string, start time.Time, err error) {
+	metrics.MeasureSinceWithLabels([]string{"provider", "fetch", "latency"}, start, []metrics.Label{
+		telemetry.NewLabel("provider", providerName.String()),
+		telemetry.NewLabel("kind", kind),
+		telemetry.NewLabel("failed", strconv.FormatBool(err != nil)),
+	})
+}
@@ -0,0 +1,95 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/armon/go-metrics"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+func TestNewAssetAggregations(t *testing.T) {
+	decisions := []DeviationDecision{
+		{Provider: provider.Binance, Base: "ATOM", Outcome: DeviationAccepted},
+		{Provider: provider.Huobi, Base: "ATOM", Outcome: DeviationFiltered},
+		{Provider: provider.Kraken, Base: "ATOM", Outcome: DeviationAccepted},
+		{Provider: provider.Kraken, Base: "OSMO", Outcome: DeviationUnchecked},
+	}
+
+	require.Equal(t, map[string]AssetAggregation{
+		"ATOM": {
+			Method:    AggregationTVWAP,
+			Providers: []provider.Name{provider.Binance, provider.Kraken},
+			Filtered:  []provider.Name{provider.Huobi},
+			Decisions: decisions[:3],
+		},
+		"OSMO": {
+			Method:    AggregationTVWAP,
+			Providers: []provider.Name{provider.Kraken},
+			Decisions: decisions[3:],
+		},
+	}, newAssetAggregations(AggregationTVWAP, decisions))
+}
+
+func TestWithConversions(t *testing.T) {
+	decisions := []DeviationDecision{
+		{Provider: provider.Binance, Base: "ATOM", Outcome: DeviationAccepted},
+		{Provider: provider.Kraken, Base: "ATOM", Outcome: DeviationAccepted},
+	}
+	providerPairs := map[provider.Name][]types.CurrencyPair{
+		provider.Binance: {{Base: "ATOM", Quote: "USDT"}, {Base: "USDT", Quote: "USD"}},
+		provider.Kraken:  {{Base: "ATOM", Quote: "USD"}},
+	}
+	rates := map[string]sdk.Dec{"USDT": sdk.MustNewDecFromStr("0.999")}
+
+	aggregations := withConversions(newAssetAggregations(AggregationVWAP, decisions), providerPairs, rates)
+	require.Equal(t, []Conversion{
+		{Provider: provider.Binance, Quote: "USDT", Rate: sdk.MustNewDecFromStr("0.999")},
+	}, aggregations["ATOM"].Conversions)
+}
+
+func TestEmitMetrics(t *testing.T) {
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+	metricsConfig := metrics.DefaultConfig("test")
+	metricsConfig.EnableHostname = false
+	metricsConfig.EnableRuntimeMetrics = false
+	_, err := metrics.NewGlobal(metricsConfig, sink)
+	require.NoError(t, err)
+
+	emitPrices(map[string]sdk.Dec{"ATOM": sdk.MustNewDecFromStr("10.5")})
+	measureProviderFetch(provider.Binance, providerFetchTickers, time.Now(), nil)
+	measureProviderFetch(provider.Binance, providerFetchTickers, time.Now(), errors.New("timeout"))
+
+	data := sink.Data()
+	require.Len(t, data, 1)
+	require.Equal(t, float32(10.5), data[0].Gauges["test.price.computed;asset=ATOM"].Value)
+	labels := ";provider=binance;kind=tickers;failed="
+	require.Equal(t, 1, data[0].Samples["test.provider.fetch.latency"+labels+"false"].Count)
+	require.Equal(t, 1, data[0].Samples["test.provider.fetch.latency"+labels+"true"].Count)
+}
+
+func TestEmitPricesVoteOnly(t *testing.T) {
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+	metricsConfig := metrics.DefaultConfig("test")
+	metricsConfig.EnableHostname = false
+	metricsConfig.EnableRuntimeMetrics = false
+	_, err := metrics.NewGlobal(metricsConfig, sink)
+	require.NoError(t, err)
+
+	// ATOM is priced by binance alone
+	o, _ := newVotingOracle(t, 20)
+	WithVoteOnlyProviders([]provider.Name{provider.Binance})(o)
+	o.tick(context.Background())
+
+	require.Contains(t, o.GetPrices(), "ATOM")
+	data := sink.Data()
+	require.Len(t, data, 1)
+	require.NotContains(t, data[0].Gauges, "test.price.computed;asset=ATOM")
+	require.Contains(t, data[0].Gauges, "test.price.age_seconds;asset=ATOM")
+}
@@ -0,0 +1,84 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type (
+	// Symbol defines the normalized, upper case ticker symbol of an asset,
+	// e.g. "STKATOM". Symbols must be created with NewSymbol so that
+	// differently cased spellings such as "stkATOM" refer to the same asset.
+	Symbol string
+
+	// Asset defines an asset the oracle votes on along with its chain denom
+	// metadata, as listed in the x/oracle accept list.
+	Asset struct {
+		Symbol   Symbol
+		Denom    string
+		Exponent uint32
+	}
+
+	// AssetRegistry defines the set of assets accepted on chain, indexed by
+	// symbol.
+	AssetRegistry struct {
+		assets map[Symbol]Asset
+	}
+)
+
+// NewSymbol returns the normalized symbol of s.
+func NewSymbol(s string) Symbol {
+	return Symbol(strings.ToUpper(strings.TrimSpace(s)))
+}
+
+// String implements the Stringer interface.
+func (s Symbol) String() string {
+	return string(s)
+}
+
+// NewAssetRegistry returns a registry of the given assets.
+func NewAssetRegistry(assets ...Asset) *AssetRegistry {
+	ar := &AssetRegistry{assets: make(map[Symbol]Asset, len(assets))}
+	for _, asset := range assets {
+		asset.Symbol = NewSymbol(asset.Symbol.String())
+		ar.assets[asset.Symbol] = asset
+	}
+
+	return ar
+}
+
+// Lookup returns the asset with the given symbol, in any casing.
+func (ar *AssetRegistry) Lookup(symbol string) (Asset, bool) {
+	asset, ok := ar.assets[NewSymbol(symbol)]
+	return asset, ok
+}
+
+// Assets returns the assets of the registry ordered by symbol.
+func (ar *AssetRegistry) Assets() []Asset {
+	assets := make([]Asset, 0, len(ar.assets))
+	for _, asset := range ar.assets {
+		assets = append(assets, asset)
+	}
+	sort.Slice(assets, func(i, j int) bool { return assets[i].Symbol < assets[j].Symbol })
+
+	return assets
+}
+
+// Validate returns an error listing the symbols which are not in the
+// registry.
+func (ar *AssetRegistry) Validate(symbols ...string) error {
+	var unknown []string
+	for _, symbol := range symbols {
+		if _, ok := ar.Lookup(symbol); !ok {
+			unknown = append(unknown, symbol)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("assets not in the accept list: %s", strings.Join(unknown, ", "))
+	}
+
+	return nil
+}
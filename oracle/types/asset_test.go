@@ -0,0 +1,38 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssetRegistry(t *testing.T) {
+	registry := NewAssetRegistry(
+		Asset{Symbol: "stkATOM", Denom: "stk/uatom", Exponent: 6},
+		Asset{Symbol: NewSymbol("XPRT"), Denom: "uxprt", Exponent: 6},
+	)
+
+	t.Run("lookup is case insensitive", func(t *testing.T) {
+		asset, ok := registry.Lookup("STKATOM")
+		require.True(t, ok)
+		require.Equal(t, Asset{Symbol: "STKATOM", Denom: "stk/uatom", Exponent: 6}, asset)
+
+		_, ok = registry.Lookup(" stkatom ")
+		require.True(t, ok)
+
+		_, ok = registry.Lookup("ATOM")
+		require.False(t, ok)
+	})
+
+	t.Run("assets are ordered by symbol", func(t *testing.T) {
+		assets := registry.Assets()
+		require.Len(t, assets, 2)
+		require.Equal(t, Symbol("STKATOM"), assets[0].Symbol)
+		require.Equal(t, Symbol("XPRT"), assets[1].Symbol)
+	})
+
+	t.Run("validate lists unknown assets", func(t *testing.T) {
+		require.NoError(t, registry.Validate("stkAtom", "xprt"))
+		require.EqualError(t, registry.Validate("XPRT", "OSMO", "ATOM"), "assets not in the accept list: ATOM, OSMO")
+	})
+}
@@ -0,0 +1,134 @@
+package oracle
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+)
+
+// LastVote defines the prices last pre-voted, after the anomalous ones were
+// held, and their exchange rates string, which is pre-voted again as long as
+// the prices don't move by more than the unchanged epsilon.
+type LastVote struct {
+	Prices        map[string]sdk.Dec `json:"prices"`
+	ExchangeRates string             `json:"exchange_rates"`
+}
+
+// WithUnchangedEpsilon enables the unchanged path, with epsilon e.g. 0.0001
+// for 0.01%. When every price was last computed from the tickers, no ticker
+// price of any provider moved by more than epsilon relative to the ones the
+// prices were computed from and no provider got a new candle, the prices are
+// kept without converting, filtering and aggregating the candles and tickers
+// again, and the tick summary leaves out the per asset details. When no voted
+// price moved by more than epsilon relative to the last pre-voted one, its
+// exchange rates are pre-voted again without being recomputed. By default the
+// prices and the exchange rates are recomputed on every tick and pre-vote.
+func WithUnchangedEpsilon(epsilon sdk.Dec) Option {
+	return func(o *Oracle) {
+		o.unchangedEpsilon = epsilon
+	}
+}
+
+// pricesUnchanged returns whether prices holds the same assets as last and
+// none of them moved by more than epsilon relative to its last price. It is
+// always false when epsilon is nil, i.e. the unchanged path is disabled.
+func pricesUnchanged(last, prices map[string]sdk.Dec, epsilon sdk.Dec) bool {
+	if epsilon.IsNil() || len(last) == 0 || len(last) != len(prices) {
+		return false
+	}
+
+	for base, price := range prices {
+		lastPrice, ok := last[base]
+		if !ok || !lastPrice.IsPositive() {
+			return false
+		}
+		if price.Sub(lastPrice).Abs().Quo(lastPrice).GT(epsilon) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// aggregationInputs defines the inputs of a price aggregation compared on the
+// unchanged path: the ticker prices of every provider which priced at least
+// one asset and the timestamp of the latest candle of every provider pair.
+type aggregationInputs struct {
+	tickers PricesByProvider
+	candles map[provider.Name]map[string]int64
+}
+
+func newAggregationInputs(
+	providerPrices provider.AggregatedProviderPrices,
+	providerCandles provider.AggregatedProviderCandles,
+) aggregationInputs {
+	inputs := aggregationInputs{
+		tickers: make(PricesByProvider, len(providerPrices)),
+		candles: make(map[provider.Name]map[string]int64, len(providerCandles)),
+	}
+	for providerName, prices := range providerPrices {
+		if len(prices) == 0 {
+			continue
+		}
+		inputs.tickers[providerName] = make(map[string]sdk.Dec, len(prices))
+		for base, ticker := range prices {
+			inputs.tickers[providerName][base] = ticker.Price
+		}
+	}
+	for providerName, candles := range providerCandles {
+		inputs.candles[providerName] = make(map[string]int64, len(candles))
+		for base, pairCandles := range candles {
+			var latest int64
+			for _, candle := range pairCandles {
+				if candle.TimeStamp > latest {
+					latest = candle.TimeStamp
+				}
+			}
+			inputs.candles[providerName][base] = latest
+		}
+	}
+
+	return inputs
+}
+
+// unchanged returns whether the inputs hold the same providers as last, none
+// of their ticker prices moved by more than epsilon, see pricesUnchanged, and
+// none of them got a new candle.
+func (in aggregationInputs) unchanged(last aggregationInputs, epsilon sdk.Dec) bool {
+	if len(last.tickers) == 0 || len(last.tickers) != len(in.tickers) || len(last.candles) != len(in.candles) {
+		return false
+	}
+
+	for providerName, prices := range in.tickers {
+		if !pricesUnchanged(last.tickers[providerName], prices, epsilon) {
+			return false
+		}
+	}
+	for providerName, candles := range in.candles {
+		lastCandles, ok := last.candles[providerName]
+		if !ok || len(lastCandles) != len(candles) {
+			return false
+		}
+		for base, latest := range candles {
+			if lastLatest, ok := lastCandles[base]; !ok || lastLatest != latest {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// pricedByVWAP returns whether every price was last computed from the
+// tickers, i.e. none from candles. The TVWAP of candles depends on the time
+// it is computed at, so it can't be kept on the unchanged path.
+func (o *Oracle) pricedByVWAP() bool {
+	aggregations := o.GetAggregations()
+	for base := range o.prices {
+		if aggregations[base].Method != AggregationVWAP {
+			return false
+		}
+	}
+
+	return true
+}
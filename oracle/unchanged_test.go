@@ -0,0 +1,243 @@
+package oracle
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+func TestPricesUnchanged(t *testing.T) {
+	last := map[string]sdk.Dec{
+		"ATOM": sdk.MustNewDecFromStr("10"),
+		"OSMO": sdk.MustNewDecFromStr("0.5"),
+	}
+	epsilon := sdk.MustNewDecFromStr("0.001")
+
+	testCases := []struct {
+		name     string
+		prices   map[string]sdk.Dec
+		epsilon  sdk.Dec
+		expected bool
+	}{
+		{
+			name:     "within epsilon",
+			prices:   map[string]sdk.Dec{"ATOM": sdk.MustNewDecFromStr("10.01"), "OSMO": sdk.MustNewDecFromStr("0.4996")},
+			epsilon:  epsilon,
+			expected: true,
+		},
+		{
+			name:     "moved by more than epsilon",
+			prices:   map[string]sdk.Dec{"ATOM": sdk.MustNewDecFromStr("10.02"), "OSMO": sdk.MustNewDecFromStr("0.5")},
+			epsilon:  epsilon,
+			expected: false,
+		},
+		{
+			name:     "asset missing",
+			prices:   map[string]sdk.Dec{"ATOM": sdk.MustNewDecFromStr("10")},
+			epsilon:  epsilon,
+			expected: false,
+		},
+		{
+			name:     "asset replaced",
+			prices:   map[string]sdk.Dec{"ATOM": sdk.MustNewDecFromStr("10"), "JUNO": sdk.MustNewDecFromStr("0.5")},
+			epsilon:  epsilon,
+			expected: false,
+		},
+		{
+			name:     "disabled",
+			prices:   last,
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, pricesUnchanged(last, tc.prices, tc.epsilon))
+		})
+	}
+
+	require.False(t, pricesUnchanged(nil, last, epsilon))
+}
+
+func TestPrevoteExchangeRatesUnchanged(t *testing.T) {
+	o, _ := newVotingOracle(t, 20)
+	WithUnchangedEpsilon(sdk.MustNewDecFromStr("0.001"))(o)
+	tick := VoteTick{State: VoteStateIdle, Broadcast: true, VotePeriod: 3}
+
+	o.prices = map[string]sdk.Dec{"ATOM": sdk.MustNewDecFromStr("29.93")}
+	rates, err := o.prevoteExchangeRates(tick)
+	require.NoError(t, err)
+	require.Equal(t, "ATOM:29.93", rates)
+
+	// a move within the epsilon pre-votes the last exchange rates again
+	o.prices = map[string]sdk.Dec{"ATOM": sdk.MustNewDecFromStr("29.94")}
+	rates, err = o.prevoteExchangeRates(tick)
+	require.NoError(t, err)
+	require.Equal(t, "ATOM:29.93", rates)
+
+	// the reference stays the last recomputed pre-vote, so small moves don't
+	// add up unnoticed
+	o.prices = map[string]sdk.Dec{"ATOM": sdk.MustNewDecFromStr("29.97")}
+	rates, err = o.prevoteExchangeRates(tick)
+	require.NoError(t, err)
+	require.Equal(t, "ATOM:29.97", rates)
+
+	// the last pre-vote is persisted with the state
+	require.Equal(t, rates, o.State().LastVote.ExchangeRates)
+}
+
+func TestSetPricesUnchanged(t *testing.T) {
+	o, _ := newVotingOracle(t, 20)
+	WithUnchangedEpsilon(sdk.MustNewDecFromStr("0.001"))(o)
+	setTicker := func(price string) {
+		o.priceProviders[provider.Binance] = staticProvider{
+			"ATOMUSD": {Price: sdk.MustNewDecFromStr(price), Volume: sdk.MustNewDecFromStr("894123")},
+		}
+	}
+
+	require.NoError(t, o.setPrices(context.Background()))
+	require.False(t, o.pricesUnchanged)
+	require.Equal(t, "29.930000000000000000", o.GetPrices()["ATOM"].String())
+
+	// a ticker move within the epsilon keeps the prices without aggregating
+	// them again
+	setTicker("29.94")
+	require.NoError(t, o.setPrices(context.Background()))
+	require.True(t, o.pricesUnchanged)
+	require.Equal(t, "29.930000000000000000", o.GetPrices()["ATOM"].String())
+
+	// the reference stays the tickers last aggregated, so small moves don't
+	// add up unnoticed
+	setTicker("29.97")
+	require.NoError(t, o.setPrices(context.Background()))
+	require.False(t, o.pricesUnchanged)
+	require.Equal(t, "29.970000000000000000", o.GetPrices()["ATOM"].String())
+
+	// a provider no longer pricing the asset changes the aggregation input
+	o.priceProviders[provider.Binance] = staticProvider{}
+	require.NoError(t, o.setPrices(context.Background()))
+	require.False(t, o.pricesUnchanged)
+}
+
+// candleProvider defines a static provider with candles.
+type candleProvider struct {
+	staticProvider
+	candles map[string][]types.CandlePrice
+}
+
+func (cp candleProvider) GetCandlePrices(
+	_ context.Context,
+	_ ...types.CurrencyPair,
+) (map[string][]types.CandlePrice, error) {
+	return cp.candles, nil
+}
+
+func TestSetPricesUnchangedCandles(t *testing.T) {
+	o, _ := newVotingOracle(t, 20)
+	WithUnchangedEpsilon(sdk.MustNewDecFromStr("0.001"))(o)
+	ticker := o.priceProviders[provider.Binance].(staticProvider)
+	candle := func(price string, age time.Duration) types.CandlePrice {
+		return types.CandlePrice{
+			Price:     sdk.MustNewDecFromStr(price),
+			Volume:    sdk.MustNewDecFromStr("1000"),
+			TimeStamp: provider.PastUnixTime(age),
+		}
+	}
+	setCandles := func(candles ...types.CandlePrice) {
+		o.priceProviders[provider.Binance] = candleProvider{
+			staticProvider: ticker,
+			candles:        map[string][]types.CandlePrice{"ATOMUSD": candles},
+		}
+	}
+
+	setCandles(candle("30", 2*time.Minute))
+	require.NoError(t, o.setPrices(context.Background()))
+	require.Equal(t, AggregationTVWAP, o.GetAggregations()["ATOM"].Method)
+	require.Equal(t, "30.000000000000000000", o.GetPrices()["ATOM"].String())
+
+	// the tickers are unchanged but a new candle moves the TVWAP
+	setCandles(candle("30", 2*time.Minute), candle("32", time.Minute))
+	require.NoError(t, o.setPrices(context.Background()))
+	require.False(t, o.pricesUnchanged)
+	require.True(t, o.GetPrices()["ATOM"].GT(sdk.MustNewDecFromStr("30")))
+
+	// the TVWAP depends on the time it is computed at, so it is computed again
+	// even from the same inputs
+	require.NoError(t, o.setPrices(context.Background()))
+	require.False(t, o.pricesUnchanged)
+
+	// prices computed from the tickers are kept until a provider gets a new
+	// candle, which may price the asset from its candles again
+	setCandles()
+	require.NoError(t, o.setPrices(context.Background()))
+	require.Equal(t, AggregationVWAP, o.GetAggregations()["ATOM"].Method)
+	require.NoError(t, o.setPrices(context.Background()))
+	require.True(t, o.pricesUnchanged)
+
+	setCandles(candle("30", time.Minute))
+	require.NoError(t, o.setPrices(context.Background()))
+	require.False(t, o.pricesUnchanged)
+	require.Equal(t, AggregationTVWAP, o.GetAggregations()["ATOM"].Method)
+	require.Equal(t, "30.000000000000000000", o.GetPrices()["ATOM"].String())
+}
+
+func TestPrevoteExchangeRatesUnchangedAnomalies(t *testing.T) {
+	o, _ := newVotingOracle(t, 20)
+	WithUnchangedEpsilon(sdk.MustNewDecFromStr("0.001"))(o)
+	WithAnomalyDetector(NewAnomalyDetector(zerolog.Nop(), sdk.MustNewDecFromStr("0.2"), 0, 2))(o)
+
+	prevote := func(votePeriod uint64, atom string) string {
+		o.prices = map[string]sdk.Dec{"ATOM": sdk.MustNewDecFromStr(atom), "OSMO": sdk.MustNewDecFromStr("1")}
+		rates, err := o.prevoteExchangeRates(VoteTick{State: VoteStateIdle, Broadcast: true, VotePeriod: votePeriod})
+		require.NoError(t, err)
+		return rates
+	}
+
+	require.Equal(t, "ATOM:10,OSMO:1", prevote(1, "10"))
+
+	// the unchanged path still goes through the anomaly detection, which
+	// records every pre-voted price
+	require.Equal(t, "ATOM:10,OSMO:1", prevote(2, "10.005"))
+	require.Len(t, o.anomalies.history["ATOM"], 2)
+
+	// the held asset is left out of the prices compared to the next pre-vote
+	require.Equal(t, "OSMO:1", prevote(3, "15"))
+	require.Equal(t, map[string]sdk.Dec{"OSMO": sdk.MustNewDecFromStr("1")}, o.lastVote.Prices)
+	require.Equal(t, "ATOM:15,OSMO:1", prevote(4, "15"))
+}
+
+func TestPrevoteExchangeRatesUnchangedRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), StateFileName)
+	epsilon := sdk.MustNewDecFromStr("0.001")
+	tick := VoteTick{State: VoteStateIdle, Broadcast: true, VotePeriod: 3}
+
+	o, _ := newVotingOracle(t, 20)
+	WithUnchangedEpsilon(epsilon)(o)
+	WithState(path, State{})(o)
+
+	o.prices = map[string]sdk.Dec{"ATOM": sdk.MustNewDecFromStr("29.93")}
+	rates, err := o.prevoteExchangeRates(tick)
+	require.NoError(t, err)
+	o.saveState()
+
+	// a restarted feeder pre-votes the persisted exchange rates again
+	state, err := LoadState(path)
+	require.NoError(t, err)
+
+	restored, _ := newVotingOracle(t, 20)
+	WithUnchangedEpsilon(epsilon)(restored)
+	WithState(path, state)(restored)
+
+	restored.prices = map[string]sdk.Dec{"ATOM": sdk.MustNewDecFromStr("29.94")}
+	restoredRates, err := restored.prevoteExchangeRates(tick)
+	require.NoError(t, err)
+	require.Equal(t, rates, restoredRates)
+}
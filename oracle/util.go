@@ -8,6 +8,7 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 
 	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
 )
 
 var (
@@ -15,11 +16,79 @@ var (
 	minimumCandleVolume = sdk.MustNewDecFromStr("0.0001")
 )
 
+// DefaultCandleFloors are the candle floors of the assets whose floors are
+// not configured.
+var DefaultCandleFloors = CandleFloors{TimeWeight: minimumTimeWeight, Volume: minimumCandleVolume}
+
 const (
 	// tvwapCandlePeriod represents the time period we use for tvwap in minutes.
 	tvwapCandlePeriod = 5 * time.Minute
 )
 
+// DefaultCandleWindow is the candle window used by ComputeTVWAP, both the
+// TVWAP period and the maximum candle age are tvwapCandlePeriod.
+var DefaultCandleWindow = CandleWindow{Period: tvwapCandlePeriod, MaxAge: tvwapCandlePeriod}
+
+// CandleWindow defines which candles are used to compute a TVWAP. Period is
+// the aggregation window and MaxAge the age of the latest candle of a provider
+// after which its candles of an asset are considered stale. If the latest
+// candle is older than Period but within MaxAge, the window ends at the latest
+// candle instead of now, so that thin markets with slow candles still get a
+// price. MaxAge must not be less than Period. Note that providers only keep
+// candles for 10 minutes.
+//
+// Floors are the candle floors of every asset, unless overridden by the
+// AssetFloors of its base. Their unset values default to the ones of
+// DefaultCandleFloors.
+type CandleWindow struct {
+	Period      time.Duration
+	MaxAge      time.Duration
+	Floors      CandleFloors
+	AssetFloors map[string]CandleFloors
+}
+
+// CandleFloors defines the floors of the TVWAP weights of candles. TimeWeight
+// is the minimum time weight of the TVWAP, which weighs the volume of a
+// candle by (1 - TimeWeight) / period * (period - age + TimeWeight), the age
+// and period being in milliseconds, and must thus be less than 1. Volume is
+// the volume given to candles without volume. The candles of low-liquidity
+// assets often have no volume, in which case the floor volume dominates their
+// TVWAP.
+type CandleFloors struct {
+	TimeWeight sdk.Dec
+	Volume     sdk.Dec
+}
+
+// floors returns the candle floors of the base.
+func (w CandleWindow) floors(base string) CandleFloors {
+	floors := DefaultCandleFloors
+	for _, override := range []CandleFloors{w.Floors, w.AssetFloors[base]} {
+		if !override.TimeWeight.IsNil() {
+			floors.TimeWeight = override.TimeWeight
+		}
+		if !override.Volume.IsNil() {
+			floors.Volume = override.Volume
+		}
+	}
+
+	return floors
+}
+
+// start returns the unix time in milliseconds after which the candles sorted
+// old -> new are used, and false if they are stale.
+func (w CandleWindow) start(now int64, candles []types.CandlePrice) (int64, bool) {
+	latest := candles[len(candles)-1].TimeStamp
+	age := time.Duration(now-latest) * time.Millisecond
+	switch {
+	case age > w.MaxAge:
+		return 0, false
+	case age <= w.Period:
+		return now - w.Period.Milliseconds(), true
+	default:
+		return latest - w.Period.Milliseconds(), true
+	}
+}
+
 // compute VWAP for each base by dividing the Σ {P * V} by Σ {V}.
 func vwap(weightedPrices, volumeSum map[string]sdk.Dec) map[string]sdk.Dec {
 	vwaps := make(map[string]sdk.Dec)
@@ -67,17 +136,22 @@ func ComputeVWAP(prices provider.AggregatedProviderPrices) map[string]sdk.Dec {
 }
 
 // ComputeTVWAP computes the time volume weighted average price for all points
-// for each exchange pair. Filters out any candles that did not occur within
-// timePeriod. The provided prices argument reflects a mapping of
+// for each exchange pair within the DefaultCandleWindow.
+func ComputeTVWAP(prices provider.AggregatedProviderCandles) (map[string]sdk.Dec, error) {
+	return ComputeTVWAPWithin(prices, DefaultCandleWindow)
+}
+
+// ComputeTVWAPWithin computes the time volume weighted average price for all
+// points for each exchange pair. Filters out any candles that did not occur
+// within the candle window. The provided prices argument reflects a mapping of
 // provider => {<base> => <TickerPrice>, ...}.
 //
 // Ref : https://en.wikipedia.org/wiki/Time-weighted_average_price
-func ComputeTVWAP(prices provider.AggregatedProviderCandles) (map[string]sdk.Dec, error) {
+func ComputeTVWAPWithin(prices provider.AggregatedProviderCandles, window CandleWindow) (map[string]sdk.Dec, error) {
 	var (
 		weightedPrices = make(map[string]sdk.Dec)
 		volumeSum      = make(map[string]sdk.Dec)
 		now            = provider.PastUnixTime(0)
-		timePeriod     = provider.PastUnixTime(tvwapCandlePeriod)
 	)
 
 	for _, providerPrices := range prices {
@@ -87,6 +161,16 @@ func ComputeTVWAP(prices provider.AggregatedProviderCandles) (map[string]sdk.Dec
 				continue
 			}
 
+			// Sort by timestamp old -> new
+			sort.SliceStable(cp, func(i, j int) bool {
+				return cp[i].TimeStamp < cp[j].TimeStamp
+			})
+
+			timePeriod, ok := window.start(now, cp)
+			if !ok {
+				continue
+			}
+
 			if _, ok := weightedPrices[base]; !ok {
 				weightedPrices[base] = sdk.ZeroDec()
 			}
@@ -94,32 +178,28 @@ func ComputeTVWAP(prices provider.AggregatedProviderCandles) (map[string]sdk.Dec
 				volumeSum[base] = sdk.ZeroDec()
 			}
 
-			// Sort by timestamp old -> new
-			sort.SliceStable(cp, func(i, j int) bool {
-				return cp[i].TimeStamp < cp[j].TimeStamp
-			})
-
 			period := sdk.NewDec(now - cp[0].TimeStamp)
 			if period.Equal(sdk.ZeroDec()) {
 				return nil, fmt.Errorf("unable to divide by zero")
 			}
-			// weightUnit = (1 - minimumTimeWeight) / period
-			weightUnit := sdk.OneDec().Sub(minimumTimeWeight).Quo(period)
+			floors := window.floors(base)
+			// weightUnit = (1 - floors.TimeWeight) / period
+			weightUnit := sdk.OneDec().Sub(floors.TimeWeight).Quo(period)
 
 			// get weighted prices, and sum of volumes
 			for _, candle := range cp {
-				// we only want candles within the last timePeriod
+				// we only want candles within the candle window
 				if timePeriod < candle.TimeStamp {
 					// timeDiff = now - candle.TimeStamp
 					timeDiff := sdk.NewDec(now - candle.TimeStamp)
 					// set minimum candle volume for low-trading assets
 					if candle.Volume.Equal(sdk.ZeroDec()) {
-						candle.Volume = minimumCandleVolume
+						candle.Volume = floors.Volume
 					}
 
-					// volume = candle.Volume * (weightUnit * (period - timeDiff) + minimumTimeWeight)
+					// volume = candle.Volume * (weightUnit * (period - timeDiff + floors.TimeWeight))
 					volume := candle.Volume.Mul(
-						weightUnit.Mul(period.Sub(timeDiff).Add(minimumTimeWeight)),
+						weightUnit.Mul(period.Sub(timeDiff).Add(floors.TimeWeight)),
 					)
 					volumeSum[base] = volumeSum[base].Add(volume)
 					weightedPrices[base] = weightedPrices[base].Add(candle.Price.Mul(volume))
@@ -188,13 +268,16 @@ func ComputeStandardDeviationsAndMeans(prices map[provider.Name]map[string]sdk.D
 
 // computeTvwapsByProvider computes the tvwap prices from candles for each provider separately and returns them
 // in a map separated by provider name.
-func computeTvwapsByProvider(prices provider.AggregatedProviderCandles) (map[provider.Name]map[string]sdk.Dec, error) {
+func computeTvwapsByProvider(
+	prices provider.AggregatedProviderCandles,
+	window CandleWindow,
+) (map[provider.Name]map[string]sdk.Dec, error) {
 	tvwaps := make(map[provider.Name]map[string]sdk.Dec)
 	var err error
 
 	for providerName, candles := range prices {
 		singleProviderCandles := provider.AggregatedProviderCandles{"providerName": candles}
-		tvwaps[providerName], err = ComputeTVWAP(singleProviderCandles)
+		tvwaps[providerName], err = ComputeTVWAPWithin(singleProviderCandles, window)
 		if err != nil {
 			return nil, err
 		}
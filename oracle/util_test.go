@@ -258,6 +258,66 @@ func TestComputeTVWAP(t *testing.T) {
 	}
 }
 
+func TestComputeTVWAPWithin(t *testing.T) {
+	candles := provider.AggregatedProviderCandles{
+		provider.Kraken: {
+			"XPRT": []types.CandlePrice{
+				{
+					Price:     sdk.MustNewDecFromStr("1.10"),
+					Volume:    sdk.OneDec(),
+					TimeStamp: provider.PastUnixTime(9 * time.Minute),
+				},
+				{
+					Price:     sdk.MustNewDecFromStr("1.20"),
+					Volume:    sdk.OneDec(),
+					TimeStamp: provider.PastUnixTime(7 * time.Minute),
+				},
+			},
+		},
+	}
+
+	// the latest candle is older than the default window
+	tvwap, err := oracle.ComputeTVWAP(candles)
+	require.NoError(t, err)
+	require.Empty(t, tvwap)
+
+	// the window ends at the latest candle, which excludes the older one
+	tvwap, err = oracle.ComputeTVWAPWithin(candles, oracle.CandleWindow{Period: time.Minute, MaxAge: 8 * time.Minute})
+	require.NoError(t, err)
+	require.InDelta(t, 1.2, tvwap["XPRT"].MustFloat64(), 1e-9)
+
+	tvwap, err = oracle.ComputeTVWAPWithin(candles, oracle.CandleWindow{Period: time.Minute, MaxAge: 6 * time.Minute})
+	require.NoError(t, err)
+	require.Empty(t, tvwap)
+}
+
+func TestComputeTVWAPWithinFloors(t *testing.T) {
+	pairCandles := []types.CandlePrice{
+		{Price: sdk.NewDec(2), Volume: sdk.OneDec(), TimeStamp: provider.PastUnixTime(4 * time.Minute)},
+		{Price: sdk.OneDec(), Volume: sdk.ZeroDec(), TimeStamp: provider.PastUnixTime(2 * time.Minute)},
+		{Price: sdk.NewDec(2), Volume: sdk.OneDec(), TimeStamp: provider.PastUnixTime(2 * time.Minute)},
+	}
+	candles := provider.AggregatedProviderCandles{
+		provider.Kraken: {
+			"ATOM": append([]types.CandlePrice{}, pairCandles...),
+			"XPRT": append([]types.CandlePrice{}, pairCandles...),
+		},
+	}
+
+	tvwap, err := oracle.ComputeTVWAP(candles)
+	require.NoError(t, err)
+	require.InDelta(t, 2, tvwap["XPRT"].MustFloat64(), 1e-3)
+
+	// the floor volume of XPRT weighs its volume-less candle as much as the
+	// other candle of the same age
+	window := oracle.DefaultCandleWindow
+	window.AssetFloors = map[string]oracle.CandleFloors{"XPRT": {Volume: sdk.OneDec()}}
+	tvwap, err = oracle.ComputeTVWAPWithin(candles, window)
+	require.NoError(t, err)
+	require.InDelta(t, 1.5, tvwap["XPRT"].MustFloat64(), 1e-3)
+	require.InDelta(t, 2, tvwap["ATOM"].MustFloat64(), 1e-3)
+}
+
 //nolint:funlen //test
 func TestStandardDeviation(t *testing.T) {
 	type deviation struct {
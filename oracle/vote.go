@@ -0,0 +1,121 @@
+package oracle
+
+import "math"
+
+// VoteState defines the state of the oracle in the commit-reveal voting of
+// the x/oracle module, where the pre-vote committed to in a vote period is
+// revealed by the vote of the following period.
+type VoteState string
+
+// Vote states.
+const (
+	// VoteStateIdle is the state without a pending pre-vote, in which the next
+	// pre-vote is submitted.
+	VoteStateIdle VoteState = "idle"
+	// VoteStatePrevoteSubmitted is the state after submitting a pre-vote and
+	// until the vote period following it.
+	VoteStatePrevoteSubmitted VoteState = "prevote_submitted"
+	// VoteStateAwaitingReveal is the state in the vote period following the
+	// pre-vote, in which the vote revealing it is submitted.
+	VoteStateAwaitingReveal VoteState = "awaiting_reveal"
+	// VoteStateMissed is the state once the vote period following the pre-vote
+	// ended without a vote. The pre-vote is discarded.
+	VoteStateMissed VoteState = "missed"
+)
+
+// VoteTick defines what a tick must do in the commit-reveal voting.
+type VoteTick struct {
+	State VoteState
+	// Broadcast is set when the pre-vote (idle state) or the vote (awaiting
+	// reveal state) must be broadcasted by the tick.
+	Broadcast bool
+	// VotePeriod is the vote period of the next block.
+	VotePeriod uint64
+	// WindowEnd is the last block of VotePeriod, by which a broadcast must be
+	// included to count for it.
+	WindowEnd int64
+	// MissedVotePeriod is the vote period whose vote was missed, in the missed
+	// state.
+	MissedVotePeriod uint64
+}
+
+// VoteMachine tracks the pre-vote awaiting its reveal and decides, for every
+// tick, whether to submit a pre-vote, a vote or nothing. It must only be used
+// from the tick goroutine.
+type VoteMachine struct {
+	prevote       *PreviousPrevote
+	prevotePeriod float64
+}
+
+// NewVoteMachine returns a VoteMachine resuming with prevote submitted in the
+// given vote period, or idle if prevote is nil.
+func NewVoteMachine(prevote *PreviousPrevote, prevotePeriod float64) VoteMachine {
+	if prevote == nil {
+		return VoteMachine{}
+	}
+
+	return VoteMachine{
+		prevote:       prevote,
+		prevotePeriod: prevotePeriod,
+	}
+}
+
+// Prevote returns the pre-vote awaiting its reveal, or nil if idle.
+func (vm *VoteMachine) Prevote() *PreviousPrevote {
+	return vm.prevote
+}
+
+// PrevotePeriod returns the vote period the pending pre-vote was submitted
+// in, or zero if idle.
+func (vm *VoteMachine) PrevotePeriod() float64 {
+	return vm.prevotePeriod
+}
+
+// Next returns what the tick preparing the block at nextBlockHeight must do.
+// The pending pre-vote is discarded once its reveal period is missed.
+func (vm *VoteMachine) Next(nextBlockHeight, oracleVotePeriod int64) VoteTick {
+	currentVotePeriod := math.Floor(float64(nextBlockHeight) / float64(oracleVotePeriod))
+	indexInVotePeriod := nextBlockHeight % oracleVotePeriod
+	tick := VoteTick{
+		VotePeriod: uint64(currentVotePeriod),
+		WindowEnd:  (int64(currentVotePeriod)+1)*oracleVotePeriod - 1,
+	}
+
+	// Transactions broadcasted on the last block of a vote period are not
+	// included before the period ends, so the window closes one block early.
+	windowOpen := oracleVotePeriod-indexInVotePeriod >= 2
+
+	switch {
+	case vm.prevote == nil:
+		tick.State = VoteStateIdle
+		tick.Broadcast = windowOpen
+
+	case currentVotePeriod == vm.prevotePeriod:
+		tick.State = VoteStatePrevoteSubmitted
+
+	case currentVotePeriod-vm.prevotePeriod == 1:
+		tick.State = VoteStateAwaitingReveal
+		tick.Broadcast = windowOpen
+
+	default:
+		// past the vote period we needed to hit, start over with a pre-vote
+		tick.State = VoteStateMissed
+		tick.MissedVotePeriod = uint64(vm.prevotePeriod) + 1
+		vm.Reset()
+	}
+
+	return tick
+}
+
+// PrevoteSubmitted records prevote, which was included in the block at
+// height, as awaiting its reveal.
+func (vm *VoteMachine) PrevoteSubmitted(prevote PreviousPrevote, height, oracleVotePeriod int64) {
+	vm.prevote = &prevote
+	vm.prevotePeriod = math.Floor(float64(height) / float64(oracleVotePeriod))
+}
+
+// Reset discards the pending pre-vote, once revealed or missed.
+func (vm *VoteMachine) Reset() {
+	vm.prevote = nil
+	vm.prevotePeriod = 0
+}
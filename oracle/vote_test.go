@@ -0,0 +1,115 @@
+package oracle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVoteMachineNext(t *testing.T) {
+	const votePeriod = 10
+	prevote := &PreviousPrevote{ExchangeRates: "ATOM:10.000000000000000000", Salt: "salt", SubmitBlockHeight: 21}
+
+	testCases := map[string]struct {
+		prevote         *PreviousPrevote
+		prevotePeriod   float64
+		nextBlockHeight int64
+		expected        VoteTick
+		expectedPending bool
+	}{
+		"idle at the start of a period": {
+			nextBlockHeight: 20,
+			expected:        VoteTick{State: VoteStateIdle, Broadcast: true, VotePeriod: 2, WindowEnd: 29},
+		},
+		"idle two blocks before the end of a period": {
+			nextBlockHeight: 28,
+			expected:        VoteTick{State: VoteStateIdle, Broadcast: true, VotePeriod: 2, WindowEnd: 29},
+		},
+		"idle on the last block of a period": {
+			nextBlockHeight: 29,
+			expected:        VoteTick{State: VoteStateIdle, VotePeriod: 2, WindowEnd: 29},
+		},
+		"prevote submitted in the current period": {
+			prevote:         prevote,
+			prevotePeriod:   2,
+			nextBlockHeight: 25,
+			expected:        VoteTick{State: VoteStatePrevoteSubmitted, VotePeriod: 2, WindowEnd: 29},
+			expectedPending: true,
+		},
+		"reveal at the start of the next period": {
+			prevote:         prevote,
+			prevotePeriod:   2,
+			nextBlockHeight: 30,
+			expected:        VoteTick{State: VoteStateAwaitingReveal, Broadcast: true, VotePeriod: 3, WindowEnd: 39},
+			expectedPending: true,
+		},
+		"reveal two blocks before the end of the next period": {
+			prevote:         prevote,
+			prevotePeriod:   2,
+			nextBlockHeight: 38,
+			expected:        VoteTick{State: VoteStateAwaitingReveal, Broadcast: true, VotePeriod: 3, WindowEnd: 39},
+			expectedPending: true,
+		},
+		"reveal on the last block of the next period": {
+			prevote:         prevote,
+			prevotePeriod:   2,
+			nextBlockHeight: 39,
+			expected:        VoteTick{State: VoteStateAwaitingReveal, VotePeriod: 3, WindowEnd: 39},
+			expectedPending: true,
+		},
+		"reveal period missed": {
+			prevote:         prevote,
+			prevotePeriod:   2,
+			nextBlockHeight: 40,
+			expected:        VoteTick{State: VoteStateMissed, VotePeriod: 4, WindowEnd: 49, MissedVotePeriod: 3},
+		},
+		"restarted several periods later": {
+			prevote:         prevote,
+			prevotePeriod:   2,
+			nextBlockHeight: 95,
+			expected:        VoteTick{State: VoteStateMissed, VotePeriod: 9, WindowEnd: 99, MissedVotePeriod: 3},
+		},
+		"restarted with a prevote from the first period": {
+			prevote:         prevote,
+			prevotePeriod:   0,
+			nextBlockHeight: 5,
+			expected:        VoteTick{State: VoteStatePrevoteSubmitted, VotePeriod: 0, WindowEnd: 9},
+			expectedPending: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			vm := NewVoteMachine(tc.prevote, tc.prevotePeriod)
+
+			require.Equal(t, tc.expected, vm.Next(tc.nextBlockHeight, votePeriod))
+			require.Equal(t, tc.expectedPending, vm.Prevote() != nil)
+		})
+	}
+}
+
+func TestVoteMachineCycle(t *testing.T) {
+	const votePeriod = 5
+	var vm VoteMachine
+
+	tick := vm.Next(11, votePeriod)
+	require.Equal(t, VoteStateIdle, tick.State)
+	require.True(t, tick.Broadcast)
+
+	// the pre-vote is included in the block following the one it was prepared for
+	vm.PrevoteSubmitted(PreviousPrevote{Salt: "salt", SubmitBlockHeight: 12}, 12, votePeriod)
+	require.Equal(t, float64(2), vm.PrevotePeriod())
+	require.Equal(t, VoteStatePrevoteSubmitted, vm.Next(13, votePeriod).State)
+
+	tick = vm.Next(15, votePeriod)
+	require.Equal(t, VoteStateAwaitingReveal, tick.State)
+	require.True(t, tick.Broadcast)
+	require.Equal(t, "salt", vm.Prevote().Salt)
+
+	vm.Reset()
+	require.Nil(t, vm.Prevote())
+	require.Zero(t, vm.PrevotePeriod())
+	require.Equal(t, VoteStateIdle, vm.Next(16, votePeriod).State)
+}
@@ -0,0 +1,192 @@
+package oracle
+
+import (
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+var (
+	// weightBiasSmoothing is the smoothing factor of the exponential moving
+	// average of a provider's relative deviation from the final price.
+	weightBiasSmoothing = sdk.MustNewDecFromStr("0.1")
+
+	// weightBiasTolerance is the average relative deviation from the final
+	// price above which a provider is considered biased.
+	weightBiasTolerance = sdk.MustNewDecFromStr("0.005")
+
+	// weightDecayFactor is applied to the weight of a biased provider on every
+	// update, and its inverse to the weight of an unbiased one until it is
+	// fully recovered.
+	weightDecayFactor = sdk.MustNewDecFromStr("0.95")
+
+	// minProviderWeight is the lowest weight a provider can decay to, so that
+	// it keeps contributing and is able to recover.
+	minProviderWeight = sdk.MustNewDecFromStr("0.1")
+)
+
+type (
+	// ProviderWeights learns an aggregation weight per provider and asset from
+	// the provider's historical deviation from the final computed price. The
+	// weight of a provider which is persistently biased decays, which scales
+	// down the volume its prices contribute to the TVWAP and VWAP.
+	ProviderWeights struct {
+		mtx     sync.RWMutex
+		weights map[provider.Name]map[string]ProviderWeight
+	}
+
+	// ProviderWeight defines the learned weight of a provider for an asset and
+	// the average relative deviation of its prices it was derived from.
+	ProviderWeight struct {
+		Weight sdk.Dec
+		Bias   sdk.Dec
+	}
+)
+
+// NewProviderWeights returns weights where every provider starts with a
+// weight of one.
+func NewProviderWeights() *ProviderWeights {
+	return &ProviderWeights{
+		weights: make(map[provider.Name]map[string]ProviderWeight),
+	}
+}
+
+// Get returns the current weight of a provider for the given asset.
+func (pw *ProviderWeights) Get(providerName provider.Name, base string) sdk.Dec {
+	pw.mtx.RLock()
+	defer pw.mtx.RUnlock()
+
+	return pw.get(providerName, base).Weight
+}
+
+// All returns a copy of the learned weights.
+func (pw *ProviderWeights) All() map[provider.Name]map[string]ProviderWeight {
+	pw.mtx.RLock()
+	defer pw.mtx.RUnlock()
+
+	all := make(map[provider.Name]map[string]ProviderWeight, len(pw.weights))
+	for providerName, weights := range pw.weights {
+		all[providerName] = make(map[string]ProviderWeight, len(weights))
+		for base, weight := range weights {
+			all[providerName][base] = weight
+		}
+	}
+
+	return all
+}
+
+// Restore replaces the learned weights with the given ones, e.g. persisted by
+// a previous run.
+func (pw *ProviderWeights) Restore(weights map[provider.Name]map[string]ProviderWeight) {
+	pw.mtx.Lock()
+	defer pw.mtx.Unlock()
+
+	pw.weights = make(map[provider.Name]map[string]ProviderWeight, len(weights))
+	for providerName, baseWeights := range weights {
+		pw.weights[providerName] = make(map[string]ProviderWeight, len(baseWeights))
+		for base, weight := range baseWeights {
+			pw.weights[providerName][base] = weight
+		}
+	}
+}
+
+// Reset forgets all learned weights.
+func (pw *ProviderWeights) Reset() {
+	pw.mtx.Lock()
+	defer pw.mtx.Unlock()
+
+	pw.weights = make(map[provider.Name]map[string]ProviderWeight)
+}
+
+// Update updates the bias of every provider with the deviation of its price
+// from the final price of the asset and decays or recovers its weight
+// accordingly.
+func (pw *ProviderWeights) Update(providerPrices PricesByProvider, prices map[string]sdk.Dec) {
+	pw.mtx.Lock()
+	defer pw.mtx.Unlock()
+
+	for providerName, basePrices := range providerPrices {
+		for base, price := range basePrices {
+			finalPrice, ok := prices[base]
+			if !ok || !finalPrice.IsPositive() {
+				continue
+			}
+
+			current := pw.get(providerName, base)
+			deviation := price.Sub(finalPrice).Quo(finalPrice)
+
+			// bias = bias * (1 - smoothing) + deviation * smoothing
+			bias := current.Bias.Mul(sdk.OneDec().Sub(weightBiasSmoothing)).
+				Add(deviation.Mul(weightBiasSmoothing))
+
+			weight := current.Weight
+			if bias.Abs().GT(weightBiasTolerance) {
+				weight = sdk.MaxDec(weight.Mul(weightDecayFactor), minProviderWeight)
+			} else {
+				weight = sdk.MinDec(weight.Quo(weightDecayFactor), sdk.OneDec())
+			}
+
+			if _, ok := pw.weights[providerName]; !ok {
+				pw.weights[providerName] = make(map[string]ProviderWeight)
+			}
+			pw.weights[providerName][base] = ProviderWeight{Weight: weight, Bias: bias}
+		}
+	}
+}
+
+// ApplyToCandles returns a copy of the candles with their volumes scaled by
+// the weight of their provider.
+func (pw *ProviderWeights) ApplyToCandles(
+	candles provider.AggregatedProviderCandles,
+) provider.AggregatedProviderCandles {
+	pw.mtx.RLock()
+	defer pw.mtx.RUnlock()
+
+	weighted := make(provider.AggregatedProviderCandles, len(candles))
+	for providerName, baseCandles := range candles {
+		weighted[providerName] = make(map[string][]types.CandlePrice, len(baseCandles))
+		for base, cp := range baseCandles {
+			weight := pw.get(providerName, base).Weight
+
+			weightedCandles := make([]types.CandlePrice, len(cp))
+			for i, candle := range cp {
+				candle.Volume = candle.Volume.Mul(weight)
+				weightedCandles[i] = candle
+			}
+			weighted[providerName][base] = weightedCandles
+		}
+	}
+
+	return weighted
+}
+
+// ApplyToTickers returns a copy of the tickers with their volumes scaled by
+// the weight of their provider.
+func (pw *ProviderWeights) ApplyToTickers(
+	tickers provider.AggregatedProviderPrices,
+) provider.AggregatedProviderPrices {
+	pw.mtx.RLock()
+	defer pw.mtx.RUnlock()
+
+	weighted := make(provider.AggregatedProviderPrices, len(tickers))
+	for providerName, baseTickers := range tickers {
+		weighted[providerName] = make(map[string]types.TickerPrice, len(baseTickers))
+		for base, tp := range baseTickers {
+			tp.Volume = tp.Volume.Mul(pw.get(providerName, base).Weight)
+			weighted[providerName][base] = tp
+		}
+	}
+
+	return weighted
+}
+
+func (pw *ProviderWeights) get(providerName provider.Name, base string) ProviderWeight {
+	if weight, ok := pw.weights[providerName][base]; ok {
+		return weight
+	}
+
+	return ProviderWeight{Weight: sdk.OneDec(), Bias: sdk.ZeroDec()}
+}
@@ -0,0 +1,67 @@
+package oracle
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+	"github.com/persistenceOne/oracle-feeder/oracle/types"
+)
+
+func TestProviderWeights(t *testing.T) {
+	weights := NewProviderWeights()
+	require.Equal(t, sdk.OneDec(), weights.Get(provider.Binance, "ATOM"))
+
+	final := map[string]sdk.Dec{"ATOM": sdk.MustNewDecFromStr("10")}
+	providerPrices := PricesByProvider{
+		provider.Binance: {"ATOM": sdk.MustNewDecFromStr("10.01")},
+		provider.Kraken:  {"ATOM": sdk.MustNewDecFromStr("11")},
+	}
+
+	for i := 0; i < 100; i++ {
+		weights.Update(providerPrices, final)
+	}
+
+	// a persistently biased provider decays to the minimum weight while an
+	// accurate one keeps its full weight
+	require.Equal(t, sdk.OneDec(), weights.Get(provider.Binance, "ATOM"))
+	require.Equal(t, minProviderWeight, weights.Get(provider.Kraken, "ATOM"))
+
+	// the weight recovers once the provider is accurate again
+	providerPrices[provider.Kraken]["ATOM"] = final["ATOM"]
+	for i := 0; i < 100; i++ {
+		weights.Update(providerPrices, final)
+	}
+	require.Equal(t, sdk.OneDec(), weights.Get(provider.Kraken, "ATOM"))
+
+	weights.Update(PricesByProvider{provider.Kraken: {"ATOM": sdk.MustNewDecFromStr("20")}}, final)
+	require.True(t, weights.Get(provider.Kraken, "ATOM").LT(sdk.OneDec()))
+	weights.Reset()
+	require.Equal(t, sdk.OneDec(), weights.Get(provider.Kraken, "ATOM"))
+}
+
+func TestProviderWeightsApply(t *testing.T) {
+	weights := NewProviderWeights()
+	weights.weights[provider.Kraken] = map[string]ProviderWeight{
+		"ATOM": {Weight: sdk.MustNewDecFromStr("0.5"), Bias: sdk.ZeroDec()},
+	}
+
+	tickers := provider.AggregatedProviderPrices{
+		provider.Kraken:  {"ATOM": types.TickerPrice{Price: sdk.OneDec(), Volume: sdk.NewDec(10)}},
+		provider.Binance: {"ATOM": types.TickerPrice{Price: sdk.OneDec(), Volume: sdk.NewDec(10)}},
+	}
+	weighted := weights.ApplyToTickers(tickers)
+	require.Equal(t, sdk.NewDec(5), weighted[provider.Kraken]["ATOM"].Volume)
+	require.Equal(t, sdk.NewDec(10), weighted[provider.Binance]["ATOM"].Volume)
+	// the input is left untouched
+	require.Equal(t, sdk.NewDec(10), tickers[provider.Kraken]["ATOM"].Volume)
+
+	candles := provider.AggregatedProviderCandles{
+		provider.Kraken: {"ATOM": []types.CandlePrice{{Price: sdk.OneDec(), Volume: sdk.NewDec(10)}}},
+	}
+	weightedCandles := weights.ApplyToCandles(candles)
+	require.Equal(t, sdk.NewDec(5), weightedCandles[provider.Kraken]["ATOM"][0].Volume)
+	require.Equal(t, sdk.NewDec(10), candles[provider.Kraken]["ATOM"][0].Volume)
+}
@@ -0,0 +1,143 @@
+// Package archive stores documents outside of the feeder host, on IPFS or S3,
+// so that they can be verified by third parties later on.
+package archive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+)
+
+// Store stores a named document and returns its location, e.g. an IPFS CID.
+type Store interface {
+	Put(ctx context.Context, name string, data []byte) (string, error)
+}
+
+// IPFSStore adds documents to IPFS through the HTTP RPC API of a node, e.g.
+// Kubo, which pins them.
+//
+// REF: https://docs.ipfs.tech/reference/kubo/rpc/#api-v0-add
+type IPFSStore struct {
+	apiURL string
+	client *http.Client
+}
+
+// NewIPFSStore returns a store adding to the node whose RPC API is served at
+// apiURL, e.g. "http://127.0.0.1:5001".
+func NewIPFSStore(apiURL string, timeout time.Duration) *IPFSStore {
+	return &IPFSStore{
+		apiURL: strings.TrimSuffix(apiURL, "/"),
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Put adds and pins the document and returns its "ipfs://<cid>" location.
+func (s *IPFSStore) Put(ctx context.Context, name string, data []byte) (string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", name)
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", err
+	}
+	if err := form.Close(); err != nil {
+		return "", err
+	}
+
+	reqURL := s.apiURL + "/api/v0/add?" + url.Values{"pin": {"true"}, "cid-version": {"1"}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("ipfs node responded with status %d", resp.StatusCode)
+	}
+
+	var added struct {
+		Hash string `json:"Hash"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&added); err != nil {
+		return "", fmt.Errorf("failed to decode ipfs response: %w", err)
+	}
+	if len(added.Hash) == 0 {
+		return "", fmt.Errorf("ipfs node returned no CID")
+	}
+
+	return "ipfs://" + added.Hash, nil
+}
+
+// S3Store puts documents into a bucket of S3, or of any service compatible
+// with its API, under a key prefix.
+type S3Store struct {
+	endpoint string
+	region   string
+	bucket   string
+	prefix   string
+	signer   *v4.Signer
+	client   *http.Client
+}
+
+// NewS3Store returns a store putting into bucket, through the path-style API
+// at endpoint, e.g. "https://s3.eu-west-1.amazonaws.com", with requests
+// signed with the given access key.
+func NewS3Store(
+	endpoint, region, bucket, prefix, accessKey, secretKey string,
+	timeout time.Duration,
+) *S3Store {
+	return &S3Store{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		region:   region,
+		bucket:   bucket,
+		prefix:   prefix,
+		signer:   v4.NewSigner(credentials.NewStaticCredentials(accessKey, secretKey, "")),
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+// Put puts the document under the prefix and returns its "s3://<bucket>/<key>"
+// location.
+func (s *S3Store) Put(ctx context.Context, name string, data []byte) (string, error) {
+	key := s.prefix + name
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.endpoint+"/"+s.bucket+"/"+key, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if _, err := s.signer.Sign(req, bytes.NewReader(data), "s3", s.region, time.Now()); err != nil {
+		return "", fmt.Errorf("failed to sign s3 request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bz, _ := io.ReadAll(io.LimitReader(resp.Body, 1024)) //nolint:gomnd //error excerpt
+		return "", fmt.Errorf("s3 responded with status %d: %s", resp.StatusCode, bz)
+	}
+
+	return "s3://" + s.bucket + "/" + key, nil
+}
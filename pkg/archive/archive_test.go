@@ -0,0 +1,68 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPFSStore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		require.Equal(t, http.MethodPost, req.Method)
+		require.Equal(t, "/api/v0/add", req.URL.Path)
+		require.Equal(t, "true", req.URL.Query().Get("pin"))
+
+		file, header, err := req.FormFile("file")
+		require.NoError(t, err)
+		require.Equal(t, "attestation.json", header.Filename)
+		bz, err := io.ReadAll(file)
+		require.NoError(t, err)
+		require.Equal(t, `{"a":1}`, string(bz))
+
+		fmt.Fprint(rw, `{"Name":"attestation.json","Hash":"bafkreitest","Size":"7"}`)
+	}))
+	defer server.Close()
+
+	store := NewIPFSStore(server.URL+"/", time.Second)
+	location, err := store.Put(context.Background(), "attestation.json", []byte(`{"a":1}`))
+	require.NoError(t, err)
+	require.Equal(t, "ipfs://bafkreitest", location)
+
+	server.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	})
+	_, err = store.Put(context.Background(), "attestation.json", nil)
+	require.EqualError(t, err, "ipfs node responded with status 500")
+}
+
+func TestS3Store(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		require.Equal(t, http.MethodPut, req.Method)
+		require.Equal(t, "/bucket/feeder/attestation.json", req.URL.Path)
+		require.True(t, strings.HasPrefix(req.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+		require.Contains(t, req.Header.Get("Authorization"), "/eu-west-1/s3/aws4_request")
+
+		bz, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		if string(bz) != `{"a":1}` {
+			rw.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(rw, "<Error><Code>AccessDenied</Code></Error>")
+		}
+	}))
+	defer server.Close()
+
+	store := NewS3Store(server.URL, "eu-west-1", "bucket", "feeder/", "AKID", "secret", time.Second)
+	location, err := store.Put(context.Background(), "attestation.json", []byte(`{"a":1}`))
+	require.NoError(t, err)
+	require.Equal(t, "s3://bucket/feeder/attestation.json", location)
+
+	_, err = store.Put(context.Background(), "attestation.json", []byte(`{}`))
+	require.EqualError(t, err, "s3 responded with status 403: <Error><Code>AccessDenied</Code></Error>")
+}
@@ -0,0 +1,176 @@
+// Package audit records every use of the feeder key, so that operators can
+// detect unexpected key usage from the feeder host.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	dirPerm  = 0o700
+	filePerm = 0o600
+)
+
+// Operations of the feeder key.
+const (
+	OperationSignTx      = "sign_tx"
+	OperationSignMessage = "sign_message"
+)
+
+type (
+	// Entry defines a single signing operation. TxHash is only set for
+	// transactions and MessageHash, the hex encoded SHA-256 of the signed
+	// bytes, only for arbitrary messages.
+	Entry struct {
+		Time        time.Time `json:"time"`
+		Operation   string    `json:"operation"`
+		Signer      string    `json:"signer"`
+		MsgTypes    []string  `json:"msg_types,omitempty"`
+		Height      int64     `json:"height"`
+		TxHash      string    `json:"tx_hash,omitempty"`
+		MessageHash string    `json:"message_hash,omitempty"`
+	}
+
+	// Sink persists audit entries outside of the feeder logs.
+	Sink interface {
+		Write(ctx context.Context, entry Entry) error
+	}
+
+	// Trail logs every entry and writes it to its sinks. Failing sinks are
+	// logged but never fail the signing operation.
+	Trail struct {
+		logger zerolog.Logger
+		sinks  []Sink
+	}
+)
+
+// New returns a trail logging to logger and writing to sinks.
+func New(logger zerolog.Logger, sinks ...Sink) *Trail {
+	return &Trail{
+		logger: logger.With().Str("module", "audit").Logger(),
+		sinks:  sinks,
+	}
+}
+
+// Record logs the entry and writes it to the sinks. It is a no-op on a nil
+// Trail.
+func (t *Trail) Record(ctx context.Context, entry Entry) {
+	if t == nil {
+		return
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+
+	t.logger.Info().
+		Str("operation", entry.Operation).
+		Str("signer", entry.Signer).
+		Strs("msg_types", entry.MsgTypes).
+		Int64("height", entry.Height).
+		Str("tx_hash", entry.TxHash).
+		Str("message_hash", entry.MessageHash).
+		Msg("feeder key used")
+
+	for _, sink := range t.sinks {
+		if err := sink.Write(ctx, entry); err != nil {
+			t.logger.Error().Err(err).Str("operation", entry.Operation).Msg("failed to write audit entry")
+		}
+	}
+}
+
+// FileSink appends entries as JSON lines to a file.
+type FileSink struct {
+	mtx  sync.Mutex
+	path string
+}
+
+// NewFileSink returns a sink appending to the file at path, which is created
+// along with its parent directories if missing.
+func NewFileSink(path string) (*FileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), dirPerm); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, filePerm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file: %w", err)
+	}
+
+	return &FileSink{path: path}, f.Close()
+}
+
+// Write appends entry to the file and syncs it.
+func (fs *FileSink) Write(_ context.Context, entry Entry) error {
+	bz, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	fs.mtx.Lock()
+	defer fs.mtx.Unlock()
+
+	f, err := os.OpenFile(fs.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, filePerm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(bz, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// HTTPSink posts entries as JSON to an audit service.
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPSink returns a sink posting to url with the given timeout.
+func NewHTTPSink(url string, timeout time.Duration) *HTTPSink {
+	return &HTTPSink{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Write posts entry to the audit service, which must answer with a 2xx status.
+func (hs *HTTPSink) Write(ctx context.Context, entry Entry) error {
+	bz, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hs.url, bytes.NewReader(bz))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := hs.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("audit service responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
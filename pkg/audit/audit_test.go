@@ -0,0 +1,65 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.jsonl")
+	fileSink, err := NewFileSink(path)
+	require.NoError(t, err)
+
+	posted := make(chan Entry, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var entry Entry
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&entry))
+		posted <- entry
+	}))
+	defer srv.Close()
+
+	trail := New(zerolog.Nop(), fileSink, NewHTTPSink(srv.URL, time.Second))
+	trail.Record(context.Background(), Entry{
+		Operation: OperationSignTx,
+		Signer:    "persistence1feeder",
+		MsgTypes:  []string{"/persistence.oracle.v1beta1.MsgAggregateExchangeRateVote"},
+		Height:    42,
+		TxHash:    "ABCD",
+	})
+	trail.Record(context.Background(), Entry{Operation: OperationSignMessage, Height: 43, MessageHash: "ef01"})
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry Entry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	require.Len(t, entries, 2)
+	require.Equal(t, "ABCD", entries[0].TxHash)
+	require.Equal(t, int64(42), entries[0].Height)
+	require.False(t, entries[0].Time.IsZero())
+	require.Equal(t, OperationSignMessage, entries[1].Operation)
+
+	require.Equal(t, "ABCD", (<-posted).TxHash)
+	require.Equal(t, "ef01", (<-posted).MessageHash)
+}
+
+func TestNilTrail(t *testing.T) {
+	var trail *Trail
+	require.NotPanics(t, func() { trail.Record(context.Background(), Entry{Operation: OperationSignTx}) })
+}
@@ -0,0 +1,126 @@
+// Package features implements the feature flags gating the experimental
+// subsystems of the price-feeder, which operators can toggle per instance at
+// runtime without rebuilding.
+package features
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Flag defines the name of a feature flag.
+type Flag string
+
+// Feature flags.
+const (
+	// ProviderWeights applies the learned provider weights when aggregating
+	// the provider prices. The weights are learned either way.
+	ProviderWeights Flag = "provider_weights"
+
+	// AsyncBroadcast broadcasts the pre-votes and votes in the async mode,
+	// confirming their inclusion in the background, whatever the configured
+	// broadcast mode.
+	AsyncBroadcast Flag = "async_broadcast"
+
+	// StandbyObservation has a standby instance, i.e. one whose voting is
+	// paused, observe the pre-votes of the active voter.
+	StandbyObservation Flag = "standby_observation"
+)
+
+// Defaults holds every known flag with the state it has unless configured
+// otherwise, which preserves the behaviour of the instances predating it.
+var Defaults = map[Flag]bool{
+	ProviderWeights:    true,
+	AsyncBroadcast:     false,
+	StandbyObservation: true,
+}
+
+// Flags holds the state of the feature flags of an instance. A nil Flags
+// reports the defaults.
+type Flags struct {
+	mtx     sync.RWMutex
+	enabled map[Flag]bool
+}
+
+// Validate returns an error if any of the configured flags is unknown.
+func Validate(configured map[string]bool) error {
+	for name := range configured {
+		if _, ok := Defaults[Flag(name)]; !ok {
+			return unknownFlagError(name)
+		}
+	}
+
+	return nil
+}
+
+// New returns the flags in their default state overridden by the configured
+// ones, which must be known.
+func New(configured map[string]bool) (*Flags, error) {
+	if err := Validate(configured); err != nil {
+		return nil, err
+	}
+
+	enabled := make(map[Flag]bool, len(Defaults))
+	for flag, state := range Defaults {
+		enabled[flag] = state
+	}
+	for name, state := range configured {
+		enabled[Flag(name)] = state
+	}
+
+	return &Flags{enabled: enabled}, nil
+}
+
+// Enabled returns whether the flag is enabled.
+func (f *Flags) Enabled(flag Flag) bool {
+	if f == nil {
+		return Defaults[flag]
+	}
+
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+
+	return f.enabled[flag]
+}
+
+// Set enables or disables the known flag until the instance restarts.
+func (f *Flags) Set(flag Flag, enabled bool) error {
+	if _, ok := Defaults[flag]; !ok {
+		return unknownFlagError(string(flag))
+	}
+
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	f.enabled[flag] = enabled
+	return nil
+}
+
+// All returns a copy of the state of every flag.
+func (f *Flags) All() map[Flag]bool {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+
+	all := make(map[Flag]bool, len(f.enabled))
+	for flag, enabled := range f.enabled {
+		all[flag] = enabled
+	}
+
+	return all
+}
+
+func unknownFlagError(name string) error {
+	return fmt.Errorf("unknown feature flag %q, expected one of %v", name, Names())
+}
+
+// Names returns the sorted names of the known flags.
+func Names() []string {
+	names := make([]string, 0, len(Defaults))
+	for flag := range Defaults {
+		names = append(names, string(flag))
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,37 @@
+package features_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/pkg/features"
+)
+
+func TestFlags(t *testing.T) {
+	// a nil Flags reports the defaults
+	var flags *features.Flags
+	require.True(t, flags.Enabled(features.ProviderWeights))
+	require.False(t, flags.Enabled(features.AsyncBroadcast))
+
+	flags, err := features.New(map[string]bool{"async_broadcast": true})
+	require.NoError(t, err)
+	require.True(t, flags.Enabled(features.AsyncBroadcast))
+	require.True(t, flags.Enabled(features.StandbyObservation))
+
+	require.NoError(t, flags.Set(features.ProviderWeights, false))
+	require.False(t, flags.Enabled(features.ProviderWeights))
+	require.Equal(t, map[features.Flag]bool{
+		features.ProviderWeights:    false,
+		features.AsyncBroadcast:     true,
+		features.StandbyObservation: true,
+	}, flags.All())
+
+	require.EqualError(
+		t,
+		flags.Set("ha", true),
+		`unknown feature flag "ha", expected one of [async_broadcast provider_weights standby_observation]`,
+	)
+	_, err = features.New(map[string]bool{"ha": true})
+	require.Error(t, err)
+}
@@ -0,0 +1,37 @@
+package httputil
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+)
+
+type connContextKey struct{}
+
+// ConnContext stores the connection a request is served on in the request
+// context. It is meant to be set as the ConnContext of a http.Server, see
+// ExtendWriteDeadline.
+func ConnContext(ctx context.Context, c net.Conn) context.Context {
+	return context.WithValue(ctx, connContextKey{}, c)
+}
+
+// ExtendWriteDeadline sets the write deadline of the connection req is
+// served on to timeout from now, or clears it if timeout is zero, so that a
+// long-lived response such as an event stream isn't cut by the write timeout
+// of the server, which then bounds each of its writes instead. The server
+// resets the deadline for the next request on the connection. It returns
+// false if the server didn't store the connection with ConnContext.
+func ExtendWriteDeadline(req *http.Request, timeout time.Duration) bool {
+	conn, ok := req.Context().Value(connContextKey{}).(net.Conn)
+	if !ok {
+		return false
+	}
+
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+
+	return conn.SetWriteDeadline(deadline) == nil
+}
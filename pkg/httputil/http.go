@@ -7,13 +7,45 @@ import (
 
 // Common HTTP methods and header values.
 const (
-	MethodGET = "GET"
+	MethodGET    = "GET"
+	MethodPOST   = "POST"
+	MethodDELETE = "DELETE"
 )
 
-// ErrResponse defines an HTTP error response.
-type ErrResponse struct {
-	Error string `json:"error"`
-}
+// ErrorCode defines a machine readable category of an API error.
+type ErrorCode string
+
+// API error codes.
+const (
+	ErrCodeBadRequest          ErrorCode = "BAD_REQUEST"
+	ErrCodeNotFound            ErrorCode = "NOT_FOUND"
+	ErrCodeMethodNotAllowed    ErrorCode = "METHOD_NOT_ALLOWED"
+	ErrCodeRequestTooLarge     ErrorCode = "REQUEST_TOO_LARGE"
+	ErrCodeInternal            ErrorCode = "INTERNAL"
+	ErrCodeNotReady            ErrorCode = "NOT_READY"
+	ErrCodeStalePrices         ErrorCode = "STALE_PRICES"
+	ErrCodeProviderUnavailable ErrorCode = "PROVIDER_UNAVAILABLE"
+	ErrCodeShuttingDown        ErrorCode = "SHUTTING_DOWN"
+	ErrCodeUnauthorized        ErrorCode = "UNAUTHORIZED"
+	ErrCodeConflict            ErrorCode = "CONFLICT"
+	ErrCodeTooManyRequests     ErrorCode = "TOO_MANY_REQUESTS"
+)
+
+type (
+	// ErrResponse defines an HTTP error response.
+	ErrResponse struct {
+		Error Error `json:"error"`
+	}
+
+	// Error defines the error envelope returned by every API error response.
+	// Details carries optional structured context, e.g. the timestamp of the
+	// last price sync for stale prices.
+	Error struct {
+		Code    ErrorCode              `json:"code"`
+		Message string                 `json:"message"`
+		Details map[string]interface{} `json:"details,omitempty"`
+	}
+)
 
 // RespondWithJSON provides an auxiliary function to return an HTTP response
 // with JSON content and an HTTP status code.
@@ -24,3 +56,36 @@ func RespondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	w.WriteHeader(code)
 	_, _ = w.Write(response)
 }
+
+// RespondWithError provides an auxiliary function to return an HTTP error
+// response wrapped in the ErrResponse envelope.
+func RespondWithError(
+	w http.ResponseWriter,
+	statusCode int,
+	errCode ErrorCode,
+	message string,
+	details map[string]interface{},
+) {
+	RespondWithJSON(w, statusCode, ErrResponse{
+		Error: Error{
+			Code:    errCode,
+			Message: message,
+			Details: details,
+		},
+	})
+}
+
+// NotFoundHandler returns a handler responding with a NOT_FOUND error.
+func NotFoundHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		RespondWithError(w, http.StatusNotFound, ErrCodeNotFound, "route not found", nil)
+	})
+}
+
+// MethodNotAllowedHandler returns a handler responding with a
+// METHOD_NOT_ALLOWED error.
+func MethodNotAllowedHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		RespondWithError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed", nil)
+	})
+}
@@ -0,0 +1,29 @@
+package jsonfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.json")
+
+	var out map[string]int
+	err := Read(path, &out)
+	require.True(t, errors.Is(err, os.ErrNotExist))
+
+	in := map[string]int{"votes": 3}
+	require.NoError(t, Write(path, in))
+	require.NoError(t, Read(path, &out))
+	require.Equal(t, in, out)
+
+	// overwriting leaves no temporary files behind
+	require.NoError(t, Write(path, map[string]int{"votes": 4}))
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
@@ -0,0 +1,135 @@
+package jsonpath
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Path defines a parsed JSONPath expression selecting a single value: the
+	// root $ followed by any number of child members, as .name, ['name'] or
+	// ["name"], and array elements, as [index], negative indexes counting
+	// from the end of the array. Wildcards, slices, filters and recursive
+	// descent aren't supported.
+	Path struct {
+		expr  string
+		steps []step
+	}
+
+	// step defines the selection of a member of an object, or of an element
+	// of an array when member is unset.
+	step struct {
+		member string
+		index  int
+	}
+)
+
+// Parse parses the JSONPath expression.
+func Parse(expr string) (Path, error) {
+	rest := strings.TrimSpace(expr)
+	if !strings.HasPrefix(rest, "$") {
+		return Path{}, fmt.Errorf("invalid JSONPath %q: must start with $", expr)
+	}
+	rest = rest[1:]
+
+	var steps []step
+	for len(rest) > 0 {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			member := rest[1 : end+1]
+			if len(member) == 0 {
+				return Path{}, fmt.Errorf("invalid JSONPath %q: empty member", expr)
+			}
+			steps = append(steps, step{member: member})
+			rest = rest[end+1:]
+
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return Path{}, fmt.Errorf("invalid JSONPath %q: unclosed bracket", expr)
+			}
+			selector := strings.TrimSpace(rest[1:end])
+			if len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') &&
+				selector[len(selector)-1] == selector[0] {
+				steps = append(steps, step{member: selector[1 : len(selector)-1]})
+			} else {
+				index, err := strconv.Atoi(selector)
+				if err != nil {
+					return Path{}, fmt.Errorf("invalid JSONPath %q: unsupported selector [%s]", expr, selector)
+				}
+				steps = append(steps, step{index: index})
+			}
+			rest = rest[end+1:]
+
+		default:
+			return Path{}, fmt.Errorf("invalid JSONPath %q: unexpected %q", expr, rest[0])
+		}
+	}
+
+	return Path{expr: expr, steps: steps}, nil
+}
+
+// MustParse parses the JSONPath expression, panicking if invalid.
+func MustParse(expr string) Path {
+	path, err := Parse(expr)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+// String returns the JSONPath expression.
+func (p Path) String() string {
+	return p.expr
+}
+
+// Get returns the value selected in the JSON document bz, whose numbers are
+// decoded as json.Number so that no precision is lost.
+func (p Path) Get(bz []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(bz))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	for _, s := range p.steps {
+		switch node := value.(type) {
+		case map[string]interface{}:
+			if len(s.member) == 0 {
+				return nil, fmt.Errorf("%s: [%d] selects an element of an object", p.expr, s.index)
+			}
+			child, ok := node[s.member]
+			if !ok {
+				return nil, fmt.Errorf("%s: no member %s", p.expr, s.member)
+			}
+			value = child
+
+		case []interface{}:
+			if len(s.member) > 0 {
+				return nil, fmt.Errorf("%s: %s selects a member of an array", p.expr, s.member)
+			}
+			index := s.index
+			if index < 0 {
+				index += len(node)
+			}
+			if index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("%s: index %d out of %d elements", p.expr, s.index, len(node))
+			}
+			value = node[index]
+
+		default:
+			return nil, fmt.Errorf("%s: can't select into %T", p.expr, value)
+		}
+	}
+
+	return value, nil
+}
@@ -0,0 +1,57 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	for _, expr := range []string{"$", "$.data.last", "$['data'][\"last\"]", "$.result.XXBTZUSD.c[0]", "$[-1].price"} {
+		_, err := Parse(expr)
+		require.NoError(t, err, expr)
+	}
+
+	for _, expr := range []string{"", "data.last", "$.", "$..price", "$[0", "$[*]", "$[1:2]", "$ data"} {
+		_, err := Parse(expr)
+		require.Error(t, err, expr)
+	}
+}
+
+func TestGet(t *testing.T) {
+	doc := []byte(`{
+		"data": {"last": "10.5", "vol": 2500.123456789012345678},
+		"result": {"XXBTZUSD": {"c": ["30000.1", "0.01"]}},
+		"trades": [{"price": 1}, {"price": 2}]
+	}`)
+
+	testCases := []struct {
+		expr     string
+		expected interface{}
+		err      string
+	}{
+		{expr: "$.data.last", expected: "10.5"},
+		{expr: "$['data'][\"vol\"]", expected: json.Number("2500.123456789012345678")},
+		{expr: "$.result.XXBTZUSD.c[0]", expected: "30000.1"},
+		{expr: "$.trades[-1].price", expected: json.Number("2")},
+		{expr: "$.data.high", err: "no member high"},
+		{expr: "$.trades[2]", err: "index 2 out of 2 elements"},
+		{expr: "$.trades.price", err: "selects a member of an array"},
+		{expr: "$.data[0]", err: "selects an element of an object"},
+		{expr: "$.data.last.value", err: "can't select into string"},
+	}
+
+	for _, tc := range testCases {
+		value, err := MustParse(tc.expr).Get(doc)
+		if len(tc.err) > 0 {
+			require.ErrorContains(t, err, tc.err, tc.expr)
+			continue
+		}
+		require.NoError(t, err, tc.expr)
+		require.Equal(t, tc.expected, value, tc.expr)
+	}
+
+	_, err := MustParse("$.data").Get([]byte("not json"))
+	require.Error(t, err)
+}
@@ -0,0 +1,201 @@
+// Package logsafe implements a log writer which redacts the secrets of the
+// feeder, e.g. its mnemonic, private key or provider API keys, and truncates
+// the oversized values of the log events, e.g. raw provider payloads wrapped
+// in errors, before they reach the log output.
+package logsafe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/cosmos/go-bip39"
+)
+
+const (
+	// Redacted replaces the secrets in the log events.
+	Redacted = "[REDACTED]"
+
+	// DefaultMaxValueBytes is the default size above which the values of the
+	// log events are truncated.
+	DefaultMaxValueBytes = 4096
+
+	// minSecretBytes is the size below which registered secrets are not
+	// redacted, as short values would redact common words of the logs.
+	minSecretBytes = 6
+
+	// minMnemonicWords is the number of consecutive BIP39 words from which a
+	// sequence is redacted as a mnemonic.
+	minMnemonicWords = 12
+)
+
+var (
+	secretsMtx sync.RWMutex
+	secrets    = make(map[string]struct{})
+
+	// credentialPattern matches the credentials passed in URL queries or
+	// forms, e.g. "access_key=...".
+	credentialPattern = regexp.MustCompile(
+		`(?i)\b((?:access_key|api_?key|api_secret|secret|token|password|passphrase)=)[^&\s"\\]+`,
+	)
+
+	// wordsPattern matches the sequences of lowercase words, which may be
+	// mnemonics.
+	wordsPattern = regexp.MustCompile(`[a-z]+(?: [a-z]+){11,}`)
+)
+
+// AddSecrets registers secrets to be redacted from every log event, e.g. as
+// they are resolved from the config. Empty and short values are ignored.
+func AddSecrets(values ...string) {
+	secretsMtx.Lock()
+	defer secretsMtx.Unlock()
+
+	for _, value := range values {
+		if value = strings.TrimSpace(value); len(value) >= minSecretBytes {
+			secrets[value] = struct{}{}
+		}
+	}
+}
+
+// Redact returns s with the registered secrets, the credentials of URL
+// queries and the BIP39 mnemonics it contains replaced by Redacted.
+func Redact(s string) string {
+	secretsMtx.RLock()
+	for secret := range secrets {
+		s = strings.ReplaceAll(s, secret, Redacted)
+	}
+	secretsMtx.RUnlock()
+
+	s = credentialPattern.ReplaceAllString(s, "${1}"+Redacted)
+	return wordsPattern.ReplaceAllStringFunc(s, redactMnemonic)
+}
+
+// redactMnemonic redacts the runs of at least minMnemonicWords BIP39 words of
+// the sequence of words.
+func redactMnemonic(sequence string) string {
+	words := strings.Split(sequence, " ")
+	out := make([]string, 0, len(words))
+	for start := 0; start < len(words); {
+		end := start
+		for end < len(words) {
+			if _, ok := bip39.ReverseWordMap[words[end]]; !ok {
+				break
+			}
+			end++
+		}
+
+		switch {
+		case end-start >= minMnemonicWords:
+			out = append(out, Redacted)
+			start = end
+		case end == start:
+			out = append(out, words[start])
+			start++
+		default:
+			out = append(out, words[start:end]...)
+			start = end
+		}
+	}
+
+	return strings.Join(out, " ")
+}
+
+// Truncate returns s cut to maxBytes, on a rune boundary, with the number of
+// bytes cut, unless it is within maxBytes or maxBytes isn't positive.
+func Truncate(s string, maxBytes int) string {
+	if maxBytes <= 0 || len(s) <= maxBytes {
+		return s
+	}
+
+	n := maxBytes
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return fmt.Sprintf("%s...[%d bytes truncated]", s[:n], len(s)-n)
+}
+
+// Writer redacts and truncates the JSON log events written to it, as by
+// zerolog, before writing them to its output, which may be a
+// zerolog.ConsoleWriter.
+type Writer struct {
+	out           io.Writer
+	maxValueBytes int
+}
+
+// NewWriter returns a writer to out, truncating the values of the log events
+// above maxValueBytes, unless it isn't positive.
+func NewWriter(out io.Writer, maxValueBytes int) *Writer {
+	return &Writer{out: out, maxValueBytes: maxValueBytes}
+}
+
+// Write writes the log event p, redacted and truncated.
+func (w *Writer) Write(p []byte) (int, error) {
+	event := []byte(Redact(string(p)))
+	if w.maxValueBytes > 0 && len(event) > w.maxValueBytes {
+		event = w.truncateEvent(event)
+	}
+
+	if _, err := w.out.Write(event); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// truncateEvent truncates the values of the JSON event above the maximum
+// size, keeping the order of its fields. Events which aren't JSON objects are
+// truncated as a whole.
+func (w *Writer) truncateEvent(event []byte) []byte {
+	dec := json.NewDecoder(bytes.NewReader(event))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return w.truncateLine(event)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return w.truncateLine(event)
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return w.truncateLine(event)
+		}
+
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		bz, _ := json.Marshal(key)
+		buf.Write(bz)
+		buf.WriteByte(':')
+		buf.Write(w.truncateValue(value))
+	}
+	buf.WriteString("}\n")
+
+	return buf.Bytes()
+}
+
+// truncateValue truncates the JSON value above the maximum size, as a string
+// unless it is a string already.
+func (w *Writer) truncateValue(value json.RawMessage) []byte {
+	if len(value) <= w.maxValueBytes {
+		return value
+	}
+
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		s = string(value)
+	}
+	bz, _ := json.Marshal(Truncate(s, w.maxValueBytes))
+	return bz
+}
+
+func (w *Writer) truncateLine(event []byte) []byte {
+	line := strings.TrimSuffix(string(event), "\n")
+	return []byte(Truncate(line, w.maxValueBytes) + "\n")
+}
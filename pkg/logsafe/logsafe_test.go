@@ -0,0 +1,60 @@
+package logsafe
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	AddSecrets("", "short", "s3cr3t-api-key")
+
+	mnemonic := "abandon abandon abandon abandon abandon abandon " +
+		"abandon abandon abandon abandon abandon about"
+	testCases := map[string]string{
+		"key s3cr3t-api-key rejected":       "key [REDACTED] rejected",
+		"GET /latest?access_key=abc&base=X": "GET /latest?access_key=[REDACTED]&base=X",
+		"short values are kept":             "short values are kept",
+		"mnemonic " + mnemonic + " leaked":  "mnemonic [REDACTED] leaked",
+		"failed to fetch price of the pool": "failed to fetch price of the pool",
+	}
+	for in, expected := range testCases {
+		require.Equal(t, expected, Redact(in))
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	require.Equal(t, "abc", Truncate("abc", 3))
+	require.Equal(t, "abc", Truncate("abc", 0))
+	require.Equal(t, "ab...[2 bytes truncated]", Truncate("abcd", 2))
+
+	// runes aren't split
+	require.Equal(t, "a...[2 bytes truncated]", Truncate("aé", 2))
+}
+
+func TestWriter(t *testing.T) {
+	AddSecrets("0123456789abcdef")
+
+	var buf bytes.Buffer
+	logger := zerolog.New(NewWriter(&buf, 16))
+	logger.Error().
+		Str("payload", strings.Repeat("x", 64)).
+		Str("key", "0123456789abcdef").
+		Int("status", 502).
+		Msg("bad")
+
+	line := buf.String()
+	require.True(t, strings.HasSuffix(line, "}\n"))
+	require.Less(t, strings.Index(line, `"payload"`), strings.Index(line, `"status"`))
+
+	var event map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &event))
+	require.Equal(t, strings.Repeat("x", 16)+"...[48 bytes truncated]", event["payload"])
+	require.Equal(t, Redacted, event["key"])
+	require.Equal(t, float64(502), event["status"])
+	require.Equal(t, "bad", event["message"])
+}
@@ -0,0 +1,66 @@
+// Package sdnotify implements the systemd service notification protocol, see
+// sd_notify(3), so that the price-feeder can run as a Type=notify service with
+// a watchdog.
+package sdnotify
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Notification states, see sd_notify(3).
+const (
+	Ready    = "READY=1"
+	Stopping = "STOPPING=1"
+	Watchdog = "WATCHDOG=1"
+)
+
+const (
+	envSocket       = "NOTIFY_SOCKET"
+	envWatchdogUsec = "WATCHDOG_USEC"
+	envWatchdogPID  = "WATCHDOG_PID"
+)
+
+// Notify sends state to the service manager. It returns false without an
+// error if the process isn't run by systemd with a notification socket.
+func Notify(state string) (bool, error) {
+	socketAddr := os.Getenv(envSocket)
+	if len(socketAddr) == 0 {
+		return false, nil
+	}
+
+	// a leading "@" denotes a socket in the abstract namespace
+	if socketAddr[0] == '@' {
+		socketAddr = "\x00" + socketAddr[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socketAddr, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// WatchdogInterval returns the watchdog timeout configured for the service,
+// i.e. WatchdogSec of the unit, or zero if the watchdog is not enabled for
+// this process.
+func WatchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv(envWatchdogUsec), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+
+	if pid := os.Getenv(envWatchdogPID); len(pid) > 0 && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+
+	return time.Duration(usec) * time.Microsecond
+}
@@ -0,0 +1,47 @@
+package sdnotify
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotify(t *testing.T) {
+	t.Setenv(envSocket, "")
+	sent, err := Notify(Ready)
+	require.NoError(t, err)
+	require.False(t, sent)
+
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	t.Setenv(envSocket, path)
+	sent, err = Notify(Ready)
+	require.NoError(t, err)
+	require.True(t, sent)
+
+	buf := make([]byte, 64)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, Ready, string(buf[:n]))
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	t.Setenv(envWatchdogUsec, "")
+	require.Zero(t, WatchdogInterval())
+
+	t.Setenv(envWatchdogUsec, "30000000")
+	t.Setenv(envWatchdogPID, strconv.Itoa(os.Getpid()))
+	require.Equal(t, 30*time.Second, WatchdogInterval())
+
+	t.Setenv(envWatchdogPID, strconv.Itoa(os.Getpid()+1))
+	require.Zero(t, WatchdogInterval())
+}
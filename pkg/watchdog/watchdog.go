@@ -0,0 +1,171 @@
+// Package watchdog implements a self-monitor of the goroutines and heap of
+// the process, detecting the leaks long-running feeders are prone to, e.g.
+// goroutines leaked by storms of provider reconnections.
+package watchdog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	"github.com/rs/zerolog"
+)
+
+const dirPerm = 0o700
+
+type (
+	// Limits defines the number of goroutines and the heap size above which
+	// the process is considered leaking. Zero limits are not checked.
+	Limits struct {
+		MaxGoroutines int
+		MaxHeapBytes  uint64
+	}
+
+	// Sample defines the goroutines and heap of the process at a time.
+	Sample struct {
+		Goroutines int
+		HeapBytes  uint64
+	}
+
+	// Watchdog samples the process every interval. Once a sample exceeds the
+	// limits, it logs the breach, dumps the goroutine and heap profiles to
+	// DumpDir, if set, and calls OnBreach, if set, e.g. to restart the
+	// leaking subsystems. It only acts again once a sample is back within the
+	// limits, so that a leak which persists doesn't trigger a storm of dumps
+	// and restarts.
+	Watchdog struct {
+		logger   zerolog.Logger
+		limits   Limits
+		interval time.Duration
+		dumpDir  string
+		onBreach func()
+		sample   func() Sample
+
+		breached bool
+	}
+)
+
+// New returns a watchdog sampling the process every interval against the
+// limits.
+func New(logger zerolog.Logger, limits Limits, interval time.Duration, dumpDir string, onBreach func()) *Watchdog {
+	return &Watchdog{
+		logger:   logger.With().Str("module", "watchdog").Logger(),
+		limits:   limits,
+		interval: interval,
+		dumpDir:  dumpDir,
+		onBreach: onBreach,
+		sample:   sampleProcess,
+	}
+}
+
+// Start samples the process until ctx is done.
+func (w *Watchdog) Start(ctx context.Context) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			w.check(time.Now())
+		}
+	}
+}
+
+// check samples the process and acts on a new breach of the limits.
+func (w *Watchdog) check(now time.Time) {
+	sample := w.sample()
+	telemetry.SetGauge(float32(sample.Goroutines), "watchdog", "goroutines")
+	telemetry.SetGauge(float32(sample.HeapBytes), "watchdog", "heap_bytes")
+
+	reason := w.exceeded(sample)
+	if len(reason) == 0 {
+		if w.breached {
+			w.logger.Info().
+				Int("goroutines", sample.Goroutines).
+				Uint64("heap_bytes", sample.HeapBytes).
+				Msg("process back within the watchdog limits")
+		}
+		w.breached = false
+		return
+	}
+	if w.breached {
+		return
+	}
+	w.breached = true
+
+	w.logger.Error().
+		Str("reason", reason).
+		Int("goroutines", sample.Goroutines).
+		Int("max_goroutines", w.limits.MaxGoroutines).
+		Uint64("heap_bytes", sample.HeapBytes).
+		Uint64("max_heap_bytes", w.limits.MaxHeapBytes).
+		Msg("process exceeds the watchdog limits")
+	telemetry.IncrCounterWithLabels([]string{"watchdog", "breaches"}, 1, []metrics.Label{
+		telemetry.NewLabel("reason", reason),
+	})
+
+	if len(w.dumpDir) > 0 {
+		if err := w.dump(now); err != nil {
+			w.logger.Error().Err(err).Msg("failed to dump diagnostics")
+		}
+	}
+	if w.onBreach != nil {
+		w.onBreach()
+	}
+}
+
+// exceeded returns the limit the sample exceeds, if any.
+func (w *Watchdog) exceeded(sample Sample) string {
+	switch {
+	case w.limits.MaxGoroutines > 0 && sample.Goroutines > w.limits.MaxGoroutines:
+		return "goroutines"
+	case w.limits.MaxHeapBytes > 0 && sample.HeapBytes > w.limits.MaxHeapBytes:
+		return "heap"
+	default:
+		return ""
+	}
+}
+
+// dump writes the goroutine and heap profiles of the process to the dump
+// directory, to be inspected with go tool pprof.
+func (w *Watchdog) dump(now time.Time) error {
+	if err := os.MkdirAll(w.dumpDir, dirPerm); err != nil {
+		return err
+	}
+
+	for _, profile := range []string{"goroutine", "heap"} {
+		path := filepath.Join(w.dumpDir, fmt.Sprintf("%s-%d.pprof", profile, now.UnixMilli()))
+		if err := writeProfile(profile, path); err != nil {
+			return fmt.Errorf("failed to write %s profile: %w", profile, err)
+		}
+		w.logger.Info().Str("path", path).Msgf("dumped %s profile", profile)
+	}
+
+	return nil
+}
+
+func writeProfile(profile, path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return pprof.Lookup(profile).WriteTo(f, 0)
+}
+
+// sampleProcess returns the goroutines and heap in use of the process.
+func sampleProcess() Sample {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	return Sample{Goroutines: runtime.NumGoroutine(), HeapBytes: stats.HeapInuse}
+}
@@ -0,0 +1,43 @@
+package watchdog
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchdog(t *testing.T) {
+	var (
+		dumpDir  = t.TempDir()
+		breaches int
+		sample   = Sample{Goroutines: 10, HeapBytes: 1 << 20}
+	)
+	w := New(zerolog.Nop(), Limits{MaxGoroutines: 100, MaxHeapBytes: 64 << 20}, time.Second, dumpDir, func() {
+		breaches++
+	})
+	w.sample = func() Sample { return sample }
+
+	now := time.Now()
+	w.check(now)
+	require.Zero(t, breaches)
+
+	// a leak is acted on once, until back within the limits
+	sample.Goroutines = 1000
+	w.check(now)
+	w.check(now.Add(time.Second))
+	require.Equal(t, 1, breaches)
+
+	entries, err := os.ReadDir(dumpDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	sample.Goroutines = 10
+	w.check(now.Add(2 * time.Second))
+	sample.HeapBytes = 128 << 20
+	w.check(now.Add(3 * time.Second))
+	require.Equal(t, 2, breaches)
+	require.Equal(t, "heap", w.exceeded(sample))
+}
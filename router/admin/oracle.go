@@ -0,0 +1,14 @@
+package admin
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Oracle defines the Oracle interface contract that the admin router depends on.
+type Oracle interface {
+	Pause()
+	Resume()
+	IsPaused() bool
+	SetDeviations(map[string]sdk.Dec)
+	ResetProviderWeights()
+}
@@ -0,0 +1,36 @@
+package admin
+
+import (
+	"github.com/persistenceOne/oracle-feeder/pkg/features"
+)
+
+// Response constants.
+const (
+	StatusPaused = "paused"
+	StatusVoting = "voting"
+)
+
+type (
+	// StatusResponse defines the response type for the admin status and
+	// pause/resume handlers.
+	StatusResponse struct {
+		Status string `json:"status"`
+	}
+
+	// ReloadResponse defines the response type for the config reload handler.
+	ReloadResponse struct {
+		Deviations map[string]string `json:"deviation_thresholds"`
+	}
+
+	// SetFeatureRequest defines the request body of the feature flag toggle
+	// handler.
+	SetFeatureRequest struct {
+		Enabled *bool `json:"enabled"`
+	}
+
+	// FeaturesResponse defines the response type for the feature flags
+	// handlers, with the state of every flag.
+	FeaturesResponse struct {
+		Features map[features.Flag]bool `json:"features"`
+	}
+)
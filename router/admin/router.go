@@ -0,0 +1,274 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/justinas/alice"
+	"github.com/rs/zerolog"
+
+	"github.com/persistenceOne/oracle-feeder/config"
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+	"github.com/persistenceOne/oracle-feeder/pkg/features"
+	"github.com/persistenceOne/oracle-feeder/pkg/httputil"
+	"github.com/persistenceOne/oracle-feeder/router/middleware"
+)
+
+const (
+	APIPathPrefix   = "/admin"
+	PprofPathPrefix = "/debug/pprof"
+)
+
+// Router defines a router wrapper used for registering the admin and debug
+// API routes. These routes allow to control a running price-feeder and must
+// not be exposed publicly, see config.Server.AdminListenAddr.
+type Router struct {
+	logger     zerolog.Logger
+	cfg        config.Config
+	configPath string
+	oracle     Oracle
+	capture    *provider.PayloadCapture
+	features   *features.Flags
+	secret     []byte
+}
+
+// New returns the admin router, toggling the given feature flags. The debug
+// capture routes are only registered when capture is non-nil, i.e. when debug
+// capture is enabled. Unless secret
+// is empty, the admin API routes only accept requests signed with it, see
+// middleware.SignRequest.
+func New(
+	logger zerolog.Logger,
+	cfg config.Config,
+	configPath string,
+	oracle Oracle,
+	capture *provider.PayloadCapture,
+	flags *features.Flags,
+	secret []byte,
+) *Router {
+	return &Router{
+		logger:     logger.With().Str("module", "admin_router").Logger(),
+		cfg:        cfg,
+		configPath: configPath,
+		oracle:     oracle,
+		capture:    capture,
+		features:   flags,
+		secret:     secret,
+	}
+}
+
+// RegisterRoutes register the admin API routes under the provided prefix and
+// the pprof debug routes on the provided router, which must only be served on
+// the admin listener. Both require signed requests when a secret is set.
+func (r *Router) RegisterRoutes(rtr *mux.Router, prefix string) {
+	adminRouter := rtr.PathPrefix(prefix).Subrouter()
+	adminRouter.NotFoundHandler = httputil.NotFoundHandler()
+	adminRouter.MethodNotAllowedHandler = httputil.MethodNotAllowedHandler()
+
+	// build middleware chain, CORS is deliberately left out since these routes
+	// are not meant to be called from browsers
+	mChain := middleware.AddRequestLoggingMiddleware(alice.New(), r.logger)
+	mChain = middleware.AddMaxBodySizeMiddleware(mChain, r.cfg.Server.MaxBodyBytes)
+	mChain = middleware.AddHMACAuthMiddleware(mChain, r.secret)
+
+	adminRouter.Handle(
+		"/status",
+		mChain.ThenFunc(r.statusHandler()),
+	).Methods(httputil.MethodGET)
+
+	adminRouter.Handle(
+		"/pause",
+		mChain.ThenFunc(r.pauseHandler()),
+	).Methods(httputil.MethodPOST)
+
+	adminRouter.Handle(
+		"/resume",
+		mChain.ThenFunc(r.resumeHandler()),
+	).Methods(httputil.MethodPOST)
+
+	adminRouter.Handle(
+		"/config/reload",
+		mChain.ThenFunc(r.reloadConfigHandler()),
+	).Methods(httputil.MethodPOST)
+
+	adminRouter.Handle(
+		"/weights/reset",
+		mChain.ThenFunc(r.resetWeightsHandler()),
+	).Methods(httputil.MethodPOST)
+
+	adminRouter.Handle(
+		"/features",
+		mChain.ThenFunc(r.featuresHandler()),
+	).Methods(httputil.MethodGET)
+
+	adminRouter.Handle(
+		"/features/{flag}",
+		mChain.ThenFunc(r.setFeatureHandler()),
+	).Methods(httputil.MethodPOST)
+
+	if r.capture != nil {
+		adminRouter.Handle(
+			"/debug/capture",
+			mChain.ThenFunc(r.captureStatusHandler()),
+		).Methods(httputil.MethodGET)
+
+		adminRouter.Handle(
+			"/debug/capture",
+			mChain.ThenFunc(r.startCaptureHandler()),
+		).Methods(httputil.MethodPOST)
+
+		adminRouter.Handle(
+			"/debug/capture",
+			mChain.ThenFunc(r.stopCaptureHandler()),
+		).Methods(httputil.MethodDELETE)
+	}
+
+	// profiles, traces and the command line are as sensitive as the admin
+	// API, so they go through the same middleware chain
+	pprofRouter := rtr.PathPrefix(PprofPathPrefix).Subrouter()
+	pprofRouter.Handle("/cmdline", mChain.ThenFunc(pprof.Cmdline))
+	pprofRouter.Handle("/profile", mChain.ThenFunc(pprof.Profile))
+	pprofRouter.Handle("/symbol", mChain.ThenFunc(pprof.Symbol))
+	pprofRouter.Handle("/trace", mChain.ThenFunc(pprof.Trace))
+	pprofRouter.PathPrefix("/").Handler(mChain.ThenFunc(pprof.Index))
+}
+
+func (r *Router) statusHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		httputil.RespondWithJSON(w, http.StatusOK, r.status())
+	}
+}
+
+func (r *Router) pauseHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		r.oracle.Pause()
+		httputil.RespondWithJSON(w, http.StatusOK, r.status())
+	}
+}
+
+func (r *Router) resumeHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		r.oracle.Resume()
+		httputil.RespondWithJSON(w, http.StatusOK, r.status())
+	}
+}
+
+// reloadConfigHandler re-reads the configuration file and applies the
+// settings that can be changed at runtime. Currently only the deviation
+// thresholds are hot-reloadable, any other change requires a restart.
+func (r *Router) reloadConfigHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		cfg, err := config.ParseConfig(r.configPath)
+		if err != nil {
+			httputil.RespondWithError(w, http.StatusBadRequest, httputil.ErrCodeBadRequest, err.Error(), nil)
+			return
+		}
+		cfg = cfg.ApplyPolicy(req.Context(), r.logger)
+
+		deviations, err := cfg.DeviationThresholds()
+		if err != nil {
+			httputil.RespondWithError(w, http.StatusBadRequest, httputil.ErrCodeBadRequest, err.Error(), nil)
+			return
+		}
+
+		r.oracle.SetDeviations(deviations)
+		r.logger.Info().Str("config_path", r.configPath).Msg("reloaded configuration")
+
+		resp := ReloadResponse{
+			Deviations: make(map[string]string, len(deviations)),
+		}
+		for base, threshold := range deviations {
+			resp.Deviations[base] = threshold.String()
+		}
+
+		httputil.RespondWithJSON(w, http.StatusOK, resp)
+	}
+}
+
+// resetWeightsHandler resets the learned provider weights, e.g. once the
+// incident which caused a provider to be down-weighted has been resolved.
+func (r *Router) resetWeightsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		r.oracle.ResetProviderWeights()
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (r *Router) featuresHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		httputil.RespondWithJSON(w, http.StatusOK, FeaturesResponse{Features: r.features.All()})
+	}
+}
+
+// setFeatureHandler enables or disables the feature flag of the path, e.g.
+// "/features/async_broadcast", until the next restart. The state is read from
+// the body, e.g. {"enabled": true}, which, unlike the query, is covered by the
+// request signature.
+func (r *Router) setFeatureHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		var body SetFeatureRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil || body.Enabled == nil {
+			httputil.RespondWithError(w, http.StatusBadRequest, httputil.ErrCodeBadRequest, "invalid request body", nil)
+			return
+		}
+		enabled := *body.Enabled
+
+		flag := features.Flag(mux.Vars(req)["flag"])
+		if err := r.features.Set(flag, enabled); err != nil {
+			httputil.RespondWithError(w, http.StatusNotFound, httputil.ErrCodeNotFound, err.Error(), nil)
+			return
+		}
+
+		r.logger.Info().Str("flag", string(flag)).Bool("enabled", enabled).Msg("toggled feature flag")
+		httputil.RespondWithJSON(w, http.StatusOK, FeaturesResponse{Features: r.features.All()})
+	}
+}
+
+// startCaptureHandler starts capturing the raw provider payloads mentioning
+// the asset query parameter for the duration query parameter, e.g.
+// "?asset=ATOM&duration=5m".
+func (r *Router) startCaptureHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		duration, err := time.ParseDuration(req.URL.Query().Get("duration"))
+		if err != nil {
+			httputil.RespondWithError(w, http.StatusBadRequest, httputil.ErrCodeBadRequest, "invalid duration", nil)
+			return
+		}
+
+		status, err := r.capture.Start(req.URL.Query().Get("asset"), duration)
+		if err != nil {
+			httputil.RespondWithError(w, http.StatusBadRequest, httputil.ErrCodeBadRequest, err.Error(), nil)
+			return
+		}
+
+		r.logger.Info().
+			Str("asset", status.Asset).
+			Str("path", status.Path).
+			Time("until", status.Until).
+			Msg("started provider payload capture")
+		httputil.RespondWithJSON(w, http.StatusOK, status)
+	}
+}
+
+func (r *Router) stopCaptureHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		httputil.RespondWithJSON(w, http.StatusOK, r.capture.Stop())
+	}
+}
+
+func (r *Router) captureStatusHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		httputil.RespondWithJSON(w, http.StatusOK, r.capture.Status())
+	}
+}
+
+func (r *Router) status() StatusResponse {
+	if r.oracle.IsPaused() {
+		return StatusResponse{Status: StatusPaused}
+	}
+
+	return StatusResponse{Status: StatusVoting}
+}
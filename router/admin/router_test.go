@@ -0,0 +1,231 @@
+package admin_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/persistenceOne/oracle-feeder/config"
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+	"github.com/persistenceOne/oracle-feeder/pkg/features"
+	"github.com/persistenceOne/oracle-feeder/router/admin"
+	"github.com/persistenceOne/oracle-feeder/router/middleware"
+)
+
+var _ admin.Oracle = (*mockOracle)(nil)
+
+type mockOracle struct {
+	paused       bool
+	deviations   map[string]sdk.Dec
+	weightsReset bool
+}
+
+func (m *mockOracle) Pause() {
+	m.paused = true
+}
+
+func (m *mockOracle) Resume() {
+	m.paused = false
+}
+
+func (m *mockOracle) IsPaused() bool {
+	return m.paused
+}
+
+func (m *mockOracle) SetDeviations(deviations map[string]sdk.Dec) {
+	m.deviations = deviations
+}
+
+func (m *mockOracle) ResetProviderWeights() {
+	m.weightsReset = true
+}
+
+type RouterTestSuite struct {
+	suite.Suite
+
+	mux      *mux.Router
+	oracle   *mockOracle
+	features *features.Flags
+}
+
+// SetupTest executes before each of the suite's tests.
+func (rts *RouterTestSuite) SetupTest() {
+	mux := mux.NewRouter()
+	rts.oracle = &mockOracle{}
+	flags, err := features.New(nil)
+	rts.Require().NoError(err)
+	rts.features = flags
+
+	r := admin.New(zerolog.Nop(), config.Config{}, "", rts.oracle, nil, flags, nil)
+	r.RegisterRoutes(mux, admin.APIPathPrefix)
+
+	rts.mux = mux
+}
+
+func TestServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(RouterTestSuite))
+}
+
+func (rts *RouterTestSuite) executeRequest(req *http.Request) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	rts.mux.ServeHTTP(rr, req)
+
+	return rr
+}
+
+func (rts *RouterTestSuite) requestStatus(method, path string) string {
+	req, err := http.NewRequest(method, path, nil)
+	rts.Require().NoError(err)
+
+	response := rts.executeRequest(req)
+	rts.Require().Equal(http.StatusOK, response.Code)
+
+	var respBody admin.StatusResponse
+	rts.Require().NoError(json.Unmarshal(response.Body.Bytes(), &respBody))
+
+	return respBody.Status
+}
+
+func (rts *RouterTestSuite) TestPauseResume() {
+	rts.Require().Equal(admin.StatusVoting, rts.requestStatus("GET", "/admin/status"))
+	rts.Require().Equal(admin.StatusPaused, rts.requestStatus("POST", "/admin/pause"))
+	rts.Require().True(rts.oracle.paused)
+	rts.Require().Equal(admin.StatusPaused, rts.requestStatus("GET", "/admin/status"))
+	rts.Require().Equal(admin.StatusVoting, rts.requestStatus("POST", "/admin/resume"))
+	rts.Require().False(rts.oracle.paused)
+}
+
+func (rts *RouterTestSuite) TestPauseMethodNotAllowed() {
+	req, err := http.NewRequest("GET", "/admin/pause", nil)
+	rts.Require().NoError(err)
+
+	response := rts.executeRequest(req)
+	rts.Require().Equal(http.StatusMethodNotAllowed, response.Code)
+	rts.Require().False(rts.oracle.paused)
+}
+
+func (rts *RouterTestSuite) TestReloadConfigInvalidPath() {
+	req, err := http.NewRequest("POST", "/admin/config/reload", nil)
+	rts.Require().NoError(err)
+
+	response := rts.executeRequest(req)
+	rts.Require().Equal(http.StatusBadRequest, response.Code)
+	rts.Require().Nil(rts.oracle.deviations)
+}
+
+func (rts *RouterTestSuite) TestPprofIndex() {
+	req, err := http.NewRequest("GET", "/debug/pprof/", nil)
+	rts.Require().NoError(err)
+
+	response := rts.executeRequest(req)
+	rts.Require().Equal(http.StatusOK, response.Code)
+}
+
+func (rts *RouterTestSuite) TestPprofRequiresSignature() {
+	secret := []byte("admin-secret")
+	mux := mux.NewRouter()
+	admin.New(zerolog.Nop(), config.Config{}, "", rts.oracle, nil, rts.features, secret).
+		RegisterRoutes(mux, admin.APIPathPrefix)
+
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/cmdline", "/debug/pprof/heap"} {
+		req, err := http.NewRequest("GET", path, nil)
+		rts.Require().NoError(err)
+
+		response := httptest.NewRecorder()
+		mux.ServeHTTP(response, req)
+		rts.Require().Equal(http.StatusUnauthorized, response.Code, path)
+	}
+
+	req, err := http.NewRequest("GET", "/debug/pprof/cmdline", nil)
+	rts.Require().NoError(err)
+	rts.Require().NoError(middleware.SignRequest(req, secret, "nonce-1", time.Now()))
+
+	response := httptest.NewRecorder()
+	mux.ServeHTTP(response, req)
+	rts.Require().Equal(http.StatusOK, response.Code)
+}
+
+func (rts *RouterTestSuite) TestResetWeights() {
+	req, err := http.NewRequest("POST", "/admin/weights/reset", nil)
+	rts.Require().NoError(err)
+
+	response := rts.executeRequest(req)
+	rts.Require().Equal(http.StatusNoContent, response.Code)
+	rts.Require().True(rts.oracle.weightsReset)
+}
+
+func (rts *RouterTestSuite) TestFeatures() {
+	req, err := http.NewRequest("POST", "/admin/features/async_broadcast", strings.NewReader(`{"enabled":true}`))
+	rts.Require().NoError(err)
+	response := rts.executeRequest(req)
+	rts.Require().Equal(http.StatusOK, response.Code)
+	rts.Require().True(rts.features.Enabled(features.AsyncBroadcast))
+
+	req, err = http.NewRequest("GET", "/admin/features", nil)
+	rts.Require().NoError(err)
+	response = rts.executeRequest(req)
+	rts.Require().Equal(http.StatusOK, response.Code)
+
+	var resp admin.FeaturesResponse
+	rts.Require().NoError(json.Unmarshal(response.Body.Bytes(), &resp))
+	rts.Require().True(resp.Features[features.AsyncBroadcast])
+	rts.Require().True(resp.Features[features.ProviderWeights])
+
+	for path, code := range map[string]int{
+		"/admin/features/async_broadcast": http.StatusBadRequest,
+		"/admin/features/ha":              http.StatusNotFound,
+	} {
+		body := `{"enabled":true}`
+		if code == http.StatusBadRequest {
+			body = `{"enabled":"maybe"}`
+		}
+		req, err = http.NewRequest("POST", path, strings.NewReader(body))
+		rts.Require().NoError(err)
+		rts.Require().Equal(code, rts.executeRequest(req).Code)
+	}
+}
+
+func (rts *RouterTestSuite) TestDebugCapture() {
+	// the capture routes are not registered unless debug capture is enabled
+	req, err := http.NewRequest("POST", "/admin/debug/capture?asset=ATOM&duration=1m", nil)
+	rts.Require().NoError(err)
+	rts.Require().Equal(http.StatusNotFound, rts.executeRequest(req).Code)
+
+	mux := mux.NewRouter()
+	capture := provider.NewPayloadCapture(rts.T().TempDir())
+	admin.New(zerolog.Nop(), config.Config{}, "", rts.oracle, capture, rts.features, nil).
+		RegisterRoutes(mux, admin.APIPathPrefix)
+
+	req, err = http.NewRequest("POST", "/admin/debug/capture?asset=ATOM&duration=2h", nil)
+	rts.Require().NoError(err)
+	response := httptest.NewRecorder()
+	mux.ServeHTTP(response, req)
+	rts.Require().Equal(http.StatusBadRequest, response.Code)
+
+	req, err = http.NewRequest("POST", "/admin/debug/capture?asset=ATOM&duration=1m", nil)
+	rts.Require().NoError(err)
+	response = httptest.NewRecorder()
+	mux.ServeHTTP(response, req)
+	rts.Require().Equal(http.StatusOK, response.Code)
+
+	var status provider.CaptureStatus
+	rts.Require().NoError(json.Unmarshal(response.Body.Bytes(), &status))
+	rts.Require().True(status.Active)
+	rts.Require().Equal("ATOM", status.Asset)
+
+	req, err = http.NewRequest("DELETE", "/admin/debug/capture", nil)
+	rts.Require().NoError(err)
+	response = httptest.NewRecorder()
+	mux.ServeHTTP(response, req)
+	rts.Require().Equal(http.StatusOK, response.Code)
+	rts.Require().NoError(json.Unmarshal(response.Body.Bytes(), &status))
+	rts.Require().False(status.Active)
+}
@@ -0,0 +1,267 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/justinas/alice"
+
+	"github.com/persistenceOne/oracle-feeder/pkg/httputil"
+)
+
+// Headers of requests signed with the admin secret, see SignRequest.
+const (
+	HeaderTimestamp = "X-Admin-Timestamp"
+	HeaderNonce     = "X-Admin-Nonce"
+	HeaderSignature = "X-Admin-Signature"
+
+	// HeaderIdempotentReplay is set on the response to a request whose nonce
+	// was already processed, which is answered with the original response
+	// instead of being executed again.
+	HeaderIdempotentReplay = "Idempotent-Replay"
+)
+
+// SignedRequestMaxAge is how far the timestamp of a signed request may be
+// from the server time. Nonces are remembered for twice as long, so a request
+// cannot be replayed once its nonce is forgotten.
+const SignedRequestMaxAge = 30 * time.Second
+
+// maxSignedNonces bounds the number of nonces remembered at once, signed
+// requests being rejected while it is reached. Admin requests are rare, this
+// only limits the memory a leaked secret can make the server use.
+const maxSignedNonces = 1024
+
+// SignRequest signs req with secret by setting the timestamp, nonce and
+// signature headers. The signature is the hex encoded HMAC-SHA256 of the
+// method, path, raw query string, timestamp, nonce and the hex encoded
+// SHA-256 of the body, separated by newlines. The nonce must be unique per
+// request and is used as idempotency key, a retried request must reuse it.
+func SignRequest(req *http.Request, secret []byte, nonce string, timestamp time.Time) error {
+	body, err := readBody(req)
+	if err != nil {
+		return err
+	}
+
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	req.Header.Set(HeaderTimestamp, ts)
+	req.Header.Set(HeaderNonce, nonce)
+	req.Header.Set(
+		HeaderSignature,
+		hex.EncodeToString(requestMAC(secret, req.Method, req.URL.Path, req.URL.RawQuery, ts, nonce, body)),
+	)
+
+	return nil
+}
+
+func requestMAC(secret []byte, method, path, query, timestamp, nonce string, body []byte) []byte {
+	bodyHash := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, secret)
+	message := strings.Join([]string{method, path, query, timestamp, nonce, hex.EncodeToString(bodyHash[:])}, "\n")
+	mac.Write([]byte(message))
+	return mac.Sum(nil)
+}
+
+// readBody reads the body of req and replaces it so that it can be read again.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+type (
+	// signedRequests verifies signed requests and remembers the response to
+	// every nonce for 2 * SignedRequestMaxAge, up to maxSignedNonces. The
+	// nonces are also queued in the order they were seen, so that the expired
+	// ones are found without going through all of them.
+	signedRequests struct {
+		secret []byte
+		now    func() time.Time
+
+		mtx       sync.Mutex
+		responses map[string]nonceResponse
+		queue     []seenNonce
+	}
+
+	seenNonce struct {
+		nonce  string
+		seenAt time.Time
+	}
+
+	nonceResponse struct {
+		pending bool
+		status  int
+		header  http.Header
+		body    []byte
+	}
+)
+
+// AddHMACAuthMiddleware appends middleware to a provided middleware chain
+// which rejects requests not signed with secret, see SignRequest, and answers
+// requests whose nonce was already processed with the original response. This
+// keeps anyone who sniffs a request, e.g. on the validator LAN, from replaying
+// it. An empty secret disables the middleware.
+func AddHMACAuthMiddleware(mChain alice.Chain, secret []byte) alice.Chain {
+	if len(secret) == 0 {
+		return mChain
+	}
+
+	sr := &signedRequests{
+		secret:    secret,
+		now:       time.Now,
+		responses: make(map[string]nonceResponse),
+	}
+
+	return mChain.Append(sr.handler)
+}
+
+func (sr *signedRequests) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonce := r.Header.Get(HeaderNonce)
+		if msg := sr.verify(r, nonce); len(msg) > 0 {
+			httputil.RespondWithError(w, http.StatusUnauthorized, httputil.ErrCodeUnauthorized, msg, nil)
+			return
+		}
+
+		resp, seen, ok := sr.claim(nonce)
+		switch {
+		case !ok:
+			httputil.RespondWithError(
+				w,
+				http.StatusTooManyRequests,
+				httputil.ErrCodeTooManyRequests,
+				"too many signed requests, retry later",
+				nil,
+			)
+			return
+
+		case seen && resp.pending:
+			httputil.RespondWithError(
+				w,
+				http.StatusConflict,
+				httputil.ErrCodeConflict,
+				"a request with this nonce is in progress",
+				nil,
+			)
+			return
+
+		case seen:
+			for key, values := range resp.header {
+				w.Header()[key] = values
+			}
+			w.Header().Set(HeaderIdempotentReplay, "true")
+			w.WriteHeader(resp.status)
+			_, _ = w.Write(resp.body)
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		sr.complete(nonce, rec)
+	})
+}
+
+// verify returns why the request is rejected, or an empty string if it is
+// signed with the secret within SignedRequestMaxAge.
+func (sr *signedRequests) verify(r *http.Request, nonce string) string {
+	ts := r.Header.Get(HeaderTimestamp)
+	signature, err := hex.DecodeString(r.Header.Get(HeaderSignature))
+	if len(ts) == 0 || len(nonce) == 0 || err != nil || len(signature) == 0 {
+		return "request must be signed with the admin secret"
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return "invalid request timestamp"
+	}
+	age := sr.now().Sub(time.Unix(unix, 0))
+	if age > SignedRequestMaxAge || age < -SignedRequestMaxAge {
+		return "request timestamp is too far from the server time"
+	}
+
+	body, err := readBody(r)
+	if err != nil {
+		return "failed to read request body"
+	}
+	if !hmac.Equal(signature, requestMAC(sr.secret, r.Method, r.URL.Path, r.URL.RawQuery, ts, nonce, body)) {
+		return "invalid request signature"
+	}
+
+	return ""
+}
+
+// claim returns the response to the nonce if it was seen, otherwise it marks
+// the nonce as pending. Expired nonces are forgotten first, and false is
+// returned if maxSignedNonces are still remembered.
+func (sr *signedRequests) claim(nonce string) (resp nonceResponse, seen, ok bool) {
+	sr.mtx.Lock()
+	defer sr.mtx.Unlock()
+
+	now := sr.now()
+	expired := 0
+	for _, seen := range sr.queue {
+		if now.Sub(seen.seenAt) <= 2*SignedRequestMaxAge {
+			break
+		}
+		delete(sr.responses, seen.nonce)
+		expired++
+	}
+	sr.queue = sr.queue[expired:]
+
+	if resp, ok := sr.responses[nonce]; ok {
+		return resp, true, true
+	}
+	if len(sr.responses) >= maxSignedNonces {
+		return nonceResponse{}, false, false
+	}
+	sr.responses[nonce] = nonceResponse{pending: true}
+	sr.queue = append(sr.queue, seenNonce{nonce: nonce, seenAt: now})
+
+	return nonceResponse{}, false, true
+}
+
+// complete records the response to the nonce.
+func (sr *signedRequests) complete(nonce string, rec *responseRecorder) {
+	sr.mtx.Lock()
+	defer sr.mtx.Unlock()
+
+	resp := sr.responses[nonce]
+	resp.pending = false
+	resp.status = rec.status
+	resp.header = rec.Header().Clone()
+	resp.body = rec.body.Bytes()
+	sr.responses[nonce] = resp
+}
+
+// responseRecorder writes the response and records it.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	rr.status = status
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	rr.body.Write(b)
+	return rr.ResponseWriter.Write(b)
+}
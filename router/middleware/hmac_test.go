@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignedRequestsClaim(t *testing.T) {
+	now := time.Now()
+	sr := &signedRequests{
+		now:       func() time.Time { return now },
+		responses: make(map[string]nonceResponse),
+	}
+
+	for i := 0; i < maxSignedNonces; i++ {
+		_, seen, ok := sr.claim(fmt.Sprintf("nonce-%d", i))
+		require.True(t, ok)
+		require.False(t, seen)
+	}
+
+	// seen nonces are still answered once the limit is reached, new ones are
+	// rejected until the oldest expire
+	_, seen, ok := sr.claim("nonce-0")
+	require.True(t, ok)
+	require.True(t, seen)
+	_, _, ok = sr.claim("nonce-new")
+	require.False(t, ok)
+
+	now = now.Add(SignedRequestMaxAge)
+	_, _, ok = sr.claim("nonce-new")
+	require.False(t, ok)
+
+	now = now.Add(SignedRequestMaxAge + time.Second)
+	_, seen, ok = sr.claim("nonce-new")
+	require.True(t, ok)
+	require.False(t, seen)
+	require.Len(t, sr.responses, 1)
+	require.Len(t, sr.queue, 1)
+}
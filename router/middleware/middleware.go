@@ -10,11 +10,13 @@ import (
 	"github.com/rs/zerolog/hlog"
 
 	"github.com/persistenceOne/oracle-feeder/config"
+	"github.com/persistenceOne/oracle-feeder/pkg/httputil"
 )
 
 func Build(logger zerolog.Logger, cfg config.Config) alice.Chain {
 	mChain := alice.New()
 	mChain = AddRequestLoggingMiddleware(mChain, logger)
+	mChain = AddMaxBodySizeMiddleware(mChain, cfg.Server.MaxBodyBytes)
 	mChain = AddCORSMiddleware(mChain, logger, cfg)
 
 	return mChain
@@ -42,6 +44,34 @@ func AddRequestLoggingMiddleware(mChain alice.Chain, logger zerolog.Logger) alic
 	return mChain
 }
 
+// AddMaxBodySizeMiddleware appends middleware limiting the size of request
+// bodies to maxBytes to a provided middleware chain. Requests announcing a
+// larger body are rejected upfront, others fail once the limit is read past.
+// A non-positive maxBytes disables the limit.
+func AddMaxBodySizeMiddleware(mChain alice.Chain, maxBytes int64) alice.Chain {
+	if maxBytes <= 0 {
+		return mChain
+	}
+
+	return mChain.Append(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > maxBytes {
+				httputil.RespondWithError(
+					w,
+					http.StatusRequestEntityTooLarge,
+					httputil.ErrCodeRequestTooLarge,
+					"request body too large",
+					map[string]interface{}{"max_body_bytes": maxBytes},
+				)
+				return
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			next.ServeHTTP(w, r)
+		})
+	})
+}
+
 // AddCORSMiddleware appends CORS middleware to a provided middleware chain.
 func AddCORSMiddleware(mChain alice.Chain, logger zerolog.Logger, cfg config.Config) alice.Chain {
 	opts := cors.Options{
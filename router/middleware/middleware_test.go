@@ -0,0 +1,137 @@
+package middleware_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/justinas/alice"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/router/middleware"
+)
+
+func TestAddMaxBodySizeMiddleware(t *testing.T) {
+	handler := middleware.AddMaxBodySizeMiddleware(alice.New(), 8).
+		ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, err := io.ReadAll(r.Body); err != nil {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		})
+
+	testCases := map[string]struct {
+		body          string
+		contentLength int64
+		expected      int
+	}{
+		"body within limit": {
+			body:          "12345678",
+			contentLength: 8,
+			expected:      http.StatusOK,
+		},
+		"announced body too large": {
+			body:          "123456789",
+			contentLength: 9,
+			expected:      http.StatusRequestEntityTooLarge,
+		},
+		"unannounced body too large": {
+			body:          "123456789",
+			contentLength: -1,
+			expected:      http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tc.body))
+			req.ContentLength = tc.contentLength
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			require.Equal(t, tc.expected, rr.Code)
+		})
+	}
+}
+
+func TestAddHMACAuthMiddleware(t *testing.T) {
+	secret := []byte("admin-secret")
+	calls := 0
+	handler := middleware.AddHMACAuthMiddleware(alice.New(), secret).
+		ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			body, _ := io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write(body)
+		})
+
+	newRequest := func(body string) *http.Request {
+		return httptest.NewRequest(http.MethodPost, "/admin/pause", strings.NewReader(body))
+	}
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("unsigned", func(t *testing.T) {
+		require.Equal(t, http.StatusUnauthorized, serve(newRequest("")).Code)
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		req := newRequest("")
+		require.NoError(t, middleware.SignRequest(req, []byte("other"), "nonce-1", time.Now()))
+		require.Equal(t, http.StatusUnauthorized, serve(req).Code)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		req := newRequest("")
+		require.NoError(t, middleware.SignRequest(req, secret, "nonce-2", time.Now().Add(-time.Minute)))
+		require.Equal(t, http.StatusUnauthorized, serve(req).Code)
+	})
+
+	t.Run("tampered body", func(t *testing.T) {
+		req := newRequest("a")
+		require.NoError(t, middleware.SignRequest(req, secret, "nonce-3", time.Now()))
+		req.Body = io.NopCloser(strings.NewReader("b"))
+		require.Equal(t, http.StatusUnauthorized, serve(req).Code)
+	})
+
+	t.Run("tampered query", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/admin/debug/capture?asset=ATOM&duration=5m", nil)
+		require.NoError(t, middleware.SignRequest(req, secret, "nonce-5", time.Now()))
+
+		tampered := httptest.NewRequest(http.MethodPost, "/admin/debug/capture?asset=ATOM&duration=24h", nil)
+		tampered.Header = req.Header.Clone()
+		require.Equal(t, http.StatusUnauthorized, serve(tampered).Code)
+		require.Equal(t, 0, calls)
+
+		require.Equal(t, http.StatusCreated, serve(req).Code)
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		req := newRequest("body")
+		require.NoError(t, middleware.SignRequest(req, secret, "nonce-4", time.Now()))
+		headers := req.Header.Clone()
+
+		rr := serve(req)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		require.Equal(t, "body", rr.Body.String())
+		require.Equal(t, 2, calls)
+
+		replayed := newRequest("body")
+		replayed.Header = headers
+		rr = serve(replayed)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		require.Equal(t, "body", rr.Body.String())
+		require.Equal(t, "true", rr.Header().Get(middleware.HeaderIdempotentReplay))
+		require.Equal(t, 2, calls, "a replayed request must not be executed again")
+	})
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/persistenceOne/oracle-feeder/pkg/httputil"
+)
+
+// PriceSource defines the oracle methods the price routes of every API
+// version share.
+type PriceSource interface {
+	GetLastPriceSyncTimestamp() time.Time
+	GetPrices() map[string]sdk.Dec
+}
+
+// CurrentPrices returns the latest prices of the oracle and when they were
+// synced. If they are not servable, because they were never synced, were
+// synced more than maxSyncAge ago or no provider returned a price, an error
+// response describing why is written and false returned. A zero maxSyncAge
+// disables the age check, as it does for the health probes.
+func CurrentPrices(
+	w http.ResponseWriter,
+	oracle PriceSource,
+	maxSyncAge time.Duration,
+) (map[string]sdk.Dec, time.Time, bool) {
+	lastSync := oracle.GetLastPriceSyncTimestamp()
+	if lastSync.IsZero() {
+		httputil.RespondWithError(
+			w,
+			http.StatusServiceUnavailable,
+			httputil.ErrCodeNotReady,
+			"prices are not available yet",
+			nil,
+		)
+		return nil, lastSync, false
+	}
+
+	if maxSyncAge > 0 && time.Since(lastSync) > maxSyncAge {
+		httputil.RespondWithError(
+			w,
+			http.StatusServiceUnavailable,
+			httputil.ErrCodeStalePrices,
+			"prices are stale",
+			map[string]interface{}{"last_sync": lastSync.Format(time.RFC3339)},
+		)
+		return nil, lastSync, false
+	}
+
+	prices := oracle.GetPrices()
+	if len(prices) == 0 {
+		httputil.RespondWithError(
+			w,
+			http.StatusServiceUnavailable,
+			httputil.ErrCodeProviderUnavailable,
+			"no provider returned a price during the last sync",
+			map[string]interface{}{"last_sync": lastSync.Format(time.RFC3339)},
+		)
+		return nil, lastSync, false
+	}
+
+	return prices, lastSync, true
+}
@@ -0,0 +1,80 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/pkg/httputil"
+	"github.com/persistenceOne/oracle-feeder/router/middleware"
+)
+
+type priceSource struct {
+	lastSync time.Time
+	prices   map[string]sdk.Dec
+}
+
+func (ps priceSource) GetLastPriceSyncTimestamp() time.Time { return ps.lastSync }
+func (ps priceSource) GetPrices() map[string]sdk.Dec        { return ps.prices }
+
+func TestCurrentPrices(t *testing.T) {
+	prices := map[string]sdk.Dec{"ATOM": sdk.MustNewDecFromStr("34.84")}
+	stale := time.Now().Add(-2 * time.Minute)
+
+	testCases := map[string]struct {
+		source     priceSource
+		maxSyncAge time.Duration
+		expected   httputil.ErrorCode
+	}{
+		"not ready": {
+			source:     priceSource{prices: prices},
+			maxSyncAge: time.Minute,
+			expected:   httputil.ErrCodeNotReady,
+		},
+		"stale": {
+			source:     priceSource{lastSync: stale, prices: prices},
+			maxSyncAge: time.Minute,
+			expected:   httputil.ErrCodeStalePrices,
+		},
+		"stale with age check disabled": {
+			source: priceSource{lastSync: stale, prices: prices},
+		},
+		"no provider prices": {
+			source:     priceSource{lastSync: time.Now()},
+			maxSyncAge: time.Minute,
+			expected:   httputil.ErrCodeProviderUnavailable,
+		},
+		"current": {
+			source:     priceSource{lastSync: time.Now(), prices: prices},
+			maxSyncAge: time.Minute,
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			current, lastSync, ok := middleware.CurrentPrices(rr, tc.source, tc.maxSyncAge)
+			require.Equal(t, tc.source.lastSync, lastSync)
+
+			if len(tc.expected) == 0 {
+				require.True(t, ok)
+				require.Equal(t, prices, current)
+				return
+			}
+
+			require.False(t, ok)
+			require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+
+			var resp httputil.ErrResponse
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.expected, resp.Error.Code)
+		})
+	}
+}
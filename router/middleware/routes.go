@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/justinas/alice"
+	"github.com/rs/zerolog"
+
+	"github.com/persistenceOne/oracle-feeder/config"
+	"github.com/persistenceOne/oracle-feeder/pkg/httputil"
+)
+
+// APIVersionHeader is the response header set to the version of the API which
+// served the request, e.g. "v1".
+const APIVersionHeader = "API-Version"
+
+// Route defines a handler of a versioned API, served at Path under the API
+// prefix for the given Method.
+type Route struct {
+	Path    string
+	Method  string
+	Handler http.HandlerFunc
+}
+
+// VersionedAPI defines a version of the public API, whose routes are served
+// under their own prefix so that several versions can be served concurrently.
+type VersionedAPI interface {
+	Version() string
+	Routes() []Route
+}
+
+// RegisterVersionedRoutes registers the routes of api under prefix on the
+// provided router. Every version shares the middleware chain built by Build,
+// which additionally sets the APIVersionHeader, and the not found, method not
+// allowed and preflight handlers.
+func RegisterVersionedRoutes(
+	rtr *mux.Router,
+	prefix string,
+	api VersionedAPI,
+	logger zerolog.Logger,
+	cfg config.Config,
+) {
+	apiRouter := rtr.PathPrefix(prefix).Subrouter()
+	apiRouter.NotFoundHandler = httputil.NotFoundHandler()
+	apiRouter.MethodNotAllowedHandler = httputil.MethodNotAllowedHandler()
+
+	// build middleware chain
+	mChain := AddAPIVersionMiddleware(Build(logger, cfg), api.Version())
+
+	// handle all preflight request
+	apiRouter.Methods("OPTIONS").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", r.Header.Get("Origin"))
+		w.Header().Set("Access-Control-Allow-Methods", "GET, PUT, POST, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Access-Control-Allow-Headers, "+
+			"Authorization, X-Requested-With")
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	for _, route := range api.Routes() {
+		apiRouter.Handle(
+			route.Path,
+			mChain.ThenFunc(route.Handler),
+		).Methods(route.Method)
+	}
+}
+
+// AddAPIVersionMiddleware appends middleware setting the APIVersionHeader of
+// responses to version to a provided middleware chain.
+func AddAPIVersionMiddleware(mChain alice.Chain, version string) alice.Chain {
+	return mChain.Append(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(APIVersionHeader, version)
+			next.ServeHTTP(w, r)
+		})
+	})
+}
@@ -0,0 +1,214 @@
+package v1
+
+import (
+	"net/url"
+
+	"github.com/persistenceOne/oracle-feeder/config"
+)
+
+// redacted replaces secrets in the config response.
+const redacted = "redacted"
+
+type (
+	// ConfigResponse defines the response type for getting the configuration
+	// the price-feeder is running with. Only an explicit set of fields is
+	// exposed: keyring secrets are left out and credentials embedded in
+	// endpoint URLs are redacted.
+	ConfigResponse struct {
+		Account             ConfigAccount        `json:"account"`
+		Keyring             ConfigKeyring        `json:"keyring"`
+		RPC                 ConfigRPC            `json:"rpc"`
+		Server              ConfigServer         `json:"server"`
+		CurrencyPairs       []ConfigCurrencyPair `json:"currency_pairs"`
+		DeviationThresholds map[string]string    `json:"deviation_thresholds"`
+		ProviderTimeout     string               `json:"provider_timeout"`
+		ProviderMinOverride bool                 `json:"provider_min_override"`
+		ProviderEndpoints   []ConfigEndpoint     `json:"provider_endpoints"`
+		SourceGroups        map[string][]string  `json:"source_groups"`
+		GasAdjustment       float64              `json:"gas_adjustment"`
+		Fees                string               `json:"fees"`
+		TimeoutMargin       int64                `json:"timeout_margin"`
+		TelemetryEnabled    bool                 `json:"telemetry_enabled"`
+		VoteSLOTarget       float64              `json:"vote_slo_target"`
+		Hub                 ConfigHub            `json:"hub"`
+	}
+
+	// ConfigHub defines the hub section of the config response.
+	ConfigHub struct {
+		Serve bool   `json:"serve"`
+		URL   string `json:"url"`
+	}
+
+	// ConfigAccount defines the account section of the config response.
+	ConfigAccount struct {
+		ChainID   string `json:"chain_id"`
+		Address   string `json:"address"`
+		Validator string `json:"validator"`
+	}
+
+	// ConfigKeyring defines the keyring section of the config response.
+	ConfigKeyring struct {
+		Backend string `json:"backend"`
+		Dir     string `json:"dir"`
+	}
+
+	// ConfigRPC defines the RPC section of the config response.
+	ConfigRPC struct {
+		TMRPCEndpoint          string   `json:"tmrpc_endpoint"`
+		TMRPCFallbackEndpoints []string `json:"tmrpc_fallback_endpoints"`
+		GRPCEndpoint           string   `json:"grpc_endpoint"`
+		RPCTimeout             string   `json:"rpc_timeout"`
+		BroadcastMode          string   `json:"broadcast_mode"`
+		MaxHeightAge           string   `json:"max_height_age"`
+	}
+
+	// ConfigServer defines the server section of the config response.
+	ConfigServer struct {
+		ListenAddr        string `json:"listen_addr"`
+		WriteTimeout      string `json:"write_timeout"`
+		ReadTimeout       string `json:"read_timeout"`
+		IdleTimeout       string `json:"idle_timeout"`
+		ReadHeaderTimeout string `json:"read_header_timeout"`
+		ShutdownTimeout   string `json:"shutdown_timeout"`
+		DrainPeriod       string `json:"drain_period"`
+		MaxSyncAge        string `json:"max_sync_age"`
+		MaxBodyBytes      int64  `json:"max_body_bytes"`
+	}
+
+	// ConfigCurrencyPair defines a currency pair of the config response.
+	ConfigCurrencyPair struct {
+		Base      string   `json:"base"`
+		Quote     string   `json:"quote"`
+		Providers []string `json:"providers"`
+	}
+
+	// ConfigEndpoint defines a provider endpoint override of the config
+	// response.
+	ConfigEndpoint struct {
+		Name      string `json:"name"`
+		Rest      string `json:"rest"`
+		Websocket string `json:"websocket"`
+		GRPC      string `json:"grpc,omitempty"`
+	}
+)
+
+// NewConfigResponse returns the sanitized view of cfg.
+func NewConfigResponse(cfg config.Config) ConfigResponse {
+	resp := ConfigResponse{
+		Account: ConfigAccount{
+			ChainID:   cfg.Account.ChainID,
+			Address:   cfg.Account.Address,
+			Validator: cfg.Account.Validator,
+		},
+		Keyring: ConfigKeyring{
+			Backend: cfg.Keyring.Backend,
+			Dir:     cfg.Keyring.Dir,
+		},
+		RPC: ConfigRPC{
+			TMRPCEndpoint:          redactURL(cfg.RPC.TMRPCEndpoint),
+			TMRPCFallbackEndpoints: redactURLs(cfg.RPC.TMRPCFallbackEndpoints),
+			GRPCEndpoint:           redactURL(cfg.RPC.GRPCEndpoint),
+			RPCTimeout:             cfg.RPC.RPCTimeout,
+			BroadcastMode:          cfg.RPC.BroadcastMode,
+			MaxHeightAge:           cfg.RPC.MaxHeightAge,
+		},
+		Server: ConfigServer{
+			ListenAddr:        cfg.Server.ListenAddr,
+			WriteTimeout:      cfg.Server.WriteTimeout,
+			ReadTimeout:       cfg.Server.ReadTimeout,
+			IdleTimeout:       cfg.Server.IdleTimeout,
+			ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+			ShutdownTimeout:   cfg.Server.ShutdownTimeout,
+			DrainPeriod:       cfg.Server.DrainPeriod,
+			MaxSyncAge:        cfg.Server.MaxSyncAge,
+			MaxBodyBytes:      cfg.Server.MaxBodyBytes,
+		},
+		CurrencyPairs:       make([]ConfigCurrencyPair, 0, len(cfg.CurrencyPairs)),
+		DeviationThresholds: make(map[string]string, len(cfg.Deviations)),
+		ProviderTimeout:     cfg.ProviderTimeout,
+		ProviderMinOverride: cfg.ProviderMinOverride,
+		ProviderEndpoints:   make([]ConfigEndpoint, 0, len(cfg.ProviderEndpoints)),
+		GasAdjustment:       cfg.GasAdjustment,
+		Fees:                cfg.Fees,
+		TimeoutMargin:       cfg.TimeoutMargin,
+		TelemetryEnabled:    cfg.Telemetry.Enabled,
+		VoteSLOTarget:       cfg.VoteSLOTarget,
+		SourceGroups:        make(map[string][]string, len(cfg.SourceGroups)),
+		Hub: ConfigHub{
+			Serve: cfg.Hub.Serve,
+			URL:   redactURL(cfg.Hub.URL),
+		},
+	}
+
+	for _, pair := range cfg.CurrencyPairs {
+		providers := make([]string, 0, len(pair.Providers))
+		for _, providerName := range pair.Providers {
+			providers = append(providers, providerName.String())
+		}
+
+		resp.CurrencyPairs = append(resp.CurrencyPairs, ConfigCurrencyPair{
+			Base:      pair.Base,
+			Quote:     pair.Quote,
+			Providers: providers,
+		})
+	}
+
+	for _, group := range cfg.SourceGroups {
+		for _, providerName := range group.Providers {
+			resp.SourceGroups[group.Name] = append(resp.SourceGroups[group.Name], providerName.String())
+		}
+	}
+
+	for _, deviation := range cfg.Deviations {
+		resp.DeviationThresholds[deviation.Base] = deviation.Threshold
+	}
+
+	for _, endpoint := range cfg.ProviderEndpoints {
+		resp.ProviderEndpoints = append(resp.ProviderEndpoints, ConfigEndpoint{
+			Name:      endpoint.Name.String(),
+			Rest:      redactURL(endpoint.Rest),
+			Websocket: redactURL(endpoint.Websocket),
+			GRPC:      redactURL(endpoint.GRPC),
+		})
+	}
+
+	return resp
+}
+
+// redactURL redacts the user info and query parameter values of rawURL, which
+// commonly carry API keys. Values which don't parse as URLs, e.g. plain
+// host:port gRPC endpoints, are returned as is.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || len(u.Host) == 0 {
+		return rawURL
+	}
+
+	if u.User != nil {
+		u.User = url.User(redacted)
+	}
+
+	if len(u.RawQuery) != 0 {
+		query := u.Query()
+		for key := range query {
+			query.Set(key, redacted)
+		}
+		u.RawQuery = query.Encode()
+	}
+
+	return u.String()
+}
+
+// redactURLs redacts each of rawURLs, see redactURL.
+func redactURLs(rawURLs []string) []string {
+	if rawURLs == nil {
+		return nil
+	}
+
+	redactedURLs := make([]string, len(rawURLs))
+	for i, rawURL := range rawURLs {
+		redactedURLs[i] = redactURL(rawURL)
+	}
+
+	return redactedURLs
+}
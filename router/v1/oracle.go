@@ -3,11 +3,29 @@ package v1
 import (
 	"time"
 
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/persistenceOne/oracle-feeder/oracle"
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
 )
 
 // Oracle defines the Oracle interface contract that the v1 router depends on.
 type Oracle interface {
 	GetLastPriceSyncTimestamp() time.Time
+	IsStopping() bool
+	GetHealth() oracle.Health
 	GetPrices() map[string]sdk.Dec
+	GetPriceTimestamps() map[string]time.Time
+	Sign(msg []byte) ([]byte, cryptotypes.PubKey, error)
+	SubscribePrices() (<-chan map[string]sdk.Dec, func())
+	GetProviderSnapshots() map[provider.Name]provider.Snapshot
+	GetVoterStatus() oracle.VoterStatus
+	GetVoteSLO() oracle.SLOReport
+	GetSpend() oracle.SpendReport
+	GetConfigDrift() (oracle.DriftReport, bool)
+	GetProviderWeights() map[provider.Name]map[string]oracle.ProviderWeight
+	GetCorrelations(window time.Duration) map[string]oracle.AssetCorrelation
+	GetAggregations() map[string]oracle.AssetAggregation
+	GetAggregationCounts() map[string]map[oracle.AggregationMethod]uint64
 }
@@ -1,12 +1,25 @@
 package v1
 
 import (
+	"time"
+
 	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
 )
 
 // Response constants.
 const (
 	StatusAvailable = "available"
+	StatusReady     = "ready"
+	StatusStale     = "stale"
+
+	// PricesSchemaVersion is the version of the AssetPricesResponse schema.
+	PricesSchemaVersion = 2
+
+	// PricesFormatLegacy is the format query value of the prices endpoint
+	// returning the legacy PricesResponse.
+	PricesFormatLegacy = "legacy"
 )
 
 type (
@@ -14,13 +27,290 @@ type (
 	HealthZResponse struct {
 		Status string `json:"status" yaml:"status"`
 		Oracle struct {
-			LastSync string `json:"last_sync"`
+			LastSync       string  `json:"last_sync"`
+			SyncAgeSeconds float64 `json:"sync_age_seconds"`
+			Stale          bool    `json:"stale"`
 		} `json:"oracle"`
+		Chain     ChainHealth                      `json:"chain"`
+		Providers map[provider.Name]ProviderHealth `json:"providers"`
+	}
+
+	// ReadyZResponse defines the response type for the readiness API handler.
+	ReadyZResponse struct {
+		Status string `json:"status"`
+	}
+
+	// ChainHealth defines the status of the block height subscription: the
+	// latest height, when it last increased and the error of the subscription,
+	// if any.
+	ChainHealth struct {
+		Height           int64     `json:"height"`
+		UpdatedAt        time.Time `json:"updated_at"`
+		HeightAgeSeconds float64   `json:"height_age_seconds"`
+		Stale            bool      `json:"stale"`
+		Error            string    `json:"error,omitempty"`
 	}
 
-	// PricesResponse defines the response type for getting the latest exchange
-	// rates from the oracle.
+	// ProviderHealth defines whether the last requests of a provider
+	// succeeded, and when it last succeeded and failed.
+	ProviderHealth struct {
+		Connected        bool       `json:"connected"`
+		UnderMaintenance bool       `json:"under_maintenance"`
+		LastSuccess      *time.Time `json:"last_success,omitempty"`
+		LastFailure      *time.Time `json:"last_failure,omitempty"`
+		LastError        string     `json:"last_error,omitempty"`
+	}
+
+	// PricesResponse defines the legacy response type for getting the latest
+	// exchange rates from the oracle, which is also sent on price streams.
 	PricesResponse struct {
 		Prices map[string]sdk.Dec `json:"prices"`
 	}
+
+	// AssetPricesResponse defines the versioned response type for getting the
+	// latest exchange rates from the oracle along with how each was computed.
+	AssetPricesResponse struct {
+		Version int                   `json:"version"`
+		Prices  map[string]AssetPrice `json:"prices"`
+	}
+
+	// AssetPrice defines the latest exchange rate of an asset, when it was
+	// last computed, from how many providers and with which method, so that
+	// consumers can ignore the individual stale assets. Confidence is
+	// the share of the providers of the asset which passed the deviation
+	// filter.
+	AssetPrice struct {
+		Price      sdk.Dec   `json:"price"`
+		Timestamp  time.Time `json:"timestamp"`
+		Providers  int       `json:"providers"`
+		Method     string    `json:"method"`
+		Confidence sdk.Dec   `json:"confidence"`
+	}
+
+	// SignedPricesResponse defines the response type for getting the latest
+	// exchange rates signed by the feeder key. The signature is computed over
+	// Payload, which is built by SignedPricesPayload, and can be verified
+	// against PubKey. Signature and PubKey are base64 encoded.
+	SignedPricesResponse struct {
+		Prices    map[string]sdk.Dec `json:"prices"`
+		Timestamp int64              `json:"timestamp"`
+		Nonce     string             `json:"nonce"`
+		Payload   string             `json:"payload"`
+		Signature string             `json:"signature"`
+		PubKey    string             `json:"pub_key"`
+		Signer    string             `json:"signer"`
+	}
+
+	// StatusResponse defines the response type for getting the voter role of
+	// the instance. LeaderActivity is only set once a standby instance has
+	// observed a pre-vote of the active voter on-chain.
+	StatusResponse struct {
+		Role           string                  `json:"role"`
+		LeaderActivity *LeaderActivityResponse `json:"last_leader_activity,omitempty"`
+	}
+
+	// LeaderActivityResponse defines the latest on-chain pre-vote of the
+	// active voter observed by a standby instance.
+	LeaderActivityResponse struct {
+		SubmitBlock uint64 `json:"submit_block"`
+		ObservedAt  string `json:"observed_at"`
+	}
+
+	// VoteSLOResponse defines the response type for getting the share of vote
+	// periods with an included vote over the SLO windows, keyed by window.
+	VoteSLOResponse struct {
+		Target  float64                  `json:"target"`
+		Windows map[string]VoteSLOWindow `json:"windows"`
+	}
+
+	// VoteSLOWindow defines the vote success ratio over a single window.
+	VoteSLOWindow struct {
+		Periods  int     `json:"periods"`
+		Success  int     `json:"success"`
+		Ratio    float64 `json:"ratio"`
+		Breached bool    `json:"breached"`
+	}
+
+	// SpendResponse defines the response type for getting the gas used and
+	// fees paid by the feeder account since the start of its tracking and over
+	// the spend windows, keyed by window.
+	SpendResponse struct {
+		Txs     uint64                 `json:"txs"`
+		GasUsed uint64                 `json:"gas_used"`
+		Fees    string                 `json:"fees"`
+		Windows map[string]SpendWindow `json:"windows"`
+	}
+
+	// SpendWindow defines the spend over a single window and whether its fees
+	// exceed its budget.
+	SpendWindow struct {
+		Txs      int    `json:"txs"`
+		GasUsed  int64  `json:"gas_used"`
+		Fees     string `json:"fees"`
+		Budget   string `json:"budget,omitempty"`
+		Exceeded bool   `json:"exceeded"`
+	}
+
+	// ConfigDriftResponse defines the response type for getting the drift of
+	// the config from the recommended config manifest of its network, as of
+	// its last check, which failed if Error is set.
+	ConfigDriftResponse struct {
+		URL       string        `json:"url"`
+		Network   string        `json:"network"`
+		CheckedAt string        `json:"checked_at,omitempty"`
+		Error     string        `json:"error,omitempty"`
+		Drifts    []ConfigDrift `json:"drifts"`
+	}
+
+	// ConfigDrift defines a difference between the config and the
+	// recommended settings of an asset.
+	ConfigDrift struct {
+		Asset    string `json:"asset"`
+		Kind     string `json:"kind"`
+		Expected string `json:"expected,omitempty"`
+		Actual   string `json:"actual,omitempty"`
+	}
+
+	// ProviderWeightsResponse defines the response type for getting the
+	// aggregation weights learned per provider and asset. Providers which
+	// always agreed with the final price are omitted and have a weight of one.
+	ProviderWeightsResponse struct {
+		Weights map[provider.Name]map[string]ProviderWeight `json:"weights"`
+	}
+
+	// AggregationsResponse defines the response type for getting how the
+	// price of every asset was computed: with the TVWAP of the candles or,
+	// as a fallback, with the VWAP of the tickers.
+	AggregationsResponse struct {
+		Assets map[string]AssetAggregation `json:"assets"`
+	}
+
+	// AssetAggregation defines how the price of an asset was computed during
+	// the last tick, and the number of ticks each method was used since the
+	// start.
+	AssetAggregation struct {
+		Method    string            `json:"method,omitempty"`
+		Providers []string          `json:"providers"`
+		Filtered  []string          `json:"filtered"`
+		Ticks     map[string]uint64 `json:"ticks"`
+	}
+
+	// DeviationsResponse defines the response type for getting the deviation
+	// filter decisions of the last tick for the price of every asset of every
+	// provider used to compute it.
+	DeviationsResponse struct {
+		Assets map[string][]DeviationDecision `json:"assets"`
+	}
+
+	// DeviationDecision defines whether the price of a provider was within
+	// margin of the mean of all providers. The outcome is "accepted",
+	// "filtered" or "unchecked" if there were too few providers to compute
+	// the standard deviation. The mean and margin are left out for the assets
+	// priced by vote-only providers.
+	DeviationDecision struct {
+		Provider provider.Name `json:"provider"`
+		Price    sdk.Dec       `json:"price"`
+		Mean     *sdk.Dec      `json:"mean,omitempty"`
+		Margin   *sdk.Dec      `json:"margin,omitempty"`
+		Outcome  string        `json:"outcome"`
+	}
+
+	// AssetPriceResponse defines the versioned response type for getting the
+	// latest exchange rate of a single asset, when it was last computed and
+	// the price of every provider it was aggregated from.
+	AssetPriceResponse struct {
+		Version    int             `json:"version"`
+		Asset      string          `json:"asset"`
+		Price      sdk.Dec         `json:"price"`
+		Timestamp  time.Time       `json:"timestamp"`
+		Method     string          `json:"method"`
+		Confidence sdk.Dec         `json:"confidence"`
+		Providers  []ProviderPrice `json:"providers"`
+	}
+
+	// ProviderPrice defines the USD price of an asset of a provider, its TVWAP
+	// or VWAP depending on the method, and whether the deviation filter
+	// accepted it.
+	ProviderPrice struct {
+		Provider provider.Name `json:"provider"`
+		Price    sdk.Dec       `json:"price"`
+		Outcome  string        `json:"outcome"`
+	}
+
+	// ExplainResponse defines the response type for explaining how the price
+	// of an asset was computed during the last tick, from the raw price of
+	// every provider to the final price.
+	ExplainResponse struct {
+		Asset     string              `json:"asset"`
+		Price     sdk.Dec             `json:"price"`
+		Timestamp time.Time           `json:"timestamp"`
+		Method    string              `json:"method"`
+		Providers []ExplainedProvider `json:"providers"`
+	}
+
+	// ExplainedProvider defines the steps from the raw ticker and candles of
+	// the pair of a provider to the USD price the deviation filter decided on:
+	// the conversion applied when the pair isn't quoted in USD, the mean and
+	// standard deviation of the prices of all providers, the margin around
+	// the mean and the outcome, the statistics being left out for the assets
+	// priced by vote-only providers. Weight is the aggregation weight learned
+	// for the provider.
+	ExplainedProvider struct {
+		Provider   provider.Name        `json:"provider"`
+		Pair       string               `json:"pair"`
+		Ticker     *ExplainedTicker     `json:"ticker,omitempty"`
+		Candles    int                  `json:"candles"`
+		Conversion *ExplainedConversion `json:"conversion,omitempty"`
+		Price      sdk.Dec              `json:"price"`
+		Mean       *sdk.Dec             `json:"mean,omitempty"`
+		StdDev     *sdk.Dec             `json:"std_dev,omitempty"`
+		Margin     *sdk.Dec             `json:"margin,omitempty"`
+		Outcome    string               `json:"outcome"`
+		Weight     sdk.Dec              `json:"weight"`
+	}
+
+	// ExplainedTicker defines the raw ticker of a provider, in the quote of
+	// its pair.
+	ExplainedTicker struct {
+		Price  sdk.Dec `json:"price"`
+		Volume sdk.Dec `json:"volume"`
+	}
+
+	// ExplainedConversion defines the USD rate of the quote of a pair its
+	// prices were converted at.
+	ExplainedConversion struct {
+		Quote string  `json:"quote"`
+		Rate  sdk.Dec `json:"rate"`
+	}
+
+	// ProviderWeight defines the learned weight of a provider for an asset and
+	// its average relative deviation from the final price.
+	ProviderWeight struct {
+		Weight sdk.Dec `json:"weight"`
+		Bias   sdk.Dec `json:"bias"`
+	}
+
+	// CorrelationsResponse defines the response type for getting the pairwise
+	// correlation and spread between the providers of every asset.
+	CorrelationsResponse struct {
+		Window string                      `json:"window"`
+		Assets map[string]AssetCorrelation `json:"assets"`
+	}
+
+	// AssetCorrelation defines the provider correlations of a single asset and
+	// the providers whose feed decorrelated from their peers.
+	AssetCorrelation struct {
+		Pairs        []ProviderPairCorrelation `json:"pairs"`
+		Decorrelated []provider.Name           `json:"decorrelated"`
+	}
+
+	// ProviderPairCorrelation defines the correlation of the price returns of
+	// two providers, which is null when undefined, and their mean relative
+	// spread.
+	ProviderPairCorrelation struct {
+		Providers   [2]provider.Name `json:"providers"`
+		Samples     int              `json:"samples"`
+		Correlation *float64         `json:"correlation"`
+		Spread      float64          `json:"spread"`
+	}
 )
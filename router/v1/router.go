@@ -1,82 +1,911 @@
 package v1
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/gorilla/mux"
 	"github.com/rs/zerolog"
 
 	"github.com/persistenceOne/oracle-feeder/config"
+	"github.com/persistenceOne/oracle-feeder/oracle"
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
 	"github.com/persistenceOne/oracle-feeder/pkg/httputil"
 	"github.com/persistenceOne/oracle-feeder/router/middleware"
 )
 
 const (
-	APIPathPrefix = "/api/v1"
+	APIPathPrefix = "/api/" + APIVersion
+	APIVersion    = "v1"
+
+	// MetricsPath is the path the metrics are served on outside of the API
+	// prefix.
+	MetricsPath = "/metrics"
+
+	// streamKeepAliveInterval defines how often a comment is sent on idle
+	// price streams to keep intermediaries from closing the connection.
+	streamKeepAliveInterval = 15 * time.Second
+
+	// streamRetryMillis defines the reconnection delay advertised to SSE
+	// clients.
+	streamRetryMillis = 5000
 )
 
 // Router defines a router wrapper used for registering v1 API routes.
 type Router struct {
-	logger zerolog.Logger
-	cfg    config.Config
-	oracle Oracle
+	logger       zerolog.Logger
+	cfg          config.Config
+	oracle       Oracle
+	metrics      *telemetry.Metrics
+	signedPrices signedPricesCache
 }
 
-func New(logger zerolog.Logger, cfg config.Config, oracle Oracle) *Router {
+// New returns a v1 API router. The metrics endpoint is only registered when
+// metrics is not nil, i.e. telemetry is enabled.
+func New(logger zerolog.Logger, cfg config.Config, oracle Oracle, metrics *telemetry.Metrics) *Router {
 	return &Router{
-		logger: logger.With().Str("module", "router").Logger(),
-		cfg:    cfg,
-		oracle: oracle,
+		logger:  logger.With().Str("module", "router").Logger(),
+		cfg:     cfg,
+		oracle:  oracle,
+		metrics: metrics,
 	}
 }
 
-// RegisterRoutes register v1 API routes on the provided sub-router.
+// RegisterRoutes register v1 API routes on the provided sub-router and, when
+// telemetry is enabled, the metrics on its MetricsPath, where Prometheus
+// scrapes them by default, in the Prometheus format unless requested
+// otherwise.
 func (r *Router) RegisterRoutes(rtr *mux.Router, prefix string) {
-	v1Router := rtr.PathPrefix(prefix).Subrouter()
-
-	// build middleware chain
-	mChain := middleware.Build(r.logger, r.cfg)
-
-	// handle all preflight request
-	v1Router.Methods("OPTIONS").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", r.Header.Get("Origin"))
-		w.Header().Set("Access-Control-Allow-Methods", "GET, PUT, POST, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Access-Control-Allow-Headers, "+
-			"Authorization, X-Requested-With")
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
-		w.WriteHeader(http.StatusOK)
-	})
+	middleware.RegisterVersionedRoutes(rtr, prefix, r, r.logger, r.cfg)
 
-	v1Router.Handle(
-		"/healthz",
-		mChain.ThenFunc(r.healthzHandler()),
-	).Methods(httputil.MethodGET)
+	if r.metrics != nil {
+		rtr.Handle(
+			MetricsPath,
+			middleware.Build(r.logger, r.cfg).ThenFunc(r.metricsHandler(telemetry.FormatPrometheus)),
+		).Methods(httputil.MethodGET)
+	}
+}
 
-	v1Router.Handle(
-		"/prices",
-		mChain.ThenFunc(r.pricesHandler()),
-	).Methods(httputil.MethodGET)
+// Version implements middleware.VersionedAPI.
+func (r *Router) Version() string {
+	return APIVersion
 }
 
+// Routes implements middleware.VersionedAPI. The providers stream is only
+// served when the instance is a hub and the metrics when telemetry is enabled.
+func (r *Router) Routes() []middleware.Route {
+	routes := []middleware.Route{
+		{Path: "/healthz", Method: httputil.MethodGET, Handler: r.healthzHandler()},
+		{Path: "/readyz", Method: httputil.MethodGET, Handler: r.readyzHandler()},
+		{Path: "/prices", Method: httputil.MethodGET, Handler: r.pricesHandler()},
+		{Path: "/prices/stream", Method: httputil.MethodGET, Handler: r.pricesStreamHandler()},
+		{Path: "/prices/signed", Method: httputil.MethodGET, Handler: r.signedPricesHandler()},
+		{Path: "/prices/{asset}", Method: httputil.MethodGET, Handler: r.assetPriceHandler()},
+		{Path: "/status", Method: httputil.MethodGET, Handler: r.statusHandler()},
+		{Path: "/slo", Method: httputil.MethodGET, Handler: r.voteSLOHandler()},
+		{Path: "/spend", Method: httputil.MethodGET, Handler: r.spendHandler()},
+		{Path: "/weights", Method: httputil.MethodGET, Handler: r.providerWeightsHandler()},
+		{Path: "/aggregations", Method: httputil.MethodGET, Handler: r.aggregationsHandler()},
+		{Path: "/deviations", Method: httputil.MethodGET, Handler: r.deviationsHandler()},
+		{Path: "/explain/{asset}", Method: httputil.MethodGET, Handler: r.explainHandler()},
+		{Path: "/diagnostics/correlation", Method: httputil.MethodGET, Handler: r.correlationsHandler()},
+		{Path: "/config", Method: httputil.MethodGET, Handler: r.configHandler()},
+		{Path: "/config/drift", Method: httputil.MethodGET, Handler: r.configDriftHandler()},
+	}
+	if r.cfg.Hub.Serve {
+		routes = append(routes, middleware.Route{
+			Path: "/providers/stream", Method: httputil.MethodGET, Handler: r.providersStreamHandler(),
+		})
+	}
+	if r.metrics != nil {
+		routes = append(routes, middleware.Route{
+			Path: "/metrics", Method: httputil.MethodGET, Handler: r.metricsHandler(telemetry.FormatDefault),
+		})
+	}
+
+	return routes
+}
+
+// healthzHandler reports the liveness of the oracle along with how current
+// it is: the age of its last price sync, the status of the block height
+// subscription and whether each provider answered its last requests. It
+// fails once the last price sync is stale, so that orchestrators can restart
+// an oracle which stopped syncing.
 func (r *Router) healthzHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
-		resp := HealthZResponse{
-			Status: StatusAvailable,
+		resp := newHealthZResponse(r.oracle.GetHealth())
+
+		statusCode := http.StatusOK
+		if resp.Oracle.Stale {
+			statusCode = http.StatusServiceUnavailable
 		}
 
-		resp.Oracle.LastSync = r.oracle.GetLastPriceSyncTimestamp().Format(time.RFC3339)
+		httputil.RespondWithJSON(w, statusCode, resp)
+	}
+}
 
-		httputil.RespondWithJSON(w, http.StatusOK, resp)
+func newHealthZResponse(health oracle.Health) HealthZResponse {
+	resp := HealthZResponse{
+		Status: StatusAvailable,
+		Chain: ChainHealth{
+			Height:           health.Height,
+			UpdatedAt:        health.HeightUpdatedAt,
+			HeightAgeSeconds: health.HeightAge.Seconds(),
+			Stale:            health.HeightStale,
+		},
+		Providers: make(map[provider.Name]ProviderHealth, len(health.Providers)),
+	}
+	if health.Stale {
+		resp.Status = StatusStale
+	}
+
+	resp.Oracle.LastSync = health.LastSync.Format(time.RFC3339)
+	resp.Oracle.SyncAgeSeconds = health.SyncAge.Seconds()
+	resp.Oracle.Stale = health.Stale
+	if health.HeightErr != nil {
+		resp.Chain.Error = health.HeightErr.Error()
+	}
+
+	for providerName, providerHealth := range health.Providers {
+		ph := ProviderHealth{
+			Connected:        providerHealth.Connected,
+			UnderMaintenance: providerHealth.UnderMaintenance,
+			LastError:        providerHealth.LastError,
+		}
+		if lastSuccess := providerHealth.LastSuccess; !lastSuccess.IsZero() {
+			ph.LastSuccess = &lastSuccess
+		}
+		if lastFailure := providerHealth.LastFailure; !lastFailure.IsZero() {
+			ph.LastFailure = &lastFailure
+		}
+		resp.Providers[providerName] = ph
 	}
+
+	return resp
 }
 
+// readyzHandler reports whether the instance should receive traffic. It
+// fails as soon as the oracle is stopping, before the vote in progress
+// completes and the server shuts down, so that load balancers stop routing to
+// it during rolling deploys. It also fails until prices are first synced,
+// and while the last sync or the block height is stale.
+func (r *Router) readyzHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if r.oracle.IsStopping() {
+			httputil.RespondWithError(
+				w,
+				http.StatusServiceUnavailable,
+				httputil.ErrCodeShuttingDown,
+				"the price-feeder is shutting down",
+				nil,
+			)
+			return
+		}
+
+		health := r.oracle.GetHealth()
+		switch {
+		case health.LastSync.IsZero():
+			httputil.RespondWithError(
+				w,
+				http.StatusServiceUnavailable,
+				httputil.ErrCodeNotReady,
+				"prices are not available yet",
+				nil,
+			)
+
+		case health.Stale:
+			httputil.RespondWithError(
+				w,
+				http.StatusServiceUnavailable,
+				httputil.ErrCodeStalePrices,
+				"prices were not synced recently",
+				map[string]interface{}{
+					"last_sync":        health.LastSync.Format(time.RFC3339),
+					"sync_age_seconds": health.SyncAge.Seconds(),
+				},
+			)
+
+		case health.HeightErr != nil || health.HeightStale:
+			details := map[string]interface{}{
+				"height":             health.Height,
+				"height_age_seconds": health.HeightAge.Seconds(),
+			}
+			if health.HeightErr != nil {
+				details["error"] = health.HeightErr.Error()
+			}
+			httputil.RespondWithError(
+				w,
+				http.StatusServiceUnavailable,
+				httputil.ErrCodeNotReady,
+				"the block height is unavailable or stale",
+				details,
+			)
+
+		default:
+			httputil.RespondWithJSON(w, http.StatusOK, ReadyZResponse{Status: StatusReady})
+		}
+	}
+}
+
+// pricesHandler returns the latest prices along with how each of them was
+// computed. The "format=legacy" query parameter returns the bare prices
+// instead.
 func (r *Router) pricesHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
-		resp := PricesResponse{
-			Prices: r.oracle.GetPrices(),
+		prices, ok := r.currentPrices(w)
+		if !ok {
+			return
+		}
+
+		if strings.TrimSpace(req.FormValue("format")) == PricesFormatLegacy {
+			httputil.RespondWithJSON(w, http.StatusOK, PricesResponse{Prices: prices})
+			return
+		}
+
+		var (
+			timestamps   = r.oracle.GetPriceTimestamps()
+			aggregations = r.oracle.GetAggregations()
+		)
+		resp := AssetPricesResponse{
+			Version: PricesSchemaVersion,
+			Prices:  make(map[string]AssetPrice, len(prices)),
+		}
+		for base, price := range prices {
+			aggregation := aggregations[base]
+			resp.Prices[base] = AssetPrice{
+				Price:      price,
+				Timestamp:  timestamps[base].UTC(),
+				Providers:  len(aggregation.Providers),
+				Method:     string(aggregation.Method),
+				Confidence: aggregation.Confidence(),
+			}
+		}
+
+		httputil.RespondWithJSON(w, http.StatusOK, resp)
+	}
+}
+
+// assetPriceHandler returns the latest price of the asset of the path, e.g.
+// "/prices/ATOM", when it was last computed and the TVWAP or VWAP of every
+// provider it was aggregated from, so that clients interested in a single
+// asset don't fetch all the prices. Vote-only providers are left out as they
+// hold their prices.
+func (r *Router) assetPriceHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		prices, ok := r.currentPrices(w)
+		if !ok {
+			return
+		}
+
+		asset := strings.ToUpper(mux.Vars(req)["asset"])
+		price, ok := prices[asset]
+		if !ok {
+			httputil.RespondWithError(
+				w,
+				http.StatusNotFound,
+				httputil.ErrCodeNotFound,
+				"no price for asset",
+				map[string]interface{}{"asset": asset},
+			)
+			return
+		}
+
+		aggregation := r.oracle.GetAggregations()[asset]
+		resp := AssetPriceResponse{
+			Version:    PricesSchemaVersion,
+			Asset:      asset,
+			Price:      price,
+			Timestamp:  r.oracle.GetPriceTimestamps()[asset].UTC(),
+			Method:     string(aggregation.Method),
+			Confidence: aggregation.Confidence(),
+			Providers:  make([]ProviderPrice, 0, len(aggregation.Decisions)),
+		}
+		for _, decision := range aggregation.Decisions {
+			if r.cfg.IsVoteOnly(decision.Provider) {
+				continue
+			}
+			resp.Providers = append(resp.Providers, ProviderPrice{
+				Provider: decision.Provider,
+				Price:    decision.Price,
+				Outcome:  string(decision.Outcome),
+			})
+		}
+
+		httputil.RespondWithJSON(w, http.StatusOK, resp)
+	}
+}
+
+// statusHandler returns whether the instance is the active voter or on
+// standby, together with the last activity of the active voter it observed.
+func (r *Router) statusHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		status := r.oracle.GetVoterStatus()
+
+		resp := StatusResponse{
+			Role: status.Role,
+		}
+		if !status.LeaderActivity.ObservedAt.IsZero() {
+			resp.LeaderActivity = &LeaderActivityResponse{
+				SubmitBlock: status.LeaderActivity.SubmitBlock,
+				ObservedAt:  status.LeaderActivity.ObservedAt.Format(time.RFC3339),
+			}
+		}
+
+		httputil.RespondWithJSON(w, http.StatusOK, resp)
+	}
+}
+
+// voteSLOHandler returns the vote success ratios over the SLO windows.
+func (r *Router) voteSLOHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		report := r.oracle.GetVoteSLO()
+
+		resp := VoteSLOResponse{
+			Target:  report.Target,
+			Windows: make(map[string]VoteSLOWindow, len(report.Windows)),
+		}
+		for name, window := range report.Windows {
+			resp.Windows[name] = VoteSLOWindow{
+				Periods:  window.Periods,
+				Success:  window.Success,
+				Ratio:    window.Ratio,
+				Breached: window.Breached,
+			}
+		}
+
+		httputil.RespondWithJSON(w, http.StatusOK, resp)
+	}
+}
+
+// spendHandler returns the gas used and fees paid by the feeder account.
+func (r *Router) spendHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		report := r.oracle.GetSpend()
+
+		resp := SpendResponse{
+			Txs:     report.Txs,
+			GasUsed: report.GasUsed,
+			Fees:    report.Fees.String(),
+			Windows: make(map[string]SpendWindow, len(report.Windows)),
+		}
+		for name, window := range report.Windows {
+			resp.Windows[name] = SpendWindow{
+				Txs:      window.Txs,
+				GasUsed:  window.GasUsed,
+				Fees:     window.Fees.String(),
+				Budget:   window.Budget.String(),
+				Exceeded: window.Exceeded,
+			}
+		}
+
+		httputil.RespondWithJSON(w, http.StatusOK, resp)
+	}
+}
+
+// aggregationsHandler returns the aggregation method used per asset during
+// the last tick and how often each method was used, so that a chronic
+// fallback from candles to tickers is noticed.
+func (r *Router) aggregationsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		aggregations := r.oracle.GetAggregations()
+		counts := r.oracle.GetAggregationCounts()
+
+		resp := AggregationsResponse{
+			Assets: make(map[string]AssetAggregation, len(counts)),
+		}
+		for base, methodCounts := range counts {
+			asset := AssetAggregation{
+				Providers: []string{},
+				Filtered:  []string{},
+				Ticks:     make(map[string]uint64, len(methodCounts)),
+			}
+			for method, count := range methodCounts {
+				asset.Ticks[string(method)] = count
+			}
+
+			if aggregation, ok := aggregations[base]; ok {
+				asset.Method = string(aggregation.Method)
+				for _, providerName := range aggregation.Providers {
+					asset.Providers = append(asset.Providers, providerName.String())
+				}
+				for _, providerName := range aggregation.Filtered {
+					asset.Filtered = append(asset.Filtered, providerName.String())
+				}
+			}
+
+			resp.Assets[base] = asset
+		}
+
+		httputil.RespondWithJSON(w, http.StatusOK, resp)
+	}
+}
+
+// deviationsHandler returns the deviation filter decisions of the last tick
+// per asset, which show the providers filtered out for an asset only. The
+// decisions of vote-only providers are left out as they hold their prices,
+// along with the mean and margin of the assets they went into.
+func (r *Router) deviationsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		aggregations := r.oracle.GetAggregations()
+
+		resp := DeviationsResponse{
+			Assets: make(map[string][]DeviationDecision, len(aggregations)),
+		}
+		for base, aggregation := range aggregations {
+			voteOnlyInput := r.hasVoteOnlyInput(aggregation)
+			decisions := make([]DeviationDecision, 0, len(aggregation.Decisions))
+			for _, decision := range aggregation.Decisions {
+				if r.cfg.IsVoteOnly(decision.Provider) {
+					continue
+				}
+				d := DeviationDecision{
+					Provider: decision.Provider,
+					Price:    decision.Price,
+					Outcome:  string(decision.Outcome),
+				}
+				if !voteOnlyInput {
+					mean, margin := decision.Mean, decision.Margin
+					d.Mean, d.Margin = &mean, &margin
+				}
+				decisions = append(decisions, d)
+			}
+			resp.Assets[base] = decisions
+		}
+
+		httputil.RespondWithJSON(w, http.StatusOK, resp)
+	}
+}
+
+// explainHandler returns how the price of the asset of the path, e.g.
+// "/explain/ATOM", was computed during the last tick: the raw ticker and
+// candles of every provider, the conversion to USD applied to them, the
+// deviation filter decision and the final price. Vote-only providers are left
+// out as they hold their prices, along with the mean, standard deviation and
+// margin of the assets they went into.
+func (r *Router) explainHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		prices, ok := r.currentPrices(w)
+		if !ok {
+			return
+		}
+
+		asset := strings.ToUpper(mux.Vars(req)["asset"])
+		price, ok := prices[asset]
+		if !ok {
+			httputil.RespondWithError(
+				w,
+				http.StatusNotFound,
+				httputil.ErrCodeNotFound,
+				"no price for asset",
+				map[string]interface{}{"asset": asset},
+			)
+			return
+		}
+
+		var (
+			aggregation = r.oracle.GetAggregations()[asset]
+			snapshots   = r.oracle.GetProviderSnapshots()
+			weights     = r.oracle.GetProviderWeights()
+		)
+		resp := ExplainResponse{
+			Asset:     asset,
+			Price:     price,
+			Timestamp: r.oracle.GetPriceTimestamps()[asset].UTC(),
+			Method:    string(aggregation.Method),
+			Providers: make([]ExplainedProvider, 0, len(aggregation.Decisions)),
+		}
+		voteOnlyInput := r.hasVoteOnlyInput(aggregation)
+		for _, decision := range aggregation.Decisions {
+			if r.cfg.IsVoteOnly(decision.Provider) {
+				continue
+			}
+
+			explained := ExplainedProvider{
+				Provider: decision.Provider,
+				Pair:     asset + config.DenomUSD,
+				Price:    decision.Price,
+				Outcome:  string(decision.Outcome),
+				Weight:   sdk.OneDec(),
+			}
+			if !voteOnlyInput {
+				mean, stdDev, margin := decision.Mean, decision.StdDev, decision.Margin
+				explained.Mean, explained.StdDev, explained.Margin = &mean, &stdDev, &margin
+			}
+			for _, conversion := range aggregation.Conversions {
+				if conversion.Provider == decision.Provider {
+					explained.Pair = asset + conversion.Quote
+					explained.Conversion = &ExplainedConversion{Quote: conversion.Quote, Rate: conversion.Rate}
+				}
+			}
+			if ticker, ok := snapshots[decision.Provider].Tickers[explained.Pair]; ok {
+				explained.Ticker = &ExplainedTicker{Price: ticker.Price, Volume: ticker.Volume}
+			}
+			explained.Candles = len(snapshots[decision.Provider].Candles[explained.Pair])
+			if weight, ok := weights[decision.Provider][asset]; ok {
+				explained.Weight = weight.Weight
+			}
+
+			resp.Providers = append(resp.Providers, explained)
+		}
+
+		httputil.RespondWithJSON(w, http.StatusOK, resp)
+	}
+}
+
+// providerWeightsHandler returns the aggregation weights learned per provider
+// and asset.
+func (r *Router) providerWeightsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		weights := r.oracle.GetProviderWeights()
+
+		resp := ProviderWeightsResponse{
+			Weights: make(map[provider.Name]map[string]ProviderWeight, len(weights)),
+		}
+		for providerName, baseWeights := range weights {
+			resp.Weights[providerName] = make(map[string]ProviderWeight, len(baseWeights))
+			for base, weight := range baseWeights {
+				resp.Weights[providerName][base] = ProviderWeight{
+					Weight: weight.Weight,
+					Bias:   weight.Bias,
+				}
+			}
 		}
 
 		httputil.RespondWithJSON(w, http.StatusOK, resp)
 	}
 }
+
+// correlationsHandler returns the pairwise correlation and spread between the
+// providers of every asset over the "window" query parameter, which defaults
+// to oracle.DefaultCorrelationWindow. The pairs with a vote-only provider are
+// left out, as their spread gives away its prices.
+func (r *Router) correlationsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		window := oracle.DefaultCorrelationWindow
+		if v := req.URL.Query().Get("window"); len(v) != 0 {
+			var err error
+			if window, err = time.ParseDuration(v); err != nil || window <= 0 {
+				httputil.RespondWithError(
+					w,
+					http.StatusBadRequest,
+					httputil.ErrCodeBadRequest,
+					"window must be a positive duration, e.g. 30m",
+					nil,
+				)
+				return
+			}
+		}
+
+		correlations := r.oracle.GetCorrelations(window)
+
+		resp := CorrelationsResponse{
+			Window: window.String(),
+			Assets: make(map[string]AssetCorrelation, len(correlations)),
+		}
+		for base, ac := range correlations {
+			asset := AssetCorrelation{
+				Pairs:        make([]ProviderPairCorrelation, 0, len(ac.Pairs)),
+				Decorrelated: make([]provider.Name, 0, len(ac.Decorrelated)),
+			}
+			for _, providerName := range ac.Decorrelated {
+				if !r.cfg.IsVoteOnly(providerName) {
+					asset.Decorrelated = append(asset.Decorrelated, providerName)
+				}
+			}
+			for _, pair := range ac.Pairs {
+				if r.cfg.IsVoteOnly(pair.Providers[0]) || r.cfg.IsVoteOnly(pair.Providers[1]) {
+					continue
+				}
+				asset.Pairs = append(asset.Pairs, ProviderPairCorrelation{
+					Providers:   pair.Providers,
+					Samples:     pair.Samples,
+					Correlation: pair.Correlation,
+					Spread:      pair.Spread,
+				})
+			}
+			resp.Assets[base] = asset
+		}
+
+		httputil.RespondWithJSON(w, http.StatusOK, resp)
+	}
+}
+
+// configHandler returns a sanitized view of the configuration the instance
+// was started with, see ConfigResponse.
+func (r *Router) configHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		httputil.RespondWithJSON(w, http.StatusOK, NewConfigResponse(r.cfg))
+	}
+}
+
+// configDriftHandler returns the drift of the config from the recommended
+// config manifest found by its last check.
+func (r *Router) configDriftHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		report, ok := r.oracle.GetConfigDrift()
+		if !ok {
+			httputil.RespondWithError(
+				w,
+				http.StatusNotFound,
+				httputil.ErrCodeNotFound,
+				"no config manifest is configured",
+				nil,
+			)
+			return
+		}
+
+		resp := ConfigDriftResponse{
+			URL:     redactURL(report.URL),
+			Network: report.Network,
+			Drifts:  make([]ConfigDrift, 0, len(report.Drifts)),
+		}
+		if !report.CheckedAt.IsZero() {
+			resp.CheckedAt = report.CheckedAt.UTC().Format(time.RFC3339)
+		}
+		if report.Err != nil {
+			resp.Error = report.Err.Error()
+		}
+		for _, drift := range report.Drifts {
+			resp.Drifts = append(resp.Drifts, ConfigDrift{
+				Asset:    drift.Asset,
+				Kind:     drift.Kind,
+				Expected: drift.Expected,
+				Actual:   drift.Actual,
+			})
+		}
+
+		httputil.RespondWithJSON(w, http.StatusOK, resp)
+	}
+}
+
+// metricsHandler returns the gathered telemetry metrics. The "format" query
+// parameter selects the encoding, e.g. "prometheus" for scraping, defaulting
+// to defaultFormat.
+func (r *Router) metricsHandler(defaultFormat string) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		format := strings.TrimSpace(req.FormValue("format"))
+		if len(format) == 0 {
+			format = defaultFormat
+		}
+
+		gr, err := r.metrics.Gather(format)
+		if err != nil {
+			httputil.RespondWithError(
+				w,
+				http.StatusBadRequest,
+				httputil.ErrCodeBadRequest,
+				fmt.Sprintf("failed to gather metrics: %s", err),
+				nil,
+			)
+			return
+		}
+
+		w.Header().Set("Content-Type", gr.ContentType)
+		_, _ = w.Write(gr.Metrics)
+	}
+}
+
+// pricesStreamHandler streams the computed prices as Server-Sent Events, one
+// "prices" event per oracle tick. The write timeout of the server bounds each
+// event rather than the whole stream, which lasts until the client goes away.
+func (r *Router) pricesStreamHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		r.serveStream(w, req, r.writePricesEvent)
+	}
+}
+
+// providersStreamHandler streams the ticker and candle prices of every
+// provider as Server-Sent Events, one "providers" event per oracle tick, to
+// the instances using this one as their hub.
+func (r *Router) providersStreamHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		r.serveStream(w, req, func(w http.ResponseWriter, _ map[string]sdk.Dec) error {
+			return r.writeProvidersEvent(w)
+		})
+	}
+}
+
+// serveStream serves a Server-Sent Events stream, writing events with
+// writeEvent for the current prices and then on every price update, until
+// the client goes away or a write fails. Each write must complete within the
+// write timeout of the server, which is extended before every write so that
+// it doesn't close the stream, see httputil.ExtendWriteDeadline.
+func (r *Router) serveStream(
+	w http.ResponseWriter,
+	req *http.Request,
+	writeEvent func(w http.ResponseWriter, prices map[string]sdk.Dec) error,
+) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		httputil.RespondWithError(
+			w,
+			http.StatusInternalServerError,
+			httputil.ErrCodeInternal,
+			"streaming is not supported",
+			nil,
+		)
+		return
+	}
+
+	updates, cancel := r.oracle.SubscribePrices()
+	defer cancel()
+
+	// the write timeout is validated by ParseConfig, and unset in tests
+	writeTimeout, _ := time.ParseDuration(r.cfg.Server.WriteTimeout)
+	send := func(write func() error) bool {
+		httputil.ExtendWriteDeadline(req, writeTimeout)
+		if err := write(); err != nil {
+			r.logger.Debug().Err(err).Msg("closing stream")
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	ok = send(func() error {
+		if _, err := fmt.Fprintf(w, "retry: %d\n\n", streamRetryMillis); err != nil {
+			return err
+		}
+		if prices := r.oracle.GetPrices(); len(prices) > 0 {
+			return writeEvent(w, prices)
+		}
+		return nil
+	})
+	if !ok {
+		return
+	}
+
+	keepAlive := time.NewTicker(streamKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+
+		case prices := <-updates:
+			ok = send(func() error { return writeEvent(w, prices) })
+
+		case <-keepAlive.C:
+			ok = send(func() error {
+				_, err := fmt.Fprint(w, ": keep-alive\n\n")
+				return err
+			})
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+// writePricesEvent writes a single "prices" Server-Sent Event.
+func (r *Router) writePricesEvent(w http.ResponseWriter, prices map[string]sdk.Dec) error {
+	data, err := json.Marshal(PricesResponse{Prices: r.publicPrices(prices)})
+	if err != nil {
+		return fmt.Errorf("failed to encode prices event: %w", err)
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: prices\ndata: %s\n\n", time.Now().UnixMilli(), data)
+	return err
+}
+
+// writeProvidersEvent writes a single "providers" Server-Sent Event.
+func (r *Router) writeProvidersEvent(w http.ResponseWriter) error {
+	data, err := json.Marshal(provider.HubEvent{
+		Timestamp: time.Now().UnixMilli(),
+		Providers: r.publicSnapshots(r.oracle.GetProviderSnapshots()),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode providers event: %w", err)
+	}
+
+	_, err = fmt.Fprintf(
+		w,
+		"id: %d\nevent: %s\ndata: %s\n\n",
+		time.Now().UnixMilli(),
+		provider.HubEventProviders,
+		data,
+	)
+	return err
+}
+
+// signedPricesHandler returns the latest prices signed by the feeder key so
+// that off-chain consumers can verify their authenticity. The prices are
+// signed once per price update, with a random nonce, and timestamped with
+// the time they were synced at, which consumers check for freshness.
+func (r *Router) signedPricesHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		prices, lastSync, ok := middleware.CurrentPrices(w, r.oracle, r.cfg.Server.PriceSyncAge())
+		if !ok {
+			return
+		}
+
+		resp, err := r.signPrices(lastSync, r.publicPrices(prices))
+		if err != nil {
+			r.logger.Err(err).Msg("failed to sign prices")
+			httputil.RespondWithError(
+				w,
+				http.StatusInternalServerError,
+				httputil.ErrCodeInternal,
+				"failed to sign prices",
+				nil,
+			)
+			return
+		}
+
+		httputil.RespondWithJSON(w, http.StatusOK, resp)
+	}
+}
+
+// currentPrices returns the latest prices of the oracle. If they are not
+// servable, an error response describing why is written and false returned.
+func (r *Router) currentPrices(w http.ResponseWriter) (map[string]sdk.Dec, bool) {
+	prices, _, ok := middleware.CurrentPrices(w, r.oracle, r.cfg.Server.PriceSyncAge())
+	if !ok {
+		return nil, false
+	}
+
+	return r.publicPrices(prices), true
+}
+
+// publicPrices returns the prices without the assets priced by vote-only
+// providers alone, whose prices must not be redistributed, see
+// config.Config.IsVoteOnly.
+func (r *Router) publicPrices(prices map[string]sdk.Dec) map[string]sdk.Dec {
+	if len(r.cfg.VoteOnlyProviders) == 0 {
+		return prices
+	}
+
+	aggregations := r.oracle.GetAggregations()
+	public := make(map[string]sdk.Dec, len(prices))
+	for base, price := range prices {
+		if r.cfg.IsVoteOnlyAsset(aggregations[base].Providers) {
+			continue
+		}
+		public[base] = price
+	}
+
+	return public
+}
+
+// hasVoteOnlyInput returns whether a vote-only provider priced the asset of
+// aggregation, in which case the statistics of its deviation filter, e.g. the
+// mean of the provider prices, give away the vote-only prices.
+func (r *Router) hasVoteOnlyInput(aggregation oracle.AssetAggregation) bool {
+	for _, decision := range aggregation.Decisions {
+		if r.cfg.IsVoteOnly(decision.Provider) {
+			return true
+		}
+	}
+	for _, providerName := range aggregation.Providers {
+		if r.cfg.IsVoteOnly(providerName) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// publicSnapshots returns the provider snapshots without the ones of the
+// vote-only providers, see config.Config.IsVoteOnly.
+func (r *Router) publicSnapshots(
+	snapshots map[provider.Name]provider.Snapshot,
+) map[provider.Name]provider.Snapshot {
+	for providerName := range snapshots {
+		if r.cfg.IsVoteOnly(providerName) {
+			delete(snapshots, providerName)
+		}
+	}
+
+	return snapshots
+}
@@ -0,0 +1,92 @@
+package v1
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	// SignedPricesDomain prefixes every signed prices payload, so that a
+	// signature of the feeder key over them can't be mistaken for one over a
+	// transaction or any other message.
+	SignedPricesDomain = "oracle-feeder/signed-prices/v1"
+
+	nonceBytes = 16
+)
+
+// signedPricesCache holds the signed prices of the last price sync, so that
+// the feeder key signs once per price update however often they are
+// requested.
+type signedPricesCache struct {
+	mtx      sync.Mutex
+	lastSync time.Time
+	resp     SignedPricesResponse
+}
+
+// SignedPricesPayload returns the canonical message signed by the signed
+// prices handler: "<domain>:<nonce>:<unix timestamp>:<rates>", where domain
+// is SignedPricesDomain and rates the comma separated, sorted list of
+// "<base>:<price>" also used for votes.
+func SignedPricesPayload(nonce string, timestamp int64, prices map[string]sdk.Dec) string {
+	rates := make([]string, 0, len(prices))
+	for base, price := range prices {
+		rates = append(rates, fmt.Sprintf("%s:%s", base, price.String()))
+	}
+	sort.Strings(rates)
+
+	return fmt.Sprintf("%s:%s:%d:%s", SignedPricesDomain, nonce, timestamp, strings.Join(rates, ","))
+}
+
+// signPrices returns the prices of the price sync at lastSync signed by the
+// feeder key, signing them only if they weren't already.
+func (r *Router) signPrices(lastSync time.Time, prices map[string]sdk.Dec) (SignedPricesResponse, error) {
+	r.signedPrices.mtx.Lock()
+	defer r.signedPrices.mtx.Unlock()
+
+	if r.signedPrices.lastSync.Equal(lastSync) {
+		return r.signedPrices.resp, nil
+	}
+
+	nonce, err := generateNonce()
+	if err != nil {
+		return SignedPricesResponse{}, err
+	}
+
+	timestamp := lastSync.Unix()
+	payload := SignedPricesPayload(nonce, timestamp, prices)
+	signature, pubKey, err := r.oracle.Sign([]byte(payload))
+	if err != nil {
+		return SignedPricesResponse{}, err
+	}
+
+	r.signedPrices.lastSync = lastSync
+	r.signedPrices.resp = SignedPricesResponse{
+		Prices:    prices,
+		Timestamp: timestamp,
+		Nonce:     nonce,
+		Payload:   payload,
+		Signature: base64.StdEncoding.EncodeToString(signature),
+		PubKey:    base64.StdEncoding.EncodeToString(pubKey.Bytes()),
+		Signer:    sdk.AccAddress(pubKey.Address()).String(),
+	}
+
+	return r.signedPrices.resp, nil
+}
+
+// generateNonce returns a random hex encoded nonce.
+func generateNonce() (string, error) {
+	bz := make([]byte, nonceBytes)
+	if _, err := rand.Read(bz); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(bz), nil
+}
@@ -0,0 +1,19 @@
+package v2
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/persistenceOne/oracle-feeder/oracle"
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+)
+
+// Oracle defines the Oracle interface contract that the v2 router depends on.
+type Oracle interface {
+	GetLastPriceSyncTimestamp() time.Time
+	GetPrices() map[string]sdk.Dec
+	GetPriceTimestamps() map[string]time.Time
+	GetAggregations() map[string]oracle.AssetAggregation
+	GetProviderWeights() map[provider.Name]map[string]oracle.ProviderWeight
+}
@@ -0,0 +1,58 @@
+package v2
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+)
+
+type (
+	// PricesResponse defines the response type for getting the latest
+	// exchange rates from the oracle, along with how and from which provider
+	// prices each of them was computed.
+	PricesResponse struct {
+		Metadata Metadata              `json:"metadata"`
+		Prices   map[string]AssetPrice `json:"prices"`
+	}
+
+	// AssetPriceResponse defines the response type for getting the latest
+	// exchange rate of a single asset.
+	AssetPriceResponse struct {
+		Metadata Metadata   `json:"metadata"`
+		Asset    string     `json:"asset"`
+		Price    AssetPrice `json:"price"`
+	}
+
+	// Metadata defines the version of the API which served a response, when
+	// the prices were last synced and when the response was generated.
+	Metadata struct {
+		APIVersion  string    `json:"api_version"`
+		LastSync    time.Time `json:"last_sync"`
+		GeneratedAt time.Time `json:"generated_at"`
+	}
+
+	// AssetPrice defines the latest exchange rate of an asset, when it was
+	// last computed and with which method. Confidence is the share of the
+	// providers of the asset which passed the deviation filter, and Providers
+	// the breakdown of their prices, without those of vote-only providers.
+	AssetPrice struct {
+		Price      sdk.Dec         `json:"price"`
+		Timestamp  time.Time       `json:"timestamp"`
+		Method     string          `json:"method"`
+		Confidence sdk.Dec         `json:"confidence"`
+		Providers  []ProviderPrice `json:"providers"`
+	}
+
+	// ProviderPrice defines the price of an asset of a provider, its relative
+	// deviation from the computed price, its aggregation weight and the
+	// deviation filter outcome: "accepted", "filtered" or "unchecked".
+	ProviderPrice struct {
+		Provider  provider.Name `json:"provider"`
+		Price     sdk.Dec       `json:"price"`
+		Deviation sdk.Dec       `json:"deviation"`
+		Weight    sdk.Dec       `json:"weight"`
+		Outcome   string        `json:"outcome"`
+	}
+)
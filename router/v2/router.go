@@ -0,0 +1,175 @@
+// Package v2 serves the v2 API, whose responses carry metadata, confidence
+// and provider breakdowns, concurrently with the stable v1 API.
+package v2
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog"
+
+	"github.com/persistenceOne/oracle-feeder/config"
+	"github.com/persistenceOne/oracle-feeder/oracle"
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+	"github.com/persistenceOne/oracle-feeder/pkg/httputil"
+	"github.com/persistenceOne/oracle-feeder/router/middleware"
+)
+
+const (
+	APIPathPrefix = "/api/" + APIVersion
+	APIVersion    = "v2"
+)
+
+// Router defines a router wrapper used for registering v2 API routes.
+type Router struct {
+	logger zerolog.Logger
+	cfg    config.Config
+	oracle Oracle
+}
+
+// New returns a v2 API router.
+func New(logger zerolog.Logger, cfg config.Config, oracle Oracle) *Router {
+	return &Router{
+		logger: logger.With().Str("module", "router_v2").Logger(),
+		cfg:    cfg,
+		oracle: oracle,
+	}
+}
+
+// RegisterRoutes register v2 API routes on the provided sub-router.
+func (r *Router) RegisterRoutes(rtr *mux.Router, prefix string) {
+	middleware.RegisterVersionedRoutes(rtr, prefix, r, r.logger, r.cfg)
+}
+
+// Version implements middleware.VersionedAPI.
+func (r *Router) Version() string {
+	return APIVersion
+}
+
+// Routes implements middleware.VersionedAPI.
+func (r *Router) Routes() []middleware.Route {
+	return []middleware.Route{
+		{Path: "/prices", Method: httputil.MethodGET, Handler: r.pricesHandler()},
+		{Path: "/prices/{asset}", Method: httputil.MethodGET, Handler: r.assetPriceHandler()},
+	}
+}
+
+// pricesHandler returns the latest prices with their provider breakdowns.
+func (r *Router) pricesHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		prices, metadata, ok := r.currentPrices(w)
+		if !ok {
+			return
+		}
+
+		httputil.RespondWithJSON(w, http.StatusOK, PricesResponse{Metadata: metadata, Prices: prices})
+	}
+}
+
+// assetPriceHandler returns the latest price of the asset of the path, e.g.
+// "/prices/ATOM", with its provider breakdown.
+func (r *Router) assetPriceHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		prices, metadata, ok := r.currentPrices(w)
+		if !ok {
+			return
+		}
+
+		asset := strings.ToUpper(mux.Vars(req)["asset"])
+		price, ok := prices[asset]
+		if !ok {
+			httputil.RespondWithError(
+				w,
+				http.StatusNotFound,
+				httputil.ErrCodeNotFound,
+				"no price for asset",
+				map[string]interface{}{"asset": asset},
+			)
+			return
+		}
+
+		httputil.RespondWithJSON(w, http.StatusOK, AssetPriceResponse{
+			Metadata: metadata,
+			Asset:    asset,
+			Price:    price,
+		})
+	}
+}
+
+// currentPrices returns the latest prices of the oracle with their provider
+// breakdowns, without the assets priced by vote-only providers alone. If
+// they are not servable, an error response describing why is written and
+// false returned.
+func (r *Router) currentPrices(w http.ResponseWriter) (map[string]AssetPrice, Metadata, bool) {
+	prices, lastSync, ok := middleware.CurrentPrices(w, r.oracle, r.cfg.Server.PriceSyncAge())
+	if !ok {
+		return nil, Metadata{}, false
+	}
+
+	var (
+		timestamps   = r.oracle.GetPriceTimestamps()
+		aggregations = r.oracle.GetAggregations()
+		weights      = r.oracle.GetProviderWeights()
+	)
+	assetPrices := make(map[string]AssetPrice, len(prices))
+	for base, price := range prices {
+		aggregation := aggregations[base]
+		if r.cfg.IsVoteOnlyAsset(aggregation.Providers) {
+			continue
+		}
+
+		assetPrices[base] = AssetPrice{
+			Price:      price,
+			Timestamp:  timestamps[base].UTC(),
+			Method:     string(aggregation.Method),
+			Confidence: aggregation.Confidence(),
+			Providers:  r.providerPrices(base, price, aggregation, weights),
+		}
+	}
+
+	metadata := Metadata{
+		APIVersion:  APIVersion,
+		LastSync:    lastSync.UTC(),
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	return assetPrices, metadata, true
+}
+
+// providerPrices returns the breakdown of the provider prices an asset price
+// was computed from. Providers without a learned weight have a weight of one.
+func (r *Router) providerPrices(
+	base string,
+	price sdk.Dec,
+	aggregation oracle.AssetAggregation,
+	weights map[provider.Name]map[string]oracle.ProviderWeight,
+) []ProviderPrice {
+	providerPrices := make([]ProviderPrice, 0, len(aggregation.Decisions))
+	for _, decision := range aggregation.Decisions {
+		if r.cfg.IsVoteOnly(decision.Provider) {
+			continue
+		}
+
+		deviation := sdk.ZeroDec()
+		if price.IsPositive() {
+			deviation = decision.Price.Sub(price).Quo(price)
+		}
+		weight := sdk.OneDec()
+		if w, ok := weights[decision.Provider][base]; ok {
+			weight = w.Weight
+		}
+
+		providerPrices = append(providerPrices, ProviderPrice{
+			Provider:  decision.Provider,
+			Price:     decision.Price,
+			Deviation: deviation,
+			Weight:    weight,
+			Outcome:   string(decision.Outcome),
+		})
+	}
+
+	return providerPrices
+}
@@ -0,0 +1,181 @@
+package v2_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/oracle-feeder/config"
+	"github.com/persistenceOne/oracle-feeder/oracle"
+	"github.com/persistenceOne/oracle-feeder/oracle/provider"
+	"github.com/persistenceOne/oracle-feeder/router/middleware"
+	v1 "github.com/persistenceOne/oracle-feeder/router/v1"
+	v2 "github.com/persistenceOne/oracle-feeder/router/v2"
+)
+
+var (
+	_ v2.Oracle = (*mockOracle)(nil)
+
+	mockPriceTimestamp = time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+)
+
+type mockOracle struct {
+	lastSync time.Time
+}
+
+func (m mockOracle) GetLastPriceSyncTimestamp() time.Time {
+	return m.lastSync
+}
+
+func (m mockOracle) GetPrices() map[string]sdk.Dec {
+	return map[string]sdk.Dec{
+		"ATOM":    sdk.MustNewDecFromStr("35"),
+		"STKATOM": sdk.MustNewDecFromStr("38"),
+	}
+}
+
+func (m mockOracle) GetPriceTimestamps() map[string]time.Time {
+	return map[string]time.Time{
+		"ATOM":    mockPriceTimestamp,
+		"STKATOM": mockPriceTimestamp,
+	}
+}
+
+func (m mockOracle) GetAggregations() map[string]oracle.AssetAggregation {
+	return map[string]oracle.AssetAggregation{
+		"ATOM": {
+			Method:    oracle.AggregationTVWAP,
+			Providers: []provider.Name{provider.Binance, provider.Kraken},
+			Filtered:  []provider.Name{provider.Okx},
+			Decisions: []oracle.DeviationDecision{
+				{Provider: provider.Binance, Price: sdk.MustNewDecFromStr("35.35"), Outcome: oracle.DeviationAccepted},
+				{Provider: provider.Kraken, Price: sdk.MustNewDecFromStr("34.65"), Outcome: oracle.DeviationAccepted},
+				{Provider: provider.Okx, Price: sdk.MustNewDecFromStr("42"), Outcome: oracle.DeviationFiltered},
+			},
+		},
+		"STKATOM": {
+			Method:    oracle.AggregationVWAP,
+			Providers: []provider.Name{provider.Okx},
+			Decisions: []oracle.DeviationDecision{
+				{Provider: provider.Okx, Price: sdk.MustNewDecFromStr("38"), Outcome: oracle.DeviationUnchecked},
+			},
+		},
+	}
+}
+
+func (m mockOracle) GetProviderWeights() map[provider.Name]map[string]oracle.ProviderWeight {
+	return map[provider.Name]map[string]oracle.ProviderWeight{
+		provider.Kraken: {
+			"ATOM": {Weight: sdk.MustNewDecFromStr("0.8")},
+		},
+	}
+}
+
+// newTestMux serves both API versions, as the price-feeder server does, with
+// okx configured as a vote-only provider and prices stale after a minute.
+func newTestMux(oracle mockOracle) *mux.Router {
+	cfg := config.Config{
+		Server:            config.Server{MaxSyncAge: "1m"},
+		VoteOnlyProviders: []provider.Name{provider.Okx},
+	}
+
+	rtr := mux.NewRouter()
+	v1.New(zerolog.Nop(), cfg, nil, nil).RegisterRoutes(rtr, v1.APIPathPrefix)
+	v2.New(zerolog.Nop(), cfg, oracle).RegisterRoutes(rtr, v2.APIPathPrefix)
+
+	return rtr
+}
+
+func TestPrices(t *testing.T) {
+	rtr := newTestMux(mockOracle{lastSync: time.Now()})
+
+	rr := httptest.NewRecorder()
+	rtr.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v2/prices", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, v2.APIVersion, rr.Header().Get(middleware.APIVersionHeader))
+
+	var resp v2.PricesResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Equal(t, v2.APIVersion, resp.Metadata.APIVersion)
+	require.False(t, resp.Metadata.GeneratedAt.IsZero())
+
+	// STKATOM is priced by the vote-only provider alone
+	require.NotContains(t, resp.Prices, "STKATOM")
+
+	atom := resp.Prices["ATOM"]
+	require.Equal(t, sdk.MustNewDecFromStr("35"), atom.Price)
+	require.Equal(t, mockPriceTimestamp, atom.Timestamp)
+	require.Equal(t, "tvwap", atom.Method)
+	require.Equal(t, sdk.MustNewDecFromStr("2").QuoInt64(3), atom.Confidence)
+	require.Equal(t, []v2.ProviderPrice{
+		{
+			Provider:  provider.Binance,
+			Price:     sdk.MustNewDecFromStr("35.35"),
+			Deviation: sdk.MustNewDecFromStr("0.01"),
+			Weight:    sdk.OneDec(),
+			Outcome:   "accepted",
+		},
+		{
+			Provider:  provider.Kraken,
+			Price:     sdk.MustNewDecFromStr("34.65"),
+			Deviation: sdk.MustNewDecFromStr("-0.01"),
+			Weight:    sdk.MustNewDecFromStr("0.8"),
+			Outcome:   "accepted",
+		},
+	}, atom.Providers)
+}
+
+func TestAssetPrice(t *testing.T) {
+	rtr := newTestMux(mockOracle{lastSync: time.Now()})
+
+	rr := httptest.NewRecorder()
+	rtr.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v2/prices/atom", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var resp v2.AssetPriceResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Equal(t, "ATOM", resp.Asset)
+	require.Equal(t, sdk.MustNewDecFromStr("35"), resp.Price.Price)
+
+	for _, asset := range []string{"STKATOM", "FOO"} {
+		rr = httptest.NewRecorder()
+		rtr.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v2/prices/"+asset, nil))
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	}
+}
+
+func TestPricesUnavailable(t *testing.T) {
+	for name, lastSync := range map[string]time.Time{
+		"not_ready": {},
+		"stale":     time.Now().Add(-2 * time.Minute),
+	} {
+		t.Run(name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			newTestMux(mockOracle{lastSync: lastSync}).
+				ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v2/prices", nil))
+			require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		})
+	}
+}
+
+func TestVersionsServedConcurrently(t *testing.T) {
+	rtr := newTestMux(mockOracle{lastSync: time.Now()})
+
+	// the v1 config route doesn't depend on the oracle
+	rr := httptest.NewRecorder()
+	rtr.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/config", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, v1.APIVersion, rr.Header().Get(middleware.APIVersionHeader))
+
+	// routes are only served by the versions registering them
+	rr = httptest.NewRecorder()
+	rtr.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v2/config", nil))
+	require.NotEqual(t, http.StatusOK, rr.Code)
+}
@@ -63,7 +63,7 @@ func (s *IntegrationTestSuite) TestSubscribeCurrencyPairs() {
 			pvd, _ := oracle.NewProvider(ctx, tc.provider, getLogger(), provider.Endpoint{}, tc.currencyPairs...)
 			time.Sleep(5 * time.Second)
 
-			err := pvd.SubscribeCurrencyPairs()
+			err := pvd.SubscribeCurrencyPairs(ctx)
 			require.NoError(t, err)
 
 			time.Sleep(25 * time.Second) // wait for provider to connect and receive some prices
@@ -75,11 +75,11 @@ func (s *IntegrationTestSuite) TestSubscribeCurrencyPairs() {
 }
 
 func checkForPrices(t *testing.T, pvd provider.Provider, currencyPairs []types.CurrencyPair) {
-	tickerPrices, err := pvd.GetTickerPrices(currencyPairs...)
+	tickerPrices, err := pvd.GetTickerPrices(context.Background(), currencyPairs...)
 	require.NoError(t, err)
 	require.NotEqual(t, len(tickerPrices), 0)
 
-	candlePrices, err := pvd.GetCandlePrices(currencyPairs...)
+	candlePrices, err := pvd.GetCandlePrices(context.Background(), currencyPairs...)
 	require.NoError(t, err)
 
 	for _, cp := range currencyPairs {